@@ -0,0 +1,220 @@
+// Package addressbook provides named account addresses.
+package addressbook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+var (
+	// ErrNotFound is the error returned when a name is not present in the address book.
+	ErrNotFound = errors.New("addressbook: name not found")
+	// ErrAlreadyExists is the error returned when a name is already present in the address book.
+	ErrAlreadyExists = errors.New("addressbook: name already exists")
+	// ErrInvalidName is the error returned when a name is not valid.
+	ErrInvalidName = errors.New("addressbook: invalid name")
+
+	_ AddressResolver = (*Book)(nil)
+)
+
+// AddressResolver resolves names to addresses and back.
+type AddressResolver interface {
+	// Resolve returns the address registered under the given name.
+	Resolve(name string) (types.Address, error)
+
+	// Lookup returns the name registered for the given address. In case there are multiple names
+	// registered for the same address, the lexicographically smallest one is returned.
+	Lookup(address types.Address) (string, bool)
+}
+
+// ResolveAddress resolves the given string either as a Bech32-encoded address or as a name known
+// to the resolver. The resolver may be nil in which case only addresses are accepted.
+func ResolveAddress(r AddressResolver, nameOrAddress string) (types.Address, error) {
+	var addr types.Address
+	if err := addr.UnmarshalText([]byte(nameOrAddress)); err == nil {
+		return addr, nil
+	}
+	if r == nil {
+		return types.Address{}, fmt.Errorf("addressbook: malformed address '%s'", nameOrAddress)
+	}
+	return r.Resolve(nameOrAddress)
+}
+
+// FormatAddress returns the name registered for the given address or the Bech32-encoded address
+// in case no name is known. The resolver may be nil.
+func FormatAddress(r AddressResolver, address types.Address) string {
+	if r != nil {
+		if name, ok := r.Lookup(address); ok {
+			return name
+		}
+	}
+	return address.String()
+}
+
+// ValidateName checks whether the given name can be used in an address book.
+//
+// Names must be non-empty, must not contain whitespace and must not themselves be valid addresses
+// so that resolution is never ambiguous.
+func ValidateName(name string) error {
+	if name == "" || strings.IndexFunc(name, func(r rune) bool { return r <= ' ' }) != -1 {
+		return fmt.Errorf("%w: '%s'", ErrInvalidName, name)
+	}
+	var addr types.Address
+	if err := addr.UnmarshalText([]byte(name)); err == nil {
+		return fmt.Errorf("%w: '%s' is an address", ErrInvalidName, name)
+	}
+	return nil
+}
+
+// Book is an address book, optionally backed by a JSON file.
+type Book struct {
+	sync.RWMutex
+
+	path    string
+	entries map[string]types.Address
+}
+
+// Implements AddressResolver.
+func (b *Book) Resolve(name string) (types.Address, error) {
+	b.RLock()
+	defer b.RUnlock()
+
+	addr, ok := b.entries[name]
+	if !ok {
+		return types.Address{}, fmt.Errorf("%w: '%s'", ErrNotFound, name)
+	}
+	return addr, nil
+}
+
+// Implements AddressResolver.
+func (b *Book) Lookup(address types.Address) (string, bool) {
+	b.RLock()
+	defer b.RUnlock()
+
+	var (
+		found bool
+		name  string
+	)
+	for n, addr := range b.entries {
+		if !addr.Equal(address) {
+			continue
+		}
+		if !found || n < name {
+			name = n
+			found = true
+		}
+	}
+	return name, found
+}
+
+// Add registers a new name for the given address.
+func (b *Book) Add(name string, address types.Address) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	if _, exists := b.entries[name]; exists {
+		return fmt.Errorf("%w: '%s'", ErrAlreadyExists, name)
+	}
+	b.entries[name] = address
+	return nil
+}
+
+// Remove removes the given name from the address book.
+func (b *Book) Remove(name string) error {
+	b.Lock()
+	defer b.Unlock()
+
+	if _, exists := b.entries[name]; !exists {
+		return fmt.Errorf("%w: '%s'", ErrNotFound, name)
+	}
+	delete(b.entries, name)
+	return nil
+}
+
+// Names returns all names in the address book in sorted order.
+func (b *Book) Names() []string {
+	b.RLock()
+	defer b.RUnlock()
+
+	names := make([]string, 0, len(b.entries))
+	for name := range b.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Save writes the address book to its backing file.
+func (b *Book) Save() error {
+	if b.path == "" {
+		return fmt.Errorf("addressbook: address book is not backed by a file")
+	}
+
+	b.RLock()
+	data, err := json.MarshalIndent(b.entries, "", "  ")
+	b.RUnlock()
+	if err != nil {
+		return fmt.Errorf("addressbook: failed to serialize address book: %w", err)
+	}
+
+	// Write to a temporary file first so that a failure does not corrupt the existing book.
+	tmpPath := b.path + ".tmp"
+	if err = ioutil.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("addressbook: failed to write address book: %w", err)
+	}
+	if err = os.Rename(tmpPath, b.path); err != nil {
+		return fmt.Errorf("addressbook: failed to write address book: %w", err)
+	}
+	return nil
+}
+
+// New creates a new empty in-memory address book.
+func New() *Book {
+	return &Book{
+		entries: make(map[string]types.Address),
+	}
+}
+
+// Open opens a file-backed address book. The file contains a JSON object mapping names to
+// Bech32-encoded addresses. In case the file does not exist, an empty address book is returned
+// and the file is created on the first call to Save.
+func Open(path string) (*Book, error) {
+	b := New()
+	b.path = path
+
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	switch {
+	case err == nil:
+	case os.IsNotExist(err):
+		return b, nil
+	default:
+		return nil, fmt.Errorf("addressbook: failed to read address book: %w", err)
+	}
+
+	if err = json.Unmarshal(data, &b.entries); err != nil {
+		return nil, fmt.Errorf("addressbook: malformed address book: %w", err)
+	}
+	if b.entries == nil {
+		// The file contains null.
+		b.entries = make(map[string]types.Address)
+	}
+	for name := range b.entries {
+		if err = ValidateName(name); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
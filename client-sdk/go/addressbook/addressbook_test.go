@@ -0,0 +1,89 @@
+package addressbook
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+)
+
+func TestBook(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "oasis-sdk-addressbook")
+	require.NoError(err, "TempDir")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "addressbook.json")
+
+	b, err := Open(path)
+	require.NoError(err, "Open non-existent")
+	require.Empty(b.Names())
+
+	require.NoError(b.Add("treasury", sdkTesting.Alice.Address))
+	require.NoError(b.Add("alice", sdkTesting.Alice.Address))
+	require.NoError(b.Add("bob", sdkTesting.Bob.Address))
+	require.True(errors.Is(b.Add("bob", sdkTesting.Charlie.Address), ErrAlreadyExists))
+	require.True(errors.Is(b.Add("", sdkTesting.Charlie.Address), ErrInvalidName))
+	require.True(errors.Is(b.Add("char lie", sdkTesting.Charlie.Address), ErrInvalidName))
+	require.True(errors.Is(b.Add(sdkTesting.Bob.Address.String(), sdkTesting.Charlie.Address), ErrInvalidName))
+	require.NoError(b.Save(), "Save")
+
+	b, err = Open(path)
+	require.NoError(err, "Open")
+	require.Equal([]string{"alice", "bob", "treasury"}, b.Names())
+
+	addr, err := b.Resolve("treasury")
+	require.NoError(err, "Resolve")
+	require.True(addr.Equal(sdkTesting.Alice.Address))
+	_, err = b.Resolve("charlie")
+	require.True(errors.Is(err, ErrNotFound))
+
+	name, ok := b.Lookup(sdkTesting.Alice.Address)
+	require.True(ok)
+	require.Equal("alice", name)
+	_, ok = b.Lookup(sdkTesting.Charlie.Address)
+	require.False(ok)
+
+	require.NoError(b.Remove("alice"))
+	require.True(errors.Is(b.Remove("alice"), ErrNotFound))
+	name, ok = b.Lookup(sdkTesting.Alice.Address)
+	require.True(ok)
+	require.Equal("treasury", name)
+
+	addr, err = ResolveAddress(b, "bob")
+	require.NoError(err, "ResolveAddress name")
+	require.True(addr.Equal(sdkTesting.Bob.Address))
+	addr, err = ResolveAddress(nil, sdkTesting.Charlie.Address.String())
+	require.NoError(err, "ResolveAddress address")
+	require.True(addr.Equal(sdkTesting.Charlie.Address))
+	_, err = ResolveAddress(nil, "bob")
+	require.Error(err, "ResolveAddress without resolver")
+
+	require.Equal("bob", FormatAddress(b, sdkTesting.Bob.Address))
+	require.Equal(sdkTesting.Charlie.Address.String(), FormatAddress(b, sdkTesting.Charlie.Address))
+}
+
+func TestOpenNull(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "oasis-sdk-addressbook")
+	require.NoError(err, "TempDir")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "addressbook.json")
+	require.NoError(ioutil.WriteFile(path, []byte("null"), 0o600), "WriteFile")
+
+	b, err := Open(path)
+	require.NoError(err, "Open")
+	require.Empty(b.Names())
+	require.NoError(b.Add("alice", sdkTesting.Alice.Address))
+	require.NoError(b.Save(), "Save")
+
+	b, err = Open(path)
+	require.NoError(err, "Open")
+	require.Equal([]string{"alice"}, b.Names())
+}
@@ -0,0 +1,102 @@
+package bridge
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// SignatureContextBase is the message signature domain separation context base.
+var SignatureContextBase = []byte("oasis-runtime-sdk/bridge: message v0")
+
+// ErrInvalidSignature is the error returned when a message signature is invalid.
+var ErrInvalidSignature = errors.New("bridge: invalid signature")
+
+// Message is a bridge message releasing tokens to a recipient on another runtime.
+type Message struct {
+	// Nonce is the unique nonce of the message.
+	Nonce uint64 `json:"nonce"`
+	// Recipient is the address receiving the tokens.
+	Recipient types.Address `json:"recipient"`
+	// Amount is the amount of released tokens.
+	Amount types.BaseUnits `json:"amount"`
+}
+
+// Sign signs the message with the given signer, for the chain of the runtime the message
+// originates from.
+func (m *Message) Sign(chainCtx signature.Context, signer signature.Signer) ([]byte, error) {
+	sig, err := signer.ContextSign(chainCtx.New(SignatureContextBase), cbor.Marshal(m))
+	if err != nil {
+		return nil, fmt.Errorf("bridge: failed to sign message: %w", err)
+	}
+	return sig, nil
+}
+
+// EscrowAddress returns the address of the escrow account of the bridge operated by the given
+// committee.
+func EscrowAddress(committee *types.MultisigConfig) types.Address {
+	return types.NewAddressFromMultisig(committee)
+}
+
+// Attestation is a message signed by members of the committee of a bridge.
+type Attestation struct {
+	// Message is the attested message.
+	Message Message `json:"message"`
+	// Signatures are the signatures of the committee members, in the order of the signers of the
+	// committee, with nil for members that have not signed.
+	Signatures [][]byte `json:"signatures"`
+}
+
+// NewAttestation creates a new attestation of the given message without signatures.
+func NewAttestation(committee *types.MultisigConfig, msg *Message) *Attestation {
+	return &Attestation{
+		Message:    *msg,
+		Signatures: make([][]byte, len(committee.Signers)),
+	}
+}
+
+// AddSignature adds the signature of the given committee member, after verifying it.
+func (a *Attestation) AddSignature(chainCtx signature.Context, committee *types.MultisigConfig, pk signature.PublicKey, sig []byte) error {
+	if len(a.Signatures) != len(committee.Signers) {
+		return fmt.Errorf("bridge: attestation does not match committee")
+	}
+	for i, member := range committee.Signers {
+		if !member.PublicKey.Equal(pk) {
+			continue
+		}
+		if !pk.Verify(chainCtx.New(SignatureContextBase), cbor.Marshal(&a.Message), sig) {
+			return ErrInvalidSignature
+		}
+		a.Signatures[i] = sig
+		return nil
+	}
+	return fmt.Errorf("bridge: %s is not a committee member", pk)
+}
+
+// Signed returns true iff the attestation is signed by a threshold of the given committee. The
+// signatures are not verified.
+func (a *Attestation) Signed(committee *types.MultisigConfig) bool {
+	_, _, err := committee.Batch(a.Signatures)
+	return err == nil
+}
+
+// Verify verifies that the attestation is signed by a threshold of the given committee, for the
+// chain of the runtime the message originates from.
+func (a *Attestation) Verify(chainCtx signature.Context, committee *types.MultisigConfig) error {
+	pks, sigs, err := committee.Batch(a.Signatures)
+	if err != nil {
+		return fmt.Errorf("bridge: %w", err)
+	}
+	msgCtx := chainCtx.New(SignatureContextBase)
+	msg := cbor.Marshal(&a.Message)
+	for i, pk := range pks {
+		if !pk.Verify(msgCtx, msg, sigs[i]) {
+			return fmt.Errorf("%w: signature of %s", ErrInvalidSignature, pk)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Package bridge implements helpers for building relayers of lock/mint bridges between runtimes.
+//
+// A bridge is operated by a committee of signers, described by a multisig configuration, whose
+// multisig address is the escrow account of the bridge on the source runtime:
+//
+//   - Users lock tokens by transferring them to the escrow account. Locks are extracted from the
+//     rounds of the source runtime, e.g. with a processor that hands each round to LockHandler
+//     exactly once.
+//   - Each committee member signs the message of each lock, and a relayer collects the signatures
+//     into an attestation until it is signed by a threshold of the committee.
+//   - The relayer submits the attestation to the bridge module of the destination runtime with a
+//     mint transaction, or of the source runtime with an unlock transaction for the way back.
+//
+// Message nonces are derived from the position of the lock event in the source chain, so that all
+// committee members sign identical messages without coordination, and they increase with the
+// position so that a NonceTracker can reject replayed messages.
+package bridge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/indexer"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/processor"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// nonceIndexBits is the number of bits of a nonce that hold the index of the lock event within
+// its round.
+const nonceIndexBits = 24
+
+// LockNonce returns the nonce of the message of the lock emitted by the event with the given index
+// in the given round.
+func LockNonce(round uint64, eventIndex uint32) uint64 {
+	return round<<nonceIndexBits | uint64(eventIndex)
+}
+
+// Lock is a transfer of tokens to the escrow account of a bridge.
+type Lock struct {
+	// Nonce is the nonce of the message of the lock.
+	Nonce uint64 `json:"nonce"`
+	// Round is the round in which the tokens were locked.
+	Round uint64 `json:"round"`
+	// TxHash is the hash of the transaction that locked the tokens, if any.
+	TxHash *hash.Hash `json:"tx_hash,omitempty"`
+	// From is the address that locked the tokens.
+	From types.Address `json:"from"`
+	// Amount is the amount of locked tokens.
+	Amount types.BaseUnits `json:"amount"`
+}
+
+// Message returns the message releasing the locked tokens to the address that locked them, which
+// is the same on all runtimes.
+func (l *Lock) Message() *Message {
+	return &Message{
+		Nonce:     l.Nonce,
+		Recipient: l.From,
+		Amount:    l.Amount,
+	}
+}
+
+// Locks returns the transfers to the given escrow account in the given round, in the order of
+// their events.
+func Locks(data *indexer.RoundData, escrow types.Address) ([]*Lock, error) {
+	var locks []*Lock
+	for _, ev := range data.Events {
+		if ev.Module != accounts.ModuleName || ev.Code != accounts.TransferEventCode {
+			continue
+		}
+		if ev.Index >= 1<<nonceIndexBits {
+			return nil, fmt.Errorf("bridge: too many events in round %d", data.Block.Round)
+		}
+		var transfer accounts.TransferEvent
+		if err := cbor.Unmarshal(ev.Value, &transfer); err != nil {
+			return nil, fmt.Errorf("bridge: malformed transfer event %d of round %d: %w", ev.Index, data.Block.Round, err)
+		}
+		if !transfer.To.Equal(escrow) {
+			continue
+		}
+
+		lock := &Lock{
+			Nonce:  LockNonce(data.Block.Round, ev.Index),
+			Round:  data.Block.Round,
+			From:   transfer.From,
+			Amount: transfer.Amount,
+		}
+		if ev.TxIndex != nil {
+			lock.TxHash = &data.Transactions[*ev.TxIndex].Hash
+		}
+		locks = append(locks, lock)
+	}
+	return locks, nil
+}
+
+// LockHandler returns a processor handler calling the given function for each lock to the given
+// escrow account, in the transaction of the round.
+func LockHandler(escrow types.Address, fn func(ctx context.Context, tx processor.Tx, lock *Lock) error) processor.Handler {
+	return func(ctx context.Context, tx processor.Tx, data *indexer.RoundData) error {
+		locks, err := Locks(data, escrow)
+		if err != nil {
+			return err
+		}
+		for _, lock := range locks {
+			if err = fn(ctx, tx, lock); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,133 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/processor"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/fakeruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const testChainContext = signature.Context("test")
+
+func nativeUnits(amount uint64) types.BaseUnits {
+	return types.NewBaseUnits(*quantity.NewFromUint64(amount), types.NativeDenomination)
+}
+
+func testCommittee() *types.MultisigConfig {
+	cfg := &types.MultisigConfig{Threshold: 2}
+	for _, key := range []sdkTesting.TestKey{sdkTesting.Alice, sdkTesting.Bob, sdkTesting.Charlie} {
+		cfg.Signers = append(cfg.Signers, types.MultisigSigner{
+			PublicKey: types.PublicKey{PublicKey: key.Signer.Public()},
+			Weight:    1,
+		})
+	}
+	return cfg
+}
+
+func TestLocks(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	committee := testCommittee()
+	escrow := EscrowAddress(committee)
+	rt := fakeruntime.New(fakeruntime.Config{})
+	rt.SetBalance(sdkTesting.Alice.Address, nativeUnits(1000))
+	transfer := func(to types.Address, amount, nonce uint64) uint64 {
+		tb := accounts.NewV1(rt).Transfer(to, nativeUnits(amount))
+		tb.AppendAuthSignature(sdkTesting.Alice.SigSpec, nonce)
+		require.NoError(tb.AppendSign(ctx, sdkTesting.Alice.Signer), "AppendSign")
+		meta, err := tb.SubmitTxMeta(ctx, nil)
+		require.NoError(err, "SubmitTxMeta")
+		return meta.Round
+	}
+	lockRound := transfer(escrow, 100, 0)
+	otherRound := transfer(sdkTesting.Bob.Address, 10, 1)
+
+	var locks []*Lock
+	p, err := processor.New(rt, processor.Config{
+		Handler: LockHandler(escrow, func(ctx context.Context, tx processor.Tx, lock *Lock) error {
+			locks = append(locks, lock)
+			return nil
+		}),
+	})
+	require.NoError(err, "processor.New")
+	pos, err := p.ProcessRound(ctx, nil, lockRound)
+	require.NoError(err, "ProcessRound")
+	_, err = p.ProcessRound(ctx, pos, otherRound)
+	require.NoError(err, "ProcessRound")
+
+	require.Len(locks, 1, "only transfers to the escrow account should be locks")
+	lock := locks[0]
+	require.Equal(lockRound, lock.Round)
+	require.Equal(LockNonce(lockRound, 0), lock.Nonce)
+	require.NotNil(lock.TxHash)
+	require.Equal(sdkTesting.Alice.Address, lock.From)
+	require.Equal(nativeUnits(100), lock.Amount)
+
+	msg := lock.Message()
+	require.Equal(sdkTesting.Alice.Address, msg.Recipient)
+	require.Less(msg.Nonce, LockNonce(otherRound, 0), "nonces should increase with rounds")
+}
+
+func TestAttestation(t *testing.T) {
+	require := require.New(t)
+
+	committee := testCommittee()
+	msg := &Message{Nonce: LockNonce(1, 0), Recipient: sdkTesting.Alice.Address, Amount: nativeUnits(100)}
+	sign := func(key sdkTesting.TestKey) []byte {
+		sig, err := msg.Sign(testChainContext, key.Signer)
+		require.NoError(err, "Sign")
+		return sig
+	}
+
+	att := NewAttestation(committee, msg)
+	require.NoError(att.AddSignature(testChainContext, committee, sdkTesting.Alice.Signer.Public(), sign(sdkTesting.Alice)))
+	require.False(att.Signed(committee), "one signature should be below the threshold")
+	require.Error(att.Verify(testChainContext, committee))
+
+	require.ErrorIs(att.AddSignature(testChainContext, committee, sdkTesting.Bob.Signer.Public(), sign(sdkTesting.Alice)), ErrInvalidSignature)
+	require.Error(att.AddSignature(testChainContext, committee, sdkTesting.Dave.Signer.Public(), sign(sdkTesting.Dave)), "non-member")
+
+	require.NoError(att.AddSignature(testChainContext, committee, sdkTesting.Bob.Signer.Public(), sign(sdkTesting.Bob)))
+	require.True(att.Signed(committee))
+	require.NoError(att.Verify(testChainContext, committee))
+	require.ErrorIs(att.Verify(signature.Context("other"), committee), ErrInvalidSignature, "other chain")
+
+	tampered := *att
+	tampered.Message.Amount = nativeUnits(1000)
+	require.ErrorIs(tampered.Verify(testChainContext, committee), ErrInvalidSignature)
+
+	// Attestations are submitted as the bodies of mint and unlock transactions.
+	rt := fakeruntime.New(fakeruntime.Config{})
+	tx := NewMintTx(rt, att).GetTransaction()
+	require.Equal(MethodMint, string(tx.Call.Method))
+	var body Attestation
+	require.NoError(cbor.Unmarshal(tx.Call.Body, &body), "decode body")
+	require.NoError(body.Verify(testChainContext, committee))
+	require.Equal(MethodUnlock, string(NewUnlockTx(rt, att).GetTransaction().Call.Method))
+}
+
+func TestNonceTracker(t *testing.T) {
+	require := require.New(t)
+
+	nt := NewNonceTracker(0)
+	require.NoError(nt.Check(LockNonce(1, 0)))
+	require.NoError(nt.Deliver(LockNonce(1, 0)))
+	require.ErrorIs(nt.Deliver(LockNonce(1, 0)), ErrNonceReplayed)
+	require.NoError(nt.Deliver(LockNonce(3, 2)))
+	require.ErrorIs(nt.Check(LockNonce(2, 0)), ErrNonceReplayed, "older nonces should be rejected")
+	require.Equal(LockNonce(3, 2), nt.Last())
+
+	// A tracker restored from the last delivered nonce should reject the same nonces.
+	require.ErrorIs(NewNonceTracker(nt.Last()).Check(LockNonce(3, 2)), ErrNonceReplayed)
+}
@@ -0,0 +1,61 @@
+package bridge
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNonceReplayed is the error returned when a message with an already delivered nonce is
+// delivered again.
+var ErrNonceReplayed = errors.New("bridge: nonce replayed")
+
+// NonceTracker tracks the nonces of the messages delivered from a source runtime, so that each
+// message is delivered at most once.
+//
+// Messages must be delivered in the order of their nonces, so only the last delivered nonce needs
+// to be persisted, e.g. in the transaction of a processor handling the locks.
+type NonceTracker struct {
+	mu   sync.Mutex
+	last uint64
+}
+
+// Last returns the last delivered nonce, or zero if no message has been delivered yet.
+func (t *NonceTracker) Last() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.last
+}
+
+// Check returns ErrNonceReplayed in case the message with the given nonce has already been
+// delivered.
+func (t *NonceTracker) Check(nonce uint64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.check(nonce)
+}
+
+func (t *NonceTracker) check(nonce uint64) error {
+	if nonce <= t.last {
+		return fmt.Errorf("%w: nonce %d, last delivered %d", ErrNonceReplayed, nonce, t.last)
+	}
+	return nil
+}
+
+// Deliver records the message with the given nonce as delivered, returning ErrNonceReplayed in
+// case it has already been delivered.
+func (t *NonceTracker) Deliver(nonce uint64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.check(nonce); err != nil {
+		return err
+	}
+	t.last = nonce
+	return nil
+}
+
+// NewNonceTracker creates a new tracker of nonces, given the last delivered nonce.
+func NewNonceTracker(last uint64) *NonceTracker {
+	return &NonceTracker{last: last}
+}
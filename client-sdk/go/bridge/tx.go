@@ -0,0 +1,26 @@
+package bridge
+
+import (
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+)
+
+const (
+	// MethodMint is the method of the bridge module of the destination runtime minting the tokens
+	// released by an attestation.
+	MethodMint = "bridge.Mint"
+	// MethodUnlock is the method of the bridge module of the source runtime unlocking the escrowed
+	// tokens released by an attestation.
+	MethodUnlock = "bridge.Unlock"
+)
+
+// NewMintTx creates a new transaction minting the tokens released by the given attestation. The
+// transaction can be signed by any account paying its fees.
+func NewMintTx(rc client.RuntimeClient, att *Attestation) *client.TransactionBuilder {
+	return client.NewTransactionBuilder(rc, MethodMint, att)
+}
+
+// NewUnlockTx creates a new transaction unlocking the escrowed tokens released by the given
+// attestation. The transaction can be signed by any account paying its fees.
+func NewUnlockTx(rc client.RuntimeClient, att *Attestation) *client.TransactionBuilder {
+	return client.NewTransactionBuilder(rc, MethodUnlock, att)
+}
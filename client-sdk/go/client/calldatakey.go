@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// DefaultCallDataPublicKeyTTL is the default amount of time a fetched call data public key is
+// considered valid before it is refreshed.
+const DefaultCallDataPublicKeyTTL = 10 * time.Minute
+
+// CallDataPublicKeyCache fetches, verifies and caches the runtime's call data public key used for
+// confidential transactions.
+//
+// Key manager public keys do not carry an expiration, so cached keys are refreshed after a fixed
+// TTL or when the cache is explicitly invalidated (e.g., after a failure to open a result which
+// indicates that the key has been rotated).
+type CallDataPublicKeyCache struct {
+	l sync.Mutex
+
+	rc             RuntimeClient
+	ttl            time.Duration
+	trustedSigners []signature.PublicKey
+
+	pk     *types.SignedPublicKey
+	expiry time.Time
+}
+
+// Get returns the current call data public key, fetching it from the runtime if the cached key is
+// missing or expired.
+func (c *CallDataPublicKeyCache) Get(ctx context.Context) (*types.SignedPublicKey, error) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if c.pk != nil && time.Now().Before(c.expiry) {
+		return c.pk, nil
+	}
+
+	var rsp callDataPublicKeyQueryResponse
+	if err := c.rc.Query(ctx, RoundLatest, methodCallDataPublicKey, nil, &rsp); err != nil {
+		return nil, fmt.Errorf("failed to query call data public key: %w", err)
+	}
+	if err := c.verify(&rsp.PublicKey); err != nil {
+		return nil, err
+	}
+
+	c.pk = &rsp.PublicKey
+	c.expiry = time.Now().Add(c.ttl)
+	return c.pk, nil
+}
+
+// Invalidate drops the cached key so that the next call to Get fetches a fresh one.
+func (c *CallDataPublicKeyCache) Invalidate() {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.pk = nil
+}
+
+func (c *CallDataPublicKeyCache) verify(pk *types.SignedPublicKey) error {
+	if len(c.trustedSigners) == 0 {
+		// No trusted signers configured, so we trust the node we are connected to.
+		return nil
+	}
+	for _, signer := range c.trustedSigners {
+		if pk.Verify(signer) {
+			return nil
+		}
+	}
+	return fmt.Errorf("call data public key not signed by a trusted key manager")
+}
+
+// NewCallDataPublicKeyCache creates a new call data public key cache.
+//
+// If any trusted signers are given, fetched keys must be signed by one of them. Otherwise keys
+// returned by the node are accepted as-is. A zero TTL selects DefaultCallDataPublicKeyTTL.
+func NewCallDataPublicKeyCache(rc RuntimeClient, ttl time.Duration, trustedSigners ...signature.PublicKey) *CallDataPublicKeyCache {
+	if ttl == 0 {
+		ttl = DefaultCallDataPublicKeyTTL
+	}
+	return &CallDataPublicKeyCache{
+		rc:             rc,
+		ttl:            ttl,
+		trustedSigners: trustedSigners,
+	}
+}
@@ -16,6 +16,13 @@ import (
 
 const methodCallDataPublicKey = "core.CallDataPublicKey"
 
+// Core module error returned by the runtime in case it fails to process the call format (e.g.,
+// when the call was encrypted to a key other than the current call data public key).
+const (
+	errCoreModuleName        = "core"
+	errCoreInvalidCallFormat = 18
+)
+
 type callDataPublicKeyQueryResponse struct {
 	PublicKey types.SignedPublicKey `json:"public_key"`
 }
@@ -25,6 +32,23 @@ type metaEncryptedX25519DeoxysII struct {
 	sk *[32]byte
 	// pk is the current calldata X25519 public key.
 	pk *[32]byte
+	// pkCache is the cache the public key was obtained from.
+	pkCache *CallDataPublicKeyCache
+}
+
+// callDataPublicKeyCache returns the call data public key cache that should be used by the
+// transaction builder.
+func (tb *TransactionBuilder) callDataPublicKeyCache() *CallDataPublicKeyCache {
+	if tb.pkCache != nil {
+		return tb.pkCache
+	}
+	if p, ok := tb.rc.(interface {
+		callDataPublicKeyCache() *CallDataPublicKeyCache
+	}); ok {
+		return p.callDataPublicKeyCache()
+	}
+	// Other runtime client implementations get a fresh cache each time.
+	return NewCallDataPublicKeyCache(tb.rc, 0)
 }
 
 // encodeCall performs call encoding based on the specified call format.
@@ -49,15 +73,15 @@ func (tb *TransactionBuilder) encodeCall(ctx context.Context, call *types.Call)
 		}
 
 		// Obtain current calldata X25519 public key.
-		var rsp callDataPublicKeyQueryResponse
-		if err := tb.rc.Query(ctx, RoundLatest, methodCallDataPublicKey, nil, &rsp); err != nil {
-			return nil, nil, fmt.Errorf("callformat: failed to query calldata X25519 public key: %w", err)
+		pkCache := tb.callDataPublicKeyCache()
+		callDataPk, err := pkCache.Get(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("callformat: failed to obtain calldata X25519 public key: %w", err)
 		}
-		// TODO: In case the node we are connecting to is not trusted, validate the key manager signature.
 
 		// Seal serialized plain call.
 		rawCall := cbor.Marshal(call)
-		sealedCall := mraeDeoxysii.Box.Seal(nil, nonce[:], rawCall, nil, &rsp.PublicKey.PublicKey, sk)
+		sealedCall := mraeDeoxysii.Box.Seal(nil, nonce[:], rawCall, nil, &callDataPk.PublicKey, sk)
 
 		encoded := &types.Call{
 			Format: call.Format,
@@ -69,8 +93,9 @@ func (tb *TransactionBuilder) encodeCall(ctx context.Context, call *types.Call)
 			}),
 		}
 		meta := &metaEncryptedX25519DeoxysII{
-			sk: sk,
-			pk: &rsp.PublicKey.PublicKey,
+			sk:      sk,
+			pk:      &callDataPk.PublicKey,
+			pkCache: pkCache,
 		}
 		return encoded, meta, nil
 	default:
@@ -93,6 +118,11 @@ func (tb *TransactionBuilder) decodeResult(result *types.CallResult, meta interf
 			return nil, fmt.Errorf("callformat: unexpected result: %s", base64.StdEncoding.EncodeToString(result.Ok))
 		default:
 			// Submission could fail before call format processing so the result would be plain.
+			if result.Failed.Module == errCoreModuleName && result.Failed.Code == errCoreInvalidCallFormat {
+				// The runtime failed to open the call, which indicates that the key has been
+				// rotated, make sure it is refreshed for future calls.
+				m.pkCache.Invalidate()
+			}
 			return nil, result.Failed
 		}
 
@@ -107,6 +137,8 @@ func (tb *TransactionBuilder) decodeResult(result *types.CallResult, meta interf
 			err error
 		)
 		if pt, err = mraeDeoxysii.Box.Open(nil, envelope.Nonce[:], envelope.Data, nil, m.pk, m.sk); err != nil {
+			// The key may have been rotated, make sure it is refreshed for future calls.
+			m.pkCache.Invalidate()
 			return nil, fmt.Errorf("callformat: failed to open result envelope: %w", err)
 		}
 
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func TestDecodeResultInvalidatesKey(t *testing.T) {
+	require := require.New(t)
+
+	for _, tc := range []struct {
+		failed      *types.FailedCallResult
+		invalidated bool
+	}{
+		{&types.FailedCallResult{Module: "core", Code: errCoreInvalidCallFormat, Message: "invalid call format"}, true},
+		{&types.FailedCallResult{Module: "core", Code: 5, Message: "insufficient balance to pay fees"}, false},
+		{&types.FailedCallResult{Module: "accounts", Code: errCoreInvalidCallFormat}, false},
+	} {
+		pk := &types.SignedPublicKey{PublicKey: [32]byte{1, 2, 3}}
+		cache := NewCallDataPublicKeyCache(nil, 0)
+		cache.pk = pk
+		cache.expiry = time.Now().Add(time.Hour)
+
+		var tb TransactionBuilder
+		_, err := tb.decodeResult(&types.CallResult{Failed: tc.failed}, &metaEncryptedX25519DeoxysII{
+			pk:      &pk.PublicKey,
+			pkCache: cache,
+		})
+		require.Equal(tc.failed, err, "failed results should be passed through")
+		if tc.invalidated {
+			require.Nil(cache.pk, "key should be invalidated for %s", tc.failed)
+		} else {
+			require.Equal(pk, cache.pk, "key should be kept for %s", tc.failed)
+		}
+	}
+}
@@ -2,18 +2,23 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"sync"
 
 	"google.golang.org/grpc"
 
 	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	cmnErrors "github.com/oasisprotocol/oasis-core/go/common/errors"
 	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
-	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	control "github.com/oasisprotocol/oasis-core/go/control/api"
 	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
 	coreClient "github.com/oasisprotocol/oasis-core/go/runtime/client/api"
 
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client/consensus"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
 )
@@ -58,6 +63,10 @@ type RuntimeClient interface {
 	// not wait for transaction execution.
 	SubmitTxNoWait(ctx context.Context, tx *types.UnverifiedTransaction) error
 
+	// CheckTx asks the runtime to check the given transaction without submitting it for
+	// execution.
+	CheckTx(ctx context.Context, tx *types.UnverifiedTransaction) error
+
 	// GetGenesisBlock returns the genesis block.
 	GetGenesisBlock(ctx context.Context) (*block.Block, error)
 
@@ -77,6 +86,20 @@ type RuntimeClient interface {
 	// GetEvents returns and decodes events emitted in a given block with the provided decoders.
 	GetEvents(ctx context.Context, round uint64, decoders []EventDecoder, includeUndecoded bool) ([]DecodedEvent, error)
 
+	// GetEventsRange returns the events emitted in the blocks from the start round to the end
+	// round, inclusive, that match the given filter (all events if nil). Rounds without matching
+	// events are omitted from the result, which is ordered by round.
+	GetEventsRange(ctx context.Context, startRound, endRound uint64, filter *EventFilter) ([]*RoundEvents, error)
+
+	// GetMessageResults returns the results of executing the consensus messages emitted by the
+	// runtime in the given round, ordered by message index.
+	GetMessageResults(ctx context.Context, round uint64) ([]*MessageResult, error)
+
+	// GetStatus returns the status of the runtime as seen by the node the client is connected to.
+	//
+	// This requires access to the node's control API.
+	GetStatus(ctx context.Context) (*RuntimeStatus, error)
+
 	// WatchBlocks subscribes to blocks for a specific runtimes.
 	WatchBlocks(ctx context.Context) (<-chan *roothash.AnnotatedBlock, pubsub.ClosableSubscription, error)
 
@@ -105,6 +128,76 @@ type BlockEvents struct {
 	Events []DecodedEvent
 }
 
+// MaxEventsRange is the maximum number of rounds that can be requested by GetEventsRange.
+const MaxEventsRange = 1000
+
+// eventsRangeBatchSize is the number of rounds whose events are requested from the node at once.
+const eventsRangeBatchSize = 16
+
+// EventFilter selects events by the module that emitted them and their code.
+type EventFilter struct {
+	// Module is the name of the module whose events are selected. Events of all modules are
+	// selected if empty.
+	Module string
+
+	// Codes are the codes of the selected events. Events with any code are selected if empty.
+	Codes []uint32
+}
+
+// Matches returns true iff the event is selected by the filter. A nil filter selects all events.
+func (f *EventFilter) Matches(ev *types.Event) bool {
+	if f == nil {
+		return true
+	}
+	if f.Module != "" && f.Module != ev.Module {
+		return false
+	}
+	if len(f.Codes) == 0 {
+		return true
+	}
+	for _, code := range f.Codes {
+		if code == ev.Code {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterEvents returns the events selected by the filter.
+func (f *EventFilter) FilterEvents(evs []*types.Event) []*types.Event {
+	if f == nil {
+		return evs
+	}
+	var filtered []*types.Event
+	for _, ev := range evs {
+		if f.Matches(ev) {
+			filtered = append(filtered, ev)
+		}
+	}
+	return filtered
+}
+
+// RoundEvents are the raw events emitted in a round.
+type RoundEvents struct {
+	// Round is the round of the block.
+	Round uint64
+
+	// Events are the events emitted in the block.
+	Events []*types.Event
+}
+
+// ValidateEventsRange checks that the given range of rounds can be requested by GetEventsRange.
+func ValidateEventsRange(startRound, endRound uint64) error {
+	switch {
+	case startRound > endRound:
+		return fmt.Errorf("client: start round %d is after end round %d", startRound, endRound)
+	case endRound-startRound >= MaxEventsRange:
+		return fmt.Errorf("client: range of rounds exceeds %d rounds", MaxEventsRange)
+	default:
+		return nil
+	}
+}
+
 // TransactionMeta are the metadata about transaction execution.
 type TransactionMeta struct {
 	// Round is the roothash round in which the transaction was executed.
@@ -139,6 +232,43 @@ type SubmitTxMeta struct {
 	Result cbor.RawMessage
 }
 
+// RuntimeStatus is the status of the runtime as seen by a node.
+type RuntimeStatus struct {
+	// GenesisRound is the round of the genesis runtime block.
+	GenesisRound uint64
+	// LastRetainedRound is the earliest round for which the node still has block history.
+	LastRetainedRound uint64
+	// LatestRound is the round of the latest runtime block seen by the node.
+	LatestRound uint64
+	// StorageSynced is true when the node's storage has finalized the latest round. Nodes that do
+	// not run a storage worker fetch state from storage nodes and are always considered synced.
+	StorageSynced bool
+}
+
+// MessageResult is the result of executing a consensus message emitted by the runtime.
+type MessageResult struct {
+	// Index is the index of the message among the messages emitted in the runtime round.
+	Index uint32
+	// Module is the consensus module that returned the result code.
+	Module string
+	// Code is the result code.
+	Code uint32
+}
+
+// IsSuccess returns true if the message was successfully executed.
+func (mr *MessageResult) IsSuccess() bool {
+	return mr.Code == cmnErrors.CodeNoError
+}
+
+// Error returns the consensus error that caused the message to fail or nil if the message was
+// successfully executed.
+func (mr *MessageResult) Error() error {
+	if mr.IsSuccess() {
+		return nil
+	}
+	return cmnErrors.FromCode(mr.Module, mr.Code, "")
+}
+
 // TransactionWithResults is an SDK transaction together with its results and emitted events.
 type TransactionWithResults struct {
 	Tx     types.UnverifiedTransaction
@@ -147,11 +277,18 @@ type TransactionWithResults struct {
 }
 
 type runtimeClient struct {
-	cs consensus.ClientBackend
-	cc coreClient.RuntimeClient
+	cons consensus.Client
+	cc   coreClient.RuntimeClient
+	nc   control.NodeController
 
 	runtimeID   common.Namespace
 	runtimeInfo *types.RuntimeInfo
+
+	pkCache *CallDataPublicKeyCache
+}
+
+func (rc *runtimeClient) callDataPublicKeyCache() *CallDataPublicKeyCache {
+	return rc.pkCache
 }
 
 // Implements RuntimeClient.
@@ -160,7 +297,7 @@ func (rc *runtimeClient) GetInfo(ctx context.Context) (*types.RuntimeInfo, error
 		return rc.runtimeInfo, nil
 	}
 
-	chainCtx, err := rc.cs.GetChainContext(ctx)
+	chainCtx, err := rc.cons.ChainContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch consensus layer chain context: %w", err)
 	}
@@ -274,6 +411,14 @@ func (rc *runtimeClient) SubmitTxNoWait(ctx context.Context, tx *types.Unverifie
 	})
 }
 
+// Implements RuntimeClient.
+func (rc *runtimeClient) CheckTx(ctx context.Context, tx *types.UnverifiedTransaction) error {
+	return rc.cc.CheckTx(ctx, &coreClient.CheckTxRequest{
+		RuntimeID: rc.runtimeID,
+		Data:      cbor.Marshal(tx),
+	})
+}
+
 // Implements RuntimeClient.
 func (rc *runtimeClient) WatchBlocks(ctx context.Context) (<-chan *roothash.AnnotatedBlock, pubsub.ClosableSubscription, error) {
 	return rc.cc.WatchBlocks(ctx, rc.runtimeID)
@@ -302,11 +447,12 @@ func (rc *runtimeClient) GetTransactions(ctx context.Context, round uint64) ([]*
 		return nil, err
 	}
 
+	// Allocate all transactions at once instead of one by one.
 	txs := make([]*types.UnverifiedTransaction, len(rawTxs))
+	txsData := make([]types.UnverifiedTransaction, len(rawTxs))
 	for i, rawTx := range rawTxs {
-		var tx types.UnverifiedTransaction
-		_ = cbor.Unmarshal(rawTx, &tx) // Ignore errors as there can be invalid transactions.
-		txs[i] = &tx
+		_ = cbor.Unmarshal(rawTx, &txsData[i]) // Ignore errors as there can be invalid transactions.
+		txs[i] = &txsData[i]
 	}
 	return txs, nil
 }
@@ -320,25 +466,42 @@ func (rc *runtimeClient) GetTransactionsWithResults(ctx context.Context, round u
 	if err != nil {
 		return nil, err
 	}
+	return decodeTransactionsWithResults(rawTxs), nil
+}
 
+// decodeTransactionsWithResults decodes the given transactions with results, allocating all
+// transactions and events at once instead of one by one.
+func decodeTransactionsWithResults(rawTxs []*coreClient.TransactionWithResults) []*TransactionWithResults {
+	var numEvents int
+	for _, raw := range rawTxs {
+		numEvents += len(raw.Events)
+	}
 	txs := make([]*TransactionWithResults, len(rawTxs))
+	txsData := make([]TransactionWithResults, len(rawTxs))
+	evsData := make([]types.Event, numEvents)
 	for i, raw := range rawTxs {
-		var tx TransactionWithResults
+		tx := &txsData[i]
 		_ = cbor.Unmarshal(raw.Tx, &tx.Tx) // Ignore errors as there can be invalid transactions.
 		_ = cbor.Unmarshal(raw.Result, &tx.Result)
 
+		if len(raw.Events) > 0 {
+			tx.Events = make([]*types.Event, 0, len(raw.Events))
+		}
 		for _, rawEv := range raw.Events {
-			var ev types.Event
+			ev := &evsData[0]
 			if err := ev.UnmarshalRaw(rawEv.Key, rawEv.Value); err != nil {
+				// The slot is reused for the next event, so it must not retain anything.
+				*ev = types.Event{}
 				continue
 			}
+			evsData = evsData[1:]
 
-			tx.Events = append(tx.Events, &ev)
+			tx.Events = append(tx.Events, ev)
 		}
 
-		txs[i] = &tx
+		txs[i] = tx
 	}
-	return txs, nil
+	return txs
 }
 
 // Implements RuntimeClient.
@@ -352,12 +515,12 @@ func (rc *runtimeClient) GetEventsRaw(ctx context.Context, round uint64) ([]*typ
 	}
 
 	evs := make([]*types.Event, len(rawEvs))
+	evsData := make([]types.Event, len(rawEvs))
 	for i, rawEv := range rawEvs {
-		var ev types.Event
-		if err := ev.UnmarshalRaw(rawEv.Key, rawEv.Value); err != nil {
+		if err := evsData[i].UnmarshalRaw(rawEv.Key, rawEv.Value); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal event '%v': %w", rawEv, err)
 		}
-		evs[i] = &ev
+		evs[i] = &evsData[i]
 	}
 
 	return evs, nil
@@ -374,23 +537,18 @@ func (rc *runtimeClient) GetEvents(ctx context.Context, round uint64, decoders [
 	}
 
 	evs := make([]DecodedEvent, 0)
-OUTER:
 	for _, rawEv := range rawEvs {
 		var ev types.Event
 		if err := ev.UnmarshalRaw(rawEv.Key, rawEv.Value); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal event '%v': %w", rawEv, err)
 		}
-		for _, decoder := range decoders {
-			decoded, err := decoder.DecodeEvent(&ev)
-			if err != nil {
-				return nil, fmt.Errorf("failed to decode event: %w", err)
-			}
-			if decoded != nil {
-				evs = append(evs, decoded)
-				continue OUTER
-			}
-		}
-		if includeUndecoded {
+		decoded, err := decodeEvent(&ev, decoders)
+		switch {
+		case err != nil:
+			return nil, err
+		case decoded != nil:
+			evs = append(evs, decoded)
+		case includeUndecoded:
 			evs = append(evs, &ev)
 		}
 	}
@@ -398,6 +556,53 @@ OUTER:
 	return evs, nil
 }
 
+// Implements RuntimeClient.
+func (rc *runtimeClient) GetEventsRange(ctx context.Context, startRound, endRound uint64, filter *EventFilter) ([]*RoundEvents, error) {
+	if err := ValidateEventsRange(startRound, endRound); err != nil {
+		return nil, err
+	}
+
+	// Request the events of a batch of rounds concurrently so that the latency of the node is only
+	// paid once per batch.
+	var result []*RoundEvents
+	for first := startRound; ; first += eventsRangeBatchSize {
+		last := first + eventsRangeBatchSize - 1
+		if last > endRound || last < first {
+			last = endRound
+		}
+
+		batch := make([]RoundEvents, last-first+1)
+		errs := make([]error, len(batch))
+		var wg sync.WaitGroup
+		for i := range batch {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				round := first + uint64(i)
+				evs, err := rc.GetEventsRaw(ctx, round)
+				if err != nil {
+					errs[i] = fmt.Errorf("client: failed to get events of round %d: %w", round, err)
+					return
+				}
+				batch[i] = RoundEvents{Round: round, Events: filter.FilterEvents(evs)}
+			}(i)
+		}
+		wg.Wait()
+
+		for i := range batch {
+			if errs[i] != nil {
+				return nil, errs[i]
+			}
+			if len(batch[i].Events) > 0 {
+				result = append(result, &batch[i])
+			}
+		}
+		if last == endRound {
+			return result, nil
+		}
+	}
+}
+
 // Implements RuntimeClient.
 func (rc *runtimeClient) WatchEvents(ctx context.Context, decoders []EventDecoder, includeUndecoded bool) (<-chan *BlockEvents, error) {
 	ch := make(chan *BlockEvents)
@@ -435,6 +640,89 @@ func (rc *runtimeClient) WatchEvents(ctx context.Context, decoders []EventDecode
 	return ch, nil
 }
 
+// Implements RuntimeClient.
+func (rc *runtimeClient) GetMessageResults(ctx context.Context, round uint64) ([]*MessageResult, error) {
+	blk, err := rc.GetBlock(ctx, round)
+	if err != nil {
+		return nil, err
+	}
+	if blk.Header.MessagesHash.IsEmpty() {
+		// No messages were emitted in this round.
+		return nil, nil
+	}
+
+	height, err := rc.cons.FinalizationHeight(ctx, rc.runtimeID, round)
+	if err != nil {
+		return nil, err
+	}
+	evs, err := rc.cons.Backend().RootHash().GetEvents(ctx, height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roothash events at height %d: %w", height, err)
+	}
+
+	var results []*MessageResult
+	for _, ev := range evs {
+		if ev.Message == nil || !ev.RuntimeID.Equal(&rc.runtimeID) {
+			continue
+		}
+		results = append(results, &MessageResult{
+			Index:  ev.Message.Index,
+			Module: ev.Message.Module,
+			Code:   ev.Message.Code,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Index < results[j].Index })
+	return results, nil
+}
+
+// Implements RuntimeClient.
+func (rc *runtimeClient) GetStatus(ctx context.Context) (*RuntimeStatus, error) {
+	nodeStatus, err := rc.nc.GetStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node status: %w", err)
+	}
+	rtStatus, ok := nodeStatus.Runtimes[rc.runtimeID]
+	if !ok {
+		return nil, fmt.Errorf("runtime %s not supported by node", rc.runtimeID)
+	}
+
+	lastRetained, err := rc.findLastRetainedRound(ctx, rtStatus.GenesisRound, rtStatus.LatestRound)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &RuntimeStatus{
+		GenesisRound:      rtStatus.GenesisRound,
+		LastRetainedRound: lastRetained,
+		LatestRound:       rtStatus.LatestRound,
+		StorageSynced:     true,
+	}
+	if rtStatus.Storage != nil {
+		status.StorageSynced = rtStatus.Storage.LastFinalizedRound >= rtStatus.LatestRound
+	}
+	return status, nil
+}
+
+// findLastRetainedRound returns the earliest round between genesis and latest for which the node
+// still has the runtime block.
+func (rc *runtimeClient) findLastRetainedRound(ctx context.Context, genesis, latest uint64) (uint64, error) {
+	lo, hi := genesis, latest
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		_, err := rc.GetBlock(ctx, mid)
+		switch {
+		case err == nil:
+			hi = mid
+		case errors.Is(err, roothash.ErrNotFound):
+			// The block has already been pruned.
+			lo = mid + 1
+		default:
+			return 0, fmt.Errorf("failed to get runtime block %d: %w", mid, err)
+		}
+	}
+	return lo, nil
+}
+
 // Implements RuntimeClient.
 func (rc *runtimeClient) Query(ctx context.Context, round uint64, method string, args, rsp interface{}) error {
 	raw, err := rc.cc.Query(ctx, &coreClient.QueryRequest{
@@ -447,18 +735,71 @@ func (rc *runtimeClient) Query(ctx context.Context, round uint64, method string,
 		return err
 	}
 	if rsp != nil {
-		if err = cbor.Unmarshal(raw.Data, rsp); err != nil {
+		if err = unmarshalQueryResult(raw.Data, rsp); err != nil {
 			return fmt.Errorf("failed to unmarshal response: %w", err)
 		}
 	}
 	return nil
 }
 
+// unmarshalQueryResult decodes the CBOR-encoded query result into the given response. As the result
+// is not used otherwise, byte strings, like the results of EVM queries, refer to the result instead
+// of copying it.
+func unmarshalQueryResult(data []byte, rsp interface{}) error {
+	if b, ok := rsp.(*[]byte); ok {
+		content, isBytes, err := cborByteString(data)
+		if err != nil {
+			return err
+		}
+		if isBytes {
+			*b = content
+			return nil
+		}
+	}
+	return cbor.Unmarshal(data, rsp)
+}
+
+// cborByteString returns the content of the given CBOR-encoded byte string without copying it,
+// or false if the data does not start with a definite-length byte string.
+func cborByteString(data []byte) ([]byte, bool, error) {
+	// Major type 2 with the length in the additional information or in the following 1-8 bytes.
+	if len(data) == 0 || data[0]>>5 != 2 || data[0]&0x1f > 27 {
+		return nil, false, nil
+	}
+	var (
+		length uint64
+		off    = 1
+	)
+	switch info := data[0] & 0x1f; {
+	case info < 24:
+		length = uint64(info)
+	default:
+		size := 1 << (info - 24)
+		if len(data) < off+size {
+			return nil, true, fmt.Errorf("client: truncated CBOR byte string")
+		}
+		for _, v := range data[off : off+size] {
+			length = length<<8 | uint64(v)
+		}
+		off += size
+	}
+	switch {
+	case length > uint64(len(data)-off):
+		return nil, true, fmt.Errorf("client: truncated CBOR byte string")
+	case length < uint64(len(data)-off):
+		return nil, true, fmt.Errorf("client: trailing data after CBOR byte string")
+	}
+	return data[off:len(data):len(data)], true, nil
+}
+
 // New creates a new runtime client for the specified runtime.
 func New(conn *grpc.ClientConn, runtimeID common.Namespace) RuntimeClient {
-	return &runtimeClient{
-		cs:        consensus.NewConsensusClient(conn),
+	rc := &runtimeClient{
+		cons:      consensus.New(conn),
 		cc:        coreClient.NewRuntimeClient(conn),
+		nc:        control.NewNodeControllerClient(conn),
 		runtimeID: runtimeID,
 	}
+	rc.pkCache = NewCallDataPublicKeyCache(rc, 0)
+	return rc
 }
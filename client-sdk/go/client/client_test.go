@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	coreClient "github.com/oasisprotocol/oasis-core/go/runtime/client/api"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// staticCoreClient is a runtime client of a node answering all queries with the same result.
+type staticCoreClient struct {
+	coreClient.RuntimeClient
+
+	result []byte
+}
+
+func (c *staticCoreClient) Query(ctx context.Context, request *coreClient.QueryRequest) (*coreClient.QueryResponse, error) {
+	return &coreClient.QueryResponse{Data: c.result}, nil
+}
+
+func TestUnmarshalQueryResult(t *testing.T) {
+	require := require.New(t)
+
+	value := []byte("value")
+	data := cbor.Marshal(value)
+
+	var b []byte
+	require.NoError(unmarshalQueryResult(data, &b))
+	require.Equal(value, b)
+	require.Equal(&data[1], &b[0], "byte strings should refer to the result")
+	require.Equal(len(b), cap(b), "appending to byte strings should not overwrite the result")
+
+	require.NoError(unmarshalQueryResult(cbor.Marshal([]byte{}), &b))
+	require.NotNil(b)
+	require.Empty(b)
+
+	require.NoError(unmarshalQueryResult(cbor.Marshal(nil), &b))
+	require.Nil(b, "null should be decoded as a nil byte string")
+
+	require.Error(unmarshalQueryResult(data[:len(data)-1], &b), "truncated results should be rejected")
+	require.Error(unmarshalQueryResult(append(data[:len(data):len(data)], 0x00), &b), "trailing data should be rejected")
+	require.Error(unmarshalQueryResult(cbor.Marshal("text"), &b), "other types should be rejected")
+
+	long := make([]byte, 300)
+	require.NoError(unmarshalQueryResult(cbor.Marshal(long), &b))
+	require.Equal(long, b)
+	require.Error(unmarshalQueryResult(cbor.Marshal(long)[:2], &b), "truncated lengths should be rejected")
+
+	var raw cbor.RawMessage
+	require.NoError(unmarshalQueryResult(data, &raw))
+	require.Equal(cbor.RawMessage(data), raw)
+	require.Error(unmarshalQueryResult(data[:len(data)-1], &raw), "truncated results should be rejected")
+
+	var s string
+	require.NoError(unmarshalQueryResult(cbor.Marshal("text"), &s))
+	require.Equal("text", s)
+}
+
+func BenchmarkQueryBytes(b *testing.B) {
+	// Arguments and result of an EVM call simulation.
+	type simulateCallQuery struct {
+		GasPrice []byte `json:"gas_price"`
+		GasLimit uint64 `json:"gas_limit"`
+		Caller   []byte `json:"caller"`
+		Address  []byte `json:"address"`
+		Value    []byte `json:"value"`
+		Data     []byte `json:"data"`
+	}
+	q := &simulateCallQuery{
+		GasPrice: make([]byte, 32),
+		GasLimit: 100_000,
+		Caller:   make([]byte, 20),
+		Address:  make([]byte, 20),
+		Value:    make([]byte, 32),
+		Data:     make([]byte, 68),
+	}
+	rc := &runtimeClient{cc: &staticCoreClient{result: cbor.Marshal(make([]byte, 4096))}}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var out []byte
+		if err := rc.Query(ctx, RoundLatest, "evm.SimulateCall", q, &out); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestDecodeTransactionsWithResults(t *testing.T) {
+	require := require.New(t)
+
+	event := func(key string, value string) *coreClient.PlainEvent {
+		return &coreClient.PlainEvent{Key: []byte(key), Value: []byte(value)}
+	}
+	rawTxs := []*coreClient.TransactionWithResults{
+		{Events: []*coreClient.PlainEvent{
+			event("test\x00\x00\x00\x01", "first"),
+			event("bad", "malformed"),
+			event("test\x00\x00\x00\x02", "second"),
+		}},
+		{},
+		{Events: []*coreClient.PlainEvent{
+			event("bad", "malformed"),
+		}},
+		{Events: []*coreClient.PlainEvent{
+			event("other\x00\x00\x00\x03", "third"),
+		}},
+	}
+
+	txs := decodeTransactionsWithResults(rawTxs)
+	require.Len(txs, len(rawTxs))
+	require.Equal([]*types.Event{
+		{Module: "test", Code: 1, Value: []byte("first")},
+		{Module: "test", Code: 2, Value: []byte("second")},
+	}, txs[0].Events, "malformed events should be skipped")
+	require.Empty(txs[1].Events)
+	require.Empty(txs[2].Events)
+	require.Equal([]*types.Event{
+		{Module: "other", Code: 3, Value: []byte("third")},
+	}, txs[3].Events)
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+)
+
+// coalescedQuery is an in-flight query shared by concurrent callers.
+type coalescedQuery struct {
+	done chan struct{}
+
+	result cbor.RawMessage
+	err    error
+}
+
+// QueryCoalescer is a runtime client deduplicating identical in-flight runtime-specific queries, so
+// that bursts of the same query, e.g. from concurrent web handlers, only reach the node once.
+//
+// Queries are identical if they have the same method, arguments and round. A query made while an
+// identical query is in flight waits for and shares its result, which is decoded separately for
+// each caller. Results are not kept once the query completes, see QueryCache for caching.
+//
+// All other methods are passed through to the wrapped client.
+type QueryCoalescer struct {
+	RuntimeClient
+
+	l        sync.Mutex
+	inFlight map[queryCacheKey]*coalescedQuery
+
+	pkCache *CallDataPublicKeyCache
+}
+
+func (c *QueryCoalescer) callDataPublicKeyCache() *CallDataPublicKeyCache {
+	return c.pkCache
+}
+
+// Implements RuntimeClient.
+func (c *QueryCoalescer) Query(ctx context.Context, round uint64, method string, args, rsp interface{}) error {
+	key := queryCacheKey{
+		method: method,
+		args:   string(cbor.Marshal(args)),
+		round:  round,
+	}
+
+	c.l.Lock()
+	q, ok := c.inFlight[key]
+	if !ok {
+		q = &coalescedQuery{done: make(chan struct{})}
+		c.inFlight[key] = q
+	}
+	c.l.Unlock()
+
+	if !ok {
+		q.err = c.RuntimeClient.Query(ctx, round, method, args, &q.result)
+
+		c.l.Lock()
+		delete(c.inFlight, key)
+		c.l.Unlock()
+		close(q.done)
+	} else {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-q.done:
+		}
+		if isContextError(q.err) && ctx.Err() == nil {
+			// The query was aborted by the context of the caller that made it, which does not
+			// concern the other callers.
+			return c.Query(ctx, round, method, args, rsp)
+		}
+	}
+
+	if q.err != nil {
+		return q.err
+	}
+	if rsp != nil {
+		if err := cbor.Unmarshal(q.result, rsp); err != nil {
+			return fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+	}
+	return nil
+}
+
+// InFlight returns the number of distinct queries in flight.
+func (c *QueryCoalescer) InFlight() int {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	return len(c.inFlight)
+}
+
+// isContextError returns true iff the given error is caused by a canceled or expired context,
+// including the gRPC status errors returned by queries over a connection.
+func isContextError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Canceled, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewQueryCoalescer creates a new runtime client deduplicating the identical in-flight queries
+// made through the given client.
+func NewQueryCoalescer(rc RuntimeClient) *QueryCoalescer {
+	c := &QueryCoalescer{
+		RuntimeClient: rc,
+		inFlight:      make(map[queryCacheKey]*coalescedQuery),
+	}
+	c.pkCache = sharedCallDataPublicKeyCache(c, rc)
+	return c
+}
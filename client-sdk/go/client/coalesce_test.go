@@ -0,0 +1,156 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+)
+
+// blockingQueryClient is a runtime client answering queries with the number of queries made, once
+// the queries are released.
+type blockingQueryClient struct {
+	RuntimeClient
+
+	queries uint64
+	release chan struct{}
+	// grpcErrors makes aborted queries fail with gRPC status errors, like queries over a
+	// connection, instead of the error of the context.
+	grpcErrors bool
+}
+
+func (c *blockingQueryClient) Query(ctx context.Context, round uint64, method string, args, rsp interface{}) error {
+	n := atomic.AddUint64(&c.queries, 1)
+	select {
+	case <-ctx.Done():
+		if c.grpcErrors {
+			return status.Error(codes.Canceled, ctx.Err().Error())
+		}
+		return ctx.Err()
+	case <-c.release:
+	}
+	return cbor.Unmarshal(cbor.Marshal(n), rsp)
+}
+
+func TestQueryCoalescer(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rc := &blockingQueryClient{release: make(chan struct{})}
+	qc := NewQueryCoalescer(rc)
+
+	type result struct {
+		rsp uint64
+		err error
+	}
+	query := func(ctx context.Context, round uint64, method string, args interface{}) <-chan result {
+		ch := make(chan result, 1)
+		go func() {
+			var rsp uint64
+			err := qc.Query(ctx, round, method, args, &rsp)
+			ch <- result{rsp, err}
+		}()
+		return ch
+	}
+	waitQueries := func(n uint64) {
+		require.Eventually(func() bool { return atomic.LoadUint64(&rc.queries) == n }, time.Second, time.Millisecond)
+	}
+
+	// Identical concurrent queries are made once.
+	var chs []<-chan result
+	for i := 0; i < 5; i++ {
+		chs = append(chs, query(ctx, RoundLatest, "test.A", "x"))
+	}
+	waitQueries(1)
+	other := []<-chan result{
+		query(ctx, RoundLatest, "test.A", "y"),
+		query(ctx, 10, "test.A", "x"),
+		query(ctx, RoundLatest, "test.B", "x"),
+	}
+	waitQueries(4)
+	require.Equal(4, qc.InFlight())
+
+	close(rc.release)
+	for _, ch := range chs {
+		res := <-ch
+		require.NoError(res.err, "Query")
+		require.EqualValues(1, res.rsp, "identical queries should share the result")
+	}
+	seen := make(map[uint64]bool)
+	for _, ch := range other {
+		res := <-ch
+		require.NoError(res.err, "Query")
+		seen[res.rsp] = true
+	}
+	require.Len(seen, 3, "different queries should not be coalesced")
+	require.Zero(qc.InFlight())
+
+	// Completed queries are not cached.
+	var rsp uint64
+	require.NoError(qc.Query(ctx, RoundLatest, "test.A", "x", &rsp))
+	require.EqualValues(5, rsp)
+}
+
+func TestQueryCoalescerCanceled(t *testing.T) {
+	t.Run("Context", func(t *testing.T) {
+		testQueryCoalescerCanceled(t, false)
+	})
+	t.Run("gRPC", func(t *testing.T) {
+		testQueryCoalescerCanceled(t, true)
+	})
+}
+
+func testQueryCoalescerCanceled(t *testing.T, grpcErrors bool) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rc := &blockingQueryClient{release: make(chan struct{}), grpcErrors: grpcErrors}
+	qc := NewQueryCoalescer(rc)
+
+	// The caller making the query gives up, which should not fail the waiting callers.
+	leaderCtx, cancel := context.WithCancel(ctx)
+	leaderErrCh := make(chan error, 1)
+	go func() { leaderErrCh <- qc.Query(leaderCtx, RoundLatest, "test.A", "x", nil) }()
+	require.Eventually(func() bool { return atomic.LoadUint64(&rc.queries) == 1 }, time.Second, time.Millisecond)
+
+	var (
+		wg  sync.WaitGroup
+		rsp uint64
+		err error
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		err = qc.Query(ctx, RoundLatest, "test.A", "x", &rsp)
+	}()
+	// Give the second caller time to join the in-flight query.
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	if leaderErr := <-leaderErrCh; grpcErrors {
+		require.Equal(codes.Canceled, status.Code(leaderErr))
+	} else {
+		require.ErrorIs(leaderErr, context.Canceled)
+	}
+	require.Eventually(func() bool { return atomic.LoadUint64(&rc.queries) == 2 }, time.Second, time.Millisecond)
+	close(rc.release)
+	wg.Wait()
+	require.NoError(err, "Query")
+	require.EqualValues(2, rsp, "the query should be made again")
+
+	// Callers giving up while waiting fail with the error of their context.
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	rc.release = make(chan struct{})
+	go func() { _ = qc.Query(ctx, RoundLatest, "test.A", "x", nil) }()
+	require.Eventually(func() bool { return qc.InFlight() == 1 }, time.Second, time.Millisecond)
+	require.ErrorIs(qc.Query(waitCtx, RoundLatest, "test.A", "x", nil), context.DeadlineExceeded)
+	close(rc.release)
+}
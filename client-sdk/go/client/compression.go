@@ -0,0 +1,89 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+// Names of the compressors supported by DialConfig.Compression.
+const (
+	// CompressionGzip is gzip compression, which is supported by all gRPC implementations.
+	CompressionGzip = gzip.Name
+	// CompressionZstd is zstd compression, which compresses better at a lower CPU cost, but is
+	// only supported by nodes that register a zstd compressor.
+	CompressionZstd = "zstd"
+)
+
+// zstdMaxDecoderMemory is the maximum memory used to decode a zstd-compressed message. It matches
+// the maximum message size of the nodes.
+const zstdMaxDecoderMemory = 128 * 1024 * 1024
+
+func init() {
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	if err != nil {
+		panic(err)
+	}
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(zstdMaxDecoderMemory))
+	if err != nil {
+		panic(err)
+	}
+	encoding.RegisterCompressor(&zstdCompressor{enc: enc, dec: dec})
+}
+
+// checkCompression checks that a compressor is registered under the given name.
+func checkCompression(name string) error {
+	if name != "" && encoding.GetCompressor(name) == nil {
+		return fmt.Errorf("client: unsupported compression '%s'", name)
+	}
+	return nil
+}
+
+// zstdCompressor is a gRPC compressor using zstd. Messages are compressed and decompressed as a
+// whole with a single encoder and decoder, which are shared by all messages and live as long as
+// the process, so they never need to be closed.
+type zstdCompressor struct {
+	enc *zstd.Encoder
+	dec *zstd.Decoder
+}
+
+// zstdWriter buffers a message, compressing it when it is closed.
+type zstdWriter struct {
+	bytes.Buffer
+
+	w   io.Writer
+	enc *zstd.Encoder
+}
+
+func (w *zstdWriter) Close() error {
+	_, err := w.w.Write(w.enc.EncodeAll(w.Bytes(), nil))
+	return err
+}
+
+// Implements encoding.Compressor.
+func (c *zstdCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	return &zstdWriter{w: w, enc: c.enc}, nil
+}
+
+// Implements encoding.Compressor.
+func (c *zstdCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	compressed, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.dec.DecodeAll(compressed, nil)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+// Implements encoding.Compressor.
+func (c *zstdCompressor) Name() string {
+	return CompressionZstd
+}
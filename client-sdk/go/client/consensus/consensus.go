@@ -0,0 +1,448 @@
+// Package consensus provides thin wrappers around the consensus layer APIs that are commonly
+// needed alongside runtime operations.
+//
+// Accounts are identified using SDK addresses so the results can be used together with the
+// runtime client without converting between address types.
+package consensus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/node"
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	coreConsensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
+	governance "github.com/oasisprotocol/oasis-core/go/governance/api"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	scheduler "github.com/oasisprotocol/oasis-core/go/scheduler/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// HeightLatest is a special height number always referring to the latest consensus block.
+const HeightLatest = coreConsensus.HeightLatest
+
+var (
+	// CommonPoolAddress is the address of the consensus layer common pool.
+	CommonPoolAddress = types.Address(staking.CommonPoolAddress)
+	// FeeAccumulatorAddress is the address of the consensus layer fee accumulator.
+	FeeAccumulatorAddress = types.Address(staking.FeeAccumulatorAddress)
+	// GovernanceDepositsAddress is the address holding consensus layer governance deposits.
+	GovernanceDepositsAddress = types.Address(staking.GovernanceDepositsAddress)
+)
+
+// Client is a client for commonly used consensus layer APIs.
+type Client interface {
+	// Backend returns the underlying consensus client backend for APIs not covered by this
+	// client.
+	Backend() coreConsensus.ClientBackend
+
+	// Account returns the consensus staking account of the given address.
+	Account(ctx context.Context, height int64, addr types.Address) (*staking.Account, error)
+
+	// Balance returns the general balance of the given address.
+	Balance(ctx context.Context, height int64, addr types.Address) (*quantity.Quantity, error)
+
+	// Allowance returns the amount the beneficiary is allowed to withdraw from the owner.
+	Allowance(ctx context.Context, height int64, owner, beneficiary types.Address) (*quantity.Quantity, error)
+
+	// DelegationsFor returns the delegations made by the given delegator, keyed by escrow address.
+	DelegationsFor(ctx context.Context, height int64, delegator types.Address) (map[types.Address]*staking.Delegation, error)
+
+	// DelegationsTo returns the delegations made to the given escrow account, keyed by delegator.
+	DelegationsTo(ctx context.Context, height int64, escrow types.Address) (map[types.Address]*staking.Delegation, error)
+
+	// DebondingDelegationsFor returns the debonding delegations of the given delegator, keyed by
+	// escrow address.
+	DebondingDelegationsFor(ctx context.Context, height int64, delegator types.Address) (map[types.Address][]*staking.DebondingDelegation, error)
+
+	// Validators returns the current consensus validators.
+	Validators(ctx context.Context, height int64) ([]*scheduler.Validator, error)
+
+	// Nodes returns all registered nodes.
+	Nodes(ctx context.Context, height int64) ([]*node.Node, error)
+
+	// Epoch returns the epoch at the given height.
+	Epoch(ctx context.Context, height int64) (beacon.EpochTime, error)
+
+	// RuntimeDescriptor returns the registry descriptor of the given runtime.
+	RuntimeDescriptor(ctx context.Context, height int64, runtimeID common.Namespace) (*registry.Runtime, error)
+
+	// RuntimeState returns the roothash state of the given runtime, e.g. its latest block and the
+	// consensus height at which it was finalized.
+	RuntimeState(ctx context.Context, height int64, runtimeID common.Namespace) (*roothash.RuntimeState, error)
+
+	// Committees returns the committees of the given runtime.
+	Committees(ctx context.Context, height int64, runtimeID common.Namespace) ([]*scheduler.Committee, error)
+
+	// ExecutorCommittee returns the executor committee of the given runtime.
+	ExecutorCommittee(ctx context.Context, height int64, runtimeID common.Namespace) (*scheduler.Committee, error)
+
+	// WatchExecutorCommittees subscribes to changes of the executor committee of the given runtime.
+	WatchExecutorCommittees(ctx context.Context, runtimeID common.Namespace) (<-chan *scheduler.Committee, error)
+
+	// FinalizationHeight returns the height at which the given round of the given runtime was
+	// finalized and its messages were executed.
+	FinalizationHeight(ctx context.Context, runtimeID common.Namespace, round uint64) (int64, error)
+
+	// RoundEpoch returns the epoch in which the given round of the given runtime was finalized.
+	RoundEpoch(ctx context.Context, runtimeID common.Namespace, round uint64) (beacon.EpochTime, error)
+
+	// EpochStartRound returns the latest round of the given runtime finalized at the start of the
+	// given epoch.
+	EpochStartRound(ctx context.Context, runtimeID common.Namespace, epoch beacon.EpochTime) (uint64, error)
+
+	// WatchEpochs subscribes to epoch transitions.
+	WatchEpochs(ctx context.Context) (<-chan beacon.EpochTime, pubsub.ClosableSubscription, error)
+
+	// WatchStakingEvents subscribes to consensus staking events.
+	WatchStakingEvents(ctx context.Context) (<-chan *staking.Event, pubsub.ClosableSubscription, error)
+
+	// ChainContext returns the chain domain separation context of the consensus layer.
+	ChainContext(ctx context.Context) (string, error)
+
+	// Nonce returns the nonce of the next consensus transaction signed by the given address.
+	Nonce(ctx context.Context, height int64, addr types.Address) (uint64, error)
+
+	// EstimateGas estimates the gas needed to execute the given consensus transaction signed by
+	// the given Ed25519 public key.
+	EstimateGas(ctx context.Context, signer signature.PublicKey, tx *transaction.Transaction) (transaction.Gas, error)
+
+	// SubmitTx submits a signed consensus transaction and waits for it to be included in a block.
+	SubmitTx(ctx context.Context, tx *transaction.SignedTransaction) error
+
+	// ActiveProposals returns the governance proposals that have not been closed yet.
+	ActiveProposals(ctx context.Context, height int64) ([]*governance.Proposal, error)
+
+	// Proposals returns all governance proposals.
+	Proposals(ctx context.Context, height int64) ([]*governance.Proposal, error)
+
+	// Proposal returns the governance proposal with the given identifier.
+	Proposal(ctx context.Context, height int64, id uint64) (*governance.Proposal, error)
+
+	// Votes returns the votes cast for the governance proposal with the given identifier.
+	Votes(ctx context.Context, height int64, id uint64) ([]*governance.VoteEntry, error)
+}
+
+type consensusClient struct {
+	cs coreConsensus.ClientBackend
+}
+
+// Implements Client.
+func (c *consensusClient) Backend() coreConsensus.ClientBackend {
+	return c.cs
+}
+
+// Implements Client.
+func (c *consensusClient) Account(ctx context.Context, height int64, addr types.Address) (*staking.Account, error) {
+	return c.cs.Staking().Account(ctx, &staking.OwnerQuery{
+		Height: height,
+		Owner:  staking.Address(addr),
+	})
+}
+
+// Implements Client.
+func (c *consensusClient) Balance(ctx context.Context, height int64, addr types.Address) (*quantity.Quantity, error) {
+	acct, err := c.Account(ctx, height, addr)
+	if err != nil {
+		return nil, err
+	}
+	return &acct.General.Balance, nil
+}
+
+// Implements Client.
+func (c *consensusClient) Allowance(ctx context.Context, height int64, owner, beneficiary types.Address) (*quantity.Quantity, error) {
+	return c.cs.Staking().Allowance(ctx, &staking.AllowanceQuery{
+		Height:      height,
+		Owner:       staking.Address(owner),
+		Beneficiary: staking.Address(beneficiary),
+	})
+}
+
+// Implements Client.
+func (c *consensusClient) DelegationsFor(ctx context.Context, height int64, delegator types.Address) (map[types.Address]*staking.Delegation, error) {
+	dels, err := c.cs.Staking().DelegationsFor(ctx, &staking.OwnerQuery{
+		Height: height,
+		Owner:  staking.Address(delegator),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toSDKAddressMap(dels), nil
+}
+
+// Implements Client.
+func (c *consensusClient) DelegationsTo(ctx context.Context, height int64, escrow types.Address) (map[types.Address]*staking.Delegation, error) {
+	dels, err := c.cs.Staking().DelegationsTo(ctx, &staking.OwnerQuery{
+		Height: height,
+		Owner:  staking.Address(escrow),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toSDKAddressMap(dels), nil
+}
+
+// Implements Client.
+func (c *consensusClient) DebondingDelegationsFor(ctx context.Context, height int64, delegator types.Address) (map[types.Address][]*staking.DebondingDelegation, error) {
+	dels, err := c.cs.Staking().DebondingDelegationsFor(ctx, &staking.OwnerQuery{
+		Height: height,
+		Owner:  staking.Address(delegator),
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[types.Address][]*staking.DebondingDelegation, len(dels))
+	for addr, d := range dels {
+		result[types.Address(addr)] = d
+	}
+	return result, nil
+}
+
+// Implements Client.
+func (c *consensusClient) Validators(ctx context.Context, height int64) ([]*scheduler.Validator, error) {
+	return c.cs.Scheduler().GetValidators(ctx, height)
+}
+
+// Implements Client.
+func (c *consensusClient) Nodes(ctx context.Context, height int64) ([]*node.Node, error) {
+	return c.cs.Registry().GetNodes(ctx, height)
+}
+
+// Implements Client.
+func (c *consensusClient) Epoch(ctx context.Context, height int64) (beacon.EpochTime, error) {
+	return c.cs.Beacon().GetEpoch(ctx, height)
+}
+
+// Implements Client.
+func (c *consensusClient) RuntimeDescriptor(ctx context.Context, height int64, runtimeID common.Namespace) (*registry.Runtime, error) {
+	return c.cs.Registry().GetRuntime(ctx, &registry.NamespaceQuery{
+		Height: height,
+		ID:     runtimeID,
+	})
+}
+
+// Implements Client.
+func (c *consensusClient) RuntimeState(ctx context.Context, height int64, runtimeID common.Namespace) (*roothash.RuntimeState, error) {
+	return c.cs.RootHash().GetRuntimeState(ctx, &roothash.RuntimeRequest{
+		RuntimeID: runtimeID,
+		Height:    height,
+	})
+}
+
+// Implements Client.
+func (c *consensusClient) Committees(ctx context.Context, height int64, runtimeID common.Namespace) ([]*scheduler.Committee, error) {
+	return c.cs.Scheduler().GetCommittees(ctx, &scheduler.GetCommitteesRequest{
+		Height:    height,
+		RuntimeID: runtimeID,
+	})
+}
+
+// Implements Client.
+func (c *consensusClient) ExecutorCommittee(ctx context.Context, height int64, runtimeID common.Namespace) (*scheduler.Committee, error) {
+	committees, err := c.Committees(ctx, height, runtimeID)
+	if err != nil {
+		return nil, err
+	}
+	for _, committee := range committees {
+		if committee.Kind == scheduler.KindComputeExecutor {
+			return committee, nil
+		}
+	}
+	return nil, fmt.Errorf("consensus: no executor committee for runtime %s", runtimeID)
+}
+
+// Implements Client.
+func (c *consensusClient) WatchExecutorCommittees(ctx context.Context, runtimeID common.Namespace) (<-chan *scheduler.Committee, error) {
+	ch := make(chan *scheduler.Committee)
+
+	committeeCh, committeeSub, err := c.cs.Scheduler().WatchCommittees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer committeeSub.Close()
+		defer close(ch)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case committee, ok := <-committeeCh:
+				if !ok {
+					return
+				}
+				if committee.Kind != scheduler.KindComputeExecutor || !committee.RuntimeID.Equal(&runtimeID) {
+					continue
+				}
+
+				select {
+				case ch <- committee:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// Implements Client.
+func (c *consensusClient) FinalizationHeight(ctx context.Context, runtimeID common.Namespace, round uint64) (int64, error) {
+	status, err := c.cs.GetStatus(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("consensus: failed to get status: %w", err)
+	}
+
+	// Find the lowest height at which the latest runtime block is at least the given round.
+	lo, hi := status.LastRetainedHeight, status.LatestHeight
+	if lo < status.GenesisHeight {
+		lo = status.GenesisHeight
+	}
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		blk, err := c.cs.RootHash().GetLatestBlock(ctx, &roothash.RuntimeRequest{
+			RuntimeID: runtimeID,
+			Height:    mid,
+		})
+		switch {
+		case err == nil && blk.Header.Round >= round:
+			hi = mid
+		case err == nil, errors.Is(err, roothash.ErrInvalidRuntime):
+			// The runtime may not have existed yet at this height.
+			lo = mid + 1
+		default:
+			return 0, fmt.Errorf("consensus: failed to get runtime block at height %d: %w", mid, err)
+		}
+	}
+
+	blk, err := c.cs.RootHash().GetLatestBlock(ctx, &roothash.RuntimeRequest{
+		RuntimeID: runtimeID,
+		Height:    lo,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("consensus: failed to get runtime block at height %d: %w", lo, err)
+	}
+	if blk.Header.Round != round {
+		return 0, fmt.Errorf("consensus: round %d not finalized in retained history", round)
+	}
+	return lo, nil
+}
+
+// Implements Client.
+func (c *consensusClient) RoundEpoch(ctx context.Context, runtimeID common.Namespace, round uint64) (beacon.EpochTime, error) {
+	height, err := c.FinalizationHeight(ctx, runtimeID, round)
+	if err != nil {
+		return beacon.EpochInvalid, err
+	}
+	return c.Epoch(ctx, height)
+}
+
+// Implements Client.
+func (c *consensusClient) EpochStartRound(ctx context.Context, runtimeID common.Namespace, epoch beacon.EpochTime) (uint64, error) {
+	height, err := c.cs.Beacon().GetEpochBlock(ctx, epoch)
+	if err != nil {
+		return 0, fmt.Errorf("consensus: failed to get start height of epoch %d: %w", epoch, err)
+	}
+	blk, err := c.cs.RootHash().GetLatestBlock(ctx, &roothash.RuntimeRequest{
+		RuntimeID: runtimeID,
+		Height:    height,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("consensus: failed to get runtime block at height %d: %w", height, err)
+	}
+	return blk.Header.Round, nil
+}
+
+// Implements Client.
+func (c *consensusClient) WatchEpochs(ctx context.Context) (<-chan beacon.EpochTime, pubsub.ClosableSubscription, error) {
+	return c.cs.Beacon().WatchEpochs(ctx)
+}
+
+// Implements Client.
+func (c *consensusClient) WatchStakingEvents(ctx context.Context) (<-chan *staking.Event, pubsub.ClosableSubscription, error) {
+	return c.cs.Staking().WatchEvents(ctx)
+}
+
+// Implements Client.
+func (c *consensusClient) ChainContext(ctx context.Context) (string, error) {
+	return c.cs.GetChainContext(ctx)
+}
+
+// Implements Client.
+func (c *consensusClient) Nonce(ctx context.Context, height int64, addr types.Address) (uint64, error) {
+	return c.cs.GetSignerNonce(ctx, &coreConsensus.GetSignerNonceRequest{
+		AccountAddress: staking.Address(addr),
+		Height:         height,
+	})
+}
+
+// Implements Client.
+func (c *consensusClient) EstimateGas(ctx context.Context, signer signature.PublicKey, tx *transaction.Transaction) (transaction.Gas, error) {
+	pk, err := corePublicKey(signer)
+	if err != nil {
+		return 0, err
+	}
+	return c.cs.EstimateGas(ctx, &coreConsensus.EstimateGasRequest{
+		Signer:      pk,
+		Transaction: tx,
+	})
+}
+
+// Implements Client.
+func (c *consensusClient) SubmitTx(ctx context.Context, tx *transaction.SignedTransaction) error {
+	return c.cs.SubmitTx(ctx, tx)
+}
+
+// Implements Client.
+func (c *consensusClient) ActiveProposals(ctx context.Context, height int64) ([]*governance.Proposal, error) {
+	return c.cs.Governance().ActiveProposals(ctx, height)
+}
+
+// Implements Client.
+func (c *consensusClient) Proposals(ctx context.Context, height int64) ([]*governance.Proposal, error) {
+	return c.cs.Governance().Proposals(ctx, height)
+}
+
+// Implements Client.
+func (c *consensusClient) Proposal(ctx context.Context, height int64, id uint64) (*governance.Proposal, error) {
+	return c.cs.Governance().Proposal(ctx, &governance.ProposalQuery{
+		Height:     height,
+		ProposalID: id,
+	})
+}
+
+// Implements Client.
+func (c *consensusClient) Votes(ctx context.Context, height int64, id uint64) ([]*governance.VoteEntry, error) {
+	return c.cs.Governance().Votes(ctx, &governance.ProposalQuery{
+		Height:     height,
+		ProposalID: id,
+	})
+}
+
+func toSDKAddressMap(dels map[staking.Address]*staking.Delegation) map[types.Address]*staking.Delegation {
+	result := make(map[types.Address]*staking.Delegation, len(dels))
+	for addr, d := range dels {
+		result[types.Address(addr)] = d
+	}
+	return result
+}
+
+// New creates a new consensus client using the given gRPC connection.
+func New(conn *grpc.ClientConn) Client {
+	return &consensusClient{
+		cs: coreConsensus.NewConsensusClient(conn),
+	}
+}
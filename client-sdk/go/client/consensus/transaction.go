@@ -0,0 +1,48 @@
+package consensus
+
+import (
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	coreSignature "github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
+)
+
+// SignatureContext returns the context used to sign consensus transactions on the chain with the
+// given chain context.
+func SignatureContext(chainContext string) []byte {
+	return signature.Context(chainContext).New([]byte(transaction.SignatureContext))
+}
+
+// SignTransaction signs a consensus transaction for the chain with the given chain context. Only
+// Ed25519 signers can sign consensus transactions.
+func SignTransaction(chainContext string, signer signature.Signer, tx *transaction.Transaction) (*transaction.SignedTransaction, error) {
+	pk, err := corePublicKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	blob := cbor.Marshal(tx)
+	sig, err := signer.ContextSign(SignatureContext(chainContext), blob)
+	if err != nil {
+		return nil, fmt.Errorf("consensus: failed to sign transaction: %w", err)
+	}
+
+	var signed transaction.SignedTransaction
+	signed.Blob = blob
+	signed.Signature.PublicKey = pk
+	if err = signed.Signature.Signature.UnmarshalBinary(sig); err != nil {
+		return nil, fmt.Errorf("consensus: malformed signature: %w", err)
+	}
+	return &signed, nil
+}
+
+func corePublicKey(pk signature.PublicKey) (coreSignature.PublicKey, error) {
+	edPk, ok := pk.(ed25519.PublicKey)
+	if !ok {
+		return coreSignature.PublicKey{}, fmt.Errorf("consensus: only Ed25519 keys can sign consensus transactions")
+	}
+	return coreSignature.PublicKey(edPk), nil
+}
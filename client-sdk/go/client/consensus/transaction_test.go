@@ -0,0 +1,36 @@
+package consensus_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
+	governance "github.com/oasisprotocol/oasis-core/go/governance/api"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client/consensus"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+)
+
+func TestSignTransaction(t *testing.T) {
+	require := require.New(t)
+
+	chainContext := "test chain context"
+	tx := governance.NewCastVoteTx(3, &transaction.Fee{Gas: 1000}, &governance.ProposalVote{
+		ID:   1,
+		Vote: governance.VoteYes,
+	})
+	signed, err := consensus.SignTransaction(chainContext, sdkTesting.Alice.Signer, tx)
+	require.NoError(err, "SignTransaction")
+	require.Equal(cbor.Marshal(tx), signed.Blob)
+
+	pk := ed25519.PublicKey(signed.Signature.PublicKey)
+	require.True(pk.Equal(sdkTesting.Alice.Signer.Public()))
+	require.True(pk.Verify(consensus.SignatureContext(chainContext), signed.Blob, signed.Signature.Signature[:]))
+	require.False(pk.Verify(consensus.SignatureContext("other chain context"), signed.Blob, signed.Signature.Signature[:]))
+
+	_, err = consensus.SignTransaction(chainContext, sdkTesting.Dave.Signer, tx)
+	require.Error(err, "Secp256k1 signers should be rejected")
+}
@@ -0,0 +1,250 @@
+// Package crossruntime implements moving tokens between runtimes via the consensus layer.
+//
+// A transfer withdraws tokens from the source runtime into the signer's consensus account, makes
+// sure the destination runtime is allowed to take them and then deposits them into the
+// destination runtime. All legs are performed by the same Ed25519 signer, as the consensus layer
+// only supports Ed25519 accounts.
+package crossruntime
+
+import (
+	"context"
+	"fmt"
+
+	coreSignature "github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	cmnErrors "github.com/oasisprotocol/oasis-core/go/common/errors"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	coreConsensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client/consensus"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/consensusaccounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// Stage is a stage of a cross-runtime transfer.
+type Stage uint8
+
+const (
+	// StageWithdraw is the withdrawal from the source runtime into the consensus layer.
+	StageWithdraw Stage = 0
+	// StageAllowance is the consensus layer allowance for the destination runtime.
+	StageAllowance Stage = 1
+	// StageDeposit is the deposit from the consensus layer into the destination runtime.
+	StageDeposit Stage = 2
+)
+
+// String returns a string representation of the stage.
+func (s Stage) String() string {
+	switch s {
+	case StageWithdraw:
+		return "withdraw"
+	case StageAllowance:
+		return "allowance"
+	case StageDeposit:
+		return "deposit"
+	default:
+		return "[unknown stage]"
+	}
+}
+
+// ProgressEvent reports the progress of a cross-runtime transfer.
+type ProgressEvent struct {
+	// Stage is the stage the event refers to.
+	Stage Stage
+	// Completed is true when the stage has completed and false when it has started.
+	Completed bool
+	// Round is the runtime round in which the stage was executed. It is only set for completed
+	// withdraw and deposit stages.
+	Round uint64
+}
+
+// Result is the result of a cross-runtime transfer.
+type Result struct {
+	// Withdrawn is true when the tokens have left the source runtime.
+	Withdrawn bool
+	// WithdrawRound is the source runtime round in which the withdrawal was executed.
+	WithdrawRound uint64
+	// Deposited is true when the tokens have arrived in the destination runtime.
+	Deposited bool
+	// DepositRound is the destination runtime round in which the deposit was executed.
+	DepositRound uint64
+}
+
+// Transfer is a cross-runtime transfer.
+type Transfer struct {
+	// Source is the runtime the tokens are withdrawn from.
+	Source client.RuntimeClient
+	// Destination is the runtime the tokens are deposited into.
+	Destination client.RuntimeClient
+	// Consensus is the consensus layer client.
+	Consensus consensus.Client
+	// Signer is the signer of all transactions.
+	Signer coreSignature.Signer
+	// Amount is the amount of consensus layer tokens to transfer.
+	Amount quantity.Quantity
+	// ConsensusGasPrice is the gas price used for consensus layer transactions.
+	ConsensusGasPrice uint64
+
+	// OnProgress is an optional callback invoked as the transfer progresses.
+	OnProgress func(*ProgressEvent)
+}
+
+// Run performs the transfer.
+//
+// The returned result is always non-nil and describes how far the transfer got, so that a caller
+// can tell whether the withdrawn tokens are left in the signer's consensus account after a failed
+// deposit.
+func (t *Transfer) Run(ctx context.Context) (*Result, error) {
+	var result Result
+
+	sdkSigner := ed25519.WrapSigner(t.Signer)
+	spec := types.NewSignatureAddressSpecEd25519(ed25519.PublicKey(t.Signer.Public()))
+	addr := types.NewAddress(spec)
+
+	// Withdraw from the source runtime.
+	t.progress(&ProgressEvent{Stage: StageWithdraw})
+	round, err := t.submitRuntimeTx(ctx, t.Source, spec, addr, sdkSigner, consensusaccounts.NewV1(t.Source).WithdrawWithReceipt)
+	if err != nil {
+		return &result, fmt.Errorf("crossruntime: withdraw failed: %w", err)
+	}
+	result.Withdrawn = true
+	result.WithdrawRound = round
+	t.progress(&ProgressEvent{Stage: StageWithdraw, Completed: true, Round: round})
+
+	// Make sure the destination runtime is allowed to take the tokens.
+	t.progress(&ProgressEvent{Stage: StageAllowance})
+	if err = t.ensureAllowance(ctx, addr); err != nil {
+		return &result, fmt.Errorf("crossruntime: allowance failed: %w", err)
+	}
+	t.progress(&ProgressEvent{Stage: StageAllowance, Completed: true})
+
+	// Deposit into the destination runtime.
+	t.progress(&ProgressEvent{Stage: StageDeposit})
+	round, err = t.submitRuntimeTx(ctx, t.Destination, spec, addr, sdkSigner, consensusaccounts.NewV1(t.Destination).DepositWithReceipt)
+	if err != nil {
+		return &result, fmt.Errorf("crossruntime: deposit failed: %w", err)
+	}
+	result.Deposited = true
+	result.DepositRound = round
+	t.progress(&ProgressEvent{Stage: StageDeposit, Completed: true, Round: round})
+
+	return &result, nil
+}
+
+func (t *Transfer) progress(ev *ProgressEvent) {
+	if t.OnProgress != nil {
+		t.OnProgress(ev)
+	}
+}
+
+// submitRuntimeTx submits a consensus accounts transaction built by the given constructor and
+// waits for the receipt of its consensus layer transfer. It returns the round of the transaction.
+func (t *Transfer) submitRuntimeTx(
+	ctx context.Context,
+	rc client.RuntimeClient,
+	spec types.SignatureAddressSpec,
+	addr types.Address,
+	signer signature.Signer,
+	newTx func(types.BaseUnits) *client.TransactionBuilder,
+) (uint64, error) {
+	ca := consensusaccounts.NewV1(rc)
+	denomination, err := ca.ConsensusDenomination(ctx, client.RoundLatest)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query consensus denomination: %w", err)
+	}
+	nonce, err := accounts.NewV1(rc).Nonce(ctx, client.RoundLatest, addr)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query nonce: %w", err)
+	}
+
+	// Subscribe to blocks before submitting so no round is missed while waiting for the receipt.
+	blkCh, blkSub, err := rc.WatchBlocks(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to watch blocks: %w", err)
+	}
+	defer blkSub.Close()
+
+	tb := newTx(types.NewBaseUnits(t.Amount, denomination)).
+		SetFeeConsensusMessages(1).
+		AppendAuthSignature(spec, nonce)
+	if err = tb.AppendSign(ctx, signer); err != nil {
+		return 0, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	meta, err := tb.SubmitTxMeta(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	if meta.CheckTxError != nil {
+		return 0, fmt.Errorf("transaction check failed (module: %s code: %d): %s",
+			meta.CheckTxError.Module, meta.CheckTxError.Code, meta.CheckTxError.Message)
+	}
+
+	// The receipt is stored once the consensus layer has processed the transfer, which happens
+	// in a later round. The receipt identifier is the nonce of the transaction.
+	query := &consensusaccounts.ReceiptQuery{Address: addr, ID: nonce}
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case blk, ok := <-blkCh:
+			if !ok {
+				return 0, fmt.Errorf("block subscription closed")
+			}
+			if blk.Block.Header.Round <= meta.Round {
+				continue
+			}
+
+			receipt, err := ca.Receipt(ctx, blk.Block.Header.Round, query)
+			switch {
+			case err == nil:
+				if !receipt.IsSuccess() {
+					return 0, fmt.Errorf("consensus transfer failed: %w",
+						cmnErrors.FromCode(receipt.Module, receipt.Code, ""))
+				}
+				return meta.Round, nil
+			case consensusaccounts.IsReceiptNotFound(err):
+				continue
+			default:
+				return 0, fmt.Errorf("failed to query receipt: %w", err)
+			}
+		}
+	}
+}
+
+// ensureAllowance makes sure the destination runtime is allowed to withdraw the transfer amount
+// from the signer's consensus account.
+func (t *Transfer) ensureAllowance(ctx context.Context, owner types.Address) error {
+	info, err := t.Destination.GetInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get destination runtime info: %w", err)
+	}
+	beneficiary := types.NewAddressForRuntime(info.ID)
+
+	allowance, err := t.Consensus.Allowance(ctx, consensus.HeightLatest, owner, beneficiary)
+	if err != nil {
+		return fmt.Errorf("failed to query allowance: %w", err)
+	}
+	if allowance.Cmp(&t.Amount) >= 0 {
+		return nil
+	}
+
+	change := t.Amount.Clone()
+	if err = change.Sub(allowance); err != nil {
+		return fmt.Errorf("failed to compute allowance change: %w", err)
+	}
+	tx := staking.NewAllowTx(0, nil, &staking.Allow{
+		Beneficiary:  staking.Address(beneficiary),
+		AmountChange: *change,
+	})
+
+	pd, err := coreConsensus.NewStaticPriceDiscovery(t.ConsensusGasPrice)
+	if err != nil {
+		return err
+	}
+	sm := coreConsensus.NewSubmissionManager(t.Consensus.Backend(), pd, 0)
+	return sm.SignAndSubmitTx(ctx, t.Signer, tx)
+}
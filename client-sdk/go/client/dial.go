@@ -0,0 +1,179 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+
+	cmnGrpc "github.com/oasisprotocol/oasis-core/go/common/grpc"
+)
+
+// DialConfig is the configuration of a gRPC connection to a node. The zero value uses the gRPC
+// defaults, which are tuned for low-traffic connections.
+type DialConfig struct {
+	// Insecure disables TLS for TCP addresses. UNIX socket addresses (unix:<path>) are always
+	// connected to without TLS.
+	Insecure bool
+	// TLSConfig is the TLS configuration of TCP connections. It defaults to requiring TLS 1.2.
+	TLSConfig *tls.Config
+
+	// KeepaliveTime is the interval of keepalive pings sent when the connection is idle, which
+	// detects broken connections behind load balancers and NATs. Keepalive pings are disabled if
+	// zero. Nodes reject pings sent more often than they permit, so it should not be too low.
+	KeepaliveTime time.Duration
+	// KeepaliveTimeout is how long to wait for a keepalive ping to be acknowledged before the
+	// connection is closed. It defaults to 20 seconds.
+	KeepaliveTimeout time.Duration
+	// KeepalivePermitWithoutStream enables keepalive pings even when there are no active RPCs,
+	// e.g. to keep connections only used for occasional queries alive.
+	KeepalivePermitWithoutStream bool
+
+	// InitialWindowSize is the initial HTTP/2 flow control window size of each stream in bytes,
+	// which bounds the throughput of a single stream on high-latency links. It is dynamically
+	// sized by gRPC if zero.
+	InitialWindowSize int32
+	// InitialConnWindowSize is the initial HTTP/2 flow control window size of the connection in
+	// bytes, shared by all streams. It is dynamically sized by gRPC if zero.
+	InitialConnWindowSize int32
+	// ReadBufferSize and WriteBufferSize are the sizes of the connection's read and write
+	// buffers in bytes. The gRPC defaults are used if zero.
+	ReadBufferSize  int
+	WriteBufferSize int
+
+	// MaxConcurrentStreams is the maximum number of RPCs, including open event subscriptions,
+	// multiplexed over the connection at once. Further RPCs wait until a stream finishes, which
+	// keeps bursts of requests within the limits of the node instead of failing them. There is no
+	// limit if zero.
+	MaxConcurrentStreams uint32
+
+	// Compression is the name of the compressor of requests, CompressionGzip or CompressionZstd.
+	// Nodes compress their responses with the compressor of the request if they support it, which
+	// greatly reduces the size of large responses, like events of busy rounds, at the cost of some
+	// CPU time on both ends, so it mainly helps over slow links. Nodes reject requests with a
+	// compressor they do not support. Compression is disabled if empty.
+	Compression string
+}
+
+// DialOptions returns the gRPC dial options of the configuration, for callers that set up the
+// connection themselves.
+func (cfg *DialConfig) DialOptions(target string) []grpc.DialOption {
+	var opts []grpc.DialOption
+	if cfg.Insecure || strings.HasPrefix(target, "unix:") {
+		opts = append(opts, grpc.WithInsecure())
+	} else {
+		tlsCfg := cfg.TLSConfig
+		if tlsCfg == nil {
+			tlsCfg = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)))
+	}
+
+	if cfg.KeepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                cfg.KeepaliveTime,
+			Timeout:             cfg.KeepaliveTimeout,
+			PermitWithoutStream: cfg.KeepalivePermitWithoutStream,
+		}))
+	}
+	if cfg.InitialWindowSize > 0 {
+		opts = append(opts, grpc.WithInitialWindowSize(cfg.InitialWindowSize))
+	}
+	if cfg.InitialConnWindowSize > 0 {
+		opts = append(opts, grpc.WithInitialConnWindowSize(cfg.InitialConnWindowSize))
+	}
+	if cfg.ReadBufferSize > 0 {
+		opts = append(opts, grpc.WithReadBufferSize(cfg.ReadBufferSize))
+	}
+	if cfg.WriteBufferSize > 0 {
+		opts = append(opts, grpc.WithWriteBufferSize(cfg.WriteBufferSize))
+	}
+	if cfg.MaxConcurrentStreams > 0 {
+		l := newStreamLimiter(cfg.MaxConcurrentStreams)
+		opts = append(opts,
+			grpc.WithChainUnaryInterceptor(l.unaryInterceptor),
+			grpc.WithChainStreamInterceptor(l.streamInterceptor),
+		)
+	}
+	if cfg.Compression != "" {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(cfg.Compression)))
+	}
+	return opts
+}
+
+// Dial connects to the node at the given gRPC address, e.g. unix:/path/to/internal.sock or
+// host:port, with the given configuration. A nil configuration uses the defaults.
+func Dial(target string, cfg *DialConfig) (*grpc.ClientConn, error) {
+	if cfg == nil {
+		cfg = &DialConfig{}
+	}
+	if err := checkCompression(cfg.Compression); err != nil {
+		return nil, err
+	}
+	return cmnGrpc.Dial(target, cfg.DialOptions(target)...)
+}
+
+// streamLimiter limits the number of concurrent RPCs of a connection.
+type streamLimiter struct {
+	sem chan struct{}
+}
+
+func newStreamLimiter(limit uint32) *streamLimiter {
+	return &streamLimiter{sem: make(chan struct{}, limit)}
+}
+
+func (l *streamLimiter) acquire(ctx context.Context) error {
+	select {
+	case l.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *streamLimiter) release() {
+	<-l.sem
+}
+
+func (l *streamLimiter) unaryInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply interface{},
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	if err := l.acquire(ctx); err != nil {
+		return err
+	}
+	defer l.release()
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func (l *streamLimiter) streamInterceptor(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	if err := l.acquire(ctx); err != nil {
+		return nil, err
+	}
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		l.release()
+		return nil, err
+	}
+	// The context of the stream is canceled once the stream finishes for any reason.
+	go func() {
+		<-cs.Context().Done()
+		l.release()
+	}()
+	return cs, nil
+}
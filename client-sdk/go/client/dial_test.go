@@ -0,0 +1,144 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+func TestDialConfig(t *testing.T) {
+	require := require.New(t)
+
+	require.Len((&DialConfig{}).DialOptions("unix:/tmp/internal.sock"), 1, "only credentials by default")
+	opts := (&DialConfig{
+		KeepaliveTime:         time.Minute,
+		InitialWindowSize:     1 << 20,
+		InitialConnWindowSize: 1 << 24,
+		MaxConcurrentStreams:  8,
+	}).DialOptions("localhost:42261")
+	require.Len(opts, 6)
+
+	conn, err := Dial("localhost:42261", &DialConfig{MaxConcurrentStreams: 1})
+	require.NoError(err, "Dial should not block")
+	require.NoError(conn.Close())
+
+	_, err = Dial("localhost:42261", &DialConfig{Compression: "lz4"})
+	require.Error(err, "Dial with unsupported compression")
+	for _, name := range []string{CompressionGzip, CompressionZstd} {
+		conn, err = Dial("localhost:42261", &DialConfig{Compression: name})
+		require.NoError(err, "Dial with %s compression", name)
+		require.NoError(conn.Close())
+	}
+}
+
+func TestZstdCompressor(t *testing.T) {
+	require := require.New(t)
+
+	c := encoding.GetCompressor(CompressionZstd)
+	require.NotNil(c, "zstd compressor should be registered")
+
+	msg := bytes.Repeat([]byte("oasis"), 10000)
+	for i := 0; i < 3; i++ {
+		// The encoder and decoder are shared between messages.
+		var buf bytes.Buffer
+		w, err := c.Compress(&buf)
+		require.NoError(err, "Compress")
+		_, err = w.Write(msg)
+		require.NoError(err, "Write")
+		require.NoError(w.Close())
+		require.Less(buf.Len(), len(msg)/10)
+
+		r, err := c.Decompress(&buf)
+		require.NoError(err, "Decompress")
+		decompressed, err := ioutil.ReadAll(r)
+		require.NoError(err, "ReadAll")
+		require.Equal(msg, decompressed)
+	}
+
+	// The encoder and decoder can be used concurrently.
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var buf bytes.Buffer
+			w, _ := c.Compress(&buf)
+			_, _ = w.Write(msg)
+			if err := w.Close(); err != nil {
+				errs <- err
+				return
+			}
+			r, err := c.Decompress(&buf)
+			if err == nil {
+				var decompressed []byte
+				if decompressed, err = ioutil.ReadAll(r); err == nil && !bytes.Equal(msg, decompressed) {
+					err = fmt.Errorf("decompressed message differs")
+				}
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(err, "concurrent compression")
+	}
+
+	r, err := c.Decompress(bytes.NewReader([]byte("not zstd")))
+	if err == nil {
+		_, err = ioutil.ReadAll(r)
+	}
+	require.Error(err, "decompressing malformed data")
+}
+
+func TestStreamLimiter(t *testing.T) {
+	require := require.New(t)
+
+	const limit = 2
+	l := newStreamLimiter(limit)
+
+	var (
+		active, maxActive int32
+		wg                sync.WaitGroup
+	)
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			m := atomic.LoadInt32(&maxActive)
+			if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		return nil
+	}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(l.unaryInterceptor(context.Background(), "method", nil, nil, nil, invoker))
+		}()
+	}
+	wg.Wait()
+	require.EqualValues(limit, maxActive, "concurrent RPCs should be limited")
+
+	// Waiting RPCs give up once their context is canceled.
+	for i := 0; i < limit; i++ {
+		require.NoError(l.acquire(context.Background()))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := l.unaryInterceptor(ctx, "method", nil, nil, nil, invoker)
+	require.ErrorIs(err, context.DeadlineExceeded)
+}
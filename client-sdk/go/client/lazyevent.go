@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// LazyEvent is a handle of an event that is only decoded on demand, so that filtering events by
+// their module and code does not pay for decoding the events that are discarded.
+type LazyEvent struct {
+	// Module is the name of the module that emitted the event.
+	Module string
+	// Code is the module-specific event code.
+	Code uint32
+	// Value is the CBOR-encoded event value.
+	Value []byte
+
+	decoders []EventDecoder
+
+	decoded    bool
+	decodedEv  DecodedEvent
+	decodedErr error
+}
+
+// Raw returns the raw event.
+func (ev *LazyEvent) Raw() *types.Event {
+	return &types.Event{
+		Module: ev.Module,
+		Code:   ev.Code,
+		Value:  ev.Value,
+	}
+}
+
+// Decode decodes the event with the decoders the handle was created with. The result is cached, so
+// the event is decoded at most once. If none of the decoders is able to decode the event, nil is
+// returned.
+//
+// Decode is not safe for concurrent use.
+func (ev *LazyEvent) Decode() (DecodedEvent, error) {
+	if ev.decoded {
+		return ev.decodedEv, ev.decodedErr
+	}
+	ev.decodedEv, ev.decodedErr = decodeEvent(ev.Raw(), ev.decoders)
+	ev.decoded = true
+	return ev.decodedEv, ev.decodedErr
+}
+
+// DecodeValue decodes the CBOR-encoded event value into the given value, bypassing the decoders.
+func (ev *LazyEvent) DecodeValue(v interface{}) error {
+	if err := cbor.Unmarshal(ev.Value, v); err != nil {
+		return fmt.Errorf("failed to decode %s event %d: %w", ev.Module, ev.Code, err)
+	}
+	return nil
+}
+
+// NewLazyEvents returns handles of the given raw events that decode them with the given decoders.
+func NewLazyEvents(evs []*types.Event, decoders []EventDecoder) []*LazyEvent {
+	lazyEvs := make([]*LazyEvent, len(evs))
+	lazyEvsData := make([]LazyEvent, len(evs))
+	for i, ev := range evs {
+		lazyEvsData[i] = LazyEvent{
+			Module:   ev.Module,
+			Code:     ev.Code,
+			Value:    ev.Value,
+			decoders: decoders,
+		}
+		lazyEvs[i] = &lazyEvsData[i]
+	}
+	return lazyEvs
+}
+
+// GetLazyEvents returns handles of the events emitted in the given block, which decode the events
+// with the given decoders on demand.
+func GetLazyEvents(ctx context.Context, rc RuntimeClient, round uint64, decoders []EventDecoder) ([]*LazyEvent, error) {
+	evs, err := rc.GetEventsRaw(ctx, round)
+	if err != nil {
+		return nil, err
+	}
+	return NewLazyEvents(evs, decoders), nil
+}
+
+// decodeEvent decodes the event with the first of the given decoders able to decode it.
+func decodeEvent(ev *types.Event, decoders []EventDecoder) (DecodedEvent, error) {
+	for _, decoder := range decoders {
+		decoded, err := decoder.DecodeEvent(ev)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode event: %w", err)
+		}
+		if decoded != nil {
+			return decoded, nil
+		}
+	}
+	return nil, nil
+}
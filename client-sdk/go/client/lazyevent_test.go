@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// staticEventsClient is a runtime client returning the same events for all rounds.
+type staticEventsClient struct {
+	RuntimeClient
+
+	events []*types.Event
+}
+
+func (c *staticEventsClient) GetEventsRaw(ctx context.Context, round uint64) ([]*types.Event, error) {
+	return c.events, nil
+}
+
+// countingDecoder decodes events of the test module as strings and counts the decoded events.
+type countingDecoder struct {
+	decoded int
+}
+
+func (d *countingDecoder) DecodeEvent(ev *types.Event) (DecodedEvent, error) {
+	if ev.Module != "test" {
+		return nil, nil
+	}
+	d.decoded++
+	var s string
+	if err := cbor.Unmarshal(ev.Value, &s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func TestLazyEvents(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rc := &staticEventsClient{events: []*types.Event{
+		{Module: "test", Code: 1, Value: cbor.Marshal("first")},
+		{Module: "test", Code: 2, Value: cbor.Marshal("second")},
+		{Module: "other", Code: 1, Value: cbor.Marshal("other")},
+		{Module: "test", Code: 1, Value: []byte{0xff}},
+	}}
+	decoder := &countingDecoder{}
+	evs, err := GetLazyEvents(ctx, rc, RoundLatest, []EventDecoder{decoder})
+	require.NoError(err, "GetLazyEvents")
+	require.Len(evs, len(rc.events))
+	require.Zero(decoder.decoded, "events should not be decoded eagerly")
+
+	for i, ev := range evs {
+		require.Equal(rc.events[i], ev.Raw())
+	}
+
+	// Only the events that are asked for are decoded, at most once.
+	for i := 0; i < 2; i++ {
+		decoded, err := evs[1].Decode()
+		require.NoError(err, "Decode")
+		require.Equal("second", decoded)
+	}
+	require.Equal(1, decoder.decoded)
+
+	decoded, err := evs[2].Decode()
+	require.NoError(err, "Decode of an event without a decoder")
+	require.Nil(decoded)
+
+	_, err = evs[3].Decode()
+	require.Error(err, "Decode of a malformed event")
+
+	var s string
+	require.NoError(evs[2].DecodeValue(&s), "DecodeValue")
+	require.Equal("other", s)
+	require.Error(evs[3].DecodeValue(&s), "DecodeValue of a malformed event")
+}
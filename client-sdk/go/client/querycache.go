@@ -0,0 +1,228 @@
+package client
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+)
+
+// DefaultQueryCacheSize is the default maximum number of query results kept by a QueryCache.
+const DefaultQueryCacheSize = 1024
+
+// QueryCacheConfig is the configuration of a QueryCache.
+type QueryCacheConfig struct {
+	// MaxEntries is the maximum number of cached query results. The least recently used results
+	// are evicted once it is reached. It defaults to DefaultQueryCacheSize if zero.
+	MaxEntries int
+}
+
+// queryCacheKey identifies the result of a query.
+type queryCacheKey struct {
+	method string
+	args   string
+	round  uint64
+}
+
+// queryCacheEntry is a cached query result.
+type queryCacheEntry struct {
+	key    queryCacheKey
+	result cbor.RawMessage
+}
+
+// QueryCache is a runtime client caching the results of runtime-specific queries, which is useful
+// for dashboards and other clients repeatedly making the same queries.
+//
+// Results are keyed by the method, arguments and round of the query. Results of queries at a
+// specific round never change, so they are kept until evicted, while results of queries at
+// RoundLatest are invalidated whenever the cache is notified of a new block via WatchBlocks. If
+// the block subscription fails, results at RoundLatest are no longer cached.
+//
+// All other methods are passed through to the wrapped client.
+type QueryCache struct {
+	RuntimeClient
+
+	l sync.Mutex
+
+	maxEntries int
+	entries    map[queryCacheKey]*list.Element
+	lru        *list.List
+
+	// generation is incremented whenever results at RoundLatest are invalidated, so that results
+	// of queries racing with an invalidation are not cached.
+	generation uint64
+	watching   bool
+
+	pkCache *CallDataPublicKeyCache
+}
+
+func (c *QueryCache) callDataPublicKeyCache() *CallDataPublicKeyCache {
+	return c.pkCache
+}
+
+// Implements RuntimeClient.
+func (c *QueryCache) Query(ctx context.Context, round uint64, method string, args, rsp interface{}) error {
+	key := queryCacheKey{
+		method: method,
+		args:   string(cbor.Marshal(args)),
+		round:  round,
+	}
+
+	c.l.Lock()
+	result, ok := c.get(key)
+	generation := c.generation
+	c.l.Unlock()
+
+	if !ok {
+		if err := c.RuntimeClient.Query(ctx, round, method, args, &result); err != nil {
+			return err
+		}
+
+		c.l.Lock()
+		if round != RoundLatest || (c.watching && c.generation == generation) {
+			c.put(key, result)
+		}
+		c.l.Unlock()
+	}
+
+	if rsp != nil {
+		if err := cbor.Unmarshal(result, rsp); err != nil {
+			return fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+	}
+	return nil
+}
+
+// Invalidate drops all cached results of queries at RoundLatest.
+func (c *QueryCache) Invalidate() {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.invalidateLocked()
+}
+
+// Purge drops all cached results.
+func (c *QueryCache) Purge() {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.entries = make(map[queryCacheKey]*list.Element)
+	c.lru.Init()
+	c.generation++
+}
+
+// Len returns the number of cached results.
+func (c *QueryCache) Len() int {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	return c.lru.Len()
+}
+
+func (c *QueryCache) get(key queryCacheKey) (cbor.RawMessage, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*queryCacheEntry).result, true
+}
+
+func (c *QueryCache) put(key queryCacheKey, result cbor.RawMessage) {
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*queryCacheEntry).result = result
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&queryCacheEntry{key: key, result: result})
+	for c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *QueryCache) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*queryCacheEntry).key)
+}
+
+func (c *QueryCache) invalidateLocked() {
+	for elem := c.lru.Front(); elem != nil; {
+		next := elem.Next()
+		if elem.Value.(*queryCacheEntry).key.round == RoundLatest {
+			c.remove(elem)
+		}
+		elem = next
+	}
+	c.generation++
+}
+
+func (c *QueryCache) watchBlocks(ctx context.Context) error {
+	blkCh, blkSub, err := c.RuntimeClient.WatchBlocks(ctx)
+	if err != nil {
+		return fmt.Errorf("client: failed to watch blocks: %w", err)
+	}
+	c.watching = true
+
+	go func() {
+		defer blkSub.Close()
+		defer func() {
+			c.l.Lock()
+			defer c.l.Unlock()
+
+			c.watching = false
+			c.invalidateLocked()
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-blkCh:
+				if !ok {
+					return
+				}
+				c.Invalidate()
+			}
+		}
+	}()
+	return nil
+}
+
+// NewQueryCache creates a new runtime client caching the query results of the given client.
+//
+// Results of queries at RoundLatest are cached until the given context is canceled, after which
+// only results of queries at specific rounds are cached. A nil configuration uses the defaults.
+func NewQueryCache(ctx context.Context, rc RuntimeClient, cfg *QueryCacheConfig) (*QueryCache, error) {
+	if cfg == nil {
+		cfg = &QueryCacheConfig{}
+	}
+	maxEntries := cfg.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = DefaultQueryCacheSize
+	}
+
+	c := &QueryCache{
+		RuntimeClient: rc,
+		maxEntries:    maxEntries,
+		entries:       make(map[queryCacheKey]*list.Element),
+		lru:           list.New(),
+	}
+	c.pkCache = sharedCallDataPublicKeyCache(c, rc)
+	if err := c.watchBlocks(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// sharedCallDataPublicKeyCache returns the call data public key cache of the given wrapped client,
+// if any, so that wrappers share it. Otherwise a new cache querying the wrapper is returned.
+func sharedCallDataPublicKeyCache(wrapper, rc RuntimeClient) *CallDataPublicKeyCache {
+	if p, ok := rc.(interface {
+		callDataPublicKeyCache() *CallDataPublicKeyCache
+	}); ok {
+		return p.callDataPublicKeyCache()
+	}
+	return NewCallDataPublicKeyCache(wrapper, 0)
+}
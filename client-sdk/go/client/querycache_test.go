@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+)
+
+// countingQueryClient is a runtime client answering queries with the number of queries made.
+type countingQueryClient struct {
+	RuntimeClient
+
+	queries  uint64
+	notifier *pubsub.Broker
+}
+
+func (c *countingQueryClient) Query(ctx context.Context, round uint64, method string, args, rsp interface{}) error {
+	return cbor.Unmarshal(cbor.Marshal(atomic.AddUint64(&c.queries, 1)), rsp)
+}
+
+func (c *countingQueryClient) WatchBlocks(ctx context.Context) (<-chan *roothash.AnnotatedBlock, pubsub.ClosableSubscription, error) {
+	sub := c.notifier.Subscribe()
+	ch := make(chan *roothash.AnnotatedBlock)
+	sub.Unwrap(ch)
+	return ch, sub, nil
+}
+
+func TestQueryCache(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	rc := &countingQueryClient{notifier: pubsub.NewBroker(false)}
+	qc, err := NewQueryCache(watchCtx, rc, &QueryCacheConfig{MaxEntries: 3})
+	require.NoError(err, "NewQueryCache")
+
+	query := func(round uint64, method string, args interface{}) uint64 {
+		var rsp uint64
+		require.NoError(qc.Query(ctx, round, method, args, &rsp), "Query")
+		return rsp
+	}
+
+	// Results are cached by method, arguments and round.
+	require.EqualValues(1, query(RoundLatest, "test.A", "x"))
+	require.EqualValues(1, query(RoundLatest, "test.A", "x"), "result should be cached")
+	require.EqualValues(2, query(RoundLatest, "test.A", "y"))
+	require.EqualValues(3, query(RoundLatest, "test.B", "x"))
+	require.EqualValues(4, query(10, "test.A", "x"))
+	require.EqualValues(4, query(10, "test.A", "x"), "result should be cached")
+	require.Equal(3, qc.Len(), "least recently used results should be evicted")
+	require.EqualValues(5, query(RoundLatest, "test.A", "x"), "evicted result should be queried again")
+
+	// New blocks invalidate results at the latest round only.
+	rc.notifier.Broadcast(&roothash.AnnotatedBlock{Block: &block.Block{}})
+	require.Eventually(func() bool { return qc.Len() == 1 }, time.Second, 10*time.Millisecond)
+	require.EqualValues(4, query(10, "test.A", "x"), "result should be cached")
+	require.EqualValues(6, query(RoundLatest, "test.A", "x"))
+
+	// Results at the latest round are no longer cached once the subscription ends.
+	cancel()
+	require.Eventually(func() bool { return qc.Len() == 1 }, time.Second, 10*time.Millisecond)
+	require.EqualValues(7, query(RoundLatest, "test.A", "x"))
+	require.EqualValues(8, query(RoundLatest, "test.A", "x"))
+	require.EqualValues(4, query(10, "test.A", "x"), "result should be cached")
+
+	qc.Purge()
+	require.Zero(qc.Len())
+}
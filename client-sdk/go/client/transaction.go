@@ -17,6 +17,7 @@ type TransactionBuilder struct {
 	ts *types.TransactionSigner
 
 	callMeta interface{}
+	pkCache  *CallDataPublicKeyCache
 }
 
 // NewTransactionBuilder creates a new transaction builder.
@@ -46,6 +47,15 @@ func (tb *TransactionBuilder) SetFeeConsensusMessages(consensusMessages uint32)
 	return tb
 }
 
+// SetCallDataPublicKeyCache configures the cache used to obtain the call data public key when
+// changing to a confidential call format.
+//
+// By default the cache of the runtime client is used.
+func (tb *TransactionBuilder) SetCallDataPublicKeyCache(cache *CallDataPublicKeyCache) *TransactionBuilder {
+	tb.pkCache = cache
+	return tb
+}
+
 // SetCallFormat changes the transaction's call format.
 //
 // Depending on the call format this operation my require queries into the runtime in order to
@@ -0,0 +1,112 @@
+// Command activity-export exports the activity of ParaTime accounts from an indexer database as
+// accounting records in CSV format.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/spf13/cobra"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/export"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/indexer"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const dateLayout = "2006-01-02"
+
+var (
+	database  string
+	addresses []string
+	from      string
+	to        string
+	format    string
+	output    string
+)
+
+// parseTime parses a date or an RFC 3339 timestamp, returning the zero time for empty strings.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(dateLayout, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+var rootCmd = &cobra.Command{
+	Use:          "activity-export",
+	Short:        "Export the activity of ParaTime accounts from an indexer database",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		q := &export.Query{}
+		for _, s := range addresses {
+			var addr types.Address
+			if err := addr.UnmarshalText([]byte(s)); err != nil {
+				return fmt.Errorf("malformed address %s: %w", s, err)
+			}
+			q.Addresses = append(q.Addresses, addr)
+		}
+		var err error
+		if q.From, err = parseTime(from); err != nil {
+			return fmt.Errorf("malformed start of period: %w", err)
+		}
+		if q.To, err = parseTime(to); err != nil {
+			return fmt.Errorf("malformed end of period: %w", err)
+		}
+
+		var out io.Writer = os.Stdout
+		if output != "" {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+		var w export.Writer
+		switch format {
+		case "csv":
+			w = export.NewCSVWriter(out)
+		default:
+			return fmt.Errorf("unsupported format %s", format)
+		}
+
+		db, err := sql.Open("postgres", database)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		ctx := context.Background()
+		storage, err := indexer.NewSQLStorage(ctx, db, indexer.DialectPostgres)
+		if err != nil {
+			return err
+		}
+		return export.Export(ctx, storage, q, w)
+	},
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.Flags().StringVar(&database, "db", "", "connection string of the PostgreSQL indexer database")
+	rootCmd.Flags().StringSliceVar(&addresses, "address", nil, "exported account address (repeatable)")
+	rootCmd.Flags().StringVar(&from, "from", "", "start of the exported period, inclusive, as a date (YYYY-MM-DD) or RFC 3339 timestamp")
+	rootCmd.Flags().StringVar(&to, "to", "", "end of the exported period, exclusive, as a date (YYYY-MM-DD) or RFC 3339 timestamp")
+	rootCmd.Flags().StringVar(&format, "format", "csv", "output format (csv)")
+	rootCmd.Flags().StringVarP(&output, "output", "o", "", "output file (default standard output)")
+	_ = rootCmd.MarkFlagRequired("db")
+	_ = rootCmd.MarkFlagRequired("address")
+}
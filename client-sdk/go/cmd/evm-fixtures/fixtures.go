@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+)
+
+// DefaultChainID is the EIP-155 chain ID of the simple EVM test runtime.
+const DefaultChainID = 0xa515
+
+// DefaultRPCURL is the URL of the JSON-RPC endpoint of a local web3-proxy.
+const DefaultRPCURL = "http://127.0.0.1:8545"
+
+// devKeys are the Secp256k1 test keys that can be used as development accounts, by name.
+var devKeys = map[string]sdkTesting.TestKey{
+	"dave":  sdkTesting.Dave,
+	"frank": sdkTesting.Frank,
+}
+
+// Account is a development account.
+type Account struct {
+	// Name is the name of the test key.
+	Name string `json:"name"`
+	// Address is the hex-encoded Ethereum address.
+	Address string `json:"address"`
+	// PrivateKey is the hex-encoded private key.
+	PrivateKey string `json:"private_key"`
+}
+
+// Network is the configuration of a local network for Ethereum tooling.
+type Network struct {
+	// Name is the name of the network in the generated configuration.
+	Name string `json:"name"`
+	// ChainID is the EIP-155 chain ID of the runtime.
+	ChainID uint64 `json:"chain_id"`
+	// RPCURL is the URL of the JSON-RPC endpoint.
+	RPCURL string `json:"rpc_url"`
+	// Accounts are the development accounts, the first of which is the default deployer.
+	Accounts []Account `json:"accounts"`
+}
+
+// NewNetwork creates the configuration of a local network with the development accounts of the
+// given test keys.
+func NewNetwork(name string, chainID uint64, rpcURL string, keys []string) (*Network, error) {
+	if name == "" {
+		return nil, fmt.Errorf("missing network name")
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no development accounts")
+	}
+	n := &Network{
+		Name:    name,
+		ChainID: chainID,
+		RPCURL:  rpcURL,
+	}
+	for _, k := range keys {
+		key, ok := devKeys[k]
+		if !ok {
+			return nil, fmt.Errorf("unknown test key %s", k)
+		}
+		n.Accounts = append(n.Accounts, Account{
+			Name:       k,
+			Address:    "0x" + hex.EncodeToString(key.EthAddress),
+			PrivateKey: "0x" + hex.EncodeToString(key.EthPrivateKey),
+		})
+	}
+	return n, nil
+}
+
+// envName returns the name of an environment variable for the given network.
+func (n *Network) envName(suffix string) string {
+	name := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(n.Name))
+	return name + "_" + suffix
+}
+
+// Hardhat returns the Hardhat network configuration, which can be used as an entry of the
+// networks of hardhat.config.js.
+func (n *Network) Hardhat() ([]byte, error) {
+	cfg := struct {
+		URL      string   `json:"url"`
+		ChainID  uint64   `json:"chainId"`
+		Accounts []string `json:"accounts"`
+	}{
+		URL:     n.RPCURL,
+		ChainID: n.ChainID,
+	}
+	for _, acct := range n.Accounts {
+		cfg.Accounts = append(cfg.Accounts, acct.PrivateKey)
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// FoundryConfig returns a foundry.toml declaring the RPC endpoint of the network, read from the
+// environment written by FoundryEnv.
+func (n *Network) FoundryConfig() []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[rpc_endpoints]\n")
+	fmt.Fprintf(&b, "%s = \"${%s}\"\n", n.Name, n.envName("RPC_URL"))
+	return []byte(b.String())
+}
+
+// FoundryEnv returns a .env file with the RPC URL, chain ID and development account keys of the
+// network, which Foundry loads automatically. ETH_RPC_URL and PRIVATE_KEY are set to the network
+// and its default deployer, so that forge and cast use them without further flags.
+func (n *Network) FoundryEnv() []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ETH_RPC_URL=%s\n", n.RPCURL)
+	fmt.Fprintf(&b, "PRIVATE_KEY=%s\n", n.Accounts[0].PrivateKey)
+	fmt.Fprintf(&b, "%s=%s\n", n.envName("RPC_URL"), n.RPCURL)
+	fmt.Fprintf(&b, "%s=%d\n", n.envName("CHAIN_ID"), n.ChainID)
+	for _, acct := range n.Accounts {
+		name := strings.ToUpper(acct.Name)
+		fmt.Fprintf(&b, "%s_ADDRESS=%s\n", name, acct.Address)
+		fmt.Fprintf(&b, "%s_PRIVATE_KEY=%s\n", name, acct.PrivateKey)
+	}
+	return []byte(b.String())
+}
+
+// Write writes the Hardhat and Foundry configuration of the network into the given directory:
+//
+//   - hardhat/<name>.json is the Hardhat network configuration.
+//   - foundry/foundry.toml and foundry/.env are the Foundry configuration.
+//   - <name>.json is the network configuration itself, for other tooling.
+func (n *Network) Write(dir string) error {
+	hardhat, err := n.Hardhat()
+	if err != nil {
+		return err
+	}
+	network, err := json.MarshalIndent(n, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	files := []struct {
+		path string
+		data []byte
+	}{
+		{filepath.Join("hardhat", n.Name+".json"), hardhat},
+		{filepath.Join("foundry", "foundry.toml"), n.FoundryConfig()},
+		{filepath.Join("foundry", ".env"), n.FoundryEnv()},
+		{n.Name + ".json", append(network, '\n')},
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, f.path)
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		// The files contain private keys, albeit of public test keys.
+		if err = ioutil.WriteFile(path, f.data, 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetwork(t *testing.T) {
+	require := require.New(t)
+
+	_, err := NewNetwork("oasis-local", DefaultChainID, DefaultRPCURL, []string{"erin"})
+	require.Error(err, "non-Secp256k1 test keys should be rejected")
+	_, err = NewNetwork("oasis-local", DefaultChainID, DefaultRPCURL, nil)
+	require.Error(err, "a development account is required")
+
+	n, err := NewNetwork("oasis-local", DefaultChainID, DefaultRPCURL, []string{"dave", "frank"})
+	require.NoError(err, "NewNetwork")
+	require.Len(n.Accounts, 2)
+	require.Equal("0xdce075e1c39b1ae0b75d554558b6451a226ffe00", n.Accounts[0].Address)
+	require.Equal("0x66f3fa9805018fb2bcbdf9b953126fc1f05a8f9c", n.Accounts[1].Address)
+
+	dir := t.TempDir()
+	require.NoError(n.Write(dir), "Write")
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "hardhat", "oasis-local.json"))
+	require.NoError(err)
+	var hardhat struct {
+		URL      string   `json:"url"`
+		ChainID  uint64   `json:"chainId"`
+		Accounts []string `json:"accounts"`
+	}
+	require.NoError(json.Unmarshal(data, &hardhat), "Hardhat configuration should be JSON")
+	require.Equal(DefaultRPCURL, hardhat.URL)
+	require.EqualValues(42261, hardhat.ChainID)
+	require.Equal([]string{n.Accounts[0].PrivateKey, n.Accounts[1].PrivateKey}, hardhat.Accounts)
+
+	data, err = ioutil.ReadFile(filepath.Join(dir, "foundry", "foundry.toml"))
+	require.NoError(err)
+	require.Contains(string(data), `oasis-local = "${OASIS_LOCAL_RPC_URL}"`)
+	data, err = ioutil.ReadFile(filepath.Join(dir, "foundry", ".env"))
+	require.NoError(err)
+	env := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		kv := strings.SplitN(line, "=", 2)
+		require.Len(kv, 2, "malformed line %s", line)
+		env[kv[0]] = kv[1]
+	}
+	require.Equal(DefaultRPCURL, env["ETH_RPC_URL"])
+	require.Equal(DefaultRPCURL, env["OASIS_LOCAL_RPC_URL"])
+	require.Equal("42261", env["OASIS_LOCAL_CHAIN_ID"])
+	require.Equal(n.Accounts[0].PrivateKey, env["PRIVATE_KEY"])
+	require.Equal(n.Accounts[1].Address, env["FRANK_ADDRESS"])
+
+	data, err = ioutil.ReadFile(filepath.Join(dir, "oasis-local.json"))
+	require.NoError(err)
+	var decoded Network
+	require.NoError(json.Unmarshal(data, &decoded))
+	require.Equal(*n, decoded)
+}
@@ -0,0 +1,49 @@
+// Command evm-fixtures generates the network configuration of a local ParaTime for Hardhat and
+// Foundry projects, so that EVM developers can bootstrap a project against a local network served
+// by web3-proxy.
+//
+// The generated configuration contains the chain ID of the ParaTime, the URL of the JSON-RPC
+// endpoint and the keys of the Secp256k1 test keys of the testing package as development accounts.
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	outputDir   string
+	networkName string
+	chainID     uint64
+	rpcURL      string
+	accountKeys []string
+)
+
+var rootCmd = &cobra.Command{
+	Use:          "evm-fixtures",
+	Short:        "Generate Hardhat and Foundry network configuration for a local ParaTime",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, err := NewNetwork(networkName, chainID, rpcURL, accountKeys)
+		if err != nil {
+			return err
+		}
+		return n.Write(outputDir)
+	},
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.Flags().StringVarP(&outputDir, "output", "o", ".", "directory to write the configuration to")
+	rootCmd.Flags().StringVar(&networkName, "network", "oasis-local", "name of the network in the configuration")
+	rootCmd.Flags().Uint64Var(&chainID, "chain-id", DefaultChainID, "EIP-155 chain ID of the ParaTime")
+	rootCmd.Flags().StringVar(&rpcURL, "rpc-url", DefaultRPCURL, "URL of the JSON-RPC endpoint of web3-proxy")
+	rootCmd.Flags().StringSliceVar(&accountKeys, "account", []string{"dave"}, "name of a Secp256k1 test key to use as development account (dave or frank, repeatable)")
+}
@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/wallet"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const (
+	cfgAlgorithm = "algorithm"
+	cfgNumber    = "number"
+	cfgPath      = "path"
+	cfgRaw       = "raw"
+)
+
+var (
+	accountAlgorithm string
+	accountNumber    uint32
+	accountPath      string
+	accountRaw       bool
+	accountFile      string
+
+	accountCmd = &cobra.Command{
+		Use:   "account",
+		Short: "Manage accounts in the encrypted keystore",
+	}
+
+	accountCreateCmd = &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create an account with a new random key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			alg, err := wallet.ParseAlgorithm(accountAlgorithm)
+			if err != nil {
+				return err
+			}
+			ks, err := common.Keystore()
+			if err != nil {
+				return err
+			}
+			if err = wallet.ValidateAccountName(name); err != nil {
+				return err
+			}
+
+			secret, err := wallet.GenerateSecret(alg)
+			if err != nil {
+				return err
+			}
+			passphrase, err := common.Passphrase(name, true)
+			if err != nil {
+				return err
+			}
+			acct, err := ks.Create(name, alg, secret, nil, passphrase)
+			if err != nil {
+				return err
+			}
+			return printAccount(cmd, acct)
+		},
+	}
+
+	accountImportCmd = &cobra.Command{
+		Use:   "import <name>",
+		Short: "Import an account from a mnemonic, a raw private key or an exported account",
+		Long: `Import an account from a BIP-0039 mnemonic or, with --raw, from a hex-encoded raw
+private key (the seed for Ed25519). With --file, an account exported with account export is
+imported instead, its passphrase being asked for before the new one.
+
+Keys are derived from mnemonics along the path given by --path or, by default, along the
+standard path of the account number given by --number: m/44'/474'/n' (ADR 0008) for Ed25519
+and m/44'/60'/0'/0/n for Secp256k1, like Ethereum wallets.
+
+The words and checksum of the mnemonic are not verified, so check the address of the imported
+account against the expected one.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			alg, err := wallet.ParseAlgorithm(accountAlgorithm)
+			if err != nil {
+				return err
+			}
+			ks, err := common.Keystore()
+			if err != nil {
+				return err
+			}
+			if err = wallet.ValidateAccountName(name); err != nil {
+				return err
+			}
+			if accountFile != "" {
+				return importAccountFile(cmd, ks, name)
+			}
+
+			var (
+				secret []byte
+				path   wallet.Path
+			)
+			if accountRaw {
+				if secret, err = promptRawSecret(); err != nil {
+					return err
+				}
+			} else {
+				if path, err = importPath(cmd, alg); err != nil {
+					return err
+				}
+				if secret, err = promptMnemonicSecret(alg, path); err != nil {
+					return err
+				}
+			}
+			if err = wallet.ValidateSecret(alg, secret); err != nil {
+				return err
+			}
+
+			passphrase, err := common.Passphrase(name, true)
+			if err != nil {
+				return err
+			}
+			acct, err := ks.Create(name, alg, secret, path, passphrase)
+			if err != nil {
+				return err
+			}
+			return printAccount(cmd, acct)
+		},
+	}
+
+	accountListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List all accounts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ks, err := common.Keystore()
+			if err != nil {
+				return err
+			}
+			accounts, err := ks.List()
+			if err != nil {
+				return err
+			}
+
+			if common.OutputJSON() {
+				infos := make([]*accountJSON, 0, len(accounts))
+				for _, acct := range accounts {
+					info, infoErr := newAccountJSON(acct)
+					if infoErr != nil {
+						return infoErr
+					}
+					infos = append(infos, info)
+				}
+				return common.PrintJSON(cmd.OutOrStdout(), infos)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 8, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tALGORITHM\tADDRESS")
+			for _, acct := range accounts {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", acct.Name, acct.Algorithm, acct.Address)
+			}
+			return w.Flush()
+		},
+	}
+
+	accountShowCmd = &cobra.Command{
+		Use:   "show <name>",
+		Short: "Show the details of an account",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ks, err := common.Keystore()
+			if err != nil {
+				return err
+			}
+			acct, err := ks.Get(args[0])
+			if err != nil {
+				return err
+			}
+			return printAccount(cmd, acct)
+		},
+	}
+)
+
+// importAccountFile imports the account exported into the file given by --file.
+func importAccountFile(cmd *cobra.Command, ks *wallet.Keystore, name string) error {
+	for _, flag := range []string{cfgRaw, cfgNumber, cfgPath, cfgAlgorithm} {
+		if cmd.Flags().Changed(flag) {
+			return fmt.Errorf("--%s and --%s are mutually exclusive", cfgFile, flag)
+		}
+	}
+	data, err := os.ReadFile(accountFile)
+	if err != nil {
+		return fmt.Errorf("failed to read exported account: %w", err)
+	}
+	exported, err := wallet.ParseAccount(data)
+	if err != nil {
+		return err
+	}
+	passphrase, err := common.PromptSecret(fmt.Sprintf("Passphrase of exported account %s: ", exported.Name))
+	if err != nil {
+		return err
+	}
+	newPassphrase, err := common.Passphrase(name, true)
+	if err != nil {
+		return err
+	}
+	acct, err := ks.Import(name, data, passphrase, newPassphrase)
+	if err != nil {
+		return err
+	}
+	return printAccount(cmd, acct)
+}
+
+// importPath returns the derivation path given by the import flags.
+func importPath(cmd *cobra.Command, alg wallet.Algorithm) (wallet.Path, error) {
+	if cmd.Flags().Changed(cfgPath) {
+		if cmd.Flags().Changed(cfgNumber) {
+			return nil, fmt.Errorf("--%s and --%s are mutually exclusive", cfgPath, cfgNumber)
+		}
+		return wallet.ParsePath(accountPath)
+	}
+	return wallet.DefaultPath(alg, accountNumber)
+}
+
+func promptRawSecret() ([]byte, error) {
+	raw, err := common.PromptSecret("Private key (hex): ")
+	if err != nil {
+		return nil, err
+	}
+	secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(raw), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("malformed private key: %w", err)
+	}
+	return secret, nil
+}
+
+func promptMnemonicSecret(alg wallet.Algorithm, path wallet.Path) ([]byte, error) {
+	mnemonic, err := common.PromptSecret("Mnemonic: ")
+	if err != nil {
+		return nil, err
+	}
+	passphrase, err := common.PromptSecret("Mnemonic passphrase (empty if none): ")
+	if err != nil {
+		return nil, err
+	}
+	return wallet.DeriveSecret(alg, mnemonic, passphrase, path)
+}
+
+// accountJSON is the JSON form of an account, without its encrypted secret.
+type accountJSON struct {
+	Name            string           `json:"name"`
+	Algorithm       wallet.Algorithm `json:"algorithm"`
+	Address         types.Address    `json:"address"`
+	EthereumAddress string           `json:"eth_address,omitempty"`
+	PublicKey       string           `json:"public_key"`
+	Path            string           `json:"path,omitempty"`
+	Created         time.Time        `json:"created"`
+}
+
+func newAccountJSON(acct *wallet.Account) (*accountJSON, error) {
+	pk, err := acct.PublicKeyValue()
+	if err != nil {
+		return nil, err
+	}
+	info := &accountJSON{
+		Name:      acct.Name,
+		Algorithm: acct.Algorithm,
+		Address:   acct.Address,
+		PublicKey: pk.String(),
+		Path:      acct.Path,
+		Created:   acct.Created,
+	}
+	if acct.Algorithm == wallet.AlgorithmSecp256k1 {
+		ethAddr, ethErr := acct.EthereumAddress()
+		if ethErr != nil {
+			return nil, ethErr
+		}
+		info.EthereumAddress = fmt.Sprintf("0x%x", ethAddr)
+	}
+	return info, nil
+}
+
+func printAccount(cmd *cobra.Command, acct *wallet.Account) error {
+	info, err := newAccountJSON(acct)
+	if err != nil {
+		return err
+	}
+	if common.OutputJSON() {
+		return common.PrintJSON(cmd.OutOrStdout(), info)
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 8, 1, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", info.Name)
+	fmt.Fprintf(w, "Algorithm:\t%s\n", info.Algorithm)
+	fmt.Fprintf(w, "Address:\t%s\n", info.Address)
+	if info.EthereumAddress != "" {
+		fmt.Fprintf(w, "Ethereum address:\t%s\n", info.EthereumAddress)
+	}
+	fmt.Fprintf(w, "Public key:\t%s\n", info.PublicKey)
+	if info.Path != "" {
+		fmt.Fprintf(w, "Derivation path:\t%s\n", info.Path)
+	}
+	fmt.Fprintf(w, "Created:\t%s\n", info.Created.Format("2006-01-02 15:04:05 MST"))
+	return w.Flush()
+}
+
+func init() {
+	algorithms := make([]string, 0, len(wallet.Algorithms))
+	for _, alg := range wallet.Algorithms {
+		algorithms = append(algorithms, string(alg))
+	}
+	for _, cmd := range []*cobra.Command{accountCreateCmd, accountImportCmd} {
+		cmd.Flags().StringVar(&accountAlgorithm, cfgAlgorithm, string(wallet.AlgorithmEd25519),
+			fmt.Sprintf("signature algorithm of the key (%s)", strings.Join(algorithms, ", ")))
+	}
+	accountImportCmd.Flags().Uint32Var(&accountNumber, cfgNumber, 0, "account number used to derive the key from the mnemonic")
+	accountImportCmd.Flags().StringVar(&accountPath, cfgPath, "", "derivation path of the key, overriding the account number")
+	accountImportCmd.Flags().BoolVar(&accountRaw, cfgRaw, false, "import a hex-encoded raw private key instead of a mnemonic")
+	accountImportCmd.Flags().StringVar(&accountFile, cfgFile, "", "import the account exported into this file instead of a mnemonic")
+
+	accountCmd.AddCommand(accountCreateCmd)
+	accountCmd.AddCommand(accountImportCmd)
+	accountCmd.AddCommand(accountListCmd)
+	accountCmd.AddCommand(accountShowCmd)
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/wallet"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+var (
+	accountOutput string
+
+	accountExportCmd = &cobra.Command{
+		Use:   "export <name>",
+		Short: "Export an account in the encrypted keystore format",
+		Long: `Export an account into the file given by --output, in the encrypted keystore format, so that it
+can be imported elsewhere with account import --file. The secret is re-encrypted under a new
+export passphrase, so that the passphrase of the account does not have to be shared. The new
+passphrase is taken from the ` + common.NewPassphraseEnvVar + ` environment variable if set.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			acct, err := common.GetAccount(args[0])
+			if err != nil {
+				return err
+			}
+			passphrase, err := common.Passphrase(acct.Name, false)
+			if err != nil {
+				return err
+			}
+			exportPassphrase, err := common.NewPassphrase("the export")
+			if err != nil {
+				return err
+			}
+			data, err := acct.Export(passphrase, exportPassphrase)
+			if err != nil {
+				return err
+			}
+			if accountOutput == "-" {
+				fmt.Fprintln(cmd.OutOrStdout(), string(data))
+			} else if err = os.WriteFile(accountOutput, append(data, '\n'), 0o600); err != nil {
+				return fmt.Errorf("failed to write exported account: %w", err)
+			}
+			return nil
+		},
+	}
+
+	accountPassphraseCmd = &cobra.Command{
+		Use:   "passphrase <name>",
+		Short: "Change the passphrase of an account",
+		Long: `Re-encrypt the secret of an account under a new passphrase, taken from the
+` + common.NewPassphraseEnvVar + ` environment variable if set.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			ks, err := common.Keystore()
+			if err != nil {
+				return err
+			}
+			passphrase, err := common.Passphrase(name, false)
+			if err != nil {
+				return err
+			}
+			newPassphrase, err := common.NewPassphrase("account " + name)
+			if err != nil {
+				return err
+			}
+			if err = ks.ChangePassphrase(name, passphrase, newPassphrase); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Changed the passphrase of account %s.\n", name)
+			return nil
+		},
+	}
+
+	accountRotateCmd = &cobra.Command{
+		Use:   "rotate <name> <new name>",
+		Short: "Rotate the key of an account",
+		Long: `Rotate the key of an account, e.g. of a service: create the account <new name> with a new
+random key of the same algorithm, transfer the native balance of <name> in the ParaTime to it,
+minus the fee of the transfer, and retire <name> by moving it out of the keystore into its
+retired subdirectory, where the old key can still be recovered from.
+
+Balances in other denominations, in other ParaTimes and on the consensus layer are not
+transferred.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			name, newName := args[0], args[1]
+			ks, err := common.Keystore()
+			if err != nil {
+				return err
+			}
+			acct, err := ks.Get(name)
+			if err != nil {
+				return err
+			}
+			if err = wallet.ValidateAccountName(newName); err != nil {
+				return err
+			}
+			if _, err = ks.Get(newName); err == nil {
+				return fmt.Errorf("%w: %s", wallet.ErrExists, newName)
+			}
+
+			rc, conn, err := common.RuntimeClient()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			balances, err := accounts.NewV1(rc).Balances(ctx, client.RoundLatest, acct.Address)
+			if err != nil {
+				return fmt.Errorf("failed to query balances: %w", err)
+			}
+			balance := balances.Balances[types.NativeDenomination]
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "Rotate account %s (%s) to new account %s\n", name, acct.Address, newName)
+			fmt.Fprintf(cmd.ErrOrStderr(), "Balance: %s\n", common.FormatAmount(&balance, common.Decimals()))
+			if err = common.Confirm("Create the new account, transfer the balance and retire the old account?"); err != nil {
+				return err
+			}
+
+			// Unlock the old account first, so that nothing is created in case of a wrong passphrase.
+			signer, err := common.AccountSigner(acct)
+			if err != nil {
+				return err
+			}
+			secret, err := wallet.GenerateSecret(acct.Algorithm)
+			if err != nil {
+				return err
+			}
+			passphrase, err := common.Passphrase(newName, true)
+			if err != nil {
+				return err
+			}
+			newAcct, err := ks.Create(newName, acct.Algorithm, secret, nil, passphrase)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Created account %s (%s).\n", newName, newAcct.Address)
+
+			if !balance.IsZero() {
+				// The gas does not depend on the amount, so estimate it for the whole balance and
+				// transfer what is left after the fee.
+				tb := accounts.NewV1(rc).Transfer(newAcct.Address, types.NewBaseUnits(balance, types.NativeDenomination))
+				if err = common.PrepareTx(ctx, rc, acct, tb); err != nil {
+					return err
+				}
+				authInfo := tb.GetTransaction().AuthInfo
+				amount := balance.Clone()
+				if err = amount.Sub(&authInfo.Fee.Amount.Amount); err != nil || amount.IsZero() {
+					fmt.Fprintf(cmd.ErrOrStderr(), "The balance does not cover the fee, nothing was transferred.\n")
+				} else {
+					tb = accounts.NewV1(rc).Transfer(newAcct.Address, types.NewBaseUnits(*amount, types.NativeDenomination))
+					tb.GetTransaction().AuthInfo = authInfo
+					meta, subErr := common.SignAndSubmitTxWith(ctx, signer, tb, nil)
+					if subErr != nil {
+						return fmt.Errorf("failed to transfer the balance, account %s was not retired: %w", name, subErr)
+					}
+					fmt.Fprintf(cmd.ErrOrStderr(), "Transferred %s in round %d.\n", common.FormatAmount(amount, common.Decimals()), meta.Round)
+				}
+			}
+
+			path, err := ks.Retire(name)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Retired account %s into %s.\n", name, path)
+			return nil
+		},
+	}
+)
+
+func init() {
+	accountExportCmd.Flags().StringVarP(&accountOutput, cfgOutput, "o", "-", "file the exported account is written to (- for standard output)")
+
+	accountRotateCmd.Flags().AddFlagSet(common.ConnectionFlags)
+	accountRotateCmd.Flags().AddFlagSet(common.AmountFlags)
+	for _, name := range []string{common.CfgGasLimit, common.CfgGasPrice, common.CfgYes} {
+		accountRotateCmd.Flags().AddFlag(common.TxFlags.Lookup(name))
+	}
+
+	accountCmd.AddCommand(accountExportCmd)
+	accountCmd.AddCommand(accountPassphraseCmd)
+	accountCmd.AddCommand(accountRotateCmd)
+}
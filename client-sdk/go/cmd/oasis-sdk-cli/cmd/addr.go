@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/wallet"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/secp256k1"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const cfgCount = "count"
+
+var (
+	addrCount uint32
+
+	addrCmd = &cobra.Command{
+		Use:   "addr",
+		Short: "Convert and derive addresses",
+	}
+
+	addrShowCmd = &cobra.Command{
+		Use:   "show <address>",
+		Short: "Show all forms of an address",
+		Long: `Show the Bech32-encoded address of an account, along with its Ethereum address and public key
+where they can be derived.
+
+The account is given as a Bech32-encoded address, a 0x-prefixed Ethereum address, a public key
+given as <algorithm>:<public key>, e.g. secp256k1:Aw9Y..., or as the name of a keystore account.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			info, err := parseAddressInfo(args[0])
+			if err != nil {
+				return err
+			}
+			if common.OutputJSON() {
+				return common.PrintJSON(cmd.OutOrStdout(), info.toJSON())
+			}
+			return printAddressInfo(cmd.OutOrStdout(), info)
+		},
+	}
+
+	addrDeriveCmd = &cobra.Command{
+		Use:   "derive",
+		Short: "Derive addresses from a mnemonic",
+		Long: `Derive the addresses of accounts from a mnemonic, which is prompted for, without importing them
+into the keystore. Keys are derived like with account import, using the default derivation path
+of the algorithm for the account number given by --number, or the path given by --path. With
+--count, consecutive account numbers starting at --number are derived.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			alg, err := wallet.ParseAlgorithm(accountAlgorithm)
+			if err != nil {
+				return err
+			}
+			if addrCount == 0 {
+				return fmt.Errorf("--%s must be positive", cfgCount)
+			}
+			if addrCount > 1 && cmd.Flags().Changed(cfgPath) {
+				return fmt.Errorf("--%s and --%s are mutually exclusive", cfgCount, cfgPath)
+			}
+			if uint64(accountNumber)+uint64(addrCount)-1 > uint64(wallet.MaxAccountNumber) {
+				return fmt.Errorf("account numbers exceed %d", wallet.MaxAccountNumber)
+			}
+			path, err := importPath(cmd, alg)
+			if err != nil {
+				return err
+			}
+
+			mnemonic, err := common.PromptSecret("Mnemonic: ")
+			if err != nil {
+				return err
+			}
+			passphrase, err := common.PromptSecret("Mnemonic passphrase (empty if none): ")
+			if err != nil {
+				return err
+			}
+
+			var infos []*addressJSON
+			for i := uint32(0); i < addrCount; i++ {
+				if i > 0 {
+					if !common.OutputJSON() {
+						fmt.Fprintln(cmd.OutOrStdout())
+					}
+					if path, err = wallet.DefaultPath(alg, accountNumber+i); err != nil {
+						return err
+					}
+				}
+				secret, deriveErr := wallet.DeriveSecret(alg, mnemonic, passphrase, path)
+				if deriveErr != nil {
+					return deriveErr
+				}
+				signer, signerErr := wallet.NewSigner(alg, secret)
+				if signerErr != nil {
+					return signerErr
+				}
+				info, infoErr := newAddressInfoFromPublicKey(alg, signer.Public())
+				if infoErr != nil {
+					return infoErr
+				}
+				info.Path = path
+				if common.OutputJSON() {
+					infos = append(infos, info.toJSON())
+					continue
+				}
+				if err = printAddressInfo(cmd.OutOrStdout(), info); err != nil {
+					return err
+				}
+			}
+			if common.OutputJSON() {
+				return common.PrintJSON(cmd.OutOrStdout(), infos)
+			}
+			return nil
+		},
+	}
+)
+
+// addressInfo are the known forms of an address.
+type addressInfo struct {
+	Address types.Address
+	// EthAddress is the Ethereum address, if known.
+	EthAddress []byte
+	// Algorithm is the algorithm of the public key, if known.
+	Algorithm wallet.Algorithm
+	// PublicKey is the public key, if known.
+	PublicKey signature.PublicKey
+	// Path is the derivation path of the key, if derived.
+	Path wallet.Path
+}
+
+// addressJSON is the JSON form of an address.
+type addressJSON struct {
+	Address         types.Address `json:"address"`
+	EthereumAddress string        `json:"eth_address,omitempty"`
+	PublicKey       string        `json:"public_key,omitempty"`
+	Path            string        `json:"path,omitempty"`
+}
+
+func (info *addressInfo) toJSON() *addressJSON {
+	out := &addressJSON{Address: info.Address}
+	if info.EthAddress != nil {
+		out.EthereumAddress = fmt.Sprintf("0x%x", info.EthAddress)
+	}
+	if info.PublicKey != nil {
+		out.PublicKey = fmt.Sprintf("%s:%s", info.Algorithm, info.PublicKey)
+	}
+	if info.Path != nil {
+		out.Path = info.Path.String()
+	}
+	return out
+}
+
+func newAddressInfoFromPublicKey(alg wallet.Algorithm, pk signature.PublicKey) (*addressInfo, error) {
+	spec, err := wallet.SigSpec(pk)
+	if err != nil {
+		return nil, err
+	}
+	info := &addressInfo{
+		Address:   types.NewAddress(spec),
+		Algorithm: alg,
+		PublicKey: pk,
+	}
+	if secpPk, ok := pk.(secp256k1.PublicKey); ok {
+		if info.EthAddress, err = wallet.EthereumAddress(secpPk); err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
+
+// parseAddressInfo parses an address given in any of the forms supported by addr show.
+func parseAddressInfo(s string) (*addressInfo, error) {
+	switch {
+	case strings.HasPrefix(s, "0x"):
+		ethAddr, err := hex.DecodeString(s[2:])
+		if err != nil {
+			return nil, fmt.Errorf("malformed Ethereum address: %w", err)
+		}
+		addr, err := wallet.AddressFromEthereum(ethAddr)
+		if err != nil {
+			return nil, err
+		}
+		return &addressInfo{Address: addr, EthAddress: ethAddr}, nil
+	case strings.Contains(s, ":"):
+		pk, err := wallet.ParsePublicKey(s)
+		if err != nil {
+			return nil, err
+		}
+		return newAddressInfoFromPublicKey(wallet.Algorithm(s[:strings.IndexByte(s, ':')]), pk)
+	case strings.HasPrefix(s, types.AddressBech32HRP.String()+"1"):
+		var addr types.Address
+		if err := addr.UnmarshalText([]byte(s)); err != nil {
+			return nil, fmt.Errorf("malformed address: %w", err)
+		}
+		return &addressInfo{Address: addr}, nil
+	default:
+		acct, err := common.GetAccount(s)
+		if err != nil {
+			return nil, err
+		}
+		pk, err := acct.PublicKeyValue()
+		if err != nil {
+			return nil, err
+		}
+		return newAddressInfoFromPublicKey(acct.Algorithm, pk)
+	}
+}
+
+func printAddressInfo(w io.Writer, info *addressInfo) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	if info.Path != nil {
+		fmt.Fprintf(tw, "Derivation path:\t%s\n", info.Path)
+	}
+	fmt.Fprintf(tw, "Address:\t%s\n", info.Address)
+	if info.EthAddress != nil {
+		fmt.Fprintf(tw, "Ethereum address:\t0x%x\n", info.EthAddress)
+	}
+	if info.PublicKey != nil {
+		fmt.Fprintf(tw, "Public key:\t%s:%s\n", info.Algorithm, info.PublicKey)
+	}
+	return tw.Flush()
+}
+
+func init() {
+	addrDeriveCmd.Flags().StringVar(&accountAlgorithm, cfgAlgorithm, string(wallet.AlgorithmEd25519), "signature algorithm of the key")
+	addrDeriveCmd.Flags().Uint32Var(&accountNumber, cfgNumber, 0, "account number used to derive the key from the mnemonic")
+	addrDeriveCmd.Flags().StringVar(&accountPath, cfgPath, "", "derivation path of the key, overriding the account number")
+	addrDeriveCmd.Flags().Uint32Var(&addrCount, cfgCount, 1, "number of consecutive accounts to derive")
+
+	addrCmd.AddCommand(addrShowCmd)
+	addrCmd.AddCommand(addrDeriveCmd)
+}
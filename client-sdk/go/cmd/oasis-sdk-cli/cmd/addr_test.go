@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/wallet"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+)
+
+func TestParseAddressInfo(t *testing.T) {
+	require := require.New(t)
+
+	pk := sdkTesting.Dave.Signer.Public()
+	fromPk, err := parseAddressInfo("secp256k1:" + pk.String())
+	require.NoError(err, "parseAddressInfo")
+	require.Equal(sdkTesting.Dave.Address, fromPk.Address)
+	require.Equal(wallet.AlgorithmSecp256k1, fromPk.Algorithm)
+	require.Len(fromPk.EthAddress, wallet.EthereumAddressSize)
+	fromPkJSON := fromPk.toJSON()
+	require.Equal("secp256k1:"+pk.String(), fromPkJSON.PublicKey)
+	require.Equal("0x"+hex.EncodeToString(fromPk.EthAddress), fromPkJSON.EthereumAddress)
+
+	fromEth, err := parseAddressInfo("0x" + hex.EncodeToString(fromPk.EthAddress))
+	require.NoError(err, "parseAddressInfo")
+	require.Equal(sdkTesting.Dave.Address, fromEth.Address)
+	require.Nil(fromEth.PublicKey)
+	require.Empty(fromEth.toJSON().PublicKey)
+
+	fromBech32, err := parseAddressInfo(sdkTesting.Dave.Address.String())
+	require.NoError(err, "parseAddressInfo")
+	require.Equal(sdkTesting.Dave.Address, fromBech32.Address)
+
+	for _, s := range []string{"0x1234", "0xzz", "ed25519:AAAA", "oasis1malformed"} {
+		_, err = parseAddressInfo(s)
+		require.Error(err, "parseAddressInfo(%s) should fail", s)
+	}
+}
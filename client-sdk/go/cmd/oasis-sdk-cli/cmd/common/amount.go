@@ -0,0 +1,102 @@
+package common
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+)
+
+const (
+	// CfgDecimals is the flag setting the number of decimals of amounts.
+	CfgDecimals = "decimals"
+
+	// DefaultDecimals is the default number of decimals of amounts, as used by ROSE.
+	DefaultDecimals = 9
+	// MaxDecimals is the maximum number of decimals of a token.
+	MaxDecimals = 36
+)
+
+// AmountFlags are the flags of commands that parse or display amounts.
+var AmountFlags = flag.NewFlagSet("", flag.ContinueOnError)
+
+var decimals uint8
+
+// Decimals returns the number of decimals of ParaTime amounts given by --decimals or, if it is not
+// set, by the selected ParaTime profile.
+func Decimals() uint8 {
+	if !AmountFlags.Changed(CfgDecimals) {
+		if _, pt, err := ParaTime(); err == nil && pt != nil {
+			return pt.Denomination.Decimals
+		}
+	}
+	return decimals
+}
+
+// ConsensusDecimals returns the number of decimals of consensus layer amounts given by --decimals
+// or, if it is not set, by the selected network profile.
+func ConsensusDecimals() uint8 {
+	if !AmountFlags.Changed(CfgDecimals) {
+		if n, err := Network(); err == nil && n != nil {
+			return n.Denomination.Decimals
+		}
+	}
+	return decimals
+}
+
+// ParseAmount parses a human-readable amount of tokens with the given number of decimals, e.g.
+// 1.5 with 9 decimals, into base units.
+func ParseAmount(s string, decimals uint8) (*quantity.Quantity, error) {
+	if decimals > MaxDecimals {
+		return nil, fmt.Errorf("invalid number of decimals: %d (maximum: %d)", decimals, MaxDecimals)
+	}
+
+	whole, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, fraction = s[:i], s[i+1:]
+	}
+	if whole == "" && fraction == "" {
+		return nil, fmt.Errorf("malformed amount: %q", s)
+	}
+	if len(fraction) > int(decimals) {
+		return nil, fmt.Errorf("malformed amount %s: more than %d decimals", s, decimals)
+	}
+	digits := whole + fraction + strings.Repeat("0", int(decimals)-len(fraction))
+	if strings.ContainsAny(digits, "+-") {
+		return nil, fmt.Errorf("malformed amount: %q", s)
+	}
+
+	v, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("malformed amount: %q", s)
+	}
+	q := quantity.NewQuantity()
+	if err := q.FromBigInt(v); err != nil {
+		return nil, fmt.Errorf("malformed amount %s: %w", s, err)
+	}
+	return q, nil
+}
+
+// FormatAmount formats an amount of base units as a human-readable amount of tokens with the
+// given number of decimals, omitting trailing zeros.
+func FormatAmount(q *quantity.Quantity, decimals uint8) string {
+	digits := q.ToBigInt().String()
+	if decimals == 0 {
+		return digits
+	}
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-int(decimals)], strings.TrimRight(digits[len(digits)-int(decimals):], "0")
+	if fraction == "" {
+		return whole
+	}
+	return whole + "." + fraction
+}
+
+func init() {
+	AmountFlags.Uint8Var(&decimals, CfgDecimals, DefaultDecimals, "number of decimals of amounts")
+}
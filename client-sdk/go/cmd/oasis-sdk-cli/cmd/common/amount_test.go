@@ -0,0 +1,36 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAmount(t *testing.T) {
+	require := require.New(t)
+
+	for _, tc := range []struct {
+		amount    string
+		decimals  uint8
+		baseUnits string
+		formatted string
+	}{
+		{"1", 9, "1000000000", "1"},
+		{"1.5", 9, "1500000000", "1.5"},
+		{".000000001", 9, "1", "0.000000001"},
+		{"10.", 9, "10000000000", "10"},
+		{"0", 9, "0", "0"},
+		{"42", 0, "42", "42"},
+		{"1.000000000000000001", 18, "1000000000000000001", "1.000000000000000001"},
+	} {
+		q, err := ParseAmount(tc.amount, tc.decimals)
+		require.NoError(err, "ParseAmount(%q)", tc.amount)
+		require.Equal(tc.baseUnits, q.String(), "ParseAmount(%q)", tc.amount)
+		require.Equal(tc.formatted, FormatAmount(q, tc.decimals), "FormatAmount(%q)", tc.amount)
+	}
+
+	for _, malformed := range []string{"", ".", "-1", "+1", "1.0000000001", "1,5", "1e9", "0x10"} {
+		_, err := ParseAmount(malformed, 9)
+		require.Error(err, "ParseAmount should fail for %q", malformed)
+	}
+}
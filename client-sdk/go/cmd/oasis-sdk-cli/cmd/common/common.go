@@ -0,0 +1,53 @@
+// Package common implements helpers shared by the commands of the CLI.
+package common
+
+import (
+	"os"
+	"path/filepath"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/wallet"
+)
+
+const (
+	// CfgConfigDir is the flag naming the configuration directory.
+	CfgConfigDir = "config-dir"
+
+	// PassphraseEnvVar is the name of the environment variable that, if set, provides the account
+	// passphrase instead of prompting for it, e.g. in scripts.
+	PassphraseEnvVar = "OASIS_CLI_PASSPHRASE"
+	// NewPassphraseEnvVar is the name of the environment variable that, if set, provides the new
+	// passphrase when changing the passphrase of an account or exporting it.
+	NewPassphraseEnvVar = "OASIS_CLI_NEW_PASSPHRASE"
+
+	appName     = "oasis-sdk-cli"
+	keystoreDir = "accounts"
+)
+
+// RootFlags are the flags shared by all commands.
+var RootFlags = flag.NewFlagSet("", flag.ContinueOnError)
+
+var configDir string
+
+// ConfigDir returns the configuration directory.
+func ConfigDir() string {
+	return configDir
+}
+
+// Keystore opens the keystore in the configuration directory.
+func Keystore() (*wallet.Keystore, error) {
+	return wallet.OpenKeystore(filepath.Join(ConfigDir(), keystoreDir))
+}
+
+func defaultConfigDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "." + appName
+	}
+	return filepath.Join(dir, appName)
+}
+
+func init() {
+	RootFlags.StringVar(&configDir, CfgConfigDir, defaultConfigDir(), "configuration directory")
+}
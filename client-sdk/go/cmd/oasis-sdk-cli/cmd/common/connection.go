@@ -0,0 +1,132 @@
+package common
+
+import (
+	"fmt"
+
+	flag "github.com/spf13/pflag"
+	"google.golang.org/grpc"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+)
+
+const (
+	// CfgNode is the flag naming the gRPC endpoint of the node to connect to.
+	CfgNode = "node"
+	// CfgRuntimeID is the flag naming the ParaTime to use. Commands operating on several ParaTimes
+	// accept it multiple times.
+	CfgRuntimeID = "runtime-id"
+	// CfgInsecure is the flag disabling TLS for TCP connections.
+	CfgInsecure = "insecure"
+	// CfgCompression is the flag naming the compressor of gRPC requests and responses.
+	CfgCompression = "compression"
+	// CfgNetwork is the flag naming the network profile to use.
+	CfgNetwork = "network"
+	// CfgParaTime is the flag naming the ParaTime profile of the network to use.
+	CfgParaTime = "paratime"
+)
+
+// ConnectionFlags are the flags of commands that connect to a node.
+var ConnectionFlags = flag.NewFlagSet("", flag.ContinueOnError)
+
+var (
+	nodeAddress  string
+	runtimeIDs   []string
+	insecure     bool
+	compression  string
+	networkName  string
+	paraTimeName string
+)
+
+// Connect connects to the node given by the connection flags, or by the selected network profile.
+//
+// UNIX socket addresses (unix:<path>) are always connected to without TLS, TCP addresses use TLS
+// unless --insecure is set.
+func Connect() (*grpc.ClientConn, error) {
+	address := nodeAddress
+	if address == "" {
+		n, err := Network()
+		if err != nil {
+			return nil, err
+		}
+		if n == nil {
+			return nil, fmt.Errorf("no node address given, use --%s or --%s", CfgNode, CfgNetwork)
+		}
+		address = n.RPC
+	}
+
+	conn, err := client.Dial(address, &client.DialConfig{
+		Insecure:    insecure,
+		Compression: compression,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	return conn, nil
+}
+
+// RuntimeID returns the ParaTime identifier given by the connection flags.
+func RuntimeID() (common.Namespace, error) {
+	ids, err := RuntimeIDs()
+	if err != nil {
+		return common.Namespace{}, err
+	}
+	switch len(ids) {
+	case 0:
+		return common.Namespace{}, fmt.Errorf("no ParaTime given, use --%s or --%s", CfgRuntimeID, CfgParaTime)
+	case 1:
+		return ids[0], nil
+	default:
+		return common.Namespace{}, fmt.Errorf("more than one ParaTime given")
+	}
+}
+
+// RuntimeIDs returns all ParaTime identifiers given by the connection flags or, if none are given,
+// the identifier of the selected ParaTime profile.
+func RuntimeIDs() ([]common.Namespace, error) {
+	if len(runtimeIDs) == 0 {
+		_, pt, err := ParaTime()
+		if err != nil || pt == nil {
+			return nil, err
+		}
+		id, err := pt.Namespace()
+		if err != nil {
+			return nil, err
+		}
+		return []common.Namespace{id}, nil
+	}
+
+	ids := make([]common.Namespace, 0, len(runtimeIDs))
+	for _, raw := range runtimeIDs {
+		var id common.Namespace
+		if err := id.UnmarshalHex(raw); err != nil {
+			return nil, fmt.Errorf("malformed ParaTime identifier %s: %w", raw, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// RuntimeClient connects to the node and returns a client of the ParaTime given by the
+// connection flags. The returned connection should be closed by the caller.
+func RuntimeClient() (client.RuntimeClient, *grpc.ClientConn, error) {
+	id, err := RuntimeID()
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := Connect()
+	if err != nil {
+		return nil, nil, err
+	}
+	return client.New(conn, id), conn, nil
+}
+
+func init() {
+	ConnectionFlags.StringVar(&nodeAddress, CfgNode, "", "gRPC address of the node, e.g. unix:/path/to/internal.sock or host:port")
+	ConnectionFlags.StringSliceVar(&runtimeIDs, CfgRuntimeID, nil, "hex-encoded ParaTime identifier")
+	ConnectionFlags.BoolVar(&insecure, CfgInsecure, false, "connect to TCP addresses without TLS")
+	ConnectionFlags.StringVar(&compression, CfgCompression, "", "compress gRPC requests and responses with gzip or zstd (zstd needs node support)")
+	ConnectionFlags.StringVar(&networkName, CfgNetwork, "", "network profile to use (the default network unless --node is given)")
+	ConnectionFlags.StringVar(&paraTimeName, CfgParaTime, "", "ParaTime profile of the network to use (its default ParaTime unless --runtime-id is given)")
+}
@@ -0,0 +1,48 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	// CfgFormat is the flag selecting the output format of command results.
+	CfgFormat = "format"
+
+	// FormatText prints human-readable results.
+	FormatText = "text"
+	// FormatJSON prints results as JSON, e.g. for processing with jq.
+	FormatJSON = "json"
+)
+
+var outputFormat string
+
+// ValidateFormat checks the output format given by --format.
+func ValidateFormat() error {
+	switch outputFormat {
+	case FormatText, FormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format: %s (supported: %s, %s)", outputFormat, FormatText, FormatJSON)
+	}
+}
+
+// OutputJSON returns true if results are to be printed as JSON.
+func OutputJSON() bool {
+	return outputFormat == FormatJSON
+}
+
+// PrintJSON prints the indented JSON encoding of a result.
+func PrintJSON(w io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+func init() {
+	RootFlags.StringVar(&outputFormat, CfgFormat, FormatText, "output format of results: text or json")
+}
@@ -0,0 +1,45 @@
+package common
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func TestOutputFormat(t *testing.T) {
+	require := require.New(t)
+
+	defer func(format string) { outputFormat = format }(outputFormat)
+	for _, tc := range []struct {
+		format string
+		valid  bool
+		json   bool
+	}{
+		{FormatText, true, false},
+		{FormatJSON, true, true},
+		{"yaml", false, false},
+		{"", false, false},
+	} {
+		outputFormat = tc.format
+		if tc.valid {
+			require.NoError(ValidateFormat(), "ValidateFormat(%q)", tc.format)
+		} else {
+			require.Error(ValidateFormat(), "ValidateFormat(%q) should fail", tc.format)
+		}
+		require.Equal(tc.json, OutputJSON(), "OutputJSON(%q)", tc.format)
+	}
+
+	var buf bytes.Buffer
+	err := PrintJSON(&buf, &struct {
+		Address types.Address     `json:"address"`
+		Amount  quantity.Quantity `json:"amount"`
+	}{sdkTesting.Alice.Address, *quantity.NewFromUint64(1000)})
+	require.NoError(err, "PrintJSON")
+	require.Equal("{\n  \"address\": \""+sdkTesting.Alice.Address.String()+"\",\n  \"amount\": \"1000\"\n}\n", buf.String())
+}
@@ -0,0 +1,79 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/config"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+)
+
+// Config loads the configuration from the configuration directory.
+func Config() (*config.Config, error) {
+	return config.Load(ConfigDir())
+}
+
+// Network returns the network profile given by --network or, unless the node is given by --node,
+// the default network. It returns nil if no network profile is selected.
+func Network() (*config.Network, error) {
+	if networkName == "" && nodeAddress != "" {
+		return nil, nil
+	}
+	cfg, err := Config()
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Network(networkName)
+}
+
+// ParaTime returns the network and ParaTime profile given by --paratime or the default ParaTime
+// of the network, unless ParaTimes are given by --runtime-id. It returns a nil ParaTime if no
+// ParaTime profile is selected.
+func ParaTime() (*config.Network, *config.ParaTime, error) {
+	if len(runtimeIDs) > 0 {
+		if paraTimeName != "" {
+			return nil, nil, fmt.Errorf("--%s and --%s are mutually exclusive", CfgParaTime, CfgRuntimeID)
+		}
+		return nil, nil, nil
+	}
+	n, err := Network()
+	if err != nil {
+		return nil, nil, err
+	}
+	if n == nil {
+		if paraTimeName != "" {
+			return nil, nil, fmt.Errorf("--%s requires a network, use --%s", CfgParaTime, CfgNetwork)
+		}
+		return nil, nil, nil
+	}
+	pt, err := n.ParaTime(paraTimeName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return n, pt, nil
+}
+
+// ParaTimeName returns the name of the ParaTime profile given by --paratime or of the default
+// ParaTime of the network. It returns an empty name if no ParaTime profile is selected.
+func ParaTimeName() (string, error) {
+	n, pt, err := ParaTime()
+	if err != nil || pt == nil {
+		return "", err
+	}
+	if paraTimeName != "" {
+		return paraTimeName, nil
+	}
+	return n.DefaultParaTime, nil
+}
+
+// ChainContext returns the chain context of the selected ParaTime profile, so that transactions
+// can be signed without querying the node.
+func ChainContext() (signature.Context, error) {
+	n, pt, err := ParaTime()
+	if err != nil {
+		return "", err
+	}
+	if pt == nil {
+		return "", fmt.Errorf("no ParaTime profile selected")
+	}
+	return n.ParaTimeChainContext(pt)
+}
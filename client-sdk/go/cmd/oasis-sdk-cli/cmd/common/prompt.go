@@ -0,0 +1,92 @@
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// stdin is shared by all prompts, so that buffered input is not lost between prompts.
+var stdin = bufio.NewReader(os.Stdin)
+
+// readLine reads a line from standard input, without echoing it in case it is a terminal and
+// hidden is set.
+func readLine(hidden bool) (string, error) {
+	if hidden {
+		fd := int(os.Stdin.Fd())
+		if restore, err := disableEcho(fd); err == nil {
+			defer func() {
+				restore()
+				fmt.Fprintln(os.Stderr)
+			}()
+		}
+	}
+
+	line, err := stdin.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// Prompt prints the given prompt to standard error and reads a line from standard input.
+func Prompt(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	return readLine(false)
+}
+
+// PromptSecret prints the given prompt to standard error and reads a line from standard input
+// without echoing it.
+func PromptSecret(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	return readLine(true)
+}
+
+// Passphrase returns the passphrase of an account, taken from the environment variable named by
+// PassphraseEnvVar if set, or read from standard input otherwise. New passphrases have to be
+// entered twice.
+func Passphrase(account string, isNew bool) (string, error) {
+	if passphrase, ok := os.LookupEnv(PassphraseEnvVar); ok {
+		return passphrase, nil
+	}
+
+	passphrase, err := PromptSecret(fmt.Sprintf("Passphrase for account %s: ", account))
+	if err != nil {
+		return "", err
+	}
+	if !isNew {
+		return passphrase, nil
+	}
+	repeated, err := PromptSecret("Repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase != repeated {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+// NewPassphrase returns a new passphrase, e.g. the one an account is re-encrypted under, taken from
+// the environment variable named by NewPassphraseEnvVar if set, or read twice from standard input
+// otherwise. The purpose describes what the passphrase is for in the prompt.
+func NewPassphrase(purpose string) (string, error) {
+	if passphrase, ok := os.LookupEnv(NewPassphraseEnvVar); ok {
+		return passphrase, nil
+	}
+
+	passphrase, err := PromptSecret(fmt.Sprintf("New passphrase for %s: ", purpose))
+	if err != nil {
+		return "", err
+	}
+	repeated, err := PromptSecret("Repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase != repeated {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+	return passphrase, nil
+}
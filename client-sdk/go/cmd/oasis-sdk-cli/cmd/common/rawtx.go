@@ -0,0 +1,97 @@
+package common
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// RawTx is a CBOR-encoded transaction, either signed or not.
+type RawTx struct {
+	// Raw is the CBOR encoding of the transaction.
+	Raw []byte
+	// Unverified is the signed transaction, or nil if the transaction is not signed.
+	Unverified *types.UnverifiedTransaction
+	// Tx is the transaction.
+	Tx *types.Transaction
+}
+
+// ReadRawTx reads a CBOR-encoded transaction from the given file, or from standard input if the
+// path is "-". The file may contain the raw encoding or its hex or base64 encoding.
+func ReadRawTx(path string) (*RawTx, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transaction: %w", err)
+	}
+
+	if tx, err := DecodeRawTx(data); err == nil {
+		return tx, nil
+	}
+	return ParseRawTx(string(bytes.TrimSpace(data)))
+}
+
+// WriteRawTx writes a CBOR-encoded transaction to the given file or, if the path is "-", its hex
+// encoding to the given writer.
+func WriteRawTx(path string, w io.Writer, data []byte) error {
+	if path == "-" {
+		_, err := fmt.Fprintln(w, hex.EncodeToString(data))
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write transaction: %w", err)
+	}
+	return nil
+}
+
+// ParseRawTx parses a hex or base64-encoded CBOR-encoded transaction.
+func ParseRawTx(s string) (*RawTx, error) {
+	s = strings.TrimSpace(s)
+	if data, err := hex.DecodeString(strings.TrimPrefix(s, "0x")); err == nil {
+		return DecodeRawTx(data)
+	}
+	if data, err := base64.StdEncoding.DecodeString(s); err == nil {
+		return DecodeRawTx(data)
+	}
+	return nil, fmt.Errorf("malformed transaction: neither hex nor base64")
+}
+
+// DecodeRawTx decodes a CBOR-encoded signed or unsigned transaction.
+func DecodeRawTx(data []byte) (*RawTx, error) {
+	var utx types.UnverifiedTransaction
+	if err := cbor.Unmarshal(data, &utx); err == nil {
+		var tx types.Transaction
+		if err = cbor.Unmarshal(utx.Body, &tx); err != nil {
+			return nil, fmt.Errorf("malformed transaction body: %w", err)
+		}
+		if err = tx.ValidateBasic(); err != nil {
+			return nil, fmt.Errorf("malformed transaction: %w", err)
+		}
+		return &RawTx{Raw: data, Unverified: &utx, Tx: &tx}, nil
+	}
+
+	// Unsigned transactions may not have any signers yet.
+	var tx types.Transaction
+	if err := cbor.Unmarshal(data, &tx); err != nil {
+		return nil, fmt.Errorf("malformed transaction: %w", err)
+	}
+	if tx.V != types.LatestTransactionVersion {
+		return nil, fmt.Errorf("malformed transaction: unsupported version %d", tx.V)
+	}
+	return &RawTx{Raw: data, Tx: &tx}, nil
+}
@@ -0,0 +1,52 @@
+package common
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func TestRawTx(t *testing.T) {
+	require := require.New(t)
+
+	tx := types.NewTransaction(nil, "accounts.Transfer", map[string]interface{}{
+		"to":     sdkTesting.Bob.Address,
+		"amount": types.NewBaseUnits(*quantity.NewFromUint64(1000), types.NativeDenomination),
+	})
+	tx.AppendAuthSignature(sdkTesting.Alice.SigSpec, 42)
+	raw := cbor.Marshal(tx)
+
+	for _, encoded := range []string{hex.EncodeToString(raw), "0x" + hex.EncodeToString(raw), base64.StdEncoding.EncodeToString(raw)} {
+		rawTx, err := ParseRawTx(encoded)
+		require.NoError(err, "ParseRawTx")
+		require.Nil(rawTx.Unverified, "transaction should not be signed")
+		require.Equal("accounts.Transfer", rawTx.Tx.Call.Method)
+		require.EqualValues(42, rawTx.Tx.AuthInfo.SignerInfo[0].Nonce)
+	}
+
+	ts := tx.PrepareForSigning()
+	require.NoError(ts.AppendSign(signature.Context("test chain context"), sdkTesting.Alice.Signer), "AppendSign")
+	signed := cbor.Marshal(ts.UnverifiedTransaction())
+
+	path := filepath.Join(t.TempDir(), "tx.cbor")
+	require.NoError(os.WriteFile(path, signed, 0o600))
+	rawTx, err := ReadRawTx(path)
+	require.NoError(err, "ReadRawTx")
+	require.NotNil(rawTx.Unverified, "transaction should be signed")
+	require.Equal(signed, rawTx.Raw)
+	require.Len(rawTx.Unverified.AuthProofs, 1)
+
+	_, err = ParseRawTx("not a transaction")
+	require.Error(err, "malformed transactions should be rejected")
+}
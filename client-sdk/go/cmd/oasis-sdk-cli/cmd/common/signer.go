@@ -0,0 +1,82 @@
+package common
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/wallet"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+)
+
+const (
+	// CfgSigner is the flag selecting the kind of signer of transactions.
+	CfgSigner = "signer"
+	// CfgLedgerPlugin is the flag naming the Oasis Core Ledger signer plugin binary.
+	CfgLedgerPlugin = "ledger-plugin"
+	// CfgLedgerWalletID is the flag selecting the Ledger wallet.
+	CfgLedgerWalletID = "ledger-wallet-id"
+	// CfgLedgerIndex is the flag selecting the account index on the Ledger device.
+	CfgLedgerIndex = "ledger-index"
+
+	// SignerKeystore signs transactions with the keystore account given by --account.
+	SignerKeystore = "keystore"
+	// SignerLedger signs transactions with a Ledger device.
+	SignerLedger = "ledger"
+)
+
+var (
+	txSigner       string
+	ledgerPlugin   string
+	ledgerWalletID string
+	ledgerIndex    uint32
+
+	ledgerSigner  signature.Signer
+	ledgerAccount *wallet.Account
+)
+
+// openLedger returns the account of the Ledger device selected by the --ledger-* flags, starting
+// the signer plugin on first use.
+func openLedger() (*wallet.Account, error) {
+	if ledgerAccount != nil {
+		return ledgerAccount, nil
+	}
+	cfg := &wallet.LedgerConfig{
+		PluginPath: ledgerPlugin,
+		WalletID:   ledgerWalletID,
+		Index:      ledgerIndex,
+	}
+	if cfg.PluginPath == "" {
+		return nil, fmt.Errorf("no Ledger signer plugin given, use --%s", CfgLedgerPlugin)
+	}
+	signer, err := wallet.NewLedgerSigner(cfg)
+	if err != nil {
+		return nil, err
+	}
+	acct, err := wallet.NewLedgerAccount(cfg, signer)
+	if err != nil {
+		return nil, err
+	}
+	ledgerSigner, ledgerAccount = signer, acct
+	return acct, nil
+}
+
+// AccountSigner returns the signer of an account returned by TxAccount. Keystore accounts are
+// unlocked with their passphrase, while Ledger signatures have to be confirmed on the device.
+func AccountSigner(acct *wallet.Account) (signature.Signer, error) {
+	if ledgerAccount != nil && acct == ledgerAccount {
+		fmt.Fprintln(os.Stderr, "Confirm the transaction on the Ledger device.")
+		return ledgerSigner, nil
+	}
+	passphrase, err := Passphrase(acct.Name, false)
+	if err != nil {
+		return nil, err
+	}
+	return acct.Signer(passphrase)
+}
+
+func init() {
+	TxFlags.StringVar(&txSigner, CfgSigner, SignerKeystore, "signer of the transaction: keystore (the account given by --account) or ledger")
+	TxFlags.StringVar(&ledgerPlugin, CfgLedgerPlugin, "", "path to the Oasis Core Ledger signer plugin, with --signer ledger")
+	TxFlags.StringVar(&ledgerWalletID, CfgLedgerWalletID, "", "hex-encoded identifier of the Ledger wallet (the first connected device if not given)")
+	TxFlags.Uint32Var(&ledgerIndex, CfgLedgerIndex, 0, "account index of the key on the Ledger device")
+}
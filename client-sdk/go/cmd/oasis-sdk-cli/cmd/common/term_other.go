@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd
+
+package common
+
+import "fmt"
+
+func disableEcho(fd int) (func(), error) {
+	return nil, fmt.Errorf("disabling echo not supported on this platform")
+}
@@ -0,0 +1,26 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+// +build linux darwin freebsd netbsd openbsd
+
+package common
+
+import "golang.org/x/sys/unix"
+
+// disableEcho disables echoing input on the terminal with the given file descriptor, returning a
+// function restoring the previous state. It fails in case the file descriptor is not a terminal.
+func disableEcho(fd int) (func(), error) {
+	termios, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return nil, err
+	}
+	saved := *termios
+
+	termios.Lflag &^= unix.ECHO
+	termios.Lflag |= unix.ICANON | unix.ISIG
+	termios.Iflag |= unix.ICRNL
+	if err = unix.IoctlSetTermios(fd, ioctlSetTermios, termios); err != nil {
+		return nil, err
+	}
+	return func() {
+		_ = unix.IoctlSetTermios(fd, ioctlSetTermios, &saved)
+	}, nil
+}
@@ -0,0 +1,189 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/wallet"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/core"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const (
+	// CfgAccount is the flag naming the keystore account signing transactions.
+	CfgAccount = "account"
+	// CfgGasLimit is the flag setting the gas limit of transactions.
+	CfgGasLimit = "gas-limit"
+	// CfgGasPrice is the flag setting the gas price of transactions.
+	CfgGasPrice = "gas-price"
+	// CfgYes is the flag skipping confirmation prompts.
+	CfgYes = "yes"
+	// CfgUnsigned is the flag naming the file the unsigned transaction is written to instead of
+	// signing and submitting it.
+	CfgUnsigned = "unsigned"
+)
+
+// TxFlags are the flags of commands that sign and submit transactions.
+var TxFlags = flag.NewFlagSet("", flag.ContinueOnError)
+
+var (
+	txAccount  string
+	txGasLimit uint64
+	txGasPrice uint64
+	assumeYes  bool
+	txUnsigned string
+)
+
+// UnsignedTxPath returns the path of the file the unsigned transaction should be written to, or an
+// empty string if the transaction should be signed and submitted.
+func UnsignedTxPath() string {
+	return txUnsigned
+}
+
+// GasLimit returns the gas limit given by --gas-limit, zero if it should be estimated.
+func GasLimit() uint64 {
+	return txGasLimit
+}
+
+// GasPrice returns the gas price given by --gas-price.
+func GasPrice() uint64 {
+	return txGasPrice
+}
+
+// Confirm asks the user to confirm an action, unless --yes is set.
+func Confirm(question string) error {
+	if assumeYes {
+		return nil
+	}
+	answer, err := Prompt(question + " [y/N]: ")
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return fmt.Errorf("aborted")
+	}
+}
+
+// TxAccount returns the account signing transactions, either the keystore account given by
+// --account or the account of the Ledger device with --signer ledger.
+func TxAccount() (*wallet.Account, error) {
+	switch txSigner {
+	case SignerKeystore:
+	case SignerLedger:
+		if txAccount != "" {
+			return nil, fmt.Errorf("--%s and --%s %s are mutually exclusive", CfgAccount, CfgSigner, SignerLedger)
+		}
+		return openLedger()
+	default:
+		return nil, fmt.Errorf("unsupported signer: %s", txSigner)
+	}
+	if txAccount == "" {
+		return nil, fmt.Errorf("no account given, use --%s", CfgAccount)
+	}
+	return GetAccount(txAccount)
+}
+
+// GetAccount returns the keystore account with the given name.
+func GetAccount(name string) (*wallet.Account, error) {
+	ks, err := Keystore()
+	if err != nil {
+		return nil, err
+	}
+	return ks.Get(name)
+}
+
+// PrepareTx sets the authentication information and the fee of a transaction signed by the given
+// account. Unless --gas-limit is set, the gas limit is estimated.
+func PrepareTx(ctx context.Context, rc client.RuntimeClient, acct *wallet.Account, tb *client.TransactionBuilder) error {
+	spec, err := acct.SigSpec()
+	if err != nil {
+		return err
+	}
+	nonce, err := accounts.NewV1(rc).Nonce(ctx, client.RoundLatest, acct.Address)
+	if err != nil {
+		return fmt.Errorf("failed to query nonce: %w", err)
+	}
+	tb.AppendAuthSignature(spec, nonce)
+
+	gas := txGasLimit
+	if gas == 0 {
+		if gas, err = core.NewV1(rc).EstimateGas(ctx, client.RoundLatest, tb.GetTransaction()); err != nil {
+			return fmt.Errorf("failed to estimate gas: %w", err)
+		}
+	}
+	fee := quantity.NewFromUint64(txGasPrice)
+	if err = fee.Mul(quantity.NewFromUint64(gas)); err != nil {
+		return fmt.Errorf("failed to compute fee: %w", err)
+	}
+	tb.SetFeeGas(gas)
+	tb.SetFeeAmount(types.NewBaseUnits(*fee, types.NativeDenomination))
+	return nil
+}
+
+// DescribeFee returns a human-readable description of the fee of a transaction.
+func DescribeFee(tx *types.Transaction) string {
+	return fmt.Sprintf("%s (gas limit: %d)", FormatAmount(&tx.AuthInfo.Fee.Amount.Amount, Decimals()), tx.AuthInfo.Fee.Gas)
+}
+
+// SignAndSubmitTx obtains the signer of the given account, signs the transaction and submits it,
+// waiting for its execution. The call result is decoded into rsp unless it is nil. It returns the
+// metadata of the executed transaction.
+func SignAndSubmitTx(ctx context.Context, acct *wallet.Account, tb *client.TransactionBuilder, rsp interface{}) (*client.TransactionMeta, error) {
+	signer, err := AccountSigner(acct)
+	if err != nil {
+		return nil, err
+	}
+	return SignAndSubmitTxWith(ctx, signer, tb, rsp)
+}
+
+// SignAndSubmitTxWith is like SignAndSubmitTx, but signs the transaction with the given, already
+// obtained signer.
+func SignAndSubmitTxWith(ctx context.Context, signer signature.Signer, tb *client.TransactionBuilder, rsp interface{}) (*client.TransactionMeta, error) {
+	if err := tb.AppendSign(ctx, signer); err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	meta, err := tb.SubmitTxMeta(ctx, rsp)
+	if err != nil {
+		return nil, fmt.Errorf("transaction failed: %w", err)
+	}
+	if meta.CheckTxError != nil {
+		return nil, fmt.Errorf("transaction check failed (module: %s code: %d): %s",
+			meta.CheckTxError.Module, meta.CheckTxError.Code, meta.CheckTxError.Message)
+	}
+	return meta, nil
+}
+
+// ResolveAddress resolves an address given either as the name of a keystore account or as a
+// Bech32-encoded address.
+func ResolveAddress(s string) (types.Address, error) {
+	if wallet.ValidateAccountName(s) == nil {
+		if acct, err := GetAccount(s); err == nil {
+			return acct.Address, nil
+		}
+	}
+	var addr types.Address
+	if err := addr.UnmarshalText([]byte(s)); err != nil {
+		return addr, fmt.Errorf("malformed address %s: %w", s, err)
+	}
+	return addr, nil
+}
+
+func init() {
+	TxFlags.StringVar(&txAccount, CfgAccount, "", "keystore account signing the transaction")
+	TxFlags.Uint64Var(&txGasLimit, CfgGasLimit, 0, "gas limit of the transaction (estimated if zero)")
+	TxFlags.Uint64Var(&txGasPrice, CfgGasPrice, 0, "gas price in base units of the native denomination")
+	TxFlags.BoolVarP(&assumeYes, CfgYes, "y", false, "do not ask for confirmation")
+	TxFlags.StringVar(&txUnsigned, CfgUnsigned, "", "write the unsigned transaction to this file (- for hex to standard output) instead of signing and submitting it")
+}
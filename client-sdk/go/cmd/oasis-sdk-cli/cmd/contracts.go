@@ -0,0 +1,368 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/contracts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const (
+	cfgInstantiatePolicy = "instantiate-policy"
+	cfgUpgradesPolicy    = "upgrades-policy"
+	cfgToken             = "token"
+
+	policyEveryone = "everyone"
+	policyNobody   = "nobody"
+)
+
+var (
+	contractsInstantiatePolicy string
+	contractsUpgradesPolicy    string
+	contractsTokens            []string
+
+	contractsCmd = &cobra.Command{
+		Use:   "contracts",
+		Short: "Upload, instantiate and call WASM contracts",
+		Long: `Upload, instantiate and call WASM contracts of the contracts module, e.g. in Cipher.
+
+Requests to contracts are given as JSON, e.g. '{"transfer": {"to": "...", "amount": 10}}', and
+are sent CBOR-encoded as defined by the Oasis ABI. Integers are encoded as CBOR integers, so
+that they can be decoded into the integer types of contracts. Results are decoded from CBOR and
+shown as JSON, with byte strings in base64.`,
+	}
+
+	contractsUploadCmd = &cobra.Command{
+		Use:   "upload <wasm file>",
+		Short: "Upload the code of a contract",
+		Long: `Upload the WASM code of a contract, which is compressed before it is submitted, and show the
+identifier assigned to the code. Who is allowed to instantiate the code is given by
+--instantiate-policy.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			code, err := os.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to read contract code: %w", err)
+			}
+			policy, err := parsePolicy(contractsInstantiatePolicy)
+			if err != nil {
+				return err
+			}
+
+			var result contracts.UploadResult
+			res, err := runTx(cmd, func(rc client.RuntimeClient) (*client.TransactionBuilder, string, error) {
+				summary := fmt.Sprintf("Upload contract code (%d bytes), instantiable by %s", len(code), contractsInstantiatePolicy)
+				return contracts.NewV1(rc).Upload(contracts.ABIOasisV1, *policy, code), summary, nil
+			}, false, &result)
+			switch {
+			case err != nil:
+				return err
+			case res == nil:
+			case common.OutputJSON():
+				return common.PrintJSON(cmd.OutOrStdout(), &struct {
+					*txResult
+					CodeID contracts.CodeID `json:"code_id"`
+				}{res, result.ID})
+			default:
+				fmt.Fprintf(cmd.OutOrStdout(), "Code ID: %d\n", result.ID)
+			}
+			return nil
+		},
+	}
+
+	contractsInstantiateCmd = &cobra.Command{
+		Use:   "instantiate <code id> <request>",
+		Short: "Instantiate uploaded contract code",
+		Long: `Instantiate uploaded contract code with the given instantiation request and show the identifier
+and address of the new instance. Who is allowed to upgrade the instance is given by
+--upgrades-policy and tokens sent to the instance by --token.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			codeID, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("malformed code identifier %s: %w", args[0], err)
+			}
+			data, err := encodeContractRequest(args[1])
+			if err != nil {
+				return err
+			}
+			policy, err := parsePolicy(contractsUpgradesPolicy)
+			if err != nil {
+				return err
+			}
+			tokens, err := parseTokens(contractsTokens)
+			if err != nil {
+				return err
+			}
+
+			var result contracts.InstantiateResult
+			res, err := runTx(cmd, func(rc client.RuntimeClient) (*client.TransactionBuilder, string, error) {
+				summary := fmt.Sprintf("Instantiate contract code %d, upgradable by %s", codeID, contractsUpgradesPolicy)
+				return contracts.NewV1(rc).InstantiateRaw(contracts.CodeID(codeID), *policy, data, tokens), summary, nil
+			}, false, &result)
+			switch {
+			case err != nil:
+				return err
+			case res == nil:
+			case common.OutputJSON():
+				return common.PrintJSON(cmd.OutOrStdout(), &struct {
+					*txResult
+					InstanceID contracts.InstanceID `json:"instance_id"`
+					Address    types.Address        `json:"address"`
+				}{res, result.ID, result.ID.Address()})
+			default:
+				fmt.Fprintf(cmd.OutOrStdout(), "Instance ID: %d\n", result.ID)
+				fmt.Fprintf(cmd.OutOrStdout(), "Address:     %s\n", result.ID.Address())
+			}
+			return nil
+		},
+	}
+
+	contractsCallCmd = &cobra.Command{
+		Use:   "call <instance id> <request>",
+		Short: "Call a contract instance in a transaction",
+		Long:  `Call a contract instance in a transaction, sending it the tokens given by --token, and show the result of the call.`,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseInstanceID(args[0])
+			if err != nil {
+				return err
+			}
+			data, err := encodeContractRequest(args[1])
+			if err != nil {
+				return err
+			}
+			tokens, err := parseTokens(contractsTokens)
+			if err != nil {
+				return err
+			}
+
+			var result contracts.CallResult
+			res, err := runTx(cmd, func(rc client.RuntimeClient) (*client.TransactionBuilder, string, error) {
+				summary := fmt.Sprintf("Call contract instance %d: %s", id, args[1])
+				return contracts.NewV1(rc).CallRaw(id, data, tokens), summary, nil
+			}, false, &result)
+			if err != nil || res == nil {
+				return err
+			}
+			decoded, err := decodeContractResult(result)
+			if err != nil {
+				return err
+			}
+			if common.OutputJSON() {
+				return common.PrintJSON(cmd.OutOrStdout(), &struct {
+					*txResult
+					Result interface{} `json:"result"`
+				}{res, decoded})
+			}
+			return common.PrintJSON(cmd.OutOrStdout(), decoded)
+		},
+	}
+
+	contractsQueryCmd = &cobra.Command{
+		Use:   "query <instance id> <request>",
+		Short: "Query a contract instance",
+		Long:  `Send a custom query to a contract instance, without submitting a transaction, and show its result.`,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseInstanceID(args[0])
+			if err != nil {
+				return err
+			}
+			data, err := encodeContractRequest(args[1])
+			if err != nil {
+				return err
+			}
+
+			rc, conn, err := common.RuntimeClient()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			result, err := contracts.NewV1(rc).CustomRaw(cmd.Context(), client.RoundLatest, id, data)
+			if err != nil {
+				return fmt.Errorf("failed to query contract: %w", err)
+			}
+			decoded, err := decodeContractResult(result)
+			if err != nil {
+				return err
+			}
+			return common.PrintJSON(cmd.OutOrStdout(), decoded)
+		},
+	}
+)
+
+func parseInstanceID(s string) (contracts.InstanceID, error) {
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("malformed instance identifier %s: %w", s, err)
+	}
+	return contracts.InstanceID(id), nil
+}
+
+// parsePolicy parses a policy given as everyone, nobody or the address or name of the only
+// account allowed.
+func parsePolicy(s string) (*contracts.Policy, error) {
+	switch s {
+	case policyEveryone:
+		return &contracts.Policy{Everyone: &struct{}{}}, nil
+	case policyNobody:
+		return &contracts.Policy{Nobody: &struct{}{}}, nil
+	default:
+		addr, err := common.ResolveAddress(s)
+		if err != nil {
+			return nil, fmt.Errorf("malformed policy %s: %w", s, err)
+		}
+		return &contracts.Policy{Address: &addr}, nil
+	}
+}
+
+// parseTokens parses tokens given as <amount>[:<denomination>], the native denomination being
+// used if none is given.
+func parseTokens(values []string) ([]types.BaseUnits, error) {
+	tokens := []types.BaseUnits{}
+	for _, v := range values {
+		rawAmount, denomination := v, types.NativeDenomination
+		if i := strings.IndexByte(v, ':'); i >= 0 {
+			rawAmount, denomination = v[:i], types.Denomination(v[i+1:])
+		}
+		amount, err := common.ParseAmount(rawAmount, common.Decimals())
+		if err != nil {
+			return nil, fmt.Errorf("malformed tokens %s: %w", v, err)
+		}
+		tokens = append(tokens, types.NewBaseUnits(*amount, denomination))
+	}
+	return tokens, nil
+}
+
+// encodeContractRequest encodes a request given as JSON into CBOR.
+func encodeContractRequest(s string) ([]byte, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("malformed request: %w", err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("malformed request: trailing data")
+	}
+	v, err := convertJSONNumbers(v)
+	if err != nil {
+		return nil, fmt.Errorf("malformed request: %w", err)
+	}
+	return cbor.Marshal(v), nil
+}
+
+// convertJSONNumbers replaces the numbers of a decoded JSON value by integers where possible and
+// by floats otherwise.
+func convertJSONNumbers(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case json.Number:
+		s := v.String()
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n, nil
+		}
+		if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("unsupported number %s", s)
+		}
+		return f, nil
+	case map[string]interface{}:
+		for k, elem := range v {
+			converted, err := convertJSONNumbers(elem)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = converted
+		}
+		return v, nil
+	case []interface{}:
+		for i, elem := range v {
+			converted, err := convertJSONNumbers(elem)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
+
+// decodeContractResult decodes a CBOR-encoded result of a contract into a value that can be
+// encoded as JSON.
+func decodeContractResult(data []byte) (interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var v interface{}
+	if err := cbor.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("failed to decode result 0x%x: %w", data, err)
+	}
+	return convertCBORMaps(v), nil
+}
+
+// convertCBORMaps replaces the maps of a decoded CBOR value, which may have keys of any type, by
+// maps with string keys.
+func convertCBORMaps(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, elem := range v {
+			var key string
+			switch k := k.(type) {
+			case string:
+				key = k
+			case []byte:
+				key = fmt.Sprintf("0x%x", k)
+			default:
+				key = fmt.Sprintf("%v", k)
+			}
+			m[key] = convertCBORMaps(elem)
+		}
+		return m
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = convertCBORMaps(elem)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func init() {
+	contractsCmd.PersistentFlags().AddFlagSet(common.ConnectionFlags)
+	contractsCmd.PersistentFlags().AddFlagSet(common.TxFlags)
+	contractsCmd.PersistentFlags().AddFlagSet(common.AmountFlags)
+
+	contractsUploadCmd.Flags().StringVar(&contractsInstantiatePolicy, cfgInstantiatePolicy, policyEveryone,
+		"who may instantiate the code: everyone, nobody or an address")
+	contractsInstantiateCmd.Flags().StringVar(&contractsUpgradesPolicy, cfgUpgradesPolicy, policyNobody,
+		"who may upgrade the instance: everyone, nobody or an address")
+	for _, cmd := range []*cobra.Command{contractsInstantiateCmd, contractsCallCmd} {
+		cmd.Flags().StringArrayVar(&contractsTokens, cfgToken, nil,
+			"tokens sent to the contract as <amount>[:<denomination>], may be repeated")
+	}
+
+	contractsCmd.AddCommand(contractsUploadCmd)
+	contractsCmd.AddCommand(contractsInstantiateCmd)
+	contractsCmd.AddCommand(contractsCallCmd)
+	contractsCmd.AddCommand(contractsQueryCmd)
+}
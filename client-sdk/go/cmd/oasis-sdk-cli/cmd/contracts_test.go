@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+)
+
+func TestContractRequest(t *testing.T) {
+	require := require.New(t)
+
+	data, err := encodeContractRequest(`{"transfer": {"to": "alice", "amount": 10, "fraction": 0.5, "ids": [1, 18446744073709551615]}}`)
+	require.NoError(err, "encodeContractRequest")
+
+	type transfer struct {
+		To       string   `json:"to"`
+		Amount   uint64   `json:"amount"`
+		Fraction float64  `json:"fraction"`
+		IDs      []uint64 `json:"ids"`
+	}
+	var req struct {
+		Transfer transfer `json:"transfer"`
+	}
+	require.NoError(cbor.Unmarshal(data, &req), "integers should be decoded as CBOR integers")
+	require.Equal(transfer{To: "alice", Amount: 10, Fraction: 0.5, IDs: []uint64{1, 18446744073709551615}}, req.Transfer)
+
+	decoded, err := decodeContractResult(data)
+	require.NoError(err, "decodeContractResult")
+	encoded, err := json.Marshal(decoded)
+	require.NoError(err, "decoded result should be encodable as JSON")
+	require.JSONEq(`{"transfer": {"to": "alice", "amount": 10, "fraction": 0.5, "ids": [1, 18446744073709551615]}}`, string(encoded))
+
+	for _, s := range []string{"", "{", `{"a": 1} {}`, "1e400"} {
+		_, err = encodeContractRequest(s)
+		require.Error(err, "encodeContractRequest(%q) should fail", s)
+	}
+}
@@ -0,0 +1,357 @@
+package cmd
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+
+	coreCommon "github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client/consensus"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const (
+	cfgModule    = "module"
+	cfgAddress   = "address"
+	cfgFilter    = "filter"
+	cfgConsensus = "consensus"
+
+	// consensusStakingModule is the module name of consensus staking events.
+	consensusStakingModule = "staking"
+)
+
+var (
+	eventsModules   []string
+	eventsAddresses []string
+	eventsFilters   []string
+	eventsConsensus bool
+
+	eventsCmd = &cobra.Command{
+		Use:   "events",
+		Short: "Inspect runtime and consensus events",
+	}
+
+	eventsWatchCmd = &cobra.Command{
+		Use:   "watch",
+		Short: "Stream events as JSON lines",
+		Long: `Stream the events emitted in every ParaTime given by --runtime-id, which may be repeated,
+and with --consensus the consensus staking events, as one JSON object per line.
+
+Each line contains the ParaTime identifier and round (or the consensus height and transaction
+hash), the module and kind of the event, e.g. accounts and transfer, and the decoded event.
+Events that cannot be decoded are shown by their code and hex-encoded value.
+
+Events can be restricted to the given modules (--module), kinds (--filter) and to events
+involving the given accounts (--address), each of which may be repeated. Accounts are given
+either as Bech32-encoded addresses or as the names of keystore accounts.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := newEventFilter()
+			if err != nil {
+				return err
+			}
+			runtimeIDs, err := common.RuntimeIDs()
+			if err != nil {
+				return err
+			}
+			if len(runtimeIDs) == 0 && !eventsConsensus {
+				return fmt.Errorf("nothing to watch, use --%s or --%s", common.CfgRuntimeID, cfgConsensus)
+			}
+			conn, err := common.Connect()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			ctx, cancel := context.WithCancel(cmd.Context())
+			defer cancel()
+
+			lines := make(chan *eventLine)
+			errCh := make(chan error, len(runtimeIDs)+1)
+			for _, id := range runtimeIDs {
+				go func(id coreCommon.Namespace) {
+					errCh <- watchRuntimeEvents(ctx, client.New(conn, id), id, filter, lines)
+				}(id)
+			}
+			if eventsConsensus {
+				go func() {
+					errCh <- watchConsensusEvents(ctx, conn, filter, lines)
+				}()
+			}
+
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			for {
+				select {
+				case line := <-lines:
+					if err = enc.Encode(line); err != nil {
+						return err
+					}
+				case err = <-errCh:
+					return err
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		},
+	}
+)
+
+// eventLine is a single event as streamed by events watch.
+type eventLine struct {
+	RuntimeID *coreCommon.Namespace `json:"runtime_id,omitempty"`
+	Round     *uint64               `json:"round,omitempty"`
+	Height    int64                 `json:"height,omitempty"`
+	TxHash    *hash.Hash            `json:"tx_hash,omitempty"`
+	Module    string                `json:"module"`
+	Kind      string                `json:"kind"`
+	Event     json.RawMessage       `json:"event"`
+}
+
+// undecodedEvent is a runtime event that no registered module could decode.
+type undecodedEvent struct {
+	Code  uint32 `json:"code"`
+	Value string `json:"value"`
+}
+
+// eventFilter selects the streamed events.
+type eventFilter struct {
+	modules   map[string]bool
+	kinds     map[string]bool
+	addresses map[string]bool
+}
+
+func newEventFilter() (*eventFilter, error) {
+	f := &eventFilter{
+		modules:   stringSet(eventsModules),
+		kinds:     stringSet(eventsFilters),
+		addresses: make(map[string]bool),
+	}
+	for _, s := range eventsAddresses {
+		addr, err := common.ResolveAddress(s)
+		if err != nil {
+			return nil, err
+		}
+		f.addresses[addr.String()] = true
+	}
+	return f, nil
+}
+
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// wantsModule returns true iff events of the given module may be streamed.
+func (f *eventFilter) wantsModule(module string) bool {
+	return len(f.modules) == 0 || f.modules[module]
+}
+
+// matches returns true iff the given event should be streamed.
+func (f *eventFilter) matches(line *eventLine) bool {
+	if !f.wantsModule(line.Module) {
+		return false
+	}
+	if len(f.kinds) > 0 && !f.kinds[line.Kind] && !f.kinds[line.Module+"."+line.Kind] {
+		return false
+	}
+	if len(f.addresses) == 0 {
+		return true
+	}
+	var v interface{}
+	if err := json.Unmarshal(line.Event, &v); err != nil {
+		return false
+	}
+	return f.involves(v)
+}
+
+// involves returns true iff any string within the given JSON value is one of the addresses.
+func (f *eventFilter) involves(v interface{}) bool {
+	switch v := v.(type) {
+	case string:
+		return f.addresses[v]
+	case map[string]interface{}:
+		for _, elem := range v {
+			if f.involves(elem) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, elem := range v {
+			if f.involves(elem) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitEventKind splits a JSON-encoded decoded event, which has a single field named after the
+// kind of the event, e.g. {"transfer": {...}}, into its kind and body. Fields named in ignore
+// are skipped.
+func splitEventKind(ev interface{}, ignore ...string) (string, json.RawMessage, error) {
+	raw, err := json.Marshal(ev)
+	if err != nil {
+		return "", nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(raw, &fields); err != nil {
+		return "", raw, nil
+	}
+	for _, name := range ignore {
+		delete(fields, name)
+	}
+	if len(fields) != 1 {
+		// Not a single-kind event, stream it as is.
+		return "", raw, nil
+	}
+	for kind, body := range fields {
+		return kind, body, nil
+	}
+	return "", raw, nil
+}
+
+func watchRuntimeEvents(ctx context.Context, rc client.RuntimeClient, id coreCommon.Namespace, filter *eventFilter, lines chan<- *eventLine) error {
+	blkCh, blkSub, err := rc.WatchBlocks(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to watch blocks of ParaTime %s: %w", id, err)
+	}
+	defer blkSub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case blk, ok := <-blkCh:
+			if !ok {
+				return fmt.Errorf("block subscription of ParaTime %s closed", id)
+			}
+			round := blk.Block.Header.Round
+			evs, evErr := rc.GetEventsRaw(ctx, round)
+			if evErr != nil {
+				return fmt.Errorf("failed to get events of ParaTime %s round %d: %w", id, round, evErr)
+			}
+			for _, ev := range evs {
+				if !filter.wantsModule(ev.Module) {
+					continue
+				}
+				line, lineErr := runtimeEventLine(ev)
+				if lineErr != nil {
+					return fmt.Errorf("failed to decode event of ParaTime %s round %d: %w", id, round, lineErr)
+				}
+				line.RuntimeID = &id
+				line.Round = &round
+				if !filter.matches(line) {
+					continue
+				}
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+	}
+}
+
+func runtimeEventLine(ev *types.Event) (*eventLine, error) {
+	line := &eventLine{Module: ev.Module}
+
+	var decoded client.DecodedEvent
+	if m, ok := registry.LookupModule(ev.Module); ok && m.EventDecoder != nil {
+		var err error
+		if decoded, err = m.EventDecoder.DecodeEvent(ev); err != nil {
+			return nil, err
+		}
+	}
+	if decoded == nil {
+		line.Kind = fmt.Sprintf("%d", ev.Code)
+		raw, err := json.Marshal(&undecodedEvent{Code: ev.Code, Value: hex.EncodeToString(ev.Value)})
+		if err != nil {
+			return nil, err
+		}
+		line.Event = raw
+		return line, nil
+	}
+
+	var err error
+	if line.Kind, line.Event, err = splitEventKind(decoded); err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+func watchConsensusEvents(ctx context.Context, conn *grpc.ClientConn, filter *eventFilter, lines chan<- *eventLine) error {
+	if !filter.wantsModule(consensusStakingModule) {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	ch, sub, err := consensus.New(conn).WatchStakingEvents(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to watch consensus staking events: %w", err)
+	}
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev, ok := <-ch:
+			if !ok {
+				return fmt.Errorf("consensus staking event subscription closed")
+			}
+			line, lineErr := consensusEventLine(ev)
+			if lineErr != nil {
+				return lineErr
+			}
+			if !filter.matches(line) {
+				continue
+			}
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+func consensusEventLine(ev *staking.Event) (*eventLine, error) {
+	line := &eventLine{
+		Height: ev.Height,
+		Module: consensusStakingModule,
+	}
+	if !ev.TxHash.IsEmpty() {
+		txHash := ev.TxHash
+		line.TxHash = &txHash
+	}
+
+	var err error
+	if line.Kind, line.Event, err = splitEventKind(ev, "height", "tx_hash"); err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+func init() {
+	eventsWatchCmd.Flags().AddFlagSet(common.ConnectionFlags)
+	eventsWatchCmd.Flags().StringSliceVar(&eventsModules, cfgModule, nil, "only stream events of this module, e.g. accounts (may be repeated)")
+	eventsWatchCmd.Flags().StringSliceVar(&eventsAddresses, cfgAddress, nil, "only stream events involving this account (may be repeated)")
+	eventsWatchCmd.Flags().StringSliceVar(&eventsFilters, cfgFilter, nil, "only stream events of this kind, e.g. transfer or accounts.transfer (may be repeated)")
+	eventsWatchCmd.Flags().BoolVar(&eventsConsensus, cfgConsensus, false, "also stream consensus staking events")
+
+	eventsCmd.AddCommand(eventsWatchCmd)
+}
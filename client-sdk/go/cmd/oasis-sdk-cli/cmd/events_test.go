@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func TestEventFilter(t *testing.T) {
+	require := require.New(t)
+
+	transfer := &types.Event{
+		Module: accounts.ModuleName,
+		Code:   accounts.TransferEventCode,
+		Value: cbor.Marshal(&accounts.TransferEvent{
+			From:   sdkTesting.Alice.Address,
+			To:     sdkTesting.Bob.Address,
+			Amount: types.NewBaseUnits(*quantity.NewFromUint64(1000), types.NativeDenomination),
+		}),
+	}
+	line, err := runtimeEventLine(transfer)
+	require.NoError(err, "runtimeEventLine")
+	require.Equal("accounts", line.Module)
+	require.Equal("transfer", line.Kind)
+	require.Contains(string(line.Event), sdkTesting.Bob.Address.String())
+
+	undecoded, err := runtimeEventLine(&types.Event{Module: "unknown", Code: 7, Value: []byte{0xca, 0xfe}})
+	require.NoError(err, "runtimeEventLine")
+	require.Equal("7", undecoded.Kind)
+	require.JSONEq(`{"code": 7, "value": "cafe"}`, string(undecoded.Event))
+
+	for _, tc := range []struct {
+		modules   []string
+		kinds     []string
+		addresses []string
+		matches   bool
+	}{
+		{nil, nil, nil, true},
+		{[]string{"accounts"}, nil, nil, true},
+		{[]string{"evm"}, nil, nil, false},
+		{nil, []string{"transfer"}, nil, true},
+		{nil, []string{"accounts.transfer"}, nil, true},
+		{nil, []string{"mint"}, nil, false},
+		{nil, nil, []string{sdkTesting.Bob.Address.String()}, true},
+		{nil, nil, []string{sdkTesting.Charlie.Address.String()}, false},
+	} {
+		eventsModules, eventsFilters, eventsAddresses = tc.modules, tc.kinds, tc.addresses
+		filter, fErr := newEventFilter()
+		require.NoError(fErr, "newEventFilter")
+		require.Equal(tc.matches, filter.matches(line), "modules: %v kinds: %v addresses: %v", tc.modules, tc.kinds, tc.addresses)
+	}
+}
@@ -0,0 +1,367 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/evmabi"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/wallet"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/evm"
+)
+
+const (
+	cfgABI    = "abi"
+	cfgValue  = "value"
+	cfgData   = "data"
+	cfgCaller = "caller"
+
+	// defaultSimulateGasLimit is the gas limit of simulated calls unless --gas-limit is set.
+	defaultSimulateGasLimit = 10_000_000
+)
+
+var (
+	evmABIFile string
+	evmValue   string
+	evmData    string
+	evmCaller  string
+
+	evmCmd = &cobra.Command{
+		Use:   "evm",
+		Short: "Deploy and call EVM contracts",
+		Long: `Deploy and call EVM contracts.
+
+Methods are given either by name, with their ABI given by --abi as a JSON file produced by the
+Solidity compiler, or by their signature, e.g. 'balanceOf(address) returns (uint256)'. Arguments
+are encoded according to the types of the method: integers in decimal or 0x-prefixed hex
+notation, addresses and byte arrays in 0x-prefixed hex notation and arrays as JSON arrays.`,
+	}
+
+	evmDeployCmd = &cobra.Command{
+		Use:   "deploy <bytecode> [<constructor args>...]",
+		Short: "Deploy an EVM contract",
+		Long: `Deploy an EVM contract given by its hex-encoded bytecode or a file containing it. Constructor
+arguments are encoded according to the constructor in the ABI given by --abi.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			initCode, err := readHexData(args[0])
+			if err != nil {
+				return fmt.Errorf("malformed bytecode: %w", err)
+			}
+			if len(args) > 1 || evmABIFile != "" {
+				var abi *evmabi.ABI
+				if abi, err = loadABI(); err != nil {
+					return err
+				}
+				constructor := abi.Constructor
+				if constructor == nil {
+					constructor = &evmabi.Method{}
+				}
+				var encodedArgs []byte
+				if encodedArgs, err = constructor.EncodeArgs(args[1:]); err != nil {
+					return err
+				}
+				initCode = append(initCode, encodedArgs...)
+			}
+			value, err := evmValueWord()
+			if err != nil {
+				return err
+			}
+
+			var contractAddr []byte
+			res, err := runTx(cmd, func(rc client.RuntimeClient) (*client.TransactionBuilder, string, error) {
+				summary := fmt.Sprintf("Deploy EVM contract (%d bytes) with value %s", len(initCode), evmValue)
+				return evm.NewV1(rc).Create(value, initCode), summary, nil
+			}, false, &contractAddr)
+			if err != nil {
+				return evmError(err)
+			}
+			switch {
+			case res == nil:
+			case common.OutputJSON():
+				return common.PrintJSON(cmd.OutOrStdout(), &struct {
+					*txResult
+					ContractAddress string `json:"contract_address"`
+				}{res, fmt.Sprintf("0x%x", contractAddr)})
+			default:
+				fmt.Fprintf(cmd.OutOrStdout(), "Contract address: 0x%x\n", contractAddr)
+			}
+			return nil
+		},
+	}
+
+	evmCallCmd = &cobra.Command{
+		Use:   "call <address> [<method> [<args>...]]",
+		Short: "Call an EVM contract in a transaction",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			addr, method, data, err := evmCallArgs(args)
+			if err != nil {
+				return err
+			}
+			value, err := evmValueWord()
+			if err != nil {
+				return err
+			}
+
+			var output []byte
+			res, err := runTx(cmd, func(rc client.RuntimeClient) (*client.TransactionBuilder, string, error) {
+				summary := fmt.Sprintf("Call EVM contract 0x%x with value %s", addr, evmValue)
+				if method != nil {
+					summary = fmt.Sprintf("Call %s on EVM contract 0x%x with value %s", method.Signature(), addr, evmValue)
+				}
+				return evm.NewV1(rc).Call(addr, value, data), summary, nil
+			}, false, &output)
+			if err != nil {
+				return evmError(err)
+			}
+			if res == nil {
+				return nil
+			}
+			decoded, err := decodeEVMOutput(method, output)
+			if err != nil {
+				return err
+			}
+			if common.OutputJSON() {
+				return common.PrintJSON(cmd.OutOrStdout(), &struct {
+					*txResult
+					*evmOutput
+				}{res, decoded})
+			}
+			return printEVMOutput(cmd.OutOrStdout(), decoded)
+		},
+	}
+
+	evmSimulateCmd = &cobra.Command{
+		Use:   "simulate <address> [<method> [<args>...]]",
+		Short: "Simulate a call of an EVM contract without submitting a transaction",
+		Long: `Simulate a call of an EVM contract without submitting a transaction, e.g. to call view
+methods. The caller is given by --caller or, for Secp256k1 accounts, by --account and is the
+zero address otherwise.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			addr, method, data, err := evmCallArgs(args)
+			if err != nil {
+				return err
+			}
+			value, err := evmValueWord()
+			if err != nil {
+				return err
+			}
+			caller, err := evmSimulateCaller()
+			if err != nil {
+				return err
+			}
+			gasLimit := common.GasLimit()
+			if gasLimit == 0 {
+				gasLimit = defaultSimulateGasLimit
+			}
+
+			rc, conn, err := common.RuntimeClient()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			gasPrice := uint256Word(new(big.Int).SetUint64(common.GasPrice()))
+			output, err := evm.NewV1(rc).SimulateCall(cmd.Context(), gasPrice, gasLimit, caller, addr, value, data)
+			if err != nil {
+				return evmError(err)
+			}
+			decoded, err := decodeEVMOutput(method, output)
+			if err != nil {
+				return err
+			}
+			if common.OutputJSON() {
+				return common.PrintJSON(cmd.OutOrStdout(), decoded)
+			}
+			return printEVMOutput(cmd.OutOrStdout(), decoded)
+		},
+	}
+)
+
+// loadABI loads the contract ABI given by --abi.
+func loadABI() (*evmabi.ABI, error) {
+	if evmABIFile == "" {
+		return nil, fmt.Errorf("no ABI given, use --%s", cfgABI)
+	}
+	data, err := os.ReadFile(evmABIFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ABI: %w", err)
+	}
+	return evmabi.ParseABI(data)
+}
+
+// evmCallArgs parses the contract address, method and arguments of a call into the call data.
+// The call data may instead be given directly by --data, in which case the method is nil.
+func evmCallArgs(args []string) ([]byte, *evmabi.Method, []byte, error) {
+	addr, err := parseEthAddress(args[0])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if evmData != "" {
+		if len(args) > 1 {
+			return nil, nil, nil, fmt.Errorf("--%s and a method are mutually exclusive", cfgData)
+		}
+		var data []byte
+		if data, err = readHexData(evmData); err != nil {
+			return nil, nil, nil, fmt.Errorf("malformed call data: %w", err)
+		}
+		return addr, nil, data, nil
+	}
+	if len(args) < 2 {
+		return nil, nil, nil, fmt.Errorf("no method given")
+	}
+
+	var method *evmabi.Method
+	if strings.Contains(args[1], "(") {
+		method, err = evmabi.ParseMethodSignature(args[1])
+	} else {
+		var abi *evmabi.ABI
+		if abi, err = loadABI(); err == nil {
+			method, err = abi.Method(args[1])
+		}
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	data, err := method.EncodeCall(args[2:])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return addr, method, data, nil
+}
+
+// evmValueWord returns the value given by --value as a 256-bit word.
+func evmValueWord() ([]byte, error) {
+	amount, err := common.ParseAmount(evmValue, common.Decimals())
+	if err != nil {
+		return nil, fmt.Errorf("malformed value: %w", err)
+	}
+	if amount.ToBigInt().BitLen() > 256 {
+		return nil, fmt.Errorf("value too large")
+	}
+	return uint256Word(amount.ToBigInt()), nil
+}
+
+// evmSimulateCaller returns the caller of simulated calls.
+func evmSimulateCaller() ([]byte, error) {
+	if evmCaller != "" {
+		return parseEthAddress(evmCaller)
+	}
+	if acct, err := common.TxAccount(); err == nil && acct.Algorithm == wallet.AlgorithmSecp256k1 {
+		return acct.EthereumAddress()
+	}
+	return make([]byte, evmabi.AddressSize), nil
+}
+
+// evmOutput is the output of a call.
+type evmOutput struct {
+	// Output is the hex-encoded raw output.
+	Output string `json:"output"`
+	// Values are the output values, if decoded according to the method.
+	Values []evmOutputValue `json:"values,omitempty"`
+}
+
+// evmOutputValue is a decoded output value of a call.
+type evmOutputValue struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// decodeEVMOutput decodes the output of a call according to the method if known.
+func decodeEVMOutput(method *evmabi.Method, output []byte) (*evmOutput, error) {
+	decoded := &evmOutput{Output: fmt.Sprintf("0x%x", output)}
+	if method == nil || len(method.Outputs) == 0 {
+		return decoded, nil
+	}
+
+	values, err := method.DecodeOutputs(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode output 0x%x: %w", output, err)
+	}
+	for i, output := range method.Outputs {
+		name := output.Name
+		if name == "" {
+			name = fmt.Sprintf("%d", i)
+		}
+		decoded.Values = append(decoded.Values, evmOutputValue{
+			Name:  name,
+			Type:  output.Type.String(),
+			Value: evmabi.FormatValue(output.Type, values[i]),
+		})
+	}
+	return decoded, nil
+}
+
+// printEVMOutput prints the decoded output of a call, or the raw output if not decoded.
+func printEVMOutput(w io.Writer, decoded *evmOutput) error {
+	if decoded.Values == nil {
+		if decoded.Output != "0x" {
+			fmt.Fprintf(w, "Output: %s\n", decoded.Output)
+		}
+		return nil
+	}
+	for _, v := range decoded.Values {
+		fmt.Fprintf(w, "%s (%s): %s\n", v.Name, v.Type, v.Value)
+	}
+	return nil
+}
+
+// evmError describes failed EVM executions. The EVM module reports the exit reason of the
+// execution, e.g. Revert(Reverted), but not the revert data.
+func evmError(err error) error {
+	const prefix = "EVM error: "
+	msg := err.Error()
+	if i := strings.Index(msg, prefix); i >= 0 {
+		return fmt.Errorf("EVM execution failed: %s", msg[i+len(prefix):])
+	}
+	return err
+}
+
+func parseEthAddress(s string) ([]byte, error) {
+	addr, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(addr) != evmabi.AddressSize {
+		return nil, fmt.Errorf("malformed Ethereum address: %s", s)
+	}
+	return addr, nil
+}
+
+// readHexData decodes hex-encoded data given either directly or as the name of a file.
+func readHexData(s string) ([]byte, error) {
+	if raw, err := os.ReadFile(s); err == nil {
+		s = string(raw)
+	}
+	s = strings.TrimPrefix(strings.TrimSpace(s), "0x")
+	return hex.DecodeString(s)
+}
+
+func uint256Word(v *big.Int) []byte {
+	return v.FillBytes(make([]byte, 32))
+}
+
+func init() {
+	evmCmd.PersistentFlags().AddFlagSet(common.ConnectionFlags)
+	evmCmd.PersistentFlags().AddFlagSet(common.TxFlags)
+	evmCmd.PersistentFlags().AddFlagSet(common.AmountFlags)
+	evmCmd.PersistentFlags().StringVar(&evmABIFile, cfgABI, "", "JSON ABI file of the contract")
+	evmCmd.PersistentFlags().StringVar(&evmValue, cfgValue, "0", "amount of tokens sent with the call")
+
+	for _, cmd := range []*cobra.Command{evmCallCmd, evmSimulateCmd} {
+		cmd.Flags().StringVar(&evmData, cfgData, "", "hex-encoded call data, instead of a method and its arguments")
+	}
+	evmSimulateCmd.Flags().StringVar(&evmCaller, cfgCaller, "", "Ethereum address of the caller")
+
+	evmCmd.AddCommand(evmDeployCmd)
+	evmCmd.AddCommand(evmCallCmd)
+	evmCmd.AddCommand(evmSimulateCmd)
+}
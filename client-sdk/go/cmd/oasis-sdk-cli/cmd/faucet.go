@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/faucet"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const (
+	cfgURL     = "url"
+	cfgWait    = "wait"
+	cfgTimeout = "timeout"
+)
+
+var (
+	faucetURL       string
+	faucetConsensus bool
+	faucetWait      bool
+	faucetTimeout   time.Duration
+
+	faucetCmd = &cobra.Command{
+		Use:   "faucet",
+		Short: "Request tokens from a faucet",
+	}
+
+	faucetRequestCmd = &cobra.Command{
+		Use:   "request <address> <amount>",
+		Short: "Request tokens from the faucet of the network",
+		Long: `Request the faucet of the network to fund an account, given either as a Bech32-encoded address or
+as the name of a keystore account, with the given amount of native tokens in the ParaTime or, with
+--consensus, on the consensus layer. The faucet is the one of the network profile, see network
+add --faucet, unless --url is given. Faucets of public networks may restrict the amount given per
+request.
+
+Faucets usually only queue the transfer, so the funds may become available some time later. With
+--wait, the command waits until the balance of the account has grown by the requested amount, so
+that the account can be used right away, e.g. by the next command of a setup script.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if faucetConsensus && faucetWait {
+				return fmt.Errorf("--%s is not supported with --%s", cfgWait, cfgConsensus)
+			}
+			url, err := faucetServiceURL()
+			if err != nil {
+				return err
+			}
+			to, err := common.ResolveAddress(args[0])
+			if err != nil {
+				return err
+			}
+			decimals := common.Decimals()
+			var paraTime string
+			if faucetConsensus {
+				decimals = common.ConsensusDecimals()
+			} else {
+				if paraTime, err = common.ParaTimeName(); err != nil {
+					return err
+				}
+				if paraTime == "" {
+					return fmt.Errorf("no ParaTime profile selected, use --%s or --%s", common.CfgParaTime, cfgConsensus)
+				}
+			}
+			amount, err := common.ParseAmount(args[1], decimals)
+			if err != nil {
+				return err
+			}
+			funds := types.NewBaseUnits(*amount, types.NativeDenomination)
+
+			// Query the balance before the request, so that the funds can be told apart from it.
+			var (
+				rc     client.RuntimeClient
+				target *quantity.Quantity
+			)
+			if faucetWait {
+				var conn *grpc.ClientConn
+				if rc, conn, err = common.RuntimeClient(); err != nil {
+					return err
+				}
+				defer conn.Close()
+
+				balances, qErr := accounts.NewV1(rc).Balances(ctx, client.RoundLatest, to)
+				if qErr != nil {
+					return fmt.Errorf("failed to query balances: %w", qErr)
+				}
+				balance := balances.Balances[types.NativeDenomination]
+				target = balance.Clone()
+				if err = target.Add(amount); err != nil {
+					return fmt.Errorf("failed to compute the expected balance: %w", err)
+				}
+			}
+
+			if err = faucet.NewHTTPFaucet(url, paraTime).Fund(ctx, to, funds); err != nil {
+				return err
+			}
+			layer := "consensus layer"
+			if paraTime != "" {
+				layer = "ParaTime " + paraTime
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Requested %s for %s in %s.\n", common.FormatAmount(amount, decimals), to, layer)
+
+			if faucetWait {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Waiting for the funds...\n")
+				waitCtx, cancel := context.WithTimeout(ctx, faucetTimeout)
+				defer cancel()
+				if err = faucet.WaitForBalance(waitCtx, rc, to, types.NewBaseUnits(*target, types.NativeDenomination)); err != nil {
+					return fmt.Errorf("funds not received: %w", err)
+				}
+				fmt.Fprintf(cmd.ErrOrStderr(), "Funds received.\n")
+			}
+
+			if common.OutputJSON() {
+				return common.PrintJSON(cmd.OutOrStdout(), &faucetRequestJSON{
+					Address:  to,
+					ParaTime: paraTime,
+					Amount:   *amount,
+					Received: faucetWait,
+				})
+			}
+			return nil
+		},
+	}
+)
+
+// faucetRequestJSON is the result of a faucet request.
+type faucetRequestJSON struct {
+	Address  types.Address     `json:"address"`
+	ParaTime string            `json:"paratime,omitempty"`
+	Amount   quantity.Quantity `json:"amount"`
+	// Received is true if the funds were waited for and received.
+	Received bool `json:"received"`
+}
+
+// faucetServiceURL returns the faucet URL given by --url or by the selected network profile.
+func faucetServiceURL() (string, error) {
+	if faucetURL != "" {
+		return faucetURL, nil
+	}
+	n, err := common.Network()
+	if err != nil {
+		return "", err
+	}
+	if n == nil || n.Faucet == "" {
+		return "", fmt.Errorf("the network has no faucet, use --%s", cfgURL)
+	}
+	return n.Faucet, nil
+}
+
+func init() {
+	faucetRequestCmd.Flags().AddFlagSet(common.ConnectionFlags)
+	faucetRequestCmd.Flags().AddFlagSet(common.AmountFlags)
+	faucetRequestCmd.Flags().StringVar(&faucetURL, cfgURL, "", "URL of the faucet service (the faucet of the network if not given)")
+	faucetRequestCmd.Flags().BoolVar(&faucetConsensus, cfgConsensus, false, "fund the account on the consensus layer instead of the ParaTime")
+	faucetRequestCmd.Flags().BoolVar(&faucetWait, cfgWait, false, "wait until the funds are received")
+	faucetRequestCmd.Flags().DurationVar(&faucetTimeout, cfgTimeout, 5*time.Minute, "maximum time to wait for the funds with --wait")
+
+	faucetCmd.AddCommand(faucetRequestCmd)
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/config"
+)
+
+func TestFaucetServiceURL(t *testing.T) {
+	require := require.New(t)
+
+	require.NoError(common.RootFlags.Set(common.CfgConfigDir, t.TempDir()))
+	defer func() { faucetURL = "" }()
+
+	_, err := faucetServiceURL()
+	require.Error(err, "faucetServiceURL should fail without a faucet")
+
+	err = updateConfig(func(cfg *config.Config) error {
+		cfg.Networks[cfg.DefaultNetwork].Faucet = "https://faucet.example.com/fund"
+		return nil
+	})
+	require.NoError(err, "updateConfig")
+	url, err := faucetServiceURL()
+	require.NoError(err, "faucetServiceURL")
+	require.Equal("https://faucet.example.com/fund", url)
+
+	faucetURL = "http://localhost:8080"
+	url, err = faucetServiceURL()
+	require.NoError(err, "faucetServiceURL")
+	require.Equal("http://localhost:8080", url, "--url should override the network faucet")
+}
@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
+	governance "github.com/oasisprotocol/oasis-core/go/governance/api"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client/consensus"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const cfgAll = "all"
+
+var (
+	governanceAll bool
+
+	governanceCmd = &cobra.Command{
+		Use:   "governance",
+		Short: "Participate in consensus layer governance",
+	}
+
+	governanceListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List governance proposals",
+		Long:  `List the consensus layer governance proposals that are open for voting, or all of them with --all.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			conn, err := common.Connect()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			cons := consensus.New(conn)
+			var proposals []*governance.Proposal
+			if governanceAll {
+				proposals, err = cons.Proposals(ctx, consensus.HeightLatest)
+			} else {
+				proposals, err = cons.ActiveProposals(ctx, consensus.HeightLatest)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to query proposals: %w", err)
+			}
+			sort.Slice(proposals, func(i, j int) bool {
+				return proposals[i].ID < proposals[j].ID
+			})
+			if common.OutputJSON() {
+				if proposals == nil {
+					proposals = []*governance.Proposal{}
+				}
+				return common.PrintJSON(cmd.OutOrStdout(), proposals)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "ID\tSTATE\tCLOSES AT\tCONTENT\n")
+			for _, p := range proposals {
+				fmt.Fprintf(w, "%d\t%s\tepoch %d\t%s\n", p.ID, p.State, p.ClosesAt, describeProposalContent(&p.Content))
+			}
+			return w.Flush()
+		},
+	}
+
+	governanceShowCmd = &cobra.Command{
+		Use:   "show <proposal id>",
+		Short: "Show a governance proposal and its votes",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			id, err := parseProposalID(args[0])
+			if err != nil {
+				return err
+			}
+			conn, err := common.Connect()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			cons := consensus.New(conn)
+			p, err := cons.Proposal(ctx, consensus.HeightLatest, id)
+			if err != nil {
+				return fmt.Errorf("failed to query proposal: %w", err)
+			}
+			votes, err := cons.Votes(ctx, consensus.HeightLatest, id)
+			if err != nil {
+				return fmt.Errorf("failed to query votes: %w", err)
+			}
+			if common.OutputJSON() {
+				if votes == nil {
+					votes = []*governance.VoteEntry{}
+				}
+				return common.PrintJSON(cmd.OutOrStdout(), &struct {
+					*governance.Proposal
+					Votes []*governance.VoteEntry `json:"votes"`
+				}{p, votes})
+			}
+
+			decimals := common.ConsensusDecimals()
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "ID:\t%d\n", p.ID)
+			fmt.Fprintf(w, "State:\t%s\n", p.State)
+			fmt.Fprintf(w, "Content:\t%s\n", describeProposalContent(&p.Content))
+			fmt.Fprintf(w, "Submitter:\t%s\n", types.Address(p.Submitter))
+			fmt.Fprintf(w, "Deposit:\t%s\n", common.FormatAmount(&p.Deposit, decimals))
+			fmt.Fprintf(w, "Created at:\tepoch %d\n", p.CreatedAt)
+			fmt.Fprintf(w, "Closes at:\tepoch %d\n", p.ClosesAt)
+			for _, v := range []governance.Vote{governance.VoteYes, governance.VoteNo, governance.VoteAbstain} {
+				if stake, ok := p.Results[v]; ok {
+					fmt.Fprintf(w, "Voted %s:\t%s\n", v, common.FormatAmount(&stake, decimals))
+				}
+			}
+			if p.InvalidVotes > 0 {
+				fmt.Fprintf(w, "Invalid votes:\t%d\n", p.InvalidVotes)
+			}
+
+			fmt.Fprintf(w, "\n")
+			fmt.Fprintf(w, "Votes (%d):\t\n", len(votes))
+			for _, v := range votes {
+				fmt.Fprintf(w, "  %s\t%s\n", types.Address(v.Voter), v.Vote)
+			}
+			return w.Flush()
+		},
+	}
+
+	governanceVoteCmd = &cobra.Command{
+		Use:   "vote <proposal id> <yes|no|abstain>",
+		Short: "Vote on a governance proposal",
+		Long: `Cast a vote on an active consensus layer governance proposal with the consensus layer account of
+the signer, which has to be an Ed25519 account. The consensus layer only accepts votes from
+accounts eligible to vote, i.e. ones of entities with validators. Stake held in ParaTimes cannot
+be used to vote, as ParaTimes cannot cast votes on behalf of their accounts.
+
+The fee is paid in consensus layer tokens, the gas price given by --gas-price being in consensus
+layer base units.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			id, err := parseProposalID(args[0])
+			if err != nil {
+				return err
+			}
+			var vote governance.Vote
+			if err = vote.UnmarshalText([]byte(args[1])); err != nil {
+				return err
+			}
+			if common.UnsignedTxPath() != "" {
+				return fmt.Errorf("--%s is not supported for consensus layer transactions", common.CfgUnsigned)
+			}
+			acct, err := common.TxAccount()
+			if err != nil {
+				return err
+			}
+			pk, err := acct.PublicKeyValue()
+			if err != nil {
+				return err
+			}
+
+			conn, err := common.Connect()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			cons := consensus.New(conn)
+			p, err := cons.Proposal(ctx, consensus.HeightLatest, id)
+			if err != nil {
+				return fmt.Errorf("failed to query proposal: %w", err)
+			}
+			if p.State != governance.StateActive {
+				return fmt.Errorf("proposal %d is %s", id, p.State)
+			}
+			nonce, err := cons.Nonce(ctx, consensus.HeightLatest, acct.Address)
+			if err != nil {
+				return fmt.Errorf("failed to query nonce: %w", err)
+			}
+
+			tx := governance.NewCastVoteTx(nonce, &transaction.Fee{}, &governance.ProposalVote{ID: id, Vote: vote})
+			gas := transaction.Gas(common.GasLimit())
+			if gas == 0 {
+				if gas, err = cons.EstimateGas(ctx, pk, tx); err != nil {
+					return fmt.Errorf("failed to estimate gas: %w", err)
+				}
+			}
+			fee := quantity.NewFromUint64(common.GasPrice())
+			if err = fee.Mul(quantity.NewFromUint64(uint64(gas))); err != nil {
+				return fmt.Errorf("failed to compute fee: %w", err)
+			}
+			tx.Fee = &transaction.Fee{Amount: *fee, Gas: gas}
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "Vote %s on proposal %d: %s\n", vote, id, describeProposalContent(&p.Content))
+			fmt.Fprintf(cmd.ErrOrStderr(), "Signer: %s (%s)\n", acct.Name, acct.Address)
+			fmt.Fprintf(cmd.ErrOrStderr(), "Fee:    %s (gas limit: %d)\n", common.FormatAmount(fee, common.ConsensusDecimals()), gas)
+			if err = common.Confirm("Sign and submit the vote?"); err != nil {
+				return err
+			}
+
+			chainContext, err := cons.ChainContext(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to query chain context: %w", err)
+			}
+			signer, err := common.AccountSigner(acct)
+			if err != nil {
+				return err
+			}
+			signed, err := consensus.SignTransaction(chainContext, signer, tx)
+			if err != nil {
+				return err
+			}
+			if err = cons.SubmitTx(ctx, signed); err != nil {
+				return fmt.Errorf("failed to submit vote: %w", err)
+			}
+			if common.OutputJSON() {
+				return common.PrintJSON(cmd.OutOrStdout(), &voteJSON{Hash: signed.Hash(), ProposalID: id, Vote: vote})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Voted %s on proposal %d.\n", vote, id)
+			return nil
+		},
+	}
+)
+
+// voteJSON is the JSON form of a cast vote.
+type voteJSON struct {
+	Hash       hash.Hash       `json:"hash"`
+	ProposalID uint64          `json:"proposal_id"`
+	Vote       governance.Vote `json:"vote"`
+}
+
+func parseProposalID(s string) (uint64, error) {
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("malformed proposal identifier %s: %w", s, err)
+	}
+	return id, nil
+}
+
+// describeProposalContent returns a one-line description of the content of a proposal.
+func describeProposalContent(c *governance.ProposalContent) string {
+	switch {
+	case c.Upgrade != nil:
+		return fmt.Sprintf("upgrade %s at epoch %d", c.Upgrade.Handler, c.Upgrade.Epoch)
+	case c.CancelUpgrade != nil:
+		return fmt.Sprintf("cancel upgrade proposal %d", c.CancelUpgrade.ProposalID)
+	default:
+		return governance.ProposalContentInvalidText
+	}
+}
+
+func init() {
+	governanceCmd.PersistentFlags().AddFlagSet(common.ConnectionFlags)
+	governanceCmd.PersistentFlags().AddFlagSet(common.AmountFlags)
+
+	governanceListCmd.Flags().BoolVar(&governanceAll, cfgAll, false, "also list closed proposals")
+	governanceVoteCmd.Flags().AddFlagSet(common.TxFlags)
+
+	governanceCmd.AddCommand(governanceListCmd)
+	governanceCmd.AddCommand(governanceShowCmd)
+	governanceCmd.AddCommand(governanceVoteCmd)
+}
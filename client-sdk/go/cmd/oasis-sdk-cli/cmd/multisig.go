@@ -0,0 +1,465 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/wallet"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const (
+	cfgThreshold = "threshold"
+	cfgConfig    = "config"
+)
+
+var (
+	multisigThreshold uint64
+	multisigConfig    string
+
+	multisigCmd = &cobra.Command{
+		Use:   "multisig",
+		Short: "Create multisig accounts and coordinate their signatures",
+		Long: `Create multisig accounts and coordinate the signatures of their transactions.
+
+A multisig transaction is created like any other unsigned transaction (--unsigned) and its signer
+is replaced by the multisig account with multisig prepare. Each participant then signs it with
+multisig sign, producing a partially signed transaction. The partially signed transactions are
+combined by multisig merge and submitted by multisig submit once the signed weight reaches the
+threshold of the account. Progress can be inspected at any time with tx decode.`,
+	}
+
+	multisigCreateCmd = &cobra.Command{
+		Use:   "create <signer>...",
+		Short: "Create a multisig configuration",
+		Long: `Create a multisig configuration and write it as JSON to the file given by --output.
+
+Signers are given either as the names of keystore accounts or as <algorithm>:<public key>, e.g.
+ed25519:NcPzNW3YU2T+ugNUtUWtoQnRvbOL9dYSaBfbjHLP1pE=, optionally followed by @<weight> (1 by
+default). Transactions of the multisig account need signatures of signers whose weights add up
+to at least --threshold.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config := &types.MultisigConfig{Threshold: multisigThreshold}
+			for _, arg := range args {
+				signer, err := parseMultisigSigner(arg)
+				if err != nil {
+					return err
+				}
+				config.Signers = append(config.Signers, *signer)
+			}
+			if err := config.ValidateBasic(); err != nil {
+				return fmt.Errorf("invalid multisig configuration: %w", err)
+			}
+
+			data, err := json.MarshalIndent(config, "", "  ")
+			if err != nil {
+				return err
+			}
+			if txOutput == "-" {
+				fmt.Fprintln(cmd.OutOrStdout(), string(data))
+			} else if err = os.WriteFile(txOutput, append(data, '\n'), 0o644); err != nil { //nolint: gosec
+				return fmt.Errorf("failed to write multisig configuration: %w", err)
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Address: %s\n", types.NewAddressFromMultisig(config))
+			return nil
+		},
+	}
+
+	multisigAddressCmd = &cobra.Command{
+		Use:   "address <config>",
+		Short: "Show the address of a multisig configuration",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := readMultisigConfig(args[0])
+			if err != nil {
+				return err
+			}
+			addr := types.NewAddressFromMultisig(config)
+			if common.OutputJSON() {
+				return common.PrintJSON(cmd.OutOrStdout(), &struct {
+					Address types.Address `json:"address"`
+				}{addr})
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), addr)
+			return nil
+		},
+	}
+
+	multisigPrepareCmd = &cobra.Command{
+		Use:   "prepare [<tx>]",
+		Short: "Make the multisig account the signer of an unsigned transaction",
+		Long: `Replace the signers of an unsigned transaction, e.g. one written with --unsigned, by the
+multisig account given by --config and write it to the file given by --output. Its fee is kept,
+so the gas limit of the transaction should account for verifying all multisig signatures.
+
+The nonce of the multisig account is given by --nonce or queried from the node at the round
+given by --round.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			rawTx, err := readRawTxArg(args)
+			if err != nil {
+				return err
+			}
+			if rawTx.Unverified != nil {
+				return fmt.Errorf("transaction is already signed")
+			}
+			if multisigConfig == "" {
+				return fmt.Errorf("no multisig configuration given, use --%s", cfgConfig)
+			}
+			config, err := readMultisigConfig(multisigConfig)
+			if err != nil {
+				return err
+			}
+
+			nonce := txNonce
+			if !cmd.Flags().Changed(cfgNonce) {
+				if txOffline {
+					return fmt.Errorf("no nonce given, use --%s", cfgNonce)
+				}
+				var (
+					rc   client.RuntimeClient
+					conn *grpc.ClientConn
+				)
+				if rc, conn, err = common.RuntimeClient(); err != nil {
+					return err
+				}
+				defer conn.Close()
+				if nonce, err = accounts.NewV1(rc).Nonce(ctx, txRound, types.NewAddressFromMultisig(config)); err != nil {
+					return fmt.Errorf("failed to query nonce: %w", err)
+				}
+			}
+
+			tx := rawTx.Tx
+			tx.AuthInfo.SignerInfo = nil
+			tx.AppendAuthMultisig(config, nonce)
+
+			printRawTx(cmd.ErrOrStderr(), &common.RawTx{Tx: tx}, common.Decimals())
+			return common.WriteRawTx(txOutput, cmd.OutOrStdout(), cbor.Marshal(tx))
+		},
+	}
+
+	multisigSignCmd = &cobra.Command{
+		Use:   "sign [<tx>]",
+		Short: "Add a signature to a multisig transaction",
+		Long: `Sign a multisig transaction, unsigned or already partially signed, with the account given by
+--account, or a Ledger device with --signer ledger, and write the partially signed transaction to
+the file given by --output.
+
+With --offline the node is never contacted and the chain context of the ParaTime is given by
+--chain-context or derived from the selected ParaTime profile.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			rawTx, err := readRawTxArg(args)
+			if err != nil {
+				return err
+			}
+			acct, err := common.TxAccount()
+			if err != nil {
+				return err
+			}
+			pk, err := acct.PublicKeyValue()
+			if err != nil {
+				return err
+			}
+			if !hasMultisigSigner(rawTx.Tx, pk) {
+				return fmt.Errorf("account %s is not a multisig signer of the transaction", acct.Name)
+			}
+
+			var rc client.RuntimeClient
+			if !txOffline {
+				var conn *grpc.ClientConn
+				if rc, conn, err = common.RuntimeClient(); err != nil {
+					return err
+				}
+				defer conn.Close()
+			}
+			chainContext, err := signingChainContext(ctx, rc)
+			if err != nil {
+				return err
+			}
+
+			printRawTx(cmd.ErrOrStderr(), rawTx, common.Decimals())
+			fmt.Fprintf(cmd.ErrOrStderr(), "Chain context: %s\n", chainContext)
+			if err = common.Confirm("Sign the transaction?"); err != nil {
+				return err
+			}
+
+			signer, err := common.AccountSigner(acct)
+			if err != nil {
+				return err
+			}
+			utx := partiallySignedTx(rawTx)
+			if err = appendMultisigSignature(chainContext, rawTx.Tx, utx, signer); err != nil {
+				return err
+			}
+			return common.WriteRawTx(txOutput, cmd.OutOrStdout(), cbor.Marshal(utx))
+		},
+	}
+
+	multisigMergeCmd = &cobra.Command{
+		Use:   "merge <tx file>...",
+		Short: "Combine partially signed multisig transactions",
+		Long: `Combine the signatures of partially signed multisig transactions, e.g. the ones written by
+the participants with multisig sign, into one transaction written to the file given by --output.
+The signed weight of every multisig signer is shown.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rawTx, err := mergeMultisigTxFiles(args)
+			if err != nil {
+				return err
+			}
+			printMultisigProgress(cmd.ErrOrStderr(), rawTx)
+			return common.WriteRawTx(txOutput, cmd.OutOrStdout(), rawTx.Raw)
+		},
+	}
+
+	multisigSubmitCmd = &cobra.Command{
+		Use:   "submit <tx file>...",
+		Short: "Combine partially signed multisig transactions and submit them",
+		Long: `Combine the signatures of partially signed multisig transactions like multisig merge and,
+once the signed weight of every multisig signer reaches its threshold, verify and submit the
+transaction, waiting for its execution unless --no-wait is set.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			rawTx, err := mergeMultisigTxFiles(args)
+			if err != nil {
+				return err
+			}
+			printMultisigProgress(cmd.ErrOrStderr(), rawTx)
+
+			rc, conn, err := common.RuntimeClient()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			chainContext, err := signingChainContext(ctx, rc)
+			if err != nil {
+				return err
+			}
+			if _, err = rawTx.Unverified.Verify(chainContext); err != nil {
+				return fmt.Errorf("transaction cannot be submitted yet: %w", err)
+			}
+			return submitSignedTx(ctx, cmd, rc, rawTx)
+		},
+	}
+)
+
+// parseMultisigSigner parses a multisig signer given as <account or public key>[@<weight>].
+func parseMultisigSigner(s string) (*types.MultisigSigner, error) {
+	key, weight := s, uint64(1)
+	if i := strings.LastIndexByte(s, '@'); i >= 0 {
+		var err error
+		if weight, err = strconv.ParseUint(s[i+1:], 10, 64); err != nil {
+			return nil, fmt.Errorf("malformed weight of signer %s: %w", s, err)
+		}
+		key = s[:i]
+	}
+
+	var (
+		pk  signature.PublicKey
+		err error
+	)
+	if strings.Contains(key, ":") {
+		pk, err = wallet.ParsePublicKey(key)
+	} else {
+		var acct *wallet.Account
+		if acct, err = common.GetAccount(key); err == nil {
+			pk, err = acct.PublicKeyValue()
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("malformed signer %s: %w", s, err)
+	}
+	return &types.MultisigSigner{PublicKey: types.PublicKey{PublicKey: pk}, Weight: weight}, nil
+}
+
+func readMultisigConfig(path string) (*types.MultisigConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read multisig configuration: %w", err)
+	}
+	var config types.MultisigConfig
+	if err = json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("malformed multisig configuration: %w", err)
+	}
+	if err = config.ValidateBasic(); err != nil {
+		return nil, fmt.Errorf("invalid multisig configuration: %w", err)
+	}
+	return &config, nil
+}
+
+// hasMultisigSigner returns true iff the given public key is a signer of any multisig signer of
+// the transaction.
+func hasMultisigSigner(tx *types.Transaction, pk signature.PublicKey) bool {
+	for _, si := range tx.AuthInfo.SignerInfo {
+		if si.AddressSpec.Multisig == nil {
+			continue
+		}
+		for _, signer := range si.AddressSpec.Multisig.Signers {
+			if signer.PublicKey.Equal(pk) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// partiallySignedTx returns a copy of the given transaction as a (partially) signed transaction,
+// with empty signature slots for all signers that have not signed yet.
+func partiallySignedTx(rawTx *common.RawTx) *types.UnverifiedTransaction {
+	signerInfo := rawTx.Tx.AuthInfo.SignerInfo
+	utx := &types.UnverifiedTransaction{
+		Body:       rawTx.Raw,
+		AuthProofs: make([]types.AuthProof, len(signerInfo)),
+	}
+	if rawTx.Unverified != nil {
+		utx.Body = rawTx.Unverified.Body
+	}
+	for i, si := range signerInfo {
+		var proof *types.AuthProof
+		if rawTx.Unverified != nil && i < len(rawTx.Unverified.AuthProofs) {
+			proof = &rawTx.Unverified.AuthProofs[i]
+			utx.AuthProofs[i].Signature = proof.Signature
+		}
+		if si.AddressSpec.Multisig == nil {
+			continue
+		}
+		utx.AuthProofs[i].Multisig = make([][]byte, len(si.AddressSpec.Multisig.Signers))
+		if proof != nil {
+			copy(utx.AuthProofs[i].Multisig, proof.Multisig)
+		}
+	}
+	return utx
+}
+
+// appendMultisigSignature signs the transaction and adds the signature to every multisig signer
+// the signer is part of.
+func appendMultisigSignature(chainContext signature.Context, tx *types.Transaction, utx *types.UnverifiedTransaction, signer signature.Signer) error {
+	sig, err := signer.ContextSign(chainContext.New(types.SignatureContextBase), utx.Body)
+	if err != nil {
+		return fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	for i, si := range tx.AuthInfo.SignerInfo {
+		if si.AddressSpec.Multisig == nil {
+			continue
+		}
+		for j, ms := range si.AddressSpec.Multisig.Signers {
+			if ms.PublicKey.Equal(signer.Public()) {
+				utx.AuthProofs[i].Multisig[j] = sig
+			}
+		}
+	}
+	return nil
+}
+
+// mergeMultisigTxs combines the signatures of partially signed copies of the same transaction.
+func mergeMultisigTxs(rawTxs []*common.RawTx) (*common.RawTx, error) {
+	merged := partiallySignedTx(rawTxs[0])
+	for n, rawTx := range rawTxs[1:] {
+		utx := partiallySignedTx(rawTx)
+		if !bytes.Equal(utx.Body, merged.Body) {
+			return nil, fmt.Errorf("transaction %d differs from the first transaction", n+2)
+		}
+		for i := range utx.AuthProofs {
+			if merged.AuthProofs[i].Signature == nil {
+				merged.AuthProofs[i].Signature = utx.AuthProofs[i].Signature
+			}
+			for j, sig := range utx.AuthProofs[i].Multisig {
+				if merged.AuthProofs[i].Multisig[j] == nil {
+					merged.AuthProofs[i].Multisig[j] = sig
+				}
+			}
+		}
+	}
+	return &common.RawTx{
+		Raw:        cbor.Marshal(merged),
+		Unverified: merged,
+		Tx:         rawTxs[0].Tx,
+	}, nil
+}
+
+func mergeMultisigTxFiles(paths []string) (*common.RawTx, error) {
+	rawTxs := make([]*common.RawTx, 0, len(paths))
+	for _, path := range paths {
+		rawTx, err := common.ReadRawTx(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		rawTxs = append(rawTxs, rawTx)
+	}
+	return mergeMultisigTxs(rawTxs)
+}
+
+// printMultisigProgress prints the signed weight of every multisig signer of the transaction.
+func printMultisigProgress(w io.Writer, rawTx *common.RawTx) {
+	for i, si := range rawTx.Tx.AuthInfo.SignerInfo {
+		config := si.AddressSpec.Multisig
+		if config == nil {
+			continue
+		}
+		var weight uint64
+		for j, signer := range config.Signers {
+			if rawTx.Unverified.AuthProofs[i].Multisig[j] != nil {
+				weight += signer.Weight
+			}
+		}
+		fmt.Fprintf(w, "%s: signed weight %d/%d\n", types.NewAddressFromMultisig(config), weight, config.Threshold)
+	}
+}
+
+func init() {
+	multisigCmd.PersistentFlags().AddFlagSet(common.AmountFlags)
+
+	multisigCreateCmd.Flags().Uint64Var(&multisigThreshold, cfgThreshold, 1, "weight of the signatures required to authorize a transaction")
+	multisigCreateCmd.Flags().StringVarP(&txOutput, cfgOutput, "o", "-", "file the configuration is written to (- for standard output)")
+
+	multisigPrepareCmd.Flags().AddFlagSet(common.ConnectionFlags)
+	multisigPrepareCmd.Flags().StringVar(&multisigConfig, cfgConfig, "", "file containing the multisig configuration")
+	multisigPrepareCmd.Flags().StringVar(&txFile, cfgFile, "", "file containing the transaction (- for standard input)")
+	multisigPrepareCmd.Flags().BoolVar(&txOffline, cfgOffline, false, "do not connect to the node")
+	multisigPrepareCmd.Flags().Uint64Var(&txNonce, cfgNonce, 0, "nonce of the multisig account (queried if not given)")
+	multisigPrepareCmd.Flags().Uint64Var(&txRound, cfgRound, client.RoundLatest, "round at which to query the nonce")
+	multisigPrepareCmd.Flags().StringVarP(&txOutput, cfgOutput, "o", "-", "file the transaction is written to (- for hex to standard output)")
+
+	multisigSignCmd.Flags().AddFlagSet(common.ConnectionFlags)
+	multisigSignCmd.Flags().AddFlagSet(common.TxFlags)
+	multisigSignCmd.Flags().StringVar(&txFile, cfgFile, "", "file containing the transaction (- for standard input)")
+	multisigSignCmd.Flags().BoolVar(&txOffline, cfgOffline, false, "do not connect to the node")
+	multisigSignCmd.Flags().StringVar(&txChainContext, cfgChainContext, "", "chain context of the ParaTime (queried if not given)")
+	multisigSignCmd.Flags().StringVarP(&txOutput, cfgOutput, "o", "-", "file the partially signed transaction is written to (- for hex to standard output)")
+
+	multisigMergeCmd.Flags().StringVarP(&txOutput, cfgOutput, "o", "-", "file the merged transaction is written to (- for hex to standard output)")
+
+	multisigSubmitCmd.Flags().AddFlagSet(common.ConnectionFlags)
+	multisigSubmitCmd.Flags().StringVar(&txChainContext, cfgChainContext, "", "chain context of the ParaTime (queried if not given)")
+	multisigSubmitCmd.Flags().BoolVar(&txNoWait, cfgNoWait, false, "do not wait for the transaction to be executed")
+
+	multisigCmd.AddCommand(multisigCreateCmd)
+	multisigCmd.AddCommand(multisigAddressCmd)
+	multisigCmd.AddCommand(multisigPrepareCmd)
+	multisigCmd.AddCommand(multisigSignCmd)
+	multisigCmd.AddCommand(multisigMergeCmd)
+	multisigCmd.AddCommand(multisigSubmitCmd)
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func TestMultisig(t *testing.T) {
+	require := require.New(t)
+
+	chainContext := signature.Context("test chain context")
+
+	signer, err := parseMultisigSigner("ed25519:" + sdkTesting.Charlie.Signer.Public().String() + "@2")
+	require.NoError(err, "parseMultisigSigner")
+	require.EqualValues(2, signer.Weight)
+	_, err = parseMultisigSigner("ed25519:" + sdkTesting.Charlie.Signer.Public().String() + "@x")
+	require.Error(err, "malformed weights should be rejected")
+
+	config := &types.MultisigConfig{
+		Signers: []types.MultisigSigner{
+			{PublicKey: types.PublicKey{PublicKey: sdkTesting.Alice.Signer.Public()}, Weight: 1},
+			{PublicKey: types.PublicKey{PublicKey: sdkTesting.Bob.Signer.Public()}, Weight: 1},
+			*signer,
+		},
+		Threshold: 2,
+	}
+	tx := types.NewTransaction(nil, "accounts.Transfer", map[string]interface{}{})
+	tx.AppendAuthMultisig(config, 3)
+	unsigned, err := common.DecodeRawTx(cbor.Marshal(tx))
+	require.NoError(err, "DecodeRawTx")
+	require.True(hasMultisigSigner(unsigned.Tx, sdkTesting.Alice.Signer.Public()))
+	require.False(hasMultisigSigner(unsigned.Tx, sdkTesting.Dave.Signer.Public()))
+
+	// Every participant signs the unsigned transaction independently.
+	partial := func(s signature.Signer) *common.RawTx {
+		utx := partiallySignedTx(unsigned)
+		require.NoError(appendMultisigSignature(chainContext, unsigned.Tx, utx, s), "appendMultisigSignature")
+		rawTx, decErr := common.DecodeRawTx(cbor.Marshal(utx))
+		require.NoError(decErr, "DecodeRawTx")
+		return rawTx
+	}
+	alice := partial(sdkTesting.Alice.Signer)
+	bob := partial(sdkTesting.Bob.Signer)
+
+	merged, err := mergeMultisigTxs([]*common.RawTx{alice})
+	require.NoError(err, "mergeMultisigTxs")
+	_, err = merged.Unverified.Verify(chainContext)
+	require.Error(err, "transactions below the threshold should not verify")
+
+	merged, err = mergeMultisigTxs([]*common.RawTx{unsigned, alice, bob})
+	require.NoError(err, "mergeMultisigTxs")
+	verified, err := merged.Unverified.Verify(chainContext)
+	require.NoError(err, "Verify")
+	require.EqualValues(3, verified.AuthInfo.SignerInfo[0].Nonce)
+
+	other := types.NewTransaction(nil, "accounts.Transfer", map[string]interface{}{})
+	other.AppendAuthMultisig(config, 4)
+	otherRaw, err := common.DecodeRawTx(cbor.Marshal(other))
+	require.NoError(err, "DecodeRawTx")
+	_, err = mergeMultisigTxs([]*common.RawTx{alice, otherRaw})
+	require.Error(err, "different transactions should not be merged")
+}
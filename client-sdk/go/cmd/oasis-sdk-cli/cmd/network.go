@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/config"
+)
+
+const (
+	cfgDescription = "description"
+	cfgSymbol      = "symbol"
+	cfgDecimals    = "decimals"
+	cfgFaucet      = "faucet"
+)
+
+var (
+	profileDescription  string
+	profileChainContext string
+	profileFaucet       string
+	profileSymbol       string
+	profileDecimals     uint8
+	profileNetwork      string
+
+	networkCmd = &cobra.Command{
+		Use:   "network",
+		Short: "Manage network profiles",
+		Long: `Manage network profiles. A network profile names the node to connect to, the chain context
+and the denomination of the consensus layer, along with the ParaTimes of the network. Commands
+connecting to a node use the network given by --network, or the default network unless --node is
+given. The configuration is seeded with the public mainnet and testnet.`,
+	}
+
+	networkListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List network profiles",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := common.Config()
+			if err != nil {
+				return err
+			}
+			if common.OutputJSON() {
+				return common.PrintJSON(cmd.OutOrStdout(), &struct {
+					Networks       map[string]*config.Network `json:"networks"`
+					DefaultNetwork string                     `json:"default_network,omitempty"`
+				}{cfg.Networks, cfg.DefaultNetwork})
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "NAME\tRPC\tDENOMINATION\tDESCRIPTION\n")
+			for _, name := range cfg.SortedNetworks() {
+				n := cfg.Networks[name]
+				if name == cfg.DefaultNetwork {
+					name += " (default)"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, n.RPC, describeDenomination(&n.Denomination), n.Description)
+			}
+			return w.Flush()
+		},
+	}
+
+	networkAddCmd = &cobra.Command{
+		Use:   "add <name> <rpc>",
+		Short: "Add a network profile",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, rpc := args[0], args[1]
+			if err := config.ValidateName(name); err != nil {
+				return err
+			}
+			return updateConfig(func(cfg *config.Config) error {
+				if cfg.Networks[name] != nil {
+					return fmt.Errorf("%w: network %s", config.ErrExists, name)
+				}
+				if cfg.Networks == nil {
+					cfg.Networks = make(map[string]*config.Network)
+				}
+				cfg.Networks[name] = &config.Network{
+					Description:  profileDescription,
+					RPC:          rpc,
+					ChainContext: profileChainContext,
+					Denomination: config.Denomination{Symbol: profileSymbol, Decimals: profileDecimals},
+					Faucet:       profileFaucet,
+				}
+				return nil
+			})
+		},
+	}
+
+	networkRemoveCmd = &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove a network profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			return updateConfig(func(cfg *config.Config) error {
+				if cfg.Networks[name] == nil {
+					return fmt.Errorf("%w: network %s", config.ErrNotFound, name)
+				}
+				if cfg.DefaultNetwork == name {
+					return fmt.Errorf("network %s is the default network", name)
+				}
+				delete(cfg.Networks, name)
+				return nil
+			})
+		},
+	}
+
+	networkSetDefaultCmd = &cobra.Command{
+		Use:   "set-default <name>",
+		Short: "Set the default network",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			return updateConfig(func(cfg *config.Config) error {
+				if cfg.Networks[name] == nil {
+					return fmt.Errorf("%w: network %s", config.ErrNotFound, name)
+				}
+				cfg.DefaultNetwork = name
+				return nil
+			})
+		},
+	}
+
+	paraTimeCmd = &cobra.Command{
+		Use:   "paratime",
+		Short: "Manage the ParaTime profiles of a network",
+		Long: `Manage the ParaTime profiles of the network given by --network, or of the default network. A
+ParaTime profile names the ParaTime identifier and its denomination. Commands use the ParaTime
+given by --paratime, or the default ParaTime of the network unless --runtime-id is given.`,
+	}
+
+	paraTimeListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List ParaTime profiles",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := common.Config()
+			if err != nil {
+				return err
+			}
+			n, err := profileNetworkOf(cfg)
+			if err != nil {
+				return err
+			}
+			if common.OutputJSON() {
+				paraTimes := n.ParaTimes
+				if paraTimes == nil {
+					paraTimes = map[string]*config.ParaTime{}
+				}
+				return common.PrintJSON(cmd.OutOrStdout(), &struct {
+					ParaTimes       map[string]*config.ParaTime `json:"paratimes"`
+					DefaultParaTime string                      `json:"default_paratime,omitempty"`
+				}{paraTimes, n.DefaultParaTime})
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "NAME\tID\tDENOMINATION\n")
+			for _, name := range n.SortedParaTimes() {
+				pt := n.ParaTimes[name]
+				if name == n.DefaultParaTime {
+					name += " (default)"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", name, pt.ID, describeDenomination(&pt.Denomination))
+			}
+			return w.Flush()
+		},
+	}
+
+	paraTimeAddCmd = &cobra.Command{
+		Use:   "add <name> <id>",
+		Short: "Add a ParaTime profile",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, id := args[0], args[1]
+			if err := config.ValidateName(name); err != nil {
+				return err
+			}
+			return updateConfig(func(cfg *config.Config) error {
+				n, err := profileNetworkOf(cfg)
+				if err != nil {
+					return err
+				}
+				if n.ParaTimes[name] != nil {
+					return fmt.Errorf("%w: ParaTime %s", config.ErrExists, name)
+				}
+				if n.ParaTimes == nil {
+					n.ParaTimes = make(map[string]*config.ParaTime)
+				}
+				n.ParaTimes[name] = &config.ParaTime{
+					ID:           id,
+					Denomination: config.Denomination{Symbol: profileSymbol, Decimals: profileDecimals},
+				}
+				return nil
+			})
+		},
+	}
+
+	paraTimeRemoveCmd = &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove a ParaTime profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			return updateConfig(func(cfg *config.Config) error {
+				n, err := profileNetworkOf(cfg)
+				if err != nil {
+					return err
+				}
+				if n.ParaTimes[name] == nil {
+					return fmt.Errorf("%w: ParaTime %s", config.ErrNotFound, name)
+				}
+				if n.DefaultParaTime == name {
+					n.DefaultParaTime = ""
+				}
+				delete(n.ParaTimes, name)
+				return nil
+			})
+		},
+	}
+
+	paraTimeSetDefaultCmd = &cobra.Command{
+		Use:   "set-default <name>",
+		Short: "Set the default ParaTime of the network",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			return updateConfig(func(cfg *config.Config) error {
+				n, err := profileNetworkOf(cfg)
+				if err != nil {
+					return err
+				}
+				if n.ParaTimes[name] == nil {
+					return fmt.Errorf("%w: ParaTime %s", config.ErrNotFound, name)
+				}
+				n.DefaultParaTime = name
+				return nil
+			})
+		},
+	}
+)
+
+// updateConfig loads the configuration, applies the given update and saves it.
+func updateConfig(update func(cfg *config.Config) error) error {
+	cfg, err := common.Config()
+	if err != nil {
+		return err
+	}
+	if err = update(cfg); err != nil {
+		return err
+	}
+	return cfg.Save(common.ConfigDir())
+}
+
+// profileNetworkOf returns the network given by --network, or the default network.
+func profileNetworkOf(cfg *config.Config) (*config.Network, error) {
+	n, err := cfg.Network(profileNetwork)
+	if err != nil {
+		return nil, err
+	}
+	if n == nil {
+		return nil, fmt.Errorf("no network given, use --%s", common.CfgNetwork)
+	}
+	return n, nil
+}
+
+func describeDenomination(d *config.Denomination) string {
+	if d.Symbol == "" {
+		return fmt.Sprintf("%d decimals", d.Decimals)
+	}
+	return fmt.Sprintf("%s (%d decimals)", d.Symbol, d.Decimals)
+}
+
+func init() {
+	networkAddCmd.Flags().StringVar(&profileDescription, cfgDescription, "", "description of the network")
+	networkAddCmd.Flags().StringVar(&profileChainContext, cfgChainContext, "", "chain context of the consensus layer, needed to sign transactions offline")
+	networkAddCmd.Flags().StringVar(&profileFaucet, cfgFaucet, "", "URL of the faucet service of the network, used by faucet request")
+	for _, cmd := range []*cobra.Command{networkAddCmd, paraTimeAddCmd} {
+		cmd.Flags().StringVar(&profileSymbol, cfgSymbol, "", "symbol of the denomination")
+		cmd.Flags().Uint8Var(&profileDecimals, cfgDecimals, common.DefaultDecimals, "number of decimals of the denomination")
+	}
+	paraTimeCmd.PersistentFlags().StringVar(&profileNetwork, common.CfgNetwork, "", "network of the ParaTime (the default network if not given)")
+
+	networkCmd.AddCommand(networkListCmd)
+	networkCmd.AddCommand(networkAddCmd)
+	networkCmd.AddCommand(networkRemoveCmd)
+	networkCmd.AddCommand(networkSetDefaultCmd)
+
+	paraTimeCmd.AddCommand(paraTimeListCmd)
+	paraTimeCmd.AddCommand(paraTimeAddCmd)
+	paraTimeCmd.AddCommand(paraTimeRemoveCmd)
+	paraTimeCmd.AddCommand(paraTimeSetDefaultCmd)
+}
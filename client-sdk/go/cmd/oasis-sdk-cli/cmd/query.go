@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	coreCommon "github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client/consensus"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+var (
+	queryCmd = &cobra.Command{
+		Use:   "query",
+		Short: "Query the consensus layer and ParaTimes",
+	}
+
+	queryBalanceCmd = &cobra.Command{
+		Use:   "balance <address>",
+		Short: "Show the balances of an account",
+		Long: `Show the consensus layer balances of an account, followed by its balances in each
+denomination of every ParaTime given by --runtime-id, which may be repeated.
+
+The account is given either as a Bech32-encoded address or as the name of a keystore
+account.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			addr, err := common.ResolveAddress(args[0])
+			if err != nil {
+				return err
+			}
+			runtimeIDs, err := common.RuntimeIDs()
+			if err != nil {
+				return err
+			}
+			conn, err := common.Connect()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			account, err := consensus.New(conn).Account(ctx, consensus.HeightLatest, addr)
+			if err != nil {
+				return fmt.Errorf("failed to query consensus account: %w", err)
+			}
+
+			result := &balanceJSON{
+				Address: addr,
+				Consensus: consensusBalanceJSON{
+					Available: account.General.Balance,
+					Staked:    account.Escrow.Active.Balance,
+					Debonding: account.Escrow.Debonding.Balance,
+					Nonce:     account.General.Nonce,
+				},
+				ParaTimes: []paraTimeBalanceJSON{},
+			}
+			for _, id := range runtimeIDs {
+				balances, qErr := accounts.NewV1(client.New(conn, id)).Balances(ctx, client.RoundLatest, addr)
+				if qErr != nil {
+					return fmt.Errorf("failed to query balances in ParaTime %s: %w", id, qErr)
+				}
+				result.ParaTimes = append(result.ParaTimes, paraTimeBalanceJSON{ID: id, Balances: balances.Balances})
+			}
+			if common.OutputJSON() {
+				return common.PrintJSON(cmd.OutOrStdout(), result)
+			}
+
+			decimals, consensusDecimals := common.Decimals(), common.ConsensusDecimals()
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "Address:\t%s\n", addr)
+			fmt.Fprintf(w, "\n")
+			fmt.Fprintf(w, "Consensus layer:\t\n")
+			fmt.Fprintf(w, "  Available:\t%s\n", common.FormatAmount(&result.Consensus.Available, consensusDecimals))
+			fmt.Fprintf(w, "  Staked:\t%s\n", common.FormatAmount(&result.Consensus.Staked, consensusDecimals))
+			fmt.Fprintf(w, "  Debonding:\t%s\n", common.FormatAmount(&result.Consensus.Debonding, consensusDecimals))
+			fmt.Fprintf(w, "  Nonce:\t%d\n", result.Consensus.Nonce)
+
+			for _, pt := range result.ParaTimes {
+				denominations := make([]types.Denomination, 0, len(pt.Balances))
+				for denomination := range pt.Balances {
+					denominations = append(denominations, denomination)
+				}
+				sort.Slice(denominations, func(i, j int) bool {
+					return string(denominations[i]) < string(denominations[j])
+				})
+
+				fmt.Fprintf(w, "\n")
+				fmt.Fprintf(w, "ParaTime %s:\t\n", pt.ID)
+				if len(denominations) == 0 {
+					fmt.Fprintf(w, "  (no balances)\t\n")
+				}
+				for _, denomination := range denominations {
+					amount := pt.Balances[denomination]
+					fmt.Fprintf(w, "  %s:\t%s\n", denomination, common.FormatAmount(&amount, decimals))
+				}
+			}
+			return w.Flush()
+		},
+	}
+
+	queryDelegationsCmd = &cobra.Command{
+		Use:   "delegations <address>",
+		Short: "Show the delegations of an account",
+		Long: `Show the consensus layer delegations of an account, active and debonding, along with the
+amounts of stake they are worth.
+
+The account is given either as a Bech32-encoded address or as the name of a keystore
+account. Tokens held in ParaTimes cannot be delegated, as the consensus accounts module does
+not support delegating on behalf of ParaTime accounts.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			addr, err := common.ResolveAddress(args[0])
+			if err != nil {
+				return err
+			}
+			conn, err := common.Connect()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			cons := consensus.New(conn)
+			dels, err := cons.DelegationsFor(ctx, consensus.HeightLatest, addr)
+			if err != nil {
+				return fmt.Errorf("failed to query delegations: %w", err)
+			}
+			debDels, err := cons.DebondingDelegationsFor(ctx, consensus.HeightLatest, addr)
+			if err != nil {
+				return fmt.Errorf("failed to query debonding delegations: %w", err)
+			}
+
+			// Shares are converted into stake using the share pools of the escrow accounts.
+			escrows := make(map[types.Address]*staking.Account)
+			escrowAccount := func(escrow types.Address) (*staking.Account, error) {
+				if acct, ok := escrows[escrow]; ok {
+					return acct, nil
+				}
+				acct, qErr := cons.Account(ctx, consensus.HeightLatest, escrow)
+				if qErr != nil {
+					return nil, fmt.Errorf("failed to query escrow account %s: %w", escrow, qErr)
+				}
+				escrows[escrow] = acct
+				return acct, nil
+			}
+
+			result := &delegationsJSON{
+				Address:   addr,
+				Active:    []delegationJSON{},
+				Debonding: []delegationJSON{},
+			}
+			for escrow, d := range dels {
+				acct, qErr := escrowAccount(escrow)
+				if qErr != nil {
+					return qErr
+				}
+				amount, qErr := acct.Escrow.Active.StakeForShares(&d.Shares)
+				if qErr != nil {
+					return fmt.Errorf("failed to compute stake of delegation to %s: %w", escrow, qErr)
+				}
+				result.Active = append(result.Active, delegationJSON{Escrow: escrow, Shares: d.Shares, Amount: *amount})
+			}
+			for escrow, ds := range debDels {
+				acct, qErr := escrowAccount(escrow)
+				if qErr != nil {
+					return qErr
+				}
+				for _, d := range ds {
+					amount, sErr := acct.Escrow.Debonding.StakeForShares(&d.Shares)
+					if sErr != nil {
+						return fmt.Errorf("failed to compute stake of debonding delegation to %s: %w", escrow, sErr)
+					}
+					result.Debonding = append(result.Debonding, delegationJSON{
+						Escrow:        escrow,
+						Shares:        d.Shares,
+						Amount:        *amount,
+						DebondEndTime: d.DebondEndTime,
+					})
+				}
+			}
+			sortDelegations(result.Active)
+			sortDelegations(result.Debonding)
+			if common.OutputJSON() {
+				return common.PrintJSON(cmd.OutOrStdout(), result)
+			}
+
+			decimals := common.ConsensusDecimals()
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "Address:\t%s\n", addr)
+			fmt.Fprintf(w, "\n")
+			fmt.Fprintf(w, "Active delegations:\t\n")
+			if len(result.Active) == 0 {
+				fmt.Fprintf(w, "  (none)\t\n")
+			}
+			for _, d := range result.Active {
+				fmt.Fprintf(w, "  %s:\t%s\n", d.Escrow, common.FormatAmount(&d.Amount, decimals))
+			}
+			fmt.Fprintf(w, "\n")
+			fmt.Fprintf(w, "Debonding delegations:\t\n")
+			if len(result.Debonding) == 0 {
+				fmt.Fprintf(w, "  (none)\t\n")
+			}
+			for _, d := range result.Debonding {
+				fmt.Fprintf(w, "  %s:\t%s (until epoch %d)\n", d.Escrow, common.FormatAmount(&d.Amount, decimals), d.DebondEndTime)
+			}
+			return w.Flush()
+		},
+	}
+)
+
+// balanceJSON are the balances of an account, in base units.
+type balanceJSON struct {
+	Address   types.Address         `json:"address"`
+	Consensus consensusBalanceJSON  `json:"consensus"`
+	ParaTimes []paraTimeBalanceJSON `json:"paratimes"`
+}
+
+type consensusBalanceJSON struct {
+	Available quantity.Quantity `json:"available"`
+	Staked    quantity.Quantity `json:"staked"`
+	Debonding quantity.Quantity `json:"debonding"`
+	Nonce     uint64            `json:"nonce"`
+}
+
+type paraTimeBalanceJSON struct {
+	ID       coreCommon.Namespace                  `json:"id"`
+	Balances map[types.Denomination]types.Quantity `json:"balances"`
+}
+
+// delegationsJSON are the delegations of an account, in base units.
+type delegationsJSON struct {
+	Address   types.Address    `json:"address"`
+	Active    []delegationJSON `json:"active"`
+	Debonding []delegationJSON `json:"debonding"`
+}
+
+type delegationJSON struct {
+	Escrow        types.Address     `json:"escrow"`
+	Shares        quantity.Quantity `json:"shares"`
+	Amount        quantity.Quantity `json:"amount"`
+	DebondEndTime beacon.EpochTime  `json:"debond_end,omitempty"`
+}
+
+// sortDelegations sorts delegations by escrow address and debonding end time.
+func sortDelegations(dels []delegationJSON) {
+	sort.Slice(dels, func(i, j int) bool {
+		if dels[i].Escrow != dels[j].Escrow {
+			return dels[i].Escrow.String() < dels[j].Escrow.String()
+		}
+		return dels[i].DebondEndTime < dels[j].DebondEndTime
+	})
+}
+
+func init() {
+	queryCmd.PersistentFlags().AddFlagSet(common.ConnectionFlags)
+	queryCmd.PersistentFlags().AddFlagSet(common.AmountFlags)
+
+	queryCmd.AddCommand(queryBalanceCmd)
+	queryCmd.AddCommand(queryDelegationsCmd)
+}
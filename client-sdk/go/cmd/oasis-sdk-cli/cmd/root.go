@@ -0,0 +1,49 @@
+// Package cmd implements the commands of the CLI.
+package cmd
+
+import (
+	"os"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/spf13/cobra"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+)
+
+var rootCmd = &cobra.Command{
+	Use:           "oasis-sdk-cli",
+	Short:         "Command-line wallet and client for ParaTimes",
+	SilenceUsage:  true,
+	SilenceErrors: false,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return common.ValidateFormat()
+	},
+}
+
+// Execute runs the root command, exiting with a non-zero status on failure.
+func Execute() {
+	err := rootCmd.Execute()
+	// Stop signer plugins, e.g. the Ledger signer, before exiting.
+	plugin.CleanupClients()
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.PersistentFlags().AddFlagSet(common.RootFlags)
+
+	rootCmd.AddCommand(accountCmd)
+	rootCmd.AddCommand(txCmd)
+	rootCmd.AddCommand(queryCmd)
+	rootCmd.AddCommand(evmCmd)
+	rootCmd.AddCommand(contractsCmd)
+	rootCmd.AddCommand(eventsCmd)
+	rootCmd.AddCommand(multisigCmd)
+	rootCmd.AddCommand(addrCmd)
+	rootCmd.AddCommand(networkCmd)
+	rootCmd.AddCommand(paraTimeCmd)
+	rootCmd.AddCommand(governanceCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(faucetCmd)
+}
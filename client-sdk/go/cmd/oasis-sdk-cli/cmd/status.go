@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+
+	coreCommon "github.com/oasisprotocol/oasis-core/go/common"
+	keymanager "github.com/oasisprotocol/oasis-core/go/keymanager/api"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client/consensus"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+)
+
+// maxBlockAge is the age of the latest consensus block above which the node is reported as
+// possibly out of sync. Consensus blocks are produced every few seconds.
+const maxBlockAge = time.Minute
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the status of the node and the ParaTime",
+	Long: `Show the status of the node given by the connection flags: its latest consensus block and, if a
+ParaTime is given, the latest and last retained rounds of the ParaTime along with the status of
+its key manager. Problems that may prevent transactions from going through, e.g. a node that is
+out of sync, are reported as warnings.
+
+The last retained round and the storage status of the ParaTime require access to the control API
+of the node, e.g. through its internal UNIX socket.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		runtimeIDs, err := common.RuntimeIDs()
+		if err != nil {
+			return err
+		}
+		if len(runtimeIDs) > 1 {
+			return fmt.Errorf("more than one ParaTime given")
+		}
+		conn, err := common.Connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		cons := consensus.New(conn)
+		start := time.Now()
+		blk, err := cons.Backend().GetBlock(ctx, consensus.HeightLatest)
+		if err != nil {
+			return fmt.Errorf("failed to query the latest consensus block: %w", err)
+		}
+		status := &statusJSON{
+			ResponseTime: time.Since(start).Milliseconds(),
+			Consensus: consensusStatusJSON{
+				LatestHeight: blk.Height,
+				LatestTime:   blk.Time,
+			},
+			Warnings: []string{},
+		}
+		if status.Consensus.ChainContext, err = cons.ChainContext(ctx); err != nil {
+			return fmt.Errorf("failed to query chain context: %w", err)
+		}
+		if age := time.Since(blk.Time); age > maxBlockAge {
+			status.warn("the latest consensus block is %s old, the node may be out of sync", age.Round(time.Second))
+		}
+		if len(runtimeIDs) == 1 {
+			status.ParaTime = queryParaTimeStatus(ctx, conn, runtimeIDs[0], status)
+		}
+
+		if common.OutputJSON() {
+			return common.PrintJSON(cmd.OutOrStdout(), status)
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "Response time:\t%d ms\n", status.ResponseTime)
+		fmt.Fprintf(w, "\n")
+		fmt.Fprintf(w, "Consensus layer:\t\n")
+		fmt.Fprintf(w, "  Chain context:\t%s\n", status.Consensus.ChainContext)
+		fmt.Fprintf(w, "  Latest height:\t%d\n", status.Consensus.LatestHeight)
+		fmt.Fprintf(w, "  Latest block time:\t%s\n", status.Consensus.LatestTime.Format("2006-01-02 15:04:05 MST"))
+		if pt := status.ParaTime; pt != nil {
+			fmt.Fprintf(w, "\n")
+			fmt.Fprintf(w, "ParaTime %s:\t\n", pt.ID)
+			if pt.LatestRound != nil {
+				fmt.Fprintf(w, "  Latest round:\t%d\n", *pt.LatestRound)
+			}
+			if pt.LastRetainedRound != nil {
+				fmt.Fprintf(w, "  Last retained round:\t%d\n", *pt.LastRetainedRound)
+			}
+			if pt.StorageSynced != nil {
+				fmt.Fprintf(w, "  Storage synced:\t%t\n", *pt.StorageSynced)
+			}
+			if km := pt.KeyManager; km == nil {
+				fmt.Fprintf(w, "  Key manager:\tnone\n")
+			} else {
+				fmt.Fprintf(w, "  Key manager:\t%s\n", km.ID)
+				fmt.Fprintf(w, "    Initialized:\t%t\n", km.Initialized)
+				fmt.Fprintf(w, "    Secure:\t%t\n", km.Secure)
+				fmt.Fprintf(w, "    Nodes:\t%d\n", km.Nodes)
+			}
+		}
+		if len(status.Warnings) > 0 {
+			fmt.Fprintf(w, "\n")
+			fmt.Fprintf(w, "Warnings:\t\n")
+			for _, warning := range status.Warnings {
+				fmt.Fprintf(w, "  %s\t\n", warning)
+			}
+		}
+		return w.Flush()
+	},
+}
+
+// statusJSON is the status of the node and the ParaTime.
+type statusJSON struct {
+	// ResponseTime is the time it took the node to return its latest consensus block, in
+	// milliseconds.
+	ResponseTime int64               `json:"response_time_ms"`
+	Consensus    consensusStatusJSON `json:"consensus"`
+	ParaTime     *paraTimeStatusJSON `json:"paratime,omitempty"`
+	// Warnings describe problems that may prevent transactions from going through.
+	Warnings []string `json:"warnings"`
+}
+
+func (s *statusJSON) warn(format string, args ...interface{}) {
+	s.Warnings = append(s.Warnings, fmt.Sprintf(format, args...))
+}
+
+type consensusStatusJSON struct {
+	ChainContext string    `json:"chain_context"`
+	LatestHeight int64     `json:"latest_height"`
+	LatestTime   time.Time `json:"latest_time"`
+}
+
+// paraTimeStatusJSON is the status of a ParaTime, whose fields are nil if they could not be
+// queried.
+type paraTimeStatusJSON struct {
+	ID                coreCommon.Namespace  `json:"id"`
+	LatestRound       *uint64               `json:"latest_round,omitempty"`
+	LastRetainedRound *uint64               `json:"last_retained_round,omitempty"`
+	StorageSynced     *bool                 `json:"storage_synced,omitempty"`
+	KeyManager        *keyManagerStatusJSON `json:"key_manager,omitempty"`
+}
+
+type keyManagerStatusJSON struct {
+	ID          coreCommon.Namespace `json:"id"`
+	Initialized bool                 `json:"initialized"`
+	Secure      bool                 `json:"secure"`
+	Nodes       int                  `json:"nodes"`
+}
+
+// queryParaTimeStatus queries the status of a ParaTime, adding warnings to the node status for
+// parts that cannot be queried or indicate problems.
+func queryParaTimeStatus(ctx context.Context, conn *grpc.ClientConn, id coreCommon.Namespace, status *statusJSON) *paraTimeStatusJSON {
+	rc := client.New(conn, id)
+	pt := &paraTimeStatusJSON{ID: id}
+
+	if rtStatus, err := rc.GetStatus(ctx); err == nil {
+		pt.LatestRound = &rtStatus.LatestRound
+		pt.LastRetainedRound = &rtStatus.LastRetainedRound
+		pt.StorageSynced = &rtStatus.StorageSynced
+		if !rtStatus.StorageSynced {
+			status.warn("the storage of the node has not caught up with the latest round")
+		}
+	} else {
+		status.warn("node status unavailable, the control API may not be exposed: %s", err)
+		if blk, blkErr := rc.GetBlock(ctx, client.RoundLatest); blkErr == nil {
+			pt.LatestRound = &blk.Header.Round
+		} else {
+			status.warn("failed to query the latest round: %s", blkErr)
+		}
+	}
+
+	rt, err := consensus.New(conn).RuntimeDescriptor(ctx, consensus.HeightLatest, id)
+	if err != nil {
+		status.warn("failed to query the ParaTime descriptor: %s", err)
+		return pt
+	}
+	if rt.KeyManager == nil {
+		return pt
+	}
+	km, err := keymanager.NewKeymanagerClient(conn).GetStatus(ctx, &registry.NamespaceQuery{
+		Height: consensus.HeightLatest,
+		ID:     *rt.KeyManager,
+	})
+	if err != nil {
+		status.warn("failed to query the key manager status: %s", err)
+		pt.KeyManager = &keyManagerStatusJSON{ID: *rt.KeyManager}
+		return pt
+	}
+	pt.KeyManager = &keyManagerStatusJSON{
+		ID:          km.ID,
+		Initialized: km.IsInitialized,
+		Secure:      km.IsSecure,
+		Nodes:       len(km.Nodes),
+	}
+	switch {
+	case !km.IsInitialized:
+		status.warn("the key manager is not initialized, confidential transactions cannot be processed")
+	case len(km.Nodes) == 0:
+		status.warn("the key manager has no active nodes, confidential transactions cannot be processed")
+	}
+	return pt
+}
+
+func init() {
+	statusCmd.Flags().AddFlagSet(common.ConnectionFlags)
+}
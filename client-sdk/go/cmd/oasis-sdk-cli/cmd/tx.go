@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	cmnErrors "github.com/oasisprotocol/oasis-core/go/common/errors"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/consensusaccounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const cfgDenomination = "denomination"
+
+var (
+	txDenomination string
+
+	txCmd = &cobra.Command{
+		Use:   "tx",
+		Short: "Sign and submit ParaTime transactions",
+	}
+
+	txTransferCmd = &cobra.Command{
+		Use:   "transfer <to> <amount>",
+		Short: "Transfer tokens to another ParaTime account",
+		Long: `Transfer tokens to another ParaTime account, given either as a Bech32-encoded address
+or as the name of a keystore account.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			to, err := common.ResolveAddress(args[0])
+			if err != nil {
+				return err
+			}
+			amount, err := common.ParseAmount(args[1], common.Decimals())
+			if err != nil {
+				return err
+			}
+
+			res, err := runTx(cmd, func(rc client.RuntimeClient) (*client.TransactionBuilder, string, error) {
+				denomination := types.Denomination(txDenomination)
+				summary := fmt.Sprintf("Transfer %s %s to %s", common.FormatAmount(amount, common.Decimals()), denomination, to)
+				return accounts.NewV1(rc).Transfer(to, types.NewBaseUnits(*amount, denomination)), summary, nil
+			}, false, nil)
+			if err != nil {
+				return err
+			}
+			return printTxResult(cmd, res)
+		},
+	}
+
+	txDepositCmd = &cobra.Command{
+		Use:   "deposit <amount>",
+		Short: "Deposit consensus layer tokens into the ParaTime",
+		Long: `Deposit consensus layer tokens from the consensus account of the signer into its ParaTime
+account. The ParaTime must be allowed to withdraw the amount from the consensus account.
+
+The command waits until the consensus layer has processed the deposit.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConsensusTx(cmd, args[0], "Deposit", consensusaccounts.V1.DepositWithReceipt)
+		},
+	}
+
+	txWithdrawCmd = &cobra.Command{
+		Use:   "withdraw <amount>",
+		Short: "Withdraw consensus layer tokens from the ParaTime",
+		Long: `Withdraw consensus layer tokens from the ParaTime account of the signer into its consensus
+account.
+
+The command waits until the consensus layer has processed the withdrawal.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConsensusTx(cmd, args[0], "Withdraw", consensusaccounts.V1.WithdrawWithReceipt)
+		},
+	}
+)
+
+// txBuilder builds a transaction to be signed and submitted, along with its human-readable
+// summary.
+type txBuilder func(rc client.RuntimeClient) (*client.TransactionBuilder, string, error)
+
+// txResult is the result of an executed transaction.
+type txResult struct {
+	// Hash is the hash of the transaction, if known.
+	Hash *hash.Hash `json:"hash,omitempty"`
+	// Round is the round in which the transaction was executed.
+	Round uint64 `json:"round"`
+	// ConsensusRound is the round in which the consensus layer transfer of the transaction was
+	// processed, if waited for.
+	ConsensusRound uint64 `json:"consensus_round,omitempty"`
+}
+
+// printTxResult prints the result of an executed transaction with --format json. The text output
+// is printed while the transaction is processed instead.
+func printTxResult(cmd *cobra.Command, res *txResult) error {
+	if res == nil || !common.OutputJSON() {
+		return nil
+	}
+	return common.PrintJSON(cmd.OutOrStdout(), res)
+}
+
+// runTx builds a transaction, asks for confirmation, signs it with the account given by the
+// transaction flags and submits it, decoding the call result into rsp unless it is nil. If
+// waitReceipt is set, it also waits for the receipt of the consensus layer transfer of the
+// transaction.
+//
+// It returns the result of the executed transaction, or nil if the unsigned transaction was
+// written to a file instead.
+func runTx(cmd *cobra.Command, build txBuilder, waitReceipt bool, rsp interface{}) (*txResult, error) {
+	ctx := cmd.Context()
+
+	acct, err := common.TxAccount()
+	if err != nil {
+		return nil, err
+	}
+	rc, conn, err := common.RuntimeClient()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	tb, summary, err := build(rc)
+	if err != nil {
+		return nil, err
+	}
+	if waitReceipt {
+		tb.SetFeeConsensusMessages(1)
+	}
+	if err = common.PrepareTx(ctx, rc, acct, tb); err != nil {
+		return nil, err
+	}
+	tx := tb.GetTransaction()
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "%s\n", summary)
+	fmt.Fprintf(cmd.ErrOrStderr(), "Signer: %s (%s)\n", acct.Name, acct.Address)
+	fmt.Fprintf(cmd.ErrOrStderr(), "Fee:    %s\n", common.DescribeFee(tx))
+
+	// Leave signing to e.g. an offline machine.
+	if path := common.UnsignedTxPath(); path != "" {
+		return nil, common.WriteRawTx(path, cmd.OutOrStdout(), cbor.Marshal(tx))
+	}
+
+	if err = common.Confirm("Sign and submit the transaction?"); err != nil {
+		return nil, err
+	}
+
+	// Subscribe to blocks before submitting so no round is missed while waiting for the receipt.
+	var blkCh <-chan *roothash.AnnotatedBlock
+	if waitReceipt {
+		ch, sub, subErr := rc.WatchBlocks(ctx)
+		if subErr != nil {
+			return nil, fmt.Errorf("failed to watch blocks: %w", subErr)
+		}
+		defer sub.Close()
+		blkCh = ch
+	}
+
+	meta, err := common.SignAndSubmitTx(ctx, acct, tb, rsp)
+	if err != nil {
+		return nil, err
+	}
+	res := &txResult{Round: meta.Round}
+	if !common.OutputJSON() {
+		fmt.Fprintf(cmd.OutOrStdout(), "Executed in round %d.\n", res.Round)
+	}
+
+	if !waitReceipt {
+		return res, nil
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Waiting for the consensus layer to process the transfer...\n")
+	query := &consensusaccounts.ReceiptQuery{Address: acct.Address, ID: tx.AuthInfo.SignerInfo[0].Nonce}
+	if res.ConsensusRound, err = waitForReceipt(ctx, consensusaccounts.NewV1(rc), blkCh, res.Round, query); err != nil {
+		return nil, err
+	}
+	if !common.OutputJSON() {
+		fmt.Fprintf(cmd.OutOrStdout(), "Processed by the consensus layer in round %d.\n", res.ConsensusRound)
+	}
+	return res, nil
+}
+
+// runConsensusTx signs and submits a consensus accounts transaction built by the given
+// constructor, which takes the amount in the consensus denomination.
+func runConsensusTx(
+	cmd *cobra.Command,
+	rawAmount string,
+	action string,
+	newTx func(consensusaccounts.V1, types.BaseUnits) *client.TransactionBuilder,
+) error {
+	amount, err := common.ParseAmount(rawAmount, common.Decimals())
+	if err != nil {
+		return err
+	}
+
+	res, err := runTx(cmd, func(rc client.RuntimeClient) (*client.TransactionBuilder, string, error) {
+		ca := consensusaccounts.NewV1(rc)
+		denomination, qErr := ca.ConsensusDenomination(cmd.Context(), client.RoundLatest)
+		if qErr != nil {
+			return nil, "", fmt.Errorf("failed to query consensus denomination: %w", qErr)
+		}
+		summary := fmt.Sprintf("%s %s %s", action, common.FormatAmount(amount, common.Decimals()), denomination)
+		return newTx(ca, types.NewBaseUnits(*amount, denomination)), summary, nil
+	}, true, nil)
+	if err != nil {
+		return err
+	}
+	return printTxResult(cmd, res)
+}
+
+// waitForReceipt waits for the consensus layer transfer receipt given by the query, which is
+// stored in a round after the one in which the transaction was executed, and returns its round.
+func waitForReceipt(
+	ctx context.Context,
+	ca consensusaccounts.V1,
+	blkCh <-chan *roothash.AnnotatedBlock,
+	txRound uint64,
+	query *consensusaccounts.ReceiptQuery,
+) (uint64, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case blk, ok := <-blkCh:
+			if !ok {
+				return 0, fmt.Errorf("block subscription closed")
+			}
+			round := blk.Block.Header.Round
+			if round <= txRound {
+				continue
+			}
+
+			receipt, err := ca.Receipt(ctx, round, query)
+			switch {
+			case err == nil:
+				if !receipt.IsSuccess() {
+					return 0, fmt.Errorf("consensus transfer failed: %w",
+						cmnErrors.FromCode(receipt.Module, receipt.Code, ""))
+				}
+				return round, nil
+			case consensusaccounts.IsReceiptNotFound(err):
+				continue
+			default:
+				return 0, fmt.Errorf("failed to query receipt: %w", err)
+			}
+		}
+	}
+}
+
+func init() {
+	txCmd.PersistentFlags().AddFlagSet(common.ConnectionFlags)
+	txCmd.PersistentFlags().AddFlagSet(common.TxFlags)
+	txCmd.PersistentFlags().AddFlagSet(common.AmountFlags)
+
+	txTransferCmd.Flags().StringVar(&txDenomination, cfgDenomination, "", "denomination of the amount (native if empty)")
+	txPayBatchCmd.Flags().StringVar(&txDenomination, cfgDenomination, "", "denomination of payments not naming one (native if empty)")
+
+	txCmd.AddCommand(txTransferCmd)
+	txCmd.AddCommand(txPayBatchCmd)
+	txCmd.AddCommand(txDepositCmd)
+	txCmd.AddCommand(txWithdrawCmd)
+	txCmd.AddCommand(txDecodeCmd)
+	txCmd.AddCommand(txSignCmd)
+	txCmd.AddCommand(txBroadcastCmd)
+	txCmd.AddCommand(txEstimateCmd)
+}
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+var txPayBatchCmd = &cobra.Command{
+	Use:   "pay-batch <file>",
+	Short: "Pay many recipients listed in a CSV file",
+	Long: `Transfer tokens to each recipient listed in a CSV file (- for standard input), with one payment
+per row: the recipient, given either as a Bech32-encoded address or as the name of a keystore
+account, the amount and, optionally, its denomination (--denomination if empty). A first row
+naming the columns, e.g. recipient,amount,denomination, is skipped.
+
+After confirming the summary of the batch, each payment is submitted as its own transaction, with
+consecutive nonces, and the result of each payment is reported. Payments are not atomic: a
+payment failing during execution does not prevent the following ones from being submitted, while
+one that fails the transaction checks stops the batch, the remaining payments being reported as
+not submitted. The command fails if any payment failed, so that the failed rows can be retried.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		if common.UnsignedTxPath() != "" {
+			return fmt.Errorf("--%s is not supported for batch payments", common.CfgUnsigned)
+		}
+		payments, err := readPayments(args[0], types.Denomination(txDenomination))
+		if err != nil {
+			return err
+		}
+		acct, err := common.TxAccount()
+		if err != nil {
+			return err
+		}
+		rc, conn, err := common.RuntimeClient()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		batch := accounts.NewBatchTransfer(rc)
+		for _, p := range payments {
+			batch.Add(p.To, types.NewBaseUnits(p.Amount, p.Denomination))
+		}
+
+		// All payments are transfers, so they need the same gas as the first one.
+		first := accounts.NewV1(rc).Transfer(payments[0].To, types.NewBaseUnits(payments[0].Amount, payments[0].Denomination))
+		if err = common.PrepareTx(ctx, rc, acct, first); err != nil {
+			return err
+		}
+		fee := first.GetTransaction().AuthInfo.Fee
+		batch.SetFeeAmount(fee.Amount).SetFeeGas(fee.Gas)
+
+		decimals := common.Decimals()
+		totals, err := paymentTotals(payments)
+		if err != nil {
+			return err
+		}
+		totalFee := fee.Amount.Amount.Clone()
+		if err = totalFee.Mul(quantity.NewFromUint64(uint64(len(payments)))); err != nil {
+			return fmt.Errorf("failed to compute fees: %w", err)
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Pay %d recipients from %s (%s)\n", len(payments), acct.Name, acct.Address)
+		for _, total := range totals {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Total:  %s %s\n", common.FormatAmount(&total.Amount, decimals), total.Denomination)
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Fees:   %s (%d transactions, each %s)\n",
+			common.FormatAmount(totalFee, decimals), len(payments), common.DescribeFee(first.GetTransaction()))
+		if err = common.Confirm("Sign and submit all payments?"); err != nil {
+			return err
+		}
+
+		signer, err := common.AccountSigner(acct)
+		if err != nil {
+			return err
+		}
+		spec, err := acct.SigSpec()
+		if err != nil {
+			return err
+		}
+		results, err := batch.Submit(ctx, signer, spec)
+		if err != nil {
+			return err
+		}
+
+		out := make([]paymentResultJSON, 0, len(results))
+		var failed int
+		for i, r := range results {
+			res := paymentResultJSON{
+				Row:          payments[i].Row,
+				To:           payments[i].To,
+				Amount:       payments[i].Amount,
+				Denomination: payments[i].Denomination,
+				Nonce:        r.Nonce,
+			}
+			if r.Meta != nil {
+				res.Round = r.Meta.Round
+			}
+			if !r.IsSuccess() {
+				failed++
+				res.Error = "unknown error"
+				if r.Error != nil {
+					res.Error = r.Error.Error()
+				}
+			}
+			out = append(out, res)
+		}
+
+		if common.OutputJSON() {
+			err = common.PrintJSON(cmd.OutOrStdout(), out)
+		} else {
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "ROW\tRECIPIENT\tAMOUNT\tRESULT\n")
+			for _, res := range out {
+				result := fmt.Sprintf("executed in round %d", res.Round)
+				if res.Error != "" {
+					result = "failed: " + res.Error
+				}
+				amount := strings.TrimSpace(common.FormatAmount(&res.Amount, decimals) + " " + string(res.Denomination))
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", res.Row, res.To, amount, result)
+			}
+			err = w.Flush()
+		}
+		if err != nil {
+			return err
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d payments failed", failed, len(payments))
+		}
+		return nil
+	},
+}
+
+// payment is a payment read from a CSV file.
+type payment struct {
+	// Row is the row of the payment in the file, starting at 1.
+	Row          int
+	To           types.Address
+	Amount       quantity.Quantity
+	Denomination types.Denomination
+}
+
+// paymentResultJSON is the result of a payment.
+type paymentResultJSON struct {
+	Row          int                `json:"row"`
+	To           types.Address      `json:"to"`
+	Amount       quantity.Quantity  `json:"amount"`
+	Denomination types.Denomination `json:"denomination"`
+	Nonce        uint64             `json:"nonce"`
+	Round        uint64             `json:"round,omitempty"`
+	Error        string             `json:"error,omitempty"`
+}
+
+// readPayments reads the payments listed in the given CSV file, or standard input if the path is
+// "-".
+func readPayments(path string, denomination types.Denomination) ([]*payment, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open payments: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	return parsePayments(r, denomination, common.Decimals())
+}
+
+// parsePayments parses payments in CSV format, using the given denomination for rows that do not
+// name one.
+func parsePayments(r io.Reader, denomination types.Denomination, decimals uint8) ([]*payment, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	var payments []*payment
+	for row := 1; ; row++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("malformed payments: %w", err)
+		}
+		if len(record) < 2 || len(record) > 3 {
+			return nil, fmt.Errorf("row %d: expected recipient, amount and optional denomination", row)
+		}
+		if row == 1 && strings.EqualFold(strings.TrimSpace(record[1]), "amount") {
+			continue
+		}
+
+		to, err := common.ResolveAddress(strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+		amount, err := common.ParseAmount(strings.TrimSpace(record[1]), decimals)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+		p := &payment{Row: row, To: to, Amount: *amount, Denomination: denomination}
+		if len(record) == 3 && strings.TrimSpace(record[2]) != "" {
+			p.Denomination = types.Denomination(strings.TrimSpace(record[2]))
+		}
+		payments = append(payments, p)
+	}
+	if len(payments) == 0 {
+		return nil, fmt.Errorf("no payments given")
+	}
+	return payments, nil
+}
+
+// paymentTotals returns the total amount of the payments in each denomination.
+func paymentTotals(payments []*payment) ([]types.BaseUnits, error) {
+	sums := make(map[types.Denomination]*quantity.Quantity)
+	for _, p := range payments {
+		sum, ok := sums[p.Denomination]
+		if !ok {
+			sum = quantity.NewQuantity()
+			sums[p.Denomination] = sum
+		}
+		if err := sum.Add(&p.Amount); err != nil {
+			return nil, fmt.Errorf("failed to compute total: %w", err)
+		}
+	}
+	totals := make([]types.BaseUnits, 0, len(sums))
+	for denomination, sum := range sums {
+		totals = append(totals, types.NewBaseUnits(*sum, denomination))
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		return string(totals[i].Denomination) < string(totals[j].Denomination)
+	})
+	return totals, nil
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func TestParsePayments(t *testing.T) {
+	require := require.New(t)
+
+	// Recipients are also looked up in the keystore.
+	require.NoError(common.RootFlags.Set(common.CfgConfigDir, t.TempDir()))
+
+	alice, bob := sdkTesting.Alice.Address.String(), sdkTesting.Bob.Address.String()
+	payments, err := parsePayments(strings.NewReader(
+		"recipient,amount,denomination\n"+
+			alice+",1.5\n"+
+			bob+", 2,FOO\n"+
+			alice+",0.25,\n",
+	), types.NativeDenomination, 9)
+	require.NoError(err, "parsePayments")
+	require.Len(payments, 3)
+	require.Equal(2, payments[0].Row)
+	require.Equal(sdkTesting.Alice.Address, payments[0].To)
+	require.Equal("1500000000", payments[0].Amount.String())
+	require.Equal(types.NativeDenomination, payments[0].Denomination)
+	require.Equal(sdkTesting.Bob.Address, payments[1].To)
+	require.Equal(types.Denomination("FOO"), payments[1].Denomination)
+	require.Equal(types.NativeDenomination, payments[2].Denomination)
+
+	totals, err := paymentTotals(payments)
+	require.NoError(err, "paymentTotals")
+	require.Len(totals, 2)
+	require.Equal(types.NativeDenomination, totals[0].Denomination)
+	require.Equal("1750000000", totals[0].Amount.String())
+	require.Equal("2000000000", totals[1].Amount.String())
+
+	for _, s := range []string{
+		"",
+		"recipient,amount\n",
+		alice + "\n",
+		alice + ",1,FOO,extra\n",
+		alice + ",x\n",
+		"oasis1malformed,1\n",
+	} {
+		_, err = parsePayments(strings.NewReader(s), types.NativeDenomination, 9)
+		require.Error(err, "parsePayments(%q) should fail", s)
+	}
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+
+	// Register the methods of all modules, so that call bodies can be decoded.
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/consensusaccounts"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/contracts"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/core"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/evm"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/rewards"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/rofl"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/roflmarket"
+)
+
+const cfgFile = "file"
+
+var (
+	txFile string
+
+	txDecodeCmd = &cobra.Command{
+		Use:   "decode [<tx>]",
+		Short: "Decode and inspect a raw transaction",
+		Long: `Decode a CBOR-encoded transaction, signed or not, and show its method, body, fee and
+signers, so that it can be inspected before signing it.
+
+The transaction is given either as a hex or base64-encoded argument or, with --file, as a file
+containing the raw, hex or base64 encoding (- for standard input).`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rawTx, err := readRawTxArg(args)
+			if err != nil {
+				return err
+			}
+			if common.OutputJSON() {
+				return common.PrintJSON(cmd.OutOrStdout(), newRawTxJSON(rawTx))
+			}
+			printRawTx(cmd.OutOrStdout(), rawTx, common.Decimals())
+			return nil
+		},
+	}
+)
+
+// readRawTxArg reads a raw transaction given either as the only argument or with --file.
+func readRawTxArg(args []string) (*common.RawTx, error) {
+	switch {
+	case len(args) == 1 && txFile != "":
+		return nil, fmt.Errorf("transaction given both as an argument and with --%s", cfgFile)
+	case len(args) == 1:
+		return common.ParseRawTx(args[0])
+	case txFile != "":
+		return common.ReadRawTx(txFile)
+	default:
+		return nil, fmt.Errorf("no transaction given")
+	}
+}
+
+// rawTxJSON is the JSON form of a raw transaction.
+type rawTxJSON struct {
+	// Hash is the hash of the transaction, if signed.
+	Hash *hash.Hash         `json:"hash,omitempty"`
+	Tx   *types.Transaction `json:"tx"`
+	// Body is the decoded body of the call, if its method is known.
+	Body interface{} `json:"body,omitempty"`
+	// Signers are the addresses of the signers of the transaction.
+	Signers []types.Address `json:"signers"`
+	// AuthProofs are the signatures of the transaction, if signed.
+	AuthProofs []types.AuthProof `json:"auth_proofs,omitempty"`
+}
+
+func newRawTxJSON(rawTx *common.RawTx) *rawTxJSON {
+	out := &rawTxJSON{
+		Tx:      rawTx.Tx,
+		Signers: []types.Address{},
+	}
+	if rawTx.Unverified != nil {
+		h := hash.NewFromBytes(rawTx.Raw)
+		out.Hash = &h
+		out.AuthProofs = rawTx.Unverified.AuthProofs
+	}
+	if _, body, err := registry.DecodeCall(&rawTx.Tx.Call); err == nil {
+		out.Body = body
+	}
+	for _, si := range rawTx.Tx.AuthInfo.SignerInfo {
+		if addr, err := si.AddressSpec.Address(); err == nil {
+			out.Signers = append(out.Signers, addr)
+		}
+	}
+	return out
+}
+
+// printRawTx prints a human-readable description of the given raw transaction.
+func printRawTx(w io.Writer, rawTx *common.RawTx, decimals uint8) {
+	tx := rawTx.Tx
+
+	if rawTx.Unverified != nil {
+		fmt.Fprintf(w, "Hash:     %s\n", hash.NewFromBytes(rawTx.Raw))
+	} else {
+		fmt.Fprintf(w, "Hash:     (unsigned transaction)\n")
+	}
+	fmt.Fprintf(w, "Method:   %s\n", tx.Call.Method)
+	if tx.Call.Format != types.CallFormatPlain {
+		fmt.Fprintf(w, "Format:   encrypted (%d)\n", tx.Call.Format)
+	}
+
+	fee := tx.AuthInfo.Fee
+	fmt.Fprintf(w, "Fee:      %s %s\n", common.FormatAmount(&fee.Amount.Amount, decimals), fee.Amount.Denomination)
+	fmt.Fprintf(w, "Gas:      %d\n", fee.Gas)
+	if fee.ConsensusMessages > 0 {
+		fmt.Fprintf(w, "Consensus messages: %d\n", fee.ConsensusMessages)
+	}
+
+	fmt.Fprintf(w, "Body:\n")
+	if _, body, err := registry.DecodeCall(&tx.Call); err == nil {
+		pretty, _ := json.MarshalIndent(body, "  ", "  ")
+		fmt.Fprintf(w, "  %s\n", pretty)
+	} else {
+		fmt.Fprintf(w, "  %s\n", hex.EncodeToString(tx.Call.Body))
+		fmt.Fprintf(w, "  (not decoded: %s)\n", err)
+	}
+
+	fmt.Fprintf(w, "Signers:\n")
+	for i := range tx.AuthInfo.SignerInfo {
+		var proof *types.AuthProof
+		if rawTx.Unverified != nil && i < len(rawTx.Unverified.AuthProofs) {
+			proof = &rawTx.Unverified.AuthProofs[i]
+		}
+		printSignerInfo(w, i, &tx.AuthInfo.SignerInfo[i], proof)
+	}
+}
+
+func printSignerInfo(w io.Writer, index int, si *types.SignerInfo, proof *types.AuthProof) {
+	addr, err := si.AddressSpec.Address()
+	if err != nil {
+		fmt.Fprintf(w, "  %d. (malformed address specification)\n", index+1)
+		return
+	}
+	fmt.Fprintf(w, "  %d. %s (nonce: %d)\n", index+1, addr, si.Nonce)
+
+	switch {
+	case si.AddressSpec.Signature != nil:
+		signed := proof != nil && proof.Signature != nil
+		fmt.Fprintf(w, "     %s %s\n", signatureAlgorithm(si.AddressSpec.Signature), si.AddressSpec.Signature.PublicKey())
+		fmt.Fprintf(w, "     signed: %t\n", signed)
+	case si.AddressSpec.Multisig != nil:
+		config := si.AddressSpec.Multisig
+		var weight uint64
+		fmt.Fprintf(w, "     multisig (threshold: %d)\n", config.Threshold)
+		for j, signer := range config.Signers {
+			signed := proof != nil && j < len(proof.Multisig) && proof.Multisig[j] != nil
+			if signed {
+				weight += signer.Weight
+			}
+			fmt.Fprintf(w, "     - %s (weight: %d, signed: %t)\n", signer.PublicKey, signer.Weight, signed)
+		}
+		fmt.Fprintf(w, "     signed weight: %d/%d\n", weight, config.Threshold)
+	}
+}
+
+// signatureAlgorithm returns the name of the signature algorithm of an address specification.
+func signatureAlgorithm(spec *types.SignatureAddressSpec) string {
+	switch {
+	case spec.Ed25519 != nil:
+		return "ed25519"
+	case spec.Secp256k1Eth != nil:
+		return "secp256k1eth"
+	case spec.Sr25519 != nil:
+		return "sr25519"
+	default:
+		return "unknown"
+	}
+}
+
+func init() {
+	txDecodeCmd.Flags().StringVar(&txFile, cfgFile, "", "file containing the transaction (- for standard input)")
+}
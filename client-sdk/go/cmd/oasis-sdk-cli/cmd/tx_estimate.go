@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/core"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+var txEstimateCmd = &cobra.Command{
+	Use:   "estimate [<tx>]",
+	Short: "Estimate the gas and fee of a raw transaction",
+	Long: `Estimate the gas used by a transaction, e.g. one written with --unsigned, and show the minimum
+gas prices of the node along with the resulting fee in the denomination of the fee of the
+transaction. Any transaction the CLI can construct can be estimated by writing it to standard
+output with --unsigned - and reading it with --file -.
+
+If the transaction has no signers yet, the account given by --account is added as its signer.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		rawTx, err := readRawTxArg(args)
+		if err != nil {
+			return err
+		}
+		rc, conn, err := common.RuntimeClient()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		tx := rawTx.Tx
+		if len(tx.AuthInfo.SignerInfo) == 0 {
+			acct, acctErr := common.TxAccount()
+			if acctErr != nil {
+				return fmt.Errorf("transaction has no signers: %w", acctErr)
+			}
+			if err = setSigner(ctx, cmd, rc, tx, acct); err != nil {
+				return err
+			}
+		}
+
+		gas, err := core.NewV1(rc).EstimateGas(ctx, client.RoundLatest, tx)
+		if err != nil {
+			return fmt.Errorf("failed to estimate gas: %w", err)
+		}
+		minGasPrices, err := core.NewV1(rc).MinGasPrice(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to query minimum gas prices: %w", err)
+		}
+		denomination := tx.AuthInfo.Fee.Amount.Denomination
+		fee, err := estimateFee(gas, minGasPrices[denomination])
+		if err != nil {
+			return err
+		}
+
+		if common.OutputJSON() {
+			return common.PrintJSON(cmd.OutOrStdout(), &estimateJSON{
+				Method:          tx.Call.Method,
+				EstimatedGas:    gas,
+				GasLimit:        tx.AuthInfo.Fee.Gas,
+				MinGasPrice:     minGasPrices,
+				EstimatedFee:    *fee,
+				FeeDenomination: denomination,
+			})
+		}
+
+		denominations := make([]types.Denomination, 0, len(minGasPrices))
+		for d := range minGasPrices {
+			denominations = append(denominations, d)
+		}
+		sort.Slice(denominations, func(i, j int) bool {
+			return string(denominations[i]) < string(denominations[j])
+		})
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "Method:\t%s\n", tx.Call.Method)
+		fmt.Fprintf(w, "Estimated gas:\t%d\n", gas)
+		fmt.Fprintf(w, "Gas limit:\t%d\n", tx.AuthInfo.Fee.Gas)
+		for _, d := range denominations {
+			price := minGasPrices[d]
+			fmt.Fprintf(w, "Min gas price (%s):\t%s base units\n", d, price.String())
+		}
+		fmt.Fprintf(w, "Estimated fee:\t%s %s\n", common.FormatAmount(fee, common.Decimals()), denomination)
+		return w.Flush()
+	},
+}
+
+// estimateJSON is the JSON form of a gas and fee estimate, with amounts in base units.
+type estimateJSON struct {
+	Method          string                                `json:"method"`
+	EstimatedGas    uint64                                `json:"estimated_gas"`
+	GasLimit        uint64                                `json:"gas_limit"`
+	MinGasPrice     map[types.Denomination]types.Quantity `json:"min_gas_price"`
+	EstimatedFee    quantity.Quantity                     `json:"estimated_fee"`
+	FeeDenomination types.Denomination                    `json:"fee_denomination"`
+}
+
+// estimateFee returns the fee of the given amount of gas at the given gas price.
+func estimateFee(gas uint64, gasPrice types.Quantity) (*quantity.Quantity, error) {
+	fee := gasPrice.Clone()
+	if err := fee.Mul(quantity.NewFromUint64(gas)); err != nil {
+		return nil, fmt.Errorf("failed to compute fee: %w", err)
+	}
+	return fee, nil
+}
+
+func init() {
+	txEstimateCmd.Flags().StringVar(&txFile, cfgFile, "", "file containing the transaction (- for standard input)")
+}
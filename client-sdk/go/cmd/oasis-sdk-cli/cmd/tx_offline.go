@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd/common"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/wallet"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const (
+	cfgOffline      = "offline"
+	cfgChainContext = "chain-context"
+	cfgNonce        = "nonce"
+	cfgRound        = "round"
+	cfgOutput       = "output"
+	cfgNoWait       = "no-wait"
+)
+
+var (
+	txOffline      bool
+	txChainContext string
+	txNonce        uint64
+	txRound        uint64
+	txOutput       string
+	txNoWait       bool
+
+	txSignCmd = &cobra.Command{
+		Use:   "sign [<tx>]",
+		Short: "Sign a raw transaction",
+		Long: `Sign an unsigned transaction, e.g. one written with --unsigned, with the account given by
+--account, or a Ledger device with --signer ledger, and write the signed transaction to the file
+given by --output.
+
+If the account is not a signer of the transaction yet, it is added as its signer with the nonce
+given by --nonce. An explicit --nonce also replaces the nonce of an existing signer.
+
+With --offline the node is never contacted, e.g. on an air-gapped machine, and the nonce of new
+signers has to be given by --nonce. The chain context of the ParaTime is then given by
+--chain-context or derived from the selected ParaTime profile.
+Otherwise they are queried from the node, the nonce at the round given by --round.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			rawTx, err := readRawTxArg(args)
+			if err != nil {
+				return err
+			}
+			if rawTx.Unverified != nil {
+				return fmt.Errorf("transaction is already signed")
+			}
+			acct, err := common.TxAccount()
+			if err != nil {
+				return err
+			}
+
+			var rc client.RuntimeClient
+			if !txOffline {
+				var conn *grpc.ClientConn
+				if rc, conn, err = common.RuntimeClient(); err != nil {
+					return err
+				}
+				defer conn.Close()
+			}
+
+			tx := rawTx.Tx
+			if err = setSigner(ctx, cmd, rc, tx, acct); err != nil {
+				return err
+			}
+			chainContext, err := signingChainContext(ctx, rc)
+			if err != nil {
+				return err
+			}
+
+			printRawTx(cmd.ErrOrStderr(), &common.RawTx{Tx: tx}, common.Decimals())
+			fmt.Fprintf(cmd.ErrOrStderr(), "Chain context: %s\n", chainContext)
+			if err = common.Confirm("Sign the transaction?"); err != nil {
+				return err
+			}
+
+			signer, err := common.AccountSigner(acct)
+			if err != nil {
+				return err
+			}
+			ts := tx.PrepareForSigning()
+			if err = ts.AppendSign(chainContext, signer); err != nil {
+				return fmt.Errorf("failed to sign transaction: %w", err)
+			}
+			return common.WriteRawTx(txOutput, cmd.OutOrStdout(), cbor.Marshal(ts.UnverifiedTransaction()))
+		},
+	}
+
+	txBroadcastCmd = &cobra.Command{
+		Use:   "broadcast [<tx>]",
+		Short: "Submit a signed raw transaction",
+		Long: `Submit a signed transaction, e.g. one written by tx sign on an offline machine, and wait for
+its execution unless --no-wait is set.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			rawTx, err := readRawTxArg(args)
+			if err != nil {
+				return err
+			}
+			if rawTx.Unverified == nil {
+				return fmt.Errorf("transaction is not signed")
+			}
+			rc, conn, err := common.RuntimeClient()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			return submitSignedTx(ctx, cmd, rc, rawTx)
+		},
+	}
+)
+
+// submitSignedTx submits a signed transaction and, unless --no-wait is set, waits for its
+// execution.
+func submitSignedTx(ctx context.Context, cmd *cobra.Command, rc client.RuntimeClient, rawTx *common.RawTx) error {
+	txHash := hash.NewFromBytes(rawTx.Raw)
+	fmt.Fprintf(cmd.ErrOrStderr(), "Submitting transaction %s...\n", txHash)
+	if txNoWait {
+		return rc.SubmitTxNoWait(ctx, rawTx.Unverified)
+	}
+
+	meta, err := rc.SubmitTxRawMeta(ctx, rawTx.Unverified)
+	if err != nil {
+		return err
+	}
+	if meta.CheckTxError != nil {
+		return fmt.Errorf("transaction check failed (module: %s code: %d): %s",
+			meta.CheckTxError.Module, meta.CheckTxError.Code, meta.CheckTxError.Message)
+	}
+	if !meta.Result.IsSuccess() {
+		return fmt.Errorf("transaction failed in round %d: %w", meta.Round, meta.Result.Failed)
+	}
+	if common.OutputJSON() {
+		return common.PrintJSON(cmd.OutOrStdout(), &txResult{Hash: &txHash, Round: meta.Round})
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Executed in round %d.\n", meta.Round)
+	return nil
+}
+
+// setSigner makes sure the given account is a signer of the transaction, adding it if the
+// transaction has no signers yet, and applies an explicit --nonce.
+func setSigner(ctx context.Context, cmd *cobra.Command, rc client.RuntimeClient, tx *types.Transaction, acct *wallet.Account) error {
+	nonceSet := cmd.Flags().Changed(cfgNonce)
+
+	for i := range tx.AuthInfo.SignerInfo {
+		si := &tx.AuthInfo.SignerInfo[i]
+		addr, err := si.AddressSpec.Address()
+		if err != nil || !addr.Equal(acct.Address) {
+			continue
+		}
+		if nonceSet {
+			si.Nonce = txNonce
+		}
+		return nil
+	}
+	if len(tx.AuthInfo.SignerInfo) > 0 {
+		return fmt.Errorf("account %s is not a signer of the transaction", acct.Name)
+	}
+
+	spec, err := acct.SigSpec()
+	if err != nil {
+		return err
+	}
+	nonce := txNonce
+	switch {
+	case nonceSet:
+	case rc == nil:
+		return fmt.Errorf("no nonce given, use --%s", cfgNonce)
+	default:
+		if nonce, err = accounts.NewV1(rc).Nonce(ctx, txRound, acct.Address); err != nil {
+			return fmt.Errorf("failed to query nonce: %w", err)
+		}
+	}
+	tx.AppendAuthSignature(spec, nonce)
+	return nil
+}
+
+// signingChainContext returns the chain context given by --chain-context or, if the node is
+// connected to, the one of the ParaTime.
+func signingChainContext(ctx context.Context, rc client.RuntimeClient) (signature.Context, error) {
+	switch {
+	case txChainContext != "":
+		return signature.Context(txChainContext), nil
+	case rc == nil:
+		chainContext, err := common.ChainContext()
+		if err != nil {
+			return "", fmt.Errorf("no chain context given, use --%s: %w", cfgChainContext, err)
+		}
+		return chainContext, nil
+	default:
+		info, err := rc.GetInfo(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get ParaTime info: %w", err)
+		}
+		return info.ChainContext, nil
+	}
+}
+
+func init() {
+	txSignCmd.Flags().StringVar(&txFile, cfgFile, "", "file containing the transaction (- for standard input)")
+	txSignCmd.Flags().BoolVar(&txOffline, cfgOffline, false, "do not connect to the node")
+	txSignCmd.Flags().StringVar(&txChainContext, cfgChainContext, "", "chain context of the ParaTime (queried if not given)")
+	txSignCmd.Flags().Uint64Var(&txNonce, cfgNonce, 0, "nonce of the signer (queried if not given)")
+	txSignCmd.Flags().Uint64Var(&txRound, cfgRound, client.RoundLatest, "round at which to query the nonce")
+	txSignCmd.Flags().StringVarP(&txOutput, cfgOutput, "o", "-", "file the signed transaction is written to (- for hex to standard output)")
+
+	txBroadcastCmd.Flags().StringVar(&txFile, cfgFile, "", "file containing the transaction (- for standard input)")
+	txBroadcastCmd.Flags().BoolVar(&txNoWait, cfgNoWait, false, "do not wait for the transaction to be executed")
+}
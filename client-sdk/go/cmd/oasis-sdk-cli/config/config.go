@@ -0,0 +1,259 @@
+// Package config implements the persistent network and ParaTime profiles of the CLI.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+)
+
+const (
+	// Version is the version of the configuration file format.
+	Version = 1
+
+	// FileName is the name of the configuration file within the configuration directory.
+	FileName = "config.json"
+
+	// MaxDecimals is the maximum number of decimals of a denomination.
+	MaxDecimals = 36
+)
+
+var (
+	// ErrNotFound is the error returned when a network or ParaTime does not exist.
+	ErrNotFound = errors.New("config: not found")
+	// ErrExists is the error returned when adding a network or ParaTime that already exists.
+	ErrExists = errors.New("config: already exists")
+
+	nameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9_.-]*$`)
+)
+
+// Denomination is the denomination of tokens in amounts shown and parsed by the CLI.
+type Denomination struct {
+	// Symbol is the symbol of the token, e.g. ROSE.
+	Symbol string `json:"symbol"`
+	// Decimals is the number of decimals of amounts.
+	Decimals uint8 `json:"decimals"`
+}
+
+// Validate checks that the denomination is valid.
+func (d *Denomination) Validate() error {
+	if d.Decimals > MaxDecimals {
+		return fmt.Errorf("config: invalid number of decimals: %d (maximum: %d)", d.Decimals, MaxDecimals)
+	}
+	return nil
+}
+
+// ParaTime is the profile of a ParaTime on a network.
+type ParaTime struct {
+	// ID is the hex-encoded ParaTime identifier.
+	ID string `json:"id"`
+	// Denomination is the native denomination of the ParaTime.
+	Denomination Denomination `json:"denomination"`
+}
+
+// Namespace returns the ParaTime identifier.
+func (pt *ParaTime) Namespace() (common.Namespace, error) {
+	var id common.Namespace
+	if err := id.UnmarshalHex(pt.ID); err != nil {
+		return id, fmt.Errorf("config: malformed ParaTime identifier %s: %w", pt.ID, err)
+	}
+	return id, nil
+}
+
+// Validate checks that the ParaTime profile is valid.
+func (pt *ParaTime) Validate() error {
+	if _, err := pt.Namespace(); err != nil {
+		return err
+	}
+	return pt.Denomination.Validate()
+}
+
+// Network is the profile of a network.
+type Network struct {
+	// Description is a human-readable description of the network.
+	Description string `json:"description,omitempty"`
+	// RPC is the gRPC address of a node of the network.
+	RPC string `json:"rpc"`
+	// ChainContext is the consensus layer chain context of the network.
+	ChainContext string `json:"chain_context"`
+	// Denomination is the denomination of the consensus layer.
+	Denomination Denomination `json:"denomination"`
+	// ParaTimes are the ParaTimes of the network by name.
+	ParaTimes map[string]*ParaTime `json:"paratimes,omitempty"`
+	// DefaultParaTime is the name of the ParaTime used unless another one is selected.
+	DefaultParaTime string `json:"default_paratime,omitempty"`
+	// Faucet is the URL of the faucet service of the network, if it has one.
+	Faucet string `json:"faucet,omitempty"`
+}
+
+// Validate checks that the network profile is valid.
+func (n *Network) Validate() error {
+	if n.RPC == "" {
+		return fmt.Errorf("config: missing RPC address")
+	}
+	if err := n.Denomination.Validate(); err != nil {
+		return err
+	}
+	for name, pt := range n.ParaTimes {
+		if err := ValidateName(name); err != nil {
+			return err
+		}
+		if err := pt.Validate(); err != nil {
+			return fmt.Errorf("config: ParaTime %s: %w", name, err)
+		}
+	}
+	if n.DefaultParaTime != "" && n.ParaTimes[n.DefaultParaTime] == nil {
+		return fmt.Errorf("config: default ParaTime %s: %w", n.DefaultParaTime, ErrNotFound)
+	}
+	return nil
+}
+
+// ParaTime returns the ParaTime with the given name, or the default ParaTime if the name is
+// empty. It returns nil if no name is given and the network has no default ParaTime.
+func (n *Network) ParaTime(name string) (*ParaTime, error) {
+	if name == "" {
+		name = n.DefaultParaTime
+		if name == "" {
+			return nil, nil
+		}
+	}
+	pt := n.ParaTimes[name]
+	if pt == nil {
+		return nil, fmt.Errorf("%w: ParaTime %s", ErrNotFound, name)
+	}
+	return pt, nil
+}
+
+// ParaTimeChainContext returns the chain context of the given ParaTime, used to sign its
+// transactions.
+func (n *Network) ParaTimeChainContext(pt *ParaTime) (signature.Context, error) {
+	if n.ChainContext == "" {
+		return "", fmt.Errorf("config: network has no chain context")
+	}
+	id, err := pt.Namespace()
+	if err != nil {
+		return "", err
+	}
+	return signature.DeriveChainContext(id, n.ChainContext), nil
+}
+
+// SortedParaTimes returns the names of all ParaTimes of the network, sorted.
+func (n *Network) SortedParaTimes() []string {
+	names := make([]string, 0, len(n.ParaTimes))
+	for name := range n.ParaTimes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Config is the configuration of the CLI.
+type Config struct {
+	// Version is the version of the configuration file format.
+	Version uint16 `json:"version"`
+	// Networks are the networks by name.
+	Networks map[string]*Network `json:"networks"`
+	// DefaultNetwork is the name of the network used unless another one is selected.
+	DefaultNetwork string `json:"default_network,omitempty"`
+}
+
+// Validate checks that the configuration is valid.
+func (c *Config) Validate() error {
+	if c.Version != Version {
+		return fmt.Errorf("config: unsupported version %d", c.Version)
+	}
+	for name, n := range c.Networks {
+		if err := ValidateName(name); err != nil {
+			return err
+		}
+		if err := n.Validate(); err != nil {
+			return fmt.Errorf("config: network %s: %w", name, err)
+		}
+	}
+	if c.DefaultNetwork != "" && c.Networks[c.DefaultNetwork] == nil {
+		return fmt.Errorf("config: default network %s: %w", c.DefaultNetwork, ErrNotFound)
+	}
+	return nil
+}
+
+// Network returns the network with the given name, or the default network if the name is empty.
+// It returns nil if no name is given and there is no default network.
+func (c *Config) Network(name string) (*Network, error) {
+	if name == "" {
+		name = c.DefaultNetwork
+		if name == "" {
+			return nil, nil
+		}
+	}
+	n := c.Networks[name]
+	if n == nil {
+		return nil, fmt.Errorf("%w: network %s", ErrNotFound, name)
+	}
+	return n, nil
+}
+
+// SortedNetworks returns the names of all networks, sorted.
+func (c *Config) SortedNetworks() []string {
+	names := make([]string, 0, len(c.Networks))
+	for name := range c.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateName checks that the given network or ParaTime name is valid.
+func ValidateName(name string) error {
+	if !nameRegexp.MatchString(name) {
+		return fmt.Errorf("config: invalid name %q: only letters, digits, '_', '-' and '.' are allowed", name)
+	}
+	return nil
+}
+
+// Load loads the configuration from the given directory. If there is no configuration file yet,
+// the default configuration is returned.
+func Load(dir string) (*Config, error) {
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	switch {
+	case err == nil:
+	case errors.Is(err, os.ErrNotExist):
+		return Default(), nil
+	default:
+		return nil, fmt.Errorf("config: failed to read configuration: %w", err)
+	}
+
+	var cfg Config
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("config: malformed configuration: %w", err)
+	}
+	if err = cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Save validates and writes the configuration to the given directory.
+func (c *Config) Save(dir string) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("config: failed to encode configuration: %w", err)
+	}
+	if err = os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("config: failed to create configuration directory: %w", err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, FileName), append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("config: failed to write configuration: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	cfg, err := Load(dir)
+	require.NoError(err, "Load")
+	require.NoError(cfg.Validate(), "the default configuration should be valid")
+	require.Equal([]string{"mainnet", "testnet"}, cfg.SortedNetworks())
+
+	n, err := cfg.Network("")
+	require.NoError(err, "Network")
+	pt, err := n.ParaTime("")
+	require.NoError(err, "ParaTime")
+	require.EqualValues(18, pt.Denomination.Decimals)
+	_, err = n.ParaTimeChainContext(pt)
+	require.NoError(err, "ParaTimeChainContext")
+	_, err = n.ParaTime("unknown")
+	require.ErrorIs(err, ErrNotFound)
+
+	cfg.Networks["localnet"] = &Network{
+		RPC:          "unix:/tmp/internal.sock",
+		Denomination: Denomination{Decimals: 9},
+		ParaTimes: map[string]*ParaTime{
+			"test": {ID: "8000000000000000000000000000000000000000000000000000000000000000"},
+		},
+		DefaultParaTime: "test",
+	}
+	cfg.DefaultNetwork = "localnet"
+	require.NoError(cfg.Save(dir), "Save")
+
+	loaded, err := Load(dir)
+	require.NoError(err, "Load")
+	require.Equal(cfg, loaded)
+	n, err = loaded.Network("")
+	require.NoError(err, "Network")
+	pt, err = n.ParaTime("")
+	require.NoError(err, "ParaTime")
+	_, err = n.ParaTimeChainContext(pt)
+	require.Error(err, "networks without a chain context should not derive one")
+
+	loaded.Networks["localnet"].DefaultParaTime = "missing"
+	require.Error(loaded.Save(dir), "invalid configurations should not be saved")
+	loaded.Networks["localnet"].DefaultParaTime = ""
+	loaded.Networks["localnet"].ParaTimes["test"].Denomination.Decimals = MaxDecimals + 1
+	require.Error(loaded.Save(dir), "invalid configurations should not be saved")
+}
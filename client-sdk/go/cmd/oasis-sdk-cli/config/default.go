@@ -0,0 +1,41 @@
+package config
+
+// Default returns the default configuration, seeded with the public Oasis networks.
+func Default() *Config {
+	// EVM ParaTimes use 18 decimals like Ethereum.
+	rose := Denomination{Symbol: "ROSE", Decimals: 9}
+	roseEVM := Denomination{Symbol: "ROSE", Decimals: 18}
+	test := Denomination{Symbol: "TEST", Decimals: 9}
+	testEVM := Denomination{Symbol: "TEST", Decimals: 18}
+
+	return &Config{
+		Version: Version,
+		Networks: map[string]*Network{
+			"mainnet": {
+				Description:  "Oasis Mainnet",
+				RPC:          "grpc.oasis.io:443",
+				ChainContext: "bb3d748def55bdfb797a2ac53ee6ee141e54cd2ab2dc2375f4a0703a178e6e55",
+				Denomination: rose,
+				ParaTimes: map[string]*ParaTime{
+					"cipher":   {ID: "000000000000000000000000000000000000000000000000e199119c992377cb", Denomination: rose},
+					"emerald":  {ID: "000000000000000000000000000000000000000000000000e2eaa99fc008f87f", Denomination: roseEVM},
+					"sapphire": {ID: "000000000000000000000000000000000000000000000000f80306c9858e7279", Denomination: roseEVM},
+				},
+				DefaultParaTime: "sapphire",
+			},
+			"testnet": {
+				Description:  "Oasis Testnet",
+				RPC:          "testnet.grpc.oasis.io:443",
+				ChainContext: "0b91b8e4e44b2003a7c5e23ddadb5e14ef5345c0ebcb3ddcae07fa2f244cab76",
+				Denomination: test,
+				ParaTimes: map[string]*ParaTime{
+					"cipher":   {ID: "0000000000000000000000000000000000000000000000000000000000000000", Denomination: test},
+					"emerald":  {ID: "00000000000000000000000000000000000000000000000072c8215e60d5bca7", Denomination: testEVM},
+					"sapphire": {ID: "000000000000000000000000000000000000000000000000a6d1e3ebf60dff6c", Denomination: testEVM},
+				},
+				DefaultParaTime: "sapphire",
+			},
+		},
+		DefaultNetwork: "mainnet",
+	}
+}
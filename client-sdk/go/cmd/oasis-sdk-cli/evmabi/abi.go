@@ -0,0 +1,241 @@
+package evmabi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Argument is an argument or return value of a method.
+type Argument struct {
+	// Name is the name of the argument, possibly empty.
+	Name string
+	// Type is the type of the argument.
+	Type *Type
+}
+
+// Method is a contract function or constructor.
+type Method struct {
+	// Name is the name of the function, empty for constructors.
+	Name string
+	// Inputs are the arguments of the method.
+	Inputs []Argument
+	// Outputs are the return values of the method.
+	Outputs []Argument
+	// StateMutability is the state mutability of the method, e.g. view.
+	StateMutability string
+}
+
+// Signature returns the canonical signature of the method, e.g. transfer(address,uint256).
+func (m *Method) Signature() string {
+	types := make([]string, 0, len(m.Inputs))
+	for _, arg := range m.Inputs {
+		types = append(types, arg.Type.String())
+	}
+	return m.Name + "(" + strings.Join(types, ",") + ")"
+}
+
+// Selector returns the 4-byte function selector of the method.
+func (m *Method) Selector() []byte {
+	h := sha3.NewLegacyKeccak256()
+	_, _ = h.Write([]byte(m.Signature()))
+	return h.Sum(nil)[:4]
+}
+
+// IsReadOnly returns true iff the method does not modify state.
+func (m *Method) IsReadOnly() bool {
+	return m.StateMutability == "view" || m.StateMutability == "pure"
+}
+
+// EncodeArgs parses and encodes the given human-readable arguments of the method.
+func (m *Method) EncodeArgs(args []string) ([]byte, error) {
+	if len(args) != len(m.Inputs) {
+		return nil, fmt.Errorf("evmabi: %s takes %d arguments, got %d", m.describe(), len(m.Inputs), len(args))
+	}
+	types := make([]*Type, 0, len(m.Inputs))
+	values := make([]interface{}, 0, len(m.Inputs))
+	for i, input := range m.Inputs {
+		v, err := ParseValue(input.Type, args[i])
+		if err != nil {
+			return nil, fmt.Errorf("evmabi: argument %d (%s): %w", i, input.Type, err)
+		}
+		types = append(types, input.Type)
+		values = append(values, v)
+	}
+	return Encode(types, values)
+}
+
+// EncodeCall encodes a call of the method with the given human-readable arguments, including
+// the function selector.
+func (m *Method) EncodeCall(args []string) ([]byte, error) {
+	data, err := m.EncodeArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	return append(m.Selector(), data...), nil
+}
+
+// DecodeOutputs decodes the given return data of the method.
+func (m *Method) DecodeOutputs(data []byte) ([]interface{}, error) {
+	types := make([]*Type, 0, len(m.Outputs))
+	for _, output := range m.Outputs {
+		types = append(types, output.Type)
+	}
+	return Decode(types, data)
+}
+
+func (m *Method) describe() string {
+	if m.Name == "" {
+		return "constructor"
+	}
+	return m.Signature()
+}
+
+// ABI is a contract ABI.
+type ABI struct {
+	// Constructor is the constructor of the contract, if any.
+	Constructor *Method
+	// Methods are the functions of the contract.
+	Methods []*Method
+}
+
+type jsonArgument struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type jsonEntry struct {
+	Type            string         `json:"type"`
+	Name            string         `json:"name"`
+	Inputs          []jsonArgument `json:"inputs"`
+	Outputs         []jsonArgument `json:"outputs"`
+	StateMutability string         `json:"stateMutability"`
+}
+
+// ParseABI parses a JSON contract ABI definition, as produced by the Solidity compiler. Entries
+// other than functions and the constructor are ignored.
+func ParseABI(data []byte) (*ABI, error) {
+	var entries []jsonEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("evmabi: malformed ABI: %w", err)
+	}
+
+	var abi ABI
+	for _, entry := range entries {
+		switch entry.Type {
+		case "function", "":
+		case "constructor":
+		default:
+			continue
+		}
+
+		m := &Method{StateMutability: entry.StateMutability}
+		var err error
+		if m.Inputs, err = parseArguments(entry.Inputs); err != nil {
+			return nil, fmt.Errorf("evmabi: %s: %w", entry.Name, err)
+		}
+		if m.Outputs, err = parseArguments(entry.Outputs); err != nil {
+			return nil, fmt.Errorf("evmabi: %s: %w", entry.Name, err)
+		}
+		if entry.Type == "constructor" {
+			abi.Constructor = m
+			continue
+		}
+		m.Name = entry.Name
+		abi.Methods = append(abi.Methods, m)
+	}
+	return &abi, nil
+}
+
+func parseArguments(args []jsonArgument) ([]Argument, error) {
+	parsed := make([]Argument, 0, len(args))
+	for _, arg := range args {
+		t, err := ParseType(arg.Type)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, Argument{Name: arg.Name, Type: t})
+	}
+	return parsed, nil
+}
+
+// Method returns the function with the given name or, for overloaded functions, the given
+// signature, e.g. transfer(address,uint256).
+func (abi *ABI) Method(nameOrSignature string) (*Method, error) {
+	var candidates []*Method
+	for _, m := range abi.Methods {
+		if m.Signature() == nameOrSignature {
+			return m, nil
+		}
+		if m.Name == nameOrSignature {
+			candidates = append(candidates, m)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return nil, fmt.Errorf("evmabi: unknown method: %s", nameOrSignature)
+	case 1:
+		return candidates[0], nil
+	default:
+		return nil, fmt.Errorf("evmabi: method %s is overloaded, use its signature", nameOrSignature)
+	}
+}
+
+// ParseMethodSignature parses a method given by its human-readable signature, e.g.
+// "balanceOf(address) returns (uint256)", for calls without a JSON ABI definition.
+func ParseMethodSignature(signature string) (*Method, error) {
+	signature = strings.TrimSpace(signature)
+	var outputs string
+	if i := strings.Index(signature, " returns "); i >= 0 {
+		signature, outputs = strings.TrimSpace(signature[:i]), strings.TrimSpace(signature[i+len(" returns "):])
+	}
+
+	name, inputs, err := splitSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	m := &Method{Name: name}
+	if m.Inputs, err = parseTypeList(inputs); err != nil {
+		return nil, err
+	}
+	if outputs != "" {
+		var empty string
+		if empty, outputs, err = splitSignature(outputs); err != nil || empty != "" {
+			return nil, fmt.Errorf("evmabi: malformed return types: %s", outputs)
+		}
+		if m.Outputs, err = parseTypeList(outputs); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func splitSignature(s string) (string, string, error) {
+	open := strings.IndexByte(s, '(')
+	if open < 0 || !strings.HasSuffix(s, ")") {
+		return "", "", fmt.Errorf("evmabi: malformed signature: %s", s)
+	}
+	return strings.TrimSpace(s[:open]), s[open+1 : len(s)-1], nil
+}
+
+func parseTypeList(s string) ([]Argument, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var args []Argument
+	for _, name := range strings.Split(s, ",") {
+		// Allow named parameters, e.g. "address owner".
+		fields := strings.Fields(name)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("evmabi: malformed type list: %s", s)
+		}
+		t, err := ParseType(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, Argument{Type: t})
+	}
+	return args, nil
+}
@@ -0,0 +1,335 @@
+package evmabi
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// AddressSize is the size of an Ethereum address.
+const AddressSize = 20
+
+var (
+	maxWordValue = new(big.Int).Lsh(big.NewInt(1), 256)
+	maxInputSize = uint64(1 << 32)
+)
+
+// ParseValue parses a human-readable value of the given type.
+//
+// Integers are given in decimal or 0x-prefixed hexadecimal notation, addresses and byte arrays in
+// hexadecimal notation and arrays as JSON arrays, e.g. ["0x01", "0x02"] or [1, 2].
+//
+// The returned value is a *big.Int for integers, a []byte for addresses and byte arrays, a bool,
+// a string or a []interface{} for arrays.
+func ParseValue(t *Type, s string) (interface{}, error) {
+	switch t.Kind {
+	case KindUint, KindInt:
+		v, ok := new(big.Int).SetString(strings.TrimSpace(s), 0)
+		if !ok {
+			return nil, fmt.Errorf("malformed integer: %q", s)
+		}
+		if err := checkIntRange(t, v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	case KindAddress:
+		data, err := parseHex(s)
+		if err != nil || len(data) != AddressSize {
+			return nil, fmt.Errorf("malformed address: %q", s)
+		}
+		return data, nil
+	case KindBool:
+		return strconv.ParseBool(strings.TrimSpace(s))
+	case KindFixedBytes:
+		data, err := parseHex(s)
+		if err != nil || len(data) != t.Size {
+			return nil, fmt.Errorf("malformed %s: %q", t, s)
+		}
+		return data, nil
+	case KindBytes:
+		data, err := parseHex(s)
+		if err != nil {
+			return nil, fmt.Errorf("malformed bytes: %q", s)
+		}
+		return data, nil
+	case KindString:
+		return s, nil
+	default:
+		var elems []json.RawMessage
+		if err := json.Unmarshal([]byte(s), &elems); err != nil {
+			return nil, fmt.Errorf("malformed array: %q", s)
+		}
+		if t.Kind == KindArray && len(elems) != t.Length {
+			return nil, fmt.Errorf("malformed %s: expected %d elements, got %d", t, t.Length, len(elems))
+		}
+		values := make([]interface{}, 0, len(elems))
+		for _, elem := range elems {
+			raw := strings.TrimSpace(string(elem))
+			var str string
+			if json.Unmarshal(elem, &str) == nil {
+				raw = str
+			}
+			v, err := ParseValue(t.Elem, raw)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	}
+}
+
+func parseHex(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return nil, fmt.Errorf("missing 0x prefix")
+	}
+	return hex.DecodeString(s[2:])
+}
+
+func checkIntRange(t *Type, v *big.Int) error {
+	bits := uint(t.Size)
+	if t.Kind == KindUint {
+		if v.Sign() < 0 || v.BitLen() > int(bits) {
+			return fmt.Errorf("value %s out of range for %s", v, t)
+		}
+		return nil
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), bits-1)
+	if v.Cmp(limit) >= 0 || v.Cmp(new(big.Int).Neg(limit)) < 0 {
+		return fmt.Errorf("value %s out of range for %s", v, t)
+	}
+	return nil
+}
+
+// Encode encodes the given values of the given types, e.g. as the arguments of a call.
+func Encode(types []*Type, values []interface{}) ([]byte, error) {
+	if len(types) != len(values) {
+		return nil, fmt.Errorf("evmabi: expected %d values, got %d", len(types), len(values))
+	}
+
+	var headSize int
+	for _, t := range types {
+		headSize += t.headSize()
+	}
+	head := make([]byte, 0, headSize)
+	var tail []byte
+	for i, t := range types {
+		enc, err := encodeValue(t, values[i])
+		if err != nil {
+			return nil, err
+		}
+		if t.IsDynamic() {
+			head = append(head, uintWord(uint64(headSize+len(tail)))...)
+			tail = append(tail, enc...)
+			continue
+		}
+		head = append(head, enc...)
+	}
+	return append(head, tail...), nil
+}
+
+func encodeValue(t *Type, v interface{}) ([]byte, error) {
+	switch t.Kind {
+	case KindUint, KindInt:
+		n, ok := v.(*big.Int)
+		if !ok {
+			return nil, fmt.Errorf("evmabi: expected *big.Int for %s, got %T", t, v)
+		}
+		if err := checkIntRange(t, n); err != nil {
+			return nil, fmt.Errorf("evmabi: %w", err)
+		}
+		if n.Sign() < 0 {
+			n = new(big.Int).Add(maxWordValue, n)
+		}
+		word := make([]byte, wordSize)
+		return n.FillBytes(word), nil
+	case KindBool:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("evmabi: expected bool for %s, got %T", t, v)
+		}
+		if b {
+			return uintWord(1), nil
+		}
+		return uintWord(0), nil
+	case KindAddress, KindFixedBytes, KindBytes:
+		data, ok := v.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("evmabi: expected []byte for %s, got %T", t, v)
+		}
+		switch t.Kind {
+		case KindAddress:
+			if len(data) != AddressSize {
+				return nil, fmt.Errorf("evmabi: malformed address")
+			}
+			word := make([]byte, wordSize)
+			copy(word[wordSize-AddressSize:], data)
+			return word, nil
+		case KindFixedBytes:
+			if len(data) != t.Size {
+				return nil, fmt.Errorf("evmabi: malformed %s", t)
+			}
+			return padRight(data), nil
+		default:
+			return append(uintWord(uint64(len(data))), padRight(data)...), nil
+		}
+	case KindString:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("evmabi: expected string for %s, got %T", t, v)
+		}
+		return append(uintWord(uint64(len(s))), padRight([]byte(s))...), nil
+	default:
+		elems, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("evmabi: expected []interface{} for %s, got %T", t, v)
+		}
+		if t.Kind == KindArray && len(elems) != t.Length {
+			return nil, fmt.Errorf("evmabi: expected %d elements for %s, got %d", t.Length, t, len(elems))
+		}
+		enc, err := Encode(repeatType(t.Elem, len(elems)), elems)
+		if err != nil {
+			return nil, err
+		}
+		if t.Kind == KindSlice {
+			enc = append(uintWord(uint64(len(elems))), enc...)
+		}
+		return enc, nil
+	}
+}
+
+// Decode decodes values of the given types, e.g. the return data of a call.
+func Decode(types []*Type, data []byte) ([]interface{}, error) {
+	values := make([]interface{}, 0, len(types))
+	var offset uint64
+	for _, t := range types {
+		var (
+			v   interface{}
+			err error
+		)
+		if t.IsDynamic() {
+			var ptr uint64
+			if ptr, err = readUint(data, offset); err != nil {
+				return nil, err
+			}
+			v, err = decodeValue(t, data[ptr:])
+			offset += wordSize
+		} else {
+			if offset > uint64(len(data)) {
+				return nil, fmt.Errorf("evmabi: data too short")
+			}
+			v, err = decodeValue(t, data[offset:])
+			offset += uint64(t.headSize())
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func decodeValue(t *Type, data []byte) (interface{}, error) {
+	switch t.Kind {
+	case KindSlice:
+		n, err := readUint(data, 0)
+		if err != nil {
+			return nil, err
+		}
+		// Every element takes at least one word.
+		if n > uint64(len(data))/wordSize {
+			return nil, fmt.Errorf("evmabi: malformed array length")
+		}
+		return Decode(repeatType(t.Elem, int(n)), data[wordSize:])
+	case KindArray:
+		return Decode(repeatType(t.Elem, t.Length), data)
+	case KindBytes, KindString:
+		n, err := readUint(data, 0)
+		if err != nil {
+			return nil, err
+		}
+		if n > uint64(len(data))-wordSize {
+			return nil, fmt.Errorf("evmabi: data too short")
+		}
+		raw := append([]byte{}, data[wordSize:wordSize+n]...)
+		if t.Kind == KindString {
+			return string(raw), nil
+		}
+		return raw, nil
+	}
+
+	if len(data) < wordSize {
+		return nil, fmt.Errorf("evmabi: data too short")
+	}
+	word := data[:wordSize]
+	switch t.Kind {
+	case KindUint:
+		return new(big.Int).SetBytes(word), nil
+	case KindInt:
+		v := new(big.Int).SetBytes(word)
+		if word[0]&0x80 != 0 {
+			v.Sub(v, maxWordValue)
+		}
+		return v, nil
+	case KindAddress:
+		return append([]byte{}, word[wordSize-AddressSize:]...), nil
+	case KindBool:
+		return word[wordSize-1] != 0, nil
+	default:
+		return append([]byte{}, word[:t.Size]...), nil
+	}
+}
+
+// FormatValue formats a value of the given type, as returned by Decode, in a human-readable way.
+func FormatValue(t *Type, v interface{}) string {
+	switch t.Kind {
+	case KindUint, KindInt:
+		return v.(*big.Int).String()
+	case KindBool:
+		return strconv.FormatBool(v.(bool))
+	case KindAddress, KindFixedBytes, KindBytes:
+		return "0x" + hex.EncodeToString(v.([]byte))
+	case KindString:
+		return strconv.Quote(v.(string))
+	default:
+		elems := v.([]interface{})
+		formatted := make([]string, 0, len(elems))
+		for _, elem := range elems {
+			formatted = append(formatted, FormatValue(t.Elem, elem))
+		}
+		return "[" + strings.Join(formatted, ", ") + "]"
+	}
+}
+
+func readUint(data []byte, offset uint64) (uint64, error) {
+	if offset > uint64(len(data)) || uint64(len(data))-offset < wordSize {
+		return 0, fmt.Errorf("evmabi: data too short")
+	}
+	v := new(big.Int).SetBytes(data[offset : offset+wordSize])
+	if !v.IsUint64() || v.Uint64() > maxInputSize || v.Uint64() > uint64(len(data)) {
+		return 0, fmt.Errorf("evmabi: malformed offset or length")
+	}
+	return v.Uint64(), nil
+}
+
+func uintWord(v uint64) []byte {
+	return new(big.Int).SetUint64(v).FillBytes(make([]byte, wordSize))
+}
+
+func padRight(data []byte) []byte {
+	padded := make([]byte, (len(data)+wordSize-1)/wordSize*wordSize)
+	copy(padded, data)
+	return padded
+}
+
+func repeatType(t *Type, n int) []*Type {
+	types := make([]*Type, n)
+	for i := range types {
+		types[i] = t
+	}
+	return types
+}
@@ -0,0 +1,109 @@
+package evmabi
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testABI = `[
+  {"type": "constructor", "inputs": [{"name": "initial", "type": "uint256"}]},
+  {"type": "function", "name": "balanceOf", "stateMutability": "view",
+   "inputs": [{"name": "owner", "type": "address"}], "outputs": [{"name": "", "type": "uint256"}]},
+  {"type": "function", "name": "transfer", "stateMutability": "nonpayable",
+   "inputs": [{"name": "to", "type": "address"}, {"name": "amount", "type": "uint256"}],
+   "outputs": [{"name": "", "type": "bool"}]},
+  {"type": "event", "name": "Transfer", "inputs": []}
+]`
+
+func TestABI(t *testing.T) {
+	require := require.New(t)
+
+	abi, err := ParseABI([]byte(testABI))
+	require.NoError(err, "ParseABI")
+	require.NotNil(abi.Constructor)
+	require.Len(abi.Methods, 2)
+
+	m, err := abi.Method("transfer")
+	require.NoError(err, "Method")
+	require.Equal("transfer(address,uint256)", m.Signature())
+	require.Equal("a9059cbb", hex.EncodeToString(m.Selector()))
+	require.False(m.IsReadOnly())
+
+	m, err = abi.Method("balanceOf(address)")
+	require.NoError(err, "Method")
+	require.True(m.IsReadOnly())
+	require.Equal("70a08231", hex.EncodeToString(m.Selector()))
+
+	_, err = abi.Method("approve")
+	require.Error(err, "unknown methods should be rejected")
+
+	m, err = ParseMethodSignature("balanceOf(address owner) returns (uint256)")
+	require.NoError(err, "ParseMethodSignature")
+	require.Equal("balanceOf(address)", m.Signature())
+	require.Len(m.Outputs, 1)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	require := require.New(t)
+
+	// Example from the Solidity ABI specification.
+	m, err := ParseMethodSignature("sam(bytes,bool,uint256[])")
+	require.NoError(err, "ParseMethodSignature")
+	data, err := m.EncodeCall([]string{"0x64617665", "true", "[1, 2, 3]"})
+	require.NoError(err, "EncodeCall")
+	expected := "a5643bf2" +
+		"0000000000000000000000000000000000000000000000000000000000000060" +
+		"0000000000000000000000000000000000000000000000000000000000000001" +
+		"00000000000000000000000000000000000000000000000000000000000000a0" +
+		"0000000000000000000000000000000000000000000000000000000000000004" +
+		"6461766500000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000003" +
+		"0000000000000000000000000000000000000000000000000000000000000001" +
+		"0000000000000000000000000000000000000000000000000000000000000002" +
+		"0000000000000000000000000000000000000000000000000000000000000003"
+	require.Equal(expected, hex.EncodeToString(data))
+
+	m.Outputs = m.Inputs
+	values, err := m.DecodeOutputs(data[4:])
+	require.NoError(err, "DecodeOutputs")
+	require.Equal([]byte("dave"), values[0])
+	require.Equal(true, values[1])
+	require.Equal("[1, 2, 3]", FormatValue(m.Inputs[2].Type, values[2]))
+
+	// Signed integers, strings and fixed-size arrays.
+	m, err = ParseMethodSignature("f(int8,string,address[2])")
+	require.NoError(err, "ParseMethodSignature")
+	data, err = m.EncodeArgs([]string{"-2", "héllo", `["0x` + strings.Repeat("11", 20) + `", "0x` + strings.Repeat("22", 20) + `"]`})
+	require.NoError(err, "EncodeArgs")
+	m.Outputs = m.Inputs
+	values, err = m.DecodeOutputs(data)
+	require.NoError(err, "DecodeOutputs")
+	require.Equal(big.NewInt(-2), values[0])
+	require.Equal("héllo", values[1])
+	require.Equal("[0x"+strings.Repeat("11", 20)+", 0x"+strings.Repeat("22", 20)+"]", FormatValue(m.Inputs[2].Type, values[2]))
+
+	for _, tc := range []struct {
+		typ   string
+		value string
+	}{
+		{"uint8", "256"},
+		{"uint256", "-1"},
+		{"int8", "128"},
+		{"address", "0x1234"},
+		{"bytes2", "0x010203"},
+		{"bool", "maybe"},
+		{"uint8[2]", "[1]"},
+	} {
+		typ, err := ParseType(tc.typ)
+		require.NoError(err, "ParseType(%s)", tc.typ)
+		_, err = ParseValue(typ, tc.value)
+		require.Error(err, "ParseValue(%s, %s) should fail", tc.typ, tc.value)
+	}
+
+	_, err = Decode([]*Type{m.Inputs[1].Type}, []byte{0x01})
+	require.Error(err, "truncated data should be rejected")
+}
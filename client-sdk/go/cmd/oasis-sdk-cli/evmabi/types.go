@@ -0,0 +1,140 @@
+// Package evmabi implements the subset of the Solidity contract ABI used by the CLI: parsing JSON
+// ABI definitions and encoding and decoding arguments of elementary types and arrays thereof.
+//
+// Tuples are not supported.
+package evmabi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// wordSize is the size of a single ABI word.
+const wordSize = 32
+
+// Kind is the kind of an ABI type.
+type Kind uint8
+
+const (
+	// KindUint is an unsigned integer type, uint<M>.
+	KindUint Kind = iota
+	// KindInt is a signed integer type, int<M>.
+	KindInt
+	// KindAddress is the address type.
+	KindAddress
+	// KindBool is the bool type.
+	KindBool
+	// KindFixedBytes is a fixed-size byte array type, bytes<M>.
+	KindFixedBytes
+	// KindBytes is the dynamic-size byte array type.
+	KindBytes
+	// KindString is the string type.
+	KindString
+	// KindSlice is a dynamic-size array type, T[].
+	KindSlice
+	// KindArray is a fixed-size array type, T[k].
+	KindArray
+)
+
+// Type is an ABI type.
+type Type struct {
+	// Kind is the kind of the type.
+	Kind Kind
+	// Size is the size in bits of integer types and in bytes of fixed-size byte array types.
+	Size int
+	// Elem is the element type of array types.
+	Elem *Type
+	// Length is the length of fixed-size array types.
+	Length int
+
+	name string
+}
+
+// String returns the canonical name of the type.
+func (t *Type) String() string {
+	return t.name
+}
+
+// IsDynamic returns true iff the type is dynamically encoded.
+func (t *Type) IsDynamic() bool {
+	switch t.Kind {
+	case KindBytes, KindString, KindSlice:
+		return true
+	case KindArray:
+		return t.Elem.IsDynamic()
+	default:
+		return false
+	}
+}
+
+// headSize returns the size of the type in the head of an encoding.
+func (t *Type) headSize() int {
+	if t.Kind == KindArray && !t.IsDynamic() {
+		return t.Length * t.Elem.headSize()
+	}
+	return wordSize
+}
+
+// ParseType parses the given ABI type name.
+func ParseType(name string) (*Type, error) {
+	name = strings.TrimSpace(name)
+
+	// Arrays.
+	if strings.HasSuffix(name, "]") {
+		open := strings.LastIndexByte(name, '[')
+		if open < 0 {
+			return nil, fmt.Errorf("evmabi: malformed type: %s", name)
+		}
+		elem, err := ParseType(name[:open])
+		if err != nil {
+			return nil, err
+		}
+		rawLength := name[open+1 : len(name)-1]
+		if rawLength == "" {
+			return &Type{Kind: KindSlice, Elem: elem, name: elem.name + "[]"}, nil
+		}
+		length, err := strconv.Atoi(rawLength)
+		if err != nil || length <= 0 {
+			return nil, fmt.Errorf("evmabi: malformed array length: %s", name)
+		}
+		return &Type{Kind: KindArray, Elem: elem, Length: length, name: fmt.Sprintf("%s[%d]", elem.name, length)}, nil
+	}
+
+	switch {
+	case name == "address":
+		return &Type{Kind: KindAddress, name: name}, nil
+	case name == "bool":
+		return &Type{Kind: KindBool, name: name}, nil
+	case name == "bytes":
+		return &Type{Kind: KindBytes, name: name}, nil
+	case name == "string":
+		return &Type{Kind: KindString, name: name}, nil
+	case strings.HasPrefix(name, "uint"):
+		return parseIntType(KindUint, "uint", name)
+	case strings.HasPrefix(name, "int"):
+		return parseIntType(KindInt, "int", name)
+	case strings.HasPrefix(name, "bytes"):
+		size, err := strconv.Atoi(strings.TrimPrefix(name, "bytes"))
+		if err != nil || size < 1 || size > wordSize {
+			return nil, fmt.Errorf("evmabi: malformed type: %s", name)
+		}
+		return &Type{Kind: KindFixedBytes, Size: size, name: name}, nil
+	case strings.HasPrefix(name, "tuple") || strings.HasPrefix(name, "("):
+		return nil, fmt.Errorf("evmabi: tuples are not supported")
+	default:
+		return nil, fmt.Errorf("evmabi: unsupported type: %s", name)
+	}
+}
+
+func parseIntType(kind Kind, prefix, name string) (*Type, error) {
+	rawSize := strings.TrimPrefix(name, prefix)
+	if rawSize == "" {
+		return &Type{Kind: kind, Size: 256, name: prefix + "256"}, nil
+	}
+	size, err := strconv.Atoi(rawSize)
+	if err != nil || size < 8 || size > 256 || size%8 != 0 {
+		return nil, fmt.Errorf("evmabi: malformed type: %s", name)
+	}
+	return &Type{Kind: kind, Size: size, name: name}, nil
+}
@@ -0,0 +1,11 @@
+// Command oasis-sdk-cli is a command-line wallet and client for ParaTimes built with the Oasis
+// Runtime SDK.
+package main
+
+import (
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/cmd/oasis-sdk-cli/cmd"
+)
+
+func main() {
+	cmd.Execute()
+}
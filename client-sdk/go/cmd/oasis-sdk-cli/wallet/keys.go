@@ -0,0 +1,157 @@
+// Package wallet implements key derivation and the encrypted keystore of the CLI.
+package wallet
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	"golang.org/x/crypto/sha3"
+
+	memorySigner "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/memory"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/secp256k1"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const (
+	// SecretSize is the size of the secret of all supported algorithms.
+	SecretSize = 32
+	// EthereumAddressSize is the size of an Ethereum address.
+	EthereumAddressSize = 20
+)
+
+// Algorithm is a signature algorithm of an account key.
+type Algorithm string
+
+const (
+	// AlgorithmEd25519 is the Ed25519 signature algorithm. The secret is the 32-byte seed.
+	AlgorithmEd25519 Algorithm = "ed25519"
+	// AlgorithmSecp256k1 is the Secp256k1 signature algorithm using Ethereum-compatible
+	// addresses. The secret is the 32-byte private key.
+	AlgorithmSecp256k1 Algorithm = "secp256k1"
+)
+
+// Algorithms are all supported signature algorithms.
+var Algorithms = []Algorithm{AlgorithmEd25519, AlgorithmSecp256k1}
+
+// ParseAlgorithm parses the given algorithm name.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	for _, alg := range Algorithms {
+		if string(alg) == name {
+			return alg, nil
+		}
+	}
+	return "", fmt.Errorf("wallet: unsupported algorithm: %s", name)
+}
+
+// GenerateSecret generates a new random secret for the given algorithm.
+func GenerateSecret(alg Algorithm) ([]byte, error) {
+	for {
+		secret := make([]byte, SecretSize)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("wallet: failed to generate secret: %w", err)
+		}
+		if err := ValidateSecret(alg, secret); err == nil {
+			return secret, nil
+		}
+	}
+}
+
+// ValidateSecret checks that the given secret is a valid secret of the given algorithm.
+func ValidateSecret(alg Algorithm, secret []byte) error {
+	if len(secret) != SecretSize {
+		return fmt.Errorf("wallet: malformed secret: expected %d bytes, got %d", SecretSize, len(secret))
+	}
+	switch alg {
+	case AlgorithmEd25519:
+		return nil
+	case AlgorithmSecp256k1:
+		k := new(big.Int).SetBytes(secret)
+		if k.Sign() == 0 || k.Cmp(btcec.S256().N) >= 0 {
+			return fmt.Errorf("wallet: secp256k1 private key out of range")
+		}
+		return nil
+	default:
+		return fmt.Errorf("wallet: unsupported algorithm: %s", alg)
+	}
+}
+
+// NewSigner creates a signer for the given algorithm and secret.
+func NewSigner(alg Algorithm, secret []byte) (signature.Signer, error) {
+	if err := ValidateSecret(alg, secret); err != nil {
+		return nil, err
+	}
+	switch alg {
+	case AlgorithmEd25519:
+		signer, err := memorySigner.NewFromSeed(secret)
+		if err != nil {
+			return nil, fmt.Errorf("wallet: failed to create ed25519 signer: %w", err)
+		}
+		return ed25519.WrapSigner(signer), nil
+	default:
+		return secp256k1.NewSigner(secret), nil
+	}
+}
+
+// ParsePublicKey parses a public key given as <algorithm>:<base64-encoded key>, e.g.
+// ed25519:NcPzNW3YU2T+ugNUtUWtoQnRvbOL9dYSaBfbjHLP1pE=.
+func ParsePublicKey(s string) (signature.PublicKey, error) {
+	i := strings.IndexByte(s, ':')
+	if i < 0 {
+		return nil, fmt.Errorf("wallet: malformed public key: missing algorithm")
+	}
+	alg, err := ParseAlgorithm(s[:i])
+	if err != nil {
+		return nil, err
+	}
+	switch alg {
+	case AlgorithmEd25519:
+		var pk ed25519.PublicKey
+		if err = pk.UnmarshalText([]byte(s[i+1:])); err != nil {
+			return nil, fmt.Errorf("wallet: malformed public key: %w", err)
+		}
+		return pk, nil
+	default:
+		var pk secp256k1.PublicKey
+		if err = pk.UnmarshalText([]byte(s[i+1:])); err != nil {
+			return nil, fmt.Errorf("wallet: malformed public key: %w", err)
+		}
+		return pk, nil
+	}
+}
+
+// SigSpec returns the signature address specification of the given public key.
+func SigSpec(pk signature.PublicKey) (types.SignatureAddressSpec, error) {
+	switch pk := pk.(type) {
+	case ed25519.PublicKey:
+		return types.NewSignatureAddressSpecEd25519(pk), nil
+	case secp256k1.PublicKey:
+		return types.NewSignatureAddressSpecSecp256k1Eth(pk), nil
+	default:
+		return types.SignatureAddressSpec{}, fmt.Errorf("wallet: unsupported public key type: %T", pk)
+	}
+}
+
+// EthereumAddress returns the Ethereum address of the given Secp256k1 public key.
+func EthereumAddress(pk secp256k1.PublicKey) ([]byte, error) {
+	untagged, err := pk.MarshalBinaryUncompressedUntagged()
+	if err != nil {
+		return nil, fmt.Errorf("wallet: malformed secp256k1 public key: %w", err)
+	}
+	h := sha3.NewLegacyKeccak256()
+	_, _ = h.Write(untagged)
+	return h.Sum(nil)[32-EthereumAddressSize:], nil
+}
+
+// AddressFromEthereum returns the address of the account with the given Ethereum address.
+func AddressFromEthereum(ethAddr []byte) (types.Address, error) {
+	if len(ethAddr) != EthereumAddressSize {
+		return types.Address{}, fmt.Errorf("wallet: malformed Ethereum address: expected %d bytes, got %d", EthereumAddressSize, len(ethAddr))
+	}
+	return types.NewAddressRaw(types.AddressV0Secp256k1EthContext, ethAddr), nil
+}
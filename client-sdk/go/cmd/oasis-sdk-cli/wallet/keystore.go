@@ -0,0 +1,456 @@
+package wallet
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/secp256k1"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const (
+	// KeystoreVersion is the version of the keystore file format.
+	KeystoreVersion = 1
+
+	kdfArgon2id            = "argon2id"
+	cipherChaCha20Poly1305 = "chacha20-poly1305"
+
+	accountFileSuffix = ".json"
+	retiredDir        = "retired"
+	saltSize          = 16
+)
+
+var (
+	// ErrNotFound is the error returned when an account does not exist.
+	ErrNotFound = errors.New("wallet: account not found")
+	// ErrExists is the error returned when creating an account that already exists.
+	ErrExists = errors.New("wallet: account already exists")
+	// ErrWrongPassphrase is the error returned when an account cannot be decrypted.
+	ErrWrongPassphrase = errors.New("wallet: wrong passphrase")
+
+	accountNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9_.-]*$`)
+
+	// DefaultKDFParams are the key derivation parameters used for new accounts.
+	DefaultKDFParams = KDFParams{
+		Time:    3,
+		Memory:  64 * 1024,
+		Threads: 4,
+	}
+)
+
+// KDFParams are the Argon2id parameters used to derive the encryption key from the passphrase.
+type KDFParams struct {
+	// Salt is the random salt.
+	Salt []byte `json:"salt"`
+	// Time is the number of passes over the memory.
+	Time uint32 `json:"time"`
+	// Memory is the size of the memory in KiB.
+	Memory uint32 `json:"memory"`
+	// Threads is the number of threads.
+	Threads uint8 `json:"threads"`
+}
+
+// Crypto is the encrypted secret of an account.
+type Crypto struct {
+	// KDF is the key derivation function, always argon2id.
+	KDF string `json:"kdf"`
+	// KDFParams are the parameters of the key derivation function.
+	KDFParams KDFParams `json:"kdf_params"`
+	// Cipher is the authenticated encryption scheme, always chacha20-poly1305.
+	Cipher string `json:"cipher"`
+	// Nonce is the nonce used for encryption.
+	Nonce []byte `json:"nonce"`
+	// Ciphertext is the encrypted secret.
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Account is an account stored in the keystore. Everything but the secret is stored in plain text,
+// so that accounts can be listed without the passphrase.
+type Account struct {
+	// Version is the version of the keystore file format.
+	Version uint16 `json:"version"`
+	// Name is the unique name of the account.
+	Name string `json:"name"`
+	// Algorithm is the signature algorithm of the account key.
+	Algorithm Algorithm `json:"algorithm"`
+	// Address is the address of the account.
+	Address types.Address `json:"address"`
+	// PublicKey is the public key of the account.
+	PublicKey []byte `json:"public_key"`
+	// Path is the derivation path in case the key was derived from a mnemonic.
+	Path string `json:"path,omitempty"`
+	// Created is when the account was created.
+	Created time.Time `json:"created"`
+	// Crypto is the encrypted secret.
+	Crypto Crypto `json:"crypto"`
+}
+
+// PublicKeyValue returns the public key of the account.
+func (a *Account) PublicKeyValue() (signature.PublicKey, error) {
+	switch a.Algorithm {
+	case AlgorithmEd25519:
+		var pk ed25519.PublicKey
+		if err := pk.UnmarshalBinary(a.PublicKey); err != nil {
+			return nil, fmt.Errorf("wallet: malformed public key: %w", err)
+		}
+		return pk, nil
+	case AlgorithmSecp256k1:
+		var pk secp256k1.PublicKey
+		if err := pk.UnmarshalBinary(a.PublicKey); err != nil {
+			return nil, fmt.Errorf("wallet: malformed public key: %w", err)
+		}
+		return pk, nil
+	default:
+		return nil, fmt.Errorf("wallet: unsupported algorithm: %s", a.Algorithm)
+	}
+}
+
+// SigSpec returns the signature address specification of the account.
+func (a *Account) SigSpec() (types.SignatureAddressSpec, error) {
+	pk, err := a.PublicKeyValue()
+	if err != nil {
+		return types.SignatureAddressSpec{}, err
+	}
+	return SigSpec(pk)
+}
+
+// EthereumAddress returns the Ethereum address of a Secp256k1 account.
+func (a *Account) EthereumAddress() ([]byte, error) {
+	pk, err := a.PublicKeyValue()
+	if err != nil {
+		return nil, err
+	}
+	secpPk, ok := pk.(secp256k1.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("wallet: account %s has no Ethereum address", a.Name)
+	}
+	return EthereumAddress(secpPk)
+}
+
+// Secret decrypts the secret of the account using the given passphrase.
+func (a *Account) Secret(passphrase string) ([]byte, error) {
+	if a.Crypto.KDF != kdfArgon2id || a.Crypto.Cipher != cipherChaCha20Poly1305 {
+		return nil, fmt.Errorf("wallet: unsupported encryption: %s/%s", a.Crypto.KDF, a.Crypto.Cipher)
+	}
+	aead, err := newAEAD(passphrase, &a.Crypto.KDFParams)
+	if err != nil {
+		return nil, err
+	}
+	if len(a.Crypto.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("wallet: malformed nonce")
+	}
+	secret, err := aead.Open(nil, a.Crypto.Nonce, a.Crypto.Ciphertext, a.associatedData())
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return secret, nil
+}
+
+// Signer decrypts the secret of the account using the given passphrase and returns its signer.
+func (a *Account) Signer(passphrase string) (signature.Signer, error) {
+	signer, _, err := a.unlock(passphrase)
+	return signer, err
+}
+
+// Export returns the account in the keystore file format, with its secret re-encrypted under the
+// given export passphrase.
+func (a *Account) Export(passphrase, exportPassphrase string) ([]byte, error) {
+	_, secret, err := a.unlock(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	exported := *a
+	if err = exported.encrypt(secret, exportPassphrase); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(&exported, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("wallet: failed to encode account: %w", err)
+	}
+	return data, nil
+}
+
+// unlock decrypts the secret of the account using the given passphrase and checks that it matches
+// the public key of the account.
+func (a *Account) unlock(passphrase string) (signature.Signer, []byte, error) {
+	secret, err := a.Secret(passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	signer, err := NewSigner(a.Algorithm, secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	pk, err := a.PublicKeyValue()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !signer.Public().Equal(pk) {
+		return nil, nil, fmt.Errorf("wallet: decrypted key does not match account %s", a.Name)
+	}
+	return signer, secret, nil
+}
+
+// ParseAccount parses an account in the keystore file format, e.g. one exported with Export.
+func ParseAccount(data []byte) (*Account, error) {
+	var acct Account
+	if err := json.Unmarshal(data, &acct); err != nil {
+		return nil, fmt.Errorf("wallet: malformed account: %w", err)
+	}
+	if acct.Version != KeystoreVersion {
+		return nil, fmt.Errorf("wallet: unsupported account version %d", acct.Version)
+	}
+	return &acct, nil
+}
+
+// associatedData binds the ciphertext to the account key, so that the encrypted secrets of
+// different accounts cannot be swapped.
+func (a *Account) associatedData() []byte {
+	return append([]byte(string(a.Algorithm)+":"), a.PublicKey...)
+}
+
+// encrypt encrypts the given secret using the given passphrase.
+func (a *Account) encrypt(secret []byte, passphrase string) error {
+	params := DefaultKDFParams
+	params.Salt = make([]byte, saltSize)
+	if _, err := rand.Read(params.Salt); err != nil {
+		return fmt.Errorf("wallet: failed to generate salt: %w", err)
+	}
+	aead, err := newAEAD(passphrase, &params)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return fmt.Errorf("wallet: failed to generate nonce: %w", err)
+	}
+
+	a.Crypto = Crypto{
+		KDF:        kdfArgon2id,
+		KDFParams:  params,
+		Cipher:     cipherChaCha20Poly1305,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, secret, a.associatedData()),
+	}
+	return nil
+}
+
+func newAEAD(passphrase string, params *KDFParams) (cipher.AEAD, error) {
+	if params.Time == 0 || params.Memory == 0 || params.Threads == 0 || len(params.Salt) == 0 {
+		return nil, fmt.Errorf("wallet: malformed key derivation parameters")
+	}
+	key := argon2.IDKey([]byte(passphrase), params.Salt, params.Time, params.Memory, params.Threads, chacha20poly1305.KeySize)
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, fmt.Errorf("wallet: failed to create cipher: %w", err)
+	}
+	return aead, nil
+}
+
+// Keystore is a directory of encrypted account files.
+type Keystore struct {
+	dir string
+}
+
+// OpenKeystore opens the keystore in the given directory, creating the directory if needed.
+func OpenKeystore(dir string) (*Keystore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("wallet: failed to create keystore directory: %w", err)
+	}
+	return &Keystore{dir: dir}, nil
+}
+
+// ValidateAccountName checks that the given account name is valid.
+func ValidateAccountName(name string) error {
+	if !accountNameRegexp.MatchString(name) {
+		return fmt.Errorf("wallet: invalid account name %q: only letters, digits, '_', '-' and '.' are allowed", name)
+	}
+	return nil
+}
+
+func (ks *Keystore) path(name string) string {
+	return filepath.Join(ks.dir, name+accountFileSuffix)
+}
+
+// List returns all accounts in the keystore, sorted by name.
+func (ks *Keystore) List() ([]*Account, error) {
+	entries, err := os.ReadDir(ks.dir)
+	if err != nil {
+		return nil, fmt.Errorf("wallet: failed to read keystore directory: %w", err)
+	}
+	var accounts []*Account
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), accountFileSuffix)
+		if entry.IsDir() || name == entry.Name() || ValidateAccountName(name) != nil {
+			continue
+		}
+		acct, err := ks.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, acct)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Name < accounts[j].Name
+	})
+	return accounts, nil
+}
+
+// Get returns the account with the given name.
+func (ks *Keystore) Get(name string) (*Account, error) {
+	if err := ValidateAccountName(name); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(ks.path(name))
+	switch {
+	case err == nil:
+	case errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	default:
+		return nil, fmt.Errorf("wallet: failed to read account %s: %w", name, err)
+	}
+
+	acct, err := ParseAccount(data)
+	if err != nil {
+		return nil, fmt.Errorf("wallet: account %s: %w", name, err)
+	}
+	acct.Name = name
+	return acct, nil
+}
+
+// Create creates a new account with the given name and secret, encrypted using the given
+// passphrase. The path is the derivation path of the secret (if any).
+func (ks *Keystore) Create(name string, alg Algorithm, secret []byte, path Path, passphrase string) (*Account, error) {
+	if err := ValidateAccountName(name); err != nil {
+		return nil, err
+	}
+	signer, err := NewSigner(alg, secret)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := SigSpec(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	pk, err := signer.Public().(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("wallet: failed to marshal public key: %w", err)
+	}
+
+	acct := &Account{
+		Version:   KeystoreVersion,
+		Name:      name,
+		Algorithm: alg,
+		Address:   types.NewAddress(spec),
+		PublicKey: pk,
+		Created:   time.Now().UTC().Truncate(time.Second),
+	}
+	if path != nil {
+		acct.Path = path.String()
+	}
+	if err = acct.encrypt(secret, passphrase); err != nil {
+		return nil, err
+	}
+	if err = ks.write(acct, false); err != nil {
+		return nil, err
+	}
+	return acct, nil
+}
+
+// Import adds an account in the keystore file format, e.g. one exported with Export, under the
+// given name. Its secret is decrypted using the given passphrase and re-encrypted under the new
+// passphrase.
+func (ks *Keystore) Import(name string, data []byte, passphrase, newPassphrase string) (*Account, error) {
+	exported, err := ParseAccount(data)
+	if err != nil {
+		return nil, err
+	}
+	_, secret, err := exported.unlock(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var path Path
+	if exported.Path != "" {
+		if path, err = ParsePath(exported.Path); err != nil {
+			return nil, err
+		}
+	}
+	return ks.Create(name, exported.Algorithm, secret, path, newPassphrase)
+}
+
+// ChangePassphrase re-encrypts the secret of the account with the given name under a new
+// passphrase.
+func (ks *Keystore) ChangePassphrase(name, passphrase, newPassphrase string) error {
+	acct, err := ks.Get(name)
+	if err != nil {
+		return err
+	}
+	_, secret, err := acct.unlock(passphrase)
+	if err != nil {
+		return err
+	}
+	if err = acct.encrypt(secret, newPassphrase); err != nil {
+		return err
+	}
+	return ks.write(acct, true)
+}
+
+// Retire removes the account with the given name from the keystore, moving its file into the
+// retired subdirectory so that the key can still be recovered. It returns the new path of the
+// account file.
+func (ks *Keystore) Retire(name string) (string, error) {
+	if _, err := ks.Get(name); err != nil {
+		return "", err
+	}
+	dir := filepath.Join(ks.dir, retiredDir)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("wallet: failed to create retired accounts directory: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s%s", name, time.Now().UTC().Format("20060102T150405Z"), accountFileSuffix))
+	if err := os.Rename(ks.path(name), path); err != nil {
+		return "", fmt.Errorf("wallet: failed to retire account %s: %w", name, err)
+	}
+	return path, nil
+}
+
+// write writes the given account, failing in case it already exists unless overwrite is set.
+func (ks *Keystore) write(acct *Account, overwrite bool) error {
+	data, err := json.MarshalIndent(acct, "", "  ")
+	if err != nil {
+		return fmt.Errorf("wallet: failed to encode account: %w", err)
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(ks.path(acct.Name), flags, 0o600)
+	switch {
+	case err == nil:
+	case errors.Is(err, os.ErrExist):
+		return fmt.Errorf("%w: %s", ErrExists, acct.Name)
+	default:
+		return fmt.Errorf("wallet: failed to create account file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err = f.Write(data); err != nil {
+		return fmt.Errorf("wallet: failed to write account file: %w", err)
+	}
+	return f.Close()
+}
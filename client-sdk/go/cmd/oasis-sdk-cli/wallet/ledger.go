@@ -0,0 +1,80 @@
+package wallet
+
+import (
+	"encoding"
+	"fmt"
+	"strings"
+
+	coreSignature "github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	pluginSigner "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/plugin"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// LedgerPluginName is the name of the Oasis Core Ledger signer plugin.
+const LedgerPluginName = "ledger"
+
+// LedgerConfig is the configuration of a Ledger signer.
+type LedgerConfig struct {
+	// PluginPath is the path to the Oasis Core Ledger signer plugin binary.
+	PluginPath string
+	// WalletID is the hex-encoded identifier of the Ledger wallet, empty for the first connected
+	// device.
+	WalletID string
+	// Index is the account index of the key on the device.
+	Index uint32
+}
+
+// pluginConfig returns the configuration string passed to the signer plugin.
+func (c *LedgerConfig) pluginConfig() string {
+	params := []string{}
+	if c.WalletID != "" {
+		params = append(params, "wallet_id:"+c.WalletID)
+	}
+	params = append(params, fmt.Sprintf("index:%d", c.Index))
+	return strings.Join(params, ",")
+}
+
+// NewLedgerSigner returns an Ed25519 signer backed by a Ledger device, through the Oasis Core
+// Ledger signer plugin. Every signature has to be confirmed on the device.
+func NewLedgerSigner(cfg *LedgerConfig) (signature.Signer, error) {
+	if cfg.PluginPath == "" {
+		return nil, fmt.Errorf("wallet: no Ledger signer plugin given")
+	}
+	factory, err := pluginSigner.NewFactory(&pluginSigner.FactoryConfig{
+		Name:   LedgerPluginName,
+		Path:   cfg.PluginPath,
+		Config: cfg.pluginConfig(),
+	}, coreSignature.SignerEntity)
+	if err != nil {
+		return nil, fmt.Errorf("wallet: failed to start Ledger signer plugin: %w", err)
+	}
+	signer, err := factory.Load(coreSignature.SignerEntity)
+	if err != nil {
+		return nil, fmt.Errorf("wallet: failed to load key from Ledger device: %w", err)
+	}
+	return ed25519.WrapSigner(signer), nil
+}
+
+// NewLedgerAccount returns an account, not stored in the keystore, for the key of the given
+// Ledger signer.
+func NewLedgerAccount(cfg *LedgerConfig, signer signature.Signer) (*Account, error) {
+	pk := signer.Public()
+	spec, err := SigSpec(pk)
+	if err != nil {
+		return nil, err
+	}
+	rawPk, err := pk.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("wallet: failed to marshal public key: %w", err)
+	}
+	return &Account{
+		Version:   KeystoreVersion,
+		Name:      fmt.Sprintf("ledger:%d", cfg.Index),
+		Algorithm: AlgorithmEd25519,
+		Address:   types.NewAddress(spec),
+		PublicKey: rawPk,
+	}, nil
+}
@@ -0,0 +1,188 @@
+package wallet
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+
+	memorySigner "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/memory"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/slip10"
+)
+
+const (
+	// hardenedOffset is the offset of hardened BIP-0032 child indices.
+	hardenedOffset = uint32(0x80000000)
+
+	// bip39Iterations is the number of PBKDF2 iterations used to derive a BIP-0039 seed.
+	bip39Iterations = 2048
+	// bip39SeedSize is the size of a BIP-0039 seed.
+	bip39SeedSize = 64
+)
+
+// MaxAccountNumber is the maximum account number of derived keys.
+const MaxAccountNumber = uint32(0x7fffffff)
+
+// Path is a BIP-0032 derivation path, with hardened indices including the hardened offset.
+type Path []uint32
+
+// String returns the path in its usual notation, e.g. m/44'/474'/0'.
+func (p Path) String() string {
+	var b strings.Builder
+	b.WriteString("m")
+	for _, index := range p {
+		if index >= hardenedOffset {
+			fmt.Fprintf(&b, "/%d'", index-hardenedOffset)
+		} else {
+			fmt.Fprintf(&b, "/%d", index)
+		}
+	}
+	return b.String()
+}
+
+// ParsePath parses a BIP-0032 derivation path in its usual notation, e.g. m/44'/474'/0'.
+func ParsePath(s string) (Path, error) {
+	components := strings.Split(s, "/")
+	if components[0] != "m" {
+		return nil, fmt.Errorf("wallet: malformed path %s: must start with m", s)
+	}
+	path := make(Path, 0, len(components)-1)
+	for _, c := range components[1:] {
+		var offset uint32
+		if strings.HasSuffix(c, "'") {
+			c = strings.TrimSuffix(c, "'")
+			offset = hardenedOffset
+		}
+		index, err := strconv.ParseUint(c, 10, 32)
+		if err != nil || uint32(index) >= hardenedOffset {
+			return nil, fmt.Errorf("wallet: malformed path %s: invalid index %s", s, c)
+		}
+		path = append(path, uint32(index)+offset)
+	}
+	return path, nil
+}
+
+// DefaultPath returns the default derivation path of the given account number for the given
+// algorithm: m/44'/474'/n' as defined by ADR 0008 for Ed25519 and m/44'/60'/0'/0/n as used by
+// Ethereum wallets for Secp256k1.
+func DefaultPath(alg Algorithm, number uint32) (Path, error) {
+	if number > MaxAccountNumber {
+		return nil, fmt.Errorf("wallet: invalid account number: %d (maximum: %d)", number, MaxAccountNumber)
+	}
+	switch alg {
+	case AlgorithmEd25519:
+		return Path{44 + hardenedOffset, 474 + hardenedOffset, number + hardenedOffset}, nil
+	case AlgorithmSecp256k1:
+		return Path{44 + hardenedOffset, 60 + hardenedOffset, hardenedOffset, 0, number}, nil
+	default:
+		return nil, fmt.Errorf("wallet: unsupported algorithm: %s", alg)
+	}
+}
+
+// NormalizeMnemonic normalizes the given BIP-0039 mnemonic and checks its length.
+//
+// As the BIP-0039 word lists are not bundled, the words and the checksum of the mnemonic are not
+// verified, so the derived address should be checked against a known one.
+func NormalizeMnemonic(mnemonic string) (string, error) {
+	words := strings.Fields(norm.NFKD.String(mnemonic))
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return "", fmt.Errorf("wallet: invalid mnemonic: expected 12, 15, 18, 21 or 24 words, got %d", len(words))
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicSeed derives the BIP-0039 seed of the given mnemonic and passphrase.
+func MnemonicSeed(mnemonic, passphrase string) ([]byte, error) {
+	mnemonic, err := NormalizeMnemonic(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	salt := "mnemonic" + norm.NFKD.String(passphrase)
+	return pbkdf2.Key([]byte(mnemonic), []byte(salt), bip39Iterations, bip39SeedSize, sha512.New), nil
+}
+
+// DeriveSecret derives the secret of the given algorithm from the given BIP-0039 mnemonic and
+// passphrase along the given path, using SLIP-0010 for Ed25519 and BIP-0032 for Secp256k1.
+func DeriveSecret(alg Algorithm, mnemonic, passphrase string, path Path) ([]byte, error) {
+	seed, err := MnemonicSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	switch alg {
+	case AlgorithmEd25519:
+		return deriveEd25519(seed, path)
+	case AlgorithmSecp256k1:
+		return deriveSecp256k1(seed, path)
+	default:
+		return nil, fmt.Errorf("wallet: unsupported algorithm: %s", alg)
+	}
+}
+
+func deriveEd25519(seed []byte, path Path) ([]byte, error) {
+	signer, chainCode, err := slip10.NewMasterKey(seed)
+	if err != nil {
+		return nil, fmt.Errorf("wallet: failed to derive master key: %w", err)
+	}
+	for _, index := range path {
+		if signer, chainCode, err = slip10.NewChildKey(signer, chainCode, index); err != nil {
+			return nil, fmt.Errorf("wallet: failed to derive child key: %w", err)
+		}
+	}
+	return signer.(*memorySigner.Signer).UnsafeBytes()[:SecretSize], nil
+}
+
+func deriveSecp256k1(seed []byte, path Path) ([]byte, error) {
+	curve := btcec.S256()
+
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	_, _ = mac.Write(seed)
+	digest := mac.Sum(nil)
+	key, chainCode := new(big.Int).SetBytes(digest[:32]), digest[32:]
+	if key.Sign() == 0 || key.Cmp(curve.N) >= 0 {
+		return nil, fmt.Errorf("wallet: invalid master key")
+	}
+
+	for _, index := range path {
+		var data []byte
+		if index >= hardenedOffset {
+			data = append([]byte{0x00}, leftPad(key.Bytes())...)
+		} else {
+			x, y := curve.ScalarBaseMult(leftPad(key.Bytes()))
+			data = (&btcec.PublicKey{Curve: curve, X: x, Y: y}).SerializeCompressed()
+		}
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], index)
+		data = append(data, b[:]...)
+
+		mac = hmac.New(sha512.New, chainCode)
+		_, _ = mac.Write(data)
+		digest = mac.Sum(nil)
+
+		tweak := new(big.Int).SetBytes(digest[:32])
+		if tweak.Cmp(curve.N) >= 0 {
+			return nil, fmt.Errorf("wallet: invalid child key at index %d", index)
+		}
+		key = tweak.Add(tweak, key).Mod(tweak, curve.N)
+		if key.Sign() == 0 {
+			return nil, fmt.Errorf("wallet: invalid child key at index %d", index)
+		}
+		chainCode = digest[32:]
+	}
+	return leftPad(key.Bytes()), nil
+}
+
+// leftPad pads the given big-endian integer to SecretSize bytes.
+func leftPad(b []byte) []byte {
+	padded := make([]byte, SecretSize)
+	copy(padded[SecretSize-len(b):], b)
+	return padded
+}
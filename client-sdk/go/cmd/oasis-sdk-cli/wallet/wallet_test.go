@@ -0,0 +1,228 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/secp256k1"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestPath(t *testing.T) {
+	require := require.New(t)
+
+	path, err := ParsePath("m/44'/60'/0'/0/7")
+	require.NoError(err, "ParsePath")
+	require.Equal("m/44'/60'/0'/0/7", path.String())
+
+	defaultPath, err := DefaultPath(AlgorithmSecp256k1, 7)
+	require.NoError(err, "DefaultPath")
+	require.Equal(path, defaultPath)
+
+	for _, malformed := range []string{"", "44'/474'", "m/x", "m/2147483648", "m//1"} {
+		_, err = ParsePath(malformed)
+		require.Error(err, "ParsePath should fail for %q", malformed)
+	}
+}
+
+func TestDeriveEd25519(t *testing.T) {
+	require := require.New(t)
+
+	// Test vectors from ADR 0008.
+	for _, tc := range []struct {
+		mnemonic   string
+		passphrase string
+		number     uint32
+		pubKeyHex  string
+	}{
+		{testMnemonic, "", 0, "ad55bbb7c192b8ecfeb6ad18bbd7681c0923f472d5b0c212fbde33008005ad61"},
+		{testMnemonic, "", 1, "73fd7c51a0f059ea34d8dca305e0fdb21134ca32216ca1681ae1d12b3d350e16"},
+		{testMnemonic, "", MaxAccountNumber, "9e7c2b2d03265ce4ea175e3664a678182548a7fc6db04801513cff7c98c8f151"},
+		{"equip will roof matter pink blind book anxiety banner elbow sun young", "p4ssphr4se", 1, "b099f8906467325aa1283590c1bca01e8708d5419557aa7771b826fa02d2abe6"},
+	} {
+		path, err := DefaultPath(AlgorithmEd25519, tc.number)
+		require.NoError(err, "DefaultPath")
+		secret, err := DeriveSecret(AlgorithmEd25519, tc.mnemonic, tc.passphrase, path)
+		require.NoError(err, "DeriveSecret")
+		signer, err := NewSigner(AlgorithmEd25519, secret)
+		require.NoError(err, "NewSigner")
+
+		pk, err := signer.Public().(ed25519.PublicKey).MarshalBinary()
+		require.NoError(err)
+		require.Equal(tc.pubKeyHex, hex.EncodeToString(pk), "derived key for account %d", tc.number)
+	}
+
+	_, err := DeriveSecret(AlgorithmEd25519, "abandon abandon", "", nil)
+	require.Error(err, "short mnemonics should be rejected")
+}
+
+func TestDeriveSecp256k1(t *testing.T) {
+	require := require.New(t)
+
+	// Well-known first Ethereum account of the test mnemonic.
+	path, err := DefaultPath(AlgorithmSecp256k1, 0)
+	require.NoError(err, "DefaultPath")
+	secret, err := DeriveSecret(AlgorithmSecp256k1, testMnemonic, "", path)
+	require.NoError(err, "DeriveSecret")
+	signer, err := NewSigner(AlgorithmSecp256k1, secret)
+	require.NoError(err, "NewSigner")
+
+	untagged, err := signer.Public().(secp256k1.PublicKey).MarshalBinaryUncompressedUntagged()
+	require.NoError(err)
+	h := sha3.NewLegacyKeccak256()
+	h.Write(untagged)
+	require.Equal("9858effd232b4033e47d90003d41ec34ecaeda94", hex.EncodeToString(h.Sum(nil)[12:]))
+
+	ethAddr, err := EthereumAddress(signer.Public().(secp256k1.PublicKey))
+	require.NoError(err, "EthereumAddress")
+	addr, err := AddressFromEthereum(ethAddr)
+	require.NoError(err, "AddressFromEthereum")
+	spec, err := SigSpec(signer.Public())
+	require.NoError(err, "SigSpec")
+	require.True(types.NewAddress(spec).Equal(addr), "addresses derived from Ethereum addresses should match")
+	_, err = AddressFromEthereum(ethAddr[1:])
+	require.Error(err, "truncated Ethereum addresses should be rejected")
+}
+
+func TestKeystore(t *testing.T) {
+	require := require.New(t)
+
+	defaultParams := DefaultKDFParams
+	DefaultKDFParams = KDFParams{Time: 1, Memory: 64, Threads: 1}
+	defer func() { DefaultKDFParams = defaultParams }()
+
+	ks, err := OpenKeystore(t.TempDir())
+	require.NoError(err, "OpenKeystore")
+
+	accounts, err := ks.List()
+	require.NoError(err, "List")
+	require.Empty(accounts)
+
+	secret, err := GenerateSecret(AlgorithmSecp256k1)
+	require.NoError(err, "GenerateSecret")
+	bob, err := ks.Create("bob", AlgorithmSecp256k1, secret, nil, "bob's passphrase")
+	require.NoError(err, "Create")
+
+	path, err := DefaultPath(AlgorithmEd25519, 0)
+	require.NoError(err, "DefaultPath")
+	secret, err = DeriveSecret(AlgorithmEd25519, testMnemonic, "", path)
+	require.NoError(err, "DeriveSecret")
+	alice, err := ks.Create("alice", AlgorithmEd25519, secret, path, "alice's passphrase")
+	require.NoError(err, "Create")
+	require.Equal("m/44'/474'/0'", alice.Path)
+
+	_, err = ks.Create("alice", AlgorithmEd25519, secret, path, "")
+	require.ErrorIs(err, ErrExists)
+	_, err = ks.Create("../alice", AlgorithmEd25519, secret, path, "")
+	require.Error(err, "invalid names should be rejected")
+
+	accounts, err = ks.List()
+	require.NoError(err, "List")
+	require.Len(accounts, 2)
+	require.Equal("alice", accounts[0].Name)
+	require.Equal(alice.Address, accounts[0].Address)
+	require.Equal("bob", accounts[1].Name)
+	require.Equal(bob.Address, accounts[1].Address)
+
+	acct, err := ks.Get("alice")
+	require.NoError(err, "Get")
+	signer, err := acct.Signer("alice's passphrase")
+	require.NoError(err, "Signer")
+	pk, err := acct.PublicKeyValue()
+	require.NoError(err, "PublicKeyValue")
+	require.True(signer.Public().Equal(pk))
+
+	parsed, err := ParsePublicKey(string(acct.Algorithm) + ":" + pk.String())
+	require.NoError(err, "ParsePublicKey")
+	require.True(parsed.Equal(pk))
+	_, err = ParsePublicKey(pk.String())
+	require.Error(err, "public keys without an algorithm should be rejected")
+
+	_, err = acct.Signer("bob's passphrase")
+	require.ErrorIs(err, ErrWrongPassphrase)
+
+	_, err = ks.Get("charlie")
+	require.ErrorIs(err, ErrNotFound)
+}
+
+func TestKeystoreExport(t *testing.T) {
+	require := require.New(t)
+
+	defaultParams := DefaultKDFParams
+	DefaultKDFParams = KDFParams{Time: 1, Memory: 64, Threads: 1}
+	defer func() { DefaultKDFParams = defaultParams }()
+
+	ks, err := OpenKeystore(t.TempDir())
+	require.NoError(err, "OpenKeystore")
+	path, err := DefaultPath(AlgorithmEd25519, 1)
+	require.NoError(err, "DefaultPath")
+	secret, err := DeriveSecret(AlgorithmEd25519, testMnemonic, "", path)
+	require.NoError(err, "DeriveSecret")
+	alice, err := ks.Create("alice", AlgorithmEd25519, secret, path, "alice's passphrase")
+	require.NoError(err, "Create")
+
+	_, err = alice.Export("wrong passphrase", "export passphrase")
+	require.ErrorIs(err, ErrWrongPassphrase)
+	data, err := alice.Export("alice's passphrase", "export passphrase")
+	require.NoError(err, "Export")
+
+	other, err := OpenKeystore(t.TempDir())
+	require.NoError(err, "OpenKeystore")
+	_, err = other.Import("carol", data, "alice's passphrase", "carol's passphrase")
+	require.ErrorIs(err, ErrWrongPassphrase, "exports should not be encrypted under the account passphrase")
+	carol, err := other.Import("carol", data, "export passphrase", "carol's passphrase")
+	require.NoError(err, "Import")
+	require.Equal(alice.Address, carol.Address)
+	require.Equal(alice.Path, carol.Path)
+	_, err = carol.Signer("carol's passphrase")
+	require.NoError(err, "Signer")
+
+	require.NoError(ks.ChangePassphrase("alice", "alice's passphrase", "new passphrase"), "ChangePassphrase")
+	acct, err := ks.Get("alice")
+	require.NoError(err, "Get")
+	_, err = acct.Signer("alice's passphrase")
+	require.ErrorIs(err, ErrWrongPassphrase)
+	_, err = acct.Signer("new passphrase")
+	require.NoError(err, "Signer")
+
+	retired, err := ks.Retire("alice")
+	require.NoError(err, "Retire")
+	_, err = ks.Get("alice")
+	require.ErrorIs(err, ErrNotFound)
+	accounts, err := ks.List()
+	require.NoError(err, "List")
+	require.Empty(accounts, "retired accounts should not be listed")
+	data, err = os.ReadFile(retired)
+	require.NoError(err, "ReadFile")
+	acct, err = ParseAccount(data)
+	require.NoError(err, "ParseAccount")
+	require.Equal(alice.Address, acct.Address)
+}
+
+func TestLedger(t *testing.T) {
+	require := require.New(t)
+
+	cfg := &LedgerConfig{Index: 3}
+	require.Equal("index:3", cfg.pluginConfig())
+	cfg.WalletID = "0a1b2c3d"
+	require.Equal("wallet_id:0a1b2c3d,index:3", cfg.pluginConfig())
+
+	_, err := NewLedgerSigner(&LedgerConfig{})
+	require.Error(err, "a plugin should be required")
+
+	acct, err := NewLedgerAccount(cfg, sdkTesting.Alice.Signer)
+	require.NoError(err, "NewLedgerAccount")
+	require.Equal("ledger:3", acct.Name)
+	require.Equal(sdkTesting.Alice.Address, acct.Address)
+	pk, err := acct.PublicKeyValue()
+	require.NoError(err, "PublicKeyValue")
+	require.True(pk.Equal(sdkTesting.Alice.Signer.Public()))
+}
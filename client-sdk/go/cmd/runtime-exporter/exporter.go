@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/indexer"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/consensusaccounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const (
+	namespace = "oasis_runtime"
+
+	// moduleUnknown is the module label of transactions that cannot be decoded, e.g. because their
+	// call is encrypted.
+	moduleUnknown = "unknown"
+
+	statusSuccess = "success"
+	statusFailed  = "failed"
+
+	directionDeposit  = "deposit"
+	directionWithdraw = "withdraw"
+)
+
+// exporter exports the metrics of the rounds of a runtime.
+type exporter struct {
+	sync.Mutex
+
+	lastRound *uint64
+
+	round             prometheus.Gauge
+	transactions      *prometheus.CounterVec
+	failedRatio       prometheus.Gauge
+	gasLimit          *prometheus.CounterVec
+	fees              *prometheus.CounterVec
+	events            *prometheus.CounterVec
+	consensusTransfer *prometheus.CounterVec
+}
+
+// observe updates the metrics with the data of the given round. Rounds must be observed in order.
+func (e *exporter) observe(data *indexer.RoundData) {
+	e.Lock()
+	defer e.Unlock()
+
+	var failed int
+	for _, tx := range data.Transactions {
+		module := moduleUnknown
+		if tx.Method != "" {
+			module = strings.SplitN(tx.Method, ".", 2)[0]
+		}
+		status := statusSuccess
+		if !tx.Result.IsSuccess() {
+			status = statusFailed
+			failed++
+		}
+		e.transactions.WithLabelValues(module, status).Inc()
+
+		body, _, decoded, err := registry.DecodeTransaction(&tx.Tx)
+		if err != nil || body == nil {
+			continue
+		}
+		e.gasLimit.WithLabelValues(module).Add(float64(body.AuthInfo.Fee.Gas))
+		e.fees.WithLabelValues(denominationLabel(body.AuthInfo.Fee.Amount.Denomination)).Add(toFloat(&body.AuthInfo.Fee.Amount.Amount))
+
+		if !tx.Result.IsSuccess() {
+			continue
+		}
+		switch b := decoded.(type) {
+		case *consensusaccounts.Deposit:
+			e.consensusTransfer.WithLabelValues(directionDeposit, denominationLabel(b.Amount.Denomination)).Add(toFloat(&b.Amount.Amount))
+		case *consensusaccounts.Withdraw:
+			e.consensusTransfer.WithLabelValues(directionWithdraw, denominationLabel(b.Amount.Denomination)).Add(toFloat(&b.Amount.Amount))
+		}
+	}
+
+	for _, ev := range data.Events {
+		e.events.WithLabelValues(ev.Module).Inc()
+	}
+
+	ratio := 0.0
+	if len(data.Transactions) > 0 {
+		ratio = float64(failed) / float64(len(data.Transactions))
+	}
+	e.failedRatio.Set(ratio)
+	e.round.Set(float64(data.Block.Round))
+	round := data.Block.Round
+	e.lastRound = &round
+}
+
+// run observes the rounds of new blocks as they are finalized, starting with the latest round,
+// until the context is canceled or an error occurs.
+func (e *exporter) run(ctx context.Context, rc client.RuntimeClient) error {
+	blkCh, blkSub, err := rc.WatchBlocks(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to watch blocks: %w", err)
+	}
+	defer blkSub.Close()
+
+	latest, err := rc.GetBlock(ctx, client.RoundLatest)
+	if err != nil {
+		return fmt.Errorf("failed to fetch latest block: %w", err)
+	}
+	if err = e.observeUpTo(ctx, rc, latest.Header.Round); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case blk, ok := <-blkCh:
+			if !ok {
+				return fmt.Errorf("block watcher closed")
+			}
+			if err = e.observeUpTo(ctx, rc, blk.Block.Header.Round); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// observeUpTo observes all rounds following the last observed round up to the given round.
+func (e *exporter) observeUpTo(ctx context.Context, rc client.RuntimeClient, round uint64) error {
+	next := round
+	e.Lock()
+	if e.lastRound != nil {
+		next = *e.lastRound + 1
+	}
+	e.Unlock()
+
+	for ; next <= round; next++ {
+		data, err := indexer.FetchRound(ctx, rc, next)
+		if err != nil {
+			return fmt.Errorf("failed to fetch round %d: %w", next, err)
+		}
+		e.observe(data)
+	}
+	return nil
+}
+
+// denominationLabel returns the label value of the given denomination.
+func denominationLabel(d types.Denomination) string {
+	if d.IsNative() {
+		return "native"
+	}
+	return string(d)
+}
+
+// toFloat converts the given quantity to a float, which may lose precision for large amounts.
+func toFloat(q *quantity.Quantity) float64 {
+	f, _ := new(big.Float).SetInt(q.ToBigInt()).Float64()
+	return f
+}
+
+// newExporter creates a new exporter with its metrics registered with the given registerer.
+func newExporter(reg prometheus.Registerer) (*exporter, error) {
+	e := &exporter{
+		round: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "round",
+			Help:      "Last observed round.",
+		}),
+		transactions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transactions_total",
+			Help:      "Number of executed transactions by status and module, which is the prefix of the called method.",
+		}, []string{"module", "status"}),
+		failedRatio: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "failed_transactions_ratio",
+			Help:      "Ratio of failed transactions in the last observed round.",
+		}),
+		gasLimit: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "gas_limit_total",
+			Help:      "Gas limit of executed transactions by the module of the called method. Runtimes do not report the gas used by transactions, so this is an upper bound.",
+		}, []string{"module"}),
+		fees: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "fees_total",
+			Help:      "Fees paid by executed transactions by denomination, in base units.",
+		}, []string{"denomination"}),
+		events: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_total",
+			Help:      "Number of emitted events by module.",
+		}, []string{"module"}),
+		consensusTransfer: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "consensus_transfer_volume_total",
+			Help:      "Amounts of successful deposits from and withdrawals to the consensus layer by denomination, in base units.",
+		}, []string{"direction", "denomination"}),
+	}
+	for _, c := range []prometheus.Collector{
+		e.round,
+		e.transactions,
+		e.failedRatio,
+		e.gasLimit,
+		e.fees,
+		e.events,
+		e.consensusTransfer,
+	} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
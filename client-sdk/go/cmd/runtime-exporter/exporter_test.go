@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/consensusaccounts"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/fakeruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func nativeUnits(amount uint64) types.BaseUnits {
+	return types.NewBaseUnits(*quantity.NewFromUint64(amount), types.NativeDenomination)
+}
+
+func TestExporter(t *testing.T) {
+	require := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rt := fakeruntime.New(fakeruntime.Config{})
+	rt.SetBalance(sdkTesting.Alice.Address, nativeUnits(1000))
+	rt.SetConsensusBalance(sdkTesting.Alice.Address, *quantity.NewFromUint64(100))
+
+	e, err := newExporter(prometheus.NewRegistry())
+	require.NoError(err, "newExporter")
+	errCh := make(chan error, 1)
+	go func() { errCh <- e.run(ctx, rt) }()
+	// Wait for the genesis round to be observed so that all transactions are observed as well.
+	require.Eventually(func() bool {
+		e.Lock()
+		defer e.Unlock()
+		return e.lastRound != nil
+	}, 5*time.Second, 10*time.Millisecond, "genesis round should be observed")
+
+	submit := func(tb *client.TransactionBuilder, nonce uint64) error {
+		tb.SetFeeGas(1000).SetFeeAmount(nativeUnits(5))
+		tb.AppendAuthSignature(sdkTesting.Alice.SigSpec, nonce)
+		require.NoError(tb.AppendSign(ctx, sdkTesting.Alice.Signer), "AppendSign")
+		_, submitErr := tb.SubmitTxMeta(ctx, nil)
+		return submitErr
+	}
+	ac := accounts.NewV1(rt)
+	ca := consensusaccounts.NewV1(rt)
+	require.NoError(submit(ac.Transfer(sdkTesting.Bob.Address, nativeUnits(10)), 0), "Transfer")
+	require.Error(submit(ac.Transfer(sdkTesting.Bob.Address, nativeUnits(10000)), 1), "Transfer exceeding balance")
+	require.NoError(submit(ca.Deposit(nativeUnits(40)), 2), "Deposit")
+	require.Error(submit(ca.Withdraw(nativeUnits(10000)), 3), "Withdraw exceeding balance")
+
+	latest, err := rt.GetBlock(ctx, client.RoundLatest)
+	require.NoError(err, "GetBlock")
+	require.Eventually(func() bool {
+		return testutil.ToFloat64(e.round) == float64(latest.Header.Round)
+	}, 5*time.Second, 10*time.Millisecond, "all rounds should be observed")
+	cancel()
+	require.ErrorIs(<-errCh, context.Canceled)
+
+	require.EqualValues(1, testutil.ToFloat64(e.transactions.WithLabelValues(accounts.ModuleName, statusSuccess)))
+	require.EqualValues(1, testutil.ToFloat64(e.transactions.WithLabelValues(accounts.ModuleName, statusFailed)))
+	require.EqualValues(1, testutil.ToFloat64(e.transactions.WithLabelValues("consensus", statusSuccess)))
+	require.EqualValues(1, testutil.ToFloat64(e.transactions.WithLabelValues("consensus", statusFailed)))
+	require.EqualValues(1, testutil.ToFloat64(e.failedRatio), "the last round only had a failed transaction")
+	require.EqualValues(2000, testutil.ToFloat64(e.gasLimit.WithLabelValues(accounts.ModuleName)))
+	require.EqualValues(20, testutil.ToFloat64(e.fees.WithLabelValues("native")))
+	require.EqualValues(2, testutil.ToFloat64(e.events.WithLabelValues(accounts.ModuleName)), "transfer and deposit mint")
+	require.EqualValues(40, testutil.ToFloat64(e.consensusTransfer.WithLabelValues(directionDeposit, "native")))
+	require.EqualValues(0, testutil.ToFloat64(e.consensusTransfer.WithLabelValues(directionWithdraw, "native")), "failed withdrawals should not count")
+}
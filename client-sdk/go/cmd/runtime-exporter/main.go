@@ -0,0 +1,99 @@
+// Command runtime-exporter exports per-round metrics of a ParaTime to Prometheus.
+//
+// The exporter follows the blocks of the ParaTime from the time it is started and counts the
+// executed transactions, emitted events, paid fees and consensus layer deposits and withdrawals,
+// which it serves on the /metrics endpoint.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/cobra"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+)
+
+var (
+	nodeAddress   string
+	runtimeID     string
+	insecure      bool
+	compression   string
+	listenAddress string
+)
+
+var rootCmd = &cobra.Command{
+	Use:          "runtime-exporter",
+	Short:        "Export per-round metrics of a ParaTime to Prometheus",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var id common.Namespace
+		if err := id.UnmarshalHex(runtimeID); err != nil {
+			return fmt.Errorf("malformed runtime ID: %w", err)
+		}
+
+		conn, err := client.Dial(nodeAddress, &client.DialConfig{
+			Insecure:    insecure,
+			Compression: compression,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to connect to %s: %w", nodeAddress, err)
+		}
+		defer conn.Close()
+
+		reg := prometheus.NewRegistry()
+		e, err := newExporter(reg)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+		srv := &http.Server{Addr: listenAddress, Handler: mux}
+		srvErrCh := make(chan error, 1)
+		go func() { srvErrCh <- srv.ListenAndServe() }()
+		defer srv.Close()
+
+		runErrCh := make(chan error, 1)
+		go func() { runErrCh <- e.run(ctx, client.New(conn, id)) }()
+
+		select {
+		case err = <-srvErrCh:
+			return fmt.Errorf("metrics server failed: %w", err)
+		case err = <-runErrCh:
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		}
+	},
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.Flags().StringVar(&nodeAddress, "node", "", "gRPC endpoint of the node, e.g. unix:/path/to/internal.sock")
+	rootCmd.Flags().StringVar(&runtimeID, "runtime-id", "", "hex-encoded ParaTime identifier")
+	rootCmd.Flags().BoolVar(&insecure, "insecure", false, "connect to TCP endpoints without TLS")
+	rootCmd.Flags().StringVar(&compression, "compression", "", "compress gRPC requests and responses with gzip or zstd (zstd needs node support)")
+	rootCmd.Flags().StringVar(&listenAddress, "listen", ":9650", "address the metrics endpoint listens on")
+	_ = rootCmd.MarkFlagRequired("node")
+	_ = rootCmd.MarkFlagRequired("runtime-id")
+}
@@ -0,0 +1,123 @@
+// Command web3-proxy serves the Ethereum JSON-RPC API on top of the EVM module of a ParaTime, so
+// that Ethereum tooling like Hardhat and Foundry can be used against a local network.
+//
+// The proxy is meant for development only. Transactions sent with eth_sendTransaction are signed
+// with the development account keys given on the command line.
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/secp256k1"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/web3proxy"
+)
+
+var (
+	nodeAddress   string
+	runtimeID     string
+	insecure      bool
+	compression   string
+	coalesce      bool
+	listenAddress string
+	chainID       uint64
+	accountKeys   []string
+	gasLimit      uint64
+	gasPrice      uint64
+)
+
+var rootCmd = &cobra.Command{
+	Use:          "web3-proxy",
+	Short:        "Serve the Ethereum JSON-RPC API of a ParaTime for development",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var id common.Namespace
+		if err := id.UnmarshalHex(runtimeID); err != nil {
+			return fmt.Errorf("malformed runtime ID: %w", err)
+		}
+		var accounts []signature.Signer
+		for _, key := range accountKeys {
+			sk, err := hex.DecodeString(strings.TrimPrefix(key, "0x"))
+			if err != nil || len(sk) != 32 {
+				return fmt.Errorf("malformed account key")
+			}
+			accounts = append(accounts, secp256k1.NewSigner(sk))
+		}
+
+		conn, err := client.Dial(nodeAddress, &client.DialConfig{
+			Insecure:    insecure,
+			Compression: compression,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to connect to %s: %w", nodeAddress, err)
+		}
+		defer conn.Close()
+
+		rc := client.New(conn, id)
+		if coalesce {
+			rc = client.NewQueryCoalescer(rc)
+		}
+		proxy, err := web3proxy.New(rc, web3proxy.Config{
+			ChainID:  chainID,
+			Accounts: accounts,
+			GasLimit: gasLimit,
+			GasPrice: gasPrice,
+		})
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		srv := &http.Server{Addr: listenAddress, Handler: proxy}
+		srvErrCh := make(chan error, 1)
+		go func() { srvErrCh <- srv.ListenAndServe() }()
+
+		select {
+		case err = <-srvErrCh:
+			return fmt.Errorf("server failed: %w", err)
+		case <-ctx.Done():
+			if err = srv.Shutdown(context.Background()); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		}
+	},
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.Flags().StringVar(&nodeAddress, "node", "", "gRPC endpoint of the node, e.g. unix:/path/to/internal.sock")
+	rootCmd.Flags().StringVar(&runtimeID, "runtime-id", "", "hex-encoded ParaTime identifier")
+	rootCmd.Flags().BoolVar(&insecure, "insecure", false, "connect to TCP endpoints without TLS")
+	rootCmd.Flags().StringVar(&compression, "compression", "", "compress gRPC requests and responses with gzip or zstd (zstd needs node support)")
+	rootCmd.Flags().BoolVar(&coalesce, "coalesce-queries", false, "make identical concurrent queries to the node only once")
+	rootCmd.Flags().StringVar(&listenAddress, "listen", "127.0.0.1:8545", "address the JSON-RPC endpoint listens on")
+	rootCmd.Flags().Uint64Var(&chainID, "chain-id", 0, "EIP-155 chain ID of the ParaTime")
+	rootCmd.Flags().StringSliceVar(&accountKeys, "account-key", nil, "hex-encoded secp256k1 private key of a development account (repeatable)")
+	rootCmd.Flags().Uint64Var(&gasLimit, "gas-limit", web3proxy.DefaultGasLimit, "gas limit of transactions and calls that do not specify one")
+	rootCmd.Flags().Uint64Var(&gasPrice, "gas-price", 0, "gas price used when the ParaTime has no minimum gas price")
+	_ = rootCmd.MarkFlagRequired("node")
+	_ = rootCmd.MarkFlagRequired("runtime-id")
+	_ = rootCmd.MarkFlagRequired("chain-id")
+}
@@ -0,0 +1,63 @@
+// Command wire-vectors exports wire compatibility vectors for the types of all registered runtime
+// modules.
+//
+// For every registered method body, method result and event, the command generates a
+// deterministic sample value and exports its canonical CBOR encoding together with its JSON
+// representation. For every call method, it also exports a sample transaction signed with the
+// Alice test key. The TypeScript and Rust SDKs can import the fixtures to check that they encode
+// and decode the same types identically.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/consensusaccounts"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/contracts"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/core"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/evm"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/rewards"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/rofl"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/roflmarket"
+)
+
+var outputFile string
+
+var rootCmd = &cobra.Command{
+	Use:          "wire-vectors",
+	Short:        "Export wire compatibility vectors for all registered module types",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fixtures, err := Generate()
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(fixtures, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode fixtures: %w", err)
+		}
+		data = append(data, '\n')
+
+		if outputFile == "" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		return ioutil.WriteFile(outputFile, data, 0o644) //nolint: gosec
+	},
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.Flags().StringVarP(&outputFile, "output", "o", "", "file to write the fixtures to instead of standard output")
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/secp256k1"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/sr25519"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// maxSampleDepth is the maximum depth of pointers followed when generating samples, to bound
+// samples of recursive types.
+const maxSampleDepth = 8
+
+var (
+	quantityType  = reflect.TypeOf(quantity.Quantity{})
+	bigIntType    = reflect.TypeOf(big.Int{})
+	addressType   = reflect.TypeOf(types.Address{})
+	secp256k1Type = reflect.TypeOf(secp256k1.PublicKey{})
+	sr25519Type   = reflect.TypeOf(sr25519.PublicKey{})
+	publicKeyType = reflect.TypeOf((*signature.PublicKey)(nil)).Elem()
+	rawType       = reflect.TypeOf(cbor.RawMessage{})
+
+	// sampleAddresses are the addresses used in samples, in turn.
+	sampleAddresses = []types.Address{
+		sdkTesting.Alice.Address,
+		sdkTesting.Bob.Address,
+		sdkTesting.Charlie.Address,
+	}
+)
+
+// sampler generates deterministic sample values. Values are derived from a counter, so that the
+// fields of a sample are distinguishable from each other.
+type sampler struct {
+	n uint64
+}
+
+func (s *sampler) next() uint64 {
+	s.n++
+	return s.n
+}
+
+// sample returns a newly allocated sample value of the type of the given example value.
+func (s *sampler) sample(example interface{}) interface{} {
+	t := reflect.TypeOf(example)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	v := reflect.New(t)
+	s.fill(v.Elem(), 0)
+	return v.Interface()
+}
+
+func (s *sampler) fill(v reflect.Value, depth int) {
+	switch v.Type() {
+	case quantityType:
+		v.Set(reflect.ValueOf(*quantity.NewFromUint64(1000 * s.next())))
+		return
+	case bigIntType:
+		v.Set(reflect.ValueOf(*new(big.Int).SetUint64(1000 * s.next())))
+		return
+	case addressType:
+		v.Set(reflect.ValueOf(sampleAddresses[s.next()%uint64(len(sampleAddresses))]))
+		return
+	case secp256k1Type:
+		v.Set(reflect.ValueOf(sdkTesting.Dave.Signer.Public()))
+		return
+	case sr25519Type:
+		v.Set(reflect.ValueOf(sdkTesting.Erin.Signer.Public()))
+		return
+	case rawType:
+		// Raw messages must be valid CBOR.
+		v.SetBytes(cbor.Marshal(s.next()))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(s.next() % 100))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(s.next() % 100)
+	case reflect.String:
+		v.SetString(fmt.Sprintf("sample-%d", s.next()))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			n := byte(s.next())
+			v.SetBytes([]byte{n, n + 1, n + 2, n + 3})
+			return
+		}
+		elem := reflect.New(v.Type().Elem()).Elem()
+		s.fill(elem, depth)
+		v.Set(reflect.Append(reflect.MakeSlice(v.Type(), 0, 1), elem))
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			n := byte(s.next())
+			for i := 0; i < v.Len(); i++ {
+				v.Index(i).SetUint(uint64(n + byte(i)))
+			}
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			s.fill(v.Index(i), depth)
+		}
+	case reflect.Map:
+		key := reflect.New(v.Type().Key()).Elem()
+		s.fill(key, depth)
+		elem := reflect.New(v.Type().Elem()).Elem()
+		s.fill(elem, depth)
+		m := reflect.MakeMap(v.Type())
+		m.SetMapIndex(key, elem)
+		v.Set(m)
+	case reflect.Ptr:
+		if depth >= maxSampleDepth {
+			return
+		}
+		p := reflect.New(v.Type().Elem())
+		s.fill(p.Elem(), depth+1)
+		v.Set(p)
+	case reflect.Interface:
+		if v.Type() == publicKeyType {
+			v.Set(reflect.ValueOf(sdkTesting.Alice.Signer.Public()))
+		}
+		// Other interfaces have no known concrete type and are left empty.
+	case reflect.Struct:
+		union := isUnion(v.Type())
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" || isSkipped(field) {
+				continue
+			}
+			s.fill(v.Field(i), depth)
+			if union {
+				// Only one variant of a union may be set.
+				return
+			}
+		}
+	}
+}
+
+// isUnion checks whether the given struct type is a union of variants, like
+// types.SignatureAddressSpec, which is a struct with multiple optional pointer fields.
+func isUnion(t reflect.Type) bool {
+	var n int
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || isSkipped(field) {
+			continue
+		}
+		tag, ok := field.Tag.Lookup("cbor")
+		if !ok {
+			tag = field.Tag.Get("json")
+		}
+		if field.Type.Kind() != reflect.Ptr || !strings.HasSuffix(tag, ",omitempty") {
+			return false
+		}
+		n++
+	}
+	return n > 1
+}
+
+// isSkipped checks whether the given struct field is not serialized. As in the CBOR encoder, JSON
+// tags apply to fields without CBOR tags.
+func isSkipped(field reflect.StructField) bool {
+	tag, ok := field.Tag.Lookup("cbor")
+	if !ok {
+		tag = field.Tag.Get("json")
+	}
+	return tag == "-"
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// Vector kinds.
+const (
+	KindCallBody    = "call_body"
+	KindQueryBody   = "query_body"
+	KindResult      = "result"
+	KindEvent       = "event"
+	KindTransaction = "transaction"
+)
+
+const (
+	// fixturesVersion is the version of the fixtures format.
+	fixturesVersion = 1
+
+	// sampleRuntimeID is the identifier of the runtime transactions are signed for.
+	sampleRuntimeID = "8000000000000000000000000000000000000000000000000000000000000000"
+	// sampleConsensusChainContext is the consensus chain context transactions are signed for.
+	sampleConsensusChainContext = "b11b369e0da5bb230b220127f5e7b242d385ef8c6f54906243f30af63c815535"
+)
+
+// Fixtures are the exported wire compatibility vectors.
+type Fixtures struct {
+	// Version is the version of the fixtures format.
+	Version uint16 `json:"version"`
+
+	// RuntimeID is the runtime identifier transactions are signed for.
+	RuntimeID string `json:"runtime_id"`
+	// ChainContext is the chain domain separation context transactions are signed for.
+	ChainContext string `json:"chain_context"`
+
+	// Vectors are the vectors, ordered by module and then in the order the module's methods and
+	// events are registered.
+	Vectors []*Vector `json:"vectors"`
+}
+
+// Vector is the canonical encoding of a sample value of a registered type.
+type Vector struct {
+	// Kind is the kind of the encoded value.
+	Kind string `json:"kind"`
+	// Module is the module the type belongs to.
+	Module string `json:"module"`
+	// Name is the method name for bodies, results and transactions, and the event name for events.
+	Name string `json:"name"`
+	// Code is the event code for events.
+	Code *uint32 `json:"code,omitempty"`
+
+	// Value is the JSON representation of the sample value, for reference. For transactions, it
+	// is the representation of the transaction that is signed.
+	Value interface{} `json:"value"`
+	// Encoded is the hex-encoded canonical CBOR encoding of the sample value.
+	Encoded string `json:"encoded"`
+}
+
+// newVector creates a new vector for the given value, checking that its encoding round-trips.
+func newVector(kind, module, name string, value interface{}) (*Vector, error) {
+	encoded := cbor.Marshal(value)
+	decoded := reflect.New(reflect.TypeOf(value).Elem())
+	if err := cbor.Unmarshal(encoded, decoded.Interface()); err != nil {
+		return nil, fmt.Errorf("%s %s: failed to decode sample: %w", kind, name, err)
+	}
+	if !bytes.Equal(cbor.Marshal(decoded.Interface()), encoded) {
+		return nil, fmt.Errorf("%s %s: sample encoding does not round-trip", kind, name)
+	}
+	return &Vector{
+		Kind:    kind,
+		Module:  module,
+		Name:    name,
+		Value:   value,
+		Encoded: hex.EncodeToString(encoded),
+	}, nil
+}
+
+// sampleTransaction returns a sample transaction calling the given method with the given body,
+// together with its version signed by Alice.
+func sampleTransaction(chainContext signature.Context, method string, body interface{}) (*types.Transaction, *types.UnverifiedTransaction, error) {
+	tx := types.NewTransaction(&types.Fee{
+		Amount: types.NewBaseUnits(*quantity.NewFromUint64(100), types.NativeDenomination),
+		Gas:    1000,
+	}, method, body)
+	tx.AppendAuthSignature(sdkTesting.Alice.SigSpec, 1)
+
+	ts := tx.PrepareForSigning()
+	if err := ts.AppendSign(chainContext, sdkTesting.Alice.Signer); err != nil {
+		return nil, nil, fmt.Errorf("transaction %s: failed to sign: %w", method, err)
+	}
+	return tx, ts.UnverifiedTransaction(), nil
+}
+
+// Generate generates the vectors of all registered modules.
+func Generate() (*Fixtures, error) {
+	var runtimeID common.Namespace
+	if err := runtimeID.UnmarshalHex(sampleRuntimeID); err != nil {
+		return nil, err
+	}
+	chainContext := signature.DeriveChainContext(runtimeID, sampleConsensusChainContext)
+	fixtures := &Fixtures{
+		Version:      fixturesVersion,
+		RuntimeID:    sampleRuntimeID,
+		ChainContext: string(chainContext),
+	}
+
+	add := func(kind, module, name string, example interface{}) (interface{}, error) {
+		// Every sample is generated from scratch so that it does not depend on other samples.
+		value := (&sampler{}).sample(example)
+		v, err := newVector(kind, module, name, value)
+		if err != nil {
+			return nil, err
+		}
+		fixtures.Vectors = append(fixtures.Vectors, v)
+		return value, nil
+	}
+
+	for _, m := range registry.Modules() {
+		for _, method := range m.Methods {
+			var (
+				body interface{}
+				err  error
+			)
+			if method.Body != nil {
+				kind := KindCallBody
+				if method.Kind == registry.MethodKindQuery {
+					kind = KindQueryBody
+				}
+				if body, err = add(kind, m.Name, method.Name, method.Body); err != nil {
+					return nil, err
+				}
+			}
+			if method.Result != nil {
+				if _, err = add(KindResult, m.Name, method.Name, method.Result); err != nil {
+					return nil, err
+				}
+			}
+			if method.Kind != registry.MethodKindCall {
+				continue
+			}
+
+			tx, utx, err := sampleTransaction(chainContext, method.Name, body)
+			if err != nil {
+				return nil, err
+			}
+			v, err := newVector(KindTransaction, m.Name, method.Name, utx)
+			if err != nil {
+				return nil, err
+			}
+			v.Value = tx
+			fixtures.Vectors = append(fixtures.Vectors, v)
+		}
+
+		for _, ev := range m.Events {
+			if _, err := add(KindEvent, m.Name, ev.Name, ev.Value); err != nil {
+				return nil, err
+			}
+			code := ev.Code
+			fixtures.Vectors[len(fixtures.Vectors)-1].Code = &code
+		}
+	}
+	return fixtures, nil
+}
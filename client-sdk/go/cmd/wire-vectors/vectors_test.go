@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+)
+
+func TestGenerate(t *testing.T) {
+	require := require.New(t)
+
+	fixtures, err := Generate()
+	require.NoError(err, "Generate")
+	again, err := Generate()
+	require.NoError(err, "Generate")
+	require.Equal(fixtures, again, "vectors should be deterministic")
+
+	// Every registered type should be covered.
+	covered := make(map[string]bool)
+	for _, v := range fixtures.Vectors {
+		covered[v.Kind+"/"+v.Name] = true
+	}
+	for _, m := range registry.Modules() {
+		for _, method := range m.Methods {
+			if method.Body != nil {
+				require.True(covered[KindCallBody+"/"+method.Name] || covered[KindQueryBody+"/"+method.Name], "body of %s", method.Name)
+			}
+			if method.Result != nil {
+				require.True(covered[KindResult+"/"+method.Name], "result of %s", method.Name)
+			}
+			if method.Kind == registry.MethodKindCall {
+				require.True(covered[KindTransaction+"/"+method.Name], "transaction of %s", method.Name)
+			}
+		}
+		for _, ev := range m.Events {
+			require.True(covered[KindEvent+"/"+ev.Name], "event %s of %s", ev.Name, m.Name)
+		}
+	}
+
+	// Encodings should match the exported fixtures, unless the wire format has changed on purpose,
+	// in which case the fixtures need to be regenerated with:
+	//
+	//   go run ./cmd/wire-vectors -o cmd/wire-vectors/testdata/vectors.json
+	data, err := json.MarshalIndent(fixtures, "", "  ")
+	require.NoError(err, "MarshalIndent")
+	exported, err := ioutil.ReadFile("testdata/vectors.json")
+	require.NoError(err, "ReadFile")
+	require.JSONEq(string(exported), string(data), "exported fixtures should be up to date")
+}
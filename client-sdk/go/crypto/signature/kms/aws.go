@@ -0,0 +1,187 @@
+package kms
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	awsService        = "kms"
+	awsContentType    = "application/x-amz-json-1.1"
+	awsTimeFormat     = "20060102T150405Z"
+	awsKeySpecSecp256 = "ECC_SECG_P256K1"
+)
+
+// AWSConfig is the configuration of an AWS KMS key.
+type AWSConfig struct {
+	// Region is the AWS region of the key, e.g. "eu-west-1".
+	Region string
+	// KeyID is the identifier, ARN or alias of the key. The key must have the ECC_SECG_P256K1
+	// key spec and the SIGN_VERIFY key usage.
+	KeyID string
+
+	// AccessKeyID is the access key identifier of the credentials.
+	AccessKeyID string
+	// SecretAccessKey is the secret access key of the credentials.
+	SecretAccessKey string
+	// SessionToken is the session token of temporary credentials, if any.
+	SessionToken string
+
+	// Endpoint is the URL of the service. If empty, the regional endpoint is used.
+	Endpoint string
+	// Client is the HTTP client used for requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+type awsBackend struct {
+	cfg AWSConfig
+}
+
+type awsGetPublicKeyRequest struct {
+	KeyID string `json:"KeyId"`
+}
+
+type awsGetPublicKeyResponse struct {
+	KeySpec   string `json:"KeySpec"`
+	PublicKey []byte `json:"PublicKey"`
+}
+
+type awsSignRequest struct {
+	KeyID            string `json:"KeyId"`
+	Message          []byte `json:"Message"`
+	MessageType      string `json:"MessageType"`
+	SigningAlgorithm string `json:"SigningAlgorithm"`
+}
+
+type awsSignResponse struct {
+	Signature []byte `json:"Signature"`
+}
+
+func (b *awsBackend) PublicKey(ctx context.Context) (string, []byte, error) {
+	var rsp awsGetPublicKeyResponse
+	if err := b.call(ctx, "GetPublicKey", &awsGetPublicKeyRequest{KeyID: b.cfg.KeyID}, &rsp); err != nil {
+		return "", nil, err
+	}
+	if rsp.KeySpec != awsKeySpecSecp256 {
+		return "", nil, fmt.Errorf("kms: unsupported key spec '%s'", rsp.KeySpec)
+	}
+	return parsePublicKey(rsp.PublicKey)
+}
+
+func (b *awsBackend) Sign(ctx context.Context, algorithm string, data []byte) ([]byte, error) {
+	// The prepared message is already a 32-byte hash, so it is signed as a digest.
+	var rsp awsSignResponse
+	if err := b.call(ctx, "Sign", &awsSignRequest{
+		KeyID:            b.cfg.KeyID,
+		Message:          data,
+		MessageType:      "DIGEST",
+		SigningAlgorithm: "ECDSA_SHA_256",
+	}, &rsp); err != nil {
+		return nil, err
+	}
+	return rsp.Signature, nil
+}
+
+func (b *awsBackend) String() string {
+	return "aws/" + b.cfg.Region + "/" + b.cfg.KeyID
+}
+
+// call calls the given action of the service.
+func (b *awsBackend) call(ctx context.Context, action string, body, rsp interface{}) error {
+	req, data, err := newJSONRequest(ctx, http.MethodPost, b.cfg.Endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", awsContentType)
+	req.Header.Set("X-Amz-Target", "TrentService."+action)
+	if b.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", b.cfg.SessionToken)
+	}
+	signV4(req, data, b.cfg.AccessKeyID, b.cfg.SecretAccessKey, b.cfg.Region, awsService, time.Now())
+
+	return doJSON(b.cfg.Client, req, rsp)
+}
+
+// signV4 signs the given request with the given body using the AWS Signature Version 4 scheme.
+// All headers of the request are signed.
+func signV4(req *http.Request, body []byte, accessKeyID, secretAccessKey, region, service string, now time.Time) {
+	amzDate := now.UTC().Format(awsTimeFormat)
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + secretAccessKey)
+	for _, part := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	sig := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, sig,
+	))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// NewAWSBackend creates a new backend using a Secp256k1 key of AWS KMS.
+func NewAWSBackend(cfg AWSConfig) (Backend, error) {
+	switch {
+	case cfg.Region == "":
+		return nil, fmt.Errorf("kms: no AWS region configured")
+	case cfg.KeyID == "":
+		return nil, fmt.Errorf("kms: no AWS key configured")
+	case cfg.AccessKeyID == "" || cfg.SecretAccessKey == "":
+		return nil, fmt.Errorf("kms: no AWS credentials configured")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://kms." + cfg.Region + ".amazonaws.com/"
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	return &awsBackend{cfg: cfg}, nil
+}
@@ -0,0 +1,119 @@
+package kms
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultGCPEndpoint is the URL of the Google Cloud KMS API.
+const DefaultGCPEndpoint = "https://cloudkms.googleapis.com"
+
+// Algorithms of Google Cloud KMS keys supported by the signer.
+const (
+	gcpAlgorithmSecp256k1 = "EC_SIGN_SECP256K1_SHA256"
+	gcpAlgorithmEd25519   = "EC_SIGN_ED25519"
+)
+
+// GCPConfig is the configuration of a Google Cloud KMS key.
+type GCPConfig struct {
+	// KeyVersion is the resource name of the key version, e.g.
+	// "projects/p/locations/l/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1". The key must have the
+	// EC_SIGN_SECP256K1_SHA256 or the EC_SIGN_ED25519 algorithm.
+	KeyVersion string
+
+	// Endpoint is the URL of the API. If empty, DefaultGCPEndpoint is used.
+	Endpoint string
+	// Client is the HTTP client used for requests, which must be authorized to use the key, e.g.
+	// one created with golang.org/x/oauth2/google.DefaultClient.
+	Client *http.Client
+}
+
+type gcpBackend struct {
+	cfg GCPConfig
+}
+
+type gcpPublicKeyResponse struct {
+	PEM       string `json:"pem"`
+	Algorithm string `json:"algorithm"`
+}
+
+type gcpDigest struct {
+	SHA256 []byte `json:"sha256"`
+}
+
+type gcpSignRequest struct {
+	Digest *gcpDigest `json:"digest,omitempty"`
+	Data   []byte     `json:"data,omitempty"`
+}
+
+type gcpSignResponse struct {
+	Signature []byte `json:"signature"`
+}
+
+func (b *gcpBackend) PublicKey(ctx context.Context) (string, []byte, error) {
+	req, _, err := newJSONRequest(ctx, http.MethodGet, b.url("/publicKey"), nil)
+	if err != nil {
+		return "", nil, err
+	}
+	var rsp gcpPublicKeyResponse
+	if err = doJSON(b.cfg.Client, req, &rsp); err != nil {
+		return "", nil, err
+	}
+	switch rsp.Algorithm {
+	case gcpAlgorithmSecp256k1, gcpAlgorithmEd25519:
+	default:
+		return "", nil, fmt.Errorf("kms: unsupported key algorithm '%s'", rsp.Algorithm)
+	}
+
+	block, _ := pem.Decode([]byte(rsp.PEM))
+	if block == nil {
+		return "", nil, fmt.Errorf("kms: malformed public key")
+	}
+	return parsePublicKey(block.Bytes)
+}
+
+func (b *gcpBackend) Sign(ctx context.Context, algorithm string, data []byte) ([]byte, error) {
+	var body gcpSignRequest
+	switch algorithm {
+	case AlgorithmSecp256k1:
+		// The prepared message is already a 32-byte hash, so it is signed as a digest.
+		body.Digest = &gcpDigest{SHA256: data}
+	default:
+		body.Data = data
+	}
+	req, _, err := newJSONRequest(ctx, http.MethodPost, b.url(":asymmetricSign"), &body)
+	if err != nil {
+		return nil, err
+	}
+	var rsp gcpSignResponse
+	if err = doJSON(b.cfg.Client, req, &rsp); err != nil {
+		return nil, err
+	}
+	return rsp.Signature, nil
+}
+
+func (b *gcpBackend) String() string {
+	return "gcp/" + b.cfg.KeyVersion
+}
+
+func (b *gcpBackend) url(suffix string) string {
+	return b.cfg.Endpoint + "/v1/" + b.cfg.KeyVersion + suffix
+}
+
+// NewGCPBackend creates a new backend using a key version of Google Cloud KMS.
+func NewGCPBackend(cfg GCPConfig) (Backend, error) {
+	switch {
+	case cfg.KeyVersion == "":
+		return nil, fmt.Errorf("kms: no GCP key version configured")
+	case cfg.Client == nil:
+		return nil, fmt.Errorf("kms: no authorized GCP client configured")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = DefaultGCPEndpoint
+	}
+	cfg.Endpoint = strings.TrimSuffix(cfg.Endpoint, "/")
+	return &gcpBackend{cfg: cfg}, nil
+}
@@ -0,0 +1,230 @@
+// Package kms implements signers delegating signing to remote key management services, for
+// services that must not hold private keys locally.
+//
+// The supported services are AWS KMS and Google Cloud KMS, with Secp256k1 keys, as well as the
+// transit secrets engine of HashiCorp Vault, with Ed25519 keys. Google Cloud KMS also supports
+// Ed25519 keys. Each service is accessed via its HTTP API, so no vendor SDKs are required.
+//
+// The public key of a signer is fetched once when the signer is created, and every signature
+// returned by the service is verified against it, so that a rotated or replaced key cannot
+// silently produce signatures of a different account.
+package kms
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	coreSignature "github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/secp256k1"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const (
+	// AlgorithmEd25519 is the algorithm of Ed25519 keys.
+	AlgorithmEd25519 = "ed25519"
+	// AlgorithmSecp256k1 is the algorithm of Secp256k1 keys.
+	AlgorithmSecp256k1 = "secp256k1"
+
+	// DefaultTimeout is the default timeout of requests to the service.
+	DefaultTimeout = 30 * time.Second
+
+	// maxErrorBodySize is the maximum size of an error response body included in errors.
+	maxErrorBodySize = 1024
+)
+
+var (
+	oidPublicKeyEd25519 = asn1.ObjectIdentifier{1, 3, 101, 112}
+	oidPublicKeyECDSA   = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidSecp256k1        = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+// Backend is a key management service holding a single signing key.
+type Backend interface {
+	// PublicKey returns the algorithm and the public key of the key, encoded as with
+	// MarshalBinary of the public key types of the algorithm.
+	PublicKey(ctx context.Context) (string, []byte, error)
+
+	// Sign signs the given prepared message, which is the hash of the signature context and the
+	// message in case of Secp256k1 keys, and returns the signature.
+	Sign(ctx context.Context, algorithm string, data []byte) ([]byte, error)
+
+	// String returns the name of the key, which must not include any sensitive information.
+	String() string
+}
+
+// Signer is a signer delegating signing to a key management service.
+type Signer struct {
+	backend   Backend
+	timeout   time.Duration
+	algorithm string
+	publicKey signature.PublicKey
+	spec      types.SignatureAddressSpec
+}
+
+// Public returns the public key of the signer.
+func (s *Signer) Public() signature.PublicKey {
+	return s.publicKey
+}
+
+// AddressSpec returns the address specification of the key of the signer.
+func (s *Signer) AddressSpec() types.SignatureAddressSpec {
+	return s.spec
+}
+
+// Address returns the address of the account of the key of the signer.
+func (s *Signer) Address() types.Address {
+	return types.NewAddress(s.spec)
+}
+
+// ContextSign requests a signature over the context and message from the service.
+func (s *Signer) ContextSign(sigCtx, message []byte) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	switch s.algorithm {
+	case AlgorithmEd25519:
+		data, err = coreSignature.PrepareSignerMessage(coreSignature.Context(sigCtx), message)
+	case AlgorithmSecp256k1:
+		data, err = secp256k1.PrepareSignerMessage(signature.Context(sigCtx), message)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	sig, err := s.backend.Sign(ctx, s.algorithm, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.algorithm == AlgorithmSecp256k1 {
+		// Services may return signatures with a high S value, which are not accepted.
+		parsed, err := btcec.ParseDERSignature(sig, btcec.S256())
+		if err != nil {
+			return nil, fmt.Errorf("kms: malformed signature: %w", err)
+		}
+		sig = parsed.Serialize()
+	}
+	if !s.publicKey.Verify(sigCtx, message, sig) {
+		return nil, fmt.Errorf("kms: invalid signature from %s", s.backend)
+	}
+	return sig, nil
+}
+
+// String returns the string representation of the signer.
+func (s *Signer) String() string {
+	return "kms:" + s.backend.String()
+}
+
+// Reset does nothing, as the private key is not held locally.
+func (s *Signer) Reset() {}
+
+// NewSigner creates a new signer using the key of the given service, whose public key is fetched
+// and cached. A zero timeout means DefaultTimeout.
+func NewSigner(ctx context.Context, backend Backend, timeout time.Duration) (*Signer, error) {
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	algorithm, rawPk, err := backend.PublicKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Signer{backend: backend, timeout: timeout, algorithm: algorithm}
+	switch algorithm {
+	case AlgorithmEd25519:
+		var pk ed25519.PublicKey
+		if err = pk.UnmarshalBinary(rawPk); err != nil {
+			return nil, fmt.Errorf("kms: malformed public key: %w", err)
+		}
+		s.publicKey, s.spec = pk, types.NewSignatureAddressSpecEd25519(pk)
+	case AlgorithmSecp256k1:
+		var pk secp256k1.PublicKey
+		if err = pk.UnmarshalBinary(rawPk); err != nil {
+			return nil, fmt.Errorf("kms: malformed public key: %w", err)
+		}
+		s.publicKey, s.spec = pk, types.NewSignatureAddressSpecSecp256k1Eth(pk)
+	default:
+		return nil, fmt.Errorf("kms: unsupported algorithm '%s'", algorithm)
+	}
+	return s, nil
+}
+
+// subjectPublicKeyInfo is a DER-encoded public key, as returned by the services.
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// parsePublicKey parses a DER-encoded public key of a supported algorithm. The standard library
+// does not support keys of the Secp256k1 curve.
+func parsePublicKey(der []byte) (string, []byte, error) {
+	var spki subjectPublicKeyInfo
+	if rest, err := asn1.Unmarshal(der, &spki); err != nil || len(rest) > 0 {
+		return "", nil, fmt.Errorf("kms: malformed public key")
+	}
+	switch {
+	case spki.Algorithm.Algorithm.Equal(oidPublicKeyEd25519):
+		return AlgorithmEd25519, spki.PublicKey.RightAlign(), nil
+	case spki.Algorithm.Algorithm.Equal(oidPublicKeyECDSA):
+		var curve asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(spki.Algorithm.Parameters.FullBytes, &curve); err != nil || !curve.Equal(oidSecp256k1) {
+			return "", nil, fmt.Errorf("kms: unsupported curve")
+		}
+		return AlgorithmSecp256k1, spki.PublicKey.RightAlign(), nil
+	default:
+		return "", nil, fmt.Errorf("kms: unsupported public key algorithm %s", spki.Algorithm.Algorithm)
+	}
+}
+
+// doJSON sends the given request and decodes the JSON-encoded response body into rsp.
+func doJSON(client *http.Client, req *http.Request, rsp interface{}) error {
+	httpRsp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("kms: request failed: %w", err)
+	}
+	defer httpRsp.Body.Close()
+
+	if httpRsp.StatusCode < 200 || httpRsp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(httpRsp.Body, maxErrorBodySize))
+		return fmt.Errorf("kms: request failed with status %s: %s", httpRsp.Status, bytes.TrimSpace(msg))
+	}
+	if err = json.NewDecoder(httpRsp.Body).Decode(rsp); err != nil {
+		return fmt.Errorf("kms: malformed response: %w", err)
+	}
+	return nil
+}
+
+// newJSONRequest creates a new request with the given body, if not nil, encoded as JSON. It also
+// returns the encoded body.
+func newJSONRequest(ctx context.Context, method, url string, body interface{}) (*http.Request, []byte, error) {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return nil, nil, fmt.Errorf("kms: failed to encode request: %w", err)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("kms: failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, data, nil
+}
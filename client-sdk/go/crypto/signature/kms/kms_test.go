@@ -0,0 +1,222 @@
+package kms
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+
+	sdkEd25519 "github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/secp256k1"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+var testContext = []byte("oasis-runtime-sdk/tx: v0 for chain test")
+
+// testSign checks that the signer produces valid signatures, unless the service signs with
+// another key.
+func testSign(t *testing.T, signer *Signer, otherKey *bool) {
+	require := require.New(t)
+
+	sig, err := signer.ContextSign(testContext, []byte("message"))
+	require.NoError(err, "ContextSign")
+	require.True(signer.Public().Verify(testContext, []byte("message"), sig), "signature should be valid")
+
+	*otherKey = true
+	_, err = signer.ContextSign(testContext, []byte("message"))
+	require.Error(err, "signature of another key should be rejected")
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestSignV4(t *testing.T) {
+	// The get-vanilla case of the AWS Signature Version 4 test suite.
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	require.NoError(t, err)
+	now, _ := time.Parse(awsTimeFormat, "20150830T123600Z")
+	signV4(req, nil, "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "us-east-1", "service", now)
+	require.Equal(t,
+		"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+			"SignedHeaders=host;x-amz-date, "+
+			"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		req.Header.Get("Authorization"),
+	)
+}
+
+func TestAWS(t *testing.T) {
+	require := require.New(t)
+
+	key, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(err, "NewPrivateKey")
+	otherKey, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(err, "NewPrivateKey")
+	curve, _ := asn1.Marshal(oidSecp256k1)
+	spki, err := asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidPublicKeyECDSA, Parameters: asn1.RawValue{FullBytes: curve}},
+		PublicKey: asn1.BitString{Bytes: key.PubKey().SerializeUncompressed(), BitLength: 65 * 8},
+	})
+	require.NoError(err, "Marshal")
+
+	var useOtherKey bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			http.Error(w, "unauthorized", http.StatusForbidden)
+			return
+		}
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.GetPublicKey":
+			writeJSON(w, &awsGetPublicKeyResponse{KeySpec: awsKeySpecSecp256, PublicKey: spki})
+		case "TrentService.Sign":
+			var req awsSignRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			signKey := key
+			if useOtherKey {
+				signKey = otherKey
+			}
+			sig, _ := signKey.Sign(req.Message)
+			// Return the signature with a high S value, as KMS may do.
+			highS := new(big.Int).Sub(btcec.S256().N, sig.S)
+			der, _ := asn1.Marshal(struct{ R, S *big.Int }{sig.R, highS})
+			writeJSON(w, &awsSignResponse{Signature: der})
+		default:
+			http.Error(w, "unknown action", http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	backend, err := NewAWSBackend(AWSConfig{
+		Region:          "eu-west-1",
+		KeyID:           "alias/test",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		Endpoint:        srv.URL,
+	})
+	require.NoError(err, "NewAWSBackend")
+	signer, err := NewSigner(context.Background(), backend, 0)
+	require.NoError(err, "NewSigner")
+	require.Equal("kms:aws/eu-west-1/alias/test", signer.String())
+	pk := secp256k1.PublicKey(*key.PubKey())
+	require.Equal(types.NewAddress(types.NewSignatureAddressSpecSecp256k1Eth(pk)), signer.Address())
+	testSign(t, signer, &useOtherKey)
+
+	_, err = NewAWSBackend(AWSConfig{Region: "eu-west-1", KeyID: "alias/test"})
+	require.Error(err, "backend without credentials")
+}
+
+func TestGCP(t *testing.T) {
+	require := require.New(t)
+
+	pk, key, err := ed25519.GenerateKey(nil)
+	require.NoError(err, "GenerateKey")
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	require.NoError(err, "GenerateKey")
+	der, err := x509.MarshalPKIXPublicKey(pk)
+	require.NoError(err, "MarshalPKIXPublicKey")
+
+	const keyVersion = "projects/p/locations/l/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1"
+	var useOtherKey bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/" + keyVersion + "/publicKey":
+			writeJSON(w, &gcpPublicKeyResponse{
+				PEM:       string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+				Algorithm: gcpAlgorithmEd25519,
+			})
+		case "/v1/" + keyVersion + ":asymmetricSign":
+			var req gcpSignRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			signKey := key
+			if useOtherKey {
+				signKey = otherKey
+			}
+			writeJSON(w, &gcpSignResponse{Signature: ed25519.Sign(signKey, req.Data)})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	backend, err := NewGCPBackend(GCPConfig{KeyVersion: keyVersion, Endpoint: srv.URL, Client: srv.Client()})
+	require.NoError(err, "NewGCPBackend")
+	signer, err := NewSigner(context.Background(), backend, 0)
+	require.NoError(err, "NewSigner")
+	var sdkPk sdkEd25519.PublicKey
+	require.NoError(sdkPk.UnmarshalBinary(pk))
+	require.Equal(types.NewAddress(types.NewSignatureAddressSpecEd25519(sdkPk)), signer.Address())
+	testSign(t, signer, &useOtherKey)
+
+	_, err = NewGCPBackend(GCPConfig{KeyVersion: keyVersion})
+	require.Error(err, "backend without a client")
+}
+
+func TestVault(t *testing.T) {
+	require := require.New(t)
+
+	pk, key, err := ed25519.GenerateKey(nil)
+	require.NoError(err, "GenerateKey")
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	require.NoError(err, "GenerateKey")
+
+	var useOtherKey bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/transit/keys/oasis":
+			var rsp vaultKeyResponse
+			rsp.Data.Type = "ed25519"
+			rsp.Data.LatestVersion = 2
+			rsp.Data.Keys = map[string]struct {
+				PublicKey string `json:"public_key"`
+			}{"2": {PublicKey: base64.StdEncoding.EncodeToString(pk)}}
+			writeJSON(w, &rsp)
+		case "/v1/transit/sign/oasis":
+			var req vaultSignRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.KeyVersion != 2 {
+				http.Error(w, "wrong key version", http.StatusBadRequest)
+				return
+			}
+			signKey := key
+			if useOtherKey {
+				signKey = otherKey
+			}
+			var rsp vaultSignResponse
+			rsp.Data.Signature = "vault:v2:" + base64.StdEncoding.EncodeToString(ed25519.Sign(signKey, req.Input))
+			writeJSON(w, &rsp)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	backend, err := NewVaultBackend(VaultConfig{Address: srv.URL + "/", Token: "token", Key: "oasis"})
+	require.NoError(err, "NewVaultBackend")
+	signer, err := NewSigner(context.Background(), backend, 0)
+	require.NoError(err, "NewSigner")
+	require.Equal("kms:vault/transit/oasis", signer.String())
+	testSign(t, signer, &useOtherKey)
+
+	backend, err = NewVaultBackend(VaultConfig{Address: srv.URL, Token: "wrong", Key: "oasis"})
+	require.NoError(err, "NewVaultBackend")
+	_, err = NewSigner(context.Background(), backend, 0)
+	require.Error(err, "request with a wrong token")
+}
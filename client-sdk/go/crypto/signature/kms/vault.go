@@ -0,0 +1,147 @@
+package kms
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultVaultMount is the default mount path of the transit secrets engine.
+const DefaultVaultMount = "transit"
+
+// VaultConfig is the configuration of a HashiCorp Vault transit key.
+type VaultConfig struct {
+	// Address is the URL of the Vault server, e.g. "https://vault.example.com:8200".
+	Address string
+	// Token is the Vault token used for requests.
+	Token string
+	// Namespace is the Vault Enterprise namespace of the key, if any.
+	Namespace string
+
+	// Mount is the mount path of the transit secrets engine. If empty, DefaultVaultMount is used.
+	Mount string
+	// Key is the name of the key, which must be of the ed25519 type.
+	Key string
+
+	// Client is the HTTP client used for requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+type vaultBackend struct {
+	cfg VaultConfig
+
+	// version is the version of the key whose public key is cached, which is used for all
+	// signatures, so that signatures are not affected by rotations of the key.
+	version int
+}
+
+type vaultKeyResponse struct {
+	Data struct {
+		Type          string `json:"type"`
+		LatestVersion int    `json:"latest_version"`
+		Keys          map[string]struct {
+			PublicKey string `json:"public_key"`
+		} `json:"keys"`
+	} `json:"data"`
+}
+
+type vaultSignRequest struct {
+	Input      []byte `json:"input"`
+	KeyVersion int    `json:"key_version"`
+}
+
+type vaultSignResponse struct {
+	Data struct {
+		Signature string `json:"signature"`
+	} `json:"data"`
+}
+
+func (b *vaultBackend) PublicKey(ctx context.Context) (string, []byte, error) {
+	req, err := b.newRequest(ctx, http.MethodGet, "/keys/", nil)
+	if err != nil {
+		return "", nil, err
+	}
+	var rsp vaultKeyResponse
+	if err = doJSON(b.cfg.Client, req, &rsp); err != nil {
+		return "", nil, err
+	}
+	if rsp.Data.Type != "ed25519" {
+		return "", nil, fmt.Errorf("kms: unsupported key type '%s'", rsp.Data.Type)
+	}
+
+	key, ok := rsp.Data.Keys[strconv.Itoa(rsp.Data.LatestVersion)]
+	if !ok {
+		return "", nil, fmt.Errorf("kms: latest key version missing")
+	}
+	pk, err := base64.StdEncoding.DecodeString(key.PublicKey)
+	if err != nil {
+		return "", nil, fmt.Errorf("kms: malformed public key: %w", err)
+	}
+	b.version = rsp.Data.LatestVersion
+	return AlgorithmEd25519, pk, nil
+}
+
+func (b *vaultBackend) Sign(ctx context.Context, algorithm string, data []byte) ([]byte, error) {
+	req, err := b.newRequest(ctx, http.MethodPost, "/sign/", &vaultSignRequest{
+		Input:      data,
+		KeyVersion: b.version,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rsp vaultSignResponse
+	if err = doJSON(b.cfg.Client, req, &rsp); err != nil {
+		return nil, err
+	}
+
+	// Signatures have the form "vault:v<version>:<base64 signature>".
+	parts := strings.Split(rsp.Data.Signature, ":")
+	if len(parts) != 3 || parts[0] != "vault" {
+		return nil, fmt.Errorf("kms: malformed signature")
+	}
+	sig, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("kms: malformed signature: %w", err)
+	}
+	return sig, nil
+}
+
+func (b *vaultBackend) String() string {
+	return "vault/" + b.cfg.Mount + "/" + b.cfg.Key
+}
+
+func (b *vaultBackend) newRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	req, _, err := newJSONRequest(ctx, method, b.cfg.Address+"/v1/"+b.cfg.Mount+path+b.cfg.Key, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", b.cfg.Token)
+	if b.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", b.cfg.Namespace)
+	}
+	return req, nil
+}
+
+// NewVaultBackend creates a new backend using an Ed25519 key of the transit secrets engine of
+// HashiCorp Vault.
+func NewVaultBackend(cfg VaultConfig) (Backend, error) {
+	switch {
+	case cfg.Address == "":
+		return nil, fmt.Errorf("kms: no Vault address configured")
+	case cfg.Token == "":
+		return nil, fmt.Errorf("kms: no Vault token configured")
+	case cfg.Key == "":
+		return nil, fmt.Errorf("kms: no Vault key configured")
+	}
+	if cfg.Mount == "" {
+		cfg.Mount = DefaultVaultMount
+	}
+	cfg.Address = strings.TrimSuffix(cfg.Address, "/")
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	return &vaultBackend{cfg: cfg}, nil
+}
@@ -0,0 +1,149 @@
+// Package pkcs11 implements a signer backed by keys stored in a PKCS#11 token, e.g. an HSM, so
+// that runtime keys never leave the token.
+//
+// Ed25519 keys are used with the CKM_EDDSA mechanism and Secp256k1 keys with the CKM_ECDSA
+// mechanism. As with the other signers, the token signs the hash of the signature context and the
+// message, so signatures are the same as those of a local signer with the same key.
+//
+// A module is loaded once per process and shared by all signers. Each signer uses its own session
+// and serializes its signing operations, so signers are safe for concurrent use.
+package pkcs11
+
+import (
+	"fmt"
+	"strings"
+
+	p11 "github.com/miekg/pkcs11"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+)
+
+const (
+	// AlgorithmEd25519 is the algorithm of Ed25519 keys.
+	AlgorithmEd25519 = "ed25519"
+	// AlgorithmSecp256k1 is the algorithm of Secp256k1 keys.
+	AlgorithmSecp256k1 = "secp256k1"
+)
+
+// Constants of PKCS#11 v3.0 missing from the bindings.
+const (
+	ckkECEdwards = 0x00000040
+	ckmEdDSA     = 0x00001057
+)
+
+// module is the part of a PKCS#11 module used by the signers.
+type module interface {
+	Finalize() error
+	Destroy()
+
+	GetSlotList(tokenPresent bool) ([]uint, error)
+	GetTokenInfo(slotID uint) (p11.TokenInfo, error)
+	OpenSession(slotID uint, flags uint) (p11.SessionHandle, error)
+	CloseSession(sh p11.SessionHandle) error
+	Login(sh p11.SessionHandle, userType uint, pin string) error
+
+	FindObjectsInit(sh p11.SessionHandle, temp []*p11.Attribute) error
+	FindObjects(sh p11.SessionHandle, max int) ([]p11.ObjectHandle, bool, error)
+	FindObjectsFinal(sh p11.SessionHandle) error
+	GetAttributeValue(sh p11.SessionHandle, o p11.ObjectHandle, a []*p11.Attribute) ([]*p11.Attribute, error)
+
+	SignInit(sh p11.SessionHandle, m []*p11.Mechanism, o p11.ObjectHandle) error
+	Sign(sh p11.SessionHandle, message []byte) ([]byte, error)
+}
+
+// Module is a loaded PKCS#11 module.
+type Module struct {
+	ctx module
+}
+
+// Close finalizes and unloads the module. Signers of the module must not be used afterwards.
+func (m *Module) Close() error {
+	defer m.ctx.Destroy()
+	if err := m.ctx.Finalize(); err != nil {
+		return fmt.Errorf("pkcs11: failed to finalize module: %w", err)
+	}
+	return nil
+}
+
+// KeyConfig selects a key of a token.
+type KeyConfig struct {
+	// TokenLabel is the label of the token holding the key.
+	TokenLabel string
+	// PIN is the user PIN of the token.
+	PIN string
+
+	// Algorithm is the algorithm of the key, AlgorithmEd25519 or AlgorithmSecp256k1.
+	Algorithm string
+	// Label is the label of the key. If empty, the key is selected by its identifier only.
+	Label string
+	// ID is the identifier of the key. If empty, the key is selected by its label only.
+	ID []byte
+}
+
+// NewSigner opens a session with the token holding the given key and returns a signer using the
+// key. The private and public key objects of the key must share the same label and identifier.
+func (m *Module) NewSigner(cfg KeyConfig) (signature.Signer, error) {
+	var keyType uint
+	switch cfg.Algorithm {
+	case AlgorithmEd25519:
+		keyType = ckkECEdwards
+	case AlgorithmSecp256k1:
+		keyType = p11.CKK_EC
+	default:
+		return nil, fmt.Errorf("pkcs11: unsupported algorithm '%s'", cfg.Algorithm)
+	}
+	if cfg.Label == "" && len(cfg.ID) == 0 {
+		return nil, fmt.Errorf("pkcs11: key without a label or identifier")
+	}
+
+	slot, err := m.findSlot(cfg.TokenLabel)
+	if err != nil {
+		return nil, err
+	}
+	session, err := m.ctx.OpenSession(slot, p11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return nil, fmt.Errorf("pkcs11: failed to open session: %w", err)
+	}
+	s := &signer{
+		ctx:       m.ctx,
+		session:   session,
+		algorithm: cfg.Algorithm,
+		name:      cfg.TokenLabel + "/" + cfg.Label,
+	}
+	if err = s.init(cfg, keyType); err != nil {
+		_ = m.ctx.CloseSession(session)
+		return nil, err
+	}
+	return s, nil
+}
+
+func (m *Module) findSlot(tokenLabel string) (uint, error) {
+	slots, err := m.ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("pkcs11: failed to list slots: %w", err)
+	}
+	for _, slot := range slots {
+		info, err := m.ctx.GetTokenInfo(slot)
+		if err != nil {
+			return 0, fmt.Errorf("pkcs11: failed to get token info: %w", err)
+		}
+		if strings.TrimSpace(info.Label) == tokenLabel {
+			return slot, nil
+		}
+	}
+	return 0, fmt.Errorf("pkcs11: token '%s' not found", tokenLabel)
+}
+
+// Open loads and initializes the PKCS#11 module at the given path, e.g. the shared library of
+// an HSM vendor or of SoftHSM.
+func Open(path string) (*Module, error) {
+	ctx := p11.New(path)
+	if ctx == nil {
+		return nil, fmt.Errorf("pkcs11: failed to load module '%s'", path)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("pkcs11: failed to initialize module: %w", err)
+	}
+	return &Module{ctx: ctx}, nil
+}
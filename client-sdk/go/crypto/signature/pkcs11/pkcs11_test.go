@@ -0,0 +1,270 @@
+package pkcs11
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/asn1"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	p11 "github.com/miekg/pkcs11"
+	"github.com/stretchr/testify/require"
+
+	sdkEd25519 "github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/secp256k1"
+)
+
+const (
+	testToken = "oasis"
+	testPIN   = "1234"
+)
+
+type testObject struct {
+	attrs map[uint][]byte
+	sign  func(data []byte) []byte
+}
+
+// testModule is a fake PKCS#11 module with a single token.
+type testModule struct {
+	sync.Mutex
+
+	objects  []*testObject
+	sessions map[p11.SessionHandle]*p11.ObjectHandle
+	found    map[p11.SessionHandle][]p11.ObjectHandle
+	loggedIn bool
+	next     p11.SessionHandle
+}
+
+func (m *testModule) addKey(label string, keyType uint, params, point []byte, sign func([]byte) []byte) {
+	attrs := func(class uint) map[uint][]byte {
+		a := make(map[uint][]byte)
+		for _, attr := range []*p11.Attribute{
+			p11.NewAttribute(p11.CKA_CLASS, class),
+			p11.NewAttribute(p11.CKA_KEY_TYPE, keyType),
+			p11.NewAttribute(p11.CKA_LABEL, label),
+			p11.NewAttribute(p11.CKA_EC_PARAMS, params),
+			p11.NewAttribute(p11.CKA_EC_POINT, point),
+		} {
+			a[attr.Type] = attr.Value
+		}
+		return a
+	}
+	m.objects = append(m.objects,
+		&testObject{attrs: attrs(p11.CKO_PRIVATE_KEY), sign: sign},
+		&testObject{attrs: attrs(p11.CKO_PUBLIC_KEY)},
+	)
+}
+
+func (m *testModule) Finalize() error { return nil }
+
+func (m *testModule) Destroy() {}
+
+func (m *testModule) GetSlotList(tokenPresent bool) ([]uint, error) {
+	return []uint{7}, nil
+}
+
+func (m *testModule) GetTokenInfo(slotID uint) (p11.TokenInfo, error) {
+	return p11.TokenInfo{Label: testToken + "   "}, nil
+}
+
+func (m *testModule) OpenSession(slotID uint, flags uint) (p11.SessionHandle, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.next++
+	m.sessions[m.next] = nil
+	return m.next, nil
+}
+
+func (m *testModule) CloseSession(sh p11.SessionHandle) error {
+	m.Lock()
+	defer m.Unlock()
+
+	delete(m.sessions, sh)
+	return nil
+}
+
+func (m *testModule) Login(sh p11.SessionHandle, userType uint, pin string) error {
+	m.Lock()
+	defer m.Unlock()
+
+	switch {
+	case pin != testPIN:
+		return p11.Error(p11.CKR_PIN_INCORRECT)
+	case m.loggedIn:
+		return p11.Error(p11.CKR_USER_ALREADY_LOGGED_IN)
+	}
+	m.loggedIn = true
+	return nil
+}
+
+func (m *testModule) FindObjectsInit(sh p11.SessionHandle, temp []*p11.Attribute) error {
+	m.Lock()
+	defer m.Unlock()
+
+	var found []p11.ObjectHandle
+	for i, obj := range m.objects {
+		matches := true
+		for _, attr := range temp {
+			if !bytes.Equal(obj.attrs[attr.Type], attr.Value) {
+				matches = false
+			}
+		}
+		if matches {
+			found = append(found, p11.ObjectHandle(i))
+		}
+	}
+	m.found[sh] = found
+	return nil
+}
+
+func (m *testModule) FindObjects(sh p11.SessionHandle, max int) ([]p11.ObjectHandle, bool, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	found := m.found[sh]
+	if len(found) > max {
+		found = found[:max]
+	}
+	return found, false, nil
+}
+
+func (m *testModule) FindObjectsFinal(sh p11.SessionHandle) error {
+	m.Lock()
+	defer m.Unlock()
+
+	delete(m.found, sh)
+	return nil
+}
+
+func (m *testModule) GetAttributeValue(sh p11.SessionHandle, o p11.ObjectHandle, a []*p11.Attribute) ([]*p11.Attribute, error) {
+	var attrs []*p11.Attribute
+	for _, attr := range a {
+		attrs = append(attrs, &p11.Attribute{Type: attr.Type, Value: m.objects[o].attrs[attr.Type]})
+	}
+	return attrs, nil
+}
+
+func (m *testModule) SignInit(sh p11.SessionHandle, mech []*p11.Mechanism, o p11.ObjectHandle) error {
+	m.Lock()
+	defer m.Unlock()
+
+	key, ok := m.sessions[sh]
+	switch {
+	case !ok:
+		return p11.Error(p11.CKR_SESSION_HANDLE_INVALID)
+	case key != nil:
+		return p11.Error(p11.CKR_OPERATION_ACTIVE)
+	}
+	m.sessions[sh] = &o
+	return nil
+}
+
+func (m *testModule) Sign(sh p11.SessionHandle, message []byte) ([]byte, error) {
+	m.Lock()
+	key := m.sessions[sh]
+	m.sessions[sh] = nil
+	m.Unlock()
+
+	if key == nil {
+		return nil, p11.Error(p11.CKR_OPERATION_NOT_INITIALIZED)
+	}
+	return m.objects[*key].sign(message), nil
+}
+
+func newTestModule(t *testing.T) *Module {
+	m := &testModule{
+		sessions: make(map[p11.SessionHandle]*p11.ObjectHandle),
+		found:    make(map[p11.SessionHandle][]p11.ObjectHandle),
+	}
+
+	_, edKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err, "GenerateKey")
+	edPoint, _ := asn1.Marshal(edKey.Public().(ed25519.PublicKey)[:])
+	edParams, _ := asn1.MarshalWithParams("edwards25519", "printable")
+	m.addKey("ed", ckkECEdwards, edParams, edPoint, func(data []byte) []byte {
+		return ed25519.Sign(edKey, data)
+	})
+
+	secpKey, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(t, err, "NewPrivateKey")
+	secpParams, _ := asn1.Marshal(oidSecp256k1)
+	// Return the point as is, as some tokens do.
+	m.addKey("secp", p11.CKK_EC, secpParams, secpKey.PubKey().SerializeUncompressed(), func(data []byte) []byte {
+		sig, _ := secpKey.Sign(data)
+		raw := make([]byte, 64)
+		sig.R.FillBytes(raw[:32])
+		sig.S.FillBytes(raw[32:])
+		return raw
+	})
+	p256Params, _ := asn1.Marshal(asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7})
+	m.addKey("p256", p11.CKK_EC, p256Params, nil, nil)
+
+	return &Module{ctx: m}
+}
+
+func TestSigner(t *testing.T) {
+	require := require.New(t)
+	m := newTestModule(t)
+	defer m.Close()
+
+	sigCtx := []byte("oasis-runtime-sdk/tx: v0 for chain test")
+	for _, tc := range []struct {
+		label     string
+		algorithm string
+	}{
+		{"ed", AlgorithmEd25519},
+		{"secp", AlgorithmSecp256k1},
+	} {
+		signer, err := m.NewSigner(KeyConfig{TokenLabel: testToken, PIN: testPIN, Algorithm: tc.algorithm, Label: tc.label})
+		require.NoError(err, "NewSigner %s", tc.label)
+		require.Equal("pkcs11:"+testToken+"/"+tc.label, signer.String())
+		switch tc.algorithm {
+		case AlgorithmEd25519:
+			require.IsType(sdkEd25519.PublicKey{}, signer.Public())
+		case AlgorithmSecp256k1:
+			require.IsType(secp256k1.PublicKey{}, signer.Public())
+		}
+
+		// Signers must be safe for concurrent use, although sessions are not.
+		var wg sync.WaitGroup
+		errCh := make(chan error, 16)
+		for i := 0; i < 16; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				msg := []byte(fmt.Sprintf("message %d", i))
+				sig, err := signer.ContextSign(sigCtx, msg)
+				switch {
+				case err != nil:
+					errCh <- err
+				case !signer.Public().Verify(sigCtx, msg, sig):
+					errCh <- fmt.Errorf("invalid signature of message %d", i)
+				}
+			}(i)
+		}
+		wg.Wait()
+		close(errCh)
+		for err := range errCh {
+			require.NoError(err, "ContextSign %s", tc.label)
+		}
+
+		signer.Reset()
+		_, err = signer.ContextSign(sigCtx, []byte("message"))
+		require.Error(err, "signing after reset")
+	}
+
+	for _, cfg := range []KeyConfig{
+		{TokenLabel: "other", PIN: testPIN, Algorithm: AlgorithmEd25519, Label: "ed"},
+		{TokenLabel: testToken, PIN: "0000", Algorithm: AlgorithmEd25519, Label: "ed"},
+		{TokenLabel: testToken, PIN: testPIN, Algorithm: AlgorithmEd25519, Label: "secp"},
+		{TokenLabel: testToken, PIN: testPIN, Algorithm: AlgorithmSecp256k1, Label: "p256"},
+		{TokenLabel: testToken, PIN: testPIN, Algorithm: "sr25519", Label: "ed"},
+		{TokenLabel: testToken, PIN: testPIN, Algorithm: AlgorithmEd25519},
+	} {
+		_, err := m.NewSigner(cfg)
+		require.Error(err, "NewSigner %+v", cfg)
+	}
+}
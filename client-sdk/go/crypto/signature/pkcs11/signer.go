@@ -0,0 +1,202 @@
+package pkcs11
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcec"
+	p11 "github.com/miekg/pkcs11"
+
+	coreSignature "github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/secp256k1"
+)
+
+// oidSecp256k1 is the object identifier of the Secp256k1 curve in the EC parameters of keys.
+var oidSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+
+// signer is a signer using a key of a token.
+type signer struct {
+	sync.Mutex
+
+	ctx       module
+	session   p11.SessionHandle
+	key       p11.ObjectHandle
+	algorithm string
+	publicKey signature.PublicKey
+	name      string
+	closed    bool
+}
+
+func (s *signer) Public() signature.PublicKey {
+	return s.publicKey
+}
+
+func (s *signer) ContextSign(context, message []byte) ([]byte, error) {
+	var (
+		data      []byte
+		mechanism uint
+		err       error
+	)
+	switch s.algorithm {
+	case AlgorithmEd25519:
+		data, err = coreSignature.PrepareSignerMessage(coreSignature.Context(context), message)
+		mechanism = ckmEdDSA
+	case AlgorithmSecp256k1:
+		data, err = secp256k1.PrepareSignerMessage(signature.Context(context), message)
+		mechanism = p11.CKM_ECDSA
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Operations of a session must not be interleaved.
+	s.Lock()
+	defer s.Unlock()
+
+	if s.closed {
+		return nil, fmt.Errorf("pkcs11: signer has been reset")
+	}
+	if err = s.ctx.SignInit(s.session, []*p11.Mechanism{p11.NewMechanism(mechanism, nil)}, s.key); err != nil {
+		return nil, fmt.Errorf("pkcs11: failed to sign: %w", err)
+	}
+	sig, err := s.ctx.Sign(s.session, data)
+	if err != nil {
+		return nil, fmt.Errorf("pkcs11: failed to sign: %w", err)
+	}
+
+	if s.algorithm == AlgorithmSecp256k1 {
+		// Tokens return the concatenated R and S values, while the other signers return the
+		// DER-encoded signature with a low S value, as produced by Serialize.
+		if len(sig) != 64 {
+			return nil, fmt.Errorf("pkcs11: malformed signature from token")
+		}
+		sig = (&btcec.Signature{
+			R: new(big.Int).SetBytes(sig[:32]),
+			S: new(big.Int).SetBytes(sig[32:]),
+		}).Serialize()
+	}
+	return sig, nil
+}
+
+func (s *signer) String() string {
+	return "pkcs11:" + s.name
+}
+
+// Reset closes the session of the signer. The key itself remains in the token.
+func (s *signer) Reset() {
+	s.Lock()
+	defer s.Unlock()
+
+	if !s.closed {
+		_ = s.ctx.CloseSession(s.session)
+		s.closed = true
+	}
+}
+
+// init logs into the token and looks up the key of the signer.
+func (s *signer) init(cfg KeyConfig, keyType uint) error {
+	// Logins are shared by all sessions of an application with a token.
+	err := s.ctx.Login(s.session, p11.CKU_USER, cfg.PIN)
+	if err != nil && err != p11.Error(p11.CKR_USER_ALREADY_LOGGED_IN) {
+		return fmt.Errorf("pkcs11: failed to log in: %w", err)
+	}
+
+	if s.key, err = s.findObject(p11.CKO_PRIVATE_KEY, keyType, cfg); err != nil {
+		return err
+	}
+	pubKey, err := s.findObject(p11.CKO_PUBLIC_KEY, keyType, cfg)
+	if err != nil {
+		return err
+	}
+	attrs, err := s.ctx.GetAttributeValue(s.session, pubKey, []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_EC_PARAMS, nil),
+		p11.NewAttribute(p11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return fmt.Errorf("pkcs11: failed to get public key: %w", err)
+	}
+	var params, point []byte
+	for _, attr := range attrs {
+		switch attr.Type {
+		case p11.CKA_EC_PARAMS:
+			params = attr.Value
+		case p11.CKA_EC_POINT:
+			point = attr.Value
+		}
+	}
+
+	switch s.algorithm {
+	case AlgorithmEd25519:
+		var pk ed25519.PublicKey
+		if err = pk.UnmarshalBinary(ecPoint(point, 32)); err != nil {
+			return fmt.Errorf("pkcs11: malformed public key: %w", err)
+		}
+		s.publicKey = pk
+	case AlgorithmSecp256k1:
+		// Keys of other curves share the key type, so check the curve.
+		var oid asn1.ObjectIdentifier
+		if _, err = asn1.Unmarshal(params, &oid); err != nil || !oid.Equal(oidSecp256k1) {
+			return fmt.Errorf("pkcs11: key is not a Secp256k1 key")
+		}
+		var pk secp256k1.PublicKey
+		if err = pk.UnmarshalBinary(ecPoint(point, 65)); err != nil {
+			return fmt.Errorf("pkcs11: malformed public key: %w", err)
+		}
+		s.publicKey = pk
+	}
+	return nil
+}
+
+// findObject returns the only key object of the given class and type matching the label and
+// identifier of the key.
+func (s *signer) findObject(class, keyType uint, cfg KeyConfig) (p11.ObjectHandle, error) {
+	template := []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_CLASS, class),
+		p11.NewAttribute(p11.CKA_KEY_TYPE, keyType),
+	}
+	if cfg.Label != "" {
+		template = append(template, p11.NewAttribute(p11.CKA_LABEL, cfg.Label))
+	}
+	if len(cfg.ID) > 0 {
+		template = append(template, p11.NewAttribute(p11.CKA_ID, cfg.ID))
+	}
+
+	if err := s.ctx.FindObjectsInit(s.session, template); err != nil {
+		return 0, fmt.Errorf("pkcs11: failed to find key: %w", err)
+	}
+	objects, _, err := s.ctx.FindObjects(s.session, 2)
+	if finalErr := s.ctx.FindObjectsFinal(s.session); err == nil {
+		err = finalErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("pkcs11: failed to find key: %w", err)
+	}
+
+	kind := "private"
+	if class == p11.CKO_PUBLIC_KEY {
+		kind = "public"
+	}
+	switch len(objects) {
+	case 0:
+		return 0, fmt.Errorf("pkcs11: %s key not found", kind)
+	case 1:
+		return objects[0], nil
+	default:
+		return 0, fmt.Errorf("pkcs11: multiple %s keys match", kind)
+	}
+}
+
+// ecPoint returns the encoded point of the given CKA_EC_POINT value, which tokens are supposed to
+// wrap in a DER-encoded octet string, but some return as is.
+func ecPoint(value []byte, size int) []byte {
+	var point []byte
+	if rest, err := asn1.Unmarshal(value, &point); err == nil && len(rest) == 0 && len(point) == size {
+		return point
+	}
+	return value
+}
@@ -0,0 +1,128 @@
+package walletconnect
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// envelopeType0 is the type of envelopes encrypted with a symmetric key known to both peers.
+	envelopeType0 = 0
+
+	// relayAuthTTL is the validity period of relay authentication tokens.
+	relayAuthTTL = 24 * time.Hour
+)
+
+// multicodecEd25519Pub is the multicodec prefix of Ed25519 public keys in did:key identifiers.
+var multicodecEd25519Pub = []byte{0xed, 0x01}
+
+// randomKey returns a new random 32-byte key.
+func randomKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("walletconnect: failed to generate key: %w", err)
+	}
+	return key, nil
+}
+
+// newKeyPair generates a new X25519 key pair used for key agreement with a wallet.
+func newKeyPair() (privateKey, publicKey []byte, err error) {
+	if privateKey, err = randomKey(); err != nil {
+		return nil, nil, err
+	}
+	if publicKey, err = curve25519.X25519(privateKey, curve25519.Basepoint); err != nil {
+		return nil, nil, fmt.Errorf("walletconnect: failed to derive public key: %w", err)
+	}
+	return privateKey, publicKey, nil
+}
+
+// deriveSymKey derives the symmetric key shared with the peer with the given X25519 public key.
+func deriveSymKey(privateKey, peerPublicKey []byte) ([]byte, error) {
+	shared, err := curve25519.X25519(privateKey, peerPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("walletconnect: key agreement failed: %w", err)
+	}
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err = io.ReadFull(hkdf.New(sha256.New, shared, nil, nil), key); err != nil {
+		return nil, fmt.Errorf("walletconnect: key derivation failed: %w", err)
+	}
+	return key, nil
+}
+
+// topicFromSymKey returns the topic of the messages encrypted with the given symmetric key.
+func topicFromSymKey(key []byte) string {
+	h := sha256.Sum256(key)
+	return hex.EncodeToString(h[:])
+}
+
+// seal encrypts the given payload into a type 0 envelope.
+func seal(key, payload []byte) (string, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return "", fmt.Errorf("walletconnect: %w", err)
+	}
+	envelope := make([]byte, 1+aead.NonceSize(), 1+aead.NonceSize()+len(payload)+aead.Overhead())
+	envelope[0] = envelopeType0
+	if _, err = io.ReadFull(rand.Reader, envelope[1:]); err != nil {
+		return "", fmt.Errorf("walletconnect: failed to generate nonce: %w", err)
+	}
+	envelope = aead.Seal(envelope, envelope[1:], payload, nil)
+	return base64.StdEncoding.EncodeToString(envelope), nil
+}
+
+// open decrypts the given type 0 envelope.
+func open(key []byte, message string) ([]byte, error) {
+	envelope, err := base64.StdEncoding.DecodeString(message)
+	if err != nil {
+		return nil, fmt.Errorf("walletconnect: malformed envelope: %w", err)
+	}
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, fmt.Errorf("walletconnect: %w", err)
+	}
+	if len(envelope) < 1+aead.NonceSize() || envelope[0] != envelopeType0 {
+		return nil, fmt.Errorf("walletconnect: unsupported envelope")
+	}
+	nonce := envelope[1 : 1+aead.NonceSize()]
+	payload, err := aead.Open(nil, nonce, envelope[1+aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("walletconnect: failed to decrypt envelope: %w", err)
+	}
+	return payload, nil
+}
+
+// didKey returns the did:key identifier of the given Ed25519 public key.
+func didKey(pk ed25519.PublicKey) string {
+	return "did:key:z" + base58.Encode(append(append([]byte{}, multicodecEd25519Pub...), pk...))
+}
+
+// relayAuthToken returns a JWT authenticating the client with the given key to the relay server.
+func relayAuthToken(key ed25519.PrivateKey, relayURL string, now time.Time) (string, error) {
+	subject, err := randomKey()
+	if err != nil {
+		return "", err
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "EdDSA", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss": didKey(key.Public().(ed25519.PublicKey)),
+		"sub": hex.EncodeToString(subject),
+		"aud": relayURL,
+		"iat": now.Unix(),
+		"exp": now.Add(relayAuthTTL).Unix(),
+	})
+	encoding := base64.RawURLEncoding
+	data := encoding.EncodeToString(header) + "." + encoding.EncodeToString(claims)
+	return data + "." + encoding.EncodeToString(ed25519.Sign(key, []byte(data))), nil
+}
@@ -0,0 +1,258 @@
+package walletconnect
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	methodRelaySubscribe    = "irn_subscribe"
+	methodRelayUnsubscribe  = "irn_unsubscribe"
+	methodRelayPublish      = "irn_publish"
+	methodRelaySubscription = "irn_subscription"
+)
+
+// Error is a JSON-RPC error, e.g. returned by the wallet when a request is rejected.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("walletconnect: request failed with code %d: %s", e.Code, e.Message)
+}
+
+// rpcMessage is a JSON-RPC request or response.
+type rpcMessage struct {
+	ID      uint64          `json:"id"`
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// lastID is the last JSON-RPC request identifier. Identifiers start at the current time in
+// milliseconds, times 1000, as is customary for WalletConnect clients.
+var lastID = uint64(time.Now().UnixNano()/int64(time.Millisecond)) * 1000
+
+func nextID() uint64 {
+	return atomic.AddUint64(&lastID, 1)
+}
+
+// newRequest creates a new JSON-RPC request.
+func newRequest(method string, params interface{}) (*rpcMessage, error) {
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("walletconnect: failed to encode request: %w", err)
+	}
+	return &rpcMessage{ID: nextID(), JSONRPC: "2.0", Method: method, Params: rawParams}, nil
+}
+
+// newResponse creates a new JSON-RPC response to the request with the given identifier.
+func newResponse(id uint64, result interface{}) (*rpcMessage, error) {
+	rawResult, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("walletconnect: failed to encode response: %w", err)
+	}
+	return &rpcMessage{ID: id, JSONRPC: "2.0", Result: rawResult}, nil
+}
+
+// result decodes the result of a response.
+func (m *rpcMessage) result(result interface{}) error {
+	if m.Error != nil {
+		return m.Error
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(m.Result, result); err != nil {
+		return fmt.Errorf("walletconnect: malformed response: %w", err)
+	}
+	return nil
+}
+
+type subscribeParams struct {
+	Topic string `json:"topic"`
+}
+
+type unsubscribeParams struct {
+	Topic string `json:"topic"`
+	ID    string `json:"id"`
+}
+
+type publishParams struct {
+	Topic   string `json:"topic"`
+	Message string `json:"message"`
+	TTL     uint64 `json:"ttl"`
+	Tag     int    `json:"tag"`
+	Prompt  bool   `json:"prompt,omitempty"`
+}
+
+type subscriptionParams struct {
+	ID   string `json:"id"`
+	Data struct {
+		Topic   string `json:"topic"`
+		Message string `json:"message"`
+	} `json:"data"`
+}
+
+// relay is a connection to a WalletConnect relay server.
+type relay struct {
+	sync.Mutex
+
+	conn      *websocket.Conn
+	writeLock sync.Mutex
+
+	pending   map[uint64]chan *rpcMessage
+	onMessage func(topic, message string)
+
+	closeOnce sync.Once
+	closeCh   chan struct{}
+	err       error
+}
+
+// call makes a JSON-RPC call to the relay server.
+func (r *relay) call(ctx context.Context, method string, params, result interface{}) error {
+	req, err := newRequest(method, params)
+	if err != nil {
+		return err
+	}
+	ch := make(chan *rpcMessage, 1)
+	r.Lock()
+	r.pending[req.ID] = ch
+	r.Unlock()
+	defer func() {
+		r.Lock()
+		delete(r.pending, req.ID)
+		r.Unlock()
+	}()
+
+	if err = r.write(req); err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-r.closeCh:
+		return r.closeErr()
+	case rsp := <-ch:
+		return rsp.result(result)
+	}
+}
+
+// subscribe subscribes to the messages published to the given topic and returns the
+// subscription identifier.
+func (r *relay) subscribe(ctx context.Context, topic string) (string, error) {
+	var id string
+	if err := r.call(ctx, methodRelaySubscribe, &subscribeParams{Topic: topic}, &id); err != nil {
+		return "", fmt.Errorf("walletconnect: failed to subscribe: %w", err)
+	}
+	return id, nil
+}
+
+// unsubscribe cancels the given subscription.
+func (r *relay) unsubscribe(ctx context.Context, topic, id string) error {
+	if err := r.call(ctx, methodRelayUnsubscribe, &unsubscribeParams{Topic: topic, ID: id}, nil); err != nil {
+		return fmt.Errorf("walletconnect: failed to unsubscribe: %w", err)
+	}
+	return nil
+}
+
+// publish publishes the given message to the given topic.
+func (r *relay) publish(ctx context.Context, topic, message string, tag int, ttl time.Duration) error {
+	params := &publishParams{
+		Topic:   topic,
+		Message: message,
+		TTL:     uint64(ttl / time.Second),
+		Tag:     tag,
+		Prompt:  tag == tagSessionRequest,
+	}
+	if err := r.call(ctx, methodRelayPublish, params, nil); err != nil {
+		return fmt.Errorf("walletconnect: failed to publish: %w", err)
+	}
+	return nil
+}
+
+func (r *relay) write(msg *rpcMessage) error {
+	r.writeLock.Lock()
+	defer r.writeLock.Unlock()
+
+	if err := r.conn.WriteJSON(msg); err != nil {
+		r.close(fmt.Errorf("walletconnect: relay connection failed: %w", err))
+		return r.closeErr()
+	}
+	return nil
+}
+
+func (r *relay) readLoop() {
+	for {
+		var msg rpcMessage
+		if err := r.conn.ReadJSON(&msg); err != nil {
+			r.close(fmt.Errorf("walletconnect: relay connection failed: %w", err))
+			return
+		}
+
+		if msg.Method == "" {
+			r.Lock()
+			ch := r.pending[msg.ID]
+			r.Unlock()
+			if ch != nil {
+				ch <- &msg
+			}
+			continue
+		}
+		if msg.Method != methodRelaySubscription {
+			continue
+		}
+		var params subscriptionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			continue
+		}
+		if ack, err := newResponse(msg.ID, true); err == nil {
+			_ = r.write(ack)
+		}
+		// Messages are handled concurrently, as handlers may need to make further calls.
+		go r.onMessage(params.Data.Topic, params.Data.Message)
+	}
+}
+
+func (r *relay) closeErr() error {
+	r.Lock()
+	defer r.Unlock()
+	return r.err
+}
+
+func (r *relay) close(err error) {
+	r.closeOnce.Do(func() {
+		r.Lock()
+		r.err = err
+		r.Unlock()
+		_ = r.conn.Close()
+		close(r.closeCh)
+	})
+}
+
+// dialRelay connects to the relay server at the given URL, passing all messages of subscribed
+// topics to the given handler.
+func dialRelay(ctx context.Context, url string, onMessage func(topic, message string)) (*relay, error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("walletconnect: failed to connect to relay: %w", err)
+	}
+	r := &relay{
+		conn:      conn,
+		pending:   make(map[uint64]chan *rpcMessage),
+		onMessage: onMessage,
+		closeCh:   make(chan struct{}),
+	}
+	go r.readLoop()
+	return r, nil
+}
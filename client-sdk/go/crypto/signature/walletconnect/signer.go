@@ -0,0 +1,113 @@
+package walletconnect
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/secp256k1"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// PublicKeyRequest is the request of the oasis_getPublicKey method.
+type PublicKeyRequest struct {
+	// Address is the address of the account.
+	Address types.Address `json:"address"`
+}
+
+// PublicKeyResponse is the response of the oasis_getPublicKey method.
+type PublicKeyResponse struct {
+	// Algorithm is the signature algorithm of the key, AlgorithmEd25519 or AlgorithmSecp256k1.
+	Algorithm string `json:"algorithm"`
+	// PublicKey is the public key, compressed in case of Secp256k1 keys.
+	PublicKey []byte `json:"public_key"`
+}
+
+// ContextSignRequest is the request of the oasis_contextSign method.
+type ContextSignRequest struct {
+	// Address is the address of the account whose key should sign.
+	Address types.Address `json:"address"`
+	// Context is the signature context, e.g. the chain-specific transaction signature context.
+	Context string `json:"context"`
+	// Message is the message to sign, e.g. a CBOR-encoded transaction.
+	Message []byte `json:"message"`
+}
+
+// ContextSignResponse is the response of the oasis_contextSign method.
+type ContextSignResponse struct {
+	// Signature is the signature, as produced by a local signer with the same key.
+	Signature []byte `json:"signature"`
+}
+
+type walletSigner struct {
+	session   *Session
+	address   types.Address
+	publicKey signature.PublicKey
+}
+
+func (s *walletSigner) Public() signature.PublicKey {
+	return s.publicKey
+}
+
+func (s *walletSigner) ContextSign(sigCtx, message []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.session.client.cfg.RequestTimeout)
+	defer cancel()
+
+	var rsp ContextSignResponse
+	if err := s.session.Request(ctx, MethodContextSign, &ContextSignRequest{
+		Address: s.address,
+		Context: string(sigCtx),
+		Message: message,
+	}, &rsp); err != nil {
+		return nil, err
+	}
+	// Check the signature, as the wallet may have signed with a different key.
+	if !s.publicKey.Verify(sigCtx, message, rsp.Signature) {
+		return nil, fmt.Errorf("walletconnect: invalid signature from wallet")
+	}
+	return rsp.Signature, nil
+}
+
+func (s *walletSigner) String() string {
+	return "walletconnect:" + s.address.String()
+}
+
+func (s *walletSigner) Reset() {}
+
+// NewSigner returns a signer for the given account of the session. Every signature is requested
+// from the wallet, where the user has to approve it.
+func (s *Session) NewSigner(ctx context.Context, address types.Address) (signature.Signer, error) {
+	if !s.hasAccount(address) {
+		return nil, fmt.Errorf("walletconnect: account %s not in session", address)
+	}
+
+	var rsp PublicKeyResponse
+	if err := s.Request(ctx, MethodGetPublicKey, &PublicKeyRequest{Address: address}, &rsp); err != nil {
+		return nil, err
+	}
+	var (
+		pk   signature.PublicKey
+		spec types.SignatureAddressSpec
+	)
+	switch rsp.Algorithm {
+	case AlgorithmEd25519:
+		var edPk ed25519.PublicKey
+		if err := edPk.UnmarshalBinary(rsp.PublicKey); err != nil {
+			return nil, fmt.Errorf("walletconnect: malformed public key: %w", err)
+		}
+		pk, spec = edPk, types.NewSignatureAddressSpecEd25519(edPk)
+	case AlgorithmSecp256k1:
+		var secpPk secp256k1.PublicKey
+		if err := secpPk.UnmarshalBinary(rsp.PublicKey); err != nil {
+			return nil, fmt.Errorf("walletconnect: malformed public key: %w", err)
+		}
+		pk, spec = secpPk, types.NewSignatureAddressSpecSecp256k1Eth(secpPk)
+	default:
+		return nil, fmt.Errorf("walletconnect: unsupported algorithm '%s'", rsp.Algorithm)
+	}
+	if !types.NewAddress(spec).Equal(address) {
+		return nil, fmt.Errorf("walletconnect: public key does not match account %s", address)
+	}
+	return &walletSigner{session: s, address: address, publicKey: pk}, nil
+}
@@ -0,0 +1,597 @@
+// Package walletconnect implements a signer delegating signing to a user's wallet over
+// WalletConnect v2.
+//
+// A dapp backend creates a pairing and shows its URI to the user, e.g. as a QR code scanned with
+// a mobile wallet. Once the user approves the session proposal in the wallet, the accounts shared
+// by the wallet can be used as signers, with every signature requested from and approved in the
+// wallet.
+//
+// There is no registered CAIP-2 namespace for ParaTimes, so sessions use the "oasis" namespace
+// with the chain identifiers returned by ChainID, and the oasis_getPublicKey and oasis_contextSign
+// methods, which wallets must support.
+package walletconnect
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const (
+	// DefaultRelayURL is the URL of the public WalletConnect relay server.
+	DefaultRelayURL = "wss://relay.walletconnect.com"
+	// DefaultRequestTimeout is the default time given to users to approve requests in the wallet.
+	DefaultRequestTimeout = 5 * time.Minute
+
+	// Namespace is the CAIP-2 namespace of ParaTime chains.
+	Namespace = "oasis"
+
+	// MethodGetPublicKey is the method returning the public key of an account of the session.
+	MethodGetPublicKey = "oasis_getPublicKey"
+	// MethodContextSign is the method signing a message with the key of an account of the
+	// session, as signature.Signer.ContextSign does.
+	MethodContextSign = "oasis_contextSign"
+
+	// AlgorithmEd25519 is the algorithm of Ed25519 public keys.
+	AlgorithmEd25519 = "ed25519"
+	// AlgorithmSecp256k1 is the algorithm of Secp256k1 public keys.
+	AlgorithmSecp256k1 = "secp256k1"
+
+	// messageTTL is the time the relay server keeps messages for offline peers.
+	messageTTL = 5 * time.Minute
+)
+
+// Sign API methods and their relay message tags.
+const (
+	methodSessionPropose = "wc_sessionPropose"
+	methodSessionSettle  = "wc_sessionSettle"
+	methodSessionUpdate  = "wc_sessionUpdate"
+	methodSessionExtend  = "wc_sessionExtend"
+	methodSessionRequest = "wc_sessionRequest"
+	methodSessionEvent   = "wc_sessionEvent"
+	methodSessionDelete  = "wc_sessionDelete"
+	methodSessionPing    = "wc_sessionPing"
+
+	tagSessionPropose = 1100
+	tagSessionSettle  = 1102
+	tagSessionRequest = 1108
+	tagSessionDelete  = 1112
+
+	// tagResponse is the offset of response tags from request tags.
+	tagResponse = 1
+)
+
+// requestTags are the relay message tags of the requests that may be received from wallets.
+var requestTags = map[string]int{
+	methodSessionSettle: tagSessionSettle,
+	methodSessionUpdate: 1104,
+	methodSessionExtend: 1106,
+	methodSessionEvent:  1110,
+	methodSessionDelete: tagSessionDelete,
+	methodSessionPing:   1114,
+}
+
+// ChainID returns the CAIP-2 chain identifier of the ParaTime with the given identifier.
+//
+// The chain reference is the hex-encoded second half of the runtime identifier, as references are
+// limited to 32 characters and the first half mostly consists of flags.
+func ChainID(runtimeID common.Namespace) string {
+	return Namespace + ":" + hex.EncodeToString(runtimeID[len(runtimeID)/2:])
+}
+
+// Metadata is the metadata of a dapp or wallet, shown to the other party.
+type Metadata struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	URL         string   `json:"url"`
+	Icons       []string `json:"icons"`
+}
+
+// Config is the WalletConnect client configuration.
+type Config struct {
+	// ProjectID is the WalletConnect Cloud project identifier.
+	ProjectID string
+	// RelayURL is the URL of the relay server. If empty, DefaultRelayURL is used.
+	RelayURL string
+	// Metadata is the metadata of the dapp shown in the wallet.
+	Metadata Metadata
+	// ChainID is the CAIP-2 identifier of the ParaTime, see ChainID.
+	ChainID string
+	// RequestTimeout is the time given to users to approve signing requests. If zero,
+	// DefaultRequestTimeout is used.
+	RequestTimeout time.Duration
+}
+
+type relayProtocol struct {
+	Protocol string `json:"protocol"`
+}
+
+type participant struct {
+	PublicKey string   `json:"publicKey"`
+	Metadata  Metadata `json:"metadata"`
+}
+
+type namespace struct {
+	Chains   []string `json:"chains,omitempty"`
+	Accounts []string `json:"accounts,omitempty"`
+	Methods  []string `json:"methods"`
+	Events   []string `json:"events"`
+}
+
+type sessionProposeParams struct {
+	Relays             []relayProtocol      `json:"relays"`
+	RequiredNamespaces map[string]namespace `json:"requiredNamespaces"`
+	Proposer           participant          `json:"proposer"`
+}
+
+type sessionProposeResult struct {
+	Relay              relayProtocol `json:"relay"`
+	ResponderPublicKey string        `json:"responderPublicKey"`
+}
+
+type sessionSettleParams struct {
+	Relay      relayProtocol        `json:"relay"`
+	Namespaces map[string]namespace `json:"namespaces"`
+	Controller participant          `json:"controller"`
+	Expiry     int64                `json:"expiry"`
+}
+
+type sessionUpdateParams struct {
+	Namespaces map[string]namespace `json:"namespaces"`
+}
+
+type sessionRequestParams struct {
+	Request struct {
+		Method string      `json:"method"`
+		Params interface{} `json:"params"`
+	} `json:"request"`
+	ChainID string `json:"chainId"`
+}
+
+type sessionDeleteParams struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// topic is a topic the client is subscribed to.
+type topic struct {
+	symKey         []byte
+	subscriptionID string
+
+	// settleCh receives the session settlement on the topic of a proposed session.
+	settleCh chan *sessionSettleParams
+	// session is the session of the topic, if any.
+	session *Session
+}
+
+// Client is a WalletConnect client, connected to a relay server.
+type Client struct {
+	sync.Mutex
+
+	cfg   Config
+	relay *relay
+
+	topics  map[string]*topic
+	pending map[uint64]chan *rpcMessage
+}
+
+// Pair creates a new pairing and proposes a session to the wallet that connects to it.
+func (c *Client) Pair(ctx context.Context) (*Pairing, error) {
+	symKey, err := randomKey()
+	if err != nil {
+		return nil, err
+	}
+	rawTopic, err := randomKey()
+	if err != nil {
+		return nil, err
+	}
+	pairingTopic := hex.EncodeToString(rawTopic)
+	if err = c.subscribe(ctx, pairingTopic, &topic{symKey: symKey}); err != nil {
+		return nil, err
+	}
+
+	privateKey, publicKey, err := newKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	id, rspCh, err := c.send(ctx, pairingTopic, methodSessionPropose, &sessionProposeParams{
+		Relays: []relayProtocol{{Protocol: "irn"}},
+		RequiredNamespaces: map[string]namespace{
+			Namespace: {
+				Chains:  []string{c.cfg.ChainID},
+				Methods: []string{MethodGetPublicKey, MethodContextSign},
+				Events:  []string{},
+			},
+		},
+		Proposer: participant{
+			PublicKey: hex.EncodeToString(publicKey),
+			Metadata:  c.cfg.Metadata,
+		},
+	}, tagSessionPropose)
+	if err != nil {
+		return nil, err
+	}
+
+	expiry := time.Now().Add(messageTTL)
+	uri := fmt.Sprintf("wc:%s@2?relay-protocol=irn&symKey=%s&expiryTimestamp=%d", pairingTopic, hex.EncodeToString(symKey), expiry.Unix())
+	return &Pairing{
+		URI:        uri,
+		client:     c,
+		id:         id,
+		rspCh:      rspCh,
+		privateKey: privateKey,
+	}, nil
+}
+
+// Close disconnects from the relay server, after which the sessions of the client can no longer
+// be used.
+func (c *Client) Close() error {
+	c.relay.close(fmt.Errorf("walletconnect: client closed"))
+	return nil
+}
+
+// subscribe subscribes to the given topic.
+func (c *Client) subscribe(ctx context.Context, name string, t *topic) error {
+	c.Lock()
+	c.topics[name] = t
+	c.Unlock()
+
+	id, err := c.relay.subscribe(ctx, name)
+	if err != nil {
+		c.Lock()
+		delete(c.topics, name)
+		c.Unlock()
+		return err
+	}
+	c.Lock()
+	t.subscriptionID = id
+	c.Unlock()
+	return nil
+}
+
+// send publishes a request to the given topic and returns its identifier and the channel
+// receiving its response.
+func (c *Client) send(ctx context.Context, name, method string, params interface{}, tag int) (uint64, chan *rpcMessage, error) {
+	req, err := newRequest(method, params)
+	if err != nil {
+		return 0, nil, err
+	}
+	rspCh := make(chan *rpcMessage, 1)
+	c.Lock()
+	c.pending[req.ID] = rspCh
+	c.Unlock()
+
+	if err = c.publish(ctx, name, req, tag); err != nil {
+		c.forget(req.ID)
+		return 0, nil, err
+	}
+	return req.ID, rspCh, nil
+}
+
+// await waits for the response to the request with the given identifier.
+func (c *Client) await(ctx context.Context, id uint64, rspCh chan *rpcMessage, result interface{}) error {
+	defer c.forget(id)
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.relay.closeCh:
+		return c.relay.closeErr()
+	case rsp := <-rspCh:
+		return rsp.result(result)
+	}
+}
+
+// request publishes a request to the given topic and waits for its response.
+func (c *Client) request(ctx context.Context, name, method string, params interface{}, tag int, result interface{}) error {
+	id, rspCh, err := c.send(ctx, name, method, params, tag)
+	if err != nil {
+		return err
+	}
+	return c.await(ctx, id, rspCh, result)
+}
+
+func (c *Client) forget(id uint64) {
+	c.Lock()
+	delete(c.pending, id)
+	c.Unlock()
+}
+
+// publish encrypts the given message and publishes it to the given topic.
+func (c *Client) publish(ctx context.Context, name string, msg *rpcMessage, tag int) error {
+	c.Lock()
+	t := c.topics[name]
+	c.Unlock()
+	if t == nil {
+		return fmt.Errorf("walletconnect: not subscribed to topic")
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("walletconnect: failed to encode message: %w", err)
+	}
+	envelope, err := seal(t.symKey, payload)
+	if err != nil {
+		return err
+	}
+	return c.relay.publish(ctx, name, envelope, tag, messageTTL)
+}
+
+// handleMessage handles a message published to a subscribed topic.
+func (c *Client) handleMessage(name, message string) {
+	c.Lock()
+	t := c.topics[name]
+	c.Unlock()
+	if t == nil {
+		return
+	}
+	payload, err := open(t.symKey, message)
+	if err != nil {
+		return
+	}
+	var msg rpcMessage
+	if err = json.Unmarshal(payload, &msg); err != nil {
+		return
+	}
+
+	if msg.Method == "" {
+		c.Lock()
+		rspCh := c.pending[msg.ID]
+		c.Unlock()
+		if rspCh != nil {
+			rspCh <- &msg
+		}
+		return
+	}
+
+	tag, ok := requestTags[msg.Method]
+	if !ok {
+		return
+	}
+	if !c.handleRequest(t, &msg) {
+		return
+	}
+	rsp, err := newResponse(msg.ID, true)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.cfg.RequestTimeout)
+	defer cancel()
+	_ = c.publish(ctx, name, rsp, tag+tagResponse)
+}
+
+// handleRequest handles a request of the wallet and returns whether it should be acknowledged.
+func (c *Client) handleRequest(t *topic, msg *rpcMessage) bool {
+	c.Lock()
+	session := t.session
+	c.Unlock()
+
+	switch msg.Method {
+	case methodSessionSettle:
+		var params sessionSettleParams
+		if t.settleCh == nil || json.Unmarshal(msg.Params, &params) != nil {
+			return false
+		}
+		select {
+		case t.settleCh <- &params:
+		default:
+			// Already settled.
+		}
+	case methodSessionUpdate:
+		var params sessionUpdateParams
+		if session == nil || json.Unmarshal(msg.Params, &params) != nil {
+			return false
+		}
+		session.setAccounts(params.Namespaces)
+	case methodSessionDelete:
+		if session != nil {
+			session.setClosed()
+		}
+	}
+	return true
+}
+
+// Connect connects to the relay server.
+func Connect(ctx context.Context, cfg Config) (*Client, error) {
+	if cfg.ChainID == "" {
+		return nil, fmt.Errorf("walletconnect: no chain identifier given")
+	}
+	if cfg.RelayURL == "" {
+		cfg.RelayURL = DefaultRelayURL
+	}
+	if cfg.RequestTimeout == 0 {
+		cfg.RequestTimeout = DefaultRequestTimeout
+	}
+
+	_, authKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("walletconnect: failed to generate relay authentication key: %w", err)
+	}
+	token, err := relayAuthToken(authKey, cfg.RelayURL, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	query.Set("auth", token)
+	query.Set("projectId", cfg.ProjectID)
+
+	c := &Client{
+		cfg:     cfg,
+		topics:  make(map[string]*topic),
+		pending: make(map[uint64]chan *rpcMessage),
+	}
+	if c.relay, err = dialRelay(ctx, strings.TrimSuffix(cfg.RelayURL, "/")+"/?"+query.Encode(), c.handleMessage); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Pairing is a pairing with a wallet, on which a session has been proposed.
+type Pairing struct {
+	// URI is the pairing URI to be shared with the wallet, e.g. as a QR code.
+	URI string
+
+	client     *Client
+	id         uint64
+	rspCh      chan *rpcMessage
+	privateKey []byte
+}
+
+// Wait waits for the user to approve the proposed session in the wallet.
+func (p *Pairing) Wait(ctx context.Context) (*Session, error) {
+	var result sessionProposeResult
+	if err := p.client.await(ctx, p.id, p.rspCh, &result); err != nil {
+		return nil, err
+	}
+	responderPublicKey, err := hex.DecodeString(result.ResponderPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("walletconnect: malformed responder public key: %w", err)
+	}
+	symKey, err := deriveSymKey(p.privateKey, responderPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionTopic := topicFromSymKey(symKey)
+	t := &topic{
+		symKey:   symKey,
+		settleCh: make(chan *sessionSettleParams, 1),
+	}
+	if err = p.client.subscribe(ctx, sessionTopic, t); err != nil {
+		return nil, err
+	}
+
+	var params *sessionSettleParams
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-p.client.relay.closeCh:
+		return nil, p.client.relay.closeErr()
+	case params = <-t.settleCh:
+	}
+
+	s := &Session{
+		client: p.client,
+		topic:  sessionTopic,
+		peer:   params.Controller.Metadata,
+		expiry: time.Unix(params.Expiry, 0),
+	}
+	s.setAccounts(params.Namespaces)
+	p.client.Lock()
+	t.session = s
+	p.client.Unlock()
+	return s, nil
+}
+
+// Session is a session with a wallet.
+type Session struct {
+	sync.Mutex
+
+	client *Client
+	topic  string
+	peer   Metadata
+	expiry time.Time
+
+	accounts []types.Address
+	closed   bool
+}
+
+// Peer returns the metadata of the wallet.
+func (s *Session) Peer() Metadata {
+	return s.peer
+}
+
+// Expiry returns the time the session expires.
+func (s *Session) Expiry() time.Time {
+	return s.expiry
+}
+
+// Accounts returns the accounts shared by the wallet for the chain of the client.
+func (s *Session) Accounts() []types.Address {
+	s.Lock()
+	defer s.Unlock()
+	return append([]types.Address{}, s.accounts...)
+}
+
+// Request makes a request to the wallet and waits for its response.
+func (s *Session) Request(ctx context.Context, method string, params, result interface{}) error {
+	s.Lock()
+	closed := s.closed
+	s.Unlock()
+	if closed {
+		return fmt.Errorf("walletconnect: session closed")
+	}
+
+	var req sessionRequestParams
+	req.Request.Method = method
+	req.Request.Params = params
+	req.ChainID = s.client.cfg.ChainID
+	return s.client.request(ctx, s.topic, methodSessionRequest, &req, tagSessionRequest, result)
+}
+
+// Disconnect ends the session.
+func (s *Session) Disconnect(ctx context.Context) error {
+	s.setClosed()
+
+	req, err := newRequest(methodSessionDelete, &sessionDeleteParams{Code: 6000, Message: "User disconnected."})
+	if err != nil {
+		return err
+	}
+	if err = s.client.publish(ctx, s.topic, req, tagSessionDelete); err != nil {
+		return err
+	}
+
+	s.client.Lock()
+	t := s.client.topics[s.topic]
+	delete(s.client.topics, s.topic)
+	s.client.Unlock()
+	if t == nil {
+		return nil
+	}
+	return s.client.relay.unsubscribe(ctx, s.topic, t.subscriptionID)
+}
+
+func (s *Session) setAccounts(namespaces map[string]namespace) {
+	var accounts []types.Address
+	prefix := s.client.cfg.ChainID + ":"
+	for _, account := range namespaces[Namespace].Accounts {
+		if !strings.HasPrefix(account, prefix) {
+			continue
+		}
+		var addr types.Address
+		if err := addr.UnmarshalText([]byte(strings.TrimPrefix(account, prefix))); err != nil {
+			continue
+		}
+		accounts = append(accounts, addr)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	s.accounts = accounts
+}
+
+func (s *Session) setClosed() {
+	s.Lock()
+	defer s.Unlock()
+	s.closed = true
+}
+
+func (s *Session) hasAccount(address types.Address) bool {
+	for _, addr := range s.Accounts() {
+		if addr.Equal(address) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,292 @@
+package walletconnect
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// testRelay is a relay server keeping all published messages and delivering them to current and
+// future subscribers.
+type testRelay struct {
+	sync.Mutex
+
+	messages    map[string][]string
+	subscribers map[string][]*testRelayConn
+}
+
+type testRelayConn struct {
+	sync.Mutex
+
+	conn *websocket.Conn
+}
+
+func (c *testRelayConn) write(msg *rpcMessage) {
+	c.Lock()
+	defer c.Unlock()
+	_ = c.conn.WriteJSON(msg)
+}
+
+func (c *testRelayConn) deliver(topic, message string) {
+	var params subscriptionParams
+	params.ID = topic
+	params.Data.Topic = topic
+	params.Data.Message = message
+	req, _ := newRequest(methodRelaySubscription, &params)
+	c.write(req)
+}
+
+func (r *testRelay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Query().Get("projectId") == "" || len(strings.Split(req.URL.Query().Get("auth"), ".")) != 3 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	wsConn, err := (&websocket.Upgrader{}).Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	conn := &testRelayConn{conn: wsConn}
+	for {
+		var msg rpcMessage
+		if err = wsConn.ReadJSON(&msg); err != nil {
+			return
+		}
+		var result interface{} = true
+		switch msg.Method {
+		case methodRelaySubscribe:
+			var params subscribeParams
+			_ = json.Unmarshal(msg.Params, &params)
+			r.Lock()
+			r.subscribers[params.Topic] = append(r.subscribers[params.Topic], conn)
+			messages := r.messages[params.Topic]
+			r.Unlock()
+			for _, message := range messages {
+				conn.deliver(params.Topic, message)
+			}
+			result = params.Topic
+		case methodRelayPublish:
+			var params publishParams
+			_ = json.Unmarshal(msg.Params, &params)
+			r.Lock()
+			r.messages[params.Topic] = append(r.messages[params.Topic], params.Message)
+			subscribers := r.subscribers[params.Topic]
+			r.Unlock()
+			for _, sub := range subscribers {
+				sub.deliver(params.Topic, params.Message)
+			}
+		case methodRelayUnsubscribe:
+		default:
+			// Acknowledgements of delivered messages.
+			continue
+		}
+		rsp, _ := newResponse(msg.ID, result)
+		conn.write(rsp)
+	}
+}
+
+// testWallet is a wallet sharing the account of a test key.
+type testWallet struct {
+	sync.Mutex
+
+	t       *testing.T
+	key     sdkTesting.TestKey
+	chainID string
+	relay   *relay
+	keys    map[string][]byte
+
+	deleted chan struct{}
+}
+
+func (w *testWallet) publish(topic string, msg *rpcMessage, tag int) {
+	w.Lock()
+	key := w.keys[topic]
+	w.Unlock()
+	payload, _ := json.Marshal(msg)
+	envelope, err := seal(key, payload)
+	require.NoError(w.t, err, "seal")
+	require.NoError(w.t, w.relay.publish(context.Background(), topic, envelope, tag, messageTTL), "publish")
+}
+
+func (w *testWallet) subscribe(topic string, key []byte) {
+	w.Lock()
+	w.keys[topic] = key
+	w.Unlock()
+	_, err := w.relay.subscribe(context.Background(), topic)
+	require.NoError(w.t, err, "subscribe")
+}
+
+func (w *testWallet) handleMessage(topic, message string) {
+	w.Lock()
+	key := w.keys[topic]
+	w.Unlock()
+	payload, err := open(key, message)
+	require.NoError(w.t, err, "open")
+	var msg rpcMessage
+	require.NoError(w.t, json.Unmarshal(payload, &msg), "malformed message")
+
+	switch msg.Method {
+	case methodSessionPropose:
+		var params sessionProposeParams
+		require.NoError(w.t, json.Unmarshal(msg.Params, &params), "malformed proposal")
+		require.Equal(w.t, []string{w.chainID}, params.RequiredNamespaces[Namespace].Chains)
+		proposerPublicKey, err := hex.DecodeString(params.Proposer.PublicKey)
+		require.NoError(w.t, err, "malformed proposer public key")
+		privateKey, publicKey, err := newKeyPair()
+		require.NoError(w.t, err, "newKeyPair")
+		symKey, err := deriveSymKey(privateKey, proposerPublicKey)
+		require.NoError(w.t, err, "deriveSymKey")
+
+		sessionTopic := topicFromSymKey(symKey)
+		w.subscribe(sessionTopic, symKey)
+		settle, _ := newRequest(methodSessionSettle, &sessionSettleParams{
+			Relay: relayProtocol{Protocol: "irn"},
+			Namespaces: map[string]namespace{Namespace: {
+				Accounts: []string{w.chainID + ":" + w.key.Address.String()},
+				Methods:  params.RequiredNamespaces[Namespace].Methods,
+				Events:   []string{},
+			}},
+			Controller: participant{PublicKey: hex.EncodeToString(publicKey), Metadata: Metadata{Name: "Test Wallet"}},
+			Expiry:     time.Now().Add(time.Hour).Unix(),
+		})
+		w.publish(sessionTopic, settle, tagSessionSettle)
+		rsp, _ := newResponse(msg.ID, &sessionProposeResult{
+			Relay:              relayProtocol{Protocol: "irn"},
+			ResponderPublicKey: hex.EncodeToString(publicKey),
+		})
+		w.publish(topic, rsp, tagSessionPropose+tagResponse)
+	case methodSessionRequest:
+		var params struct {
+			Request struct {
+				Method string          `json:"method"`
+				Params json.RawMessage `json:"params"`
+			} `json:"request"`
+			ChainID string `json:"chainId"`
+		}
+		require.NoError(w.t, json.Unmarshal(msg.Params, &params), "malformed request")
+		require.Equal(w.t, w.chainID, params.ChainID)
+
+		var result interface{}
+		switch params.Request.Method {
+		case MethodGetPublicKey:
+			rawPk, _ := w.key.Signer.Public().(interface{ MarshalBinary() ([]byte, error) }).MarshalBinary()
+			result = &PublicKeyResponse{Algorithm: AlgorithmEd25519, PublicKey: rawPk}
+		case MethodContextSign:
+			var req ContextSignRequest
+			require.NoError(w.t, json.Unmarshal(params.Request.Params, &req), "malformed sign request")
+			if string(req.Message) == "reject" {
+				w.publish(topic, &rpcMessage{ID: msg.ID, JSONRPC: "2.0", Error: &Error{Code: 5000, Message: "User rejected."}}, tagSessionRequest+tagResponse)
+				return
+			}
+			sig, err := w.key.Signer.ContextSign([]byte(req.Context), req.Message)
+			require.NoError(w.t, err, "ContextSign")
+			result = &ContextSignResponse{Signature: sig}
+		}
+		rsp, _ := newResponse(msg.ID, result)
+		w.publish(topic, rsp, tagSessionRequest+tagResponse)
+	case methodSessionDelete:
+		close(w.deleted)
+	}
+}
+
+// pair connects the wallet to the dapp with the given pairing URI.
+func (w *testWallet) pair(relayURL, uri string) {
+	// wc:<topic>@2?relay-protocol=irn&symKey=<key>&...
+	parsed, err := url.Parse(uri)
+	require.NoError(w.t, err, "malformed pairing URI")
+	require.Equal(w.t, "wc", parsed.Scheme)
+	pairingTopic := strings.TrimSuffix(parsed.Opaque, "@2")
+	symKey, err := hex.DecodeString(parsed.Query().Get("symKey"))
+	require.NoError(w.t, err, "malformed symmetric key")
+
+	w.relay, err = dialRelay(context.Background(), relayURL+"/?projectId=wallet&auth=a.b.c", w.handleMessage)
+	require.NoError(w.t, err, "dialRelay")
+	w.subscribe(pairingTopic, symKey)
+}
+
+func TestSigner(t *testing.T) {
+	require := require.New(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	relaySrv := httptest.NewServer(&testRelay{
+		messages:    make(map[string][]string),
+		subscribers: make(map[string][]*testRelayConn),
+	})
+	defer relaySrv.Close()
+	relayURL := "ws" + strings.TrimPrefix(relaySrv.URL, "http")
+
+	var runtimeID common.Namespace
+	require.NoError(runtimeID.UnmarshalHex("8000000000000000000000000000000000000000000000000000000000000000"))
+	chainID := ChainID(runtimeID)
+	require.Equal("oasis:00000000000000000000000000000000", chainID)
+
+	c, err := Connect(ctx, Config{
+		ProjectID: "test",
+		RelayURL:  relayURL,
+		Metadata:  Metadata{Name: "Test Dapp"},
+		ChainID:   chainID,
+	})
+	require.NoError(err, "Connect")
+	defer c.Close()
+
+	pairing, err := c.Pair(ctx)
+	require.NoError(err, "Pair")
+	require.True(strings.HasPrefix(pairing.URI, "wc:"), "pairing URI")
+
+	wallet := &testWallet{
+		t:       t,
+		key:     sdkTesting.Alice,
+		chainID: chainID,
+		keys:    make(map[string][]byte),
+		deleted: make(chan struct{}),
+	}
+	wallet.pair(relayURL, pairing.URI)
+	defer wallet.relay.close(fmt.Errorf("closed"))
+
+	session, err := pairing.Wait(ctx)
+	require.NoError(err, "Wait")
+	require.Equal("Test Wallet", session.Peer().Name)
+	require.Equal([]types.Address{sdkTesting.Alice.Address}, session.Accounts())
+
+	_, err = session.NewSigner(ctx, sdkTesting.Bob.Address)
+	require.Error(err, "NewSigner for an account not in the session")
+	signer, err := session.NewSigner(ctx, sdkTesting.Alice.Address)
+	require.NoError(err, "NewSigner")
+	require.True(signer.Public().Equal(sdkTesting.Alice.Signer.Public()), "public key")
+
+	sigCtx := signature.DeriveChainContext(runtimeID, "test").New(types.SignatureContextBase)
+	sig, err := signer.ContextSign(sigCtx, []byte("message"))
+	require.NoError(err, "ContextSign")
+	require.True(signer.Public().Verify(sigCtx, []byte("message"), sig), "signature should be valid")
+
+	_, err = signer.ContextSign(sigCtx, []byte("reject"))
+	var rpcErr *Error
+	require.ErrorAs(err, &rpcErr, "rejected request")
+	require.Equal(5000, rpcErr.Code)
+
+	require.NoError(session.Disconnect(ctx), "Disconnect")
+	select {
+	case <-wallet.deleted:
+	case <-ctx.Done():
+		require.Fail("session deletion not received by the wallet")
+	}
+	_, err = signer.ContextSign(sigCtx, []byte("message"))
+	require.Error(err, "signing after disconnecting")
+}
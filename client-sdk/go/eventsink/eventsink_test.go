@@ -0,0 +1,178 @@
+package eventsink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/processor"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/fakeruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func nativeUnits(amount uint64) types.BaseUnits {
+	return types.NewBaseUnits(*quantity.NewFromUint64(amount), types.NativeDenomination)
+}
+
+type testSink struct {
+	sync.Mutex
+
+	msgs     []*Message
+	attempts int
+}
+
+func (s *testSink) Publish(ctx context.Context, msgs []*Message) error {
+	s.Lock()
+	defer s.Unlock()
+
+	// Fail the first publication to exercise retries.
+	if s.attempts++; s.attempts == 1 {
+		return fmt.Errorf("try again")
+	}
+	s.msgs = append(s.msgs, msgs...)
+	return nil
+}
+
+func (s *testSink) Close() error {
+	return nil
+}
+
+func TestPublisher(t *testing.T) {
+	require := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rt := fakeruntime.New(fakeruntime.Config{})
+	rt.SetBalance(sdkTesting.Alice.Address, nativeUnits(1000))
+	transfer := func(to types.Address, nonce uint64) uint64 {
+		tb := accounts.NewV1(rt).Transfer(to, nativeUnits(10))
+		tb.AppendAuthSignature(sdkTesting.Alice.SigSpec, nonce)
+		require.NoError(tb.AppendSign(ctx, sdkTesting.Alice.Signer), "AppendSign")
+		meta, err := tb.SubmitTxMeta(ctx, nil)
+		require.NoError(err, "SubmitTxMeta")
+		return meta.Round
+	}
+
+	first := transfer(sdkTesting.Bob.Address, 0)
+	transfer(sdkTesting.Charlie.Address, 1)
+
+	sink := &testSink{}
+	store := processor.NewMemoryStore()
+	var publishErrors int
+	p, err := New(rt, Config{
+		Sink:           sink,
+		Modules:        []string{accounts.ModuleName},
+		Store:          store,
+		StartRound:     first,
+		InitialBackoff: 10 * time.Millisecond,
+		OnPublishError: func(round uint64, err error) { publishErrors++ },
+	})
+	require.NoError(err, "New")
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- p.Run(ctx) }()
+
+	// Rounds finalized while running should be processed as well.
+	last := transfer(sdkTesting.Bob.Address, 2)
+	require.Eventually(func() bool {
+		pos, loadErr := store.Load(ctx)
+		return loadErr == nil && pos != nil && pos.Round >= last
+	}, 5*time.Second, 10*time.Millisecond, "all rounds should be processed")
+	cancel()
+	require.ErrorIs(<-errCh, context.Canceled)
+
+	sink.Lock()
+	defer sink.Unlock()
+	require.Equal(1, publishErrors, "failed publication should be retried")
+	require.Len(sink.msgs, 3)
+	msg := sink.msgs[0]
+	require.Equal(messageID(first, 0), msg.ID)
+	require.Equal(first, msg.Round)
+	require.Equal(accounts.ModuleName, msg.Module)
+	require.NotNil(msg.TxIndex)
+	require.NotNil(msg.TxHash)
+	ev, ok := msg.Event.(*accounts.Event)
+	require.True(ok, "event should be decoded")
+	require.Equal(sdkTesting.Bob.Address, ev.Transfer.To)
+	require.Equal(last, sink.msgs[2].Round)
+
+	_, err = New(rt, Config{})
+	require.Error(err, "publisher without a sink")
+}
+
+type testKafkaWriter struct {
+	msgs []kafka.Message
+}
+
+func (w *testKafkaWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+func (w *testKafkaWriter) Close() error {
+	return nil
+}
+
+type testNATSConn struct {
+	msgs    []*nats.Msg
+	flushes int
+}
+
+func (c *testNATSConn) PublishMsg(m *nats.Msg) error {
+	c.msgs = append(c.msgs, m)
+	return nil
+}
+
+func (c *testNATSConn) FlushWithContext(ctx context.Context) error {
+	c.flushes++
+	return nil
+}
+
+func (c *testNATSConn) Close() {}
+
+func TestSinks(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	txIndex := uint32(0)
+	msgs := []*Message{
+		{ID: "5-0", Round: 5, Timestamp: 1000, Module: accounts.ModuleName, Code: 1, TxIndex: &txIndex},
+		{ID: "5-1", Round: 5, Timestamp: 1000, EventIndex: 1, Module: "core", Code: 1},
+	}
+
+	w := &testKafkaWriter{}
+	require.NoError((&kafkaSink{w: w}).Publish(ctx, msgs), "Publish")
+	require.Len(w.msgs, 2)
+	require.Equal([]byte("5-0"), w.msgs[0].Key, "messages should be keyed by identifier")
+	require.Equal(int64(1000), w.msgs[0].Time.Unix())
+	require.Contains(w.msgs[1].Headers, kafka.Header{Key: HeaderModule, Value: []byte("core")})
+	var decoded Message
+	require.NoError(json.Unmarshal(w.msgs[0].Value, &decoded))
+	require.Equal(msgs[0].ID, decoded.ID)
+	require.Equal(uint32(0), *decoded.TxIndex)
+
+	nc := &testNATSConn{}
+	require.NoError((&natsSink{nc: nc, prefix: DefaultNATSSubjectPrefix}).Publish(ctx, msgs), "Publish")
+	require.Len(nc.msgs, 2)
+	require.Equal("oasis.events.accounts", nc.msgs[0].Subject)
+	require.Equal("oasis.events.core", nc.msgs[1].Subject)
+	require.Equal("5-1", nc.msgs[1].Header.Get(nats.MsgIdHdr))
+	require.Equal("5", nc.msgs[1].Header.Get(HeaderRound))
+	require.Equal(1, nc.flushes, "publications should be flushed once")
+
+	_, err := NewKafkaSink(KafkaConfig{Topic: "events"})
+	require.Error(err, "Kafka sink without brokers")
+	_, err = NewKafkaSink(KafkaConfig{Brokers: []string{"localhost:9092"}})
+	require.Error(err, "Kafka sink without a topic")
+}
@@ -0,0 +1,93 @@
+package eventsink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Headers set on the published Kafka and NATS messages.
+const (
+	HeaderModule = "Oasis-Module"
+	HeaderCode   = "Oasis-Code"
+	HeaderRound  = "Oasis-Round"
+)
+
+// kafkaWriter is the part of the Kafka writer used by the sink.
+type kafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// KafkaConfig is the configuration of a Kafka sink.
+type KafkaConfig struct {
+	// Brokers are the addresses of the Kafka brokers.
+	Brokers []string
+	// Topic is the topic messages are published to.
+	Topic string
+	// Balancer assigns messages to partitions based on their key, which is the message
+	// identifier. If nil, messages are assigned by hashing their key.
+	Balancer kafka.Balancer
+	// Dialer is the dialer used to connect to the brokers. If nil, the default dialer is used.
+	Dialer *kafka.Dialer
+}
+
+type kafkaSink struct {
+	w kafkaWriter
+}
+
+func (s *kafkaSink) Publish(ctx context.Context, msgs []*Message) error {
+	kmsgs := make([]kafka.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		value, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("eventsink: failed to encode message: %w", err)
+		}
+		kmsgs = append(kmsgs, kafka.Message{
+			Key:   []byte(msg.ID),
+			Value: value,
+			Headers: []kafka.Header{
+				{Key: HeaderModule, Value: []byte(msg.Module)},
+				{Key: HeaderCode, Value: []byte(strconv.FormatUint(uint64(msg.Code), 10))},
+				{Key: HeaderRound, Value: []byte(strconv.FormatUint(msg.Round, 10))},
+			},
+			Time: time.Unix(int64(msg.Timestamp), 0),
+		})
+	}
+	if err := s.w.WriteMessages(ctx, kmsgs...); err != nil {
+		return fmt.Errorf("eventsink: failed to write to Kafka: %w", err)
+	}
+	return nil
+}
+
+func (s *kafkaSink) Close() error {
+	return s.w.Close()
+}
+
+// NewKafkaSink creates a new sink publishing JSON-encoded messages to a Kafka topic.
+//
+// Writes are synchronous, so that a round is only recorded as processed once all of its messages
+// have been acknowledged by the brokers.
+func NewKafkaSink(cfg KafkaConfig) (Sink, error) {
+	switch {
+	case len(cfg.Brokers) == 0:
+		return nil, fmt.Errorf("eventsink: no Kafka brokers configured")
+	case cfg.Topic == "":
+		return nil, fmt.Errorf("eventsink: no Kafka topic configured")
+	}
+	if cfg.Balancer == nil {
+		cfg.Balancer = &kafka.Hash{}
+	}
+	return &kafkaSink{
+		w: kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  cfg.Brokers,
+			Topic:    cfg.Topic,
+			Balancer: cfg.Balancer,
+			Dialer:   cfg.Dialer,
+		}),
+	}, nil
+}
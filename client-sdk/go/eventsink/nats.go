@@ -0,0 +1,78 @@
+package eventsink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/nats-io/nats.go"
+)
+
+// DefaultNATSSubjectPrefix is the default prefix of the subjects messages are published to.
+const DefaultNATSSubjectPrefix = "oasis.events"
+
+// natsConn is the part of the NATS connection used by the sink.
+type natsConn interface {
+	PublishMsg(m *nats.Msg) error
+	FlushWithContext(ctx context.Context) error
+	Close()
+}
+
+// NATSConfig is the configuration of a NATS sink.
+type NATSConfig struct {
+	// URL is the URL of the NATS server.
+	URL string
+	// SubjectPrefix is the prefix of the subjects messages are published to. The events of each
+	// module are published to the subject consisting of the prefix and the module name, e.g.
+	// "oasis.events.accounts". If empty, DefaultNATSSubjectPrefix is used.
+	SubjectPrefix string
+	// Options are additional options of the connection, e.g. for authentication.
+	Options []nats.Option
+}
+
+type natsSink struct {
+	nc     natsConn
+	prefix string
+}
+
+func (s *natsSink) Publish(ctx context.Context, msgs []*Message) error {
+	for _, msg := range msgs {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("eventsink: failed to encode message: %w", err)
+		}
+		nmsg := nats.NewMsg(s.prefix + "." + msg.Module)
+		nmsg.Data = data
+		// Allows JetStream streams to drop duplicates of republished messages.
+		nmsg.Header.Set(nats.MsgIdHdr, msg.ID)
+		nmsg.Header.Set(HeaderModule, msg.Module)
+		nmsg.Header.Set(HeaderCode, strconv.FormatUint(uint64(msg.Code), 10))
+		nmsg.Header.Set(HeaderRound, strconv.FormatUint(msg.Round, 10))
+		if err = s.nc.PublishMsg(nmsg); err != nil {
+			return fmt.Errorf("eventsink: failed to publish to NATS: %w", err)
+		}
+	}
+	// Make sure the server has processed all messages before the round is recorded as processed.
+	if err := s.nc.FlushWithContext(ctx); err != nil {
+		return fmt.Errorf("eventsink: failed to flush NATS connection: %w", err)
+	}
+	return nil
+}
+
+func (s *natsSink) Close() error {
+	s.nc.Close()
+	return nil
+}
+
+// NewNATSSink creates a new sink publishing JSON-encoded messages to NATS subjects.
+func NewNATSSink(cfg NATSConfig) (Sink, error) {
+	if cfg.SubjectPrefix == "" {
+		cfg.SubjectPrefix = DefaultNATSSubjectPrefix
+	}
+	nc, err := nats.Connect(cfg.URL, cfg.Options...)
+	if err != nil {
+		return nil, fmt.Errorf("eventsink: failed to connect to NATS: %w", err)
+	}
+	return &natsSink{nc: nc, prefix: cfg.SubjectPrefix}, nil
+}
@@ -0,0 +1,214 @@
+// Package eventsink implements a publisher of runtime events to external sinks.
+//
+// The publisher follows the blocks of a runtime, decodes the events of every round and publishes
+// them, together with their round and transaction metadata, to a pluggable sink, e.g. a Kafka
+// topic or a NATS subject, so that data pipelines can ingest runtime activity directly.
+//
+// The publisher runs on a round processor (see the processor package). Events are published at
+// least once: failed publications are retried with exponential backoff, and a round is only
+// recorded as processed in the store once all of its events have been published. Consumers should
+// use the message identifier, which is the same for all publications of an event, to skip
+// duplicates.
+package eventsink
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/indexer"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/processor"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+)
+
+const (
+	// DefaultInitialBackoff is the default delay before the first retry of a failed publication.
+	DefaultInitialBackoff = time.Second
+	// DefaultMaxBackoff is the default maximum delay between retries of a failed publication.
+	DefaultMaxBackoff = 5 * time.Minute
+)
+
+// Sink is a destination events are published to.
+type Sink interface {
+	// Publish publishes the given messages, in order. It only returns successfully once all of
+	// them have been durably accepted by the sink.
+	Publish(ctx context.Context, msgs []*Message) error
+
+	// Close flushes any pending messages and releases the resources of the sink.
+	Close() error
+}
+
+// Message is the JSON-encoded payload of a published event.
+type Message struct {
+	// ID is the identifier of the message, which is the same for all its publications.
+	ID string `json:"id"`
+
+	// Round is the round in which the event was emitted.
+	Round uint64 `json:"round"`
+	// BlockHash is the hash of the block of the round.
+	BlockHash hash.Hash `json:"block_hash"`
+	// Timestamp is the timestamp of the block in seconds since the Unix epoch.
+	Timestamp uint64 `json:"timestamp"`
+	// EventIndex is the index of the event within the round.
+	EventIndex uint32 `json:"event_index"`
+	// TxIndex is the index of the transaction that emitted the event, if any.
+	TxIndex *uint32 `json:"tx_index,omitempty"`
+	// TxHash is the hash of the transaction that emitted the event, if any.
+	TxHash *hash.Hash `json:"tx_hash,omitempty"`
+
+	// Module is the module that emitted the event.
+	Module string `json:"module"`
+	// Code is the event code.
+	Code uint32 `json:"code"`
+	// Value is the raw CBOR-encoded event value.
+	Value []byte `json:"value"`
+	// Event is the decoded event, if the event is known to the module registry.
+	Event client.DecodedEvent `json:"event,omitempty"`
+}
+
+// messageID returns the identifier of the message of the given event.
+func messageID(round uint64, eventIndex uint32) string {
+	return fmt.Sprintf("%d-%d", round, eventIndex)
+}
+
+// Messages returns the messages for the events of the given round, in the order of the events,
+// only including events of the given modules if any are given.
+func Messages(data *indexer.RoundData, modules ...string) []*Message {
+	var msgs []*Message
+	for _, ev := range data.Events {
+		if len(modules) > 0 && !containsString(modules, ev.Module) {
+			continue
+		}
+		msg := &Message{
+			ID:         messageID(data.Block.Round, ev.Index),
+			Round:      data.Block.Round,
+			BlockHash:  data.Block.Hash,
+			Timestamp:  data.Block.Timestamp,
+			EventIndex: ev.Index,
+			TxIndex:    ev.TxIndex,
+			Module:     ev.Module,
+			Code:       ev.Code,
+			Value:      ev.Value,
+		}
+		if ev.TxIndex != nil && int(*ev.TxIndex) < len(data.Transactions) {
+			msg.TxHash = &data.Transactions[*ev.TxIndex].Hash
+		}
+		if decoded, err := registry.DecodeEvent(ev.Raw()); err == nil {
+			msg.Event = decoded
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Config is the publisher configuration.
+type Config struct {
+	// Sink is the sink events are published to.
+	Sink Sink
+	// Modules are the modules whose events are published. If empty, events of all modules are
+	// published.
+	Modules []string
+
+	// Store is the store of the last processed round. If nil, an in-memory store is used.
+	Store processor.Store
+	// StartRound is the first round to process in case the store is empty. If zero, processing
+	// starts with the latest round.
+	StartRound uint64
+
+	// InitialBackoff is the delay before the first retry of a failed publication or after another
+	// failure. If zero, DefaultInitialBackoff is used.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum delay between retries of failed publications and after other
+	// failures. If zero, DefaultMaxBackoff is used.
+	MaxBackoff time.Duration
+
+	// OnPublishError, if not nil, is called for each failed publication attempt, e.g. for logging.
+	OnPublishError func(round uint64, err error)
+	// OnError, if not nil, is called for each other failure that is retried, e.g. for logging.
+	OnError func(err error)
+}
+
+// Publisher publishes the events of a runtime to a sink.
+type Publisher struct {
+	cfg Config
+	p   *processor.Processor
+}
+
+// Run processes all rounds following the last processed round, and then the rounds of new blocks
+// as they are finalized, until the context is canceled or a chain inconsistency is detected.
+func (p *Publisher) Run(ctx context.Context) error {
+	return p.p.Run(ctx)
+}
+
+// handle publishes the events of the given round. It only returns once all events have been
+// published or the context is canceled.
+func (p *Publisher) handle(ctx context.Context, tx processor.Tx, data *indexer.RoundData) error {
+	msgs := Messages(data, p.cfg.Modules...)
+	if len(msgs) == 0 {
+		return nil
+	}
+	return p.publish(ctx, data.Block.Round, msgs)
+}
+
+// publish publishes the messages, retrying until it succeeds or the context is canceled.
+func (p *Publisher) publish(ctx context.Context, round uint64, msgs []*Message) error {
+	backoff := p.cfg.InitialBackoff
+	for {
+		err := p.cfg.Sink.Publish(ctx, msgs)
+		if err == nil {
+			return nil
+		}
+		if p.cfg.OnPublishError != nil {
+			p.cfg.OnPublishError(round, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > p.cfg.MaxBackoff {
+			backoff = p.cfg.MaxBackoff
+		}
+	}
+}
+
+// New creates a new publisher of the events of the runtime of the given client.
+func New(rc client.RuntimeClient, cfg Config) (*Publisher, error) {
+	if cfg.Sink == nil {
+		return nil, fmt.Errorf("eventsink: no sink configured")
+	}
+	if cfg.InitialBackoff == 0 {
+		cfg.InitialBackoff = DefaultInitialBackoff
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+
+	p := &Publisher{cfg: cfg}
+	proc, err := processor.New(rc, processor.Config{
+		Handler:        p.handle,
+		Store:          cfg.Store,
+		StartRound:     cfg.StartRound,
+		InitialBackoff: cfg.InitialBackoff,
+		MaxBackoff:     cfg.MaxBackoff,
+		OnError:        cfg.OnError,
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.p = proc
+	return p, nil
+}
@@ -0,0 +1,120 @@
+package eventstream
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	protoCodec "google.golang.org/grpc/encoding/proto"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+var (
+	baseSchemaOnce sync.Once
+	baseSchema     *Schema
+)
+
+// getBaseSchema returns the schema without any module events, whose fixed messages are the same
+// as those of any server.
+func getBaseSchema() *Schema {
+	baseSchemaOnce.Do(func() {
+		var err error
+		if baseSchema, err = newSchema(nil); err != nil {
+			panic(err)
+		}
+	})
+	return baseSchema
+}
+
+// Client is a client of the event streaming service.
+type Client struct {
+	conn *grpc.ClientConn
+}
+
+// callOptions returns the options of all calls, which use the protobuf codec also on connections
+// created by the gRPC helpers of oasis-core, which default to CBOR.
+func callOptions() []grpc.CallOption {
+	return []grpc.CallOption{grpc.ForceCodec(encoding.GetCodec(protoCodec.Name))}
+}
+
+// Schema fetches the schema of the service, which describes the messages of the event stream.
+func (c *Client) Schema(ctx context.Context) (protoreflect.FileDescriptor, error) {
+	base := getBaseSchema()
+	req := dynamicpb.NewMessage(base.message(messageGetSchemaRequest))
+	rsp := dynamicpb.NewMessage(base.message(messageGetSchemaResponse))
+	if err := c.conn.Invoke(ctx, "/"+serviceName+"/"+methodGetSchema, req, rsp, callOptions()...); err != nil {
+		return nil, err
+	}
+
+	var fdp descriptorpb.FileDescriptorProto
+	if err := proto.Unmarshal(rsp.Get(rsp.Descriptor().Fields().ByName("file_descriptor")).Bytes(), &fdp); err != nil {
+		return nil, fmt.Errorf("eventstream: malformed schema: %w", err)
+	}
+	file, err := protodesc.NewFile(&fdp, new(protoregistry.Files))
+	if err != nil {
+		return nil, fmt.Errorf("eventstream: invalid schema: %w", err)
+	}
+	return file, nil
+}
+
+// Watch streams the events of all rounds starting at the given round, only including events of
+// the given modules if any are given. If the round is zero, the stream starts at the round of the
+// next block.
+//
+// Messages are decoded using the given schema, as returned by Schema. To resume a stream, watch
+// again starting at the round following the round of the last received message.
+func (c *Client) Watch(ctx context.Context, schema protoreflect.FileDescriptor, fromRound uint64, modules ...string) (*Stream, error) {
+	stream, err := c.conn.NewStream(ctx, &grpc.StreamDesc{
+		StreamName:    methodWatch,
+		ServerStreams: true,
+	}, "/"+serviceName+"/"+methodWatch, callOptions()...)
+	if err != nil {
+		return nil, err
+	}
+
+	req := dynamicpb.NewMessage(schema.Messages().ByName(messageWatchRequest))
+	fields := req.Descriptor().Fields()
+	req.Set(fields.ByName("from_round"), protoreflect.ValueOfUint64(fromRound))
+	reqModules := req.Mutable(fields.ByName("modules")).List()
+	for _, m := range modules {
+		reqModules.Append(protoreflect.ValueOfString(m))
+	}
+	if err = stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err = stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return &Stream{
+		stream:      stream,
+		roundEvents: schema.Messages().ByName(messageRoundEvents),
+	}, nil
+}
+
+// Stream is a stream of the events of consecutive rounds.
+type Stream struct {
+	stream      grpc.ClientStream
+	roundEvents protoreflect.MessageDescriptor
+}
+
+// Recv receives the RoundEvents message of the next round.
+func (s *Stream) Recv() (*dynamicpb.Message, error) {
+	msg := dynamicpb.NewMessage(s.roundEvents)
+	if err := s.stream.RecvMsg(msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// NewClient creates a new client of the event streaming service reachable via the given
+// connection.
+func NewClient(conn *grpc.ClientConn) *Client {
+	return &Client{conn: conn}
+}
@@ -0,0 +1,227 @@
+package eventstream
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/indexer"
+)
+
+// encodeRound encodes the events of the given round, only including events of the given modules
+// if any are given.
+func (s *Schema) encodeRound(data *indexer.RoundData, modules map[string]struct{}) (*dynamicpb.Message, error) {
+	msg := dynamicpb.NewMessage(s.message(messageRoundEvents))
+	fields := msg.Descriptor().Fields()
+	msg.Set(fields.ByName("round"), protoreflect.ValueOfUint64(data.Block.Round))
+	msg.Set(fields.ByName("block_hash"), protoreflect.ValueOfBytes(data.Block.Hash[:]))
+	msg.Set(fields.ByName("timestamp"), protoreflect.ValueOfUint64(data.Block.Timestamp))
+
+	events := msg.Mutable(fields.ByName("events")).List()
+	for _, ev := range data.Events {
+		if _, ok := modules[ev.Module]; len(modules) > 0 && !ok {
+			continue
+		}
+		encoded, err := s.encodeEvent(data, ev)
+		if err != nil {
+			return nil, err
+		}
+		events.Append(protoreflect.ValueOfMessage(encoded))
+	}
+	return msg, nil
+}
+
+func (s *Schema) encodeEvent(data *indexer.RoundData, ev *indexer.Event) (*dynamicpb.Message, error) {
+	msg := dynamicpb.NewMessage(s.message(messageEvent))
+	fields := msg.Descriptor().Fields()
+	msg.Set(fields.ByName("index"), protoreflect.ValueOfUint32(ev.Index))
+	msg.Set(fields.ByName("module"), protoreflect.ValueOfString(ev.Module))
+	msg.Set(fields.ByName("code"), protoreflect.ValueOfUint32(ev.Code))
+	msg.Set(fields.ByName("value"), protoreflect.ValueOfBytes(ev.Value))
+	if ev.TxIndex != nil {
+		msg.Set(fields.ByName("tx_index"), protoreflect.ValueOfUint32(*ev.TxIndex))
+		if int(*ev.TxIndex) < len(data.Transactions) {
+			txHash := data.Transactions[*ev.TxIndex].Hash
+			msg.Set(fields.ByName("tx_hash"), protoreflect.ValueOfBytes(txHash[:]))
+		}
+	}
+
+	variant, ok := s.events[eventKey{ev.Module, ev.Code}]
+	if !ok {
+		return msg, nil
+	}
+	value, err := variant.event.DecodeValue(ev.Value)
+	if err != nil {
+		// Keep events that cannot be decoded, which are still available in their raw form.
+		return msg, nil
+	}
+	decoded, err := s.encodeMessage(variant.field.Message(), reflect.ValueOf(value))
+	if err != nil {
+		return nil, fmt.Errorf("eventstream: failed to encode event %s.%s: %w", ev.Module, variant.event.Name, err)
+	}
+	msg.Set(variant.field, protoreflect.ValueOfMessage(decoded))
+	return msg, nil
+}
+
+// encodeMessage encodes the given struct value as a message of the given type.
+func (s *Schema) encodeMessage(md protoreflect.MessageDescriptor, v reflect.Value) (*dynamicpb.Message, error) {
+	msg := dynamicpb.NewMessage(md)
+	v = indirectValue(v)
+	if !v.IsValid() {
+		return msg, nil
+	}
+	for _, f := range s.fields[md.FullName()] {
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok {
+			continue
+		}
+		if err := s.setField(msg, md.Fields().ByNumber(f.number), fv); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
+
+func (s *Schema) setField(msg *dynamicpb.Message, fd protoreflect.FieldDescriptor, v reflect.Value) error {
+	v = indirectValue(v)
+	if !v.IsValid() {
+		return nil
+	}
+	switch {
+	case fd.IsMap():
+		if v.Kind() != reflect.Map {
+			break
+		}
+		m := msg.Mutable(fd).Map()
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := s.scalarValue(fd.MapKey(), iter.Key())
+			if err != nil {
+				return err
+			}
+			value, err := s.value(fd.MapValue(), iter.Value())
+			if err != nil {
+				return err
+			}
+			m.Set(key.MapKey(), value)
+		}
+		return nil
+	case fd.IsList():
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			break
+		}
+		l := msg.Mutable(fd).List()
+		for i := 0; i < v.Len(); i++ {
+			value, err := s.value(fd, v.Index(i))
+			if err != nil {
+				return err
+			}
+			l.Append(value)
+		}
+		return nil
+	}
+	value, err := s.value(fd, v)
+	if err != nil {
+		return err
+	}
+	msg.Set(fd, value)
+	return nil
+}
+
+// value encodes a single value of the given field.
+func (s *Schema) value(fd protoreflect.FieldDescriptor, v reflect.Value) (protoreflect.Value, error) {
+	if fd.Kind() != protoreflect.MessageKind {
+		return s.scalarValue(fd, v)
+	}
+	msg, err := s.encodeMessage(fd.Message(), v)
+	if err != nil {
+		return protoreflect.Value{}, err
+	}
+	return protoreflect.ValueOfMessage(msg), nil
+}
+
+func (s *Schema) scalarValue(fd protoreflect.FieldDescriptor, v reflect.Value) (protoreflect.Value, error) {
+	v = indirectValue(v)
+	if !v.IsValid() {
+		return fd.Default(), nil
+	}
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		if tm, ok := textMarshaler(v); ok {
+			text, err := tm.MarshalText()
+			if err != nil {
+				return protoreflect.Value{}, err
+			}
+			return protoreflect.ValueOfString(string(text)), nil
+		}
+		return protoreflect.ValueOfString(v.String()), nil
+	case protoreflect.BytesKind:
+		if isBytes(v.Type()) {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return protoreflect.ValueOfBytes(b), nil
+		}
+		return protoreflect.ValueOfBytes(cbor.Marshal(v.Interface())), nil
+	case protoreflect.BoolKind:
+		return protoreflect.ValueOfBool(v.Bool()), nil
+	case protoreflect.Int64Kind:
+		return protoreflect.ValueOfInt64(v.Int()), nil
+	case protoreflect.Uint64Kind:
+		return protoreflect.ValueOfUint64(v.Uint()), nil
+	case protoreflect.DoubleKind:
+		return protoreflect.ValueOfFloat64(v.Float()), nil
+	default:
+		return protoreflect.Value{}, fmt.Errorf("unsupported field kind %s", fd.Kind())
+	}
+}
+
+// textMarshaler returns the text marshaler of the given value, if its type has a text encoding.
+func textMarshaler(v reflect.Value) (encoding.TextMarshaler, bool) {
+	if !isText(v.Type()) {
+		return nil, false
+	}
+	if tm, ok := v.Interface().(encoding.TextMarshaler); ok {
+		return tm, true
+	}
+	// The method has a pointer receiver, so marshal an addressable copy.
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	tm, ok := p.Interface().(encoding.TextMarshaler)
+	return tm, ok
+}
+
+// fieldByIndex returns the nested struct field with the given index, or false if an embedded
+// struct pointer on the way is nil.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 {
+			v = indirectValue(v)
+			if !v.IsValid() {
+				return reflect.Value{}, false
+			}
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// indirectValue dereferences pointers, returning an invalid value if any is nil. Nil interfaces
+// are also returned as invalid values, while others are kept, as their schema only depends on the
+// interface type.
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		if v.Kind() == reflect.Interface {
+			break
+		}
+		v = v.Elem()
+	}
+	return v
+}
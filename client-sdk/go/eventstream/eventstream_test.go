@@ -0,0 +1,187 @@
+package eventstream
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/evm"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/roflmarket"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/fakeruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func nativeUnits(amount uint64) types.BaseUnits {
+	return types.NewBaseUnits(*quantity.NewFromUint64(amount), types.NativeDenomination)
+}
+
+func transfer(ctx context.Context, t *testing.T, rt client.RuntimeClient, to types.Address, nonce uint64) {
+	tb := accounts.NewV1(rt).Transfer(to, nativeUnits(10))
+	tb.AppendAuthSignature(sdkTesting.Alice.SigSpec, nonce)
+	require.NoError(t, tb.AppendSign(ctx, sdkTesting.Alice.Signer), "AppendSign")
+	_, err := tb.SubmitTxMeta(ctx, nil)
+	require.NoError(t, err, "SubmitTxMeta")
+}
+
+// field returns the value of the field with the given name of the given message.
+func field(msg protoreflect.Message, name string) protoreflect.Value {
+	return msg.Get(msg.Descriptor().Fields().ByName(protoreflect.Name(name)))
+}
+
+func TestSchema(t *testing.T) {
+	require := require.New(t)
+
+	schema, err := NewSchema()
+	require.NoError(err, "NewSchema")
+
+	transfer := schema.File().Messages().ByName("AccountsTransferEvent")
+	require.NotNil(transfer, "message of the transfer event")
+	require.Equal(protoreflect.StringKind, transfer.Fields().ByName("to").Kind(), "addresses should be strings")
+	require.Equal(protoreflect.BytesKind, transfer.Fields().ByName("memo").Kind())
+	amount := transfer.Fields().ByName("amount").Message()
+	require.Equal(protoreflect.FullName(protoPackage+".TypesBaseUnits"), amount.FullName())
+	require.Equal(protoreflect.StringKind, amount.Fields().ByName("amount").Kind(), "quantities should be strings")
+
+	log := schema.File().Messages().ByName("EvmLogEvent")
+	require.NotNil(log, "message of the EVM log event")
+	require.True(log.Fields().ByName("topics").IsList(), "topics should be repeated")
+
+	// Events sharing a value type should share its message.
+	event := schema.File().Messages().ByName(messageEvent)
+	decoded := event.Oneofs().ByName("decoded")
+	require.NotNil(decoded.Fields().ByName("roflmarket_provider_created"))
+	require.Equal(
+		decoded.Fields().ByName("roflmarket_provider_created").Message(),
+		decoded.Fields().ByName("roflmarket_provider_removed").Message(),
+	)
+	require.EqualValues(firstEventFieldNumber, decoded.Fields().Get(0).Number())
+
+	svc := schema.File().Services().ByName("EventStream")
+	require.NotNil(svc, "service")
+	require.True(svc.Methods().ByName(methodWatch).IsStreamingServer())
+
+	require.Equal(protoFile, schema.Proto().GetName())
+}
+
+func TestStream(t *testing.T) {
+	require := require.New(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rt := fakeruntime.New(fakeruntime.Config{})
+	rt.SetBalance(sdkTesting.Alice.Address, nativeUnits(100))
+	transfer(ctx, t, rt, sdkTesting.Bob.Address, 0)
+	transfer(ctx, t, rt, sdkTesting.Charlie.Address, 1)
+
+	srv, err := NewServer(rt)
+	require.NoError(err, "NewServer")
+	grpcSrv := grpc.NewServer()
+	srv.Register(grpcSrv)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err, "Listen")
+	go func() { _ = grpcSrv.Serve(listener) }()
+	defer grpcSrv.Stop()
+
+	conn, err := grpc.DialContext(ctx, listener.Addr().String(), grpc.WithInsecure())
+	require.NoError(err, "Dial")
+	defer conn.Close()
+	c := NewClient(conn)
+
+	schema, err := c.Schema(ctx)
+	require.NoError(err, "Schema")
+	require.Equal(srv.Schema().File().Messages().Len(), schema.Messages().Len())
+
+	recv := func(stream *Stream, round uint64) *dynamicpb.Message {
+		msg, err := stream.Recv()
+		require.NoError(err, "Recv")
+		require.Equal(round, field(msg, "round").Uint())
+		return msg
+	}
+
+	// Resuming from a past round should stream all following rounds.
+	stream, err := c.Watch(ctx, schema, 2)
+	require.NoError(err, "Watch")
+	msg := recv(stream, 2)
+	events := field(msg, "events").List()
+	require.Equal(1, events.Len())
+	ev := events.Get(0).Message()
+	require.Equal(accounts.ModuleName, field(ev, "module").String())
+	require.EqualValues(0, field(ev, "tx_index").Uint())
+	require.Len(field(ev, "tx_hash").Bytes(), 32, "transaction hash")
+	decoded := field(ev, "accounts_transfer").Message()
+	require.Equal(sdkTesting.Charlie.Address.String(), field(decoded, "to").String())
+	require.Equal("10", field(field(decoded, "amount").Message(), "amount").String())
+
+	// New rounds should be streamed as they are finalized.
+	transfer(ctx, t, rt, sdkTesting.Bob.Address, 2)
+	recv(stream, 3)
+
+	// Events can be filtered by module.
+	filtered, err := c.Watch(ctx, schema, 1, "evm")
+	require.NoError(err, "Watch")
+	for round := uint64(1); round <= 3; round++ {
+		require.Equal(0, field(recv(filtered, round), "events").List().Len(), "filtered events")
+	}
+}
+
+type testEvent struct {
+	ID       uint64                      `json:"id"`
+	Owner    types.Address               `json:"owner"`
+	Amounts  map[string]types.BaseUnits  `json:"amounts"`
+	Labels   map[string]string           `json:"labels,omitempty"`
+	Nested   struct{ Flag bool }         `json:"nested"`
+	Matrix   [][]uint64                  `json:"matrix"`
+	Any      interface{}                 `json:"any"`
+	Skipped  string                      `json:"-"`
+	Optional *types.BaseUnits            `json:"optional,omitempty"`
+	ByTarget map[types.Address][]*uint64 `json:"by_target"`
+}
+
+func TestEncodeMessage(t *testing.T) {
+	require := require.New(t)
+
+	schema, err := newSchema([]*registry.Module{{
+		Name:   "test",
+		Events: []*registry.Event{{Code: 1, Name: "Test", Value: &testEvent{}}},
+	}})
+	require.NoError(err, "newSchema")
+	md := schema.File().Messages().ByName("EventstreamTestEvent")
+	require.NotNil(md, "message of the event")
+	require.True(md.Fields().ByName("amounts").IsMap(), "maps with scalar keys should be maps")
+	require.Equal(protoreflect.BytesKind, md.Fields().ByName("matrix").Kind(), "nested lists should be CBOR-encoded")
+	require.Equal(protoreflect.BytesKind, md.Fields().ByName("any").Kind(), "interfaces should be CBOR-encoded")
+	require.Nil(md.Fields().ByName("skipped"))
+
+	ev := &testEvent{
+		ID:      42,
+		Owner:   sdkTesting.Alice.Address,
+		Amounts: map[string]types.BaseUnits{"fee": nativeUnits(5)},
+		Matrix:  [][]uint64{{1, 2}},
+		Any:     "hello",
+	}
+	ev.Nested.Flag = true
+	msg, err := schema.encodeMessage(md, reflect.ValueOf(ev))
+	require.NoError(err, "encodeMessage")
+	require.EqualValues(42, field(msg, "id").Uint())
+	require.Equal(sdkTesting.Alice.Address.String(), field(msg, "owner").String())
+	fee := field(msg, "amounts").Map().Get(protoreflect.ValueOfString("fee").MapKey()).Message()
+	require.Equal("5", field(fee, "amount").String())
+	require.True(field(field(msg, "nested").Message(), "flag").Bool())
+	require.Equal(cbor.Marshal(ev.Matrix), field(msg, "matrix").Bytes())
+	require.Equal(cbor.Marshal(ev.Any), field(msg, "any").Bytes())
+	require.False(msg.Has(md.Fields().ByName("optional")), "nil pointers should be unset")
+}
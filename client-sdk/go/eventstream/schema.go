@@ -0,0 +1,486 @@
+package eventstream
+
+import (
+	"encoding"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+)
+
+const (
+	// protoPackage is the protobuf package of the schema.
+	protoPackage = "oasis_sdk.eventstream.v1"
+	// protoFile is the path of the schema file.
+	protoFile = "oasis_sdk/eventstream/v1/eventstream.proto"
+
+	// serviceName is the name of the gRPC service.
+	serviceName = protoPackage + ".EventStream"
+
+	// firstEventFieldNumber is the field number of the first decoded event variant of the Event
+	// message. Lower numbers are reserved for its fixed fields.
+	firstEventFieldNumber = 16
+)
+
+// Names of the fixed messages of the schema.
+const (
+	messageWatchRequest      = "WatchRequest"
+	messageRoundEvents       = "RoundEvents"
+	messageEvent             = "Event"
+	messageGetSchemaRequest  = "GetSchemaRequest"
+	messageGetSchemaResponse = "GetSchemaResponse"
+)
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// Schema is the protobuf schema of the event stream, generated from the events of the modules in
+// the module registry.
+//
+// Every registered event value type is represented by a message named after its Go type, e.g.
+// AccountsTransferEvent, and the Event message has a variant for every registered event. Field
+// numbers of the variants are assigned in the order of the registry, so consumers should obtain
+// the schema from the server they connect to, e.g. via the GetSchema method.
+type Schema struct {
+	file protoreflect.FileDescriptor
+
+	// fields are the Go struct fields of each message generated from a struct type.
+	fields map[protoreflect.FullName][]goField
+	// events are the registered events with their variant fields of the Event message, by module
+	// and event code.
+	events map[eventKey]*eventVariant
+}
+
+// goField is a Go struct field backing a message field.
+type goField struct {
+	number protoreflect.FieldNumber
+	index  []int
+}
+
+type eventKey struct {
+	module string
+	code   uint32
+}
+
+type eventVariant struct {
+	event *registry.Event
+	field protoreflect.FieldDescriptor
+}
+
+// File returns the descriptor of the schema file.
+func (s *Schema) File() protoreflect.FileDescriptor {
+	return s.file
+}
+
+// Proto returns the schema file as a descriptor proto, e.g. for generating code with protoc.
+func (s *Schema) Proto() *descriptorpb.FileDescriptorProto {
+	return protodesc.ToFileDescriptorProto(s.file)
+}
+
+func (s *Schema) message(name protoreflect.Name) protoreflect.MessageDescriptor {
+	return s.file.Messages().ByName(name)
+}
+
+// NewSchema generates the schema of the events of the currently registered modules.
+func NewSchema() (*Schema, error) {
+	return newSchema(registry.Modules())
+}
+
+func newSchema(modules []*registry.Module) (*Schema, error) {
+	b := &schemaBuilder{
+		fdp: &descriptorpb.FileDescriptorProto{
+			Name:    proto.String(protoFile),
+			Package: proto.String(protoPackage),
+			Syntax:  proto.String("proto3"),
+		},
+		messages: make(map[reflect.Type]string),
+		fields:   make(map[protoreflect.FullName][]goField),
+	}
+	b.addFixedMessages()
+
+	event := &descriptorpb.DescriptorProto{
+		Name: proto.String(messageEvent),
+		// The index is the index of the event within the round. Events emitted by transactions
+		// have the index and hash of the transaction set, and the value is always the raw
+		// CBOR-encoded event value.
+		Field: []*descriptorpb.FieldDescriptorProto{
+			scalarField("index", 1, descriptorpb.FieldDescriptorProto_TYPE_UINT32),
+			scalarField("module", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			scalarField("code", 3, descriptorpb.FieldDescriptorProto_TYPE_UINT32),
+			scalarField("tx_index", 4, descriptorpb.FieldDescriptorProto_TYPE_UINT32),
+			scalarField("tx_hash", 5, descriptorpb.FieldDescriptorProto_TYPE_BYTES),
+			scalarField("value", 6, descriptorpb.FieldDescriptorProto_TYPE_BYTES),
+		},
+	}
+	b.fdp.MessageType = append(b.fdp.MessageType, event)
+
+	type variant struct {
+		key   eventKey
+		event *registry.Event
+		name  string
+	}
+	var variants []variant
+	number := int32(firstEventFieldNumber)
+	for _, m := range modules {
+		for _, ev := range m.Events {
+			name := snakeCase(m.Name) + "_" + snakeCase(ev.Name)
+			typeName := b.messageOf(reflect.TypeOf(ev.Value))
+			if typeName == "" {
+				return nil, fmt.Errorf("eventstream: event %s.%s: value is not a struct", m.Name, ev.Name)
+			}
+			event.Field = append(event.Field, &descriptorpb.FieldDescriptorProto{
+				Name:       proto.String(name),
+				Number:     proto.Int32(number),
+				Label:      descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:       descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName:   proto.String(typeName),
+				OneofIndex: proto.Int32(0),
+			})
+			variants = append(variants, variant{eventKey{m.Name, ev.Code}, ev, name})
+			number++
+		}
+	}
+	if len(variants) > 0 {
+		event.OneofDecl = []*descriptorpb.OneofDescriptorProto{{Name: proto.String("decoded")}}
+	}
+	b.addService()
+
+	file, err := protodesc.NewFile(b.fdp, new(protoregistry.Files))
+	if err != nil {
+		return nil, fmt.Errorf("eventstream: invalid schema: %w", err)
+	}
+	s := &Schema{
+		file:   file,
+		fields: b.fields,
+		events: make(map[eventKey]*eventVariant),
+	}
+	eventDesc := s.message(messageEvent)
+	for _, v := range variants {
+		s.events[v.key] = &eventVariant{
+			event: v.event,
+			field: eventDesc.Fields().ByName(protoreflect.Name(v.name)),
+		}
+	}
+	return s, nil
+}
+
+type schemaBuilder struct {
+	fdp *descriptorpb.FileDescriptorProto
+
+	// messages are the fully qualified names of the messages generated from named struct types.
+	messages map[reflect.Type]string
+	fields   map[protoreflect.FullName][]goField
+}
+
+func (b *schemaBuilder) addFixedMessages() {
+	roundEvents := &descriptorpb.DescriptorProto{
+		Name: proto.String(messageRoundEvents),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			scalarField("round", 1, descriptorpb.FieldDescriptorProto_TYPE_UINT64),
+			scalarField("block_hash", 2, descriptorpb.FieldDescriptorProto_TYPE_BYTES),
+			scalarField("timestamp", 3, descriptorpb.FieldDescriptorProto_TYPE_UINT64),
+			{
+				Name:     proto.String("events"),
+				Number:   proto.Int32(4),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: proto.String(qualifiedName(messageEvent)),
+			},
+		},
+	}
+	modules := scalarField("modules", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING)
+	modules.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	b.fdp.MessageType = append(b.fdp.MessageType,
+		&descriptorpb.DescriptorProto{
+			Name: proto.String(messageWatchRequest),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				scalarField("from_round", 1, descriptorpb.FieldDescriptorProto_TYPE_UINT64),
+				modules,
+			},
+		},
+		roundEvents,
+		&descriptorpb.DescriptorProto{Name: proto.String(messageGetSchemaRequest)},
+		&descriptorpb.DescriptorProto{
+			Name: proto.String(messageGetSchemaResponse),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				scalarField("file_descriptor", 1, descriptorpb.FieldDescriptorProto_TYPE_BYTES),
+			},
+		},
+	)
+}
+
+func (b *schemaBuilder) addService() {
+	b.fdp.Service = append(b.fdp.Service, &descriptorpb.ServiceDescriptorProto{
+		Name: proto.String(strings.TrimPrefix(serviceName, protoPackage+".")),
+		Method: []*descriptorpb.MethodDescriptorProto{
+			{
+				Name:            proto.String(methodWatch),
+				InputType:       proto.String(qualifiedName(messageWatchRequest)),
+				OutputType:      proto.String(qualifiedName(messageRoundEvents)),
+				ServerStreaming: proto.Bool(true),
+			},
+			{
+				Name:       proto.String(methodGetSchema),
+				InputType:  proto.String(qualifiedName(messageGetSchemaRequest)),
+				OutputType: proto.String(qualifiedName(messageGetSchemaResponse)),
+			},
+		},
+	})
+}
+
+// messageOf returns the fully qualified name of the message generated from the given named struct
+// type, generating it if needed. It returns an empty name for other types.
+func (b *schemaBuilder) messageOf(t reflect.Type) string {
+	t = indirectType(t)
+	if t.Kind() != reflect.Struct || t.Name() == "" || isText(t) {
+		return ""
+	}
+	if name, ok := b.messages[t]; ok {
+		return name
+	}
+	msg := &descriptorpb.DescriptorProto{Name: proto.String(camelCase(path.Base(t.PkgPath())) + camelCase(t.Name()))}
+	name := qualifiedName(msg.GetName())
+	// Register the name first, so that recursive types terminate.
+	b.messages[t] = name
+	b.fdp.MessageType = append(b.fdp.MessageType, msg)
+	b.addFields(msg, name, t)
+	return name
+}
+
+// addFields adds the fields of the given struct type to the given message, following the naming
+// rules of encoding/json for tags and embedded structs.
+func (b *schemaBuilder) addFields(msg *descriptorpb.DescriptorProto, msgName string, t reflect.Type) {
+	var add func(t reflect.Type, index []int)
+	add = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name := tag
+			if i := strings.IndexByte(tag, ','); i >= 0 {
+				name = tag[:i]
+			}
+			fieldIndex := append(append([]int{}, index...), i)
+			if f.Anonymous && name == "" && indirectType(f.Type).Kind() == reflect.Struct {
+				add(indirectType(f.Type), fieldIndex)
+				continue
+			}
+			if f.PkgPath != "" {
+				// Unexported field.
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+
+			number := int32(len(msg.Field) + 1)
+			field := b.fieldOf(msg, msgName, snakeCase(name), f.Type)
+			field.Number = proto.Int32(number)
+			msg.Field = append(msg.Field, field)
+			fullName := protoreflect.FullName(strings.TrimPrefix(msgName, "."))
+			b.fields[fullName] = append(b.fields[fullName], goField{
+				number: protoreflect.FieldNumber(number),
+				index:  fieldIndex,
+			})
+		}
+	}
+	add(t, nil)
+}
+
+// fieldOf returns the field of the given message representing a value of the given type. Values
+// without a protobuf representation, like interfaces or nested lists, are given as their CBOR
+// encoding.
+func (b *schemaBuilder) fieldOf(msg *descriptorpb.DescriptorProto, msgName, name string, t reflect.Type) *descriptorpb.FieldDescriptorProto {
+	t = indirectType(t)
+	field := &descriptorpb.FieldDescriptorProto{
+		Name:  proto.String(name),
+		Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+	if isBytes(t) {
+		field.Type = descriptorpb.FieldDescriptorProto_TYPE_BYTES.Enum()
+		return field
+	}
+	if typ, ok := scalarType(t); ok {
+		field.Type = typ.Enum()
+		return field
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		elem := indirectType(t.Elem())
+		if isBytes(elem) {
+			field.Type = descriptorpb.FieldDescriptorProto_TYPE_BYTES.Enum()
+		} else if typ, ok := scalarType(elem); ok {
+			field.Type = typ.Enum()
+		} else if typeName := b.elemMessageOf(msg, msgName, name, elem); typeName != "" {
+			field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+			field.TypeName = proto.String(typeName)
+		} else {
+			break
+		}
+		field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		return field
+	case reflect.Map:
+		key, ok := scalarType(indirectType(t.Key()))
+		if !ok || key == descriptorpb.FieldDescriptorProto_TYPE_DOUBLE {
+			break
+		}
+		entryName := camelCase(name) + "Entry"
+		entry := &descriptorpb.DescriptorProto{
+			Name:    proto.String(entryName),
+			Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+		}
+		value := indirectType(t.Elem())
+		valueField := &descriptorpb.FieldDescriptorProto{
+			Name:  proto.String("value"),
+			Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+		if isBytes(value) {
+			valueField.Type = descriptorpb.FieldDescriptorProto_TYPE_BYTES.Enum()
+		} else if typ, ok := scalarType(value); ok {
+			valueField.Type = typ.Enum()
+		} else if typeName := b.elemMessageOf(msg, msgName, name, value); typeName != "" {
+			valueField.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+			valueField.TypeName = proto.String(typeName)
+		} else {
+			break
+		}
+		keyField := scalarField("key", 1, key)
+		valueField.Number = proto.Int32(2)
+		entry.Field = []*descriptorpb.FieldDescriptorProto{keyField, valueField}
+		msg.NestedType = append(msg.NestedType, entry)
+
+		field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+		field.TypeName = proto.String(msgName + "." + entryName)
+		return field
+	case reflect.Struct:
+		if typeName := b.elemMessageOf(msg, msgName, name, t); typeName != "" {
+			field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+			field.TypeName = proto.String(typeName)
+			return field
+		}
+	}
+	field.Type = descriptorpb.FieldDescriptorProto_TYPE_BYTES.Enum()
+	return field
+}
+
+// elemMessageOf returns the fully qualified name of the message representing a struct value of the
+// given field. Anonymous structs are represented by messages nested in the message of the field.
+func (b *schemaBuilder) elemMessageOf(msg *descriptorpb.DescriptorProto, msgName, name string, t reflect.Type) string {
+	if t.Kind() != reflect.Struct || isText(t) {
+		return ""
+	}
+	if t.Name() != "" {
+		return b.messageOf(t)
+	}
+	nested := &descriptorpb.DescriptorProto{Name: proto.String(camelCase(name))}
+	nestedName := msgName + "." + nested.GetName()
+	msg.NestedType = append(msg.NestedType, nested)
+	b.addFields(nested, nestedName, t)
+	return nestedName
+}
+
+// scalarType returns the protobuf scalar type representing values of the given type. Types with a
+// text encoding, like addresses and amounts, are represented by strings.
+func scalarType(t reflect.Type) (descriptorpb.FieldDescriptorProto_Type, bool) {
+	if isText(t) {
+		return descriptorpb.FieldDescriptorProto_TYPE_STRING, true
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return descriptorpb.FieldDescriptorProto_TYPE_BOOL, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return descriptorpb.FieldDescriptorProto_TYPE_INT64, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return descriptorpb.FieldDescriptorProto_TYPE_UINT64, true
+	case reflect.Float32, reflect.Float64:
+		return descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, true
+	case reflect.String:
+		return descriptorpb.FieldDescriptorProto_TYPE_STRING, true
+	default:
+		return 0, false
+	}
+}
+
+func scalarField(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+	return &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:   typ.Enum(),
+	}
+}
+
+// isText checks whether values of the given type have a text encoding.
+func isText(t reflect.Type) bool {
+	return t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType)
+}
+
+// isBytes checks whether the given type is a byte string without a text encoding.
+func isBytes(t reflect.Type) bool {
+	return !isText(t) && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() == reflect.Uint8
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func qualifiedName(name string) string {
+	return "." + protoPackage + "." + name
+}
+
+// snakeCase converts a Go or JSON name like "TransferEvent" to a protobuf field name like
+// "transfer_event".
+func snakeCase(s string) string {
+	var sb strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			// Start a new word, except within acronyms like "ID".
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(unicode.ToLower(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			sb.WriteRune(r)
+		default:
+			sb.WriteByte('_')
+		}
+	}
+	return sb.String()
+}
+
+// camelCase converts a name like "transfer_event" or "roflmarket" to a protobuf message name like
+// "TransferEvent" or "Roflmarket".
+func camelCase(s string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range s {
+		switch {
+		case r == '_' || r == '-' || r == '.':
+			upper = true
+		case upper:
+			sb.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,151 @@
+// Package eventstream implements a gRPC service streaming the decoded events of a runtime.
+//
+// The service pushes the events of every round to its subscribers as protobuf messages, whose
+// schema is generated from the events of the modules in the module registry. Subscribers can
+// resume streams from a given round, so that no events are missed across reconnects.
+//
+// The protobuf schema is available via the GetSchema method of the service, e.g. for generating
+// client code with protoc, and via Schema.Proto.
+package eventstream
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/indexer"
+)
+
+// Methods of the service.
+const (
+	methodWatch     = "Watch"
+	methodGetSchema = "GetSchema"
+)
+
+// Server is the event streaming service.
+type Server struct {
+	rc     client.RuntimeClient
+	schema *Schema
+}
+
+// Schema returns the schema of the service.
+func (s *Server) Schema() *Schema {
+	return s.schema
+}
+
+// Register registers the service with the given gRPC server. The server must use the default
+// protobuf codec, unlike the servers created by the gRPC helpers of oasis-core.
+func (s *Server) Register(srv *grpc.Server) {
+	srv.RegisterService(&grpc.ServiceDesc{
+		ServiceName: serviceName,
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: methodGetSchema,
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				if err := dec(dynamicpb.NewMessage(s.schema.message(messageGetSchemaRequest))); err != nil {
+					return nil, err
+				}
+				return s.getSchema()
+			},
+		}},
+		Streams: []grpc.StreamDesc{{
+			StreamName:    methodWatch,
+			ServerStreams: true,
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				req := dynamicpb.NewMessage(s.schema.message(messageWatchRequest))
+				if err := stream.RecvMsg(req); err != nil {
+					return err
+				}
+				return s.watch(stream, req)
+			},
+		}},
+		Metadata: protoFile,
+	}, s)
+}
+
+func (s *Server) getSchema() (*dynamicpb.Message, error) {
+	fd, err := proto.Marshal(s.schema.Proto())
+	if err != nil {
+		return nil, err
+	}
+	rsp := dynamicpb.NewMessage(s.schema.message(messageGetSchemaResponse))
+	rsp.Set(rsp.Descriptor().Fields().ByName("file_descriptor"), protoreflect.ValueOfBytes(fd))
+	return rsp, nil
+}
+
+// watch streams the events of all rounds starting at the requested round, or at the round of the
+// next block if none is requested, until the stream is closed.
+func (s *Server) watch(stream grpc.ServerStream, req *dynamicpb.Message) error {
+	ctx := stream.Context()
+	fields := req.Descriptor().Fields()
+	fromRound := req.Get(fields.ByName("from_round")).Uint()
+	modules := make(map[string]struct{})
+	reqModules := req.Get(fields.ByName("modules")).List()
+	for i := 0; i < reqModules.Len(); i++ {
+		modules[reqModules.Get(i).String()] = struct{}{}
+	}
+
+	// Watch blocks before fetching the latest one, so that no blocks are missed in between.
+	blkCh, blkSub, err := s.rc.WatchBlocks(ctx)
+	if err != nil {
+		return fmt.Errorf("eventstream: failed to watch blocks: %w", err)
+	}
+	defer blkSub.Close()
+
+	latest, err := s.rc.GetBlock(ctx, client.RoundLatest)
+	if err != nil {
+		return fmt.Errorf("eventstream: failed to fetch latest block: %w", err)
+	}
+	next := latest.Header.Round + 1
+	if fromRound > 0 {
+		next = fromRound
+	}
+
+	sendUpTo := func(round uint64) error {
+		for ; next <= round; next++ {
+			data, err := indexer.FetchRound(ctx, s.rc, next)
+			if err != nil {
+				return err
+			}
+			msg, err := s.schema.encodeRound(data, modules)
+			if err != nil {
+				return err
+			}
+			if err = stream.SendMsg(msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err = sendUpTo(latest.Header.Round); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case blk, ok := <-blkCh:
+			if !ok {
+				return fmt.Errorf("eventstream: block watcher closed")
+			}
+			if err = sendUpTo(blk.Block.Header.Round); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// NewServer creates a new event streaming service for the runtime of the given client, streaming
+// the events of the currently registered modules.
+func NewServer(rc client.RuntimeClient) (*Server, error) {
+	schema, err := NewSchema()
+	if err != nil {
+		return nil, err
+	}
+	return &Server{rc: rc, schema: schema}, nil
+}
@@ -0,0 +1,74 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvHeader is the header row of CSV exports.
+var csvHeader = []string{
+	"timestamp",
+	"round",
+	"tx_hash",
+	"address",
+	"counterparty",
+	"denomination",
+	"amount",
+	"fee",
+	"fee_denomination",
+}
+
+type csvWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func (cw *csvWriter) writeHeader() error {
+	if cw.wroteHeader {
+		return nil
+	}
+	cw.wroteHeader = true
+	return cw.w.Write(csvHeader)
+}
+
+func (cw *csvWriter) Write(r *Record) error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+
+	var counterparty, fee, feeDenomination string
+	if r.Counterparty != nil {
+		counterparty = r.Counterparty.String()
+	}
+	if !r.Fee.IsZero() {
+		fee = r.Fee.String()
+		feeDenomination = r.FeeDenomination.String()
+	}
+	return cw.w.Write([]string{
+		r.Timestamp.UTC().Format(time.RFC3339),
+		strconv.FormatUint(r.Round, 10),
+		r.TxHash.Hex(),
+		r.Address.String(),
+		counterparty,
+		r.Denomination.String(),
+		r.SignedAmount(),
+		fee,
+		feeDenomination,
+	})
+}
+
+func (cw *csvWriter) Close() error {
+	// Exports without records still have a header.
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// NewCSVWriter creates a new writer of records as CSV with a header row.
+func NewCSVWriter(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
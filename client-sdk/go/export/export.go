@@ -0,0 +1,246 @@
+// Package export exports the activity of runtime accounts from an indexer as accounting records.
+//
+// Each record is a change of the balance of an exported account caused by a transaction: a
+// transfer from or to another account, or a mint or a burn, e.g. caused by deposits from and
+// withdrawals to the consensus layer. Fees paid by an exported account are reported on the first
+// record of the transaction, or on a record without an amount in case the transaction did not
+// change any balance otherwise, e.g. because it failed.
+//
+// Only the events of the accounts module are considered, so that balance changes reported by
+// multiple modules are not counted twice.
+//
+// Records are written in CSV format only, see NewCSVWriter.
+package export
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/history"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/indexer"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// pageSize is the number of transactions fetched from the indexer at once.
+const pageSize = 1000
+
+// Record is a change of the balance of an exported account.
+type Record struct {
+	// Timestamp is the time of the block of the transaction.
+	Timestamp time.Time
+	// Round is the round of the transaction.
+	Round uint64
+	// TxHash is the hash of the transaction.
+	TxHash hash.Hash
+	// Address is the exported account.
+	Address types.Address
+	// Counterparty is the other account of a transfer, or nil for mints and burns.
+	Counterparty *types.Address
+	// Denomination is the denomination of the amount.
+	Denomination types.Denomination
+	// Amount is the absolute amount by which the balance changed.
+	Amount quantity.Quantity
+	// Outgoing is true iff the balance decreased.
+	Outgoing bool
+	// Fee is the fee paid by the exported account for the transaction, if any.
+	Fee quantity.Quantity
+	// FeeDenomination is the denomination of the fee.
+	FeeDenomination types.Denomination
+
+	txIndex uint32
+}
+
+// SignedAmount returns the amount as a decimal string, with a minus sign for outgoing amounts.
+func (r *Record) SignedAmount() string {
+	if r.Outgoing && !r.Amount.IsZero() {
+		return "-" + r.Amount.String()
+	}
+	return r.Amount.String()
+}
+
+// Writer writes records in a file format.
+type Writer interface {
+	// Write writes a record.
+	Write(r *Record) error
+
+	// Close flushes the written records. It does not close the underlying writer.
+	Close() error
+}
+
+// Query selects the exported records.
+type Query struct {
+	// Addresses are the exported accounts.
+	Addresses []types.Address
+	// From is the start of the exported period, inclusive. If zero, the period is not bounded.
+	From time.Time
+	// To is the end of the exported period, exclusive. If zero, the period is not bounded.
+	To time.Time
+}
+
+func (q *Query) includes(ts time.Time) bool {
+	return (q.From.IsZero() || !ts.Before(q.From)) && (q.To.IsZero() || ts.Before(q.To))
+}
+
+// Records returns the records of the given query, ordered by round and by position within the
+// round.
+func Records(ctx context.Context, r indexer.Reader, q *Query) ([]*Record, error) {
+	blocks := make(map[uint64]*indexer.Block)
+	block := func(round uint64) (*indexer.Block, error) {
+		if blk, ok := blocks[round]; ok {
+			return blk, nil
+		}
+		blk, err := r.Block(ctx, round)
+		if err != nil {
+			return nil, fmt.Errorf("export: failed to fetch block %d: %w", round, err)
+		}
+		blocks[round] = blk
+		return blk, nil
+	}
+
+	var records []*Record
+	for _, addr := range q.Addresses {
+		pager := &history.Pager{Limit: pageSize}
+	pages:
+		for {
+			page, err := r.TransactionsForAddress(ctx, addr, pager)
+			if err != nil {
+				return nil, fmt.Errorf("export: failed to fetch transactions of %s: %w", addr, err)
+			}
+			for _, tx := range page.Transactions {
+				blk, err := block(tx.Round)
+				if err != nil {
+					return nil, err
+				}
+				ts := time.Unix(int64(blk.Timestamp), 0).UTC()
+				if !q.From.IsZero() && ts.Before(q.From) {
+					// Transactions are ordered from the most recent one.
+					break pages
+				}
+				if !q.includes(ts) {
+					continue
+				}
+
+				txRecords, err := transactionRecords(tx, addr, ts)
+				if err != nil {
+					return nil, err
+				}
+				records = append(records, txRecords...)
+			}
+			if page.Next == nil {
+				break
+			}
+			pager.Cursor = page.Next
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Round != records[j].Round {
+			return records[i].Round < records[j].Round
+		}
+		return records[i].txIndex < records[j].txIndex
+	})
+	return records, nil
+}
+
+// Export writes the records of the given query.
+func Export(ctx context.Context, r indexer.Reader, q *Query, w Writer) error {
+	records, err := Records(ctx, r, q)
+	if err != nil {
+		return err
+	}
+	for _, rec := range records {
+		if err = w.Write(rec); err != nil {
+			return fmt.Errorf("export: failed to write record: %w", err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("export: failed to write records: %w", err)
+	}
+	return nil
+}
+
+// transactionRecords returns the records of the given transaction for the given address.
+func transactionRecords(tx *history.Transaction, addr types.Address, ts time.Time) ([]*Record, error) {
+	newRecord := func(denomination types.Denomination, amount quantity.Quantity, outgoing bool) *Record {
+		return &Record{
+			Timestamp:    ts,
+			Round:        tx.Round,
+			TxHash:       tx.Hash,
+			Address:      addr,
+			Denomination: denomination,
+			Amount:       amount,
+			Outgoing:     outgoing,
+			txIndex:      tx.Index,
+		}
+	}
+
+	var records []*Record
+	for _, ev := range tx.Events {
+		if ev.Module != accounts.ModuleName {
+			continue
+		}
+		switch ev.Code {
+		case accounts.TransferEventCode:
+			var transfer accounts.TransferEvent
+			if err := cbor.Unmarshal(ev.Value, &transfer); err != nil {
+				return nil, fmt.Errorf("export: malformed transfer event of transaction %s: %w", tx.Hash, err)
+			}
+			if transfer.To.Equal(accounts.FeeAccumulatorAddress) {
+				// Fees are reported separately.
+				continue
+			}
+			var (
+				rec          *Record
+				counterparty types.Address
+			)
+			switch {
+			case transfer.From.Equal(addr) && transfer.To.Equal(addr):
+				continue
+			case transfer.From.Equal(addr):
+				rec = newRecord(transfer.Amount.Denomination, transfer.Amount.Amount, true)
+				counterparty = transfer.To
+			case transfer.To.Equal(addr):
+				rec = newRecord(transfer.Amount.Denomination, transfer.Amount.Amount, false)
+				counterparty = transfer.From
+			default:
+				continue
+			}
+			rec.Counterparty = &counterparty
+			records = append(records, rec)
+		case accounts.MintEventCode, accounts.BurnEventCode:
+			// Mint and burn events have the same structure.
+			var supplyEv accounts.MintEvent
+			if err := cbor.Unmarshal(ev.Value, &supplyEv); err != nil {
+				return nil, fmt.Errorf("export: malformed event of transaction %s: %w", tx.Hash, err)
+			}
+			if !supplyEv.Owner.Equal(addr) {
+				continue
+			}
+			records = append(records, newRecord(supplyEv.Amount.Denomination, supplyEv.Amount.Amount, ev.Code == accounts.BurnEventCode))
+		}
+	}
+
+	var decoded types.Transaction
+	if err := cbor.Unmarshal(tx.Tx.Body, &decoded); err != nil {
+		return nil, fmt.Errorf("export: malformed transaction %s: %w", tx.Hash, err)
+	}
+	fee := decoded.AuthInfo.Fee.Amount
+	if len(decoded.AuthInfo.SignerInfo) > 0 && !fee.Amount.IsZero() {
+		payer, err := decoded.AuthInfo.SignerInfo[0].AddressSpec.Address()
+		if err == nil && payer.Equal(addr) {
+			if len(records) == 0 {
+				records = append(records, newRecord(fee.Denomination, quantity.Quantity{}, true))
+			}
+			records[0].Fee = fee.Amount
+			records[0].FeeDenomination = fee.Denomination
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,83 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/indexer"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/fakeruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func nativeUnits(amount uint64) types.BaseUnits {
+	return types.NewBaseUnits(*quantity.NewFromUint64(amount), types.NativeDenomination)
+}
+
+func TestExport(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rt := fakeruntime.New(fakeruntime.Config{})
+	rt.SetBalance(sdkTesting.Alice.Address, nativeUnits(1000))
+	storage := indexer.NewMemoryStorage()
+	ix := indexer.New(rt, storage, 1)
+	transfer := func(from sdkTesting.TestKey, to types.Address, amount, fee, nonce uint64) error {
+		tb := accounts.NewV1(rt).Transfer(to, nativeUnits(amount)).SetFeeAmount(nativeUnits(fee))
+		tb.AppendAuthSignature(from.SigSpec, nonce)
+		require.NoError(tb.AppendSign(ctx, from.Signer), "AppendSign")
+		meta, err := tb.SubmitTxMeta(ctx, nil)
+		require.NotNil(meta, "SubmitTxMeta")
+		require.NoError(ix.IndexRound(ctx, meta.Round), "IndexRound")
+		return err
+	}
+	require.NoError(transfer(sdkTesting.Alice, sdkTesting.Bob.Address, 100, 5, 0))
+	require.NoError(transfer(sdkTesting.Bob, sdkTesting.Charlie.Address, 10, 0, 0))
+	// A failed transfer still pays the fee.
+	require.Error(transfer(sdkTesting.Alice, sdkTesting.Bob.Address, 10_000, 1, 1))
+
+	q := &Query{Addresses: []types.Address{sdkTesting.Alice.Address, sdkTesting.Bob.Address}}
+	records, err := Records(ctx, storage, q)
+	require.NoError(err, "Records")
+	require.Len(records, 4)
+
+	rec := records[0]
+	require.Equal(sdkTesting.Alice.Address, rec.Address)
+	require.Equal(sdkTesting.Bob.Address, *rec.Counterparty)
+	require.Equal("-100", rec.SignedAmount())
+	require.Equal(*quantity.NewFromUint64(5), rec.Fee)
+	require.Equal(sdkTesting.Bob.Address, records[1].Address)
+	require.Equal(sdkTesting.Alice.Address, *records[1].Counterparty)
+	require.Equal("100", records[1].SignedAmount())
+	require.True(records[1].Fee.IsZero(), "fees are only reported for the payer")
+	require.Equal("-10", records[2].SignedAmount())
+	rec = records[3]
+	require.Equal(sdkTesting.Alice.Address, rec.Address)
+	require.Nil(rec.Counterparty)
+	require.Equal("0", rec.SignedAmount())
+	require.Equal(*quantity.NewFromUint64(1), rec.Fee)
+
+	// Records outside of the period are not exported.
+	records, err = Records(ctx, storage, &Query{Addresses: q.Addresses, From: time.Now().Add(time.Hour)})
+	require.NoError(err, "Records")
+	require.Empty(records)
+	records, err = Records(ctx, storage, &Query{Addresses: q.Addresses, To: time.Now().Add(-time.Hour)})
+	require.NoError(err, "Records")
+	require.Empty(records)
+
+	var buf bytes.Buffer
+	require.NoError(Export(ctx, storage, q, NewCSVWriter(&buf)), "Export CSV")
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(err, "read CSV")
+	require.Len(rows, 5)
+	require.Equal(csvHeader, rows[0])
+	require.Equal([]string{sdkTesting.Alice.Address.String(), sdkTesting.Bob.Address.String(), "<native>", "-100", "5", "<native>"}, rows[1][3:])
+}
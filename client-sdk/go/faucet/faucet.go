@@ -0,0 +1,52 @@
+// Package faucet implements clients for faucets funding accounts on test networks, so that
+// examples and integration tests can obtain funds programmatically.
+package faucet
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// Faucet funds accounts on a test network.
+type Faucet interface {
+	// Fund requests the given amount to be transferred to the given account.
+	//
+	// Depending on the faucet, the funds may only become available some time after Fund returns
+	// (see WaitForBalance).
+	Fund(ctx context.Context, to types.Address, amount types.BaseUnits) error
+}
+
+// WaitForBalance waits until the balance of the given account is at least the given amount.
+func WaitForBalance(ctx context.Context, rc client.RuntimeClient, address types.Address, amount types.BaseUnits) error {
+	blkCh, blkSub, err := rc.WatchBlocks(ctx)
+	if err != nil {
+		return fmt.Errorf("faucet: failed to watch blocks: %w", err)
+	}
+	defer blkSub.Close()
+
+	ac := accounts.NewV1(rc)
+	for {
+		balances, err := ac.Balances(ctx, client.RoundLatest, address)
+		if err != nil {
+			return fmt.Errorf("faucet: failed to query balances: %w", err)
+		}
+		balance := balances.Balances[amount.Denomination]
+		if balance.Cmp(&amount.Amount) >= 0 {
+			return nil
+		}
+
+		// Check again once the next block is available.
+		select {
+		case _, ok := <-blkCh:
+			if !ok {
+				return fmt.Errorf("faucet: block subscription closed")
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
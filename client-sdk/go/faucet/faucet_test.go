@@ -0,0 +1,100 @@
+package faucet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/fakeruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func nativeUnits(amount uint64) types.BaseUnits {
+	return types.NewBaseUnits(*quantity.NewFromUint64(amount), types.NativeDenomination)
+}
+
+func TestLocalFaucet(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rt := fakeruntime.New(fakeruntime.Config{})
+	rt.SetBalance(sdkTesting.Alice.Address, nativeUnits(100))
+
+	f, err := NewLocalFaucet(rt, sdkTesting.Alice.Signer, nil)
+	require.NoError(err, "NewLocalFaucet")
+	require.Equal(sdkTesting.Alice.Address, f.Address())
+
+	for _, to := range []types.Address{sdkTesting.Dave.Address, sdkTesting.Erin.Address, sdkTesting.Dave.Address} {
+		require.NoError(f.Fund(ctx, to, nativeUnits(10)), "Fund")
+	}
+	require.NoError(WaitForBalance(ctx, rt, sdkTesting.Dave.Address, nativeUnits(20)), "WaitForBalance")
+
+	balances, err := accounts.NewV1(rt).Balances(ctx, client.RoundLatest, sdkTesting.Erin.Address)
+	require.NoError(err, "Balances")
+	require.Equal(*quantity.NewFromUint64(10), balances.Balances[types.NativeDenomination])
+
+	require.Error(f.Fund(ctx, sdkTesting.Bob.Address, nativeUnits(1000)), "Fund should fail without sufficient funds")
+}
+
+func TestWaitForBalance(t *testing.T) {
+	require := require.New(t)
+
+	rt := fakeruntime.New(fakeruntime.Config{})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	done := make(chan error)
+	go func() {
+		done <- WaitForBalance(ctx, rt, sdkTesting.Bob.Address, nativeUnits(10))
+	}()
+
+	// Wait until the balance is checked against the initial state before funding the account.
+	time.Sleep(100 * time.Millisecond)
+	rt.SetBalance(sdkTesting.Bob.Address, nativeUnits(10))
+	rt.NextBlock()
+	require.NoError(<-done, "WaitForBalance should return once the account is funded")
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(WaitForBalance(ctx, rt, sdkTesting.Bob.Address, nativeUnits(20)), context.DeadlineExceeded)
+}
+
+func TestHTTPFaucet(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	var requests []FundRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req FundRequest
+		if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&req) != nil {
+			http.Error(w, "malformed request", http.StatusBadRequest)
+			return
+		}
+		if req.Account == sdkTesting.Bob.Address.String() {
+			http.Error(w, "rate limited", http.StatusTooManyRequests)
+			return
+		}
+		requests = append(requests, req)
+	}))
+	defer srv.Close()
+
+	f := NewHTTPFaucet(srv.URL, "emerald")
+	require.NoError(f.Fund(ctx, sdkTesting.Alice.Address, nativeUnits(10)), "Fund")
+	require.Equal([]FundRequest{
+		{Account: sdkTesting.Alice.Address.String(), ParaTime: "emerald", Amount: "10"},
+	}, requests)
+
+	err := f.Fund(ctx, sdkTesting.Bob.Address, nativeUnits(10))
+	require.Error(err, "Fund should fail on error responses")
+	require.Contains(err.Error(), "rate limited")
+}
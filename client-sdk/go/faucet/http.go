@@ -0,0 +1,85 @@
+package faucet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// maxErrorBodySize is the maximum size of an error response body included in errors.
+const maxErrorBodySize = 1024
+
+var _ Faucet = (*HTTPFaucet)(nil)
+
+// FundRequest is a request to an HTTP faucet.
+type FundRequest struct {
+	// Account is the Bech32-encoded address of the account to fund.
+	Account string `json:"account"`
+	// ParaTime is the name of the ParaTime the account should be funded in. It is empty for the
+	// consensus layer.
+	ParaTime string `json:"paratime,omitempty"`
+	// Amount is the amount to fund, in base units.
+	Amount string `json:"amount"`
+	// Denomination is the denomination of the amount. It is empty for the native denomination.
+	Denomination string `json:"denomination,omitempty"`
+}
+
+// HTTPFaucet is a client for a faucet service, like the one of the public testnet, which funds
+// accounts in response to HTTP requests.
+//
+// Each funding request is sent as a JSON-encoded FundRequest in the body of a POST request to the
+// faucet URL. Any 2xx response status code is treated as success. As faucet services usually
+// only queue the transfer, the funds may only become available some time after Fund returns.
+type HTTPFaucet struct {
+	url      string
+	paraTime string
+
+	// Client is the HTTP client used for requests.
+	Client *http.Client
+}
+
+// NewHTTPFaucet creates a new client for the faucet service at the given URL, funding accounts in
+// the given ParaTime (empty for the consensus layer).
+func NewHTTPFaucet(url, paraTime string) *HTTPFaucet {
+	return &HTTPFaucet{
+		url:      url,
+		paraTime: paraTime,
+		Client:   http.DefaultClient,
+	}
+}
+
+// Fund requests the given amount to be transferred to the given account.
+func (f *HTTPFaucet) Fund(ctx context.Context, to types.Address, amount types.BaseUnits) error {
+	body, err := json.Marshal(&FundRequest{
+		Account:      to.String(),
+		ParaTime:     f.paraTime,
+		Amount:       amount.Amount.String(),
+		Denomination: string(amount.Denomination),
+	})
+	if err != nil {
+		return fmt.Errorf("faucet: failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("faucet: failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rsp, err := f.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("faucet: request failed: %w", err)
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(rsp.Body, maxErrorBodySize))
+		return fmt.Errorf("faucet: request failed with status %s: %s", rsp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package faucet
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/secp256k1"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/sr25519"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// DefaultTransferGas is the gas limit used for transfers in case no fee is configured.
+const DefaultTransferGas = 100_000
+
+var _ Faucet = (*LocalFaucet)(nil)
+
+// LocalFaucet is a faucet for local test networks, which funds accounts by transferring tokens
+// from a funded account, e.g. one of the test keys endowed in the genesis state of the network.
+//
+// Transfers are submitted one at a time, so that a single faucet can be safely shared.
+type LocalFaucet struct {
+	sync.Mutex
+
+	rc      client.RuntimeClient
+	signer  signature.Signer
+	sigspec types.SignatureAddressSpec
+	fee     types.Fee
+}
+
+// NewLocalFaucet creates a new local faucet funding accounts from the account of the given signer.
+//
+// In case the fee is nil, transfers pay no fee and use DefaultTransferGas.
+func NewLocalFaucet(rc client.RuntimeClient, signer signature.Signer, fee *types.Fee) (*LocalFaucet, error) {
+	var sigspec types.SignatureAddressSpec
+	switch pk := signer.Public().(type) {
+	case ed25519.PublicKey:
+		sigspec = types.NewSignatureAddressSpecEd25519(pk)
+	case secp256k1.PublicKey:
+		sigspec = types.NewSignatureAddressSpecSecp256k1Eth(pk)
+	case sr25519.PublicKey:
+		sigspec = types.NewSignatureAddressSpecSr25519(pk)
+	default:
+		return nil, fmt.Errorf("faucet: unsupported signer public key type: %T", pk)
+	}
+	if fee == nil {
+		fee = &types.Fee{Gas: DefaultTransferGas}
+	}
+
+	return &LocalFaucet{
+		rc:      rc,
+		signer:  signer,
+		sigspec: sigspec,
+		fee:     *fee,
+	}, nil
+}
+
+// Address returns the address of the account funds are transferred from.
+func (f *LocalFaucet) Address() types.Address {
+	return types.NewAddress(f.sigspec)
+}
+
+// Fund transfers the given amount to the given account. The funds are available once Fund returns.
+func (f *LocalFaucet) Fund(ctx context.Context, to types.Address, amount types.BaseUnits) error {
+	f.Lock()
+	defer f.Unlock()
+
+	ac := accounts.NewV1(f.rc)
+	nonce, err := ac.Nonce(ctx, client.RoundLatest, f.Address())
+	if err != nil {
+		return fmt.Errorf("faucet: failed to query nonce: %w", err)
+	}
+
+	tb := ac.Transfer(to, amount).
+		SetFeeAmount(f.fee.Amount).
+		SetFeeGas(f.fee.Gas).
+		SetFeeConsensusMessages(f.fee.ConsensusMessages).
+		AppendAuthSignature(f.sigspec, nonce)
+	if err = tb.AppendSign(ctx, f.signer); err != nil {
+		return fmt.Errorf("faucet: failed to sign transfer: %w", err)
+	}
+	if err = tb.SubmitTx(ctx, nil); err != nil {
+		return fmt.Errorf("faucet: failed to transfer funds to %s: %w", to, err)
+	}
+	return nil
+}
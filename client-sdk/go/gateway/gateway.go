@@ -0,0 +1,459 @@
+// Package gateway implements an HTTP gateway exposing runtime data over REST.
+//
+// The gateway serves account balances and nonces, block transactions and events and EVM queries
+// as JSON, so that clients that cannot speak gRPC, like web applications, can consume runtime
+// data through a service built on the SDK. Any query method of the modules registered in the
+// module registry can also be invoked via POST /v1/query/{method}, so importing the client
+// package of a module makes its queries available. An OpenAPI document describing all endpoints
+// is served at /openapi.json.
+//
+// Byte strings in request and response bodies of registered methods are base64-encoded, as is
+// standard for JSON. The EVM endpoints use hex encoding instead, following Ethereum conventions.
+//
+// For applications using gRPC clients, GRPCWebProxy exposes the gRPC services of the node over
+// gRPC-Web, and can be combined with the REST gateway on the same listener. For scripting
+// environments and other consumers preferring JSON-RPC, JSONRPCServer exposes queries,
+// transaction submission and block and event subscriptions as JSON-RPC 2.0 methods.
+package gateway
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/evm"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const (
+	// pathPrefix is the prefix of all API paths.
+	pathPrefix = "/v1/"
+	// specPath is the path of the OpenAPI document.
+	specPath = "/openapi.json"
+
+	// roundLatest is the round parameter value selecting the latest round.
+	roundLatest = "latest"
+
+	// maxRequestSize is the maximum size of a request body.
+	maxRequestSize = 1 << 20
+)
+
+// errNotFound is the error returned for unknown paths.
+var errNotFound = errors.New("not found")
+
+// requestError is an error caused by a malformed request.
+type requestError struct {
+	err error
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &requestError{fmt.Errorf(format, args...)}
+}
+
+// Server is the REST gateway. It implements http.Handler.
+type Server struct {
+	rc client.RuntimeClient
+
+	accounts accounts.V1
+	evm      evm.V1
+}
+
+// New creates a new REST gateway serving data of the runtime of the given client.
+func New(rc client.RuntimeClient) *Server {
+	return &Server{
+		rc:       rc,
+		accounts: accounts.NewV1(rc),
+		evm:      evm.NewV1(rc),
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == specPath {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		writeJSON(w, http.StatusOK, Spec())
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, pathPrefix) {
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+
+	rsp, err := s.route(r, strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, pathPrefix), "/"), "/"))
+	var reqErr *requestError
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, rsp)
+	case errors.Is(err, errNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.As(err, &reqErr):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		writeError(w, http.StatusGatewayTimeout, err)
+	default:
+		writeError(w, http.StatusBadGateway, err)
+	}
+}
+
+// route dispatches a request given the segments of its path after the prefix.
+func (s *Server) route(r *http.Request, segments []string) (interface{}, error) {
+	ctx := r.Context()
+	get, post := r.Method == http.MethodGet, r.Method == http.MethodPost
+
+	switch {
+	case get && len(segments) == 3 && segments[0] == "accounts":
+		address, err := parseAddress(segments[1])
+		if err != nil {
+			return nil, err
+		}
+		round, err := parseRound(r.URL.Query().Get("round"))
+		if err != nil {
+			return nil, err
+		}
+		switch segments[2] {
+		case "balances":
+			return s.accounts.Balances(ctx, round, address)
+		case "nonce":
+			nonce, err := s.accounts.Nonce(ctx, round, address)
+			if err != nil {
+				return nil, err
+			}
+			return &NonceResponse{Nonce: nonce}, nil
+		}
+	case get && len(segments) >= 2 && segments[0] == "blocks":
+		round, err := parseRound(segments[1])
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case len(segments) == 2:
+			return s.getBlock(ctx, round)
+		case len(segments) == 3 && segments[2] == "transactions":
+			return s.getTransactions(ctx, round)
+		case len(segments) == 3 && segments[2] == "events":
+			return s.getEvents(ctx, round)
+		}
+	case get && len(segments) == 4 && segments[0] == "evm" && segments[1] == "accounts":
+		address, err := parseHex("address", segments[2])
+		if err != nil {
+			return nil, err
+		}
+		switch segments[3] {
+		case "balance":
+			balance, err := s.evm.Balance(ctx, address)
+			if err != nil {
+				return nil, err
+			}
+			return &EVMBalanceResponse{Balance: *balance}, nil
+		case "code":
+			code, err := s.evm.Code(ctx, address)
+			if err != nil {
+				return nil, err
+			}
+			return &EVMDataResponse{Data: code}, nil
+		}
+	case get && len(segments) == 5 && segments[0] == "evm" && segments[1] == "accounts" && segments[3] == "storage":
+		address, err := parseHex("address", segments[2])
+		if err != nil {
+			return nil, err
+		}
+		index, err := parseHex("storage index", segments[4])
+		if err != nil {
+			return nil, err
+		}
+		value, err := s.evm.Storage(ctx, address, index)
+		if err != nil {
+			return nil, err
+		}
+		return &EVMDataResponse{Data: value}, nil
+	case post && len(segments) == 2 && segments[0] == "evm" && segments[1] == "simulate-call":
+		var req EVMSimulateCallRequest
+		if err := decodeRequest(r, &req); err != nil {
+			return nil, err
+		}
+		out, err := s.evm.SimulateCall(ctx, req.GasPrice, req.GasLimit, req.Caller, req.Address, req.Value, req.Data)
+		if err != nil {
+			return nil, err
+		}
+		return &EVMDataResponse{Data: out}, nil
+	case post && len(segments) == 2 && segments[0] == "query":
+		return s.query(r, segments[1])
+	}
+	return nil, errNotFound
+}
+
+func (s *Server) getBlock(ctx context.Context, round uint64) (*BlockResponse, error) {
+	blk, err := s.rc.GetBlock(ctx, round)
+	if err != nil {
+		return nil, err
+	}
+	return &BlockResponse{
+		Round:     blk.Header.Round,
+		Timestamp: uint64(blk.Header.Timestamp),
+		Hash:      blk.Header.EncodedHash(),
+		StateRoot: blk.Header.StateRoot,
+	}, nil
+}
+
+func (s *Server) getTransactions(ctx context.Context, round uint64) ([]*TransactionResponse, error) {
+	txs, err := s.rc.GetTransactionsWithResults(ctx, round)
+	if err != nil {
+		return nil, err
+	}
+	rsp := make([]*TransactionResponse, 0, len(txs))
+	for i, tx := range txs {
+		tr := &TransactionResponse{
+			Index:  uint32(i),
+			Hash:   hash.NewFromBytes(cbor.Marshal(&tx.Tx)),
+			Events: make([]*EventResponse, 0, len(tx.Events)),
+		}
+		tr.Method, tr.Body, tr.Result = decodeTransaction(&tx.Tx, &tx.Result)
+		for _, ev := range tx.Events {
+			tr.Events = append(tr.Events, newEventResponse(ev))
+		}
+		rsp = append(rsp, tr)
+	}
+	return rsp, nil
+}
+
+func (s *Server) getEvents(ctx context.Context, round uint64) ([]*EventResponse, error) {
+	evs, err := s.rc.GetEventsRaw(ctx, round)
+	if err != nil {
+		return nil, err
+	}
+	rsp := make([]*EventResponse, 0, len(evs))
+	for _, ev := range evs {
+		rsp = append(rsp, newEventResponse(ev))
+	}
+	return rsp, nil
+}
+
+// query invokes the given registered query method with the arguments in the request body.
+func (s *Server) query(r *http.Request, name string) (interface{}, error) {
+	method, ok := registry.LookupMethod(name)
+	if !ok || method.Kind != registry.MethodKindQuery {
+		return nil, fmt.Errorf("%w: query method %s", errNotFound, name)
+	}
+	round, err := parseRound(r.URL.Query().Get("round"))
+	if err != nil {
+		return nil, err
+	}
+
+	var args interface{}
+	if method.Body != nil {
+		args = newValueOf(method.Body)
+		if err = decodeRequest(r, args); err != nil {
+			return nil, err
+		}
+	}
+	var rsp interface{}
+	if method.Result != nil {
+		rsp = newValueOf(method.Result)
+	}
+	if err = s.rc.Query(r.Context(), round, name, args, rsp); err != nil {
+		return nil, err
+	}
+	return rsp, nil
+}
+
+// BlockResponse is a block header.
+type BlockResponse struct {
+	Round uint64 `json:"round"`
+	// Timestamp is the block timestamp (POSIX time).
+	Timestamp uint64    `json:"timestamp"`
+	Hash      hash.Hash `json:"hash"`
+	StateRoot hash.Hash `json:"state_root"`
+}
+
+// NonceResponse is the nonce of an account.
+type NonceResponse struct {
+	Nonce uint64 `json:"nonce"`
+}
+
+// TransactionResponse is a transaction of a block along with its result and events.
+type TransactionResponse struct {
+	Index uint32    `json:"index"`
+	Hash  hash.Hash `json:"hash"`
+	// Method is the name of the called method. It is empty if the transaction is malformed.
+	Method string `json:"method,omitempty"`
+	// Body is the decoded call body. It is omitted if the method is not registered.
+	Body   interface{}      `json:"body,omitempty"`
+	Result *ResultResponse  `json:"result"`
+	Events []*EventResponse `json:"events"`
+}
+
+// ResultResponse is the result of a transaction.
+type ResultResponse struct {
+	Success bool `json:"success"`
+	// Ok is the decoded result of a successful call. It is omitted if the method does not return
+	// anything or is not registered.
+	Ok     interface{}             `json:"ok,omitempty"`
+	Failed *types.FailedCallResult `json:"fail,omitempty"`
+}
+
+func newResultResponse(res *types.CallResult) *ResultResponse {
+	return &ResultResponse{
+		Success: res.IsSuccess(),
+		Failed:  res.Failed,
+	}
+}
+
+// decodeTransaction decodes the given transaction and its result, returning the name of the called
+// method, the decoded call body and the decoded result.
+func decodeTransaction(tx *types.UnverifiedTransaction, result *types.CallResult) (string, interface{}, *ResultResponse) {
+	rsp := newResultResponse(result)
+	decoded, method, body, err := registry.DecodeTransaction(tx)
+	switch {
+	case err == nil:
+		if rsp.Success && len(result.Ok) > 0 {
+			rsp.Ok, _ = method.DecodeResult(result.Ok)
+		}
+		return method.Name, body, rsp
+	case decoded != nil:
+		// Keep the method name of transactions that cannot be decoded, e.g. of unregistered
+		// modules or encrypted calls.
+		return decoded.Call.Method, nil, rsp
+	default:
+		return "", nil, rsp
+	}
+}
+
+// EventResponse is an event, decoded if its module is registered.
+type EventResponse struct {
+	Module string `json:"module"`
+	Code   uint32 `json:"code"`
+	// Decoded is the decoded event. If the event could not be decoded, the CBOR-encoded value is
+	// given instead.
+	Decoded client.DecodedEvent `json:"decoded,omitempty"`
+	Value   []byte              `json:"value,omitempty"`
+}
+
+func newEventResponse(ev *types.Event) *EventResponse {
+	rsp := &EventResponse{Module: ev.Module, Code: ev.Code}
+	if decoded, err := registry.DecodeEvent(ev); err == nil && decoded != nil {
+		rsp.Decoded = decoded
+	} else {
+		rsp.Value = ev.Value
+	}
+	return rsp
+}
+
+// EVMBalanceResponse is the balance of an EVM account.
+type EVMBalanceResponse struct {
+	Balance types.Quantity `json:"balance"`
+}
+
+// EVMDataResponse is data returned by an EVM query.
+type EVMDataResponse struct {
+	Data HexBytes `json:"data"`
+}
+
+// EVMSimulateCallRequest is a request to simulate an EVM call.
+type EVMSimulateCallRequest struct {
+	GasPrice HexBytes `json:"gas_price"`
+	GasLimit uint64   `json:"gas_limit"`
+	Caller   HexBytes `json:"caller"`
+	Address  HexBytes `json:"address"`
+	Value    HexBytes `json:"value"`
+	Data     HexBytes `json:"data"`
+}
+
+// HexBytes are bytes encoded as a 0x-prefixed hex string in JSON.
+type HexBytes []byte
+
+// MarshalText implements encoding.TextMarshaler.
+func (b HexBytes) MarshalText() ([]byte, error) {
+	return []byte("0x" + hex.EncodeToString(b)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *HexBytes) UnmarshalText(text []byte) error {
+	data, err := hex.DecodeString(strings.TrimPrefix(string(text), "0x"))
+	if err != nil {
+		return err
+	}
+	*b = data
+	return nil
+}
+
+// parseRound parses a round, where an empty round or "latest" selects the latest round.
+func parseRound(s string) (uint64, error) {
+	if s == "" || s == roundLatest {
+		return client.RoundLatest, nil
+	}
+	round, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, badRequest("malformed round: %s", s)
+	}
+	return round, nil
+}
+
+func parseAddress(s string) (types.Address, error) {
+	var address types.Address
+	if err := address.UnmarshalText([]byte(s)); err != nil {
+		return address, badRequest("malformed address: %s", s)
+	}
+	return address, nil
+}
+
+func parseHex(what, s string) ([]byte, error) {
+	var b HexBytes
+	if err := b.UnmarshalText([]byte(s)); err != nil {
+		return nil, badRequest("malformed %s: %s", what, s)
+	}
+	return b, nil
+}
+
+// decodeRequest decodes the JSON-encoded request body.
+func decodeRequest(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxRequestSize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return badRequest("malformed request: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		data, _ = json.Marshal(&ErrorResponse{Error: fmt.Sprintf("failed to encode response: %s", err)})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
+
+// ErrorResponse is the body of error responses.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, &ErrorResponse{Error: err.Error()})
+}
+
+// newValueOf returns a pointer to a new zero value of the type of the given example value.
+func newValueOf(example interface{}) interface{} {
+	return reflect.New(indirectType(reflect.TypeOf(example))).Interface()
+}
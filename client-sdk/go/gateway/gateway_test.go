@@ -0,0 +1,140 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/fakeruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func nativeUnits(amount uint64) types.BaseUnits {
+	return types.NewBaseUnits(*quantity.NewFromUint64(amount), types.NativeDenomination)
+}
+
+func request(t *testing.T, srv *httptest.Server, method, path, body string, rsp interface{}) int {
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	require.NoError(t, err, "NewRequest")
+	res, err := srv.Client().Do(req)
+	require.NoError(t, err, "Do")
+	defer res.Body.Close()
+	require.Equal(t, "application/json", res.Header.Get("Content-Type"))
+
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err, "ReadAll")
+	if rsp != nil {
+		require.NoError(t, json.Unmarshal(data, rsp), "malformed response: %s", data)
+	}
+	return res.StatusCode
+}
+
+func TestGateway(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rt := fakeruntime.New(fakeruntime.Config{})
+	rt.SetBalance(sdkTesting.Alice.Address, nativeUnits(100))
+	rt.SetEVMCode([]byte{0x01}, []byte{0xca, 0xfe})
+
+	tb := accounts.NewV1(rt).Transfer(sdkTesting.Bob.Address, nativeUnits(30))
+	tb.AppendAuthSignature(sdkTesting.Alice.SigSpec, 0)
+	require.NoError(tb.AppendSign(ctx, sdkTesting.Alice.Signer), "AppendSign")
+	meta, err := tb.SubmitTxMeta(ctx, nil)
+	require.NoError(err, "SubmitTxMeta")
+
+	srv := httptest.NewServer(New(rt))
+	defer srv.Close()
+
+	var balances accounts.AccountBalances
+	require.Equal(http.StatusOK, request(t, srv, http.MethodGet, "/v1/accounts/"+sdkTesting.Bob.Address.String()+"/balances", "", &balances))
+	require.Equal(*quantity.NewFromUint64(30), balances.Balances[types.NativeDenomination])
+	var genesisBalances accounts.AccountBalances
+	require.Equal(http.StatusOK, request(t, srv, http.MethodGet, "/v1/accounts/"+sdkTesting.Bob.Address.String()+"/balances?round=0", "", &genesisBalances))
+	require.Empty(genesisBalances.Balances, "balances at genesis")
+
+	var nonce NonceResponse
+	require.Equal(http.StatusOK, request(t, srv, http.MethodGet, "/v1/accounts/"+sdkTesting.Alice.Address.String()+"/nonce", "", &nonce))
+	require.EqualValues(1, nonce.Nonce)
+
+	var block BlockResponse
+	require.Equal(http.StatusOK, request(t, srv, http.MethodGet, "/v1/blocks/latest", "", &block))
+	require.Equal(meta.Round, block.Round)
+
+	var txs []struct {
+		Method string `json:"method"`
+		Body   struct {
+			To types.Address `json:"to"`
+		} `json:"body"`
+		Result ResultResponse `json:"result"`
+		Events []struct {
+			Module  string          `json:"module"`
+			Decoded *accounts.Event `json:"decoded"`
+		} `json:"events"`
+	}
+	require.Equal(http.StatusOK, request(t, srv, http.MethodGet, "/v1/blocks/1/transactions", "", &txs))
+	require.Len(txs, 1)
+	require.Equal("accounts.Transfer", txs[0].Method)
+	require.Equal(sdkTesting.Bob.Address, txs[0].Body.To)
+	require.True(txs[0].Result.Success)
+	require.Len(txs[0].Events, 1)
+	require.Equal(accounts.ModuleName, txs[0].Events[0].Module)
+	require.NotNil(txs[0].Events[0].Decoded.Transfer, "transfer event should be decoded")
+
+	var events []*EventResponse
+	require.Equal(http.StatusOK, request(t, srv, http.MethodGet, "/v1/blocks/1/events", "", &events))
+	require.Len(events, 1)
+
+	// Registered query methods.
+	var queried uint64
+	body := `{"address": "` + sdkTesting.Alice.Address.String() + `"}`
+	require.Equal(http.StatusOK, request(t, srv, http.MethodPost, "/v1/query/accounts.Nonce", body, &queried))
+	require.EqualValues(1, queried)
+
+	// EVM queries.
+	var code EVMDataResponse
+	require.Equal(http.StatusOK, request(t, srv, http.MethodGet, "/v1/evm/accounts/0x01/code", "", &code))
+	require.Equal(HexBytes{0xca, 0xfe}, code.Data)
+
+	// Errors.
+	var rspErr ErrorResponse
+	require.Equal(http.StatusNotFound, request(t, srv, http.MethodGet, "/v1/unknown", "", &rspErr))
+	require.Equal(http.StatusNotFound, request(t, srv, http.MethodPost, "/v1/query/accounts.Transfer", "{}", &rspErr))
+	require.Equal(http.StatusBadRequest, request(t, srv, http.MethodGet, "/v1/accounts/bogus/balances", "", &rspErr))
+	require.Contains(rspErr.Error, "malformed address")
+	require.Equal(http.StatusBadRequest, request(t, srv, http.MethodPost, "/v1/query/accounts.Nonce", `{"bogus": 1}`, &rspErr))
+	require.Equal(http.StatusBadGateway, request(t, srv, http.MethodGet, "/v1/blocks/42", "", &rspErr))
+}
+
+func TestSpec(t *testing.T) {
+	require := require.New(t)
+
+	doc := Spec()
+	require.Equal(openAPIVersion, doc.OpenAPI)
+	require.Contains(doc.Paths, "/v1/accounts/{address}/balances")
+	require.Contains(doc.Paths, "/v1/evm/simulate-call")
+
+	// Query methods of registered modules should be included, calls should not.
+	op := doc.Paths["/v1/query/accounts.Balances"]["post"]
+	require.NotNil(op, "registered query method")
+	require.Equal(Schema{"$ref": "#/components/schemas/accounts.BalancesQuery"}, op.RequestBody.Content["application/json"].Schema)
+	require.NotContains(doc.Paths, "/v1/query/accounts.Transfer")
+
+	query := doc.Components.Schemas["accounts.BalancesQuery"]
+	require.Equal(Schema{"type": "string"}, query["properties"].(map[string]Schema)["address"])
+	require.Equal([]string{"address"}, query["required"])
+
+	// The document should be valid JSON.
+	_, err := json.Marshal(doc)
+	require.NoError(err, "Marshal")
+}
@@ -0,0 +1,202 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultGRPCWebServices are the gRPC services exposed by the gRPC-Web proxy by default.
+var DefaultGRPCWebServices = []string{"oasis-core.RuntimeClient"}
+
+// GRPCWebConfig is the configuration of the gRPC-Web proxy.
+type GRPCWebConfig struct {
+	// Services are the names of the gRPC services that are exposed, e.g. oasis-core.RuntimeClient.
+	// If empty, DefaultGRPCWebServices are exposed.
+	Services []string
+	// AllowedOrigins are the origins allowed to make cross-origin requests. If empty, requests
+	// from all origins are allowed.
+	AllowedOrigins []string
+}
+
+// GRPCWebProxy is a proxy exposing the gRPC services of a node over gRPC-Web, for browser
+// applications and environments without support for HTTP/2 trailers. It implements http.Handler.
+//
+// Messages are forwarded to the node as they are, so the proxy exposes the same methods and
+// message encoding as the node itself.
+type GRPCWebProxy struct {
+	conn     *grpc.ClientConn
+	services map[string]struct{}
+
+	server  *grpc.Server
+	wrapped *grpcweb.WrappedGrpcServer
+}
+
+// ServeHTTP implements http.Handler.
+func (p *GRPCWebProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.wrapped.ServeHTTP(w, r)
+}
+
+// IsGRPCWebRequest checks whether the given request is a gRPC-Web request or a CORS pre-flight
+// request for one.
+func (p *GRPCWebProxy) IsGRPCWebRequest(r *http.Request) bool {
+	return p.wrapped.IsGrpcWebRequest(r) || p.wrapped.IsAcceptableGrpcCorsRequest(r)
+}
+
+// Handler returns a handler serving gRPC-Web requests via the proxy and all other requests via
+// the given handler, e.g. the REST gateway.
+func (p *GRPCWebProxy) Handler(fallback http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p.IsGRPCWebRequest(r) {
+			p.ServeHTTP(w, r)
+			return
+		}
+		fallback.ServeHTTP(w, r)
+	})
+}
+
+// Close stops the proxy, aborting all pending calls.
+func (p *GRPCWebProxy) Close() {
+	p.server.Stop()
+}
+
+// proxy forwards a call to the node.
+func (p *GRPCWebProxy) proxy(srv interface{}, serverStream grpc.ServerStream) error {
+	method, ok := grpc.MethodFromServerStream(serverStream)
+	if !ok {
+		return status.Error(codes.Internal, "unknown method")
+	}
+	// Methods are of the form /service/method.
+	service := strings.SplitN(strings.TrimPrefix(method, "/"), "/", 2)[0]
+	if _, ok = p.services[service]; !ok {
+		return status.Errorf(codes.Unimplemented, "unknown service %s", service)
+	}
+
+	ctx, cancel := context.WithCancel(serverStream.Context())
+	defer cancel()
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md.Copy())
+
+	clientStream, err := p.conn.NewStream(ctx, &grpc.StreamDesc{
+		ServerStreams: true,
+		ClientStreams: true,
+	}, method, grpc.ForceCodec(rawCodec{}))
+	if err != nil {
+		return err
+	}
+
+	// Forward requests to the node until the client is done.
+	reqErrCh := make(chan error, 1)
+	go func() {
+		for {
+			var msg []byte
+			if rErr := serverStream.RecvMsg(&msg); rErr != nil {
+				if errors.Is(rErr, io.EOF) {
+					rErr = clientStream.CloseSend()
+				}
+				reqErrCh <- rErr
+				return
+			}
+			if sErr := clientStream.SendMsg(&msg); sErr != nil {
+				reqErrCh <- sErr
+				return
+			}
+		}
+	}()
+
+	// Forward responses to the client until the node is done.
+	header, err := clientStream.Header()
+	if err != nil {
+		return err
+	}
+	if err = serverStream.SendHeader(header); err != nil {
+		return err
+	}
+	for {
+		var msg []byte
+		if err = clientStream.RecvMsg(&msg); err != nil {
+			serverStream.SetTrailer(clientStream.Trailer())
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if err = serverStream.SendMsg(&msg); err != nil {
+			return err
+		}
+
+		select {
+		case reqErr := <-reqErrCh:
+			if reqErr != nil {
+				return reqErr
+			}
+			reqErrCh = nil
+		default:
+		}
+	}
+}
+
+// rawCodec passes messages through without decoding them.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	return *(v.(*[]byte)), nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	*(v.(*[]byte)) = append([]byte(nil), data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	// Messages exchanged with nodes are CBOR-encoded.
+	return "cbor"
+}
+
+// NewGRPCWebProxy creates a new gRPC-Web proxy forwarding calls to the node at the other end of
+// the given connection.
+func NewGRPCWebProxy(conn *grpc.ClientConn, cfg *GRPCWebConfig) *GRPCWebProxy {
+	services := cfg.Services
+	if len(services) == 0 {
+		services = DefaultGRPCWebServices
+	}
+
+	p := &GRPCWebProxy{
+		conn:     conn,
+		services: make(map[string]struct{}),
+	}
+	for _, service := range services {
+		p.services[service] = struct{}{}
+	}
+	p.server = grpc.NewServer(
+		grpc.ForceServerCodec(rawCodec{}),
+		grpc.UnknownServiceHandler(p.proxy),
+	)
+
+	originFunc := func(string) bool { return true }
+	if len(cfg.AllowedOrigins) > 0 {
+		originFunc = func(origin string) bool {
+			for _, allowed := range cfg.AllowedOrigins {
+				if origin == allowed {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	p.wrapped = grpcweb.WrapServer(p.server,
+		grpcweb.WithOriginFunc(originFunc),
+		// Services are proxied rather than registered, so checking for registered endpoints
+		// would reject all pre-flight requests.
+		grpcweb.WithCorsForRegisteredEndpointsOnly(false),
+	)
+	return p
+}
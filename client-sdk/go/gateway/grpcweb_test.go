@@ -0,0 +1,151 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	cmnGrpc "github.com/oasisprotocol/oasis-core/go/common/grpc"
+)
+
+// echoService is a node service echoing requests, once for unary calls and twice for streams.
+var echoService = grpc.ServiceDesc{
+	ServiceName: "oasis-core.RuntimeClient",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Echo",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+			var msg string
+			if err := dec(&msg); err != nil {
+				return nil, err
+			}
+			return msg, nil
+		},
+	}},
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "WatchEcho",
+		ServerStreams: true,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			var msg string
+			if err := stream.RecvMsg(&msg); err != nil {
+				return err
+			}
+			for i := 0; i < 2; i++ {
+				if err := stream.SendMsg(msg); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}},
+}
+
+// grpcWebCall makes a gRPC-Web call with a CBOR-encoded request and returns the CBOR-decoded
+// response messages and the grpc-status trailer.
+func grpcWebCall(t *testing.T, srv *httptest.Server, method string, req string) ([]string, string) {
+	var body bytes.Buffer
+	data := cbor.Marshal(req)
+	body.WriteByte(0)
+	_ = binary.Write(&body, binary.BigEndian, uint32(len(data)))
+	body.Write(data)
+
+	httpReq, err := http.NewRequest(http.MethodPost, srv.URL+method, &body)
+	require.NoError(t, err, "NewRequest")
+	httpReq.Header.Set("Content-Type", "application/grpc-web+proto")
+	rsp, err := srv.Client().Do(httpReq)
+	require.NoError(t, err, "Do")
+	defer rsp.Body.Close()
+	require.Equal(t, http.StatusOK, rsp.StatusCode)
+
+	var (
+		msgs       []string
+		grpcStatus = rsp.Header.Get("Grpc-Status")
+	)
+	for {
+		var hdr [5]byte
+		if _, err = io.ReadFull(rsp.Body, hdr[:]); err == io.EOF {
+			return msgs, grpcStatus
+		}
+		require.NoError(t, err, "reading frame header")
+		frame := make([]byte, binary.BigEndian.Uint32(hdr[1:]))
+		_, err = io.ReadFull(rsp.Body, frame)
+		require.NoError(t, err, "reading frame")
+
+		if hdr[0]&0x80 != 0 {
+			// Trailers.
+			for _, line := range strings.Split(string(frame), "\r\n") {
+				if kv := strings.SplitN(line, ":", 2); len(kv) == 2 && strings.EqualFold(kv[0], "grpc-status") {
+					grpcStatus = strings.TrimSpace(kv[1])
+				}
+			}
+			continue
+		}
+		var msg string
+		require.NoError(t, cbor.Unmarshal(frame, &msg), "malformed message")
+		msgs = append(msgs, msg)
+	}
+}
+
+func TestGRPCWebProxy(t *testing.T) {
+	require := require.New(t)
+
+	// Node.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err, "Listen")
+	node := grpc.NewServer(grpc.ForceServerCodec(&cmnGrpc.CBORCodec{}))
+	node.RegisterService(&echoService, struct{}{})
+	go func() { _ = node.Serve(listener) }()
+	defer node.Stop()
+
+	conn, err := cmnGrpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	require.NoError(err, "Dial")
+	defer conn.Close()
+
+	proxy := NewGRPCWebProxy(conn, &GRPCWebConfig{AllowedOrigins: []string{"https://example.com"}})
+	defer proxy.Close()
+	srv := httptest.NewServer(proxy.Handler(http.NotFoundHandler()))
+	defer srv.Close()
+
+	msgs, grpcStatus := grpcWebCall(t, srv, "/oasis-core.RuntimeClient/Echo", "hello")
+	require.Equal("0", grpcStatus)
+	require.Equal([]string{"hello"}, msgs)
+
+	msgs, grpcStatus = grpcWebCall(t, srv, "/oasis-core.RuntimeClient/WatchEcho", "hi")
+	require.Equal("0", grpcStatus)
+	require.Equal([]string{"hi", "hi"}, msgs)
+
+	// Services that are not exposed should be rejected.
+	_, grpcStatus = grpcWebCall(t, srv, "/oasis-core.Consensus/SubmitTx", "tx")
+	require.Equal("12", grpcStatus, "Unimplemented")
+
+	// CORS pre-flight requests.
+	preflight := func(origin string) string {
+		req, reqErr := http.NewRequest(http.MethodOptions, srv.URL+"/oasis-core.RuntimeClient/Echo", nil)
+		require.NoError(reqErr, "NewRequest")
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "x-grpc-web")
+		rsp, reqErr := srv.Client().Do(req)
+		require.NoError(reqErr, "Do")
+		rsp.Body.Close()
+		return rsp.Header.Get("Access-Control-Allow-Origin")
+	}
+	require.Equal("https://example.com", preflight("https://example.com"))
+	require.Empty(preflight("https://evil.example"))
+
+	// Other requests should be passed to the fallback handler.
+	rsp, err := srv.Client().Get(srv.URL + "/v1/blocks/latest")
+	require.NoError(err, "Get")
+	rsp.Body.Close()
+	require.Equal(http.StatusNotFound, rsp.StatusCode)
+}
@@ -0,0 +1,580 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// JSON-RPC methods.
+const (
+	// MethodQuery invokes a registered query method, with QueryParams.
+	MethodQuery = "oasis_query"
+	// MethodSubmitTx submits a transaction and waits for its result, with SubmitTxParams.
+	MethodSubmitTx = "oasis_submitTx"
+	// MethodGetBlock returns a block header, with RoundParams.
+	MethodGetBlock = "oasis_getBlock"
+	// MethodGetTransactions returns the transactions of a block, with RoundParams.
+	MethodGetTransactions = "oasis_getTransactions"
+	// MethodGetEvents returns the events of a block, with RoundParams.
+	MethodGetEvents = "oasis_getEvents"
+	// MethodSubscribe subscribes to blocks or events, with SubscribeParams, returning the
+	// identifier of the subscription. Subscriptions are only available on WebSocket connections.
+	// An events subscription ends if the events of a block cannot be fetched, after which
+	// MethodUnsubscribe returns false for it.
+	MethodSubscribe = "oasis_subscribe"
+	// MethodUnsubscribe cancels a subscription, with UnsubscribeParams.
+	MethodUnsubscribe = "oasis_unsubscribe"
+	// MethodSubscription is the method of notifications sent to subscribers, with
+	// SubscriptionParams.
+	MethodSubscription = "oasis_subscription"
+)
+
+// Subscription kinds.
+const (
+	// SubscriptionBlocks notifies subscribers of new blocks with a BlockResponse.
+	SubscriptionBlocks = "blocks"
+	// SubscriptionEvents notifies subscribers of the events of new blocks with an
+	// EventsNotification.
+	SubscriptionEvents = "events"
+)
+
+// JSON-RPC error codes.
+const (
+	ErrCodeParse          = -32700
+	ErrCodeInvalidRequest = -32600
+	ErrCodeMethodNotFound = -32601
+	ErrCodeInvalidParams  = -32602
+	ErrCodeInternal       = -32603
+	// ErrCodeNode is the code of errors returned by the node, e.g. failed queries.
+	ErrCodeNode = -32000
+)
+
+const (
+	jsonrpcVersion = "2.0"
+
+	// maxSubscriptions is the maximum number of active subscriptions of a connection.
+	maxSubscriptions = 64
+)
+
+var nullID = json.RawMessage("null")
+
+// JSONRPCError is a JSON-RPC error.
+type JSONRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error implements error.
+func (e *JSONRPCError) Error() string {
+	return fmt.Sprintf("jsonrpc: %s (code %d)", e.Message, e.Code)
+}
+
+func newJSONRPCError(code int, format string, args ...interface{}) *JSONRPCError {
+	return &JSONRPCError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+type jsonrpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type jsonrpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *JSONRPCError   `json:"error,omitempty"`
+}
+
+type jsonrpcNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// QueryParams are the parameters of MethodQuery.
+type QueryParams struct {
+	// Method is the name of the registered query method.
+	Method string `json:"method"`
+	// Round is the round to query at. If omitted, the latest round is queried.
+	Round *uint64 `json:"round,omitempty"`
+	// Args are the query arguments, as in the body of the corresponding REST endpoint.
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+// SubmitTxParams are the parameters of MethodSubmitTx.
+type SubmitTxParams struct {
+	// Tx is the CBOR-encoded signed transaction.
+	Tx []byte `json:"tx"`
+}
+
+// SubmitTxResult is the result of MethodSubmitTx.
+type SubmitTxResult struct {
+	// Round is the round the transaction was included in.
+	Round  uint64          `json:"round"`
+	Hash   hash.Hash       `json:"hash"`
+	Result *ResultResponse `json:"result"`
+}
+
+// RoundParams are the parameters of methods returning data of a block.
+type RoundParams struct {
+	// Round is the round of the block. If omitted, the latest block is used.
+	Round *uint64 `json:"round,omitempty"`
+}
+
+// SubscribeParams are the parameters of MethodSubscribe.
+type SubscribeParams struct {
+	// Kind is the kind of the subscription, SubscriptionBlocks or SubscriptionEvents.
+	Kind string `json:"kind"`
+}
+
+// UnsubscribeParams are the parameters of MethodUnsubscribe.
+type UnsubscribeParams struct {
+	// Subscription is the identifier returned by MethodSubscribe.
+	Subscription string `json:"subscription"`
+}
+
+// SubscriptionParams are the parameters of MethodSubscription notifications.
+type SubscriptionParams struct {
+	// Subscription is the identifier returned by MethodSubscribe.
+	Subscription string `json:"subscription"`
+	// Result is the notified value, depending on the kind of the subscription.
+	Result interface{} `json:"result"`
+}
+
+// EventsNotification are the events emitted in a block.
+type EventsNotification struct {
+	Round  uint64           `json:"round"`
+	Events []*EventResponse `json:"events"`
+}
+
+// JSONRPCConfig is the configuration of the JSON-RPC server.
+type JSONRPCConfig struct {
+	// AllowedOrigins are the origins allowed to open WebSocket connections. If empty, connections
+	// from all origins are allowed.
+	AllowedOrigins []string
+}
+
+// JSONRPCServer is a JSON-RPC 2.0 server exposing the native runtime client methods, like queries
+// of registered modules and transaction submission. It implements http.Handler.
+//
+// Requests, including batches, are served over HTTP POST and WebSocket connections. Subscriptions
+// to blocks and events are only available on WebSocket connections. Values are encoded as in the
+// REST gateway.
+type JSONRPCServer struct {
+	gw       *Server
+	upgrader websocket.Upgrader
+}
+
+// NewJSONRPC creates a new JSON-RPC server serving data of the runtime of the given client.
+func NewJSONRPC(rc client.RuntimeClient, cfg *JSONRPCConfig) *JSONRPCServer {
+	s := &JSONRPCServer{gw: New(rc)}
+	s.upgrader.CheckOrigin = func(r *http.Request) bool { return true }
+	if cfg != nil && len(cfg.AllowedOrigins) > 0 {
+		allowed := make(map[string]struct{}, len(cfg.AllowedOrigins))
+		for _, origin := range cfg.AllowedOrigins {
+			allowed[origin] = struct{}{}
+		}
+		s.upgrader.CheckOrigin = func(r *http.Request) bool {
+			_, ok := allowed[r.Header.Get("Origin")]
+			return ok
+		}
+	}
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *JSONRPCServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case websocket.IsWebSocketUpgrade(r):
+		conn, err := s.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// The upgrader has already replied.
+			return
+		}
+		s.serveConn(r.Context(), conn)
+	case r.Method == http.MethodPost:
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+		if err != nil {
+			writeJSON(w, http.StatusOK, &jsonrpcResponse{
+				JSONRPC: jsonrpcVersion,
+				ID:      nullID,
+				Error:   newJSONRPCError(ErrCodeInvalidRequest, "failed to read request: %s", err),
+			})
+			return
+		}
+		rsp := s.handleMessage(r.Context(), data, nil)
+		if rsp == nil {
+			// Only notifications, which are not answered.
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, http.StatusOK, rsp)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+// handleMessage handles a request or a batch of requests, returning the response to be sent or
+// nil if there is none.
+func (s *JSONRPCServer) handleMessage(ctx context.Context, data []byte, conn *jsonrpcConn) interface{} {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '[' {
+		var req jsonrpcRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			return &jsonrpcResponse{
+				JSONRPC: jsonrpcVersion,
+				ID:      nullID,
+				Error:   newJSONRPCError(ErrCodeParse, "malformed request: %s", err),
+			}
+		}
+		if rsp := s.handleRequest(ctx, &req, conn); rsp != nil {
+			return rsp
+		}
+		return nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return &jsonrpcResponse{
+			JSONRPC: jsonrpcVersion,
+			ID:      nullID,
+			Error:   newJSONRPCError(ErrCodeParse, "malformed batch: %s", err),
+		}
+	}
+	if len(batch) == 0 {
+		return &jsonrpcResponse{
+			JSONRPC: jsonrpcVersion,
+			ID:      nullID,
+			Error:   newJSONRPCError(ErrCodeInvalidRequest, "empty batch"),
+		}
+	}
+	var rsps []*jsonrpcResponse
+	for _, raw := range batch {
+		var (
+			req jsonrpcRequest
+			rsp *jsonrpcResponse
+		)
+		if err := json.Unmarshal(raw, &req); err != nil {
+			rsp = &jsonrpcResponse{
+				JSONRPC: jsonrpcVersion,
+				ID:      nullID,
+				Error:   newJSONRPCError(ErrCodeInvalidRequest, "malformed request: %s", err),
+			}
+		} else {
+			rsp = s.handleRequest(ctx, &req, conn)
+		}
+		if rsp != nil {
+			rsps = append(rsps, rsp)
+		}
+	}
+	if len(rsps) == 0 {
+		return nil
+	}
+	return rsps
+}
+
+// handleRequest handles a single request, returning nil for notifications.
+func (s *JSONRPCServer) handleRequest(ctx context.Context, req *jsonrpcRequest, conn *jsonrpcConn) *jsonrpcResponse {
+	rsp := &jsonrpcResponse{JSONRPC: jsonrpcVersion, ID: req.ID}
+	if req.JSONRPC != jsonrpcVersion || req.Method == "" {
+		if rsp.ID == nil {
+			rsp.ID = nullID
+		}
+		rsp.Error = newJSONRPCError(ErrCodeInvalidRequest, "invalid request")
+		return rsp
+	}
+
+	result, err := s.call(ctx, req.Method, req.Params, conn)
+	if req.ID == nil {
+		return nil
+	}
+	if err == nil {
+		if rsp.Result, err = json.Marshal(result); err != nil {
+			err = newJSONRPCError(ErrCodeInternal, "failed to encode result: %s", err)
+		}
+	}
+	var (
+		rpcErr *JSONRPCError
+		reqErr *requestError
+	)
+	switch {
+	case err == nil:
+	case errors.As(err, &rpcErr):
+		rsp.Error = rpcErr
+	case errors.As(err, &reqErr):
+		rsp.Error = newJSONRPCError(ErrCodeInvalidParams, "%s", err)
+	default:
+		rsp.Error = newJSONRPCError(ErrCodeNode, "%s", err)
+	}
+	if rsp.Error != nil {
+		rsp.Result = nil
+	}
+	return rsp
+}
+
+// call invokes the given method.
+func (s *JSONRPCServer) call(ctx context.Context, method string, params json.RawMessage, conn *jsonrpcConn) (interface{}, error) {
+	switch method {
+	case MethodQuery:
+		var p QueryParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.query(ctx, &p)
+	case MethodSubmitTx:
+		var p SubmitTxParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.submitTx(ctx, &p)
+	case MethodGetBlock, MethodGetTransactions, MethodGetEvents:
+		var p RoundParams
+		if params != nil {
+			if err := decodeParams(params, &p); err != nil {
+				return nil, err
+			}
+		}
+		round := client.RoundLatest
+		if p.Round != nil {
+			round = *p.Round
+		}
+		switch method {
+		case MethodGetBlock:
+			return s.gw.getBlock(ctx, round)
+		case MethodGetTransactions:
+			return s.gw.getTransactions(ctx, round)
+		default:
+			return s.gw.getEvents(ctx, round)
+		}
+	case MethodSubscribe, MethodUnsubscribe:
+		if conn == nil {
+			return nil, newJSONRPCError(ErrCodeMethodNotFound, "subscriptions require a WebSocket connection")
+		}
+		if method == MethodSubscribe {
+			var p SubscribeParams
+			if err := decodeParams(params, &p); err != nil {
+				return nil, err
+			}
+			return conn.subscribe(p.Kind)
+		}
+		var p UnsubscribeParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return conn.unsubscribe(p.Subscription), nil
+	default:
+		return nil, newJSONRPCError(ErrCodeMethodNotFound, "method %s not found", method)
+	}
+}
+
+// query invokes a registered query method.
+func (s *JSONRPCServer) query(ctx context.Context, p *QueryParams) (interface{}, error) {
+	method, ok := registry.LookupMethod(p.Method)
+	if !ok || method.Kind != registry.MethodKindQuery {
+		return nil, badRequest("unknown query method: %s", p.Method)
+	}
+	round := client.RoundLatest
+	if p.Round != nil {
+		round = *p.Round
+	}
+
+	var args interface{}
+	if method.Body != nil {
+		args = newValueOf(method.Body)
+		if err := decodeParams(p.Args, args); err != nil {
+			return nil, err
+		}
+	}
+	var rsp interface{}
+	if method.Result != nil {
+		rsp = newValueOf(method.Result)
+	}
+	if err := s.gw.rc.Query(ctx, round, p.Method, args, rsp); err != nil {
+		return nil, err
+	}
+	return rsp, nil
+}
+
+// submitTx submits a signed transaction and waits for its result.
+func (s *JSONRPCServer) submitTx(ctx context.Context, p *SubmitTxParams) (*SubmitTxResult, error) {
+	var tx types.UnverifiedTransaction
+	if err := cbor.Unmarshal(p.Tx, &tx); err != nil {
+		return nil, badRequest("malformed transaction: %w", err)
+	}
+	meta, err := s.gw.rc.SubmitTxRawMeta(ctx, &tx)
+	if err != nil {
+		return nil, err
+	}
+	_, _, result := decodeTransaction(&tx, &meta.Result)
+	return &SubmitTxResult{
+		Round:  meta.Round,
+		Hash:   hash.NewFromBytes(cbor.Marshal(&tx)),
+		Result: result,
+	}, nil
+}
+
+// decodeParams decodes JSON-encoded parameters.
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return badRequest("missing parameters")
+	}
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return badRequest("malformed parameters: %w", err)
+	}
+	return nil
+}
+
+// serveConn serves requests received over the given WebSocket connection until it is closed.
+func (s *JSONRPCServer) serveConn(ctx context.Context, wsConn *websocket.Conn) {
+	ctx, cancel := context.WithCancel(ctx)
+	conn := &jsonrpcConn{
+		srv:           s,
+		ctx:           ctx,
+		conn:          wsConn,
+		subscriptions: make(map[string]context.CancelFunc),
+	}
+	defer func() {
+		// Stop all subscriptions and pending requests.
+		cancel()
+		conn.wg.Wait()
+		wsConn.Close()
+	}()
+
+	wsConn.SetReadLimit(maxRequestSize)
+	for {
+		_, data, err := wsConn.ReadMessage()
+		if err != nil {
+			return
+		}
+		// Handle requests concurrently, so that long requests like transaction submissions do not
+		// hold up others.
+		conn.wg.Add(1)
+		go func() {
+			defer conn.wg.Done()
+			if rsp := s.handleMessage(ctx, data, conn); rsp != nil {
+				conn.write(rsp)
+			}
+		}()
+	}
+}
+
+// jsonrpcConn is a WebSocket connection with its subscriptions.
+type jsonrpcConn struct {
+	sync.Mutex
+
+	srv  *JSONRPCServer
+	ctx  context.Context
+	wg   sync.WaitGroup
+	conn *websocket.Conn
+
+	writeLock     sync.Mutex
+	subscriptions map[string]context.CancelFunc
+	lastID        uint64
+}
+
+func (c *jsonrpcConn) write(v interface{}) {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	// Write errors are noticed by the read loop, which then closes the connection.
+	_ = c.conn.WriteJSON(v)
+}
+
+func (c *jsonrpcConn) subscribe(kind string) (string, error) {
+	switch kind {
+	case SubscriptionBlocks, SubscriptionEvents:
+	default:
+		return "", badRequest("unknown subscription kind: %s", kind)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	if len(c.subscriptions) >= maxSubscriptions {
+		return "", newJSONRPCError(ErrCodeInvalidRequest, "too many subscriptions")
+	}
+	// Subscribe before returning, so that no blocks following the subscription are missed.
+	ctx, cancel := context.WithCancel(c.ctx)
+	blkCh, blkSub, err := c.srv.gw.rc.WatchBlocks(ctx)
+	if err != nil {
+		cancel()
+		return "", err
+	}
+	c.lastID++
+	id := "0x" + strconv.FormatUint(c.lastID, 16)
+	c.subscriptions[id] = cancel
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer blkSub.Close()
+		defer c.unsubscribe(id)
+
+		for {
+			var blk *block.Block
+			select {
+			case <-ctx.Done():
+				return
+			case annBlk, ok := <-blkCh:
+				if !ok {
+					return
+				}
+				blk = annBlk.Block
+			}
+
+			var result interface{}
+			switch kind {
+			case SubscriptionBlocks:
+				result = &BlockResponse{
+					Round:     blk.Header.Round,
+					Timestamp: uint64(blk.Header.Timestamp),
+					Hash:      blk.Header.EncodedHash(),
+					StateRoot: blk.Header.StateRoot,
+				}
+			case SubscriptionEvents:
+				events, err := c.srv.gw.getEvents(ctx, blk.Header.Round)
+				if err != nil {
+					return
+				}
+				result = &EventsNotification{Round: blk.Header.Round, Events: events}
+			}
+			c.write(&jsonrpcNotification{
+				JSONRPC: jsonrpcVersion,
+				Method:  MethodSubscription,
+				Params:  &SubscriptionParams{Subscription: id, Result: result},
+			})
+		}
+	}()
+	return id, nil
+}
+
+func (c *jsonrpcConn) unsubscribe(id string) bool {
+	c.Lock()
+	defer c.Unlock()
+	cancel, ok := c.subscriptions[id]
+	if !ok {
+		return false
+	}
+	cancel()
+	delete(c.subscriptions, id)
+	return true
+}
@@ -0,0 +1,154 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/fakeruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+type testResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *JSONRPCError   `json:"error"`
+}
+
+func TestJSONRPC(t *testing.T) {
+	require := require.New(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rt := fakeruntime.New(fakeruntime.Config{})
+	rt.SetBalance(sdkTesting.Alice.Address, nativeUnits(100))
+	info, err := rt.GetInfo(ctx)
+	require.NoError(err, "GetInfo")
+
+	srv := httptest.NewServer(NewJSONRPC(rt, nil))
+	defer srv.Close()
+
+	call := func(body string, rsp interface{}) int {
+		return request(t, srv, http.MethodPost, "/", body, rsp)
+	}
+
+	// Subscribe over WebSocket before submitting the transaction.
+	wsConn, _, err := websocket.DefaultDialer.DialContext(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(err, "Dial")
+	defer wsConn.Close()
+	require.NoError(wsConn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "oasis_subscribe", "params": {"kind": "events"}}`)))
+	var subRsp testResponse
+	require.NoError(wsConn.ReadJSON(&subRsp), "subscribe response")
+	require.Nil(subRsp.Error, "subscribe")
+	var subID string
+	require.NoError(json.Unmarshal(subRsp.Result, &subID))
+
+	// Transaction submission.
+	tx := types.NewTransaction(nil, "accounts.Transfer", &accounts.Transfer{
+		To:     sdkTesting.Bob.Address,
+		Amount: nativeUnits(30),
+	})
+	tx.AppendAuthSignature(sdkTesting.Alice.SigSpec, 0)
+	ts := tx.PrepareForSigning()
+	require.NoError(ts.AppendSign(info.ChainContext, sdkTesting.Alice.Signer), "AppendSign")
+	params, _ := json.Marshal(&SubmitTxParams{Tx: cbor.Marshal(ts.UnverifiedTransaction())})
+	var submitRsp testResponse
+	require.Equal(http.StatusOK, call(`{"jsonrpc": "2.0", "id": "submit", "method": "oasis_submitTx", "params": `+string(params)+`}`, &submitRsp))
+	require.Nil(submitRsp.Error, "submitTx")
+	require.Equal(`"submit"`, string(submitRsp.ID))
+	var submitted SubmitTxResult
+	require.NoError(json.Unmarshal(submitRsp.Result, &submitted))
+	require.EqualValues(1, submitted.Round)
+	require.True(submitted.Result.Success, "transaction should succeed")
+
+	// The subscriber should be notified of the transfer event.
+	var notification struct {
+		Method string `json:"method"`
+		Params struct {
+			Subscription string `json:"subscription"`
+			Result       struct {
+				Round  uint64 `json:"round"`
+				Events []struct {
+					Decoded *accounts.Event `json:"decoded"`
+				} `json:"events"`
+			} `json:"result"`
+		} `json:"params"`
+	}
+	require.NoError(wsConn.ReadJSON(&notification), "notification")
+	require.Equal(MethodSubscription, notification.Method)
+	require.Equal(subID, notification.Params.Subscription)
+	require.EqualValues(1, notification.Params.Result.Round)
+	require.Len(notification.Params.Result.Events, 1)
+	require.NotNil(notification.Params.Result.Events[0].Decoded.Transfer, "transfer event should be decoded")
+
+	// Registered query methods.
+	var queryRsp testResponse
+	require.Equal(http.StatusOK, call(`{"jsonrpc": "2.0", "id": 2, "method": "oasis_query", "params": {"method": "accounts.Nonce", "args": {"address": "`+sdkTesting.Alice.Address.String()+`"}}}`, &queryRsp))
+	require.Nil(queryRsp.Error, "query")
+	require.Equal("1", string(queryRsp.Result))
+	require.Equal(http.StatusOK, call(`{"jsonrpc": "2.0", "id": 3, "method": "oasis_query", "params": {"method": "accounts.Nonce", "round": 0, "args": {"address": "`+sdkTesting.Alice.Address.String()+`"}}}`, &queryRsp))
+	require.Equal("0", string(queryRsp.Result), "nonce at genesis")
+
+	// Batches, where notifications are not answered.
+	var batchRsp []testResponse
+	require.Equal(http.StatusOK, call(`[
+		{"jsonrpc": "2.0", "id": 4, "method": "oasis_getBlock"},
+		{"jsonrpc": "2.0", "method": "oasis_getEvents", "params": {"round": 1}},
+		{"jsonrpc": "2.0", "id": 5, "method": "oasis_getTransactions", "params": {"round": 1}}
+	]`, &batchRsp))
+	require.Len(batchRsp, 2)
+	var blk BlockResponse
+	require.NoError(json.Unmarshal(batchRsp[0].Result, &blk))
+	require.EqualValues(1, blk.Round)
+	var txs []*TransactionResponse
+	require.NoError(json.Unmarshal(batchRsp[1].Result, &txs))
+	require.Len(txs, 1)
+	require.Equal("accounts.Transfer", txs[0].Method)
+	res, err := srv.Client().Post(srv.URL, "application/json", strings.NewReader(`{"jsonrpc": "2.0", "method": "oasis_getBlock"}`))
+	require.NoError(err, "Post")
+	res.Body.Close()
+	require.Equal(http.StatusNoContent, res.StatusCode, "notifications should not be answered")
+
+	// Errors.
+	for _, tc := range []struct {
+		body string
+		code int
+	}{
+		{`{"jsonrpc": "2.0", "id": 1`, ErrCodeParse},
+		{`[]`, ErrCodeInvalidRequest},
+		{`{"id": 1, "method": "oasis_getBlock"}`, ErrCodeInvalidRequest},
+		{`{"jsonrpc": "2.0", "id": 1, "method": "oasis_bogus"}`, ErrCodeMethodNotFound},
+		{`{"jsonrpc": "2.0", "id": 1, "method": "oasis_subscribe", "params": {"kind": "blocks"}}`, ErrCodeMethodNotFound},
+		{`{"jsonrpc": "2.0", "id": 1, "method": "oasis_query", "params": {"method": "accounts.Transfer"}}`, ErrCodeInvalidParams},
+		{`{"jsonrpc": "2.0", "id": 1, "method": "oasis_query", "params": {"method": "accounts.Nonce", "args": {"bogus": 1}}}`, ErrCodeInvalidParams},
+		{`{"jsonrpc": "2.0", "id": 1, "method": "oasis_submitTx", "params": {"tx": "AAAA"}}`, ErrCodeInvalidParams},
+		{`{"jsonrpc": "2.0", "id": 1, "method": "oasis_getBlock", "params": {"round": 42}}`, ErrCodeNode},
+	} {
+		var rsp testResponse
+		require.Equal(http.StatusOK, call(tc.body, &rsp), tc.body)
+		require.NotNil(rsp.Error, tc.body)
+		require.Equal(tc.code, rsp.Error.Code, tc.body)
+	}
+
+	// Unsubscribing.
+	require.NoError(wsConn.WriteJSON(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      6,
+		"method":  MethodUnsubscribe,
+		"params":  &UnsubscribeParams{Subscription: subID},
+	}))
+	var unsubRsp testResponse
+	require.NoError(wsConn.ReadJSON(&unsubRsp), "unsubscribe response")
+	require.Equal("true", string(unsubRsp.Result))
+}
@@ -0,0 +1,315 @@
+package gateway
+
+import (
+	"encoding"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+)
+
+// openAPIVersion is the version of the OpenAPI specification the document follows.
+const openAPIVersion = "3.0.3"
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info is the metadata of the API.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem are the operations of a path by lower-case HTTP method.
+type PathItem map[string]*Operation
+
+// Operation is an API operation.
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter is a path or query parameter of an operation.
+type Parameter struct {
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required"`
+	Schema      Schema `json:"schema"`
+}
+
+// RequestBody is the JSON request body of an operation.
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response is a response of an operation.
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the content of a request or response body.
+type MediaType struct {
+	Schema Schema `json:"schema"`
+}
+
+// Components are the reusable schemas referenced by the document.
+type Components struct {
+	Schemas map[string]Schema `json:"schemas"`
+}
+
+// Schema is a JSON schema, as used by OpenAPI.
+type Schema map[string]interface{}
+
+// Spec generates the OpenAPI document of the gateway, including the query methods of all modules
+// registered at the time of the call.
+func Spec() *Document {
+	b := &specBuilder{
+		doc: &Document{
+			OpenAPI:    openAPIVersion,
+			Info:       Info{Title: "Oasis runtime REST gateway", Version: "1"},
+			Paths:      make(map[string]PathItem),
+			Components: Components{Schemas: make(map[string]Schema)},
+		},
+	}
+
+	roundParam := &Parameter{
+		Name:        "round",
+		In:          "query",
+		Description: "round to query, the latest round if not given",
+		Schema:      Schema{"type": "string", "default": roundLatest},
+	}
+	addressParam := pathParam("address", "Bech32-encoded address of the account")
+	blockRoundParam := pathParam("round", "round of the block or latest")
+	evmAddressParam := pathParam("address", "hex-encoded address of the EVM account")
+
+	b.add("get", "/v1/accounts/{address}/balances", &Operation{
+		OperationID: "getBalances",
+		Summary:     "Balances of an account",
+		Tags:        []string{"accounts"},
+		Parameters:  []*Parameter{addressParam, roundParam},
+	}, nil, &accounts.AccountBalances{})
+	b.add("get", "/v1/accounts/{address}/nonce", &Operation{
+		OperationID: "getNonce",
+		Summary:     "Nonce of an account",
+		Tags:        []string{"accounts"},
+		Parameters:  []*Parameter{addressParam, roundParam},
+	}, nil, &NonceResponse{})
+	b.add("get", "/v1/blocks/{round}", &Operation{
+		OperationID: "getBlock",
+		Summary:     "Block header",
+		Tags:        []string{"blocks"},
+		Parameters:  []*Parameter{blockRoundParam},
+	}, nil, &BlockResponse{})
+	b.add("get", "/v1/blocks/{round}/transactions", &Operation{
+		OperationID: "getTransactions",
+		Summary:     "Transactions of a block with their results and events",
+		Tags:        []string{"blocks"},
+		Parameters:  []*Parameter{blockRoundParam},
+	}, nil, []*TransactionResponse{})
+	b.add("get", "/v1/blocks/{round}/events", &Operation{
+		OperationID: "getEvents",
+		Summary:     "Events of a block",
+		Tags:        []string{"blocks"},
+		Parameters:  []*Parameter{blockRoundParam},
+	}, nil, []*EventResponse{})
+	b.add("get", "/v1/evm/accounts/{address}/balance", &Operation{
+		OperationID: "getEVMBalance",
+		Summary:     "Balance of an EVM account",
+		Tags:        []string{"evm"},
+		Parameters:  []*Parameter{evmAddressParam},
+	}, nil, &EVMBalanceResponse{})
+	b.add("get", "/v1/evm/accounts/{address}/code", &Operation{
+		OperationID: "getEVMCode",
+		Summary:     "Code of an EVM contract",
+		Tags:        []string{"evm"},
+		Parameters:  []*Parameter{evmAddressParam},
+	}, nil, &EVMDataResponse{})
+	b.add("get", "/v1/evm/accounts/{address}/storage/{index}", &Operation{
+		OperationID: "getEVMStorage",
+		Summary:     "Storage slot of an EVM contract",
+		Tags:        []string{"evm"},
+		Parameters:  []*Parameter{evmAddressParam, pathParam("index", "hex-encoded storage index")},
+	}, nil, &EVMDataResponse{})
+	b.add("post", "/v1/evm/simulate-call", &Operation{
+		OperationID: "simulateEVMCall",
+		Summary:     "Simulate an EVM call",
+		Tags:        []string{"evm"},
+	}, &EVMSimulateCallRequest{}, &EVMDataResponse{})
+
+	for _, m := range registry.Modules() {
+		for _, method := range m.Methods {
+			if method.Kind != registry.MethodKindQuery {
+				continue
+			}
+			b.add("post", "/v1/query/"+method.Name, &Operation{
+				OperationID: method.Name,
+				Summary:     "Query " + method.Name,
+				Tags:        []string{m.Name},
+				Parameters:  []*Parameter{roundParam},
+			}, method.Body, method.Result)
+		}
+	}
+	return b.doc
+}
+
+func pathParam(name, description string) *Parameter {
+	return &Parameter{
+		Name:        name,
+		In:          "path",
+		Description: description,
+		Required:    true,
+		Schema:      Schema{"type": "string"},
+	}
+}
+
+type specBuilder struct {
+	doc *Document
+}
+
+// add adds an operation with the given request and response types, given as example values. A nil
+// request means the operation has no body and a nil response that it returns null.
+func (b *specBuilder) add(method, path string, op *Operation, req, rsp interface{}) {
+	if req != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  jsonContent(b.schemaOf(reflect.TypeOf(req))),
+		}
+	}
+	rspSchema := Schema{"nullable": true}
+	if rsp != nil {
+		rspSchema = b.schemaOf(reflect.TypeOf(rsp))
+	}
+	errSchema := b.schemaOf(reflect.TypeOf(&ErrorResponse{}))
+	op.Responses = map[string]*Response{
+		"200": {Description: "success", Content: jsonContent(rspSchema)},
+		"400": {Description: "malformed request", Content: jsonContent(errSchema)},
+		"502": {Description: "the node failed to serve the request", Content: jsonContent(errSchema)},
+	}
+
+	item := b.doc.Paths[path]
+	if item == nil {
+		item = make(PathItem)
+		b.doc.Paths[path] = item
+	}
+	item[method] = op
+}
+
+func jsonContent(schema Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: schema}}
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// schemaOf returns the schema of the JSON encoding of the given type. Named struct types are
+// added to the components of the document and referenced.
+func (b *specBuilder) schemaOf(t reflect.Type) Schema {
+	t = indirectType(t)
+	if t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		return Schema{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Schema{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return Schema{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string", "format": "byte"}
+		}
+		return Schema{"type": "array", "items": b.schemaOf(t.Elem())}
+	case reflect.Array:
+		return Schema{"type": "array", "items": b.schemaOf(t.Elem()), "minItems": t.Len(), "maxItems": t.Len()}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": b.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		name := schemaName(t)
+		if _, exists := b.doc.Components.Schemas[name]; !exists {
+			// Register the name first, so that recursive types terminate.
+			b.doc.Components.Schemas[name] = Schema{}
+			b.doc.Components.Schemas[name] = b.structSchema(t)
+		}
+		return Schema{"$ref": "#/components/schemas/" + name}
+	default:
+		// Interfaces can hold any value.
+		return Schema{}
+	}
+}
+
+func (b *specBuilder) structSchema(t reflect.Type) Schema {
+	properties := make(map[string]Schema)
+	var required []string
+	b.addFields(t, properties, &required)
+
+	schema := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// addFields adds the properties of the JSON encoding of the fields of the given struct type,
+// following the rules of encoding/json for tags and embedded structs.
+func (b *specBuilder) addFields(t reflect.Type, properties map[string]Schema, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if i := strings.IndexByte(tag, ','); i >= 0 {
+			name, opts = tag[:i], tag[i+1:]
+		}
+		if f.Anonymous && name == "" && indirectType(f.Type).Kind() == reflect.Struct {
+			b.addFields(indirectType(f.Type), properties, required)
+			continue
+		}
+		if f.PkgPath != "" {
+			// Unexported field.
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = b.schemaOf(f.Type)
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Ptr {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// schemaName returns the component name of a named type, qualified by its package name.
+func schemaName(t reflect.Type) string {
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
@@ -11,11 +11,27 @@ replace (
 
 require (
 	github.com/btcsuite/btcd v0.22.0-beta
+	github.com/btcsuite/btcutil v1.0.3-0.20201208143702-a53e38424cce
 	github.com/golang/snappy v0.0.4
+	github.com/gorilla/websocket v1.4.2
+	github.com/graph-gophers/graphql-go v1.3.0
+	github.com/hashicorp/go-plugin v1.4.2
+	github.com/improbable-eng/grpc-web v0.14.1
+	github.com/klauspost/compress v1.12.3
+	github.com/lib/pq v1.10.9
+	github.com/miekg/pkcs11 v1.1.1
+	github.com/nats-io/nats.go v1.11.0
 	github.com/oasisprotocol/curve25519-voi v0.0.0-20210716083614-f38f8e8b0b84
 	github.com/oasisprotocol/deoxysii v0.0.0-20200527154044-851aec403956
 	github.com/oasisprotocol/oasis-core/go v0.2103.1
+	github.com/prometheus/client_golang v1.11.0
+	github.com/segmentio/kafka-go v0.3.5
+	github.com/spf13/cobra v1.2.1
+	github.com/spf13/pflag v1.0.5
 	github.com/stretchr/testify v1.7.0
 	golang.org/x/crypto v0.0.0-20210817164053-32db794688a5
+	golang.org/x/sys v0.0.0-20210823070655-63515b42dcdf
+	golang.org/x/text v0.3.7
 	google.golang.org/grpc v1.41.0
+	google.golang.org/protobuf v1.27.1
 )
@@ -0,0 +1,96 @@
+// Package graphql implements a GraphQL server exposing runtime data.
+//
+// The server serves blocks, transactions, events and EVM logs from an indexer (see the indexer
+// package) and account balances and nonces from the runtime, so that clients like web frontends
+// can query exactly the fields they need in a single request. Transactions, their call bodies
+// and results and events are decoded with the module registry, so importing the client package
+// of a module makes its data available in decoded form.
+//
+// Queries are POSTed as JSON objects with the query, operationName and variables fields, and
+// answered with JSON objects with the data and errors fields, as is standard for GraphQL over
+// HTTP. The schema can be discovered through introspection and is also available as Schema.
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/errors"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/indexer"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+)
+
+const (
+	// maxRequestSize is the maximum size of a request body.
+	maxRequestSize = 1 << 20
+	// maxQueryDepth is the maximum depth of the selections of a query, which bounds the number of
+	// lookups of nested queries like transactions of the blocks of transactions.
+	maxQueryDepth = 8
+	// maxLogRounds is the maximum number of rounds of a query for EVM logs.
+	maxLogRounds = 1000
+)
+
+// Server is the GraphQL server. It implements http.Handler.
+type Server struct {
+	rc     client.RuntimeClient
+	reader indexer.Reader
+	schema *graphqlgo.Schema
+
+	accounts accounts.V1
+}
+
+// request is the body of a GraphQL request.
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	var req request
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("malformed request: %w", err))
+		return
+	}
+
+	// Errors of the query are reported in the response, as the request itself was valid.
+	writeJSON(w, http.StatusOK, s.schema.Exec(r.Context(), req.Query, req.OperationName, req.Variables))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		data, _ = json.Marshal(&graphqlgo.Response{Errors: []*errors.QueryError{errors.Errorf("%s", err)}})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, &graphqlgo.Response{Errors: []*errors.QueryError{errors.Errorf("%s", err)}})
+}
+
+// New creates a new GraphQL server serving data of the runtime of the given client and of the
+// indexer with the given reader.
+func New(rc client.RuntimeClient, reader indexer.Reader) *Server {
+	s := &Server{
+		rc:       rc,
+		reader:   reader,
+		accounts: accounts.NewV1(rc),
+	}
+	s.schema = graphqlgo.MustParseSchema(Schema, &queryResolver{s: s}, graphqlgo.MaxDepth(maxQueryDepth))
+	return s
+}
@@ -0,0 +1,272 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/indexer"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/evm"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/fakeruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func nativeUnits(amount uint64) types.BaseUnits {
+	return types.NewBaseUnits(*quantity.NewFromUint64(amount), types.NativeDenomination)
+}
+
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// query executes the given query and decodes its data into the given value, returning the
+// messages of reported errors.
+func query(t *testing.T, srv *httptest.Server, q string, variables map[string]interface{}, data interface{}) []string {
+	body, err := json.Marshal(&request{Query: q, Variables: variables})
+	require.NoError(t, err, "Marshal")
+	res, err := srv.Client().Post(srv.URL, "application/json", bytes.NewReader(body))
+	require.NoError(t, err, "Post")
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var rsp response
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&rsp), "Decode")
+	var msgs []string
+	for _, e := range rsp.Errors {
+		msgs = append(msgs, e.Message)
+	}
+	if data != nil && len(rsp.Data) > 0 {
+		require.NoError(t, json.Unmarshal(rsp.Data, data), "malformed data: %s", rsp.Data)
+	}
+	return msgs
+}
+
+func TestServer(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rt := fakeruntime.New(fakeruntime.Config{})
+	rt.SetBalance(sdkTesting.Alice.Address, nativeUnits(1000))
+	storage := indexer.NewMemoryStorage()
+	ix := indexer.New(rt, storage, 1)
+
+	var transfers []uint64
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		tb := accounts.NewV1(rt).Transfer(sdkTesting.Bob.Address, nativeUnits(100))
+		tb.AppendAuthSignature(sdkTesting.Alice.SigSpec, nonce)
+		require.NoError(tb.AppendSign(ctx, sdkTesting.Alice.Signer), "AppendSign")
+		meta, err := tb.SubmitTxMeta(ctx, nil)
+		require.NoError(err, "SubmitTxMeta")
+		require.NoError(ix.IndexRound(ctx, meta.Round), "IndexRound")
+		transfers = append(transfers, meta.Round)
+	}
+
+	// Index a round with an EVM log, which the fake runtime does not emit.
+	emitter := bytes.Repeat([]byte{0x01}, 20)
+	topic := bytes.Repeat([]byte{0x02}, 32)
+	logRound := transfers[1] + 1
+	txIndex := uint32(0)
+	require.NoError(storage.StoreRound(ctx, &indexer.RoundData{
+		Block: &indexer.Block{Round: logRound, NumTransactions: 1},
+		Transactions: []*indexer.Transaction{
+			{Round: logRound, Hash: hash.NewFromBytes([]byte("evm tx"))},
+		},
+		Events: []*indexer.Event{{
+			Round:   logRound,
+			TxIndex: &txIndex,
+			Module:  evm.ModuleName,
+			Code:    evm.LogEventCode,
+			Value:   cbor.Marshal(&evm.LogEvent{Address: emitter, Topics: [][]byte{topic}, Data: []byte{0x03}}),
+		}},
+	}), "StoreRound")
+
+	srv := httptest.NewServer(New(rt, storage))
+	defer srv.Close()
+
+	// Blocks with their transactions and events.
+	var blockData struct {
+		Block struct {
+			Round           uint64
+			NumTransactions int
+			Transactions    []struct {
+				Hash    string
+				Method  string
+				Success bool
+				Body    struct {
+					To     string
+					Amount struct{ Amount string }
+				}
+				Events []struct {
+					Module  string
+					Code    int
+					Decoded map[string]interface{}
+				}
+				Block struct {
+					Round uint64
+				}
+			}
+		}
+	}
+	msgs := query(t, srv, `query($round: Uint64) {
+		block(round: $round) {
+			round
+			numTransactions
+			transactions {
+				hash method success body
+				events(module: "accounts") { module code decoded }
+				block { round }
+			}
+		}
+	}`, map[string]interface{}{"round": transfers[0]}, &blockData)
+	require.Empty(msgs)
+	blk := blockData.Block
+	require.Equal(transfers[0], blk.Round)
+	require.Equal(1, blk.NumTransactions)
+	require.Len(blk.Transactions, 1)
+	tx := blk.Transactions[0]
+	require.Equal("accounts.Transfer", tx.Method)
+	require.True(tx.Success)
+	require.Equal(sdkTesting.Bob.Address.String(), tx.Body.To)
+	require.Equal("100", tx.Body.Amount.Amount)
+	require.Len(tx.Events, 1)
+	require.Equal(accounts.ModuleName, tx.Events[0].Module)
+	require.EqualValues(accounts.TransferEventCode, tx.Events[0].Code)
+	require.NotNil(tx.Events[0].Decoded["transfer"], "event should be decoded")
+	require.Equal(transfers[0], tx.Block.Round)
+
+	// Blocks default to the last indexed round, and rounds that are not indexed are null.
+	msgs = query(t, srv, `{ block { round evmLogs { address } } }`, nil, &blockData)
+	require.Empty(msgs)
+	require.Equal(logRound, blockData.Block.Round)
+	var missing struct {
+		Block       *struct{ Round uint64 }
+		Transaction *struct{ Hash string }
+	}
+	msgs = query(t, srv, `{ block(round: "1000") { round } transaction(hash: "`+hash.NewFromBytes([]byte("unknown")).Hex()+`") { hash } }`, nil, &missing)
+	require.Empty(msgs)
+	require.Nil(missing.Block)
+	require.Nil(missing.Transaction)
+
+	// Transactions by hash.
+	var txData struct {
+		Transaction struct {
+			Round  uint64
+			Method string
+		}
+	}
+	msgs = query(t, srv, `query($hash: Hash!) { transaction(hash: $hash) { round method } }`,
+		map[string]interface{}{"hash": tx.Hash}, &txData)
+	require.Empty(msgs)
+	require.Equal(transfers[0], txData.Transaction.Round)
+	require.Equal("accounts.Transfer", txData.Transaction.Method)
+
+	// Accounts with their state and paged transactions.
+	type page struct {
+		Transactions []struct{ Round uint64 }
+		Next         *string
+	}
+	var accountData struct {
+		Account struct {
+			Address  string
+			Balances []struct {
+				Denomination string
+				Amount       string
+			}
+			Nonce        uint64
+			Transactions page
+		}
+	}
+	accountQuery := `query($address: Address!, $after: String) {
+		account(address: $address) {
+			address
+			balances { denomination amount }
+			nonce
+			transactions(first: 1, after: $after) { transactions { round } next }
+		}
+	}`
+	variables := map[string]interface{}{"address": sdkTesting.Alice.Address.String()}
+	msgs = query(t, srv, accountQuery, variables, &accountData)
+	require.Empty(msgs)
+	account := accountData.Account
+	require.Equal(sdkTesting.Alice.Address.String(), account.Address)
+	require.Len(account.Balances, 1)
+	require.Equal("<native>", account.Balances[0].Denomination)
+	require.Equal("800", account.Balances[0].Amount)
+	require.EqualValues(2, account.Nonce)
+	require.Len(account.Transactions.Transactions, 1)
+	require.Equal(transfers[1], account.Transactions.Transactions[0].Round, "most recent transaction first")
+	require.NotNil(account.Transactions.Next)
+
+	variables["after"] = *account.Transactions.Next
+	msgs = query(t, srv, accountQuery, variables, &accountData)
+	require.Empty(msgs)
+	require.Len(accountData.Account.Transactions.Transactions, 1)
+	require.Equal(transfers[0], accountData.Account.Transactions.Transactions[0].Round)
+
+	// EVM logs of a range of rounds.
+	var logsData struct {
+		EVMLogs []struct {
+			Round   uint64
+			TxIndex *int
+			Address string
+			Topics  []string
+			Data    string
+		}
+	}
+	logsQuery := `query($address: HexBytes) {
+		evmLogs(fromRound: 0, toRound: 100, address: $address) { round txIndex address topics data }
+	}`
+	msgs = query(t, srv, logsQuery, map[string]interface{}{"address": "0x0101010101010101010101010101010101010101"}, &logsData)
+	require.Empty(msgs)
+	require.Len(logsData.EVMLogs, 1)
+	log := logsData.EVMLogs[0]
+	require.Equal(logRound, log.Round)
+	require.NotNil(log.TxIndex)
+	require.Equal(0, *log.TxIndex)
+	require.Equal("0x0101010101010101010101010101010101010101", log.Address)
+	require.Equal([]string{"0x0202020202020202020202020202020202020202020202020202020202020202"}, log.Topics)
+	require.Equal("0x03", log.Data)
+	msgs = query(t, srv, logsQuery, map[string]interface{}{"address": "0x02"}, &logsData)
+	require.Empty(msgs)
+	require.Empty(logsData.EVMLogs, "logs of other contracts should be filtered")
+
+	// Malformed arguments are reported as errors.
+	msgs = query(t, srv, `{ account(address: "bogus") { nonce } }`, nil, nil)
+	require.NotEmpty(msgs)
+	msgs = query(t, srv, `{ evmLogs(fromRound: 0, toRound: 5000) { round } }`, nil, nil)
+	require.NotEmpty(msgs)
+
+	res, err := srv.Client().Get(srv.URL)
+	require.NoError(err, "Get")
+	res.Body.Close()
+	require.Equal(http.StatusMethodNotAllowed, res.StatusCode)
+}
+
+func TestCursor(t *testing.T) {
+	require := require.New(t)
+
+	cursor, err := parseCursor("42:7")
+	require.NoError(err, "parseCursor")
+	require.EqualValues(42, cursor.Round)
+	require.EqualValues(7, cursor.Index)
+	require.Equal("42:7", formatCursor(cursor))
+
+	for _, s := range []string{"", "42", "42:", "a:7", "42:7:1", "42:4294967296"} {
+		_, err = parseCursor(s)
+		require.Error(err, "malformed cursor '%s'", s)
+	}
+}
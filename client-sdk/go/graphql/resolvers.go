@@ -0,0 +1,520 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/history"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/indexer"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/evm"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// roundArg returns the round of an optional round argument.
+func roundArg(round *uint64Scalar) uint64 {
+	if round == nil {
+		return client.RoundLatest
+	}
+	return uint64(*round)
+}
+
+// events returns the indexed events of the given round, only those of the given module in case
+// it is not nil.
+func (s *Server) events(ctx context.Context, round uint64, module *string) ([]*indexer.Event, error) {
+	evs, err := s.reader.Events(ctx, round)
+	if err != nil {
+		return nil, err
+	}
+	if module == nil {
+		return evs, nil
+	}
+	var filtered []*indexer.Event
+	for _, ev := range evs {
+		if ev.Module == *module {
+			filtered = append(filtered, ev)
+		}
+	}
+	return filtered, nil
+}
+
+// newEventResolvers returns the resolvers of the given events, only of those emitted by the
+// transaction with the given index in case it is not nil.
+func newEventResolvers(evs []*indexer.Event, txIndex *uint32) []*eventResolver {
+	rs := []*eventResolver{}
+	for _, ev := range evs {
+		if txIndex != nil && (ev.TxIndex == nil || *ev.TxIndex != *txIndex) {
+			continue
+		}
+		rs = append(rs, &eventResolver{ev: ev})
+	}
+	return rs
+}
+
+// newEVMLogResolvers returns the resolvers of the EVM logs of the given events, only of those
+// emitted by the transaction with the given index in case it is not nil.
+func newEVMLogResolvers(evs []*indexer.Event, txIndex *uint32) []*evmLogResolver {
+	rs := []*evmLogResolver{}
+	for _, ev := range evs {
+		if ev.Module != evm.ModuleName || ev.Code != evm.LogEventCode {
+			continue
+		}
+		if txIndex != nil && (ev.TxIndex == nil || *ev.TxIndex != *txIndex) {
+			continue
+		}
+		var log evm.LogEvent
+		if err := cbor.Unmarshal(ev.Value, &log); err != nil {
+			continue
+		}
+		rs = append(rs, &evmLogResolver{ev: ev, log: &log})
+	}
+	return rs
+}
+
+// queryResolver resolves the root query.
+type queryResolver struct {
+	s *Server
+}
+
+func (r *queryResolver) Block(ctx context.Context, args struct{ Round *uint64Scalar }) (*blockResolver, error) {
+	var round uint64
+	if args.Round != nil {
+		round = uint64(*args.Round)
+	} else {
+		last, ok, err := r.s.reader.LastRound(ctx)
+		switch {
+		case err != nil:
+			return nil, err
+		case !ok:
+			return nil, nil
+		}
+		round = last
+	}
+
+	blk, err := r.s.reader.Block(ctx, round)
+	switch {
+	case errors.Is(err, indexer.ErrNotFound):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	return &blockResolver{s: r.s, blk: blk}, nil
+}
+
+func (r *queryResolver) Transaction(ctx context.Context, args struct{ Hash hashScalar }) (*txResolver, error) {
+	tx, err := r.s.reader.Transaction(ctx, hash.Hash(args.Hash))
+	switch {
+	case errors.Is(err, indexer.ErrNotFound):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	return newTxResolver(r.s, tx.Round, tx.Index, tx.Hash, &tx.Tx, &tx.Result), nil
+}
+
+func (r *queryResolver) Account(args struct{ Address addressScalar }) *accountResolver {
+	return &accountResolver{s: r.s, address: types.Address(args.Address)}
+}
+
+func (r *queryResolver) EVMLogs(ctx context.Context, args struct {
+	FromRound uint64Scalar
+	ToRound   uint64Scalar
+	Address   *hexBytes
+}) ([]*evmLogResolver, error) {
+	from, to := uint64(args.FromRound), uint64(args.ToRound)
+	if from > to {
+		return nil, fmt.Errorf("graphql: start round %d after end round %d", from, to)
+	}
+	if to-from >= maxLogRounds {
+		return nil, fmt.Errorf("graphql: range of more than %d rounds", maxLogRounds)
+	}
+	last, ok, err := r.s.reader.LastRound(ctx)
+	switch {
+	case err != nil:
+		return nil, err
+	case !ok:
+		return []*evmLogResolver{}, nil
+	case to > last:
+		to = last
+	}
+
+	logs := []*evmLogResolver{}
+	for round := from; round <= to; round++ {
+		evs, err := r.s.reader.Events(ctx, round)
+		switch {
+		case errors.Is(err, indexer.ErrNotFound):
+			// Rounds before the start round of the indexer are not indexed.
+			continue
+		case err != nil:
+			return nil, err
+		}
+		for _, log := range newEVMLogResolvers(evs, nil) {
+			if args.Address != nil && string(log.log.Address) != string(*args.Address) {
+				continue
+			}
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+// blockResolver resolves an indexed block.
+type blockResolver struct {
+	s   *Server
+	blk *indexer.Block
+}
+
+func (r *blockResolver) Round() uint64Scalar {
+	return uint64Scalar(r.blk.Round)
+}
+
+func (r *blockResolver) Hash() hashScalar {
+	return hashScalar(r.blk.Hash)
+}
+
+func (r *blockResolver) Timestamp() uint64Scalar {
+	return uint64Scalar(r.blk.Timestamp)
+}
+
+func (r *blockResolver) NumTransactions() int32 {
+	return int32(r.blk.NumTransactions)
+}
+
+func (r *blockResolver) Transactions(ctx context.Context) ([]*txResolver, error) {
+	txs, err := r.s.reader.Transactions(ctx, r.blk.Round)
+	if err != nil {
+		return nil, err
+	}
+	rs := make([]*txResolver, 0, len(txs))
+	for _, tx := range txs {
+		rs = append(rs, newTxResolver(r.s, tx.Round, tx.Index, tx.Hash, &tx.Tx, &tx.Result))
+	}
+	return rs, nil
+}
+
+func (r *blockResolver) Events(ctx context.Context, args struct{ Module *string }) ([]*eventResolver, error) {
+	evs, err := r.s.events(ctx, r.blk.Round, args.Module)
+	if err != nil {
+		return nil, err
+	}
+	return newEventResolvers(evs, nil), nil
+}
+
+func (r *blockResolver) EVMLogs(ctx context.Context) ([]*evmLogResolver, error) {
+	evs, err := r.s.events(ctx, r.blk.Round, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newEVMLogResolvers(evs, nil), nil
+}
+
+// txResolver resolves a transaction along with its result.
+type txResolver struct {
+	s      *Server
+	round  uint64
+	index  uint32
+	hash   hash.Hash
+	result *types.CallResult
+
+	method  *string
+	body    *jsonScalar
+	decoded *jsonScalar
+}
+
+func newTxResolver(s *Server, round uint64, index uint32, txHash hash.Hash, tx *types.UnverifiedTransaction, result *types.CallResult) *txResolver {
+	r := &txResolver{s: s, round: round, index: index, hash: txHash, result: result}
+	decoded, method, body, err := registry.DecodeTransaction(tx)
+	switch {
+	case err == nil:
+		r.method = &method.Name
+		if body != nil {
+			r.body = &jsonScalar{value: body}
+		}
+		if result.IsSuccess() && len(result.Ok) > 0 {
+			if ok, err := method.DecodeResult(result.Ok); err == nil && ok != nil {
+				r.decoded = &jsonScalar{value: ok}
+			}
+		}
+	case decoded != nil:
+		// Keep the method name of transactions that cannot be decoded, e.g. of unregistered
+		// modules or encrypted calls.
+		r.method = &decoded.Call.Method
+	}
+	return r
+}
+
+func (r *txResolver) Round() uint64Scalar {
+	return uint64Scalar(r.round)
+}
+
+func (r *txResolver) Index() int32 {
+	return int32(r.index)
+}
+
+func (r *txResolver) Hash() hashScalar {
+	return hashScalar(r.hash)
+}
+
+func (r *txResolver) Method() *string {
+	return r.method
+}
+
+func (r *txResolver) Body() *jsonScalar {
+	return r.body
+}
+
+func (r *txResolver) Success() bool {
+	return r.result.IsSuccess()
+}
+
+func (r *txResolver) Result() *jsonScalar {
+	return r.decoded
+}
+
+func (r *txResolver) Error() *callErrorResolver {
+	if r.result.Failed == nil {
+		return nil
+	}
+	return &callErrorResolver{failed: r.result.Failed}
+}
+
+func (r *txResolver) Events(ctx context.Context, args struct{ Module *string }) ([]*eventResolver, error) {
+	evs, err := r.s.events(ctx, r.round, args.Module)
+	if err != nil {
+		return nil, err
+	}
+	return newEventResolvers(evs, &r.index), nil
+}
+
+func (r *txResolver) EVMLogs(ctx context.Context) ([]*evmLogResolver, error) {
+	evs, err := r.s.events(ctx, r.round, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newEVMLogResolvers(evs, &r.index), nil
+}
+
+func (r *txResolver) Block(ctx context.Context) (*blockResolver, error) {
+	blk, err := r.s.reader.Block(ctx, r.round)
+	if err != nil {
+		return nil, err
+	}
+	return &blockResolver{s: r.s, blk: blk}, nil
+}
+
+// callErrorResolver resolves the error of a failed call.
+type callErrorResolver struct {
+	failed *types.FailedCallResult
+}
+
+func (r *callErrorResolver) Module() string {
+	return r.failed.Module
+}
+
+func (r *callErrorResolver) Code() int32 {
+	return int32(r.failed.Code)
+}
+
+func (r *callErrorResolver) Message() string {
+	return r.failed.Message
+}
+
+// eventResolver resolves an indexed event.
+type eventResolver struct {
+	ev *indexer.Event
+}
+
+func (r *eventResolver) Round() uint64Scalar {
+	return uint64Scalar(r.ev.Round)
+}
+
+func (r *eventResolver) Index() int32 {
+	return int32(r.ev.Index)
+}
+
+func (r *eventResolver) TxIndex() *int32 {
+	if r.ev.TxIndex == nil {
+		return nil
+	}
+	txIndex := int32(*r.ev.TxIndex)
+	return &txIndex
+}
+
+func (r *eventResolver) Module() string {
+	return r.ev.Module
+}
+
+func (r *eventResolver) Code() int32 {
+	return int32(r.ev.Code)
+}
+
+func (r *eventResolver) Decoded() *jsonScalar {
+	decoded, err := registry.DecodeEvent(r.ev.Raw())
+	if err != nil || decoded == nil {
+		return nil
+	}
+	return &jsonScalar{value: decoded}
+}
+
+func (r *eventResolver) Value() hexBytes {
+	return r.ev.Value
+}
+
+// evmLogResolver resolves an EVM log.
+type evmLogResolver struct {
+	ev  *indexer.Event
+	log *evm.LogEvent
+}
+
+func (r *evmLogResolver) Round() uint64Scalar {
+	return uint64Scalar(r.ev.Round)
+}
+
+func (r *evmLogResolver) Index() int32 {
+	return int32(r.ev.Index)
+}
+
+func (r *evmLogResolver) TxIndex() *int32 {
+	return (&eventResolver{ev: r.ev}).TxIndex()
+}
+
+func (r *evmLogResolver) Address() hexBytes {
+	return r.log.Address
+}
+
+func (r *evmLogResolver) Topics() []hexBytes {
+	topics := make([]hexBytes, 0, len(r.log.Topics))
+	for _, topic := range r.log.Topics {
+		topics = append(topics, topic)
+	}
+	return topics
+}
+
+func (r *evmLogResolver) Data() hexBytes {
+	return r.log.Data
+}
+
+// accountResolver resolves a runtime account.
+type accountResolver struct {
+	s       *Server
+	address types.Address
+}
+
+func (r *accountResolver) Address() addressScalar {
+	return addressScalar(r.address)
+}
+
+func (r *accountResolver) Balances(ctx context.Context, args struct{ Round *uint64Scalar }) ([]*balanceResolver, error) {
+	balances, err := r.s.accounts.Balances(ctx, roundArg(args.Round), r.address)
+	if err != nil {
+		return nil, err
+	}
+	rs := make([]*balanceResolver, 0, len(balances.Balances))
+	for denomination, amount := range balances.Balances {
+		rs = append(rs, &balanceResolver{denomination: denomination, amount: amount})
+	}
+	sort.Slice(rs, func(i, j int) bool {
+		return rs[i].denomination < rs[j].denomination
+	})
+	return rs, nil
+}
+
+func (r *accountResolver) Nonce(ctx context.Context, args struct{ Round *uint64Scalar }) (uint64Scalar, error) {
+	nonce, err := r.s.accounts.Nonce(ctx, roundArg(args.Round), r.address)
+	if err != nil {
+		return 0, err
+	}
+	return uint64Scalar(nonce), nil
+}
+
+func (r *accountResolver) Transactions(ctx context.Context, args struct {
+	First *int32
+	After *string
+}) (*txPageResolver, error) {
+	pager := &history.Pager{}
+	if args.First != nil {
+		if *args.First <= 0 || *args.First > history.DefaultLimit {
+			return nil, fmt.Errorf("graphql: page size must be between 1 and %d", history.DefaultLimit)
+		}
+		pager.Limit = uint64(*args.First)
+	}
+	if args.After != nil {
+		cursor, err := parseCursor(*args.After)
+		if err != nil {
+			return nil, err
+		}
+		pager.Cursor = cursor
+	}
+
+	page, err := r.s.reader.TransactionsForAddress(ctx, r.address, pager)
+	if err != nil {
+		return nil, err
+	}
+	return &txPageResolver{s: r.s, page: page}, nil
+}
+
+// balanceResolver resolves the balance of an account in a denomination.
+type balanceResolver struct {
+	denomination types.Denomination
+	amount       quantity.Quantity
+}
+
+func (r *balanceResolver) Denomination() string {
+	return r.denomination.String()
+}
+
+func (r *balanceResolver) Amount() quantityScalar {
+	return quantityScalar(r.amount)
+}
+
+// txPageResolver resolves a page of the transactions of an account.
+type txPageResolver struct {
+	s    *Server
+	page *history.Page
+}
+
+func (r *txPageResolver) Transactions() []*txResolver {
+	rs := make([]*txResolver, 0, len(r.page.Transactions))
+	for _, tx := range r.page.Transactions {
+		rs = append(rs, newTxResolver(r.s, tx.Round, tx.Index, tx.Hash, &tx.Tx, &tx.Result))
+	}
+	return rs
+}
+
+func (r *txPageResolver) Next() *string {
+	if r.page.Next == nil {
+		return nil
+	}
+	cursor := formatCursor(r.page.Next)
+	return &cursor
+}
+
+// formatCursor encodes a history cursor as round:index.
+func formatCursor(cursor *history.Cursor) string {
+	return fmt.Sprintf("%d:%d", cursor.Round, cursor.Index)
+}
+
+func parseCursor(s string) (*history.Cursor, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("graphql: malformed cursor '%s'", s)
+	}
+	round, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("graphql: malformed cursor '%s': %w", s, err)
+	}
+	index, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("graphql: malformed cursor '%s': %w", s, err)
+	}
+	return &history.Cursor{Round: round, Index: uint32(index)}, nil
+}
@@ -0,0 +1,296 @@
+package graphql
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// Schema is the GraphQL schema of the server.
+const Schema = `
+schema {
+	query: Query
+}
+
+"Unsigned 64-bit integer. It is encoded as a JSON number and also accepted as a decimal string."
+scalar Uint64
+"Arbitrary-precision unsigned integer, e.g. an amount in base units, encoded as a decimal string."
+scalar Quantity
+"Hash, encoded as a hex string."
+scalar Hash
+"Runtime account address, encoded in Bech32."
+scalar Address
+"Byte string, encoded as a hex string with the 0x prefix."
+scalar HexBytes
+"Arbitrary JSON value. Byte strings within it are base64-encoded."
+scalar JSON
+
+type Query {
+	"Indexed block of the given round, or of the last indexed round if omitted."
+	block(round: Uint64): Block
+	"Indexed transaction with the given hash."
+	transaction(hash: Hash!): Transaction
+	"Account with the given address."
+	account(address: Address!): Account!
+	"""
+	EVM logs emitted in the given inclusive range of indexed rounds, optionally only those of the
+	contract with the given address. The range may span at most 1000 rounds.
+	"""
+	evmLogs(fromRound: Uint64!, toRound: Uint64!, address: HexBytes): [EVMLog!]!
+}
+
+type Block {
+	round: Uint64!
+	"Hash of the block header."
+	hash: Hash!
+	"Block timestamp in seconds since the Unix epoch."
+	timestamp: Uint64!
+	numTransactions: Int!
+	transactions: [Transaction!]!
+	"Events of the block, including those emitted by transactions, optionally of a single module."
+	events(module: String): [Event!]!
+	evmLogs: [EVMLog!]!
+}
+
+type Transaction {
+	round: Uint64!
+	"Index of the transaction within the round."
+	index: Int!
+	hash: Hash!
+	"Called method, or null if the transaction could not be decoded, e.g. because it is encrypted."
+	method: String
+	"Decoded call body, or null if the method is not registered."
+	body: JSON
+	success: Boolean!
+	"Decoded result of a successful call, or null if the method is not registered."
+	result: JSON
+	"Error of a failed call."
+	error: CallError
+	"Events emitted by the transaction, optionally of a single module."
+	events(module: String): [Event!]!
+	evmLogs: [EVMLog!]!
+	block: Block!
+}
+
+type CallError {
+	module: String!
+	code: Int!
+	message: String!
+}
+
+type Event {
+	round: Uint64!
+	"Index of the event within the round."
+	index: Int!
+	"Index of the transaction that emitted the event, or null for events emitted outside of transactions."
+	txIndex: Int
+	module: String!
+	code: Int!
+	"Decoded event, or null if the module of the event is not registered."
+	decoded: JSON
+	"CBOR-encoded event value."
+	value: HexBytes!
+}
+
+type EVMLog {
+	round: Uint64!
+	"Index of the event of the log within the round."
+	index: Int!
+	"Index of the transaction that emitted the log."
+	txIndex: Int
+	"Address of the contract that emitted the log."
+	address: HexBytes!
+	topics: [HexBytes!]!
+	data: HexBytes!
+}
+
+type Account {
+	address: Address!
+	"Balances at the given round, or at the latest round if omitted."
+	balances(round: Uint64): [Balance!]!
+	"Nonce at the given round, or at the latest round if omitted."
+	nonce(round: Uint64): Uint64!
+	"""
+	Indexed transactions involving the account, from the most recent one. The next page is
+	requested by passing the next cursor of a page as after.
+	"""
+	transactions(first: Int, after: String): TransactionPage!
+}
+
+type Balance {
+	denomination: String!
+	amount: Quantity!
+}
+
+type TransactionPage {
+	transactions: [Transaction!]!
+	"Cursor of the next page, or null if there are no more transactions."
+	next: String
+}
+`
+
+// uint64Scalar is the Uint64 scalar.
+type uint64Scalar uint64
+
+// Implements decode.Unmarshaler.
+func (uint64Scalar) ImplementsGraphQLType(name string) bool {
+	return name == "Uint64"
+}
+
+// Implements decode.Unmarshaler.
+func (u *uint64Scalar) UnmarshalGraphQL(input interface{}) error {
+	switch v := input.(type) {
+	case int32:
+		if v < 0 {
+			return fmt.Errorf("graphql: negative Uint64 %d", v)
+		}
+		*u = uint64Scalar(v)
+	case float64:
+		// Variables are decoded as JSON numbers.
+		if v < 0 || v > math.MaxUint64 || v != math.Trunc(v) {
+			return fmt.Errorf("graphql: malformed Uint64 %v", v)
+		}
+		*u = uint64Scalar(v)
+	case string:
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("graphql: malformed Uint64: %w", err)
+		}
+		*u = uint64Scalar(n)
+	default:
+		return fmt.Errorf("graphql: wrong type for Uint64: %T", input)
+	}
+	return nil
+}
+
+// quantityScalar is the Quantity scalar.
+type quantityScalar quantity.Quantity
+
+// Implements decode.Unmarshaler.
+func (quantityScalar) ImplementsGraphQLType(name string) bool {
+	return name == "Quantity"
+}
+
+// Implements decode.Unmarshaler.
+func (q *quantityScalar) UnmarshalGraphQL(input interface{}) error {
+	s, ok := input.(string)
+	if !ok {
+		return fmt.Errorf("graphql: wrong type for Quantity: %T", input)
+	}
+	return (*quantity.Quantity)(q).UnmarshalText([]byte(s))
+}
+
+// Implements json.Marshaler.
+func (q quantityScalar) MarshalJSON() ([]byte, error) {
+	v := quantity.Quantity(q)
+	return json.Marshal(v.String())
+}
+
+// hashScalar is the Hash scalar.
+type hashScalar hash.Hash
+
+// Implements decode.Unmarshaler.
+func (hashScalar) ImplementsGraphQLType(name string) bool {
+	return name == "Hash"
+}
+
+// Implements decode.Unmarshaler.
+func (h *hashScalar) UnmarshalGraphQL(input interface{}) error {
+	s, ok := input.(string)
+	if !ok {
+		return fmt.Errorf("graphql: wrong type for Hash: %T", input)
+	}
+	if err := (*hash.Hash)(h).UnmarshalHex(s); err != nil {
+		return fmt.Errorf("graphql: malformed Hash: %w", err)
+	}
+	return nil
+}
+
+// Implements json.Marshaler.
+func (h hashScalar) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hash.Hash(h).Hex())
+}
+
+// addressScalar is the Address scalar.
+type addressScalar types.Address
+
+// Implements decode.Unmarshaler.
+func (addressScalar) ImplementsGraphQLType(name string) bool {
+	return name == "Address"
+}
+
+// Implements decode.Unmarshaler.
+func (a *addressScalar) UnmarshalGraphQL(input interface{}) error {
+	s, ok := input.(string)
+	if !ok {
+		return fmt.Errorf("graphql: wrong type for Address: %T", input)
+	}
+	if err := (*types.Address)(a).UnmarshalText([]byte(s)); err != nil {
+		return fmt.Errorf("graphql: malformed Address: %w", err)
+	}
+	return nil
+}
+
+// Implements json.Marshaler.
+func (a addressScalar) MarshalJSON() ([]byte, error) {
+	return json.Marshal(types.Address(a).String())
+}
+
+// hexBytes is the HexBytes scalar.
+type hexBytes []byte
+
+// Implements decode.Unmarshaler.
+func (hexBytes) ImplementsGraphQLType(name string) bool {
+	return name == "HexBytes"
+}
+
+// Implements decode.Unmarshaler.
+func (b *hexBytes) UnmarshalGraphQL(input interface{}) error {
+	s, ok := input.(string)
+	if !ok {
+		return fmt.Errorf("graphql: wrong type for HexBytes: %T", input)
+	}
+	if !strings.HasPrefix(s, "0x") {
+		return fmt.Errorf("graphql: HexBytes without 0x prefix")
+	}
+	data, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return fmt.Errorf("graphql: malformed HexBytes: %w", err)
+	}
+	*b = data
+	return nil
+}
+
+// Implements json.Marshaler.
+func (b hexBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal("0x" + hex.EncodeToString(b))
+}
+
+// jsonScalar is the JSON scalar.
+type jsonScalar struct {
+	value interface{}
+}
+
+// Implements decode.Unmarshaler.
+func (jsonScalar) ImplementsGraphQLType(name string) bool {
+	return name == "JSON"
+}
+
+// Implements decode.Unmarshaler.
+func (j *jsonScalar) UnmarshalGraphQL(input interface{}) error {
+	j.value = input
+	return nil
+}
+
+// Implements json.Marshaler.
+func (j jsonScalar) MarshalJSON() ([]byte, error) {
+	return json.Marshal(j.value)
+}
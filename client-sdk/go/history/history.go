@@ -0,0 +1,100 @@
+// Package history provides access to the transaction history of runtime accounts.
+package history
+
+import (
+	"context"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// DefaultLimit is the default maximum number of transactions returned in a single page.
+const DefaultLimit = 100
+
+// Cursor identifies a position in the transaction history.
+type Cursor struct {
+	// Round is the round of the transaction.
+	Round uint64 `json:"round"`
+	// Index is the index of the transaction within the round.
+	Index uint32 `json:"index"`
+}
+
+// Pager controls which part of the history is returned.
+type Pager struct {
+	// Limit is the maximum number of transactions to return. If zero, DefaultLimit is used.
+	Limit uint64 `json:"limit,omitempty"`
+	// Cursor is the position returned as Next in a previous page. If nil, the history is
+	// returned starting with the most recent transaction.
+	Cursor *Cursor `json:"cursor,omitempty"`
+}
+
+// GetLimit returns the effective page size limit.
+func (p *Pager) GetLimit() uint64 {
+	if p == nil || p.Limit == 0 {
+		return DefaultLimit
+	}
+	return p.Limit
+}
+
+// Transaction is a transaction that involves a given account.
+type Transaction struct {
+	// Round is the round in which the transaction was executed.
+	Round uint64 `json:"round"`
+	// Index is the index of the transaction within the round.
+	Index uint32 `json:"index"`
+	// Hash is the transaction hash.
+	Hash hash.Hash `json:"hash"`
+
+	// Tx is the (signed) transaction.
+	Tx types.UnverifiedTransaction `json:"tx"`
+	// Result is the transaction call result.
+	Result types.CallResult `json:"result"`
+	// Events are the events emitted by the transaction.
+	Events []*types.Event `json:"events,omitempty"`
+}
+
+// DecodeCall decodes the transaction's call body using the module registry.
+func (t *Transaction) DecodeCall() (*registry.Method, interface{}, error) {
+	_, method, body, err := registry.DecodeTransaction(&t.Tx)
+	return method, body, err
+}
+
+// DecodeEvents decodes the transaction's events using the module registry. Events not recognized
+// by any registered module are skipped.
+func (t *Transaction) DecodeEvents() ([]client.DecodedEvent, error) {
+	var evs []client.DecodedEvent
+	for _, rawEv := range t.Events {
+		ev, err := registry.DecodeEvent(rawEv)
+		if err != nil {
+			return nil, err
+		}
+		if ev == nil {
+			continue
+		}
+		evs = append(evs, ev)
+	}
+	return evs, nil
+}
+
+// Page is a page of transaction history, ordered from the most recent transaction to the
+// oldest one.
+type Page struct {
+	// Transactions are the transactions in this page.
+	Transactions []*Transaction `json:"transactions"`
+	// Next is the cursor that should be used to fetch the next page. It is nil in case there
+	// are no more transactions.
+	Next *Cursor `json:"next,omitempty"`
+}
+
+// Backend is a source of account transaction history.
+//
+// The SDK provides a backend that scans the blocks available on the connected node (see
+// NewNodeScanBackend), but applications can plug in an external indexer instead.
+type Backend interface {
+	// TransactionsForAddress returns a page of transactions that involve the given address,
+	// either as one of the signers or as a party in any of the emitted events.
+	TransactionsForAddress(ctx context.Context, addr types.Address, pager *Pager) (*Page, error)
+}
@@ -0,0 +1,183 @@
+package history
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/consensusaccounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// DefaultMaxScanRounds is the default maximum number of rounds scanned by the node scan backend
+// in a single call.
+const DefaultMaxScanRounds = 1000
+
+type nodeScanBackend struct {
+	rc  client.RuntimeClient
+	ac  accounts.V1
+	cac consensusaccounts.V1
+
+	maxScanRounds uint64
+}
+
+// Implements Backend.
+func (b *nodeScanBackend) TransactionsForAddress(ctx context.Context, addr types.Address, pager *Pager) (*Page, error) {
+	genesis, err := b.rc.GetGenesisBlock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("history: failed to fetch genesis block: %w", err)
+	}
+	minRound := genesis.Header.Round
+
+	// Determine where to start scanning. Cursors are exclusive, so we only need to consider
+	// transactions strictly before the cursor position.
+	var (
+		round    uint64
+		maxIndex = ^uint32(0)
+	)
+	switch {
+	case pager != nil && pager.Cursor != nil:
+		round = pager.Cursor.Round
+		if pager.Cursor.Index == 0 {
+			if round <= minRound {
+				return &Page{}, nil
+			}
+			round--
+		} else {
+			maxIndex = pager.Cursor.Index - 1
+		}
+	default:
+		blk, err := b.rc.GetBlock(ctx, client.RoundLatest)
+		if err != nil {
+			return nil, fmt.Errorf("history: failed to fetch latest block: %w", err)
+		}
+		round = blk.Header.Round
+	}
+
+	limit := pager.GetLimit()
+	page := &Page{}
+	for scanned := uint64(0); ; scanned++ {
+		if scanned >= b.maxScanRounds {
+			// Scan budget exhausted, let the caller continue from here.
+			page.Next = &Cursor{Round: round + 1}
+			return page, nil
+		}
+
+		txs, err := b.rc.GetTransactionsWithResults(ctx, round)
+		if err != nil {
+			return nil, fmt.Errorf("history: failed to fetch transactions for round %d: %w", round, err)
+		}
+
+		for i := len(txs) - 1; i >= 0; i-- {
+			if uint64(i) > uint64(maxIndex) {
+				continue
+			}
+			if !b.involves(txs[i], addr) {
+				continue
+			}
+
+			page.Transactions = append(page.Transactions, &Transaction{
+				Round:  round,
+				Index:  uint32(i),
+				Hash:   hash.NewFromBytes(cbor.Marshal(&txs[i].Tx)),
+				Tx:     txs[i].Tx,
+				Result: txs[i].Result,
+				Events: txs[i].Events,
+			})
+			if uint64(len(page.Transactions)) >= limit {
+				page.Next = &Cursor{Round: round, Index: uint32(i)}
+				return page, nil
+			}
+		}
+
+		if round <= minRound {
+			return page, nil
+		}
+		round--
+		maxIndex = ^uint32(0)
+	}
+}
+
+// involves checks whether the given transaction involves the given address.
+func (b *nodeScanBackend) involves(tx *client.TransactionWithResults, addr types.Address) bool {
+	var body types.Transaction
+	if err := cbor.Unmarshal(tx.Tx.Body, &body); err == nil {
+		for _, si := range body.AuthInfo.SignerInfo {
+			signer, err := si.AddressSpec.Address()
+			if err != nil {
+				continue
+			}
+			if signer.Equal(addr) {
+				return true
+			}
+		}
+	}
+
+	for _, rawEv := range tx.Events {
+		switch rawEv.Module {
+		case accounts.ModuleName:
+			ev, err := b.ac.DecodeEvent(rawEv)
+			if err != nil || ev == nil {
+				continue
+			}
+			if eventInvolves(ev.(*accounts.Event), addr) {
+				return true
+			}
+		case consensusaccounts.ModuleName:
+			ev, err := b.cac.DecodeEvent(rawEv)
+			if err != nil || ev == nil {
+				continue
+			}
+			if consensusEventInvolves(ev.(*consensusaccounts.Event), addr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func eventInvolves(ev *accounts.Event, addr types.Address) bool {
+	switch {
+	case ev.Transfer != nil:
+		return ev.Transfer.From.Equal(addr) || ev.Transfer.To.Equal(addr)
+	case ev.Burn != nil:
+		return ev.Burn.Owner.Equal(addr)
+	case ev.Mint != nil:
+		return ev.Mint.Owner.Equal(addr)
+	default:
+		return false
+	}
+}
+
+func consensusEventInvolves(ev *consensusaccounts.Event, addr types.Address) bool {
+	switch {
+	case ev.Deposit != nil:
+		return ev.Deposit.Address.Equal(addr)
+	case ev.Withdraw != nil:
+		return ev.Withdraw.Address.Equal(addr)
+	default:
+		return false
+	}
+}
+
+// NewNodeScanBackend creates a history backend that scans the blocks available on the node the
+// runtime client is connected to.
+//
+// Since the node does not maintain an address index, each call scans at most maxScanRounds rounds
+// (or DefaultMaxScanRounds if zero). When the scan budget is exhausted before the page is full, the
+// partial page is returned together with a cursor to continue the scan.
+func NewNodeScanBackend(rc client.RuntimeClient, maxScanRounds uint64) Backend {
+	if maxScanRounds == 0 {
+		maxScanRounds = DefaultMaxScanRounds
+	}
+	return &nodeScanBackend{
+		rc:            rc,
+		ac:            accounts.NewV1(rc),
+		cac:           consensusaccounts.NewV1(rc),
+		maxScanRounds: maxScanRounds,
+	}
+}
@@ -0,0 +1,87 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/consensusaccounts"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func TestEventInvolves(t *testing.T) {
+	require := require.New(t)
+
+	amount := types.NewBaseUnits(*quantity.NewFromUint64(10), types.NativeDenomination)
+	for _, tc := range []struct {
+		ev       *accounts.Event
+		addr     types.Address
+		involved bool
+	}{
+		{&accounts.Event{Transfer: &accounts.TransferEvent{From: sdkTesting.Alice.Address, To: sdkTesting.Bob.Address, Amount: amount}}, sdkTesting.Alice.Address, true},
+		{&accounts.Event{Transfer: &accounts.TransferEvent{From: sdkTesting.Alice.Address, To: sdkTesting.Bob.Address, Amount: amount}}, sdkTesting.Bob.Address, true},
+		{&accounts.Event{Transfer: &accounts.TransferEvent{From: sdkTesting.Alice.Address, To: sdkTesting.Bob.Address, Amount: amount}}, sdkTesting.Charlie.Address, false},
+		{&accounts.Event{Mint: &accounts.MintEvent{Owner: sdkTesting.Charlie.Address, Amount: amount}}, sdkTesting.Charlie.Address, true},
+		{&accounts.Event{Burn: &accounts.BurnEvent{Owner: sdkTesting.Charlie.Address, Amount: amount}}, sdkTesting.Dave.Address, false},
+		{&accounts.Event{}, sdkTesting.Dave.Address, false},
+	} {
+		require.Equal(tc.involved, eventInvolves(tc.ev, tc.addr))
+	}
+}
+
+func TestConsensusEventInvolves(t *testing.T) {
+	require := require.New(t)
+
+	amount := types.NewBaseUnits(*quantity.NewFromUint64(10), types.NativeDenomination)
+	for _, tc := range []struct {
+		ev       *consensusaccounts.Event
+		addr     types.Address
+		involved bool
+	}{
+		{&consensusaccounts.Event{Deposit: &consensusaccounts.DepositEvent{Address: sdkTesting.Alice.Address, Amount: amount}}, sdkTesting.Alice.Address, true},
+		{&consensusaccounts.Event{Deposit: &consensusaccounts.DepositEvent{Address: sdkTesting.Alice.Address, Amount: amount}}, sdkTesting.Bob.Address, false},
+		{&consensusaccounts.Event{Withdraw: &consensusaccounts.WithdrawEvent{Address: sdkTesting.Charlie.Address, Amount: amount}}, sdkTesting.Charlie.Address, true},
+		{&consensusaccounts.Event{}, sdkTesting.Dave.Address, false},
+	} {
+		require.Equal(tc.involved, consensusEventInvolves(tc.ev, tc.addr))
+	}
+}
+
+func TestInvolvesEvents(t *testing.T) {
+	require := require.New(t)
+
+	b := NewNodeScanBackend(nil, 0).(*nodeScanBackend)
+	amount := types.NewBaseUnits(*quantity.NewFromUint64(10), types.NativeDenomination)
+	tx := &client.TransactionWithResults{
+		Events: []*types.Event{
+			{
+				Module: accounts.ModuleName,
+				Code:   accounts.MintEventCode,
+				Value:  cbor.Marshal(&accounts.MintEvent{Owner: sdkTesting.Alice.Address, Amount: amount}),
+			},
+			{
+				Module: consensusaccounts.ModuleName,
+				Code:   consensusaccounts.WithdrawEventCode,
+				Value:  cbor.Marshal(&consensusaccounts.WithdrawEvent{Address: sdkTesting.Bob.Address, Amount: amount}),
+			},
+		},
+	}
+	require.True(b.involves(tx, sdkTesting.Alice.Address), "accounts events should be considered")
+	require.True(b.involves(tx, sdkTesting.Bob.Address), "consensus accounts events should be considered")
+	require.False(b.involves(tx, sdkTesting.Charlie.Address))
+}
+
+func TestPagerLimit(t *testing.T) {
+	require := require.New(t)
+
+	var pager *Pager
+	require.EqualValues(DefaultLimit, pager.GetLimit())
+	require.EqualValues(DefaultLimit, (&Pager{}).GetLimit())
+	require.EqualValues(5, (&Pager{Limit: 5}).GetLimit())
+}
@@ -0,0 +1,105 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+)
+
+// DefaultBackfillWorkers is the default number of rounds fetched concurrently by Backfill.
+const DefaultBackfillWorkers = 8
+
+// backfillResult is the result of fetching a round.
+type backfillResult struct {
+	data *RoundData
+	err  error
+}
+
+// Backfill fetches the data of all rounds from the first to the last round, inclusive, and calls
+// fn with the data of each round in round order.
+//
+// Rounds are fetched concurrently by the given number of workers (DefaultBackfillWorkers if zero),
+// which hides the latency of the node when catching up. At most twice as many rounds as there
+// are workers are fetched ahead of the round being delivered, which bounds the memory used when
+// fn is slower than fetching.
+//
+// Backfill stops at the first error returned by fetching a round or by fn.
+func Backfill(ctx context.Context, rc client.RuntimeClient, first, last uint64, workers int, fn func(*RoundData) error) error {
+	if first > last {
+		return nil
+	}
+	if workers <= 0 {
+		workers = DefaultBackfillWorkers
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type job struct {
+		round    uint64
+		resultCh chan<- *backfillResult
+	}
+	jobCh := make(chan *job)
+	// The result channels of dispatched rounds in round order. The capacity bounds how far fetching
+	// runs ahead of delivery.
+	orderCh := make(chan chan *backfillResult, 2*workers)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for j := range jobCh {
+				data, err := FetchRound(ctx, rc, j.round)
+				j.resultCh <- &backfillResult{data: data, err: err}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobCh)
+		defer close(orderCh)
+		for round := first; ; round++ {
+			resultCh := make(chan *backfillResult, 1)
+			select {
+			case orderCh <- resultCh:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobCh <- &job{round: round, resultCh: resultCh}:
+			case <-ctx.Done():
+				return
+			}
+			if round == last {
+				return
+			}
+		}
+	}()
+
+	for resultCh := range orderCh {
+		var result *backfillResult
+		select {
+		case result = <-resultCh:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if result.err != nil {
+			return result.err
+		}
+		if err := fn(result.data); err != nil {
+			return err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// indexRange indexes the given range of rounds, fetching rounds concurrently.
+func (ix *Indexer) indexRange(ctx context.Context, first, last uint64) error {
+	return Backfill(ctx, ix.rc, first, last, ix.workers, func(data *RoundData) error {
+		if err := ix.storage.StoreRound(ctx, data); err != nil {
+			return fmt.Errorf("indexer: failed to store round %d: %w", data.Block.Round, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,277 @@
+// Package indexer implements an indexer of runtime blocks.
+//
+// The indexer tails the blocks of a runtime, decodes their transactions and events using the
+// module registry and persists them in a pluggable storage. The storage can then be queried by
+// round, transaction hash or involved address, the latter also via the history.Backend interface.
+//
+// When catching up, rounds are fetched concurrently and stored in order (see Backfill).
+//
+// Storage is provided in memory (NewMemoryStorage) and in SQL databases (NewSQLStorage), with
+// PostgreSQL and SQLite supported.
+package indexer
+
+import (
+	"context"
+	"crypto/sha512"
+	"fmt"
+	gohash "hash"
+	"sync"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// Indexer indexes the blocks of a runtime.
+type Indexer struct {
+	rc      client.RuntimeClient
+	storage Storage
+
+	startRound uint64
+	workers    int
+}
+
+// Run indexes all rounds following the last indexed round, and then the rounds of new blocks as
+// they are finalized, until the context is canceled or an error occurs.
+func (ix *Indexer) Run(ctx context.Context) error {
+	blkCh, blkSub, err := ix.rc.WatchBlocks(ctx)
+	if err != nil {
+		return fmt.Errorf("indexer: failed to watch blocks: %w", err)
+	}
+	defer blkSub.Close()
+
+	latest, err := ix.rc.GetBlock(ctx, client.RoundLatest)
+	if err != nil {
+		return fmt.Errorf("indexer: failed to fetch latest block: %w", err)
+	}
+	if err = ix.indexUpTo(ctx, latest.Header.Round); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case blk, ok := <-blkCh:
+			if !ok {
+				return fmt.Errorf("indexer: block watcher closed")
+			}
+			if err = ix.indexUpTo(ctx, blk.Block.Header.Round); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// indexUpTo indexes all rounds following the last indexed round up to the given round.
+func (ix *Indexer) indexUpTo(ctx context.Context, round uint64) error {
+	next, err := ix.nextRound(ctx)
+	if err != nil {
+		return err
+	}
+	return ix.indexRange(ctx, next, round)
+}
+
+// nextRound returns the next round to be indexed.
+func (ix *Indexer) nextRound(ctx context.Context) (uint64, error) {
+	last, ok, err := ix.storage.LastRound(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if ok {
+		return last + 1, nil
+	}
+
+	genesis, err := ix.rc.GetGenesisBlock(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("indexer: failed to fetch genesis block: %w", err)
+	}
+	if genesis.Header.Round > ix.startRound {
+		return genesis.Header.Round, nil
+	}
+	return ix.startRound, nil
+}
+
+// SetWorkers sets the number of rounds fetched concurrently when catching up (see Backfill). It
+// must be called before Run.
+func (ix *Indexer) SetWorkers(workers int) {
+	ix.workers = workers
+}
+
+// IndexRound fetches the data of the given round from the node and stores it.
+//
+// Rounds must be indexed in order, which Run takes care of.
+func (ix *Indexer) IndexRound(ctx context.Context, round uint64) error {
+	data, err := FetchRound(ctx, ix.rc, round)
+	if err != nil {
+		return err
+	}
+	if err = ix.storage.StoreRound(ctx, data); err != nil {
+		return fmt.Errorf("indexer: failed to store round %d: %w", round, err)
+	}
+	return nil
+}
+
+// FetchRound fetches the data of the given round from the node and decodes it for indexing.
+func FetchRound(ctx context.Context, rc client.RuntimeClient, round uint64) (*RoundData, error) {
+	blk, err := rc.GetBlock(ctx, round)
+	if err != nil {
+		return nil, fmt.Errorf("indexer: failed to fetch block %d: %w", round, err)
+	}
+	txs, err := rc.GetTransactionsWithResults(ctx, round)
+	if err != nil {
+		return nil, fmt.Errorf("indexer: failed to fetch transactions of round %d: %w", round, err)
+	}
+	rawEvs, err := rc.GetEventsRaw(ctx, round)
+	if err != nil {
+		return nil, fmt.Errorf("indexer: failed to fetch events of round %d: %w", round, err)
+	}
+
+	data := &RoundData{
+		Block: &Block{
+			Round:           round,
+			Hash:            blk.Header.EncodedHash(),
+			Timestamp:       uint64(blk.Header.Timestamp),
+			NumTransactions: uint32(len(txs)),
+		},
+	}
+
+	// Round events include the events of transactions, which are attributed to the first
+	// transaction that emitted an identical event.
+	kb := keyBufferPool.Get().(*keyBuffer)
+	defer keyBufferPool.Put(kb)
+
+	var txEvents map[string][]uint32
+	if len(txs) > 0 {
+		data.Transactions = make([]*Transaction, 0, len(txs))
+		txEvents = make(map[string][]uint32)
+	}
+	for i, tx := range txs {
+		data.Transactions = append(data.Transactions, newTransaction(round, uint32(i), tx))
+		for _, ev := range tx.Events {
+			key := string(kb.eventKey(ev))
+			txEvents[key] = append(txEvents[key], uint32(i))
+		}
+	}
+	if len(rawEvs) > 0 {
+		data.Events = make([]*Event, 0, len(rawEvs))
+	}
+	evs := make([]Event, len(rawEvs))
+	txIndexes := make([]uint32, len(rawEvs))
+	for i, rawEv := range rawEvs {
+		ev := &evs[i]
+		*ev = Event{
+			Round:  round,
+			Index:  uint32(i),
+			Module: rawEv.Module,
+			Code:   rawEv.Code,
+			Value:  rawEv.Value,
+		}
+		// Looking up a key converted from bytes does not allocate.
+		key := kb.eventKey(rawEv)
+		if idxs := txEvents[string(key)]; len(idxs) > 0 {
+			txIndexes[i] = idxs[0]
+			ev.TxIndex = &txIndexes[i]
+			txEvents[string(key)] = idxs[1:]
+		}
+		data.Events = append(data.Events, ev)
+	}
+	return data, nil
+}
+
+func newTransaction(round uint64, index uint32, txr *client.TransactionWithResults) *Transaction {
+	tx := &Transaction{
+		Round:  round,
+		Index:  index,
+		Hash:   txHash(&txr.Tx),
+		Tx:     txr.Tx,
+		Result: txr.Result,
+		Events: txr.Events,
+	}
+
+	var addrs []types.Address
+	// Transactions that cannot be decoded are still indexed, just without any details.
+	body, _, decoded, _ := registry.DecodeTransaction(&txr.Tx)
+	if body != nil {
+		tx.Method = body.Call.Method
+		for _, si := range body.AuthInfo.SignerInfo {
+			if addr, err := si.AddressSpec.Address(); err == nil {
+				addrs = registry.AppendAddress(addrs, addr)
+			}
+		}
+	}
+	for _, addr := range registry.Addresses(decoded) {
+		addrs = registry.AppendAddress(addrs, addr)
+	}
+	for _, rawEv := range txr.Events {
+		if ev, err := registry.DecodeEvent(rawEv); err == nil {
+			for _, addr := range registry.Addresses(ev) {
+				addrs = registry.AppendAddress(addrs, addr)
+			}
+		}
+	}
+
+	tx.Addresses = addrs
+	sortAddresses(tx.Addresses)
+	return tx
+}
+
+// hasherPool is a pool of hashers for transaction hashes.
+var hasherPool = sync.Pool{
+	New: func() interface{} {
+		return sha512.New512_256()
+	},
+}
+
+// txHash returns the hash of the given transaction, encoding it directly into the hasher instead
+// of into an intermediate buffer.
+func txHash(utx *types.UnverifiedTransaction) (h hash.Hash) {
+	hasher := hasherPool.Get().(gohash.Hash)
+	defer hasherPool.Put(hasher)
+
+	hasher.Reset()
+	if err := cbor.NewEncoder(hasher).Encode(utx); err != nil {
+		// Writing into a hasher cannot fail.
+		panic(err)
+	}
+	copy(h[:], hasher.Sum(nil))
+	return h
+}
+
+// keyBuffer is a reusable buffer for building event keys.
+type keyBuffer struct {
+	buf []byte
+}
+
+var keyBufferPool = sync.Pool{
+	New: func() interface{} {
+		return &keyBuffer{}
+	},
+}
+
+// eventKey returns a key identifying events with the same module, code and value. The key is only
+// valid until the next call.
+func (kb *keyBuffer) eventKey(ev *types.Event) []byte {
+	kb.buf = append(kb.buf[:0], ev.Module...)
+	kb.buf = append(kb.buf, 0)
+	kb.buf = append(kb.buf, byte(ev.Code>>24), byte(ev.Code>>16), byte(ev.Code>>8), byte(ev.Code))
+	kb.buf = append(kb.buf, ev.Value...)
+	return kb.buf
+}
+
+// New creates a new indexer of the runtime of the given client, storing indexed data in the given
+// storage.
+//
+// In case the storage is empty, indexing starts at the given round or at the genesis round if it
+// is later.
+func New(rc client.RuntimeClient, storage Storage, startRound uint64) *Indexer {
+	return &Indexer{
+		rc:         rc,
+		storage:    storage,
+		startRound: startRound,
+		workers:    DefaultBackfillWorkers,
+	}
+}
@@ -0,0 +1,209 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/history"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/fakeruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func nativeUnits(amount uint64) types.BaseUnits {
+	return types.NewBaseUnits(*quantity.NewFromUint64(amount), types.NativeDenomination)
+}
+
+func transfer(ctx context.Context, t testing.TB, rt client.RuntimeClient, to types.Address, nonce uint64) *client.TransactionMeta {
+	tb := accounts.NewV1(rt).Transfer(to, nativeUnits(10))
+	tb.AppendAuthSignature(sdkTesting.Alice.SigSpec, nonce)
+	require.NoError(t, tb.AppendSign(ctx, sdkTesting.Alice.Signer), "AppendSign")
+	meta, err := tb.SubmitTxMeta(ctx, nil)
+	require.NoError(t, err, "SubmitTxMeta")
+	return meta
+}
+
+func waitForRound(t *testing.T, storage Storage, round uint64) {
+	require.Eventually(t, func() bool {
+		last, ok, err := storage.LastRound(context.Background())
+		return err == nil && ok && last >= round
+	}, 5*time.Second, 10*time.Millisecond, "round %d should be indexed", round)
+}
+
+func TestIndexer(t *testing.T) {
+	require := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rt := fakeruntime.New(fakeruntime.Config{})
+	rt.SetBalance(sdkTesting.Alice.Address, nativeUnits(100))
+	first := transfer(ctx, t, rt, sdkTesting.Bob.Address, 0)
+
+	storage := NewMemoryStorage()
+	errCh := make(chan error, 1)
+	go func() { errCh <- New(rt, storage, 0).Run(ctx) }()
+
+	// Rounds finalized before the indexer started should be caught up, new ones followed.
+	waitForRound(t, storage, first.Round)
+	second := transfer(ctx, t, rt, sdkTesting.Charlie.Address, 1)
+	third := transfer(ctx, t, rt, sdkTesting.Bob.Address, 2)
+	waitForRound(t, storage, third.Round)
+
+	blk, err := storage.Block(ctx, second.Round)
+	require.NoError(err, "Block")
+	require.EqualValues(1, blk.NumTransactions)
+	_, err = storage.Block(ctx, third.Round+1)
+	require.ErrorIs(err, ErrNotFound)
+
+	txs, err := storage.Transactions(ctx, second.Round)
+	require.NoError(err, "Transactions")
+	require.Len(txs, 1)
+	tx := txs[0]
+	require.Equal("accounts.Transfer", tx.Method)
+	require.ElementsMatch([]types.Address{sdkTesting.Alice.Address, sdkTesting.Charlie.Address}, tx.Addresses)
+	require.Len(tx.Events, 1)
+
+	byHash, err := storage.Transaction(ctx, tx.Hash)
+	require.NoError(err, "Transaction")
+	require.Equal(tx, byHash)
+
+	evs, err := storage.Events(ctx, second.Round)
+	require.NoError(err, "Events")
+	require.Len(evs, 1)
+	require.NotNil(evs[0].TxIndex, "event should be attributed to the transaction")
+	require.EqualValues(0, *evs[0].TxIndex)
+	require.Equal(tx.Events[0], evs[0].Raw())
+
+	// Address history, most recent first.
+	page, err := storage.TransactionsForAddress(ctx, sdkTesting.Bob.Address, &history.Pager{Limit: 1})
+	require.NoError(err, "TransactionsForAddress")
+	require.Len(page.Transactions, 1)
+	require.Equal(third.Round, page.Transactions[0].Round)
+	require.NotNil(page.Next)
+	page, err = storage.TransactionsForAddress(ctx, sdkTesting.Bob.Address, &history.Pager{Limit: 1, Cursor: page.Next})
+	require.NoError(err, "TransactionsForAddress")
+	require.Len(page.Transactions, 1)
+	require.Equal(first.Round, page.Transactions[0].Round)
+	require.Nil(page.Next)
+
+	cancel()
+	require.ErrorIs(<-errCh, context.Canceled)
+}
+
+// slowClient is a runtime client whose block fetches of earlier rounds take longer, so that
+// concurrently fetched rounds complete out of order.
+type slowClient struct {
+	client.RuntimeClient
+
+	failRound uint64
+}
+
+func (c *slowClient) GetBlock(ctx context.Context, round uint64) (*block.Block, error) {
+	if round == c.failRound {
+		return nil, fmt.Errorf("round %d unavailable", round)
+	}
+	time.Sleep(time.Duration(round%4) * 5 * time.Millisecond)
+	return c.RuntimeClient.GetBlock(ctx, round)
+}
+
+func TestBackfill(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rt := fakeruntime.New(fakeruntime.Config{})
+	rt.SetBalance(sdkTesting.Alice.Address, nativeUnits(1000))
+	var rounds []uint64
+	for nonce := uint64(0); nonce < 20; nonce++ {
+		rounds = append(rounds, transfer(ctx, t, rt, sdkTesting.Bob.Address, nonce).Round)
+	}
+	first, last := rounds[0], rounds[len(rounds)-1]
+
+	var delivered []uint64
+	err := Backfill(ctx, &slowClient{RuntimeClient: rt}, first, last, 4, func(data *RoundData) error {
+		delivered = append(delivered, data.Block.Round)
+		return nil
+	})
+	require.NoError(err, "Backfill")
+	require.Len(delivered, int(last-first+1))
+	for i, round := range delivered {
+		require.Equal(first+uint64(i), round, "rounds should be delivered in order")
+	}
+
+	// Rounds following a failed round are not delivered.
+	delivered = nil
+	err = Backfill(ctx, &slowClient{RuntimeClient: rt, failRound: first + 5}, first, last, 4, func(data *RoundData) error {
+		delivered = append(delivered, data.Block.Round)
+		return nil
+	})
+	require.Error(err, "Backfill should fail")
+	require.Len(delivered, 5)
+
+	// Callback errors stop the backfill.
+	err = Backfill(ctx, rt, first, last, 0, func(data *RoundData) error {
+		return ErrNotFound
+	})
+	require.ErrorIs(err, ErrNotFound)
+
+	// The indexer catches up using the backfill.
+	storage := NewMemoryStorage()
+	ix := New(rt, storage, first)
+	ix.SetWorkers(3)
+	require.NoError(ix.indexUpTo(ctx, last), "indexUpTo")
+	lastIndexed, ok, err := storage.LastRound(ctx)
+	require.NoError(err, "LastRound")
+	require.True(ok)
+	require.Equal(last, lastIndexed)
+	txs, err := storage.Transactions(ctx, rounds[10])
+	require.NoError(err, "Transactions")
+	require.Len(txs, 1)
+}
+
+func TestFetchRound(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rt := fakeruntime.New(fakeruntime.Config{})
+	rt.SetBalance(sdkTesting.Alice.Address, nativeUnits(100))
+	meta := transfer(ctx, t, rt, sdkTesting.Bob.Address, 0)
+
+	data, err := FetchRound(ctx, rt, meta.Round)
+	require.NoError(err, "FetchRound")
+	require.Len(data.Transactions, 1)
+	tx := data.Transactions[0]
+	require.Equal(hash.NewFromBytes(cbor.Marshal(&tx.Tx)), tx.Hash, "transaction hash")
+	require.Len(data.Events, 1)
+	require.NotNil(data.Events[0].TxIndex)
+}
+
+func BenchmarkFetchRound(b *testing.B) {
+	ctx := context.Background()
+	rt := fakeruntime.New(fakeruntime.Config{})
+	rt.SetBalance(sdkTesting.Alice.Address, nativeUnits(100))
+	meta := transfer(ctx, b, rt, sdkTesting.Bob.Address, 0)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := FetchRound(ctx, rt, meta.Round); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestDialectRebind(t *testing.T) {
+	require := require.New(t)
+
+	query := "SELECT round FROM blocks WHERE round > ? AND round < ?"
+	require.Equal("SELECT round FROM blocks WHERE round > $1 AND round < $2", DialectPostgres.Rebind(query))
+	require.Equal(query, DialectSQLite.Rebind(query))
+}
@@ -0,0 +1,145 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/history"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+type txRef struct {
+	round uint64
+	index uint32
+}
+
+type memoryStorage struct {
+	sync.RWMutex
+
+	rounds    map[uint64]*RoundData
+	lastRound *uint64
+	txs       map[hash.Hash]txRef
+	addresses map[types.Address][]txRef
+}
+
+// Implements Reader.
+func (s *memoryStorage) LastRound(ctx context.Context) (uint64, bool, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	if s.lastRound == nil {
+		return 0, false, nil
+	}
+	return *s.lastRound, true, nil
+}
+
+// Implements Reader.
+func (s *memoryStorage) Block(ctx context.Context, round uint64) (*Block, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	data, ok := s.rounds[round]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return data.Block, nil
+}
+
+// Implements Reader.
+func (s *memoryStorage) Transaction(ctx context.Context, txHash hash.Hash) (*Transaction, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	ref, ok := s.txs[txHash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return s.rounds[ref.round].Transactions[ref.index], nil
+}
+
+// Implements Reader.
+func (s *memoryStorage) Transactions(ctx context.Context, round uint64) ([]*Transaction, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	data, ok := s.rounds[round]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return data.Transactions, nil
+}
+
+// Implements Reader.
+func (s *memoryStorage) Events(ctx context.Context, round uint64) ([]*Event, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	data, ok := s.rounds[round]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return data.Events, nil
+}
+
+// Implements history.Backend.
+func (s *memoryStorage) TransactionsForAddress(ctx context.Context, addr types.Address, pager *history.Pager) (*history.Page, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	refs := s.addresses[addr]
+	limit := pager.GetLimit()
+	page := &history.Page{}
+	for i := len(refs) - 1; i >= 0; i-- {
+		ref := refs[i]
+		if pager != nil && pager.Cursor != nil && !before(ref, pager.Cursor) {
+			continue
+		}
+		if uint64(len(page.Transactions)) >= limit {
+			last := page.Transactions[len(page.Transactions)-1]
+			page.Next = &history.Cursor{Round: last.Round, Index: last.Index}
+			break
+		}
+		tx := s.rounds[ref.round].Transactions[ref.index]
+		page.Transactions = append(page.Transactions, tx.historyTransaction())
+	}
+	return page, nil
+}
+
+// before checks whether the transaction is strictly before the cursor position.
+func before(ref txRef, cursor *history.Cursor) bool {
+	return ref.round < cursor.Round || (ref.round == cursor.Round && ref.index < cursor.Index)
+}
+
+// Implements Storage.
+func (s *memoryStorage) StoreRound(ctx context.Context, data *RoundData) error {
+	s.Lock()
+	defer s.Unlock()
+
+	round := data.Block.Round
+	if s.lastRound != nil && round <= *s.lastRound {
+		return fmt.Errorf("indexer: round %d already indexed", round)
+	}
+
+	s.rounds[round] = data
+	s.lastRound = &round
+	for _, tx := range data.Transactions {
+		ref := txRef{round: round, index: tx.Index}
+		s.txs[tx.Hash] = ref
+		for _, addr := range tx.Addresses {
+			s.addresses[addr] = append(s.addresses[addr], ref)
+		}
+	}
+	return nil
+}
+
+// NewMemoryStorage creates a new storage that keeps all indexed data in memory.
+func NewMemoryStorage() Storage {
+	return &memoryStorage{
+		rounds:    make(map[uint64]*RoundData),
+		txs:       make(map[hash.Hash]txRef),
+		addresses: make(map[types.Address][]txRef),
+	}
+}
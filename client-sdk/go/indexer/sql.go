@@ -0,0 +1,417 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/history"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// Dialect is the SQL dialect of a database.
+type Dialect uint8
+
+const (
+	// DialectPostgres is the dialect of PostgreSQL.
+	DialectPostgres Dialect = 0
+	// DialectSQLite is the dialect of SQLite.
+	DialectSQLite Dialect = 1
+)
+
+// String returns a string representation of the dialect.
+func (d Dialect) String() string {
+	switch d {
+	case DialectPostgres:
+		return "postgres"
+	case DialectSQLite:
+		return "sqlite"
+	default:
+		return "[unknown dialect]"
+	}
+}
+
+// bytesType returns the column type of binary data.
+func (d Dialect) bytesType() string {
+	if d == DialectPostgres {
+		return "BYTEA"
+	}
+	return "BLOB"
+}
+
+// Rebind rewrites the ? placeholders of a query to the placeholders of the dialect.
+func (d Dialect) Rebind(query string) string {
+	if d != DialectPostgres {
+		return query
+	}
+	var (
+		b strings.Builder
+		n int
+	)
+	for _, c := range query {
+		if c != '?' {
+			b.WriteRune(c)
+			continue
+		}
+		n++
+		b.WriteString("$" + strconv.Itoa(n))
+	}
+	return b.String()
+}
+
+const sqlSchema = `
+CREATE TABLE IF NOT EXISTS blocks (
+	round BIGINT PRIMARY KEY,
+	hash TEXT NOT NULL,
+	timestamp BIGINT NOT NULL,
+	num_transactions INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS transactions (
+	round BIGINT NOT NULL,
+	idx INTEGER NOT NULL,
+	hash TEXT NOT NULL,
+	method TEXT NOT NULL,
+	tx %[1]s NOT NULL,
+	result %[1]s NOT NULL,
+	PRIMARY KEY (round, idx)
+);
+CREATE INDEX IF NOT EXISTS transactions_hash ON transactions (hash);
+CREATE TABLE IF NOT EXISTS events (
+	round BIGINT NOT NULL,
+	idx INTEGER NOT NULL,
+	tx_index INTEGER,
+	module TEXT NOT NULL,
+	code BIGINT NOT NULL,
+	value %[1]s NOT NULL,
+	PRIMARY KEY (round, idx)
+);
+CREATE TABLE IF NOT EXISTS address_transactions (
+	address TEXT NOT NULL,
+	round BIGINT NOT NULL,
+	idx INTEGER NOT NULL,
+	PRIMARY KEY (address, round, idx)
+);
+`
+
+// execer is implemented by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+type sqlStorage struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+func (s *sqlStorage) exec(ctx context.Context, e execer, query string, args ...interface{}) error {
+	_, err := e.ExecContext(ctx, s.dialect.Rebind(query), args...)
+	return err
+}
+
+func (s *sqlStorage) query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, s.dialect.Rebind(query), args...)
+}
+
+// Implements Reader.
+func (s *sqlStorage) LastRound(ctx context.Context) (uint64, bool, error) {
+	var round sql.NullInt64
+	if err := s.db.QueryRowContext(ctx, "SELECT MAX(round) FROM blocks").Scan(&round); err != nil {
+		return 0, false, fmt.Errorf("indexer: failed to query last round: %w", err)
+	}
+	return uint64(round.Int64), round.Valid, nil
+}
+
+// Implements Reader.
+func (s *sqlStorage) Block(ctx context.Context, round uint64) (*Block, error) {
+	var (
+		blk     Block
+		blkHash string
+	)
+	err := s.db.QueryRowContext(ctx,
+		s.dialect.Rebind("SELECT round, hash, timestamp, num_transactions FROM blocks WHERE round = ?"),
+		int64(round),
+	).Scan(&blk.Round, &blkHash, &blk.Timestamp, &blk.NumTransactions)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, ErrNotFound
+	case err != nil:
+		return nil, fmt.Errorf("indexer: failed to query block: %w", err)
+	}
+	if err = blk.Hash.UnmarshalHex(blkHash); err != nil {
+		return nil, fmt.Errorf("indexer: malformed block hash: %w", err)
+	}
+	return &blk, nil
+}
+
+// Implements Reader.
+func (s *sqlStorage) Transaction(ctx context.Context, txHash hash.Hash) (*Transaction, error) {
+	txs, err := s.transactions(ctx, "WHERE hash = ?", txHash.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if len(txs) == 0 {
+		return nil, ErrNotFound
+	}
+	return txs[0], nil
+}
+
+// Implements Reader.
+func (s *sqlStorage) Transactions(ctx context.Context, round uint64) ([]*Transaction, error) {
+	if _, err := s.Block(ctx, round); err != nil {
+		return nil, err
+	}
+	return s.transactions(ctx, "WHERE round = ? ORDER BY idx", int64(round))
+}
+
+// transactions returns the transactions selected by the given condition, together with their
+// events and addresses.
+func (s *sqlStorage) transactions(ctx context.Context, cond string, args ...interface{}) ([]*Transaction, error) {
+	rows, err := s.query(ctx, "SELECT round, idx, hash, method, tx, result FROM transactions "+cond, args...)
+	if err != nil {
+		return nil, fmt.Errorf("indexer: failed to query transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var txs []*Transaction
+	for rows.Next() {
+		var (
+			tx             Transaction
+			txHash         string
+			rawTx, rawRslt []byte
+		)
+		if err = rows.Scan(&tx.Round, &tx.Index, &txHash, &tx.Method, &rawTx, &rawRslt); err != nil {
+			return nil, fmt.Errorf("indexer: failed to scan transaction: %w", err)
+		}
+		if err = tx.Hash.UnmarshalHex(txHash); err != nil {
+			return nil, fmt.Errorf("indexer: malformed transaction hash: %w", err)
+		}
+		if err = cbor.Unmarshal(rawTx, &tx.Tx); err != nil {
+			return nil, fmt.Errorf("indexer: malformed transaction: %w", err)
+		}
+		if err = cbor.Unmarshal(rawRslt, &tx.Result); err != nil {
+			return nil, fmt.Errorf("indexer: malformed transaction result: %w", err)
+		}
+		txs = append(txs, &tx)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("indexer: failed to query transactions: %w", err)
+	}
+	// Release the connection before issuing further queries.
+	rows.Close()
+
+	for _, tx := range txs {
+		if err = s.loadTransactionDetails(ctx, tx); err != nil {
+			return nil, err
+		}
+	}
+	return txs, nil
+}
+
+// loadTransactionDetails loads the events and addresses of a transaction.
+func (s *sqlStorage) loadTransactionDetails(ctx context.Context, tx *Transaction) error {
+	evs, err := s.events(ctx, "WHERE round = ? AND tx_index = ? ORDER BY idx", int64(tx.Round), tx.Index)
+	if err != nil {
+		return err
+	}
+	for _, ev := range evs {
+		tx.Events = append(tx.Events, ev.Raw())
+	}
+
+	rows, err := s.query(ctx, "SELECT address FROM address_transactions WHERE round = ? AND idx = ?", int64(tx.Round), tx.Index)
+	if err != nil {
+		return fmt.Errorf("indexer: failed to query addresses: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			text string
+			addr types.Address
+		)
+		if err = rows.Scan(&text); err != nil {
+			return fmt.Errorf("indexer: failed to scan address: %w", err)
+		}
+		if err = addr.UnmarshalText([]byte(text)); err != nil {
+			return fmt.Errorf("indexer: malformed address: %w", err)
+		}
+		tx.Addresses = append(tx.Addresses, addr)
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("indexer: failed to query addresses: %w", err)
+	}
+	sortAddresses(tx.Addresses)
+	return nil
+}
+
+// Implements Reader.
+func (s *sqlStorage) Events(ctx context.Context, round uint64) ([]*Event, error) {
+	if _, err := s.Block(ctx, round); err != nil {
+		return nil, err
+	}
+	return s.events(ctx, "WHERE round = ? ORDER BY idx", int64(round))
+}
+
+func (s *sqlStorage) events(ctx context.Context, cond string, args ...interface{}) ([]*Event, error) {
+	rows, err := s.query(ctx, "SELECT round, idx, tx_index, module, code, value FROM events "+cond, args...)
+	if err != nil {
+		return nil, fmt.Errorf("indexer: failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	var evs []*Event
+	for rows.Next() {
+		var (
+			ev      Event
+			txIndex sql.NullInt64
+		)
+		if err = rows.Scan(&ev.Round, &ev.Index, &txIndex, &ev.Module, &ev.Code, &ev.Value); err != nil {
+			return nil, fmt.Errorf("indexer: failed to scan event: %w", err)
+		}
+		if txIndex.Valid {
+			idx := uint32(txIndex.Int64)
+			ev.TxIndex = &idx
+		}
+		evs = append(evs, &ev)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("indexer: failed to query events: %w", err)
+	}
+	return evs, nil
+}
+
+// Implements history.Backend.
+func (s *sqlStorage) TransactionsForAddress(ctx context.Context, addr types.Address, pager *history.Pager) (*history.Page, error) {
+	limit := pager.GetLimit()
+	cond := "WHERE address = ?"
+	args := []interface{}{addr.String()}
+	if pager != nil && pager.Cursor != nil {
+		cond += " AND (round < ? OR (round = ? AND idx < ?))"
+		args = append(args, int64(pager.Cursor.Round), int64(pager.Cursor.Round), pager.Cursor.Index)
+	}
+	// Fetch one more reference than needed to know whether there is a next page.
+	args = append(args, int64(limit+1))
+
+	rows, err := s.query(ctx, "SELECT round, idx FROM address_transactions "+cond+" ORDER BY round DESC, idx DESC LIMIT ?", args...)
+	if err != nil {
+		return nil, fmt.Errorf("indexer: failed to query address transactions: %w", err)
+	}
+	var refs []txRef
+	for rows.Next() {
+		var ref txRef
+		if err = rows.Scan(&ref.round, &ref.index); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("indexer: failed to scan address transaction: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("indexer: failed to query address transactions: %w", err)
+	}
+
+	page := &history.Page{}
+	for i, ref := range refs {
+		if uint64(i) >= limit {
+			last := page.Transactions[len(page.Transactions)-1]
+			page.Next = &history.Cursor{Round: last.Round, Index: last.Index}
+			break
+		}
+		txs, txErr := s.transactions(ctx, "WHERE round = ? AND idx = ?", int64(ref.round), ref.index)
+		if txErr != nil {
+			return nil, txErr
+		}
+		if len(txs) == 0 {
+			return nil, fmt.Errorf("indexer: missing transaction %d in round %d", ref.index, ref.round)
+		}
+		page.Transactions = append(page.Transactions, txs[0].historyTransaction())
+	}
+	return page, nil
+}
+
+// Implements Storage.
+func (s *sqlStorage) StoreRound(ctx context.Context, data *RoundData) (err error) {
+	dbTx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("indexer: failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = dbTx.Rollback()
+		}
+	}()
+
+	blk := data.Block
+	round := int64(blk.Round)
+	if err = s.exec(ctx, dbTx, "INSERT INTO blocks (round, hash, timestamp, num_transactions) VALUES (?, ?, ?, ?)",
+		round, blk.Hash.Hex(), int64(blk.Timestamp), blk.NumTransactions,
+	); err != nil {
+		return fmt.Errorf("indexer: failed to store block: %w", err)
+	}
+	for _, tx := range data.Transactions {
+		if err = s.exec(ctx, dbTx, "INSERT INTO transactions (round, idx, hash, method, tx, result) VALUES (?, ?, ?, ?, ?, ?)",
+			round, tx.Index, tx.Hash.Hex(), tx.Method, cbor.Marshal(&tx.Tx), cbor.Marshal(&tx.Result),
+		); err != nil {
+			return fmt.Errorf("indexer: failed to store transaction: %w", err)
+		}
+		for _, addr := range tx.Addresses {
+			if err = s.exec(ctx, dbTx, "INSERT INTO address_transactions (address, round, idx) VALUES (?, ?, ?)",
+				addr.String(), round, tx.Index,
+			); err != nil {
+				return fmt.Errorf("indexer: failed to store address: %w", err)
+			}
+		}
+	}
+	for _, ev := range data.Events {
+		var txIndex sql.NullInt64
+		if ev.TxIndex != nil {
+			txIndex = sql.NullInt64{Int64: int64(*ev.TxIndex), Valid: true}
+		}
+		if err = s.exec(ctx, dbTx, "INSERT INTO events (round, idx, tx_index, module, code, value) VALUES (?, ?, ?, ?, ?, ?)",
+			round, ev.Index, txIndex, ev.Module, int64(ev.Code), ev.Value,
+		); err != nil {
+			return fmt.Errorf("indexer: failed to store event: %w", err)
+		}
+	}
+
+	if err = dbTx.Commit(); err != nil {
+		return fmt.Errorf("indexer: failed to commit round %d: %w", blk.Round, err)
+	}
+	return nil
+}
+
+// NewSQLStorage creates a new storage backed by the given SQL database, creating the schema if
+// it does not exist yet.
+//
+// The caller is responsible for opening the database with a driver for the given dialect, e.g.
+// github.com/lib/pq for PostgreSQL or github.com/mattn/go-sqlite3 for SQLite.
+func NewSQLStorage(ctx context.Context, db *sql.DB, dialect Dialect) (Storage, error) {
+	switch dialect {
+	case DialectPostgres, DialectSQLite:
+	default:
+		return nil, fmt.Errorf("indexer: unsupported SQL dialect %d", dialect)
+	}
+
+	s := &sqlStorage{db: db, dialect: dialect}
+	// Not all drivers support multiple statements per call, so the schema is created one
+	// statement at a time.
+	for _, stmt := range strings.Split(fmt.Sprintf(sqlSchema, dialect.bytesType()), ";") {
+		if stmt = strings.TrimSpace(stmt); stmt == "" {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("indexer: failed to create schema: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// sortAddresses sorts addresses by their Bech32 encoding.
+func sortAddresses(addrs []types.Address) {
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].String() < addrs[j].String() })
+}
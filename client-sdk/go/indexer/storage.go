@@ -0,0 +1,122 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/history"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// ErrNotFound is the error returned when a block or transaction has not been indexed.
+var ErrNotFound = errors.New("indexer: not found")
+
+// Block is an indexed block.
+type Block struct {
+	// Round is the round of the block.
+	Round uint64 `json:"round"`
+	// Hash is the hash of the block header.
+	Hash hash.Hash `json:"hash"`
+	// Timestamp is the block timestamp in seconds since the Unix epoch.
+	Timestamp uint64 `json:"timestamp"`
+	// NumTransactions is the number of transactions in the block.
+	NumTransactions uint32 `json:"num_transactions"`
+}
+
+// Transaction is an indexed transaction.
+type Transaction struct {
+	// Round is the round in which the transaction was executed.
+	Round uint64 `json:"round"`
+	// Index is the index of the transaction within the round.
+	Index uint32 `json:"index"`
+	// Hash is the transaction hash.
+	Hash hash.Hash `json:"hash"`
+	// Method is the called method. It is empty in case the transaction could not be decoded, e.g.
+	// because its call is encrypted.
+	Method string `json:"method,omitempty"`
+
+	// Tx is the (signed) transaction.
+	Tx types.UnverifiedTransaction `json:"tx"`
+	// Result is the transaction call result.
+	Result types.CallResult `json:"result"`
+	// Events are the events emitted by the transaction.
+	Events []*types.Event `json:"events,omitempty"`
+
+	// Addresses are the addresses involved in the transaction: its signers and the addresses in
+	// its call body and decoded events.
+	Addresses []types.Address `json:"addresses,omitempty"`
+}
+
+// historyTransaction converts the transaction into a history transaction.
+func (t *Transaction) historyTransaction() *history.Transaction {
+	return &history.Transaction{
+		Round:  t.Round,
+		Index:  t.Index,
+		Hash:   t.Hash,
+		Tx:     t.Tx,
+		Result: t.Result,
+		Events: t.Events,
+	}
+}
+
+// Event is an indexed event.
+type Event struct {
+	// Round is the round in which the event was emitted.
+	Round uint64 `json:"round"`
+	// Index is the index of the event within the round.
+	Index uint32 `json:"index"`
+	// TxIndex is the index of the transaction that emitted the event or nil in case the event was
+	// emitted outside of transactions, e.g. at the end of the block.
+	TxIndex *uint32 `json:"tx_index,omitempty"`
+
+	Module string `json:"module"`
+	Code   uint32 `json:"code"`
+	Value  []byte `json:"value"`
+}
+
+// Raw returns the raw runtime event.
+func (e *Event) Raw() *types.Event {
+	return &types.Event{Module: e.Module, Code: e.Code, Value: e.Value}
+}
+
+// RoundData is everything indexed for a single round.
+type RoundData struct {
+	Block        *Block
+	Transactions []*Transaction
+	Events       []*Event
+}
+
+// Reader is the query interface of the indexer storage.
+//
+// Readers also implement history.Backend so that they can serve account transaction history
+// without scanning blocks.
+type Reader interface {
+	history.Backend
+
+	// LastRound returns the last indexed round. The boolean is false in case nothing has been
+	// indexed yet.
+	LastRound(ctx context.Context) (uint64, bool, error)
+
+	// Block returns the indexed block of the given round.
+	Block(ctx context.Context, round uint64) (*Block, error)
+
+	// Transaction returns the indexed transaction with the given hash.
+	Transaction(ctx context.Context, txHash hash.Hash) (*Transaction, error)
+
+	// Transactions returns the indexed transactions of the given round.
+	Transactions(ctx context.Context, round uint64) ([]*Transaction, error)
+
+	// Events returns the indexed events of the given round, including those emitted by
+	// transactions.
+	Events(ctx context.Context, round uint64) ([]*Event, error)
+}
+
+// Storage is the storage of indexed data.
+type Storage interface {
+	Reader
+
+	// StoreRound atomically stores the data of a round. Rounds are stored in order.
+	StoreRound(ctx context.Context, data *RoundData) error
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
 )
 
@@ -15,9 +16,11 @@ const (
 	methodTransfer = "accounts.Transfer"
 
 	// Queries.
-	methodNonce     = "accounts.Nonce"
-	methodBalances  = "accounts.Balances"
-	methodAddresses = "accounts.Addresses"
+	methodParameters    = "accounts.Parameters"
+	methodNonce         = "accounts.Nonce"
+	methodBalances      = "accounts.Balances"
+	methodAddresses     = "accounts.Addresses"
+	methodTotalSupplies = "accounts.TotalSupplies"
 )
 
 // V1 is the v1 accounts module interface.
@@ -27,6 +30,12 @@ type V1 interface {
 	// Transfer generates an accounts.Transfer transaction.
 	Transfer(to types.Address, amount types.BaseUnits) *client.TransactionBuilder
 
+	// TransferWithMemo generates an accounts.Transfer transaction with an attached memo.
+	TransferWithMemo(to types.Address, amount types.BaseUnits, memo []byte) *client.TransactionBuilder
+
+	// Parameters queries the accounts module parameters.
+	Parameters(ctx context.Context, round uint64) (*Parameters, error)
+
 	// Nonce queries the given account's nonce.
 	Nonce(ctx context.Context, round uint64, address types.Address) (uint64, error)
 
@@ -36,6 +45,9 @@ type V1 interface {
 	// Addresses queries all account addresses.
 	Addresses(ctx context.Context, round uint64, denomination types.Denomination) (Addresses, error)
 
+	// TotalSupplies queries the total supply of all denominations.
+	TotalSupplies(ctx context.Context, round uint64) (TotalSupplies, error)
+
 	// GetEvents returns all account events emitted in a given block.
 	GetEvents(ctx context.Context, round uint64) ([]*Event, error)
 }
@@ -52,6 +64,25 @@ func (a *v1) Transfer(to types.Address, amount types.BaseUnits) *client.Transact
 	})
 }
 
+// Implements V1.
+func (a *v1) Parameters(ctx context.Context, round uint64) (*Parameters, error) {
+	var params Parameters
+	err := a.rc.Query(ctx, round, methodParameters, nil, &params)
+	if err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+// Implements V1.
+func (a *v1) TransferWithMemo(to types.Address, amount types.BaseUnits, memo []byte) *client.TransactionBuilder {
+	return client.NewTransactionBuilder(a.rc, methodTransfer, &Transfer{
+		To:     to,
+		Amount: amount,
+		Memo:   memo,
+	})
+}
+
 // Implements V1.
 func (a *v1) Nonce(ctx context.Context, round uint64, address types.Address) (uint64, error) {
 	var nonce uint64
@@ -82,6 +113,16 @@ func (a *v1) Addresses(ctx context.Context, round uint64, denomination types.Den
 	return addresses, nil
 }
 
+// Implements V1.
+func (a *v1) TotalSupplies(ctx context.Context, round uint64) (TotalSupplies, error) {
+	var supplies TotalSupplies
+	err := a.rc.Query(ctx, round, methodTotalSupplies, nil, &supplies)
+	if err != nil {
+		return nil, err
+	}
+	return supplies, nil
+}
+
 // Implements V1.
 func (a *v1) GetEvents(ctx context.Context, round uint64) ([]*Event, error) {
 	rawEvs, err := a.rc.GetEventsRaw(ctx, round)
@@ -148,3 +189,23 @@ func NewV1(rc client.RuntimeClient) V1 {
 func NewTransferTx(fee *types.Fee, body *Transfer) *types.Transaction {
 	return types.NewTransaction(fee, methodTransfer, body)
 }
+
+func init() {
+	registry.Register(&registry.Module{
+		Name: ModuleName,
+		Methods: []*registry.Method{
+			{Name: methodTransfer, Kind: registry.MethodKindCall, Body: &Transfer{}, Result: nil},
+			{Name: methodParameters, Kind: registry.MethodKindQuery, Body: nil, Result: &Parameters{}},
+			{Name: methodNonce, Kind: registry.MethodKindQuery, Body: &NonceQuery{}, Result: new(uint64)},
+			{Name: methodBalances, Kind: registry.MethodKindQuery, Body: &BalancesQuery{}, Result: &AccountBalances{}},
+			{Name: methodAddresses, Kind: registry.MethodKindQuery, Body: &AddressesQuery{}, Result: &Addresses{}},
+			{Name: methodTotalSupplies, Kind: registry.MethodKindQuery, Body: nil, Result: &TotalSupplies{}},
+		},
+		Events: []*registry.Event{
+			{Code: TransferEventCode, Name: "Transfer", Value: &TransferEvent{}},
+			{Code: BurnEventCode, Name: "Burn", Value: &BurnEvent{}},
+			{Code: MintEventCode, Name: "Mint", Value: &MintEvent{}},
+		},
+		EventDecoder: NewV1(nil),
+	})
+}
@@ -0,0 +1,151 @@
+package accounts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// BatchTransferResult is the outcome of a single transfer in a batch.
+type BatchTransferResult struct {
+	// Transfer is the transfer this result is for.
+	Transfer Transfer
+	// Nonce is the nonce of the transaction carrying the transfer.
+	Nonce uint64
+	// Meta is the transaction metadata. It is nil in case the transaction was not submitted.
+	Meta *client.TransactionMeta
+	// Error is the error encountered while processing the transfer, if any.
+	Error error
+}
+
+// IsSuccess returns true if the transfer was executed successfully.
+func (r *BatchTransferResult) IsSuccess() bool {
+	return r.Meta != nil && r.Meta.CheckTxError == nil && r.Error == nil
+}
+
+// BatchTransfer is a helper for paying many recipients from a single account.
+//
+// Since each accounts.Transfer call has a single recipient, the batch is executed as a sequence
+// of transactions with consecutive nonces, all signed by the same signer.
+type BatchTransfer struct {
+	rc        client.RuntimeClient
+	transfers []Transfer
+
+	feeAmount types.BaseUnits
+	feeGas    uint64
+}
+
+// NewBatchTransfer creates a new empty batch transfer.
+func NewBatchTransfer(rc client.RuntimeClient) *BatchTransfer {
+	return &BatchTransfer{rc: rc}
+}
+
+// Add appends a transfer of the given amount to the given recipient.
+func (bt *BatchTransfer) Add(to types.Address, amount types.BaseUnits) *BatchTransfer {
+	bt.transfers = append(bt.transfers, Transfer{To: to, Amount: amount})
+	return bt
+}
+
+// SetFeeAmount configures the fee amount paid for each transaction in the batch.
+func (bt *BatchTransfer) SetFeeAmount(amount types.BaseUnits) *BatchTransfer {
+	bt.feeAmount = amount
+	return bt
+}
+
+// SetFeeGas configures the maximum gas amount for each transaction in the batch.
+func (bt *BatchTransfer) SetFeeGas(gas uint64) *BatchTransfer {
+	bt.feeGas = gas
+	return bt
+}
+
+// Transfers returns the transfers in the batch.
+func (bt *BatchTransfer) Transfers() []Transfer {
+	return bt.transfers
+}
+
+// Build creates signed transactions for all transfers in the batch, starting at the given nonce.
+// It fails with ErrEmptyBatch if the batch has no transfers.
+func (bt *BatchTransfer) Build(
+	ctx context.Context,
+	signer signature.Signer,
+	spec types.SignatureAddressSpec,
+	nonce uint64,
+) ([]*client.TransactionBuilder, error) {
+	if len(bt.transfers) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	txbs := make([]*client.TransactionBuilder, 0, len(bt.transfers))
+	for i, xfer := range bt.transfers {
+		txb := client.NewTransactionBuilder(bt.rc, methodTransfer, &Transfer{
+			To:     xfer.To,
+			Amount: xfer.Amount,
+		}).
+			SetFeeAmount(bt.feeAmount).
+			SetFeeGas(bt.feeGas).
+			AppendAuthSignature(spec, nonce+uint64(i))
+		if err := txb.AppendSign(ctx, signer); err != nil {
+			return nil, fmt.Errorf("failed to sign transfer %d: %w", i, err)
+		}
+		txbs = append(txbs, txb)
+	}
+	return txbs, nil
+}
+
+// Submit signs and submits all transfers in the batch and returns a result for each transfer,
+// in the order they were added.
+//
+// The signer's current nonce is queried from the runtime. Transactions are submitted one after
+// another as each one must be executed before the next nonce is accepted. In case a transaction
+// fails the transaction checks, the remaining transfers are not submitted and their results carry
+// an error. A transfer that fails during execution does not prevent the following transfers from
+// being submitted. It fails with ErrEmptyBatch if the batch has no transfers.
+func (bt *BatchTransfer) Submit(
+	ctx context.Context,
+	signer signature.Signer,
+	spec types.SignatureAddressSpec,
+) ([]*BatchTransferResult, error) {
+	if len(bt.transfers) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	nonce, err := NewV1(bt.rc).Nonce(ctx, client.RoundLatest, types.NewAddress(spec))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query nonce: %w", err)
+	}
+	txbs, err := bt.Build(ctx, signer, spec, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*BatchTransferResult, len(bt.transfers))
+	var abortErr error
+	for i, txb := range txbs {
+		results[i] = &BatchTransferResult{
+			Transfer: bt.transfers[i],
+			Nonce:    nonce + uint64(i),
+		}
+		if abortErr != nil {
+			results[i].Error = abortErr
+			continue
+		}
+
+		meta, err := txb.SubmitTxMeta(ctx, nil)
+		results[i].Meta = meta
+		results[i].Error = err
+		switch {
+		case meta == nil:
+			// Submission failed so we don't know whether the nonce was consumed.
+			abortErr = fmt.Errorf("previous transfer failed to submit: %w", err)
+		case meta.CheckTxError != nil:
+			results[i].Error = fmt.Errorf("transaction check failed: module %s code %d: %s",
+				meta.CheckTxError.Module,
+				meta.CheckTxError.Code,
+				meta.CheckTxError.Message,
+			)
+			abortErr = fmt.Errorf("previous transfer failed transaction checks")
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,119 @@
+package accounts_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/fakeruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+var errSign = errors.New("sign failed")
+
+// failingSigner is a signer that fails once it has signed the given number of messages.
+type failingSigner struct {
+	signature.Signer
+
+	signs int
+}
+
+func (s *failingSigner) ContextSign(context, message []byte) ([]byte, error) {
+	if s.signs == 0 {
+		return nil, errSign
+	}
+	s.signs--
+	return s.Signer.ContextSign(context, message)
+}
+
+func nativeUnits(amount uint64) types.BaseUnits {
+	return types.NewBaseUnits(*quantity.NewFromUint64(amount), types.NativeDenomination)
+}
+
+func TestBatchTransferBuild(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rt := fakeruntime.New(fakeruntime.Config{})
+	batch := accounts.NewBatchTransfer(rt)
+
+	_, err := batch.Build(ctx, sdkTesting.Alice.Signer, sdkTesting.Alice.SigSpec, 0)
+	require.ErrorIs(err, accounts.ErrEmptyBatch)
+
+	batch.
+		Add(sdkTesting.Bob.Address, nativeUnits(1)).
+		Add(sdkTesting.Charlie.Address, nativeUnits(2)).
+		Add(sdkTesting.Dave.Address, nativeUnits(3)).
+		SetFeeAmount(nativeUnits(5)).
+		SetFeeGas(1000)
+	txbs, err := batch.Build(ctx, sdkTesting.Alice.Signer, sdkTesting.Alice.SigSpec, 7)
+	require.NoError(err, "Build")
+	require.Len(txbs, 3)
+	for i, txb := range txbs {
+		tx := txb.GetTransaction()
+		require.Len(tx.AuthInfo.SignerInfo, 1)
+		require.EqualValues(7+i, tx.AuthInfo.SignerInfo[0].Nonce, "transactions should have consecutive nonces")
+		require.EqualValues(1000, tx.AuthInfo.Fee.Gas)
+		require.Equal(nativeUnits(5), tx.AuthInfo.Fee.Amount)
+	}
+
+	// Signing failures abort the build.
+	signer := &failingSigner{Signer: sdkTesting.Alice.Signer, signs: 1}
+	txbs, err = batch.Build(ctx, signer, sdkTesting.Alice.SigSpec, 0)
+	require.ErrorIs(err, errSign)
+	require.Nil(txbs)
+	require.Zero(signer.signs, "signing should stop at the first failure")
+}
+
+func TestBatchTransferSubmit(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rt := fakeruntime.New(fakeruntime.Config{})
+	batch := accounts.NewBatchTransfer(rt)
+
+	_, err := batch.Submit(ctx, sdkTesting.Alice.Signer, sdkTesting.Alice.SigSpec)
+	require.ErrorIs(err, accounts.ErrEmptyBatch)
+
+	// Each transaction pays a fee of 2, so the balance of 10 covers the fees of the first three
+	// transactions and the transfers of 1 and 3, after which the fourth transaction fails the
+	// transaction checks.
+	rt.SetBalance(sdkTesting.Alice.Address, nativeUnits(10))
+	batch.
+		Add(sdkTesting.Bob.Address, nativeUnits(1)).
+		Add(sdkTesting.Charlie.Address, nativeUnits(100)).
+		Add(sdkTesting.Dave.Address, nativeUnits(3)).
+		Add(sdkTesting.Bob.Address, nativeUnits(1)).
+		Add(sdkTesting.Charlie.Address, nativeUnits(1)).
+		SetFeeAmount(nativeUnits(2))
+	results, err := batch.Submit(ctx, sdkTesting.Alice.Signer, sdkTesting.Alice.SigSpec)
+	require.NoError(err, "Submit")
+	require.Len(results, 5)
+	for i, result := range results {
+		require.Equal(batch.Transfers()[i], result.Transfer)
+		require.EqualValues(i, result.Nonce, "transfers should have consecutive nonces")
+	}
+
+	require.True(results[0].IsSuccess(), "first transfer should succeed")
+	require.False(results[1].IsSuccess(), "transfer exceeding the balance should fail")
+	require.NotNil(results[1].Meta, "failed transfer should have been executed")
+	require.True(results[2].IsSuccess(), "execution failures should not abort the batch")
+	require.False(results[3].IsSuccess(), "transfer without fee balance should fail")
+	require.NotNil(results[3].Meta.CheckTxError)
+	require.Error(results[3].Error)
+	require.Nil(results[4].Meta, "transfers after a check failure should not be submitted")
+	require.Error(results[4].Error)
+
+	ac := accounts.NewV1(rt)
+	nonce, err := ac.Nonce(ctx, client.RoundLatest, sdkTesting.Alice.Address)
+	require.NoError(err, "Nonce")
+	require.EqualValues(3, nonce)
+}
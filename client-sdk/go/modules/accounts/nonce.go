@@ -0,0 +1,171 @@
+package accounts
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// NonceManager hands out sequential nonces of accounts to concurrent transaction senders, so that
+// transactions of the same account can be signed and submitted in parallel without querying the
+// nonce of the account for each transaction.
+//
+// The nonce of an account is queried from the runtime on first use and whenever a gap may have
+// appeared after a failure. Senders must commit or release each handed out nonce once the outcome
+// of the transaction using it is known.
+type NonceManager struct {
+	l sync.Mutex
+
+	ac       V1
+	accounts map[types.Address]*accountNonces
+}
+
+// accountNonces are the nonces of an account.
+type accountNonces struct {
+	l sync.Mutex
+
+	// next is the next nonce to hand out.
+	next uint64
+	// leases are the handed out nonces whose transactions have not been committed or released.
+	leases map[uint64]*NonceLease
+	// synced is true iff next is known to be consistent with the runtime state.
+	synced bool
+}
+
+// NonceLease is a nonce handed out by a NonceManager.
+type NonceLease struct {
+	// Nonce is the nonce to use for the transaction.
+	Nonce uint64
+
+	an *accountNonces
+}
+
+// Commit reports that the transaction using the nonce was executed, successfully or not, which
+// used up the nonce.
+func (nl *NonceLease) Commit() {
+	an := nl.an
+	an.l.Lock()
+	defer an.l.Unlock()
+
+	if an.leases[nl.Nonce] == nl {
+		delete(an.leases, nl.Nonce)
+	}
+}
+
+// Release reports that the transaction using the nonce was not executed, e.g. because it failed
+// the transaction checks or its outcome is unknown. This leaves a gap that makes transactions with
+// later nonces fail, so the nonces from the released one on are handed out again, unless the
+// runtime state shows that the released nonce has been used after all. Later nonces handed out
+// before the release are invalidated and releasing them has no effect.
+func (nl *NonceLease) Release() {
+	an := nl.an
+	an.l.Lock()
+	defer an.l.Unlock()
+
+	if an.leases[nl.Nonce] != nl {
+		// The nonce has already been handed out again after an earlier gap.
+		return
+	}
+	for nonce := range an.leases {
+		if nonce >= nl.Nonce {
+			delete(an.leases, nonce)
+		}
+	}
+	an.next = nl.Nonce
+	an.synced = false
+}
+
+// Next hands out the next nonce of the given account.
+func (m *NonceManager) Next(ctx context.Context, address types.Address) (*NonceLease, error) {
+	an := m.account(address)
+	an.l.Lock()
+	defer an.l.Unlock()
+
+	if !an.synced {
+		if err := m.sync(ctx, address, an); err != nil {
+			return nil, err
+		}
+	}
+
+	nl := &NonceLease{
+		Nonce: an.next,
+		an:    an,
+	}
+	an.leases[nl.Nonce] = nl
+	an.next++
+	return nl, nil
+}
+
+// Pending returns the number of handed out nonces of the given account that have not been
+// committed or released yet.
+func (m *NonceManager) Pending(address types.Address) int {
+	an := m.account(address)
+	an.l.Lock()
+	defer an.l.Unlock()
+
+	return len(an.leases)
+}
+
+// Resync queries the nonce of the given account from the runtime, e.g. after the account was used
+// by another sender. All nonces handed out before are invalidated.
+func (m *NonceManager) Resync(ctx context.Context, address types.Address) error {
+	an := m.account(address)
+	an.l.Lock()
+	defer an.l.Unlock()
+
+	an.next = 0
+	an.leases = make(map[uint64]*NonceLease)
+	return m.sync(ctx, address, an)
+}
+
+// Forget drops the nonces of the given account, e.g. when it is no longer used.
+func (m *NonceManager) Forget(address types.Address) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	delete(m.accounts, address)
+}
+
+func (m *NonceManager) account(address types.Address) *accountNonces {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	an, ok := m.accounts[address]
+	if !ok {
+		an = &accountNonces{leases: make(map[uint64]*NonceLease)}
+		m.accounts[address] = an
+	}
+	return an
+}
+
+// sync brings the next nonce of the account in line with the runtime state. Nonces below the
+// nonce of the account have been used, while a released nonce above it may still be reused, as
+// transactions with lower nonces can still be pending.
+func (m *NonceManager) sync(ctx context.Context, address types.Address, an *accountNonces) error {
+	nonce, err := m.ac.Nonce(ctx, client.RoundLatest, address)
+	if err != nil {
+		return fmt.Errorf("accounts: failed to query nonce of %s: %w", address, err)
+	}
+	if nonce > an.next {
+		an.next = nonce
+	}
+	for leased := range an.leases {
+		if leased < nonce {
+			// The transaction has been executed, even if it was not committed yet.
+			delete(an.leases, leased)
+		}
+	}
+	an.synced = true
+	return nil
+}
+
+// NewNonceManager creates a new nonce manager querying account nonces from the given runtime.
+func NewNonceManager(rc client.RuntimeClient) *NonceManager {
+	return &NonceManager{
+		ac:       NewV1(rc),
+		accounts: make(map[types.Address]*accountNonces),
+	}
+}
@@ -0,0 +1,104 @@
+package accounts_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/fakeruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func TestNonceManager(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rt := fakeruntime.New(fakeruntime.Config{})
+	rt.SetBalance(sdkTesting.Alice.Address, types.NewBaseUnits(*quantity.NewFromUint64(1000), types.NativeDenomination))
+	ac := accounts.NewV1(rt)
+	nm := accounts.NewNonceManager(rt)
+
+	transfer := func(nl *accounts.NonceLease) error {
+		tb := ac.Transfer(sdkTesting.Bob.Address, types.NewBaseUnits(*quantity.NewFromUint64(1), types.NativeDenomination)).
+			AppendAuthSignature(sdkTesting.Alice.SigSpec, nl.Nonce)
+		if err := tb.AppendSign(ctx, sdkTesting.Alice.Signer); err != nil {
+			return err
+		}
+		meta, err := tb.SubmitTxMeta(ctx, nil)
+		if err != nil || meta.CheckTxError != nil {
+			nl.Release()
+			return err
+		}
+		nl.Commit()
+		return nil
+	}
+
+	// Concurrent senders get distinct sequential nonces.
+	var (
+		l      sync.Mutex
+		wg     sync.WaitGroup
+		leases []*accounts.NonceLease
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nl, err := nm.Next(ctx, sdkTesting.Alice.Address)
+			require.NoError(err, "Next")
+			l.Lock()
+			defer l.Unlock()
+			leases = append(leases, nl)
+		}()
+	}
+	wg.Wait()
+	seen := make(map[uint64]bool)
+	for _, nl := range leases {
+		require.Less(nl.Nonce, uint64(10))
+		require.False(seen[nl.Nonce], "nonces should be distinct")
+		seen[nl.Nonce] = true
+	}
+	require.Equal(10, nm.Pending(sdkTesting.Alice.Address))
+
+	// Releasing a nonce leaves a gap that makes it and the later nonces be handed out again, while
+	// releasing the invalidated later nonces has no effect.
+	for _, nl := range leases {
+		if nl.Nonce < 3 {
+			require.NoError(transfer(nl), "transfer")
+		}
+	}
+	for _, nl := range leases {
+		if nl.Nonce >= 3 {
+			nl.Release()
+		}
+	}
+	require.Zero(nm.Pending(sdkTesting.Alice.Address))
+	nl, err := nm.Next(ctx, sdkTesting.Alice.Address)
+	require.NoError(err, "Next")
+	require.EqualValues(3, nl.Nonce)
+	require.NoError(transfer(nl), "transfer")
+
+	// Nonces used by other senders are detected on the next gap.
+	nl, err = nm.Next(ctx, sdkTesting.Alice.Address)
+	require.NoError(err, "Next")
+	require.EqualValues(4, nl.Nonce)
+	other := accounts.NewNonceManager(rt)
+	otherNl, err := other.Next(ctx, sdkTesting.Alice.Address)
+	require.NoError(err, "Next")
+	require.NoError(transfer(otherNl), "transfer")
+	require.NoError(transfer(nl), "transfer with a used nonce should fail the checks")
+	nl, err = nm.Next(ctx, sdkTesting.Alice.Address)
+	require.NoError(err, "Next")
+	require.EqualValues(5, nl.Nonce)
+	require.NoError(transfer(nl), "transfer")
+
+	require.NoError(nm.Resync(ctx, sdkTesting.Alice.Address), "Resync")
+	nl, err = nm.Next(ctx, sdkTesting.Alice.Address)
+	require.NoError(err, "Next")
+	require.EqualValues(6, nl.Nonce)
+}
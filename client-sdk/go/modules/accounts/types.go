@@ -1,13 +1,86 @@
 package accounts
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
 )
 
+var (
+	// ErrTransfersDisabled is the error returned when transfers are disabled.
+	ErrTransfersDisabled = errors.New("accounts: transfers are disabled")
+	// ErrInsufficientBalance is the error returned when an account does not have enough balance.
+	ErrInsufficientBalance = errors.New("accounts: insufficient balance")
+	// ErrEmptyBatch is the error returned when building or submitting a batch without transfers.
+	ErrEmptyBatch = errors.New("accounts: empty batch")
+)
+
+// GasCosts are the accounts module gas costs.
+type GasCosts struct {
+	TxTransfer uint64 `json:"tx_transfer"`
+}
+
+// MethodCosts returns the gas costs of the accounts module methods, keyed by method name.
+func (gc *GasCosts) MethodCosts() map[string]uint64 {
+	return map[string]uint64{
+		methodTransfer: gc.TxTransfer,
+	}
+}
+
+// Parameters are the parameters for the accounts module.
+type Parameters struct {
+	TransfersDisabled bool     `json:"transfers_disabled"`
+	GasCosts          GasCosts `json:"gas_costs"`
+
+	DebugDisableNonceCheck bool `json:"debug_disable_nonce_check,omitempty"`
+}
+
+// ValidateTransfer checks whether a transfer of the given amount, paying the given fee, would be
+// accepted by the accounts module for an account with the given balances.
+//
+// Both fee and balances are optional. In case balances are not given, only the module parameters
+// are checked.
+func (p *Parameters) ValidateTransfer(amount types.BaseUnits, fee *types.Fee, balances *AccountBalances) error {
+	if p.TransfersDisabled {
+		return ErrTransfersDisabled
+	}
+	if balances == nil {
+		return nil
+	}
+
+	required := amount.Amount.Clone()
+	if fee != nil && fee.Amount.Denomination == amount.Denomination {
+		if err := required.Add(&fee.Amount.Amount); err != nil {
+			return err
+		}
+	}
+	available := balances.Balances[amount.Denomination]
+	if available.Cmp(required) < 0 {
+		return fmt.Errorf("%w: need %s, have %s", ErrInsufficientBalance,
+			types.NewBaseUnits(*required, amount.Denomination),
+			types.NewBaseUnits(available, amount.Denomination),
+		)
+	}
+
+	if fee != nil && fee.Amount.Denomination != amount.Denomination {
+		available = balances.Balances[fee.Amount.Denomination]
+		if available.Cmp(&fee.Amount.Amount) < 0 {
+			return fmt.Errorf("%w: need %s for fees, have %s", ErrInsufficientBalance,
+				fee.Amount,
+				types.NewBaseUnits(available, fee.Amount.Denomination),
+			)
+		}
+	}
+	return nil
+}
+
 // Transfer is the body for the accounts.Transfer call.
 type Transfer struct {
 	To     types.Address   `json:"to"`
 	Amount types.BaseUnits `json:"amount"`
+	// Memo is an optional opaque memo attached to the transfer.
+	Memo []byte `json:"memo,omitempty"`
 }
 
 // NonceQuery are the arguments for the accounts.Nonce query.
@@ -33,9 +106,20 @@ type AddressesQuery struct {
 // Addresses is the response of the accounts.Addresses query.
 type Addresses []types.Address
 
+// TotalSupplies is the response of the accounts.TotalSupplies query.
+type TotalSupplies map[types.Denomination]types.Quantity
+
 // ModuleName is the accounts module name.
 const ModuleName = "accounts"
 
+var (
+	// CommonPoolAddress is the address of the runtime's common pool.
+	CommonPoolAddress = types.NewAddressForModule(ModuleName, []byte("common-pool"))
+	// FeeAccumulatorAddress is the address where transaction fees are accumulated before they
+	// are disbursed at the end of the block.
+	FeeAccumulatorAddress = types.NewAddressForModule(ModuleName, []byte("fee-accumulator"))
+)
+
 const (
 	// TransferEventCode is the event code for the transfer event.
 	TransferEventCode = 1
@@ -50,6 +134,8 @@ type TransferEvent struct {
 	From   types.Address   `json:"from"`
 	To     types.Address   `json:"to"`
 	Amount types.BaseUnits `json:"amount"`
+	// Memo is the memo attached to the transfer, if any.
+	Memo []byte `json:"memo,omitempty"`
 }
 
 // BurnEvent is the burn event.
@@ -0,0 +1,43 @@
+package accounts
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func TestValidateTransfer(t *testing.T) {
+	require := require.New(t)
+
+	other := types.Denomination("OTHER")
+	native := func(v uint64) types.BaseUnits {
+		return types.NewBaseUnits(*quantity.NewFromUint64(v), types.NativeDenomination)
+	}
+	balances := &AccountBalances{
+		Balances: map[types.Denomination]types.Quantity{
+			types.NativeDenomination: *quantity.NewFromUint64(100),
+			other:                    *quantity.NewFromUint64(5),
+		},
+	}
+
+	var params Parameters
+	require.NoError(params.ValidateTransfer(native(100), nil, balances))
+	require.NoError(params.ValidateTransfer(native(90), &types.Fee{Amount: native(10)}, balances))
+	require.NoError(params.ValidateTransfer(native(1000), nil, nil))
+
+	err := params.ValidateTransfer(native(91), &types.Fee{Amount: native(10)}, balances)
+	require.True(errors.Is(err, ErrInsufficientBalance))
+
+	fee := &types.Fee{Amount: types.NewBaseUnits(*quantity.NewFromUint64(6), other)}
+	err = params.ValidateTransfer(native(10), fee, balances)
+	require.True(errors.Is(err, ErrInsufficientBalance))
+
+	params.TransfersDisabled = true
+	err = params.ValidateTransfer(native(1), nil, balances)
+	require.True(errors.Is(err, ErrTransfersDisabled))
+}
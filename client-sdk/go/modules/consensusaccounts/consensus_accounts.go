@@ -2,36 +2,82 @@ package consensusaccounts
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
 	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
 
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
 )
 
 const (
 	// Callable methods.
-	methodDeposit  = "consensus.Deposit"
-	methodWithdraw = "consensus.Withdraw"
+	methodDeposit     = "consensus.Deposit"
+	methodWithdraw    = "consensus.Withdraw"
+	methodTakeReceipt = "consensus.TakeReceipt"
 
 	// Queries.
-	methodBalance = "consensus.Balance"
-	methodAccount = "consensus.Account"
+	methodParameters   = "consensus.Parameters"
+	methodDenomination = "consensus.Denomination"
+	methodBalance      = "consensus.Balance"
+	methodAccount      = "consensus.Account"
+	methodReceipt      = "consensus.Receipt"
 )
 
+// errCodeReceiptNotFound is the module error code returned when a receipt does not exist.
+const errCodeReceiptNotFound = 4
+
 // V1 is the v1 consensus accounts module interface.
 type V1 interface {
+	client.EventDecoder
+
 	// Deposit generates a consensus.Deposit transaction.
 	Deposit(amount types.BaseUnits) *client.TransactionBuilder
 
+	// DepositWithMemo generates a consensus.Deposit transaction with an attached memo.
+	DepositWithMemo(amount types.BaseUnits, memo []byte) *client.TransactionBuilder
+
+	// DepositWithReceipt generates a consensus.Deposit transaction that stores a receipt once the
+	// deposit has been processed.
+	DepositWithReceipt(amount types.BaseUnits) *client.TransactionBuilder
+
 	// Withdraw generates a consensus.Withdraw transaction.
 	Withdraw(amount types.BaseUnits) *client.TransactionBuilder
 
+	// WithdrawWithMemo generates a consensus.Withdraw transaction with an attached memo.
+	WithdrawWithMemo(amount types.BaseUnits, memo []byte) *client.TransactionBuilder
+
+	// WithdrawWithReceipt generates a consensus.Withdraw transaction that stores a receipt once
+	// the withdrawal has been processed.
+	WithdrawWithReceipt(amount types.BaseUnits) *client.TransactionBuilder
+
+	// TakeReceipt generates a consensus.TakeReceipt transaction, which returns the receipt of a
+	// deposit or withdrawal of the caller with the given identifier and removes it.
+	TakeReceipt(id uint64) *client.TransactionBuilder
+
+	// Parameters queries the consensus accounts module parameters.
+	Parameters(ctx context.Context, round uint64) (*Parameters, error)
+
+	// ConsensusDenomination queries the denomination representing consensus layer tokens in the
+	// runtime.
+	ConsensusDenomination(ctx context.Context, round uint64) (types.Denomination, error)
+
 	// Balance queries the given account's balance of consensus denomination tokens.
 	Balance(ctx context.Context, round uint64, query *BalanceQuery) (*AccountBalance, error)
 
 	// ConsensusAccount queries the given consensus layer account.
 	ConsensusAccount(ctx context.Context, round uint64, query *AccountQuery) (*staking.Account, error)
+
+	// Receipt queries the receipt of a deposit or withdrawal that requested one. The receipt
+	// identifier is the nonce of the transaction that performed the deposit or withdrawal.
+	// Receipts can be queried until they are taken with TakeReceipt.
+	Receipt(ctx context.Context, round uint64, query *ReceiptQuery) (*Receipt, error)
+
+	// GetEvents returns all consensus accounts events emitted in a given block.
+	GetEvents(ctx context.Context, round uint64) ([]*Event, error)
 }
 
 type v1 struct {
@@ -45,6 +91,22 @@ func (a *v1) Deposit(amount types.BaseUnits) *client.TransactionBuilder {
 	})
 }
 
+// Implements V1.
+func (a *v1) DepositWithMemo(amount types.BaseUnits, memo []byte) *client.TransactionBuilder {
+	return client.NewTransactionBuilder(a.rc, methodDeposit, &Deposit{
+		Amount: amount,
+		Memo:   memo,
+	})
+}
+
+// Implements V1.
+func (a *v1) DepositWithReceipt(amount types.BaseUnits) *client.TransactionBuilder {
+	return client.NewTransactionBuilder(a.rc, methodDeposit, &Deposit{
+		Amount:  amount,
+		Receipt: true,
+	})
+}
+
 // Implements V1.
 func (a *v1) Withdraw(amount types.BaseUnits) *client.TransactionBuilder {
 	return client.NewTransactionBuilder(a.rc, methodWithdraw, &Withdraw{
@@ -52,6 +114,49 @@ func (a *v1) Withdraw(amount types.BaseUnits) *client.TransactionBuilder {
 	})
 }
 
+// Implements V1.
+func (a *v1) WithdrawWithMemo(amount types.BaseUnits, memo []byte) *client.TransactionBuilder {
+	return client.NewTransactionBuilder(a.rc, methodWithdraw, &Withdraw{
+		Amount: amount,
+		Memo:   memo,
+	})
+}
+
+// Implements V1.
+func (a *v1) WithdrawWithReceipt(amount types.BaseUnits) *client.TransactionBuilder {
+	return client.NewTransactionBuilder(a.rc, methodWithdraw, &Withdraw{
+		Amount:  amount,
+		Receipt: true,
+	})
+}
+
+// Implements V1.
+func (a *v1) TakeReceipt(id uint64) *client.TransactionBuilder {
+	return client.NewTransactionBuilder(a.rc, methodTakeReceipt, &TakeReceipt{
+		ID: id,
+	})
+}
+
+// Implements V1.
+func (a *v1) Parameters(ctx context.Context, round uint64) (*Parameters, error) {
+	var params Parameters
+	err := a.rc.Query(ctx, round, methodParameters, nil, &params)
+	if err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+// Implements V1.
+func (a *v1) ConsensusDenomination(ctx context.Context, round uint64) (types.Denomination, error) {
+	var denomination types.Denomination
+	err := a.rc.Query(ctx, round, methodDenomination, nil, &denomination)
+	if err != nil {
+		return "", err
+	}
+	return denomination, nil
+}
+
 // Implements V1.
 func (a *v1) Balance(ctx context.Context, round uint64, query *BalanceQuery) (*AccountBalance, error) {
 	var balance AccountBalance
@@ -72,6 +177,72 @@ func (a *v1) ConsensusAccount(ctx context.Context, round uint64, query *AccountQ
 	return &account, nil
 }
 
+// Implements V1.
+func (a *v1) Receipt(ctx context.Context, round uint64, query *ReceiptQuery) (*Receipt, error) {
+	var receipt Receipt
+	err := a.rc.Query(ctx, round, methodReceipt, query, &receipt)
+	if err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+// Implements V1.
+func (a *v1) GetEvents(ctx context.Context, round uint64) ([]*Event, error) {
+	rawEvs, err := a.rc.GetEventsRaw(ctx, round)
+	if err != nil {
+		return nil, err
+	}
+
+	evs := make([]*Event, 0)
+	for _, rawEv := range rawEvs {
+		ev, err := a.DecodeEvent(rawEv)
+		if err != nil {
+			return nil, err
+		}
+		if ev == nil {
+			continue
+		}
+		evs = append(evs, ev.(*Event))
+	}
+
+	return evs, nil
+}
+
+// Implements client.EventDecoder.
+func (a *v1) DecodeEvent(event *types.Event) (client.DecodedEvent, error) {
+	if event.Module != ModuleName {
+		return nil, nil
+	}
+	switch event.Code {
+	case DepositEventCode:
+		var ev *DepositEvent
+		if err := cbor.Unmarshal(event.Value, &ev); err != nil {
+			return nil, fmt.Errorf("decode consensus accounts deposit event value: %w", err)
+		}
+		return &Event{
+			Deposit: ev,
+		}, nil
+	case WithdrawEventCode:
+		var ev *WithdrawEvent
+		if err := cbor.Unmarshal(event.Value, &ev); err != nil {
+			return nil, fmt.Errorf("decode consensus accounts withdraw event value: %w", err)
+		}
+		return &Event{
+			Withdraw: ev,
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid consensus accounts event code: %v", event.Code)
+	}
+}
+
+// IsReceiptNotFound checks whether the given error indicates that the queried receipt does not
+// exist (yet).
+func IsReceiptNotFound(err error) bool {
+	module, code := errors.Code(err)
+	return module == ModuleName && code == errCodeReceiptNotFound
+}
+
 // NewV1 generates a V1 client helper for the consensus accounts module.
 func NewV1(rc client.RuntimeClient) V1 {
 	return &v1{rc: rc}
@@ -86,3 +257,24 @@ func NewDepositTx(fee *types.Fee, body *Deposit) *types.Transaction {
 func NewWithdrawTx(fee *types.Fee, body *Withdraw) *types.Transaction {
 	return types.NewTransaction(fee, methodWithdraw, body)
 }
+
+func init() {
+	registry.Register(&registry.Module{
+		Name: ModuleName,
+		Methods: []*registry.Method{
+			{Name: methodDeposit, Kind: registry.MethodKindCall, Body: &Deposit{}, Result: nil},
+			{Name: methodWithdraw, Kind: registry.MethodKindCall, Body: &Withdraw{}, Result: nil},
+			{Name: methodTakeReceipt, Kind: registry.MethodKindCall, Body: &TakeReceipt{}, Result: &Receipt{}},
+			{Name: methodParameters, Kind: registry.MethodKindQuery, Body: nil, Result: &Parameters{}},
+			{Name: methodDenomination, Kind: registry.MethodKindQuery, Body: nil, Result: new(types.Denomination)},
+			{Name: methodBalance, Kind: registry.MethodKindQuery, Body: &BalanceQuery{}, Result: &AccountBalance{}},
+			{Name: methodAccount, Kind: registry.MethodKindQuery, Body: &AccountQuery{}, Result: &staking.Account{}},
+			{Name: methodReceipt, Kind: registry.MethodKindQuery, Body: &ReceiptQuery{}, Result: &Receipt{}},
+		},
+		Events: []*registry.Event{
+			{Code: DepositEventCode, Name: "Deposit", Value: &DepositEvent{}},
+			{Code: WithdrawEventCode, Name: "Withdraw", Value: &WithdrawEvent{}},
+		},
+		EventDecoder: NewV1(nil),
+	})
+}
@@ -2,14 +2,46 @@ package consensusaccounts
 
 import "github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
 
+// GasCosts are the consensus accounts module gas costs.
+type GasCosts struct {
+	TxDeposit     uint64 `json:"tx_deposit"`
+	TxWithdraw    uint64 `json:"tx_withdraw"`
+	TxTakeReceipt uint64 `json:"tx_take_receipt,omitempty"`
+}
+
+// MethodCosts returns the gas costs of the consensus accounts module methods, keyed by method
+// name.
+func (gc *GasCosts) MethodCosts() map[string]uint64 {
+	return map[string]uint64{
+		methodDeposit:     gc.TxDeposit,
+		methodWithdraw:    gc.TxWithdraw,
+		methodTakeReceipt: gc.TxTakeReceipt,
+	}
+}
+
+// Parameters are the parameters for the consensus accounts module.
+type Parameters struct {
+	GasCosts GasCosts `json:"gas_costs"`
+}
+
 // Deposit are the arguments for consensus.Deposit method.
 type Deposit struct {
 	Amount types.BaseUnits `json:"amount"`
+	// Memo is an optional opaque memo attached to the deposit.
+	Memo []byte `json:"memo,omitempty"`
+	// Receipt specifies whether a receipt should be stored once the deposit is processed. The
+	// receipt identifier is the nonce of the depositing transaction.
+	Receipt bool `json:"receipt,omitempty"`
 }
 
-// Withdraw are the arguments for consensus.Deposit method.
+// Withdraw are the arguments for consensus.Withdraw method.
 type Withdraw struct {
 	Amount types.BaseUnits `json:"amount"`
+	// Memo is an optional opaque memo attached to the withdrawal.
+	Memo []byte `json:"memo,omitempty"`
+	// Receipt specifies whether a receipt should be stored once the withdrawal is processed. The
+	// receipt identifier is the nonce of the withdrawing transaction.
+	Receipt bool `json:"receipt,omitempty"`
 }
 
 // BalanceQuery are the arguments for consensus.Balance method.
@@ -26,3 +58,80 @@ type AccountBalance struct {
 type AccountQuery struct {
 	Address types.Address `json:"address"`
 }
+
+// TakeReceipt are the arguments for consensus.TakeReceipt method.
+type TakeReceipt struct {
+	// ID is the identifier of the receipt of the caller, i.e. the nonce of the transaction that
+	// requested the receipt.
+	ID uint64 `json:"id"`
+}
+
+// ReceiptQuery are the arguments for consensus.Receipt method.
+type ReceiptQuery struct {
+	Address types.Address `json:"address"`
+	ID      uint64        `json:"id"`
+}
+
+// Receipt is the receipt of a processed deposit or withdrawal.
+type Receipt struct {
+	Amount types.BaseUnits `json:"amount"`
+	// Module is the module that emitted the consensus layer error, if any.
+	Module string `json:"module,omitempty"`
+	// Code is the consensus layer error code, zero in case of success.
+	Code uint32 `json:"code,omitempty"`
+}
+
+// IsSuccess returns true if the consensus layer successfully processed the transfer.
+func (r *Receipt) IsSuccess() bool {
+	return r.Code == 0
+}
+
+const (
+	// DepositEventCode is the event code for the deposit event.
+	DepositEventCode = 1
+	// WithdrawEventCode is the event code for the withdraw event.
+	WithdrawEventCode = 2
+)
+
+// DepositEvent is the event emitted once the consensus layer processed a deposit.
+type DepositEvent struct {
+	Address types.Address   `json:"address"`
+	Amount  types.BaseUnits `json:"amount"`
+	// Memo is the memo attached to the deposit.
+	Memo []byte `json:"memo,omitempty"`
+	// Module is the module that emitted the consensus layer error, if any.
+	Module string `json:"module,omitempty"`
+	// Code is the consensus layer error code, zero in case of success.
+	Code uint32 `json:"code,omitempty"`
+}
+
+// IsSuccess returns true if the consensus layer successfully processed the deposit.
+func (e *DepositEvent) IsSuccess() bool {
+	return e.Code == 0
+}
+
+// WithdrawEvent is the event emitted once the consensus layer processed a withdrawal.
+type WithdrawEvent struct {
+	Address types.Address   `json:"address"`
+	Amount  types.BaseUnits `json:"amount"`
+	// Memo is the memo attached to the withdrawal.
+	Memo []byte `json:"memo,omitempty"`
+	// Module is the module that emitted the consensus layer error, if any.
+	Module string `json:"module,omitempty"`
+	// Code is the consensus layer error code, zero in case of success.
+	Code uint32 `json:"code,omitempty"`
+}
+
+// IsSuccess returns true if the consensus layer successfully processed the withdrawal.
+func (e *WithdrawEvent) IsSuccess() bool {
+	return e.Code == 0
+}
+
+// Event is a consensus accounts event.
+type Event struct {
+	Deposit  *DepositEvent  `json:"deposit,omitempty"`
+	Withdraw *WithdrawEvent `json:"withdraw,omitempty"`
+}
+
+// ModuleName is the consensus accounts module name.
+const ModuleName = "consensus_accounts"
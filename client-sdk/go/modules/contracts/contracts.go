@@ -4,13 +4,16 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/golang/snappy"
 
 	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
 
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
 )
 
@@ -75,12 +78,24 @@ type V1 interface {
 	// Code queries the given code information.
 	Code(ctx context.Context, round uint64, id CodeID) (*Code, error)
 
+	// Codes queries information about up to limit consecutive uploaded codes, starting with the
+	// given code identifier.
+	Codes(ctx context.Context, round uint64, start CodeID, limit uint64) ([]*Code, error)
+
 	// Instance queries the given instance information.
 	Instance(ctx context.Context, round uint64, id InstanceID) (*Instance, error)
 
+	// Instances queries information about up to limit consecutive instances, starting with the
+	// given instance identifier.
+	Instances(ctx context.Context, round uint64, start InstanceID, limit uint64) ([]*Instance, error)
+
 	// InstanceStorage queries the given instance's storage.
 	InstanceStorage(ctx context.Context, round uint64, id InstanceID, key []byte) (*InstanceStorageQueryResult, error)
 
+	// InstanceStorageValue queries the given instance's storage and decodes the stored value
+	// using CBOR into the given value. It returns false in case the key does not exist.
+	InstanceStorageValue(ctx context.Context, round uint64, id InstanceID, key []byte, value interface{}) (bool, error)
+
 	// PublicKey queries the given instance's public key.
 	PublicKey(ctx context.Context, round uint64, id InstanceID, kind PublicKeyKind) (*PublicKeyQueryResult, error)
 
@@ -176,6 +191,23 @@ func (a *v1) Code(ctx context.Context, round uint64, id CodeID) (*Code, error) {
 	return &code, nil
 }
 
+// Implements V1.
+func (a *v1) Codes(ctx context.Context, round uint64, start CodeID, limit uint64) ([]*Code, error) {
+	codes := make([]*Code, 0)
+	for id := start; uint64(len(codes)) < limit; id++ {
+		code, err := a.Code(ctx, round, id)
+		switch {
+		case err == nil:
+		case isNotFound(err, ErrCodeCodeNotFound):
+			return codes, nil
+		default:
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
 // Implements V1.
 func (a *v1) Instance(ctx context.Context, round uint64, id InstanceID) (*Instance, error) {
 	var instance Instance
@@ -186,6 +218,23 @@ func (a *v1) Instance(ctx context.Context, round uint64, id InstanceID) (*Instan
 	return &instance, nil
 }
 
+// Implements V1.
+func (a *v1) Instances(ctx context.Context, round uint64, start InstanceID, limit uint64) ([]*Instance, error) {
+	instances := make([]*Instance, 0)
+	for id := start; uint64(len(instances)) < limit; id++ {
+		instance, err := a.Instance(ctx, round, id)
+		switch {
+		case err == nil:
+		case isNotFound(err, ErrCodeInstanceNotFound):
+			return instances, nil
+		default:
+			return nil, err
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
 // Implements V1.
 func (a *v1) InstanceStorage(ctx context.Context, round uint64, id InstanceID, key []byte) (*InstanceStorageQueryResult, error) {
 	var rsp InstanceStorageQueryResult
@@ -196,6 +245,21 @@ func (a *v1) InstanceStorage(ctx context.Context, round uint64, id InstanceID, k
 	return &rsp, nil
 }
 
+// Implements V1.
+func (a *v1) InstanceStorageValue(ctx context.Context, round uint64, id InstanceID, key []byte, value interface{}) (bool, error) {
+	rsp, err := a.InstanceStorage(ctx, round, id, key)
+	if err != nil {
+		return false, err
+	}
+	if rsp.Value == nil {
+		return false, nil
+	}
+	if err = cbor.Unmarshal(rsp.Value, value); err != nil {
+		return false, fmt.Errorf("failed to unmarshal instance storage value: %w", err)
+	}
+	return true, nil
+}
+
 // Implements V1.
 func (a *v1) PublicKey(ctx context.Context, round uint64, id InstanceID, kind PublicKeyKind) (*PublicKeyQueryResult, error) {
 	var pk PublicKeyQueryResult
@@ -259,14 +323,65 @@ func (a *v1) DecodeEvent(event *types.Event) (client.DecodedEvent, error) {
 	if event.Module != ModuleName && !strings.HasPrefix(event.Module, ModuleName+".") {
 		return nil, nil
 	}
-	var ev *Event
+	var ev Event
 	if err := cbor.Unmarshal(event.Value, &ev); err != nil {
 		return nil, fmt.Errorf("decode contract event value: %w", err)
 	}
-	return ev, nil
+	// Contract events are emitted under "contracts.<code-id>".
+	if codeID, err := strconv.ParseUint(strings.TrimPrefix(event.Module, ModuleName+"."), 10, 64); err == nil {
+		ev.CodeID = CodeID(codeID)
+	}
+	ev.Code = event.Code
+	return &ev, nil
+}
+
+// isNotFound checks whether the given error is a contracts module error with the given code.
+func isNotFound(err error, code uint32) bool {
+	module, errCode := errors.Code(err)
+	return module == ModuleName && errCode == code
 }
 
 // NewV1 generates a V1 client helper for the contracts module.
 func NewV1(rc client.RuntimeClient) V1 {
 	return &v1{rc: rc}
 }
+
+// NewUploadTx generates a new contracts.Upload transaction.
+//
+// The code in the body must already be compressed using Snappy framing.
+func NewUploadTx(fee *types.Fee, body *Upload) *types.Transaction {
+	return types.NewTransaction(fee, methodUpload, body)
+}
+
+// NewInstantiateTx generates a new contracts.Instantiate transaction.
+func NewInstantiateTx(fee *types.Fee, body *Instantiate) *types.Transaction {
+	return types.NewTransaction(fee, methodInstantiate, body)
+}
+
+// NewCallTx generates a new contracts.Call transaction.
+func NewCallTx(fee *types.Fee, body *Call) *types.Transaction {
+	return types.NewTransaction(fee, methodCall, body)
+}
+
+// NewUpgradeTx generates a new contracts.Upgrade transaction.
+func NewUpgradeTx(fee *types.Fee, body *Upgrade) *types.Transaction {
+	return types.NewTransaction(fee, methodUpgrade, body)
+}
+
+func init() {
+	registry.Register(&registry.Module{
+		Name: ModuleName,
+		Methods: []*registry.Method{
+			{Name: methodUpload, Kind: registry.MethodKindCall, Body: &Upload{}, Result: &UploadResult{}},
+			{Name: methodInstantiate, Kind: registry.MethodKindCall, Body: &Instantiate{}, Result: &InstantiateResult{}},
+			{Name: methodCall, Kind: registry.MethodKindCall, Body: &Call{}, Result: &CallResult{}},
+			{Name: methodUpgrade, Kind: registry.MethodKindCall, Body: &Upgrade{}, Result: nil},
+			{Name: methodCode, Kind: registry.MethodKindQuery, Body: &CodeQuery{}, Result: &Code{}},
+			{Name: methodInstance, Kind: registry.MethodKindQuery, Body: &InstanceQuery{}, Result: &Instance{}},
+			{Name: methodInstanceStorage, Kind: registry.MethodKindQuery, Body: &InstanceStorageQuery{}, Result: &InstanceStorageQueryResult{}},
+			{Name: methodPublicKey, Kind: registry.MethodKindQuery, Body: &PublicKeyQuery{}, Result: &PublicKeyQueryResult{}},
+			{Name: methodCustom, Kind: registry.MethodKindQuery, Body: &CustomQuery{}, Result: &CustomQueryResult{}},
+		},
+		EventDecoder: NewV1(nil),
+	})
+}
@@ -5,6 +5,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
 )
 
 func TestInstanceIDToAddress(t *testing.T) {
@@ -34,3 +38,47 @@ func TestInstanceIDToAddress(t *testing.T) {
 		require.EqualValues(tc.expectedAddress, tc.id.Address().String())
 	}
 }
+
+func TestCallResultDecode(t *testing.T) {
+	require := require.New(t)
+
+	type response struct {
+		Greeting string `json:"greeting"`
+	}
+
+	raw := CallResult(cbor.Marshal(&response{Greeting: "hello"}))
+	var rsp response
+	require.NoError(raw.Decode(&rsp), "Decode")
+	require.Equal("hello", rsp.Greeting)
+
+	raw = CallResult([]byte{0xff})
+	require.Error(raw.Decode(&rsp), "Decode should fail on malformed result")
+}
+
+func TestDecodeEvent(t *testing.T) {
+	require := require.New(t)
+
+	type greeting struct {
+		Text string `json:"text"`
+	}
+
+	raw := &types.Event{
+		Module: "contracts.42",
+		Code:   3,
+		Value:  cbor.Marshal(&Event{ID: 7, Data: cbor.Marshal(&greeting{Text: "hello"})}),
+	}
+	dec, err := NewV1(nil).DecodeEvent(raw)
+	require.NoError(err, "DecodeEvent")
+	ev := dec.(*Event)
+	require.EqualValues(7, ev.ID)
+	require.EqualValues(42, ev.CodeID)
+	require.EqualValues(3, ev.Code)
+
+	var g greeting
+	require.NoError(ev.DecodeData(&g), "DecodeData")
+	require.Equal("hello", g.Text)
+
+	dec, err = NewV1(nil).DecodeEvent(&types.Event{Module: "accounts", Code: 1})
+	require.NoError(err, "DecodeEvent other module")
+	require.Nil(dec)
+}
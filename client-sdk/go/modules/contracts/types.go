@@ -2,7 +2,9 @@ package contracts
 
 import (
 	"encoding/binary"
+	"fmt"
 
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
@@ -109,6 +111,14 @@ type Call struct {
 // CallResult is the result of the contracts.Call call.
 type CallResult []byte
 
+// Decode decodes the call result into the given value using CBOR as defined by the Oasis ABI.
+func (cr CallResult) Decode(rsp interface{}) error {
+	if err := cbor.Unmarshal(cr, rsp); err != nil {
+		return fmt.Errorf("failed to unmarshal call result from contract: %w", err)
+	}
+	return nil
+}
+
 // Upgrade is the body of the contracts.Upgrade call.
 type Upgrade struct {
 	// ID is the instance identifier.
@@ -184,6 +194,14 @@ type CustomQuery struct {
 // CustomQueryResult is the result of the contracts.Custom query.
 type CustomQueryResult []byte
 
+const (
+	// ErrCodeCodeNotFound is the error code returned when the requested code does not exist.
+	ErrCodeCodeNotFound = 9
+	// ErrCodeInstanceNotFound is the error code returned when the requested instance does not
+	// exist.
+	ErrCodeInstanceNotFound = 10
+)
+
 // ModuleName is the contracts module name.
 const ModuleName = "contracts"
 
@@ -193,4 +211,19 @@ type Event struct {
 	ID InstanceID `json:"id"`
 	// Data is the cbor serialized event data.
 	Data []byte `json:"data,omitempty"`
+
+	// CodeID is the identifier of the code that emitted the event. It is not part of the
+	// serialized event and is populated by the event decoder.
+	CodeID CodeID `json:"-"`
+	// Code is the contract-specific event code. It is not part of the serialized event and is
+	// populated by the event decoder.
+	Code uint32 `json:"-"`
+}
+
+// DecodeData decodes the event data into the given value using CBOR as defined by the Oasis ABI.
+func (e *Event) DecodeData(v interface{}) error {
+	if err := cbor.Unmarshal(e.Data, v); err != nil {
+		return fmt.Errorf("failed to unmarshal contract event data: %w", err)
+	}
+	return nil
 }
@@ -4,13 +4,17 @@ import (
 	"context"
 
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
 )
 
 const (
 	// Queries.
-	methodEstimateGas = "core.EstimateGas"
-	methodMinGasPrice = "core.MinGasPrice"
+	methodEstimateGas       = "core.EstimateGas"
+	methodCheckInvariants   = "core.CheckInvariants"
+	methodCallDataPublicKey = "core.CallDataPublicKey"
+	methodMinGasPrice       = "core.MinGasPrice"
+	methodParameters        = "core.Parameters"
 )
 
 // V1 is the v1 core module interface.
@@ -18,8 +22,25 @@ type V1 interface {
 	// EstimateGas performs gas estimation for executing the given transaction.
 	EstimateGas(ctx context.Context, round uint64, tx *types.Transaction) (uint64, error)
 
+	// CheckInvariants checks invariants of all modules in the runtime.
+	CheckInvariants(ctx context.Context, round uint64) error
+
+	// CallDataPublicKey returns the runtime's call data public key used for confidential
+	// transactions.
+	CallDataPublicKey(ctx context.Context, round uint64) (*CallDataPublicKeyQueryResponse, error)
+
 	// MinGasPrice returns the minimum gas price.
 	MinGasPrice(ctx context.Context) (map[types.Denomination]types.Quantity, error)
+
+	// Parameters queries the core module parameters.
+	Parameters(ctx context.Context, round uint64) (*Parameters, error)
+
+	// RuntimeInfo returns information about the runtime.
+	RuntimeInfo(ctx context.Context) (*types.RuntimeInfo, error)
+
+	// CheckTx asks the runtime to check the given transaction without submitting it for
+	// execution.
+	CheckTx(ctx context.Context, tx *types.UnverifiedTransaction) error
 }
 
 type v1 struct {
@@ -36,6 +57,21 @@ func (a *v1) EstimateGas(ctx context.Context, round uint64, tx *types.Transactio
 	return gas, nil
 }
 
+// Implements V1.
+func (a *v1) CheckInvariants(ctx context.Context, round uint64) error {
+	return a.rc.Query(ctx, round, methodCheckInvariants, nil, nil)
+}
+
+// Implements V1.
+func (a *v1) CallDataPublicKey(ctx context.Context, round uint64) (*CallDataPublicKeyQueryResponse, error) {
+	var rsp CallDataPublicKeyQueryResponse
+	err := a.rc.Query(ctx, round, methodCallDataPublicKey, nil, &rsp)
+	if err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
 // Implements V1.
 func (a *v1) MinGasPrice(ctx context.Context) (map[types.Denomination]types.Quantity, error) {
 	var mgp map[types.Denomination]types.Quantity
@@ -46,7 +82,40 @@ func (a *v1) MinGasPrice(ctx context.Context) (map[types.Denomination]types.Quan
 	return mgp, nil
 }
 
+// Implements V1.
+func (a *v1) Parameters(ctx context.Context, round uint64) (*Parameters, error) {
+	var params Parameters
+	err := a.rc.Query(ctx, round, methodParameters, nil, &params)
+	if err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+// Implements V1.
+func (a *v1) RuntimeInfo(ctx context.Context) (*types.RuntimeInfo, error) {
+	return a.rc.GetInfo(ctx)
+}
+
+// Implements V1.
+func (a *v1) CheckTx(ctx context.Context, tx *types.UnverifiedTransaction) error {
+	return a.rc.CheckTx(ctx, tx)
+}
+
 // NewV1 generates a V1 client helper for the core module.
 func NewV1(rc client.RuntimeClient) V1 {
 	return &v1{rc: rc}
 }
+
+func init() {
+	registry.Register(&registry.Module{
+		Name: ModuleName,
+		Methods: []*registry.Method{
+			{Name: methodEstimateGas, Kind: registry.MethodKindQuery, Body: &types.Transaction{}, Result: new(uint64)},
+			{Name: methodCheckInvariants, Kind: registry.MethodKindQuery, Body: nil, Result: nil},
+			{Name: methodCallDataPublicKey, Kind: registry.MethodKindQuery, Body: nil, Result: &CallDataPublicKeyQueryResponse{}},
+			{Name: methodMinGasPrice, Kind: registry.MethodKindQuery, Body: nil, Result: &map[types.Denomination]types.Quantity{}},
+			{Name: methodParameters, Kind: registry.MethodKindQuery, Body: nil, Result: &Parameters{}},
+		},
+	})
+}
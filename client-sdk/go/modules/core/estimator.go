@@ -0,0 +1,93 @@
+package core
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// maxSignatureSize is the maximum size of a signature produced by any of the supported signature
+// schemes (a DER-encoded secp256k1 signature).
+const maxSignatureSize = 72
+
+// LocalGasEstimator predicts the amount of gas a transaction will use without querying the
+// runtime.
+//
+// The estimate mirrors the charges made by the core module before dispatching a call (size and
+// signatures) plus a fixed per-method cost. It is only accurate for methods whose gas usage does
+// not depend on execution, like transfers, deposits and withdrawals. Since the method of an
+// encrypted call is not visible, only plain calls can be estimated.
+type LocalGasEstimator struct {
+	costs       GasCosts
+	methodCosts map[string]uint64
+}
+
+// AddMethodCosts registers fixed gas costs for the given methods, as returned by the MethodCosts
+// helpers of the module gas cost parameters.
+func (e *LocalGasEstimator) AddMethodCosts(costs map[string]uint64) *LocalGasEstimator {
+	for method, cost := range costs {
+		e.methodCosts[method] = cost
+	}
+	return e
+}
+
+// Estimate returns the estimated amount of gas the given transaction will use.
+//
+// Signatures are assumed to have the maximum size of any supported scheme, so the estimate is an
+// upper bound for the size-related costs.
+func (e *LocalGasEstimator) Estimate(tx *types.Transaction) (uint64, error) {
+	methodCost, ok := e.methodCosts[tx.Call.Method]
+	if !ok {
+		return 0, fmt.Errorf("core: no gas cost known for method '%s'", tx.Call.Method)
+	}
+
+	var (
+		numSignatures      uint64
+		numMultisigSigners uint64
+		proofs             []types.AuthProof
+	)
+	for _, si := range tx.AuthInfo.SignerInfo {
+		switch {
+		case si.AddressSpec.Signature != nil:
+			numSignatures++
+			proofs = append(proofs, types.AuthProof{Signature: make([]byte, maxSignatureSize)})
+		case si.AddressSpec.Multisig != nil:
+			signers := len(si.AddressSpec.Multisig.Signers)
+			numMultisigSigners += uint64(signers)
+			sigs := make([][]byte, signers)
+			for i := range sigs {
+				sigs[i] = make([]byte, maxSignatureSize)
+			}
+			proofs = append(proofs, types.AuthProof{Multisig: sigs})
+		}
+	}
+
+	// Use the largest possible gas limit so the size does not change once the estimate is set.
+	sizedTx := *tx
+	sizedTx.AuthInfo.Fee.Gas = math.MaxUint64
+	utx := types.UnverifiedTransaction{
+		Body:       cbor.Marshal(&sizedTx),
+		AuthProofs: proofs,
+	}
+	txSize := uint64(len(cbor.Marshal(&utx)))
+
+	gas := e.costs.TxByte*txSize +
+		e.costs.AuthSignature*numSignatures +
+		e.costs.AuthMultisigSigner*numMultisigSigners +
+		methodCost
+	return gas, nil
+}
+
+// NewLocalGasEstimator creates a new local gas estimator using the given core module parameters.
+//
+// Method costs must be registered via AddMethodCosts before transactions calling those methods
+// can be estimated.
+func NewLocalGasEstimator(params *Parameters) *LocalGasEstimator {
+	return &LocalGasEstimator{
+		costs:       params.GasCosts,
+		methodCosts: make(map[string]uint64),
+	}
+}
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func TestLocalGasEstimator(t *testing.T) {
+	require := require.New(t)
+
+	params := &Parameters{
+		GasCosts: GasCosts{
+			AuthSignature:      1_000,
+			AuthMultisigSigner: 1_000,
+		},
+	}
+	accountsCosts := accounts.GasCosts{TxTransfer: 100}
+	est := NewLocalGasEstimator(params).AddMethodCosts(accountsCosts.MethodCosts())
+
+	amount := types.NewBaseUnits(*quantity.NewFromUint64(1000), types.NativeDenomination)
+	tx := accounts.NewTransferTx(nil, &accounts.Transfer{To: sdkTesting.Bob.Address, Amount: amount})
+	tx.AppendAuthSignature(sdkTesting.Alice.SigSpec, 0)
+
+	gas, err := est.Estimate(tx)
+	require.NoError(err, "Estimate")
+	require.EqualValues(1_100, gas, "signature and method costs")
+
+	// Size costs should grow with the transaction size.
+	params.GasCosts.TxByte = 1
+	est = NewLocalGasEstimator(params).AddMethodCosts(accountsCosts.MethodCosts())
+	gas, err = est.Estimate(tx)
+	require.NoError(err, "Estimate")
+	require.Greater(gas, uint64(1_100), "size costs should be included")
+
+	tx = types.NewTransaction(nil, "accounts.Unknown", nil)
+	_, err = est.Estimate(tx)
+	require.Error(err, "unknown methods cannot be estimated")
+}
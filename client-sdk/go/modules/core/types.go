@@ -0,0 +1,33 @@
+package core
+
+import (
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// GasCosts are the core module gas costs.
+type GasCosts struct {
+	TxByte uint64 `json:"tx_byte"`
+
+	AuthSignature      uint64 `json:"auth_signature"`
+	AuthMultisigSigner uint64 `json:"auth_multisig_signer"`
+
+	CallformatX25519DeoxysII uint64 `json:"callformat_x25519_deoxysii"`
+}
+
+// Parameters are the parameters for the core module.
+type Parameters struct {
+	MaxBatchGas        uint64                                `json:"max_batch_gas"`
+	MaxTxSigners       uint32                                `json:"max_tx_signers"`
+	MaxMultisigSigners uint32                                `json:"max_multisig_signers"`
+	GasCosts           GasCosts                              `json:"gas_costs"`
+	MinGasPrice        map[types.Denomination]types.Quantity `json:"min_gas_price"`
+}
+
+// CallDataPublicKeyQueryResponse is the response of the core.CallDataPublicKey query.
+type CallDataPublicKeyQueryResponse struct {
+	// PublicKey is the signed runtime call data public key.
+	PublicKey types.SignedPublicKey `json:"public_key"`
+}
+
+// ModuleName is the core module name.
+const ModuleName = "core"
@@ -2,8 +2,12 @@ package evm
 
 import (
 	"context"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
 )
 
@@ -21,6 +25,8 @@ const (
 
 // V1 is the v1 EVM module interface.
 type V1 interface {
+	client.EventDecoder
+
 	// Create generates an EVM CREATE transaction.
 	// Note that the transaction's gas limit should be set to cover both the
 	// SDK gas limit and the EVM gas limit.  The transaction fee should be
@@ -44,6 +50,9 @@ type V1 interface {
 
 	// SimulateCall simulates an EVM CALL.
 	SimulateCall(ctx context.Context, gasPrice []byte, gasLimit uint64, caller []byte, address []byte, value []byte, data []byte) ([]byte, error)
+
+	// GetEvents returns all EVM events emitted in a given block.
+	GetEvents(ctx context.Context, round uint64) ([]*Event, error)
 }
 
 type v1 struct {
@@ -74,7 +83,7 @@ func (a *v1) Storage(ctx context.Context, address []byte, index []byte) ([]byte,
 		Address: address,
 		Index:   index,
 	}
-	if err := a.rtc.Query(ctx, client.RoundLatest, methodStorage, q, &res); err != nil {
+	if err := a.rtc.Query(ctx, client.RoundLatest, methodStorage, &q, &res); err != nil {
 		return nil, err
 	}
 	return res, nil
@@ -86,7 +95,7 @@ func (a *v1) Code(ctx context.Context, address []byte) ([]byte, error) {
 	q := CodeQuery{
 		Address: address,
 	}
-	if err := a.rtc.Query(ctx, client.RoundLatest, methodCode, q, &res); err != nil {
+	if err := a.rtc.Query(ctx, client.RoundLatest, methodCode, &q, &res); err != nil {
 		return nil, err
 	}
 	return res, nil
@@ -98,7 +107,7 @@ func (a *v1) Balance(ctx context.Context, address []byte) (*types.Quantity, erro
 	q := BalanceQuery{
 		Address: address,
 	}
-	if err := a.rtc.Query(ctx, client.RoundLatest, methodBalance, q, &res); err != nil {
+	if err := a.rtc.Query(ctx, client.RoundLatest, methodBalance, &q, &res); err != nil {
 		return nil, err
 	}
 	return &res, nil
@@ -115,13 +124,72 @@ func (a *v1) SimulateCall(ctx context.Context, gasPrice []byte, gasLimit uint64,
 		Value:    value,
 		Data:     data,
 	}
-	if err := a.rtc.Query(ctx, client.RoundLatest, methodSimulateCall, q, &res); err != nil {
+	if err := a.rtc.Query(ctx, client.RoundLatest, methodSimulateCall, &q, &res); err != nil {
 		return nil, err
 	}
 	return res, nil
 }
 
+// Implements V1.
+func (a *v1) GetEvents(ctx context.Context, round uint64) ([]*Event, error) {
+	rawEvs, err := a.rtc.GetEventsRaw(ctx, round)
+	if err != nil {
+		return nil, err
+	}
+
+	evs := make([]*Event, 0)
+	for _, rawEv := range rawEvs {
+		ev, err := a.DecodeEvent(rawEv)
+		if err != nil {
+			return nil, err
+		}
+		if ev == nil {
+			continue
+		}
+		evs = append(evs, ev.(*Event))
+	}
+
+	return evs, nil
+}
+
+// Implements client.EventDecoder.
+func (a *v1) DecodeEvent(event *types.Event) (client.DecodedEvent, error) {
+	if event.Module != ModuleName {
+		return nil, nil
+	}
+	switch event.Code {
+	case LogEventCode:
+		var ev LogEvent
+		if err := cbor.Unmarshal(event.Value, &ev); err != nil {
+			return nil, fmt.Errorf("decode evm log event value: %w", err)
+		}
+		return &Event{
+			Log: &ev,
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid evm event code: %v", event.Code)
+	}
+}
+
 // NewV1 generates a V1 client helper for the EVM module.
 func NewV1(rtc client.RuntimeClient) V1 {
 	return &v1{rtc: rtc}
 }
+
+func init() {
+	registry.Register(&registry.Module{
+		Name: ModuleName,
+		Methods: []*registry.Method{
+			{Name: methodCreate, Kind: registry.MethodKindCall, Body: &Create{}, Result: &[]byte{}},
+			{Name: methodCall, Kind: registry.MethodKindCall, Body: &Call{}, Result: &[]byte{}},
+			{Name: methodStorage, Kind: registry.MethodKindQuery, Body: &StorageQuery{}, Result: &[]byte{}},
+			{Name: methodCode, Kind: registry.MethodKindQuery, Body: &CodeQuery{}, Result: &[]byte{}},
+			{Name: methodBalance, Kind: registry.MethodKindQuery, Body: &BalanceQuery{}, Result: &types.Quantity{}},
+			{Name: methodSimulateCall, Kind: registry.MethodKindQuery, Body: &SimulateCallQuery{}, Result: &[]byte{}},
+		},
+		Events: []*registry.Event{
+			{Code: LogEventCode, Name: "Log", Value: &LogEvent{}},
+		},
+		EventDecoder: NewV1(nil),
+	})
+}
@@ -0,0 +1,36 @@
+package evm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func TestDecodeEvent(t *testing.T) {
+	require := require.New(t)
+
+	log := &LogEvent{
+		Address: []byte("01234567890123456789"),
+		Topics:  [][]byte{[]byte("0123456789012345678901234567890a")},
+		Data:    []byte("data"),
+	}
+	e := NewV1(nil)
+
+	ev, err := e.DecodeEvent(&types.Event{Module: ModuleName, Code: LogEventCode, Value: cbor.Marshal(log)})
+	require.NoError(err)
+	require.Equal(&Event{Log: log}, ev)
+
+	ev, err = e.DecodeEvent(&types.Event{Module: "accounts", Code: LogEventCode, Value: cbor.Marshal(log)})
+	require.NoError(err)
+	require.Nil(ev, "events of other modules should be ignored")
+
+	_, err = e.DecodeEvent(&types.Event{Module: ModuleName, Code: 42, Value: cbor.Marshal(log)})
+	require.Error(err, "unknown event codes should be rejected")
+
+	_, err = e.DecodeEvent(&types.Event{Module: ModuleName, Code: LogEventCode, Value: []byte{0xff}})
+	require.Error(err, "malformed events should be rejected")
+}
@@ -41,3 +41,23 @@ type SimulateCallQuery struct {
 	Value    []byte `json:"value"`
 	Data     []byte `json:"data"`
 }
+
+// ModuleName is the EVM module name.
+const ModuleName = "evm"
+
+const (
+	// LogEventCode is the event code for the log event.
+	LogEventCode = 1
+)
+
+// LogEvent is an event log emitted by an EVM contract.
+type LogEvent struct {
+	Address []byte   `json:"address"`
+	Topics  [][]byte `json:"topics"`
+	Data    []byte   `json:"data"`
+}
+
+// Event is an EVM event.
+type Event struct {
+	Log *LogEvent `json:"log,omitempty"`
+}
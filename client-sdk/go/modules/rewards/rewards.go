@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
 )
 
 const (
@@ -15,6 +17,9 @@ const (
 type V1 interface {
 	// Parameters queries the rewards module parameters.
 	Parameters(ctx context.Context, round uint64) (*Parameters, error)
+
+	// RewardPoolBalances queries the balances of the reward pool.
+	RewardPoolBalances(ctx context.Context, round uint64) (*accounts.AccountBalances, error)
 }
 
 type v1 struct {
@@ -31,7 +36,21 @@ func (a *v1) Parameters(ctx context.Context, round uint64) (*Parameters, error)
 	return &params, nil
 }
 
+// Implements V1.
+func (a *v1) RewardPoolBalances(ctx context.Context, round uint64) (*accounts.AccountBalances, error) {
+	return accounts.NewV1(a.rc).Balances(ctx, round, RewardPoolAddress)
+}
+
 // NewV1 generates a V1 client helper for the rewards module.
 func NewV1(rc client.RuntimeClient) V1 {
 	return &v1{rc: rc}
 }
+
+func init() {
+	registry.Register(&registry.Module{
+		Name: ModuleName,
+		Methods: []*registry.Method{
+			{Name: methodParameters, Kind: registry.MethodKindQuery, Body: nil, Result: &Parameters{}},
+		},
+	})
+}
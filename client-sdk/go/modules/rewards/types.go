@@ -1,6 +1,8 @@
 package rewards
 
 import (
+	"fmt"
+
 	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
 
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
@@ -17,6 +19,30 @@ type RewardSchedule struct {
 	Steps []RewardStep `json:"steps"`
 }
 
+// ValidateBasic performs basic reward schedule validation.
+func (rs *RewardSchedule) ValidateBasic() error {
+	var lastEpoch beacon.EpochTime
+	for _, step := range rs.Steps {
+		if step.Until <= lastEpoch {
+			return fmt.Errorf("rewards: steps not sorted correctly")
+		}
+		lastEpoch = step.Until
+	}
+	return nil
+}
+
+// ForEpoch computes the per-entity reward amount for the given epoch based on the schedule.
+func (rs *RewardSchedule) ForEpoch(epoch beacon.EpochTime) types.BaseUnits {
+	for _, step := range rs.Steps {
+		if epoch < step.Until {
+			return step.Amount
+		}
+	}
+
+	// End of the schedule, default to no rewards.
+	return types.BaseUnits{}
+}
+
 // Parameters are the parameters for the rewards module.
 type Parameters struct {
 	Schedule RewardSchedule `json:"schedule"`
@@ -24,3 +50,9 @@ type Parameters struct {
 	ParticipationThresholdNumerator   uint64 `json:"participation_threshold_numerator"`
 	ParticipationThresholdDenominator uint64 `json:"participation_threshold_denominator"`
 }
+
+// ModuleName is the rewards module name.
+const ModuleName = "rewards"
+
+// RewardPoolAddress is the address of the reward pool from which rewards are disbursed.
+var RewardPoolAddress = types.NewAddressForModule(ModuleName, []byte("reward-pool"))
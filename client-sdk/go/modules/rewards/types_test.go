@@ -0,0 +1,35 @@
+package rewards
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func TestRewardSchedule(t *testing.T) {
+	require := require.New(t)
+
+	amount := func(v uint64) types.BaseUnits {
+		return types.NewBaseUnits(*quantity.NewFromUint64(v), types.NativeDenomination)
+	}
+	rs := RewardSchedule{
+		Steps: []RewardStep{
+			{Until: 10, Amount: amount(1000)},
+			{Until: 20, Amount: amount(500)},
+		},
+	}
+	require.NoError(rs.ValidateBasic())
+	require.EqualValues(amount(1000), rs.ForEpoch(0))
+	require.EqualValues(amount(1000), rs.ForEpoch(9))
+	require.EqualValues(amount(500), rs.ForEpoch(10))
+	require.EqualValues(amount(500), rs.ForEpoch(19))
+	end := rs.ForEpoch(20)
+	require.True(end.Amount.IsZero(), "no rewards after the schedule ends")
+
+	rs.Steps[1].Until = 10
+	require.Error(rs.ValidateBasic(), "steps must be sorted")
+}
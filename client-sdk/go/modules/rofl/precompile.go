@@ -0,0 +1,132 @@
+package rofl
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+)
+
+// abiWordSize is the size of a single Solidity ABI word.
+const abiWordSize = 32
+
+var (
+	// SubcallPrecompileAddress is the address of the EVM precompile that dispatches calls to
+	// runtime modules, as used by the Sapphire contracts library.
+	SubcallPrecompileAddress = [20]byte{0x01, 19: 0x03}
+
+	// ErrUnauthorizedOrigin is the error returned when the transaction origin is not an endorsed
+	// key of the given application.
+	ErrUnauthorizedOrigin = errors.New("rofl: origin not authorized for app")
+)
+
+// SubcallError is a failure reported by the subcall precompile.
+type SubcallError struct {
+	// Code is the module error code.
+	Code uint64
+	// Module is the name of the module that failed.
+	Module string
+}
+
+// Error returns the string representation of the subcall error.
+func (e *SubcallError) Error() string {
+	return fmt.Sprintf("rofl: subcall failed (module: %s code: %d)", e.Module, e.Code)
+}
+
+// NewIsAuthorizedOriginCalldata builds the calldata for calling the subcall precompile in order to
+// check whether the transaction origin is an endorsed key of the given application.
+//
+// This is the same calldata that contracts produce when calling `roflEnsureAuthorizedOrigin`, so it
+// can be used to simulate the check before submitting a transaction.
+func NewIsAuthorizedOriginCalldata(id AppID) []byte {
+	return encodeSubcall(methodIsAuthorizedOrigin, cbor.Marshal(id))
+}
+
+// VerifyIsAuthorizedOriginResult verifies the output of the subcall precompile after it has been
+// invoked with calldata built by NewIsAuthorizedOriginCalldata.
+//
+// It returns nil iff the origin is authorized.
+func VerifyIsAuthorizedOriginResult(output []byte) error {
+	status, data, err := decodeSubcallResult(output)
+	if err != nil {
+		return err
+	}
+	if status != 0 {
+		return &SubcallError{Code: status, Module: string(data)}
+	}
+
+	var authorized bool
+	if err = cbor.Unmarshal(data, &authorized); err != nil {
+		return fmt.Errorf("rofl: malformed subcall result: %w", err)
+	}
+	if !authorized {
+		return ErrUnauthorizedOrigin
+	}
+	return nil
+}
+
+// encodeSubcall encodes the subcall precompile input as `abi.encode(string method, bytes body)`.
+func encodeSubcall(method string, body []byte) []byte {
+	methodSize := abiPaddedSize(len(method))
+	bodyOffset := 2*abiWordSize + abiWordSize + methodSize
+
+	out := make([]byte, 0, bodyOffset+abiWordSize+abiPaddedSize(len(body)))
+	out = append(out, abiUint(uint64(2*abiWordSize))...)
+	out = append(out, abiUint(uint64(bodyOffset))...)
+	out = append(out, abiBytes([]byte(method))...)
+	out = append(out, abiBytes(body)...)
+	return out
+}
+
+// decodeSubcallResult decodes the subcall precompile output encoded as
+// `abi.encode(uint64 status, bytes data)`.
+func decodeSubcallResult(output []byte) (uint64, []byte, error) {
+	status, err := abiReadUint(output, 0)
+	if err != nil {
+		return 0, nil, err
+	}
+	offset, err := abiReadUint(output, abiWordSize)
+	if err != nil {
+		return 0, nil, err
+	}
+	size, err := abiReadUint(output, offset)
+	if err != nil {
+		return 0, nil, err
+	}
+	start := offset + abiWordSize
+	if size > uint64(len(output)) || start > uint64(len(output))-size {
+		return 0, nil, fmt.Errorf("rofl: malformed subcall output")
+	}
+	return status, output[start : start+size], nil
+}
+
+func abiPaddedSize(n int) int {
+	return (n + abiWordSize - 1) / abiWordSize * abiWordSize
+}
+
+func abiUint(v uint64) []byte {
+	word := make([]byte, abiWordSize)
+	binary.BigEndian.PutUint64(word[abiWordSize-8:], v)
+	return word
+}
+
+func abiBytes(data []byte) []byte {
+	out := make([]byte, abiWordSize+abiPaddedSize(len(data)))
+	copy(out, abiUint(uint64(len(data))))
+	copy(out[abiWordSize:], data)
+	return out
+}
+
+func abiReadUint(data []byte, offset uint64) (uint64, error) {
+	if offset > uint64(len(data)) || uint64(len(data))-offset < abiWordSize {
+		return 0, fmt.Errorf("rofl: malformed subcall output")
+	}
+	word := data[offset : offset+abiWordSize]
+	for _, b := range word[:abiWordSize-8] {
+		if b != 0 {
+			return 0, fmt.Errorf("rofl: subcall output value out of range")
+		}
+	}
+	return binary.BigEndian.Uint64(word[abiWordSize-8:]), nil
+}
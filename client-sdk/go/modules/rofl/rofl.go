@@ -0,0 +1,73 @@
+// Package rofl provides a client for the ROFL module, which manages the registrations of runtime
+// off-chain logic (ROFL) application instances.
+//
+// The module is not part of the Runtime SDK modules in this repository, so the client can only be
+// used with runtimes that ship it.
+package rofl
+
+import (
+	"context"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+)
+
+const (
+	// Callable methods.
+	methodIsAuthorizedOrigin = "rofl.IsAuthorizedOrigin"
+
+	// Queries.
+	methodAppInstance  = "rofl.AppInstance"
+	methodAppInstances = "rofl.AppInstances"
+)
+
+// V1 is the v1 rofl module interface.
+type V1 interface {
+	// AppInstance queries a specific registered instance of the given application.
+	AppInstance(ctx context.Context, round uint64, id AppID, rak signature.PublicKey) (*Registration, error)
+
+	// AppInstances queries all registered instances of the given application.
+	AppInstances(ctx context.Context, round uint64, id AppID) ([]*Registration, error)
+}
+
+type v1 struct {
+	rc client.RuntimeClient
+}
+
+// Implements V1.
+func (a *v1) AppInstance(ctx context.Context, round uint64, id AppID, rak signature.PublicKey) (*Registration, error) {
+	var instance Registration
+	err := a.rc.Query(ctx, round, methodAppInstance, &AppInstanceQuery{App: id, RAK: rak}, &instance)
+	if err != nil {
+		return nil, err
+	}
+	return &instance, nil
+}
+
+// Implements V1.
+func (a *v1) AppInstances(ctx context.Context, round uint64, id AppID) ([]*Registration, error) {
+	var instances []*Registration
+	err := a.rc.Query(ctx, round, methodAppInstances, &AppQuery{ID: id}, &instances)
+	if err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// NewV1 generates a V1 client helper for the rofl module.
+func NewV1(rc client.RuntimeClient) V1 {
+	return &v1{rc: rc}
+}
+
+func init() {
+	registry.Register(&registry.Module{
+		Name: ModuleName,
+		Methods: []*registry.Method{
+			{Name: methodIsAuthorizedOrigin, Kind: registry.MethodKindCall, Body: &AppID{}, Result: new(bool)},
+			{Name: methodAppInstance, Kind: registry.MethodKindQuery, Body: &AppInstanceQuery{}, Result: &Registration{}},
+			{Name: methodAppInstances, Kind: registry.MethodKindQuery, Body: &AppQuery{}, Result: &[]*Registration{}},
+		},
+	})
+}
@@ -0,0 +1,84 @@
+package rofl
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func TestAppID(t *testing.T) {
+	require := require.New(t)
+
+	id := NewAppIDCreatorRoundIndex(sdkTesting.Alice.Address, 42, 0)
+	require.NotEqual(id, NewAppIDCreatorRoundIndex(sdkTesting.Alice.Address, 42, 1))
+	require.NotEqual(id, NewAppIDCreatorRoundIndex(sdkTesting.Bob.Address, 42, 0))
+	require.NotEqual(id, NewAppIDGlobalName("test"))
+
+	text, err := id.MarshalText()
+	require.NoError(err, "MarshalText")
+	require.Equal("rofl1", string(text[:5]), "app identifiers should use the rofl prefix")
+	require.Equal(id, NewAppIDFromBech32(string(text)))
+	require.Equal(string(text), id.String())
+
+	var decoded AppID
+	require.Error(decoded.UnmarshalText([]byte(sdkTesting.Alice.Address.String())), "addresses are not app identifiers")
+
+	var raw []byte
+	require.NoError(cbor.Unmarshal(cbor.Marshal(id), &raw), "app identifiers should encode as bytes")
+	require.Len(raw, 21)
+}
+
+func TestIsAuthorizedOriginCalldata(t *testing.T) {
+	require := require.New(t)
+
+	id := NewAppIDGlobalName("test")
+	calldata := NewIsAuthorizedOriginCalldata(id)
+
+	// Offsets, method length and padded method, body length and padded body.
+	require.Len(calldata, 6*abiWordSize)
+	require.Equal("0000000000000000000000000000000000000000000000000000000000000040", hex.EncodeToString(calldata[:abiWordSize]))
+	require.Equal("0000000000000000000000000000000000000000000000000000000000000080", hex.EncodeToString(calldata[abiWordSize:2*abiWordSize]))
+	require.EqualValues(len(methodIsAuthorizedOrigin), calldata[3*abiWordSize-1])
+	require.Equal(methodIsAuthorizedOrigin, string(calldata[3*abiWordSize:3*abiWordSize+len(methodIsAuthorizedOrigin)]))
+	require.EqualValues(22, calldata[5*abiWordSize-1])
+	require.Equal(append([]byte{0x55}, id[:]...), calldata[5*abiWordSize:5*abiWordSize+22])
+}
+
+func TestVerifyIsAuthorizedOriginResult(t *testing.T) {
+	require := require.New(t)
+
+	output := append(abiUint(0), abiUint(2*abiWordSize)...)
+	require.NoError(VerifyIsAuthorizedOriginResult(append(output, abiBytes(cbor.Marshal(true))...)))
+	require.ErrorIs(VerifyIsAuthorizedOriginResult(append(output, abiBytes(cbor.Marshal(false))...)), ErrUnauthorizedOrigin)
+
+	output = append(abiUint(4), abiUint(2*abiWordSize)...)
+	err := VerifyIsAuthorizedOriginResult(append(output, abiBytes([]byte("rofl"))...))
+	var subcallErr *SubcallError
+	require.ErrorAs(err, &subcallErr)
+	require.EqualValues(4, subcallErr.Code)
+	require.Equal("rofl", subcallErr.Module)
+
+	require.Error(VerifyIsAuthorizedOriginResult(output[:abiWordSize]), "truncated output should be rejected")
+}
+
+func TestRegistrationIsEndorsed(t *testing.T) {
+	require := require.New(t)
+
+	rak := sdkTesting.Alice.Signer.Public().(ed25519.PublicKey)
+	reg := Registration{
+		RAK:       signature.PublicKey(rak),
+		ExtraKeys: []types.PublicKey{{PublicKey: sdkTesting.Dave.Signer.Public()}},
+	}
+	require.True(reg.IsEndorsed(types.PublicKey{PublicKey: sdkTesting.Alice.Signer.Public()}), "RAK should be endorsed")
+	require.True(reg.IsEndorsed(types.PublicKey{PublicKey: sdkTesting.Dave.Signer.Public()}), "extra keys should be endorsed")
+	require.False(reg.IsEndorsed(types.PublicKey{PublicKey: sdkTesting.Bob.Signer.Public()}))
+	require.False(reg.IsEndorsed(types.PublicKey{}))
+}
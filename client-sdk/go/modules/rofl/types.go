@@ -0,0 +1,139 @@
+package rofl
+
+import (
+	"encoding"
+	"encoding/binary"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/address"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/encoding/bech32"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// ModuleName is the rofl module name.
+const ModuleName = "rofl"
+
+var (
+	// AppIDV0CRIContext is the unique context for v0 creator/round/index application identifiers.
+	AppIDV0CRIContext = address.NewContext("oasis-sdk/rofl: cri app id", 0)
+	// AppIDV0GlobalNameContext is the unique context for v0 global name application identifiers.
+	AppIDV0GlobalNameContext = address.NewContext("oasis-sdk/rofl: global name app id", 0)
+	// AppIDBech32HRP is the unique human readable part of Bech32 encoded application identifiers.
+	AppIDBech32HRP = address.NewBech32HRP("rofl")
+
+	_ encoding.BinaryMarshaler   = AppID{}
+	_ encoding.BinaryUnmarshaler = (*AppID)(nil)
+	_ encoding.TextMarshaler     = AppID{}
+	_ encoding.TextUnmarshaler   = (*AppID)(nil)
+)
+
+// AppID is the ROFL application identifier.
+type AppID address.Address
+
+// MarshalBinary encodes an application identifier into binary form.
+func (a AppID) MarshalBinary() ([]byte, error) {
+	return (address.Address)(a).MarshalBinary()
+}
+
+// UnmarshalBinary decodes a binary marshaled application identifier.
+func (a *AppID) UnmarshalBinary(data []byte) error {
+	return (*address.Address)(a).UnmarshalBinary(data)
+}
+
+// MarshalText encodes an application identifier into text form.
+func (a AppID) MarshalText() ([]byte, error) {
+	return (address.Address)(a).MarshalBech32(AppIDBech32HRP)
+}
+
+// UnmarshalText decodes a text marshaled application identifier.
+func (a *AppID) UnmarshalText(text []byte) error {
+	return (*address.Address)(a).UnmarshalBech32(AppIDBech32HRP, text)
+}
+
+// Equal compares vs another application identifier for equality.
+func (a AppID) Equal(cmp AppID) bool {
+	return (address.Address)(a).Equal((address.Address)(cmp))
+}
+
+// String returns the string representation of an application identifier.
+func (a AppID) String() string {
+	bech32Addr, err := bech32.Encode(AppIDBech32HRP.String(), a[:])
+	if err != nil {
+		return "[malformed]"
+	}
+	return bech32Addr
+}
+
+// NewAppIDCreatorRoundIndex creates a new application identifier from the given creator/round/index
+// tuple.
+func NewAppIDCreatorRoundIndex(creator types.Address, round uint64, index uint32) AppID {
+	data := make([]byte, address.Size+8+4)
+	copy(data, creator[:])
+	binary.BigEndian.PutUint64(data[address.Size:], round)
+	binary.BigEndian.PutUint32(data[address.Size+8:], index)
+	return (AppID)(address.NewAddress(AppIDV0CRIContext, data))
+}
+
+// NewAppIDGlobalName creates a new application identifier from the given global app name.
+func NewAppIDGlobalName(name string) AppID {
+	return (AppID)(address.NewAddress(AppIDV0GlobalNameContext, []byte(name)))
+}
+
+// NewAppIDFromBech32 creates a new application identifier from the given bech-32 encoded string.
+//
+// Panics in case of errors -- use UnmarshalText if you want to handle errors.
+func NewAppIDFromBech32(data string) (a AppID) {
+	err := a.UnmarshalText([]byte(data))
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// AppInstanceQuery is an application instance query.
+type AppInstanceQuery struct {
+	// App is the application identifier.
+	App AppID `json:"app"`
+	// RAK is the runtime attestation key of the instance.
+	RAK signature.PublicKey `json:"rak"`
+}
+
+// AppQuery is an application-related query.
+type AppQuery struct {
+	// ID is the application identifier.
+	ID AppID `json:"id"`
+}
+
+// Registration is an application instance registration.
+type Registration struct {
+	// App is the application identifier.
+	App AppID `json:"app"`
+	// NodeID is the identifier of the endorsing node.
+	NodeID signature.PublicKey `json:"node_id"`
+	// RAK is the runtime attestation key of the instance.
+	RAK signature.PublicKey `json:"rak"`
+	// Expiration is the epoch when the registration expires.
+	Expiration uint64 `json:"expiration"`
+	// ExtraKeys are additional keys endorsed by the instance's attestation key.
+	ExtraKeys []types.PublicKey `json:"extra_keys"`
+}
+
+// IsEndorsed returns true iff transactions signed by the given public key are considered to
+// originate from this application instance, i.e. the key is either the instance's attestation key
+// or one of the extra keys endorsed by it.
+func (r *Registration) IsEndorsed(pk types.PublicKey) bool {
+	if pk.PublicKey == nil {
+		return false
+	}
+	if ed25519.PublicKey(r.RAK).Equal(pk.PublicKey) {
+		return true
+	}
+	for _, ek := range r.ExtraKeys {
+		if ek.PublicKey != nil && ek.Equal(pk.PublicKey) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,288 @@
+// Package roflmarket provides a client for the ROFL market module, which allows providers to offer
+// compute resources for running ROFL apps and users to rent them.
+//
+// The module is not part of the Runtime SDK modules in this repository, so the client can only be
+// used with runtimes that ship it.
+package roflmarket
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const (
+	// Callable methods.
+	methodProviderCreate       = "roflmarket.ProviderCreate"
+	methodProviderUpdate       = "roflmarket.ProviderUpdate"
+	methodProviderUpdateOffers = "roflmarket.ProviderUpdateOffers"
+	methodProviderRemove       = "roflmarket.ProviderRemove"
+	methodInstanceCreate       = "roflmarket.InstanceCreate"
+	methodInstanceTopUp        = "roflmarket.InstanceTopUp"
+	methodInstanceCancel       = "roflmarket.InstanceCancel"
+
+	// Queries.
+	methodProvider  = "roflmarket.Provider"
+	methodProviders = "roflmarket.Providers"
+	methodOffer     = "roflmarket.Offer"
+	methodOffers    = "roflmarket.Offers"
+	methodInstance  = "roflmarket.Instance"
+	methodInstances = "roflmarket.Instances"
+)
+
+// V1 is the v1 roflmarket module interface.
+type V1 interface {
+	client.EventDecoder
+
+	// ProviderCreate generates a roflmarket.ProviderCreate transaction.
+	ProviderCreate(body *ProviderCreate) *client.TransactionBuilder
+
+	// ProviderUpdate generates a roflmarket.ProviderUpdate transaction.
+	ProviderUpdate(body *ProviderUpdate) *client.TransactionBuilder
+
+	// ProviderUpdateOffers generates a roflmarket.ProviderUpdateOffers transaction.
+	ProviderUpdateOffers(body *ProviderUpdateOffers) *client.TransactionBuilder
+
+	// ProviderRemove generates a roflmarket.ProviderRemove transaction.
+	ProviderRemove(provider types.Address) *client.TransactionBuilder
+
+	// InstanceCreate generates a roflmarket.InstanceCreate transaction.
+	InstanceCreate(body *InstanceCreate) *client.TransactionBuilder
+
+	// InstanceTopUp generates a roflmarket.InstanceTopUp transaction.
+	InstanceTopUp(body *InstanceTopUp) *client.TransactionBuilder
+
+	// InstanceCancel generates a roflmarket.InstanceCancel transaction.
+	InstanceCancel(provider types.Address, id InstanceID) *client.TransactionBuilder
+
+	// Provider queries the given provider.
+	Provider(ctx context.Context, round uint64, provider types.Address) (*Provider, error)
+
+	// Providers queries all providers.
+	Providers(ctx context.Context, round uint64) ([]*Provider, error)
+
+	// Offer queries the given provider's offer.
+	Offer(ctx context.Context, round uint64, provider types.Address, id OfferID) (*Offer, error)
+
+	// Offers queries all offers of the given provider.
+	Offers(ctx context.Context, round uint64, provider types.Address) ([]*Offer, error)
+
+	// Instance queries the given provider's instance.
+	Instance(ctx context.Context, round uint64, provider types.Address, id InstanceID) (*Instance, error)
+
+	// Instances queries all instances of the given provider.
+	Instances(ctx context.Context, round uint64, provider types.Address) ([]*Instance, error)
+
+	// GetEvents returns all roflmarket events emitted in a given block.
+	GetEvents(ctx context.Context, round uint64) ([]*Event, error)
+}
+
+type v1 struct {
+	rc client.RuntimeClient
+}
+
+// Implements V1.
+func (a *v1) ProviderCreate(body *ProviderCreate) *client.TransactionBuilder {
+	return client.NewTransactionBuilder(a.rc, methodProviderCreate, body)
+}
+
+// Implements V1.
+func (a *v1) ProviderUpdate(body *ProviderUpdate) *client.TransactionBuilder {
+	return client.NewTransactionBuilder(a.rc, methodProviderUpdate, body)
+}
+
+// Implements V1.
+func (a *v1) ProviderUpdateOffers(body *ProviderUpdateOffers) *client.TransactionBuilder {
+	return client.NewTransactionBuilder(a.rc, methodProviderUpdateOffers, body)
+}
+
+// Implements V1.
+func (a *v1) ProviderRemove(provider types.Address) *client.TransactionBuilder {
+	return client.NewTransactionBuilder(a.rc, methodProviderRemove, &ProviderRemove{
+		Provider: provider,
+	})
+}
+
+// Implements V1.
+func (a *v1) InstanceCreate(body *InstanceCreate) *client.TransactionBuilder {
+	return client.NewTransactionBuilder(a.rc, methodInstanceCreate, body)
+}
+
+// Implements V1.
+func (a *v1) InstanceTopUp(body *InstanceTopUp) *client.TransactionBuilder {
+	return client.NewTransactionBuilder(a.rc, methodInstanceTopUp, body)
+}
+
+// Implements V1.
+func (a *v1) InstanceCancel(provider types.Address, id InstanceID) *client.TransactionBuilder {
+	return client.NewTransactionBuilder(a.rc, methodInstanceCancel, &InstanceCancel{
+		Provider: provider,
+		ID:       id,
+	})
+}
+
+// Implements V1.
+func (a *v1) Provider(ctx context.Context, round uint64, provider types.Address) (*Provider, error) {
+	var p Provider
+	err := a.rc.Query(ctx, round, methodProvider, &ProviderQuery{Provider: provider}, &p)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Implements V1.
+func (a *v1) Providers(ctx context.Context, round uint64) ([]*Provider, error) {
+	var providers []*Provider
+	err := a.rc.Query(ctx, round, methodProviders, nil, &providers)
+	if err != nil {
+		return nil, err
+	}
+	return providers, nil
+}
+
+// Implements V1.
+func (a *v1) Offer(ctx context.Context, round uint64, provider types.Address, id OfferID) (*Offer, error) {
+	var offer Offer
+	err := a.rc.Query(ctx, round, methodOffer, &OfferQuery{Provider: provider, ID: id}, &offer)
+	if err != nil {
+		return nil, err
+	}
+	return &offer, nil
+}
+
+// Implements V1.
+func (a *v1) Offers(ctx context.Context, round uint64, provider types.Address) ([]*Offer, error) {
+	var offers []*Offer
+	err := a.rc.Query(ctx, round, methodOffers, &ProviderQuery{Provider: provider}, &offers)
+	if err != nil {
+		return nil, err
+	}
+	return offers, nil
+}
+
+// Implements V1.
+func (a *v1) Instance(ctx context.Context, round uint64, provider types.Address, id InstanceID) (*Instance, error) {
+	var instance Instance
+	err := a.rc.Query(ctx, round, methodInstance, &InstanceQuery{Provider: provider, ID: id}, &instance)
+	if err != nil {
+		return nil, err
+	}
+	return &instance, nil
+}
+
+// Implements V1.
+func (a *v1) Instances(ctx context.Context, round uint64, provider types.Address) ([]*Instance, error) {
+	var instances []*Instance
+	err := a.rc.Query(ctx, round, methodInstances, &ProviderQuery{Provider: provider}, &instances)
+	if err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// Implements V1.
+func (a *v1) GetEvents(ctx context.Context, round uint64) ([]*Event, error) {
+	rawEvs, err := a.rc.GetEventsRaw(ctx, round)
+	if err != nil {
+		return nil, err
+	}
+
+	evs := make([]*Event, 0)
+	for _, rawEv := range rawEvs {
+		ev, err := a.DecodeEvent(rawEv)
+		if err != nil {
+			return nil, err
+		}
+		if ev == nil {
+			continue
+		}
+		evs = append(evs, ev.(*Event))
+	}
+
+	return evs, nil
+}
+
+// Implements client.EventDecoder.
+func (a *v1) DecodeEvent(event *types.Event) (client.DecodedEvent, error) {
+	if event.Module != ModuleName {
+		return nil, nil
+	}
+	switch event.Code {
+	case ProviderCreatedEventCode, ProviderUpdatedEventCode, ProviderRemovedEventCode:
+		var ev *ProviderEvent
+		if err := cbor.Unmarshal(event.Value, &ev); err != nil {
+			return nil, fmt.Errorf("decode roflmarket provider event value: %w", err)
+		}
+		switch event.Code {
+		case ProviderCreatedEventCode:
+			return &Event{ProviderCreated: ev}, nil
+		case ProviderUpdatedEventCode:
+			return &Event{ProviderUpdated: ev}, nil
+		default:
+			return &Event{ProviderRemoved: ev}, nil
+		}
+	case InstanceCreatedEventCode, InstanceUpdatedEventCode, InstanceAcceptedEventCode,
+		InstanceCancelledEventCode, InstanceRemovedEventCode:
+		var ev *InstanceEvent
+		if err := cbor.Unmarshal(event.Value, &ev); err != nil {
+			return nil, fmt.Errorf("decode roflmarket instance event value: %w", err)
+		}
+		switch event.Code {
+		case InstanceCreatedEventCode:
+			return &Event{InstanceCreated: ev}, nil
+		case InstanceUpdatedEventCode:
+			return &Event{InstanceUpdated: ev}, nil
+		case InstanceAcceptedEventCode:
+			return &Event{InstanceAccepted: ev}, nil
+		case InstanceCancelledEventCode:
+			return &Event{InstanceCancelled: ev}, nil
+		default:
+			return &Event{InstanceRemoved: ev}, nil
+		}
+	default:
+		return nil, fmt.Errorf("invalid roflmarket event code: %v", event.Code)
+	}
+}
+
+// NewV1 generates a V1 client helper for the roflmarket module.
+func NewV1(rc client.RuntimeClient) V1 {
+	return &v1{rc: rc}
+}
+
+func init() {
+	registry.Register(&registry.Module{
+		Name: ModuleName,
+		Methods: []*registry.Method{
+			{Name: methodProviderCreate, Kind: registry.MethodKindCall, Body: &ProviderCreate{}, Result: nil},
+			{Name: methodProviderUpdate, Kind: registry.MethodKindCall, Body: &ProviderUpdate{}, Result: nil},
+			{Name: methodProviderUpdateOffers, Kind: registry.MethodKindCall, Body: &ProviderUpdateOffers{}, Result: nil},
+			{Name: methodProviderRemove, Kind: registry.MethodKindCall, Body: &ProviderRemove{}, Result: nil},
+			{Name: methodInstanceCreate, Kind: registry.MethodKindCall, Body: &InstanceCreate{}, Result: &InstanceID{}},
+			{Name: methodInstanceTopUp, Kind: registry.MethodKindCall, Body: &InstanceTopUp{}, Result: nil},
+			{Name: methodInstanceCancel, Kind: registry.MethodKindCall, Body: &InstanceCancel{}, Result: nil},
+			{Name: methodProvider, Kind: registry.MethodKindQuery, Body: &ProviderQuery{}, Result: &Provider{}},
+			{Name: methodProviders, Kind: registry.MethodKindQuery, Body: nil, Result: &[]*Provider{}},
+			{Name: methodOffer, Kind: registry.MethodKindQuery, Body: &OfferQuery{}, Result: &Offer{}},
+			{Name: methodOffers, Kind: registry.MethodKindQuery, Body: &ProviderQuery{}, Result: &[]*Offer{}},
+			{Name: methodInstance, Kind: registry.MethodKindQuery, Body: &InstanceQuery{}, Result: &Instance{}},
+			{Name: methodInstances, Kind: registry.MethodKindQuery, Body: &ProviderQuery{}, Result: &[]*Instance{}},
+		},
+		Events: []*registry.Event{
+			{Code: ProviderCreatedEventCode, Name: "ProviderCreated", Value: &ProviderEvent{}},
+			{Code: ProviderUpdatedEventCode, Name: "ProviderUpdated", Value: &ProviderEvent{}},
+			{Code: ProviderRemovedEventCode, Name: "ProviderRemoved", Value: &ProviderEvent{}},
+			{Code: InstanceCreatedEventCode, Name: "InstanceCreated", Value: &InstanceEvent{}},
+			{Code: InstanceUpdatedEventCode, Name: "InstanceUpdated", Value: &InstanceEvent{}},
+			{Code: InstanceAcceptedEventCode, Name: "InstanceAccepted", Value: &InstanceEvent{}},
+			{Code: InstanceCancelledEventCode, Name: "InstanceCancelled", Value: &InstanceEvent{}},
+			{Code: InstanceRemovedEventCode, Name: "InstanceRemoved", Value: &InstanceEvent{}},
+		},
+		EventDecoder: NewV1(nil),
+	})
+}
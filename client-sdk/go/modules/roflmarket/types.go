@@ -0,0 +1,355 @@
+package roflmarket
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// identifierSize is the size of offer and instance identifiers.
+const identifierSize = 8
+
+func marshalIdentifierText(id []byte) ([]byte, error) {
+	return []byte(hex.EncodeToString(id)), nil
+}
+
+func unmarshalIdentifierText(dst, text []byte) error {
+	raw, err := hex.DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+	return unmarshalIdentifierBinary(dst, raw)
+}
+
+func unmarshalIdentifierBinary(dst, data []byte) error {
+	if len(data) != identifierSize {
+		return fmt.Errorf("roflmarket: malformed identifier")
+	}
+	copy(dst, data)
+	return nil
+}
+
+// OfferID is the per-provider offer identifier.
+type OfferID [identifierSize]byte
+
+// MarshalBinary encodes an offer identifier into binary form.
+func (id OfferID) MarshalBinary() ([]byte, error) {
+	return id[:], nil
+}
+
+// UnmarshalBinary decodes a binary marshaled offer identifier.
+func (id *OfferID) UnmarshalBinary(data []byte) error {
+	return unmarshalIdentifierBinary(id[:], data)
+}
+
+// MarshalText encodes an offer identifier into text form.
+func (id OfferID) MarshalText() ([]byte, error) {
+	return marshalIdentifierText(id[:])
+}
+
+// UnmarshalText decodes a text marshaled offer identifier.
+func (id *OfferID) UnmarshalText(text []byte) error {
+	return unmarshalIdentifierText(id[:], text)
+}
+
+// String returns a string representation of the offer identifier.
+func (id OfferID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// InstanceID is the per-provider instance identifier.
+type InstanceID [identifierSize]byte
+
+// MarshalBinary encodes an instance identifier into binary form.
+func (id InstanceID) MarshalBinary() ([]byte, error) {
+	return id[:], nil
+}
+
+// UnmarshalBinary decodes a binary marshaled instance identifier.
+func (id *InstanceID) UnmarshalBinary(data []byte) error {
+	return unmarshalIdentifierBinary(id[:], data)
+}
+
+// MarshalText encodes an instance identifier into text form.
+func (id InstanceID) MarshalText() ([]byte, error) {
+	return marshalIdentifierText(id[:])
+}
+
+// UnmarshalText decodes a text marshaled instance identifier.
+func (id *InstanceID) UnmarshalText(text []byte) error {
+	return unmarshalIdentifierText(id[:], text)
+}
+
+// String returns a string representation of the instance identifier.
+func (id InstanceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// TeeType is the type of trusted execution environment.
+type TeeType uint8
+
+const (
+	// TeeTypeSGX is the Intel SGX trusted execution environment.
+	TeeTypeSGX = TeeType(1)
+	// TeeTypeTDX is the Intel TDX trusted execution environment.
+	TeeTypeTDX = TeeType(2)
+)
+
+// GPUResource is the GPU resource descriptor.
+type GPUResource struct {
+	// Model is the GPU model. Empty means any model.
+	Model string `json:"model,omitempty"`
+	// Count is the number of GPUs.
+	Count uint8 `json:"count"`
+}
+
+// Resources are the compute resources offered by a provider.
+type Resources struct {
+	// TEE is the type of trusted execution environment.
+	TEE TeeType `json:"tee"`
+	// Memory is the amount of memory in megabytes.
+	Memory uint64 `json:"memory"`
+	// CPUCount is the number of vCPUs.
+	CPUCount uint16 `json:"cpus"`
+	// Storage is the amount of storage in megabytes.
+	Storage uint64 `json:"storage"`
+	// GPU is the optional GPU resource.
+	GPU *GPUResource `json:"gpu,omitempty"`
+}
+
+// Term is the rental term unit.
+type Term uint8
+
+const (
+	// TermHour is an hourly term.
+	TermHour = Term(1)
+	// TermMonth is a monthly term.
+	TermMonth = Term(2)
+	// TermYear is a yearly term.
+	TermYear = Term(3)
+)
+
+// NativePayment is a payment in the runtime's native tokens.
+type NativePayment struct {
+	// Denomination is the denomination of the payment.
+	Denomination types.Denomination `json:"denomination"`
+	// Terms are the prices for each supported term.
+	Terms map[Term]types.Quantity `json:"terms"`
+}
+
+// EvmContractPayment is a payment handled by an EVM smart contract.
+type EvmContractPayment struct {
+	// Address is the address of the payment contract.
+	Address []byte `json:"address"`
+	// Data is the opaque data passed to the payment contract.
+	Data []byte `json:"data"`
+}
+
+// Payment is the payment method for an offer.
+type Payment struct {
+	Native      *NativePayment      `json:"native,omitempty"`
+	EvmContract *EvmContractPayment `json:"evm,omitempty"`
+}
+
+// Offer is a provider's offer of resources.
+type Offer struct {
+	// ID is the offer identifier.
+	ID OfferID `json:"id"`
+	// Resources are the offered resources.
+	Resources Resources `json:"resources"`
+	// Payment is the payment method and price.
+	Payment Payment `json:"payment"`
+	// Capacity is the number of instances that can still be rented using this offer.
+	Capacity uint64 `json:"capacity"`
+	// Metadata is arbitrary metadata attached to the offer.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// PaymentAddress is the address where a provider receives payments.
+type PaymentAddress struct {
+	Native *types.Address `json:"native,omitempty"`
+	Eth    *[20]byte      `json:"eth,omitempty"`
+}
+
+// Provider is a registered provider.
+type Provider struct {
+	// Address is the provider address.
+	Address types.Address `json:"address"`
+	// Nodes are the identities of the provider's nodes.
+	Nodes []signature.PublicKey `json:"nodes"`
+	// PaymentAddress is the address where the provider receives payments.
+	PaymentAddress PaymentAddress `json:"payment_address"`
+	// Metadata is arbitrary metadata attached to the provider.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Stake is the amount staked for provider registration.
+	Stake types.BaseUnits `json:"stake"`
+	// OffersNextID is the identifier of the next offer.
+	OffersNextID OfferID `json:"offers_next_id"`
+	// OffersCount is the number of offers.
+	OffersCount uint64 `json:"offers_count"`
+	// InstancesNextID is the identifier of the next instance.
+	InstancesNextID InstanceID `json:"instances_next_id"`
+	// InstancesCount is the number of instances.
+	InstancesCount uint64 `json:"instances_count"`
+	// CreatedAt is the timestamp when the provider was created.
+	CreatedAt uint64 `json:"created_at"`
+	// UpdatedAt is the timestamp when the provider was last updated.
+	UpdatedAt uint64 `json:"updated_at"`
+}
+
+// InstanceStatus is the status of a rented instance.
+type InstanceStatus uint8
+
+const (
+	// InstanceStatusCreated means the instance has been created but not yet accepted.
+	InstanceStatusCreated = InstanceStatus(0)
+	// InstanceStatusAccepted means the instance has been accepted by the provider.
+	InstanceStatusAccepted = InstanceStatus(1)
+	// InstanceStatusCancelled means the instance has been cancelled.
+	InstanceStatusCancelled = InstanceStatus(2)
+)
+
+// Instance is a rented instance.
+type Instance struct {
+	// Provider is the address of the provider.
+	Provider types.Address `json:"provider"`
+	// ID is the instance identifier.
+	ID InstanceID `json:"id"`
+	// Offer is the identifier of the offer used to rent the instance.
+	Offer OfferID `json:"offer"`
+	// Status is the instance status.
+	Status InstanceStatus `json:"status"`
+	// Creator is the address of the account that created the instance.
+	Creator types.Address `json:"creator"`
+	// Admin is the address of the account that administers the instance.
+	Admin types.Address `json:"admin"`
+	// NodeID is the identifier of the node hosting the instance, if accepted.
+	NodeID *signature.PublicKey `json:"node_id,omitempty"`
+	// Resources are the rented resources.
+	Resources Resources `json:"resources"`
+	// CreatedAt is the timestamp when the instance was created.
+	CreatedAt uint64 `json:"created_at"`
+	// UpdatedAt is the timestamp when the instance was last updated.
+	UpdatedAt uint64 `json:"updated_at"`
+	// PaidFrom is the timestamp from which the instance is paid.
+	PaidFrom uint64 `json:"paid_from"`
+	// PaidUntil is the timestamp until which the instance is paid.
+	PaidUntil uint64 `json:"paid_until"`
+}
+
+// ProviderCreate is the body of the roflmarket.ProviderCreate call.
+type ProviderCreate struct {
+	Nodes          []signature.PublicKey `json:"nodes"`
+	PaymentAddress PaymentAddress        `json:"payment_address"`
+	Offers         []*Offer              `json:"offers"`
+	Metadata       map[string]string     `json:"metadata,omitempty"`
+}
+
+// ProviderUpdate is the body of the roflmarket.ProviderUpdate call.
+type ProviderUpdate struct {
+	Provider       types.Address         `json:"provider"`
+	Nodes          []signature.PublicKey `json:"nodes"`
+	PaymentAddress PaymentAddress        `json:"payment_address"`
+	Metadata       map[string]string     `json:"metadata,omitempty"`
+}
+
+// ProviderUpdateOffers is the body of the roflmarket.ProviderUpdateOffers call.
+type ProviderUpdateOffers struct {
+	Provider types.Address `json:"provider"`
+	Add      []*Offer      `json:"add,omitempty"`
+	Update   []*Offer      `json:"update,omitempty"`
+	Remove   []OfferID     `json:"remove,omitempty"`
+}
+
+// ProviderRemove is the body of the roflmarket.ProviderRemove call.
+type ProviderRemove struct {
+	Provider types.Address `json:"provider"`
+}
+
+// InstanceCreate is the body of the roflmarket.InstanceCreate call.
+type InstanceCreate struct {
+	Provider  types.Address  `json:"provider"`
+	Offer     OfferID        `json:"offer"`
+	Admin     *types.Address `json:"admin,omitempty"`
+	Term      Term           `json:"term"`
+	TermCount uint64         `json:"term_count"`
+}
+
+// InstanceTopUp is the body of the roflmarket.InstanceTopUp call.
+type InstanceTopUp struct {
+	Provider  types.Address `json:"provider"`
+	ID        InstanceID    `json:"id"`
+	Term      Term          `json:"term"`
+	TermCount uint64        `json:"term_count"`
+}
+
+// InstanceCancel is the body of the roflmarket.InstanceCancel call.
+type InstanceCancel struct {
+	Provider types.Address `json:"provider"`
+	ID       InstanceID    `json:"id"`
+}
+
+// ProviderQuery is the body of the roflmarket.Provider query.
+type ProviderQuery struct {
+	Provider types.Address `json:"provider"`
+}
+
+// OfferQuery is the body of the roflmarket.Offer query.
+type OfferQuery struct {
+	Provider types.Address `json:"provider"`
+	ID       OfferID       `json:"id"`
+}
+
+// InstanceQuery is the body of the roflmarket.Instance query.
+type InstanceQuery struct {
+	Provider types.Address `json:"provider"`
+	ID       InstanceID    `json:"id"`
+}
+
+// ModuleName is the roflmarket module name.
+const ModuleName = "roflmarket"
+
+const (
+	// ProviderCreatedEventCode is the event code for the provider created event.
+	ProviderCreatedEventCode = 1
+	// ProviderUpdatedEventCode is the event code for the provider updated event.
+	ProviderUpdatedEventCode = 2
+	// ProviderRemovedEventCode is the event code for the provider removed event.
+	ProviderRemovedEventCode = 3
+	// InstanceCreatedEventCode is the event code for the instance created event.
+	InstanceCreatedEventCode = 4
+	// InstanceUpdatedEventCode is the event code for the instance updated event.
+	InstanceUpdatedEventCode = 5
+	// InstanceAcceptedEventCode is the event code for the instance accepted event.
+	InstanceAcceptedEventCode = 6
+	// InstanceCancelledEventCode is the event code for the instance cancelled event.
+	InstanceCancelledEventCode = 7
+	// InstanceRemovedEventCode is the event code for the instance removed event.
+	InstanceRemovedEventCode = 8
+)
+
+// ProviderEvent is an event concerning a provider.
+type ProviderEvent struct {
+	Address types.Address `json:"address"`
+}
+
+// InstanceEvent is an event concerning an instance.
+type InstanceEvent struct {
+	Provider types.Address `json:"provider"`
+	ID       InstanceID    `json:"id"`
+}
+
+// Event is a roflmarket module event.
+type Event struct {
+	ProviderCreated   *ProviderEvent `json:"provider_created,omitempty"`
+	ProviderUpdated   *ProviderEvent `json:"provider_updated,omitempty"`
+	ProviderRemoved   *ProviderEvent `json:"provider_removed,omitempty"`
+	InstanceCreated   *InstanceEvent `json:"instance_created,omitempty"`
+	InstanceUpdated   *InstanceEvent `json:"instance_updated,omitempty"`
+	InstanceAccepted  *InstanceEvent `json:"instance_accepted,omitempty"`
+	InstanceCancelled *InstanceEvent `json:"instance_cancelled,omitempty"`
+	InstanceRemoved   *InstanceEvent `json:"instance_removed,omitempty"`
+}
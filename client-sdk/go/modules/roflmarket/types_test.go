@@ -0,0 +1,31 @@
+package roflmarket
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+)
+
+func TestIdentifierSerialization(t *testing.T) {
+	require := require.New(t)
+
+	id := InstanceID{0, 0, 0, 0, 0, 0, 0x01, 0x02}
+	require.Equal("0000000000000102", id.String())
+
+	var dec InstanceID
+	require.NoError(cbor.Unmarshal(cbor.Marshal(id), &dec), "cbor round trip")
+	require.Equal(id, dec)
+
+	raw, err := json.Marshal(&InstanceCancel{ID: id})
+	require.NoError(err, "json.Marshal")
+	var cancel InstanceCancel
+	require.NoError(json.Unmarshal(raw, &cancel), "json.Unmarshal")
+	require.Equal(id, cancel.ID)
+
+	var offer OfferID
+	require.Error(offer.UnmarshalBinary([]byte{1, 2, 3}), "malformed identifier")
+	require.Error(offer.UnmarshalText([]byte("zz")), "malformed identifier")
+}
@@ -0,0 +1,273 @@
+// Package processor implements a framework for processing the events of a runtime exactly once.
+//
+// The processor follows the blocks of a runtime and hands the data of each round to a handler in
+// a transaction of a persistent store, which records the processed block as part of the same
+// transaction. When the handler makes its effects in that transaction, e.g. in the same SQL
+// database as the store, each round is thus handled exactly once, even across process restarts:
+// a round whose transaction was not committed is handled again from scratch.
+//
+// The processor verifies that processed blocks form a chain, so that it never silently skips
+// rounds or resumes on another chain, e.g. after a node was reset to another network:
+//
+//   - On start, the block at the stored position must have the stored hash.
+//   - Each processed block must link to the previously processed block.
+//   - Rounds following the stored position must still be available on the node.
+//
+// Violations are reported with ErrChainMismatch and ErrRoundsUnavailable, and require operator
+// intervention. Other errors, like failed handlers and node connection failures, are retried with
+// exponential backoff, and the processor resubscribes to blocks when the node restarts.
+//
+// Rounds of blocks without transactions of their own, i.e. failed rounds, epoch transitions and
+// blocks recording the suspension of the runtime, advance the position without being handed to
+// the handler. While the runtime is suspended no blocks are produced, and processing continues
+// with the first block after it resumes.
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/indexer"
+)
+
+const (
+	// DefaultInitialBackoff is the default delay before the first retry after a failure.
+	DefaultInitialBackoff = time.Second
+	// DefaultMaxBackoff is the default maximum delay between retries after failures.
+	DefaultMaxBackoff = 5 * time.Minute
+)
+
+var (
+	// ErrChainMismatch is the error returned when the blocks of the node do not form a chain with
+	// the processed blocks.
+	ErrChainMismatch = errors.New("processor: chain mismatch")
+	// ErrRoundsUnavailable is the error returned when rounds following the last processed round
+	// are no longer available on the node, e.g. because they have been pruned.
+	ErrRoundsUnavailable = errors.New("processor: rounds unavailable")
+
+	errWatcherClosed = errors.New("processor: block watcher closed")
+)
+
+// Handler handles the data of a round in the given store transaction. In case it returns an error,
+// the transaction is rolled back and the round is handled again later.
+type Handler func(ctx context.Context, tx Tx, data *indexer.RoundData) error
+
+// Config is the processor configuration.
+type Config struct {
+	// Handler is the handler of rounds.
+	Handler Handler
+	// Store is the store of the position. If nil, an in-memory store is used.
+	Store Store
+	// StartRound is the first round to process in case the store is empty. If zero, processing
+	// starts with the latest round.
+	StartRound uint64
+
+	// InitialBackoff is the delay before the first retry after a failure. If zero,
+	// DefaultInitialBackoff is used.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum delay between retries after failures. If zero, DefaultMaxBackoff
+	// is used.
+	MaxBackoff time.Duration
+
+	// OnError, if not nil, is called for each failure that is retried, e.g. for logging.
+	OnError func(err error)
+}
+
+// Processor processes the rounds of a runtime.
+type Processor struct {
+	rc  client.RuntimeClient
+	cfg Config
+}
+
+// Run processes all rounds following the last processed round, and then the rounds of new blocks
+// as they are finalized, until the context is canceled or a chain inconsistency is detected.
+func (p *Processor) Run(ctx context.Context) error {
+	backoff := p.cfg.InitialBackoff
+	for {
+		err := p.run(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.Is(err, ErrChainMismatch), errors.Is(err, ErrRoundsUnavailable):
+			return err
+		case errors.Is(err, errWatcherClosed):
+			// The node restarted, so resubscribe immediately.
+			backoff = p.cfg.InitialBackoff
+			continue
+		}
+		if p.cfg.OnError != nil {
+			p.cfg.OnError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > p.cfg.MaxBackoff {
+			backoff = p.cfg.MaxBackoff
+		}
+	}
+}
+
+// run processes rounds until an error occurs.
+func (p *Processor) run(ctx context.Context) error {
+	blkCh, blkSub, err := p.rc.WatchBlocks(ctx)
+	if err != nil {
+		return fmt.Errorf("processor: failed to watch blocks: %w", err)
+	}
+	defer blkSub.Close()
+
+	pos, err := p.cfg.Store.Load(ctx)
+	if err != nil {
+		return err
+	}
+	if pos != nil {
+		if err = p.verify(ctx, pos); err != nil {
+			return err
+		}
+	}
+
+	latest, err := p.rc.GetBlock(ctx, client.RoundLatest)
+	if err != nil {
+		return fmt.Errorf("processor: failed to fetch latest block: %w", err)
+	}
+	if pos, err = p.processUpTo(ctx, pos, latest.Header.Round); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case blk, ok := <-blkCh:
+			if !ok {
+				return errWatcherClosed
+			}
+			if pos, err = p.processUpTo(ctx, pos, blk.Block.Header.Round); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// verify checks that the block at the given position is still part of the chain of the node and
+// that the following rounds are available.
+func (p *Processor) verify(ctx context.Context, pos *Position) error {
+	blk, err := p.rc.GetBlock(ctx, pos.Round)
+	switch {
+	case err == nil:
+		if h := blk.Header.EncodedHash(); !h.Equal(&pos.BlockHash) {
+			return fmt.Errorf("%w: block %d has hash %s, processed %s", ErrChainMismatch, pos.Round, h, pos.BlockHash)
+		}
+		return nil
+	case !isNotFound(err):
+		return fmt.Errorf("processor: failed to fetch block %d: %w", pos.Round, err)
+	}
+
+	// The block at the position is not available, e.g. because it has been pruned, so processing
+	// can only continue if the next block is, and only its link can be verified. The blocks are
+	// checked directly, as the retained rounds are only reported by the control API of the node.
+	latest, err := p.rc.GetBlock(ctx, client.RoundLatest)
+	if err != nil {
+		return fmt.Errorf("processor: failed to fetch latest block: %w", err)
+	}
+	if latest.Header.Round < pos.Round {
+		return fmt.Errorf("processor: latest round %d is behind processed round %d", latest.Header.Round, pos.Round)
+	}
+	if _, err = p.rc.GetBlock(ctx, pos.Round+1); err != nil {
+		if isNotFound(err) {
+			return fmt.Errorf("%w: rounds %d and %d not found", ErrRoundsUnavailable, pos.Round, pos.Round+1)
+		}
+		return fmt.Errorf("processor: failed to fetch block %d: %w", pos.Round+1, err)
+	}
+	return nil
+}
+
+// isNotFound returns whether the given error reports that a block was not found. Errors of the
+// node only carry the gRPC status code in this case.
+func isNotFound(err error) bool {
+	return errors.Is(err, roothash.ErrNotFound) || status.Code(err) == codes.NotFound
+}
+
+// processUpTo processes all rounds following the given position up to the given round, returning
+// the new position.
+func (p *Processor) processUpTo(ctx context.Context, pos *Position, round uint64) (*Position, error) {
+	next := round
+	switch {
+	case pos != nil:
+		next = pos.Round + 1
+	case p.cfg.StartRound != 0:
+		next = p.cfg.StartRound
+	}
+
+	for ; next <= round; next++ {
+		var err error
+		if pos, err = p.ProcessRound(ctx, pos, next); err != nil {
+			return nil, err
+		}
+	}
+	return pos, nil
+}
+
+// ProcessRound processes the given round following the given position, which is nil in case no
+// round has been processed yet, and returns the new position.
+func (p *Processor) ProcessRound(ctx context.Context, pos *Position, round uint64) (*Position, error) {
+	blk, err := p.rc.GetBlock(ctx, round)
+	if err != nil {
+		return nil, fmt.Errorf("processor: failed to fetch block %d: %w", round, err)
+	}
+	if pos != nil && !blk.Header.PreviousHash.Equal(&pos.BlockHash) {
+		return nil, fmt.Errorf("%w: block %d does not follow processed block %d", ErrChainMismatch, round, pos.Round)
+	}
+
+	var data *indexer.RoundData
+	if blk.Header.HeaderType == block.Normal {
+		if data, err = indexer.FetchRound(ctx, p.rc, round); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := p.cfg.Store.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() //nolint: errcheck
+
+	if data != nil {
+		if err = p.cfg.Handler(ctx, tx, data); err != nil {
+			return nil, fmt.Errorf("processor: failed to handle round %d: %w", round, err)
+		}
+	}
+	next := &Position{Round: round, BlockHash: blk.Header.EncodedHash()}
+	if err = tx.Commit(ctx, next); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// New creates a new processor of the rounds of the runtime of the given client.
+func New(rc client.RuntimeClient, cfg Config) (*Processor, error) {
+	if cfg.Handler == nil {
+		return nil, fmt.Errorf("processor: no handler configured")
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+	if cfg.InitialBackoff == 0 {
+		cfg.InitialBackoff = DefaultInitialBackoff
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+	return &Processor{rc: rc, cfg: cfg}, nil
+}
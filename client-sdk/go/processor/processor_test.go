@@ -0,0 +1,222 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/indexer"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/fakeruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func nativeUnits(amount uint64) types.BaseUnits {
+	return types.NewBaseUnits(*quantity.NewFromUint64(amount), types.NativeDenomination)
+}
+
+// testClient prunes the blocks of rounds before the last retained round and overrides the header
+// types of blocks of the wrapped client.
+type testClient struct {
+	client.RuntimeClient
+
+	lastRetainedRound uint64
+	suspendedRound    uint64
+}
+
+func (c *testClient) GetBlock(ctx context.Context, round uint64) (*block.Block, error) {
+	if round != client.RoundLatest && round < c.lastRetainedRound {
+		// The node only reports the status code of errors.
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	blk, err := c.RuntimeClient.GetBlock(ctx, round)
+	if err != nil || round != c.suspendedRound {
+		return blk, err
+	}
+	suspended := *blk
+	suspended.Header.HeaderType = block.Suspended
+	return &suspended, nil
+}
+
+func setup(ctx context.Context, t *testing.T) (*fakeruntime.Runtime, func(nonce uint64) uint64) {
+	rt := fakeruntime.New(fakeruntime.Config{})
+	rt.SetBalance(sdkTesting.Alice.Address, nativeUnits(1000))
+	transfer := func(nonce uint64) uint64 {
+		tb := accounts.NewV1(rt).Transfer(sdkTesting.Bob.Address, nativeUnits(10))
+		tb.AppendAuthSignature(sdkTesting.Alice.SigSpec, nonce)
+		require.NoError(t, tb.AppendSign(ctx, sdkTesting.Alice.Signer), "AppendSign")
+		meta, err := tb.SubmitTxMeta(ctx, nil)
+		require.NoError(t, err, "SubmitTxMeta")
+		return meta.Round
+	}
+	return rt, transfer
+}
+
+func TestProcessor(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	rt, transfer := setup(ctx, t)
+
+	var (
+		mu       sync.Mutex
+		handled  []uint64
+		attempts int
+		failures int
+	)
+	handler := func(ctx context.Context, tx Tx, data *indexer.RoundData) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Fail the first attempt to exercise retries.
+		if attempts++; attempts == 1 {
+			return fmt.Errorf("try again")
+		}
+		require.Len(data.Transactions, 1, "round %d should have a transaction", data.Block.Round)
+		handled = append(handled, data.Block.Round)
+		return nil
+	}
+	store := NewMemoryStore()
+	run := func(last uint64) {
+		runCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		p, err := New(rt, Config{
+			Handler:        handler,
+			Store:          store,
+			StartRound:     1,
+			InitialBackoff: 10 * time.Millisecond,
+			OnError:        func(err error) { failures++ },
+		})
+		require.NoError(err, "New")
+
+		errCh := make(chan error, 1)
+		go func() { errCh <- p.Run(runCtx) }()
+		require.Eventually(func() bool {
+			pos, loadErr := store.Load(ctx)
+			return loadErr == nil && pos != nil && pos.Round >= last
+		}, 5*time.Second, 10*time.Millisecond, "all rounds should be processed")
+		cancel()
+		require.ErrorIs(<-errCh, context.Canceled)
+	}
+
+	first := transfer(0)
+	second := transfer(1)
+	run(second)
+
+	// Restarting with the same store should only process new rounds.
+	third := transfer(2)
+	run(third)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(1, failures, "failed handler should be retried")
+	require.Equal([]uint64{first, second, third}, handled, "each round should be handled exactly once")
+
+	_, err := New(rt, Config{})
+	require.Error(err, "processor without a handler")
+}
+
+func TestProcessorChainChecks(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	rt, transfer := setup(ctx, t)
+	first := transfer(0)
+	transfer(1)
+
+	handler := func(ctx context.Context, tx Tx, data *indexer.RoundData) error { return nil }
+	newStore := func(pos *Position) Store {
+		store := NewMemoryStore()
+		tx, err := store.Begin(ctx)
+		require.NoError(err, "Begin")
+		require.NoError(tx.Commit(ctx, pos), "Commit")
+		return store
+	}
+	blk, err := rt.GetBlock(ctx, first)
+	require.NoError(err, "GetBlock")
+
+	// A position that is not part of the chain must not be resumed.
+	var otherHash hash.Hash
+	otherHash.FromBytes([]byte("other"))
+	p, err := New(rt, Config{Handler: handler, Store: newStore(&Position{Round: first, BlockHash: otherHash})})
+	require.NoError(err, "New")
+	require.ErrorIs(p.Run(ctx), ErrChainMismatch)
+
+	// Neither can a position whose following rounds have been pruned.
+	rc := &testClient{RuntimeClient: rt, lastRetainedRound: first + 2}
+	pos := &Position{Round: first, BlockHash: blk.Header.EncodedHash()}
+	p, err = New(rc, Config{Handler: handler, Store: newStore(pos)})
+	require.NoError(err, "New")
+	require.ErrorIs(p.Run(ctx), ErrRoundsUnavailable)
+
+	// A pruned position can be resumed as long as the next round is available.
+	rc.lastRetainedRound = first + 1
+	require.NoError(p.verify(ctx, pos), "verify")
+
+	// A position beyond the latest round of the node is retried.
+	err = p.verify(ctx, &Position{Round: first + 100, BlockHash: otherHash})
+	require.Error(err, "verify")
+	require.NotErrorIs(err, ErrRoundsUnavailable)
+
+	// Blocks must link to the processed block.
+	_, err = p.ProcessRound(ctx, &Position{Round: first, BlockHash: otherHash}, first+1)
+	require.ErrorIs(err, ErrChainMismatch)
+}
+
+func TestProcessorSuspension(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	rt, transfer := setup(ctx, t)
+	first := transfer(0)
+	second := transfer(1)
+
+	var handled []uint64
+	rc := &testClient{RuntimeClient: rt, suspendedRound: second}
+	p, err := New(rc, Config{
+		Handler: func(ctx context.Context, tx Tx, data *indexer.RoundData) error {
+			handled = append(handled, data.Block.Round)
+			return nil
+		},
+	})
+	require.NoError(err, "New")
+
+	pos, err := p.ProcessRound(ctx, nil, first)
+	require.NoError(err, "ProcessRound")
+	require.Equal(first, pos.Round)
+	pos, err = p.ProcessRound(ctx, pos, second)
+	require.NoError(err, "ProcessRound")
+	require.Equal(second, pos.Round, "suspension should advance the position")
+	require.Equal([]uint64{first}, handled, "suspension should not be handled")
+}
+
+func TestFileStore(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "position")
+
+	store := NewFileStore(path)
+	pos, err := store.Load(ctx)
+	require.NoError(err, "Load")
+	require.Nil(pos, "empty store should have no position")
+
+	expected := &Position{Round: 42, BlockHash: hash.NewFromBytes([]byte("block"))}
+	tx, err := store.Begin(ctx)
+	require.NoError(err, "Begin")
+	require.NoError(tx.Commit(ctx, expected), "Commit")
+
+	pos, err = NewFileStore(path).Load(ctx)
+	require.NoError(err, "Load")
+	require.Equal(expected, pos, "position should persist")
+}
@@ -0,0 +1,246 @@
+package processor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/indexer"
+)
+
+// Position is the last processed block.
+type Position struct {
+	// Round is the round of the block.
+	Round uint64
+	// BlockHash is the hash of the block header.
+	BlockHash hash.Hash
+}
+
+// Tx is a store transaction in which a round is processed.
+type Tx interface {
+	// Commit records the given position and commits the transaction.
+	Commit(ctx context.Context, pos *Position) error
+
+	// Rollback aborts the transaction. It is a no-op after the transaction has been committed.
+	Rollback() error
+}
+
+// Store persists the position of the processor.
+//
+// Rounds are processed in store transactions, so that the effects of handlers are committed
+// atomically with the position in case they are made in the same transaction, e.g. via SQLTx.
+type Store interface {
+	// Load returns the last committed position, or nil if no round has been processed yet.
+	Load(ctx context.Context) (*Position, error)
+
+	// Begin starts a new transaction.
+	Begin(ctx context.Context) (Tx, error)
+}
+
+type memoryStore struct {
+	sync.Mutex
+
+	pos *Position
+}
+
+type memoryTx struct {
+	s *memoryStore
+}
+
+func (tx *memoryTx) Commit(ctx context.Context, pos *Position) error {
+	tx.s.Lock()
+	defer tx.s.Unlock()
+
+	p := *pos
+	tx.s.pos = &p
+	return nil
+}
+
+func (tx *memoryTx) Rollback() error {
+	return nil
+}
+
+func (s *memoryStore) Load(ctx context.Context) (*Position, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.pos == nil {
+		return nil, nil
+	}
+	pos := *s.pos
+	return &pos, nil
+}
+
+func (s *memoryStore) Begin(ctx context.Context) (Tx, error) {
+	return &memoryTx{s: s}, nil
+}
+
+// NewMemoryStore creates a new store that is kept in memory and is therefore lost when the
+// process exits.
+func NewMemoryStore() Store {
+	return &memoryStore{}
+}
+
+type fileStore struct {
+	path string
+}
+
+type fileTx struct {
+	s *fileStore
+}
+
+func (tx *fileTx) Commit(ctx context.Context, pos *Position) error {
+	// Write to a temporary file first so that a failure does not corrupt the existing position.
+	data := fmt.Sprintf("%d %s\n", pos.Round, pos.BlockHash.Hex())
+	tmpPath := tx.s.path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, []byte(data), 0o600); err != nil {
+		return fmt.Errorf("processor: failed to store position: %w", err)
+	}
+	if err := os.Rename(tmpPath, tx.s.path); err != nil {
+		return fmt.Errorf("processor: failed to store position: %w", err)
+	}
+	return nil
+}
+
+func (tx *fileTx) Rollback() error {
+	return nil
+}
+
+func (s *fileStore) Load(ctx context.Context) (*Position, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(s.path))
+	switch {
+	case os.IsNotExist(err):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("processor: failed to load position: %w", err)
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("processor: malformed position")
+	}
+	round, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("processor: malformed position: %w", err)
+	}
+	pos := &Position{Round: round}
+	if err = pos.BlockHash.UnmarshalHex(fields[1]); err != nil {
+		return nil, fmt.Errorf("processor: malformed position: %w", err)
+	}
+	return pos, nil
+}
+
+func (s *fileStore) Begin(ctx context.Context) (Tx, error) {
+	return &fileTx{s: s}, nil
+}
+
+// NewFileStore creates a new store persisted in the file at the given path.
+//
+// Effects of handlers are not part of its transactions, so a round is handled again in case the
+// process exits after the handler succeeded but before the position was stored.
+func NewFileStore(path string) Store {
+	return &fileStore{path: path}
+}
+
+const sqlSchema = `CREATE TABLE IF NOT EXISTS processor_positions (
+	name TEXT PRIMARY KEY,
+	round BIGINT NOT NULL,
+	block_hash TEXT NOT NULL
+)`
+
+type sqlStore struct {
+	db      *sql.DB
+	dialect indexer.Dialect
+	name    string
+}
+
+type sqlTx struct {
+	s  *sqlStore
+	tx *sql.Tx
+}
+
+func (tx *sqlTx) Commit(ctx context.Context, pos *Position) error {
+	if _, err := tx.tx.ExecContext(ctx, tx.s.dialect.Rebind(
+		"INSERT INTO processor_positions (name, round, block_hash) VALUES (?, ?, ?) "+
+			"ON CONFLICT (name) DO UPDATE SET round = excluded.round, block_hash = excluded.block_hash",
+	), tx.s.name, int64(pos.Round), pos.BlockHash.Hex()); err != nil {
+		return fmt.Errorf("processor: failed to store position: %w", err)
+	}
+	if err := tx.tx.Commit(); err != nil {
+		return fmt.Errorf("processor: failed to commit: %w", err)
+	}
+	return nil
+}
+
+func (tx *sqlTx) Rollback() error {
+	if err := tx.tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		return err
+	}
+	return nil
+}
+
+func (s *sqlStore) Load(ctx context.Context) (*Position, error) {
+	var (
+		round     int64
+		blockHash string
+	)
+	err := s.db.QueryRowContext(ctx,
+		s.dialect.Rebind("SELECT round, block_hash FROM processor_positions WHERE name = ?"),
+		s.name,
+	).Scan(&round, &blockHash)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("processor: failed to load position: %w", err)
+	}
+
+	pos := &Position{Round: uint64(round)}
+	if err = pos.BlockHash.UnmarshalHex(blockHash); err != nil {
+		return nil, fmt.Errorf("processor: malformed position: %w", err)
+	}
+	return pos, nil
+}
+
+func (s *sqlStore) Begin(ctx context.Context) (Tx, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("processor: failed to begin transaction: %w", err)
+	}
+	return &sqlTx{s: s, tx: tx}, nil
+}
+
+// NewSQLStore creates a new store backed by the given SQL database, creating the schema if it
+// does not exist yet. Multiple processors can share a database by using distinct names.
+//
+// Handlers can make their effects in the transaction of the store, obtained with SQLTx, so that
+// they are committed atomically with the position.
+func NewSQLStore(ctx context.Context, db *sql.DB, dialect indexer.Dialect, name string) (Store, error) {
+	switch dialect {
+	case indexer.DialectPostgres, indexer.DialectSQLite:
+	default:
+		return nil, fmt.Errorf("processor: unsupported SQL dialect %d", dialect)
+	}
+	if _, err := db.ExecContext(ctx, sqlSchema); err != nil {
+		return nil, fmt.Errorf("processor: failed to create schema: %w", err)
+	}
+	return &sqlStore{db: db, dialect: dialect, name: name}, nil
+}
+
+// SQLTx returns the SQL transaction of a transaction of a store created by NewSQLStore, or nil for
+// transactions of other stores.
+func SQLTx(tx Tx) *sql.Tx {
+	if stx, ok := tx.(*sqlTx); ok {
+		return stx.tx
+	}
+	return nil
+}
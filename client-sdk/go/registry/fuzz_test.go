@@ -0,0 +1,60 @@
+//go:build go1.18
+// +build go1.18
+
+package registry_test
+
+import (
+	"testing"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/consensusaccounts"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/contracts"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/core"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/evm"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/rewards"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/rofl"
+	_ "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/roflmarket"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func FuzzDecodeTransaction(f *testing.F) {
+	amount := types.NewBaseUnits(*quantity.NewFromUint64(1000), types.NativeDenomination)
+	tx := accounts.NewTransferTx(nil, &accounts.Transfer{To: sdkTesting.Bob.Address, Amount: amount})
+	f.Add(cbor.Marshal(tx))
+	for _, m := range registry.Modules() {
+		for _, method := range m.Methods {
+			if method.Kind != registry.MethodKindCall {
+				continue
+			}
+			f.Add(cbor.Marshal(types.NewTransaction(nil, method.Name, nil)))
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _, _, _ = registry.DecodeTransaction(&types.UnverifiedTransaction{Body: data})
+
+		var call types.Call
+		if err := cbor.Unmarshal(data, &call); err == nil {
+			_, _, _ = registry.DecodeCall(&call)
+		}
+	})
+}
+
+func FuzzDecodeEvent(f *testing.F) {
+	amount := types.NewBaseUnits(*quantity.NewFromUint64(1000), types.NativeDenomination)
+	f.Add(accounts.ModuleName, uint32(accounts.MintEventCode), cbor.Marshal(&accounts.MintEvent{Owner: sdkTesting.Alice.Address, Amount: amount}))
+	for _, m := range registry.Modules() {
+		for code := uint32(1); code <= 4; code++ {
+			f.Add(m.Name, code, []byte{0xa0})
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, module string, code uint32, value []byte) {
+		_, _ = registry.DecodeEvent(&types.Event{Module: module, Code: code, Value: value})
+	})
+}
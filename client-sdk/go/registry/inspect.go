@@ -0,0 +1,87 @@
+package registry
+
+import (
+	"reflect"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+var (
+	addressType   = reflect.TypeOf(types.Address{})
+	baseUnitsType = reflect.TypeOf(types.BaseUnits{})
+)
+
+// Addresses returns the distinct addresses contained in the given decoded value, e.g. a method
+// body or an event, in the order they are found.
+func Addresses(v interface{}) []types.Address {
+	var addrs []types.Address
+	walk(reflect.ValueOf(v), func(v reflect.Value) bool {
+		if v.Type() != addressType {
+			return false
+		}
+		addrs = AppendAddress(addrs, v.Interface().(types.Address))
+		return true
+	})
+	return addrs
+}
+
+// AppendAddress appends the given address to the given addresses unless it is already contained.
+//
+// Values contain few addresses, so a linear search is cheaper than allocating a set.
+func AppendAddress(addrs []types.Address, addr types.Address) []types.Address {
+	for i := range addrs {
+		if addrs[i] == addr {
+			return addrs
+		}
+	}
+	return append(addrs, addr)
+}
+
+// Amounts returns the amounts contained in the given decoded value, e.g. a method body or an
+// event, in the order they are found.
+func Amounts(v interface{}) []types.BaseUnits {
+	var amounts []types.BaseUnits
+	walk(reflect.ValueOf(v), func(v reflect.Value) bool {
+		if v.Type() != baseUnitsType {
+			return false
+		}
+		amounts = append(amounts, v.Interface().(types.BaseUnits))
+		return true
+	})
+	return amounts
+}
+
+// walk calls visit for all values reachable from the given value via exported fields, pointers,
+// interfaces, arrays, slices and maps. Values for which visit returns true are not descended into.
+func walk(v reflect.Value, visit func(v reflect.Value) bool) {
+	if !v.IsValid() || visit(v) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			walk(v.Elem(), visit)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				walk(v.Field(i), visit)
+			}
+		}
+	case reflect.Array, reflect.Slice:
+		// Byte strings are opaque.
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			walk(v.Index(i), visit)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			walk(iter.Key(), visit)
+			walk(iter.Value(), visit)
+		}
+	}
+}
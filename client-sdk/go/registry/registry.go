@@ -0,0 +1,255 @@
+// Package registry provides a central registry of runtime module metadata.
+//
+// Module clients register the methods they support, together with the types of their bodies and
+// results, and their event decoders. Tools can then decode arbitrary transactions, results and
+// events without keeping their own per-module switch statements.
+package registry
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// MethodKind is the kind of a module method.
+type MethodKind uint8
+
+const (
+	// MethodKindCall is a method that can be invoked via a transaction.
+	MethodKindCall MethodKind = 0
+	// MethodKindQuery is a method that can be invoked via a query.
+	MethodKindQuery MethodKind = 1
+)
+
+// String returns a string representation of the method kind.
+func (k MethodKind) String() string {
+	switch k {
+	case MethodKindCall:
+		return "call"
+	case MethodKindQuery:
+		return "query"
+	default:
+		return "[unknown method kind]"
+	}
+}
+
+// Method describes a module method.
+type Method struct {
+	// Name is the full method name (e.g., "accounts.Transfer").
+	Name string
+	// Kind is the kind of the method.
+	Kind MethodKind
+	// Body is an example value of the method body type or nil if the method takes no arguments.
+	Body interface{}
+	// Result is an example value of the method result type or nil if the method returns nothing.
+	Result interface{}
+}
+
+// DecodeBody decodes the given CBOR-encoded method body into a newly allocated value of the
+// registered body type.
+func (m *Method) DecodeBody(raw []byte) (interface{}, error) {
+	return decodeAs(m.Body, raw)
+}
+
+// DecodeResult decodes the given CBOR-encoded method result into a newly allocated value of the
+// registered result type.
+func (m *Method) DecodeResult(raw []byte) (interface{}, error) {
+	return decodeAs(m.Result, raw)
+}
+
+func decodeAs(example interface{}, raw []byte) (interface{}, error) {
+	if example == nil {
+		return nil, nil
+	}
+	t := reflect.TypeOf(example)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	v := reflect.New(t)
+	if err := cbor.Unmarshal(raw, v.Interface()); err != nil {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
+
+// Event describes a module event.
+type Event struct {
+	// Code is the event code.
+	Code uint32
+	// Name is the event name (e.g., "Transfer").
+	Name string
+	// Value is an example value of the event value type.
+	Value interface{}
+}
+
+// DecodeValue decodes the given CBOR-encoded event value into a newly allocated value of the
+// registered value type.
+func (e *Event) DecodeValue(raw []byte) (interface{}, error) {
+	return decodeAs(e.Value, raw)
+}
+
+// Module describes a runtime module.
+type Module struct {
+	// Name is the module name.
+	Name string
+	// Methods are the methods supported by the module.
+	Methods []*Method
+	// Events are the events emitted by the module. Events whose codes are not fixed by the
+	// module, like contract events, are not included.
+	Events []*Event
+	// EventDecoder is the decoder for the module's events. It may be nil if the module does not
+	// emit any events.
+	EventDecoder client.EventDecoder
+}
+
+var (
+	lock    sync.RWMutex
+	modules = make(map[string]*Module)
+	methods = make(map[string]*Method)
+
+	// decoders are the event decoders of all registered modules sorted by module name, and
+	// moduleDecoders are the event decoders by module name. They are rebuilt on registration so
+	// that decoding events does not allocate.
+	decoders       []client.EventDecoder
+	moduleDecoders = make(map[string]client.EventDecoder)
+)
+
+// Register registers the given module.
+//
+// This is meant to be called from the init function of module client packages and panics if the
+// module or any of its methods are already registered.
+func Register(m *Module) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if _, exists := modules[m.Name]; exists {
+		panic(fmt.Sprintf("registry: module '%s' already registered", m.Name))
+	}
+	for _, method := range m.Methods {
+		if _, exists := methods[method.Name]; exists {
+			panic(fmt.Sprintf("registry: method '%s' already registered", method.Name))
+		}
+	}
+
+	modules[m.Name] = m
+	for _, method := range m.Methods {
+		methods[method.Name] = method
+	}
+	if m.EventDecoder != nil {
+		moduleDecoders[m.Name] = m.EventDecoder
+		// Replace the slice instead of modifying it, as DecodeEvent uses it without the lock.
+		decoders = nil
+		for _, m := range sortedModules() {
+			if m.EventDecoder != nil {
+				decoders = append(decoders, m.EventDecoder)
+			}
+		}
+	}
+}
+
+func sortedModules() []*Module {
+	ms := make([]*Module, 0, len(modules))
+	for _, m := range modules {
+		ms = append(ms, m)
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i].Name < ms[j].Name })
+	return ms
+}
+
+// Modules returns all registered modules sorted by name.
+func Modules() []*Module {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	return sortedModules()
+}
+
+// LookupModule returns the registered module with the given name.
+func LookupModule(name string) (*Module, bool) {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	m, ok := modules[name]
+	return m, ok
+}
+
+// LookupMethod returns the registered method with the given name.
+func LookupMethod(name string) (*Method, bool) {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	m, ok := methods[name]
+	return m, ok
+}
+
+// EventDecoders returns the event decoders of all registered modules.
+func EventDecoders() []client.EventDecoder {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	return append([]client.EventDecoder{}, decoders...)
+}
+
+// DecodeEvent decodes the given event using the registered event decoders.
+//
+// The decoder of the module named by the event is tried first, so that decoding is cheap for the
+// common case of modules emitting events under their own name. In case no registered decoder
+// recognizes the event, `nil, nil` is returned.
+func DecodeEvent(event *types.Event) (client.DecodedEvent, error) {
+	lock.RLock()
+	decoder := moduleDecoders[event.Module]
+	all := decoders
+	lock.RUnlock()
+
+	if decoder != nil {
+		ev, err := decoder.DecodeEvent(event)
+		if err != nil || ev != nil {
+			return ev, err
+		}
+	}
+	for _, d := range all {
+		ev, err := d.DecodeEvent(event)
+		if err != nil {
+			return nil, err
+		}
+		if ev != nil {
+			return ev, nil
+		}
+	}
+	return nil, nil
+}
+
+// DecodeCall decodes the body of the given plain call using the registered method metadata.
+func DecodeCall(call *types.Call) (*Method, interface{}, error) {
+	if call.Format != types.CallFormatPlain {
+		return nil, nil, fmt.Errorf("registry: cannot decode call with format %d", call.Format)
+	}
+	method, ok := LookupMethod(call.Method)
+	if !ok {
+		return nil, nil, fmt.Errorf("registry: unknown method '%s'", call.Method)
+	}
+	body, err := method.DecodeBody(call.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("registry: malformed body for method '%s': %w", call.Method, err)
+	}
+	return method, body, nil
+}
+
+// DecodeTransaction decodes the call body of the given unverified transaction.
+func DecodeTransaction(utx *types.UnverifiedTransaction) (*types.Transaction, *Method, interface{}, error) {
+	var tx types.Transaction
+	if err := cbor.Unmarshal(utx.Body, &tx); err != nil {
+		return nil, nil, nil, fmt.Errorf("registry: malformed transaction: %w", err)
+	}
+	method, body, err := DecodeCall(&tx.Call)
+	if err != nil {
+		return &tx, nil, nil, err
+	}
+	return &tx, method, body, nil
+}
@@ -0,0 +1,139 @@
+package registry_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func TestLookup(t *testing.T) {
+	require := require.New(t)
+
+	m, ok := registry.LookupModule(accounts.ModuleName)
+	require.True(ok, "accounts module should be registered")
+	require.NotNil(m.EventDecoder)
+	require.Len(m.Events, 3)
+	require.EqualValues(accounts.TransferEventCode, m.Events[0].Code)
+
+	mint := &accounts.MintEvent{Owner: sdkTesting.Alice.Address, Amount: types.NewBaseUnits(*quantity.NewFromUint64(1), types.NativeDenomination)}
+	value, err := m.Events[2].DecodeValue(cbor.Marshal(mint))
+	require.NoError(err, "DecodeValue")
+	require.Equal(mint, value)
+
+	method, ok := registry.LookupMethod("accounts.Transfer")
+	require.True(ok, "accounts.Transfer should be registered")
+	require.Equal(registry.MethodKindCall, method.Kind)
+
+	method, ok = registry.LookupMethod("accounts.Nonce")
+	require.True(ok, "accounts.Nonce should be registered")
+	require.Equal(registry.MethodKindQuery, method.Kind)
+
+	_, ok = registry.LookupMethod("accounts.NonExistent")
+	require.False(ok, "unknown method should not be registered")
+
+	require.Panics(func() {
+		registry.Register(&registry.Module{Name: accounts.ModuleName})
+	}, "duplicate registration should panic")
+}
+
+func TestDecodeTransaction(t *testing.T) {
+	require := require.New(t)
+
+	amount := types.NewBaseUnits(*quantity.NewFromUint64(1000), types.NativeDenomination)
+	tx := accounts.NewTransferTx(nil, &accounts.Transfer{To: sdkTesting.Bob.Address, Amount: amount})
+	utx := types.UnverifiedTransaction{Body: cbor.Marshal(tx)}
+
+	_, method, body, err := registry.DecodeTransaction(&utx)
+	require.NoError(err, "DecodeTransaction")
+	require.Equal("accounts.Transfer", method.Name)
+	xfer, ok := body.(*accounts.Transfer)
+	require.True(ok, "body should be decoded as a transfer")
+	require.Equal(sdkTesting.Bob.Address, xfer.To)
+	require.Equal(amount, xfer.Amount)
+
+	result, err := method.DecodeResult(cbor.Marshal(nil))
+	require.NoError(err, "DecodeResult")
+	require.Nil(result, "transfers have no result")
+
+	tx = types.NewTransaction(nil, "unknown.Method", nil)
+	utx = types.UnverifiedTransaction{Body: cbor.Marshal(tx)}
+	_, _, _, err = registry.DecodeTransaction(&utx)
+	require.Error(err, "unknown methods should fail to decode")
+}
+
+func TestDecodeEvent(t *testing.T) {
+	require := require.New(t)
+
+	amount := types.NewBaseUnits(*quantity.NewFromUint64(1000), types.NativeDenomination)
+	ev, err := registry.DecodeEvent(&types.Event{
+		Module: accounts.ModuleName,
+		Code:   accounts.MintEventCode,
+		Value:  cbor.Marshal(&accounts.MintEvent{Owner: sdkTesting.Alice.Address, Amount: amount}),
+	})
+	require.NoError(err, "DecodeEvent")
+	require.NotNil(ev)
+	require.Equal(sdkTesting.Alice.Address, ev.(*accounts.Event).Mint.Owner)
+
+	ev, err = registry.DecodeEvent(&types.Event{Module: "unknown", Code: 1})
+	require.NoError(err, "DecodeEvent")
+	require.Nil(ev, "unknown events should not be decoded")
+}
+
+func BenchmarkDecodeEvent(b *testing.B) {
+	ev := &types.Event{
+		Module: accounts.ModuleName,
+		Code:   accounts.TransferEventCode,
+		Value: cbor.Marshal(&accounts.TransferEvent{
+			From:   sdkTesting.Alice.Address,
+			To:     sdkTesting.Bob.Address,
+			Amount: types.NewBaseUnits(*quantity.NewFromUint64(1000), types.NativeDenomination),
+		}),
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		decoded, err := registry.DecodeEvent(ev)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = registry.Addresses(decoded)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	require := require.New(t)
+
+	amount := types.NewBaseUnits(*quantity.NewFromUint64(1000), types.NativeDenomination)
+	v := &struct {
+		To       *types.Address
+		Owners   []types.Address
+		Balances map[types.Address]types.BaseUnits
+		Transfer *accounts.TransferEvent
+		Data     []byte
+		ignored  types.Address
+	}{
+		To:       &sdkTesting.Alice.Address,
+		Owners:   []types.Address{sdkTesting.Bob.Address, sdkTesting.Alice.Address},
+		Balances: map[types.Address]types.BaseUnits{sdkTesting.Charlie.Address: amount},
+		Transfer: &accounts.TransferEvent{From: sdkTesting.Alice.Address, To: sdkTesting.Dave.Address, Amount: amount},
+		ignored:  sdkTesting.Erin.Address,
+	}
+	require.Equal([]types.Address{
+		sdkTesting.Alice.Address,
+		sdkTesting.Bob.Address,
+		sdkTesting.Charlie.Address,
+		sdkTesting.Dave.Address,
+	}, registry.Addresses(v))
+	require.Equal([]types.BaseUnits{amount, amount}, registry.Amounts(v))
+
+	require.Empty(registry.Addresses(nil))
+	require.Empty(registry.Amounts(nil))
+}
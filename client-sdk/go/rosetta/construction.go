@@ -0,0 +1,451 @@
+package rosetta
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	coreSignature "github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/secp256k1"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/core"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// Curve and signature types.
+const (
+	CurveEdwards25519 = "edwards25519"
+	CurveSecp256k1    = "secp256k1"
+
+	SignatureEd25519 = "ed25519"
+	SignatureECDSA   = "ecdsa"
+)
+
+// transfer is a transfer described by construction operations.
+type transfer struct {
+	From   types.Address
+	To     types.Address
+	Amount types.BaseUnits
+}
+
+func (s *Server) constructionDerive(ctx context.Context, body []byte) (interface{}, error) {
+	var req ConstructionDeriveRequest
+	if err := s.decodeRequest(body, &req, &req.NetworkIdentifier); err != nil {
+		return nil, err
+	}
+	spec, _, err := parsePublicKey(req.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &ConstructionDeriveResponse{
+		AccountIdentifier: &AccountIdentifier{Address: types.NewAddress(spec).String()},
+	}, nil
+}
+
+func (s *Server) constructionPreprocess(ctx context.Context, body []byte) (interface{}, error) {
+	var req ConstructionPreprocessRequest
+	if err := s.decodeRequest(body, &req, &req.NetworkIdentifier); err != nil {
+		return nil, err
+	}
+	xfer, err := s.parseTransfer(req.Operations)
+	if err != nil {
+		return nil, err
+	}
+	options := &Options{
+		From:   xfer.From.String(),
+		To:     xfer.To.String(),
+		Amount: xfer.Amount.Amount.String(),
+	}
+	if !xfer.Amount.Denomination.IsNative() {
+		options.Denomination = string(xfer.Amount.Denomination)
+	}
+	return &ConstructionPreprocessResponse{
+		Options:            options,
+		RequiredPublicKeys: []*AccountIdentifier{{Address: options.From}},
+	}, nil
+}
+
+func (s *Server) constructionMetadata(ctx context.Context, body []byte) (interface{}, error) {
+	var req ConstructionMetadataRequest
+	if err := s.decodeRequest(body, &req, &req.NetworkIdentifier); err != nil {
+		return nil, err
+	}
+	if req.Options == nil {
+		return nil, ErrMalformedRequest.withDetails(fmt.Errorf("missing options"))
+	}
+	xfer, err := parseOptions(req.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := accounts.NewV1(s.rc).Nonce(ctx, client.RoundLatest, xfer.From)
+	if err != nil {
+		return nil, err
+	}
+	gas := s.cfg.GasLimit
+	if gas == 0 {
+		// Estimating the gas needs the signer, as signature verification costs gas.
+		spec, pkErr := findSigner(req.PublicKeys, xfer.From)
+		if pkErr != nil {
+			return nil, pkErr
+		}
+		tx := accounts.NewTransferTx(&types.Fee{}, &accounts.Transfer{To: xfer.To, Amount: xfer.Amount})
+		tx.AppendAuthSignature(spec, nonce)
+		if gas, err = core.NewV1(s.rc).EstimateGas(ctx, client.RoundLatest, tx); err != nil {
+			return nil, err
+		}
+	}
+	mgp, err := core.NewV1(s.rc).MinGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	price := mgp[types.NativeDenomination]
+	fee := price.Clone()
+	if err = fee.Mul(quantity.NewFromUint64(gas)); err != nil {
+		return nil, fmt.Errorf("failed to compute fee: %w", err)
+	}
+
+	return &ConstructionMetadataResponse{
+		Metadata: &Metadata{
+			Nonce:     nonce,
+			FeeGas:    gas,
+			FeeAmount: fee.String(),
+		},
+		SuggestedFee: []*Amount{newAmount(fee, &s.cfg.Currency, false)},
+	}, nil
+}
+
+func (s *Server) constructionPayloads(ctx context.Context, body []byte) (interface{}, error) {
+	var req ConstructionPayloadsRequest
+	if err := s.decodeRequest(body, &req, &req.NetworkIdentifier); err != nil {
+		return nil, err
+	}
+	if req.Metadata == nil {
+		return nil, ErrMalformedRequest.withDetails(fmt.Errorf("missing metadata"))
+	}
+	xfer, err := s.parseTransfer(req.Operations)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := findSigner(req.PublicKeys, xfer.From)
+	if err != nil {
+		return nil, err
+	}
+	var fee quantity.Quantity
+	if err = fee.UnmarshalText([]byte(req.Metadata.FeeAmount)); err != nil {
+		return nil, ErrMalformedRequest.withDetails(fmt.Errorf("malformed fee amount: %w", err))
+	}
+
+	tx := accounts.NewTransferTx(&types.Fee{
+		Amount: types.NewBaseUnits(fee, types.NativeDenomination),
+		Gas:    req.Metadata.FeeGas,
+	}, &accounts.Transfer{To: xfer.To, Amount: xfer.Amount})
+	tx.AppendAuthSignature(spec, req.Metadata.Nonce)
+	raw := cbor.Marshal(tx)
+
+	payload, sigType, err := s.signingPayload(&spec, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &ConstructionPayloadsResponse{
+		UnsignedTransaction: hex.EncodeToString(raw),
+		Payloads: []*SigningPayload{{
+			AccountIdentifier: &AccountIdentifier{Address: xfer.From.String()},
+			HexBytes:          hex.EncodeToString(payload),
+			SignatureType:     sigType,
+		}},
+	}, nil
+}
+
+func (s *Server) constructionCombine(ctx context.Context, body []byte) (interface{}, error) {
+	var req ConstructionCombineRequest
+	if err := s.decodeRequest(body, &req, &req.NetworkIdentifier); err != nil {
+		return nil, err
+	}
+	raw, tx, err := decodeUnsignedTx(req.UnsignedTransaction)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Signatures) != 1 || len(tx.AuthInfo.SignerInfo) != 1 {
+		return nil, ErrInvalidSignature.withDetails(fmt.Errorf("exactly one signature is required"))
+	}
+	sig, err := decodeHex("signature", req.Signatures[0].HexBytes)
+	if err != nil {
+		return nil, err
+	}
+	if req.Signatures[0].SignatureType == SignatureECDSA {
+		// Rosetta ECDSA signatures are r || s, while the SDK uses the DER encoding.
+		if len(sig) != 64 {
+			return nil, ErrInvalidSignature.withDetails(fmt.Errorf("malformed ECDSA signature"))
+		}
+		der := &btcec.Signature{R: new(big.Int).SetBytes(sig[:32]), S: new(big.Int).SetBytes(sig[32:])}
+		sig = der.Serialize()
+	}
+
+	utx := &types.UnverifiedTransaction{
+		Body:       raw,
+		AuthProofs: []types.AuthProof{{Signature: sig}},
+	}
+	if _, err = utx.Verify(s.cfg.ChainContext); err != nil {
+		return nil, ErrInvalidSignature.withDetails(err)
+	}
+	return &ConstructionCombineResponse{SignedTransaction: hex.EncodeToString(cbor.Marshal(utx))}, nil
+}
+
+func (s *Server) constructionParse(ctx context.Context, body []byte) (interface{}, error) {
+	var req ConstructionParseRequest
+	if err := s.decodeRequest(body, &req, &req.NetworkIdentifier); err != nil {
+		return nil, err
+	}
+	var tx *types.Transaction
+	if req.Signed {
+		_, utx, err := decodeSignedTx(req.Transaction)
+		if err != nil {
+			return nil, err
+		}
+		tx = new(types.Transaction)
+		if err = cbor.Unmarshal(utx.Body, tx); err != nil {
+			return nil, ErrInvalidTransaction.withDetails(err)
+		}
+	} else {
+		var err error
+		if _, tx, err = decodeUnsignedTx(req.Transaction); err != nil {
+			return nil, err
+		}
+	}
+
+	if tx.Call.Method != "accounts.Transfer" || len(tx.AuthInfo.SignerInfo) == 0 {
+		return nil, ErrInvalidTransaction.withDetails(fmt.Errorf("not a transfer"))
+	}
+	var xferBody accounts.Transfer
+	if err := cbor.Unmarshal(tx.Call.Body, &xferBody); err != nil {
+		return nil, ErrInvalidTransaction.withDetails(err)
+	}
+	from, err := tx.AuthInfo.SignerInfo[0].AddressSpec.Address()
+	if err != nil {
+		return nil, ErrInvalidTransaction.withDetails(err)
+	}
+	currency := s.currency(xferBody.Amount.Denomination)
+	if currency == nil {
+		return nil, ErrInvalidTransaction.withDetails(fmt.Errorf("unsupported denomination %s", xferBody.Amount.Denomination))
+	}
+
+	rsp := &ConstructionParseResponse{
+		Operations: transferOperations(from, xferBody.To, &xferBody.Amount.Amount, currency),
+	}
+	if req.Signed {
+		rsp.AccountIdentifierSigners = []*AccountIdentifier{{Address: from.String()}}
+	}
+	return rsp, nil
+}
+
+func (s *Server) constructionHash(ctx context.Context, body []byte) (interface{}, error) {
+	var req ConstructionHashRequest
+	if err := s.decodeRequest(body, &req, &req.NetworkIdentifier); err != nil {
+		return nil, err
+	}
+	raw, _, err := decodeSignedTx(req.SignedTransaction)
+	if err != nil {
+		return nil, err
+	}
+	return &TransactionIdentifierResponse{
+		TransactionIdentifier: &TransactionIdentifier{Hash: hash.NewFromBytes(raw).Hex()},
+	}, nil
+}
+
+func (s *Server) constructionSubmit(ctx context.Context, body []byte) (interface{}, error) {
+	var req ConstructionSubmitRequest
+	if err := s.decodeRequest(body, &req, &req.NetworkIdentifier); err != nil {
+		return nil, err
+	}
+	raw, utx, err := decodeSignedTx(req.SignedTransaction)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.rc.SubmitTxNoWait(ctx, utx); err != nil {
+		return nil, ErrSubmitFailed.withDetails(err)
+	}
+	return &TransactionIdentifierResponse{
+		TransactionIdentifier: &TransactionIdentifier{Hash: hash.NewFromBytes(raw).Hex()},
+	}, nil
+}
+
+// parseTransfer parses the operations of a transfer: a Transfer operation debiting the sender and
+// one crediting the recipient with the same amount.
+func (s *Server) parseTransfer(ops []*Operation) (*transfer, error) {
+	if len(ops) != 2 {
+		return nil, ErrInvalidOperations.withDetails(fmt.Errorf("expected 2 operations, got %d", len(ops)))
+	}
+	var (
+		xfer      transfer
+		from, to  *Operation
+		amountStr string
+	)
+	for _, op := range ops {
+		if op.Type != OpTransfer || op.Account == nil || op.Amount == nil {
+			return nil, ErrInvalidOperations.withDetails(fmt.Errorf("expected Transfer operations with account and amount"))
+		}
+		if strings.HasPrefix(op.Amount.Value, "-") {
+			from = op
+		} else {
+			to = op
+			amountStr = op.Amount.Value
+		}
+	}
+	if from == nil || to == nil || from.Amount.Value != "-"+amountStr {
+		return nil, ErrInvalidOperations.withDetails(fmt.Errorf("expected a debit and a credit of the same amount"))
+	}
+	if from.Amount.Currency == nil || to.Amount.Currency == nil || *from.Amount.Currency != *to.Amount.Currency {
+		return nil, ErrInvalidOperations.withDetails(fmt.Errorf("currencies do not match"))
+	}
+	denomination, ok := s.denomination(to.Amount.Currency)
+	if !ok {
+		return nil, ErrInvalidOperations.withDetails(fmt.Errorf("unsupported currency %s", to.Amount.Currency.Symbol))
+	}
+
+	var err error
+	if xfer.From, err = parseAddress(from.Account.Address); err != nil {
+		return nil, err
+	}
+	if xfer.To, err = parseAddress(to.Account.Address); err != nil {
+		return nil, err
+	}
+	var amount quantity.Quantity
+	if err = amount.UnmarshalText([]byte(amountStr)); err != nil {
+		return nil, ErrInvalidOperations.withDetails(fmt.Errorf("malformed amount: %w", err))
+	}
+	xfer.Amount = types.NewBaseUnits(amount, denomination)
+	return &xfer, nil
+}
+
+func parseOptions(o *Options) (*transfer, error) {
+	var (
+		xfer transfer
+		err  error
+	)
+	if xfer.From, err = parseAddress(o.From); err != nil {
+		return nil, err
+	}
+	if xfer.To, err = parseAddress(o.To); err != nil {
+		return nil, err
+	}
+	var amount quantity.Quantity
+	if err = amount.UnmarshalText([]byte(o.Amount)); err != nil {
+		return nil, ErrMalformedRequest.withDetails(fmt.Errorf("malformed amount: %w", err))
+	}
+	xfer.Amount = types.NewBaseUnits(amount, types.Denomination(o.Denomination))
+	return &xfer, nil
+}
+
+// transferOperations returns the operations describing a transfer, without status, as used by the
+// Construction API.
+func transferOperations(from, to types.Address, amount *types.Quantity, currency *Currency) []*Operation {
+	debit := &OperationIdentifier{Index: 0}
+	return []*Operation{
+		{
+			OperationIdentifier: debit,
+			Type:                OpTransfer,
+			Account:             &AccountIdentifier{Address: from.String()},
+			Amount:              newAmount(amount, currency, true),
+		},
+		{
+			OperationIdentifier: &OperationIdentifier{Index: 1},
+			RelatedOperations:   []*OperationIdentifier{debit},
+			Type:                OpTransfer,
+			Account:             &AccountIdentifier{Address: to.String()},
+			Amount:              newAmount(amount, currency, false),
+		},
+	}
+}
+
+// parsePublicKey parses a public key into its address specification and signature type.
+func parsePublicKey(pk *PublicKey) (types.SignatureAddressSpec, string, error) {
+	if pk == nil {
+		return types.SignatureAddressSpec{}, "", ErrInvalidPublicKey.withDetails(fmt.Errorf("missing public key"))
+	}
+	raw, err := hex.DecodeString(pk.HexBytes)
+	if err != nil {
+		return types.SignatureAddressSpec{}, "", ErrInvalidPublicKey.withDetails(err)
+	}
+	switch pk.CurveType {
+	case CurveEdwards25519:
+		var key ed25519.PublicKey
+		if err = key.UnmarshalBinary(raw); err != nil {
+			return types.SignatureAddressSpec{}, "", ErrInvalidPublicKey.withDetails(err)
+		}
+		return types.NewSignatureAddressSpecEd25519(key), SignatureEd25519, nil
+	case CurveSecp256k1:
+		var key secp256k1.PublicKey
+		if err = key.UnmarshalBinary(raw); err != nil {
+			return types.SignatureAddressSpec{}, "", ErrInvalidPublicKey.withDetails(err)
+		}
+		return types.NewSignatureAddressSpecSecp256k1Eth(key), SignatureECDSA, nil
+	default:
+		return types.SignatureAddressSpec{}, "", ErrInvalidPublicKey.withDetails(fmt.Errorf("unsupported curve %s", pk.CurveType))
+	}
+}
+
+// findSigner returns the address specification of the public key deriving the given address.
+func findSigner(pks []*PublicKey, address types.Address) (types.SignatureAddressSpec, error) {
+	for _, pk := range pks {
+		spec, _, err := parsePublicKey(pk)
+		if err != nil {
+			return types.SignatureAddressSpec{}, err
+		}
+		if types.NewAddress(spec).Equal(address) {
+			return spec, nil
+		}
+	}
+	return types.SignatureAddressSpec{}, ErrInvalidPublicKey.withDetails(fmt.Errorf("no public key of %s given", address))
+}
+
+// signingPayload returns the message to be signed over the given transaction by the given
+// signer, i.e. the hash of the transaction and its signature context, as signed by SDK signers.
+func (s *Server) signingPayload(spec *types.SignatureAddressSpec, raw []byte) ([]byte, string, error) {
+	sigCtx := s.cfg.ChainContext.New(types.SignatureContextBase)
+	switch {
+	case spec.Ed25519 != nil:
+		payload, err := coreSignature.PrepareSignerMessage(coreSignature.Context(sigCtx), raw)
+		return payload, SignatureEd25519, err
+	case spec.Secp256k1Eth != nil:
+		payload, err := secp256k1.PrepareSignerMessage(signature.Context(sigCtx), raw)
+		return payload, SignatureECDSA, err
+	default:
+		return nil, "", ErrInvalidPublicKey.withDetails(fmt.Errorf("unsupported signer"))
+	}
+}
+
+func decodeUnsignedTx(s string) ([]byte, *types.Transaction, error) {
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, nil, ErrInvalidTransaction.withDetails(err)
+	}
+	var tx types.Transaction
+	if err = cbor.Unmarshal(raw, &tx); err != nil {
+		return nil, nil, ErrInvalidTransaction.withDetails(err)
+	}
+	return raw, &tx, nil
+}
+
+func decodeSignedTx(s string) ([]byte, *types.UnverifiedTransaction, error) {
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, nil, ErrInvalidTransaction.withDetails(err)
+	}
+	var utx types.UnverifiedTransaction
+	if err = cbor.Unmarshal(raw, &utx); err != nil {
+		return nil, nil, ErrInvalidTransaction.withDetails(err)
+	}
+	return raw, &utx, nil
+}
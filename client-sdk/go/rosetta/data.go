@@ -0,0 +1,335 @@
+package rosetta
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func (s *Server) networkStatus(ctx context.Context, body []byte) (interface{}, error) {
+	var req NetworkRequest
+	if err := s.decodeRequest(body, &req, &req.NetworkIdentifier); err != nil {
+		return nil, err
+	}
+	latest, err := s.rc.GetBlock(ctx, client.RoundLatest)
+	if err != nil {
+		return nil, err
+	}
+	genesis, err := s.rc.GetGenesisBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rsp := &NetworkStatusResponse{
+		CurrentBlockIdentifier: blockIdentifier(latest),
+		CurrentBlockTimestamp:  int64(latest.Header.Timestamp) * 1000,
+		GenesisBlockIdentifier: blockIdentifier(genesis),
+		Peers:                  []*Peer{},
+	}
+	// Nodes may prune old rounds, in which case the oldest block is the last retained one.
+	if status, stErr := s.rc.GetStatus(ctx); stErr == nil && status.LastRetainedRound > genesis.Header.Round {
+		if oldest, blkErr := s.rc.GetBlock(ctx, status.LastRetainedRound); blkErr == nil {
+			rsp.OldestBlockIdentifier = blockIdentifier(oldest)
+		}
+	}
+	return rsp, nil
+}
+
+func (s *Server) block(ctx context.Context, body []byte) (interface{}, error) {
+	var req BlockRequest
+	if err := s.decodeRequest(body, &req, &req.NetworkIdentifier); err != nil {
+		return nil, err
+	}
+	blk, err := s.getBlock(ctx, req.BlockIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	txs, err := s.blockTransactions(ctx, blk)
+	if err != nil {
+		return nil, err
+	}
+
+	parent := &BlockIdentifier{
+		Index: int64(blk.Header.Round) - 1,
+		Hash:  blk.Header.PreviousHash.Hex(),
+	}
+	if blk.Header.Round == 0 {
+		// The genesis block is its own parent, as required by the specification.
+		parent = blockIdentifier(blk)
+	}
+	return &BlockResponse{
+		Block: &Block{
+			BlockIdentifier:       blockIdentifier(blk),
+			ParentBlockIdentifier: parent,
+			Timestamp:             int64(blk.Header.Timestamp) * 1000,
+			Transactions:          txs,
+		},
+	}, nil
+}
+
+func (s *Server) blockTransaction(ctx context.Context, body []byte) (interface{}, error) {
+	var req BlockTransactionRequest
+	if err := s.decodeRequest(body, &req, &req.NetworkIdentifier); err != nil {
+		return nil, err
+	}
+	if req.BlockIdentifier == nil || req.TransactionIdentifier == nil {
+		return nil, ErrMalformedRequest.withDetails(fmt.Errorf("missing block or transaction identifier"))
+	}
+	index := req.BlockIdentifier.Index
+	blk, err := s.getBlock(ctx, &PartialBlockIdentifier{Index: &index, Hash: &req.BlockIdentifier.Hash})
+	if err != nil {
+		return nil, err
+	}
+	txs, err := s.blockTransactions(ctx, blk)
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range txs {
+		if tx.TransactionIdentifier.Hash == req.TransactionIdentifier.Hash {
+			return &BlockTransactionResponse{Transaction: tx}, nil
+		}
+	}
+	return nil, ErrTransactionNotFound
+}
+
+func (s *Server) accountBalance(ctx context.Context, body []byte) (interface{}, error) {
+	var req AccountBalanceRequest
+	if err := s.decodeRequest(body, &req, &req.NetworkIdentifier); err != nil {
+		return nil, err
+	}
+	if req.AccountIdentifier == nil {
+		return nil, ErrMalformedRequest.withDetails(fmt.Errorf("missing account identifier"))
+	}
+	address, err := parseAddress(req.AccountIdentifier.Address)
+	if err != nil {
+		return nil, err
+	}
+	blk, err := s.getBlock(ctx, req.BlockIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := accounts.NewV1(s.rc).Balances(ctx, blk.Header.Round, address)
+	if err != nil {
+		return nil, err
+	}
+
+	currencies := req.Currencies
+	if len(currencies) == 0 {
+		currencies = []*Currency{&s.cfg.Currency}
+		for _, denomination := range sortedDenominations(s.cfg.Denominations) {
+			currencies = append(currencies, s.cfg.Denominations[denomination])
+		}
+	}
+	rsp := &AccountBalanceResponse{
+		BlockIdentifier: blockIdentifier(blk),
+		Balances:        make([]*Amount, 0, len(currencies)),
+	}
+	for _, c := range currencies {
+		denomination, ok := s.denomination(c)
+		if !ok {
+			return nil, ErrMalformedRequest.withDetails(fmt.Errorf("unsupported currency %s", c.Symbol))
+		}
+		balance := balances.Balances[denomination]
+		rsp.Balances = append(rsp.Balances, &Amount{Value: balance.String(), Currency: c})
+	}
+	return rsp, nil
+}
+
+func (s *Server) mempool(ctx context.Context, body []byte) (interface{}, error) {
+	var req NetworkRequest
+	if err := s.decodeRequest(body, &req, &req.NetworkIdentifier); err != nil {
+		return nil, err
+	}
+	// The runtime client does not expose the transaction pool of the node.
+	return &MempoolResponse{TransactionIdentifiers: []*TransactionIdentifier{}}, nil
+}
+
+// getBlock returns the block with the given identifier, or the latest block if it is nil.
+func (s *Server) getBlock(ctx context.Context, id *PartialBlockIdentifier) (*block.Block, error) {
+	round := client.RoundLatest
+	switch {
+	case id == nil || (id.Index == nil && id.Hash == nil):
+	case id.Index == nil:
+		return nil, ErrBlockHashUnsupported
+	case *id.Index < 0:
+		return nil, ErrBlockNotFound
+	default:
+		round = uint64(*id.Index)
+	}
+	blk, err := s.rc.GetBlock(ctx, round)
+	if err != nil {
+		return nil, ErrBlockNotFound.withDetails(err)
+	}
+	if id != nil && id.Hash != nil && *id.Hash != blk.Header.EncodedHash().Hex() {
+		return nil, ErrBlockNotFound
+	}
+	return blk, nil
+}
+
+// blockTransactions returns the transactions of a block with their balance changes. Balance
+// changes outside of transactions, e.g. rewards, are reported as a transaction identified by the
+// hash of the block.
+func (s *Server) blockTransactions(ctx context.Context, blk *block.Block) ([]*Transaction, error) {
+	round := blk.Header.Round
+	txs, err := s.rc.GetTransactionsWithResults(ctx, round)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.rc.GetEventsRaw(ctx, round)
+	if err != nil {
+		return nil, err
+	}
+
+	// Count the events of the block, to find out which are not emitted by any transaction.
+	remaining := make(map[string]int)
+	for _, ev := range events {
+		remaining[eventKey(ev)]++
+	}
+
+	rsp := make([]*Transaction, 0, len(txs)+1)
+	for _, tx := range txs {
+		t := &Transaction{
+			TransactionIdentifier: &TransactionIdentifier{Hash: hash.NewFromBytes(cbor.Marshal(&tx.Tx)).Hex()},
+			Operations:            []*Operation{},
+		}
+		var decoded types.Transaction
+		if err = cbor.Unmarshal(tx.Tx.Body, &decoded); err == nil {
+			t.Metadata = map[string]interface{}{"method": decoded.Call.Method}
+			t.Operations = s.feeOperations(t.Operations, &decoded)
+		}
+		if !tx.Result.IsSuccess() && tx.Result.Failed != nil {
+			if t.Metadata == nil {
+				t.Metadata = make(map[string]interface{})
+			}
+			t.Metadata["error"] = tx.Result.Failed.Error()
+		}
+		for _, ev := range tx.Events {
+			remaining[eventKey(ev)]--
+			if t.Operations, err = s.eventOperations(t.Operations, ev); err != nil {
+				return nil, err
+			}
+		}
+		rsp = append(rsp, t)
+	}
+
+	blockTx := &Transaction{
+		TransactionIdentifier: &TransactionIdentifier{Hash: blk.Header.EncodedHash().Hex()},
+		Operations:            []*Operation{},
+	}
+	for _, ev := range events {
+		key := eventKey(ev)
+		if remaining[key] <= 0 {
+			continue
+		}
+		remaining[key]--
+		if blockTx.Operations, err = s.eventOperations(blockTx.Operations, ev); err != nil {
+			return nil, err
+		}
+	}
+	if len(blockTx.Operations) > 0 {
+		rsp = append(rsp, blockTx)
+	}
+	return rsp, nil
+}
+
+// feeOperations appends the operation debiting the fee of a transaction from its first signer.
+func (s *Server) feeOperations(ops []*Operation, tx *types.Transaction) []*Operation {
+	fee := tx.AuthInfo.Fee.Amount
+	if fee.Amount.IsZero() || len(tx.AuthInfo.SignerInfo) == 0 {
+		return ops
+	}
+	payer, err := tx.AuthInfo.SignerInfo[0].AddressSpec.Address()
+	if err != nil {
+		return ops
+	}
+	return s.appendOperation(ops, OpFee, payer, &fee, true, nil)
+}
+
+// eventOperations appends the operations corresponding to the balance changes of an event.
+func (s *Server) eventOperations(ops []*Operation, ev *types.Event) ([]*Operation, error) {
+	if ev.Module != accounts.ModuleName {
+		return ops, nil
+	}
+	decoded, err := accounts.NewV1(nil).DecodeEvent(ev)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode event: %w", err)
+	}
+	aev := decoded.(*accounts.Event)
+	switch {
+	case aev.Transfer != nil:
+		n := len(ops)
+		ops = s.appendOperation(ops, OpTransfer, aev.Transfer.From, &aev.Transfer.Amount, true, nil)
+		if len(ops) > n {
+			ops = s.appendOperation(ops, OpTransfer, aev.Transfer.To, &aev.Transfer.Amount, false, ops[n].OperationIdentifier)
+		}
+	case aev.Mint != nil:
+		ops = s.appendOperation(ops, OpMint, aev.Mint.Owner, &aev.Mint.Amount, false, nil)
+	case aev.Burn != nil:
+		ops = s.appendOperation(ops, OpBurn, aev.Burn.Owner, &aev.Burn.Amount, true, nil)
+	}
+	return ops, nil
+}
+
+// appendOperation appends a successful operation changing the balance of an account by the given
+// amount, unless the denomination of the amount has no currency.
+func (s *Server) appendOperation(ops []*Operation, opType string, address types.Address, amount *types.BaseUnits, debit bool, related *OperationIdentifier) []*Operation {
+	currency := s.currency(amount.Denomination)
+	if currency == nil {
+		return ops
+	}
+	status := OpStatusSuccess
+	op := &Operation{
+		OperationIdentifier: &OperationIdentifier{Index: int64(len(ops))},
+		Type:                opType,
+		Status:              &status,
+		Account:             &AccountIdentifier{Address: address.String()},
+		Amount:              newAmount(&amount.Amount, currency, debit),
+	}
+	if related != nil {
+		op.RelatedOperations = []*OperationIdentifier{related}
+	}
+	return append(ops, op)
+}
+
+func newAmount(q *types.Quantity, currency *Currency, debit bool) *Amount {
+	value := q.String()
+	if debit && !q.IsZero() {
+		value = "-" + value
+	}
+	return &Amount{Value: value, Currency: currency}
+}
+
+func blockIdentifier(blk *block.Block) *BlockIdentifier {
+	return &BlockIdentifier{
+		Index: int64(blk.Header.Round),
+		Hash:  blk.Header.EncodedHash().Hex(),
+	}
+}
+
+func eventKey(ev *types.Event) string {
+	return fmt.Sprintf("%s/%d/%x", ev.Module, ev.Code, ev.Value)
+}
+
+func sortedDenominations(m map[types.Denomination]*Currency) []types.Denomination {
+	denominations := make([]types.Denomination, 0, len(m))
+	for d := range m {
+		denominations = append(denominations, d)
+	}
+	sort.Slice(denominations, func(i, j int) bool { return denominations[i] < denominations[j] })
+	return denominations
+}
+
+func parseAddress(s string) (types.Address, error) {
+	var address types.Address
+	if err := address.UnmarshalText([]byte(s)); err != nil {
+		return address, ErrMalformedRequest.withDetails(fmt.Errorf("malformed address %s: %w", s, err))
+	}
+	return address, nil
+}
@@ -0,0 +1,274 @@
+// Package rosetta implements the Rosetta API for ParaTimes built with the SDK.
+//
+// The server implements the Data API (networks, blocks, balances) and the Construction API for
+// native token transfers via accounts.Transfer, on top of the runtime client. Balance changes of
+// blocks are reported as operations derived from the events of the accounts module, with the fee
+// of each transaction reported as a debit of its first signer. Fees are credited to the fee
+// accumulator of the runtime without an event, so the accounts fees are disbursed to cannot be
+// reconciled.
+//
+// Blocks can only be looked up by round, as the runtime client has no index of block hashes.
+//
+// Without a runtime client, the server runs in offline mode and only serves the endpoints of the
+// Construction API that do not need a node (derive, preprocess, payloads, combine, parse and
+// hash), e.g. on an air-gapped signing host.
+package rosetta
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/version"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const (
+	// RosettaVersion is the version of the Rosetta specification implemented by the server.
+	RosettaVersion = "1.4.10"
+
+	// Blockchain is the name of the blockchain in network identifiers.
+	Blockchain = "Oasis"
+
+	// maxRequestSize is the maximum size of a request body.
+	maxRequestSize = 1 << 20
+)
+
+// Operation types.
+const (
+	OpTransfer = "Transfer"
+	OpMint     = "Mint"
+	OpBurn     = "Burn"
+	OpFee      = "Fee"
+)
+
+// OpStatusSuccess is the status of all operations reported by the server, as operations are
+// derived from the balance changes that took effect.
+const OpStatusSuccess = "success"
+
+// Errors returned by the server.
+var (
+	ErrUnsupportedNetwork   = &Error{Code: 1, Message: "unsupported network"}
+	ErrMalformedRequest     = &Error{Code: 2, Message: "malformed request"}
+	ErrUnavailableOffline   = &Error{Code: 3, Message: "endpoint unavailable in offline mode"}
+	ErrNodeRequestFailed    = &Error{Code: 4, Message: "node request failed", Retriable: true}
+	ErrBlockNotFound        = &Error{Code: 5, Message: "block not found"}
+	ErrTransactionNotFound  = &Error{Code: 6, Message: "transaction not found"}
+	ErrInvalidOperations    = &Error{Code: 7, Message: "invalid operations"}
+	ErrInvalidPublicKey     = &Error{Code: 8, Message: "invalid public key"}
+	ErrInvalidTransaction   = &Error{Code: 9, Message: "invalid transaction"}
+	ErrInvalidSignature     = &Error{Code: 10, Message: "invalid signature"}
+	ErrSubmitFailed         = &Error{Code: 11, Message: "transaction submission failed"}
+	ErrBlockHashUnsupported = &Error{Code: 12, Message: "lookup of blocks by hash is not supported"}
+
+	allErrors = []*Error{
+		ErrUnsupportedNetwork,
+		ErrMalformedRequest,
+		ErrUnavailableOffline,
+		ErrNodeRequestFailed,
+		ErrBlockNotFound,
+		ErrTransactionNotFound,
+		ErrInvalidOperations,
+		ErrInvalidPublicKey,
+		ErrInvalidTransaction,
+		ErrInvalidSignature,
+		ErrSubmitFailed,
+		ErrBlockHashUnsupported,
+	}
+)
+
+// Config is the configuration of the server.
+type Config struct {
+	// Network is the name of the network in network identifiers, e.g. mainnet.
+	Network string
+	// ParaTime is the name of the ParaTime in the sub-network identifiers, e.g. emerald.
+	ParaTime string
+
+	// RuntimeID is the identifier of the ParaTime.
+	RuntimeID common.Namespace
+	// ChainContext is the chain context of the ParaTime, used to sign transactions. Both the
+	// identifier and the chain context are returned by the GetInfo method of the runtime client.
+	ChainContext signature.Context
+
+	// Currency is the currency of the native denomination.
+	Currency Currency
+	// Denominations are the currencies of other denominations by denomination. Balance changes in
+	// denominations without a currency are not reported.
+	Denominations map[types.Denomination]*Currency
+
+	// GasLimit is the gas limit of transfers. If zero, the gas is estimated by the node.
+	GasLimit uint64
+}
+
+// Server is the Rosetta API server. It implements http.Handler.
+type Server struct {
+	rc  client.RuntimeClient
+	cfg *Config
+
+	network *NetworkIdentifier
+
+	handlers map[string]handler
+}
+
+type handler func(ctx context.Context, body []byte) (interface{}, error)
+
+// New creates a new Rosetta API server for the ParaTime of the given runtime client. If the client
+// is nil, the server runs in offline mode.
+func New(rc client.RuntimeClient, cfg *Config) *Server {
+	s := &Server{
+		rc:  rc,
+		cfg: cfg,
+		network: &NetworkIdentifier{
+			Blockchain:           Blockchain,
+			Network:              cfg.Network,
+			SubNetworkIdentifier: &SubNetworkIdentifier{Network: cfg.ParaTime},
+		},
+	}
+	s.handlers = map[string]handler{
+		"/network/list":    s.networkList,
+		"/network/options": s.networkOptions,
+		"/network/status":  online(s, s.networkStatus),
+
+		"/block":             online(s, s.block),
+		"/block/transaction": online(s, s.blockTransaction),
+		"/account/balance":   online(s, s.accountBalance),
+		"/mempool":           online(s, s.mempool),
+
+		"/construction/derive":     s.constructionDerive,
+		"/construction/preprocess": s.constructionPreprocess,
+		"/construction/metadata":   online(s, s.constructionMetadata),
+		"/construction/payloads":   s.constructionPayloads,
+		"/construction/combine":    s.constructionCombine,
+		"/construction/parse":      s.constructionParse,
+		"/construction/hash":       s.constructionHash,
+		"/construction/submit":     online(s, s.constructionSubmit),
+	}
+	return s
+}
+
+// online wraps the handler of an endpoint that needs a node.
+func online(s *Server, h handler) handler {
+	return func(ctx context.Context, body []byte) (interface{}, error) {
+		if s.rc == nil {
+			return nil, ErrUnavailableOffline
+		}
+		return h(ctx, body)
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h, ok := s.handlers[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body json.RawMessage
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&body); err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrMalformedRequest.withDetails(err))
+		return
+	}
+	rsp, err := h(r.Context(), body)
+	if err != nil {
+		rErr, ok := err.(*Error)
+		if !ok {
+			rErr = ErrNodeRequestFailed.withDetails(err)
+		}
+		writeJSON(w, http.StatusInternalServerError, rErr)
+		return
+	}
+	writeJSON(w, http.StatusOK, rsp)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// decodeRequest decodes a request and checks the network identifier it contains.
+func (s *Server) decodeRequest(body []byte, req interface{}, network **NetworkIdentifier) error {
+	if err := json.Unmarshal(body, req); err != nil {
+		return ErrMalformedRequest.withDetails(err)
+	}
+	return s.checkNetwork(*network)
+}
+
+func (s *Server) checkNetwork(n *NetworkIdentifier) error {
+	switch {
+	case n == nil:
+		return ErrMalformedRequest.withDetails(fmt.Errorf("missing network identifier"))
+	case n.Blockchain != s.network.Blockchain, n.Network != s.network.Network,
+		n.SubNetworkIdentifier == nil, n.SubNetworkIdentifier.Network != s.network.SubNetworkIdentifier.Network:
+		return ErrUnsupportedNetwork
+	default:
+		return nil
+	}
+}
+
+func (s *Server) networkList(ctx context.Context, body []byte) (interface{}, error) {
+	return &NetworkListResponse{NetworkIdentifiers: []*NetworkIdentifier{s.network}}, nil
+}
+
+func (s *Server) networkOptions(ctx context.Context, body []byte) (interface{}, error) {
+	var req NetworkRequest
+	if err := s.decodeRequest(body, &req, &req.NetworkIdentifier); err != nil {
+		return nil, err
+	}
+	return &NetworkOptionsResponse{
+		Version: &Version{
+			RosettaVersion: RosettaVersion,
+			NodeVersion:    version.SoftwareVersion,
+		},
+		Allow: &Allow{
+			OperationStatuses:       []*OperationStatus{{Status: OpStatusSuccess, Successful: true}},
+			OperationTypes:          []string{OpTransfer, OpMint, OpBurn, OpFee},
+			Errors:                  allErrors,
+			HistoricalBalanceLookup: true,
+		},
+	}, nil
+}
+
+// currency returns the currency of the given denomination, or nil if it has none.
+func (s *Server) currency(denomination types.Denomination) *Currency {
+	if denomination.IsNative() {
+		return &s.cfg.Currency
+	}
+	return s.cfg.Denominations[denomination]
+}
+
+// denomination returns the denomination of the given currency.
+func (s *Server) denomination(c *Currency) (types.Denomination, bool) {
+	if c == nil {
+		return "", false
+	}
+	if *c == s.cfg.Currency {
+		return types.NativeDenomination, true
+	}
+	for denomination, dc := range s.cfg.Denominations {
+		if *c == *dc {
+			return denomination, true
+		}
+	}
+	return "", false
+}
+
+func decodeHex(what, s string) ([]byte, error) {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, ErrMalformedRequest.withDetails(fmt.Errorf("malformed %s: %w", what, err))
+	}
+	return data, nil
+}
@@ -0,0 +1,257 @@
+package rosetta
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	coreSignature "github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/core"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/fakeruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+var testCurrency = Currency{Symbol: "TEST", Decimals: 18}
+
+func call(t *testing.T, srv *httptest.Server, path string, req, rsp interface{}) *Error {
+	body, err := json.Marshal(req)
+	require.NoError(t, err, "Marshal")
+	res, err := srv.Client().Post(srv.URL+path, "application/json", bytes.NewReader(body))
+	require.NoError(t, err, "Post")
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err, "ReadAll")
+	if res.StatusCode != http.StatusOK {
+		var rspErr Error
+		require.NoError(t, json.Unmarshal(data, &rspErr), "malformed error: %s", data)
+		return &rspErr
+	}
+	require.NoError(t, json.Unmarshal(data, rsp), "malformed response: %s", data)
+	return nil
+}
+
+func TestRosetta(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rt := fakeruntime.New(fakeruntime.Config{
+		CoreParameters: core.Parameters{
+			MinGasPrice: map[types.Denomination]types.Quantity{
+				types.NativeDenomination: *quantity.NewFromUint64(1),
+			},
+		},
+	})
+	rt.SetBalance(sdkTesting.Alice.Address, types.NewBaseUnits(*quantity.NewFromUint64(1000), types.NativeDenomination))
+	info, err := rt.GetInfo(ctx)
+	require.NoError(err, "GetInfo")
+
+	cfg := &Config{
+		Network:      "testnet",
+		ParaTime:     "test",
+		RuntimeID:    info.ID,
+		ChainContext: info.ChainContext,
+		Currency:     testCurrency,
+		GasLimit:     100,
+	}
+	srv := httptest.NewServer(New(rt, cfg))
+	defer srv.Close()
+
+	var networks NetworkListResponse
+	require.Nil(call(t, srv, "/network/list", struct{}{}, &networks))
+	require.Len(networks.NetworkIdentifiers, 1)
+	network := networks.NetworkIdentifiers[0]
+	require.Equal("test", network.SubNetworkIdentifier.Network)
+
+	var options NetworkOptionsResponse
+	require.Nil(call(t, srv, "/network/options", &NetworkRequest{NetworkIdentifier: network}, &options))
+	require.Equal(RosettaVersion, options.Version.RosettaVersion)
+
+	// Construct, sign and submit a transfer.
+	pk, err := sdkTesting.Alice.SigSpec.Ed25519.MarshalBinary()
+	require.NoError(err, "MarshalBinary")
+	publicKey := &PublicKey{HexBytes: hex.EncodeToString(pk), CurveType: CurveEdwards25519}
+
+	var derived ConstructionDeriveResponse
+	require.Nil(call(t, srv, "/construction/derive", &ConstructionDeriveRequest{
+		NetworkIdentifier: network,
+		PublicKey:         publicKey,
+	}, &derived))
+	require.Equal(sdkTesting.Alice.Address.String(), derived.AccountIdentifier.Address)
+
+	ops := []*Operation{
+		{
+			OperationIdentifier: &OperationIdentifier{Index: 0},
+			Type:                OpTransfer,
+			Account:             &AccountIdentifier{Address: sdkTesting.Alice.Address.String()},
+			Amount:              &Amount{Value: "-30", Currency: &testCurrency},
+		},
+		{
+			OperationIdentifier: &OperationIdentifier{Index: 1},
+			Type:                OpTransfer,
+			Account:             &AccountIdentifier{Address: sdkTesting.Bob.Address.String()},
+			Amount:              &Amount{Value: "30", Currency: &testCurrency},
+		},
+	}
+	var preprocessed ConstructionPreprocessResponse
+	require.Nil(call(t, srv, "/construction/preprocess", &ConstructionPreprocessRequest{
+		NetworkIdentifier: network,
+		Operations:        ops,
+	}, &preprocessed))
+	require.Equal("30", preprocessed.Options.Amount)
+
+	var metadata ConstructionMetadataResponse
+	require.Nil(call(t, srv, "/construction/metadata", &ConstructionMetadataRequest{
+		NetworkIdentifier: network,
+		Options:           preprocessed.Options,
+		PublicKeys:        []*PublicKey{publicKey},
+	}, &metadata))
+	require.EqualValues(0, metadata.Metadata.Nonce)
+	require.Equal("100", metadata.Metadata.FeeAmount)
+	require.Equal("100", metadata.SuggestedFee[0].Value)
+
+	var payloads ConstructionPayloadsResponse
+	require.Nil(call(t, srv, "/construction/payloads", &ConstructionPayloadsRequest{
+		NetworkIdentifier: network,
+		Operations:        ops,
+		Metadata:          metadata.Metadata,
+		PublicKeys:        []*PublicKey{publicKey},
+	}, &payloads))
+	require.Len(payloads.Payloads, 1)
+	require.Equal(SignatureEd25519, payloads.Payloads[0].SignatureType)
+
+	var parsed ConstructionParseResponse
+	require.Nil(call(t, srv, "/construction/parse", &ConstructionParseRequest{
+		NetworkIdentifier: network,
+		Transaction:       payloads.UnsignedTransaction,
+	}, &parsed))
+	require.Len(parsed.Operations, 2)
+	require.Equal("-30", parsed.Operations[0].Amount.Value)
+	require.Empty(parsed.AccountIdentifierSigners)
+
+	payload, err := hex.DecodeString(payloads.Payloads[0].HexBytes)
+	require.NoError(err, "DecodeString")
+	// Payloads are signed as is, as done by Rosetta signers.
+	sk := sdkTesting.Alice.ConsensusSigner.(coreSignature.UnsafeSigner).UnsafeBytes()
+	sig := ed25519.Sign(ed25519.PrivateKey(sk), payload)
+	signature := &Signature{
+		SigningPayload: payloads.Payloads[0],
+		PublicKey:      publicKey,
+		SignatureType:  SignatureEd25519,
+		HexBytes:       hex.EncodeToString(sig),
+	}
+
+	var combined ConstructionCombineResponse
+	require.Nil(call(t, srv, "/construction/combine", &ConstructionCombineRequest{
+		NetworkIdentifier:   network,
+		UnsignedTransaction: payloads.UnsignedTransaction,
+		Signatures:          []*Signature{signature},
+	}, &combined))
+
+	require.Nil(call(t, srv, "/construction/parse", &ConstructionParseRequest{
+		NetworkIdentifier: network,
+		Signed:            true,
+		Transaction:       combined.SignedTransaction,
+	}, &parsed))
+	require.Equal([]*AccountIdentifier{{Address: sdkTesting.Alice.Address.String()}}, parsed.AccountIdentifierSigners)
+
+	var hashed, submitted TransactionIdentifierResponse
+	require.Nil(call(t, srv, "/construction/hash", &ConstructionHashRequest{
+		NetworkIdentifier: network,
+		SignedTransaction: combined.SignedTransaction,
+	}, &hashed))
+	require.Nil(call(t, srv, "/construction/submit", &ConstructionSubmitRequest{
+		NetworkIdentifier: network,
+		SignedTransaction: combined.SignedTransaction,
+	}, &submitted))
+	require.Equal(hashed, submitted)
+
+	// A tampered signature should be rejected.
+	sig[0] ^= 0xff
+	signature.HexBytes = hex.EncodeToString(sig)
+	rspErr := call(t, srv, "/construction/combine", &ConstructionCombineRequest{
+		NetworkIdentifier:   network,
+		UnsignedTransaction: payloads.UnsignedTransaction,
+		Signatures:          []*Signature{signature},
+	}, nil)
+	require.NotNil(rspErr)
+	require.Equal(ErrInvalidSignature.Code, rspErr.Code)
+
+	// The transfer should be reported in the block it was included in.
+	round := int64(1)
+	var block BlockResponse
+	require.Nil(call(t, srv, "/block", &BlockRequest{
+		NetworkIdentifier: network,
+		BlockIdentifier:   &PartialBlockIdentifier{Index: &round},
+	}, &block))
+	require.EqualValues(1, block.Block.BlockIdentifier.Index)
+	require.Len(block.Block.Transactions, 1)
+	tx := block.Block.Transactions[0]
+	require.Equal(submitted.TransactionIdentifier, tx.TransactionIdentifier)
+	require.Equal("accounts.Transfer", tx.Metadata["method"])
+	require.Len(tx.Operations, 3)
+	require.Equal(OpFee, tx.Operations[0].Type)
+	require.Equal("-100", tx.Operations[0].Amount.Value)
+	require.Equal(OpTransfer, tx.Operations[1].Type)
+	require.Equal(sdkTesting.Alice.Address.String(), tx.Operations[1].Account.Address)
+	require.Equal("-30", tx.Operations[1].Amount.Value)
+	require.Equal(sdkTesting.Bob.Address.String(), tx.Operations[2].Account.Address)
+	require.Equal("30", tx.Operations[2].Amount.Value)
+	require.Equal(OpStatusSuccess, *tx.Operations[2].Status)
+
+	var balance AccountBalanceResponse
+	require.Nil(call(t, srv, "/account/balance", &AccountBalanceRequest{
+		NetworkIdentifier: network,
+		AccountIdentifier: &AccountIdentifier{Address: sdkTesting.Alice.Address.String()},
+	}, &balance))
+	require.Equal("870", balance.Balances[0].Value)
+
+	// Errors.
+	hashOnly := block.Block.BlockIdentifier.Hash
+	rspErr = call(t, srv, "/block", &BlockRequest{
+		NetworkIdentifier: network,
+		BlockIdentifier:   &PartialBlockIdentifier{Hash: &hashOnly},
+	}, nil)
+	require.Equal(ErrBlockHashUnsupported.Code, rspErr.Code)
+	rspErr = call(t, srv, "/network/options", &NetworkRequest{NetworkIdentifier: &NetworkIdentifier{
+		Blockchain: Blockchain,
+		Network:    "mainnet",
+	}}, nil)
+	require.Equal(ErrUnsupportedNetwork.Code, rspErr.Code)
+}
+
+func TestOffline(t *testing.T) {
+	require := require.New(t)
+
+	srv := httptest.NewServer(New(nil, &Config{Network: "testnet", ParaTime: "test", Currency: testCurrency}))
+	defer srv.Close()
+	network := &NetworkIdentifier{
+		Blockchain:           Blockchain,
+		Network:              "testnet",
+		SubNetworkIdentifier: &SubNetworkIdentifier{Network: "test"},
+	}
+
+	pk, err := sdkTesting.Dave.SigSpec.Secp256k1Eth.MarshalBinary()
+	require.NoError(err, "MarshalBinary")
+	var derived ConstructionDeriveResponse
+	require.Nil(call(t, srv, "/construction/derive", &ConstructionDeriveRequest{
+		NetworkIdentifier: network,
+		PublicKey:         &PublicKey{HexBytes: hex.EncodeToString(pk), CurveType: CurveSecp256k1},
+	}, &derived))
+	require.Equal(sdkTesting.Dave.Address.String(), derived.AccountIdentifier.Address)
+
+	rspErr := call(t, srv, "/network/status", &NetworkRequest{NetworkIdentifier: network}, nil)
+	require.NotNil(rspErr)
+	require.Equal(ErrUnavailableOffline.Code, rspErr.Code)
+}
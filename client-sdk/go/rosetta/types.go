@@ -0,0 +1,338 @@
+package rosetta
+
+// The types below are the subset of the Rosetta API object model used by the server. Their JSON
+// encodings follow the Rosetta specification.
+
+// NetworkIdentifier identifies the network served.
+type NetworkIdentifier struct {
+	Blockchain           string                `json:"blockchain"`
+	Network              string                `json:"network"`
+	SubNetworkIdentifier *SubNetworkIdentifier `json:"sub_network_identifier,omitempty"`
+}
+
+// SubNetworkIdentifier identifies the ParaTime served.
+type SubNetworkIdentifier struct {
+	Network string `json:"network"`
+}
+
+// BlockIdentifier uniquely identifies a block.
+type BlockIdentifier struct {
+	Index int64  `json:"index"`
+	Hash  string `json:"hash"`
+}
+
+// PartialBlockIdentifier identifies a block by index or hash. If neither is given, it identifies
+// the latest block.
+type PartialBlockIdentifier struct {
+	Index *int64  `json:"index,omitempty"`
+	Hash  *string `json:"hash,omitempty"`
+}
+
+// TransactionIdentifier uniquely identifies a transaction.
+type TransactionIdentifier struct {
+	Hash string `json:"hash"`
+}
+
+// OperationIdentifier identifies an operation within a transaction.
+type OperationIdentifier struct {
+	Index int64 `json:"index"`
+}
+
+// AccountIdentifier identifies an account by its Bech32-encoded address.
+type AccountIdentifier struct {
+	Address string `json:"address"`
+}
+
+// Currency is a currency, corresponding to a denomination.
+type Currency struct {
+	Symbol   string `json:"symbol"`
+	Decimals int32  `json:"decimals"`
+}
+
+// Amount is a signed amount in base units of a currency.
+type Amount struct {
+	Value    string    `json:"value"`
+	Currency *Currency `json:"currency"`
+}
+
+// Operation is a balance change of an account.
+type Operation struct {
+	OperationIdentifier *OperationIdentifier   `json:"operation_identifier"`
+	RelatedOperations   []*OperationIdentifier `json:"related_operations,omitempty"`
+	Type                string                 `json:"type"`
+	Status              *string                `json:"status,omitempty"`
+	Account             *AccountIdentifier     `json:"account,omitempty"`
+	Amount              *Amount                `json:"amount,omitempty"`
+}
+
+// Transaction is a transaction along with its operations.
+type Transaction struct {
+	TransactionIdentifier *TransactionIdentifier `json:"transaction_identifier"`
+	Operations            []*Operation           `json:"operations"`
+	Metadata              map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Block is a block with its transactions.
+type Block struct {
+	BlockIdentifier       *BlockIdentifier `json:"block_identifier"`
+	ParentBlockIdentifier *BlockIdentifier `json:"parent_block_identifier"`
+	// Timestamp is the block timestamp in milliseconds since the Unix epoch.
+	Timestamp    int64          `json:"timestamp"`
+	Transactions []*Transaction `json:"transactions"`
+}
+
+// PublicKey is a public key on the given curve.
+type PublicKey struct {
+	HexBytes  string `json:"hex_bytes"`
+	CurveType string `json:"curve_type"`
+}
+
+// SigningPayload is a payload to be signed by an account.
+type SigningPayload struct {
+	AccountIdentifier *AccountIdentifier `json:"account_identifier"`
+	HexBytes          string             `json:"hex_bytes"`
+	SignatureType     string             `json:"signature_type"`
+}
+
+// Signature is a signature of a signing payload.
+type Signature struct {
+	SigningPayload *SigningPayload `json:"signing_payload"`
+	PublicKey      *PublicKey      `json:"public_key"`
+	SignatureType  string          `json:"signature_type"`
+	HexBytes       string          `json:"hex_bytes"`
+}
+
+// Version is the version information of the server.
+type Version struct {
+	RosettaVersion    string `json:"rosetta_version"`
+	NodeVersion       string `json:"node_version"`
+	MiddlewareVersion string `json:"middleware_version,omitempty"`
+}
+
+// OperationStatus is an operation status and whether operations with it affect balances.
+type OperationStatus struct {
+	Status     string `json:"status"`
+	Successful bool   `json:"successful"`
+}
+
+// Allow describes the operations and errors supported by the server.
+type Allow struct {
+	OperationStatuses       []*OperationStatus `json:"operation_statuses"`
+	OperationTypes          []string           `json:"operation_types"`
+	Errors                  []*Error           `json:"errors"`
+	HistoricalBalanceLookup bool               `json:"historical_balance_lookup"`
+}
+
+// Peer is a peer of the node.
+type Peer struct {
+	PeerID string `json:"peer_id"`
+}
+
+// Error is a Rosetta error. It implements error.
+type Error struct {
+	Code      int32                  `json:"code"`
+	Message   string                 `json:"message"`
+	Retriable bool                   `json:"retriable"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	if msg, ok := e.Details["error"]; ok {
+		return e.Message + ": " + msg.(string)
+	}
+	return e.Message
+}
+
+// withDetails returns a copy of the error with the given underlying error attached.
+func (e *Error) withDetails(err error) *Error {
+	cp := *e
+	cp.Details = map[string]interface{}{"error": err.Error()}
+	return &cp
+}
+
+// Request and response bodies of the endpoints.
+
+// NetworkListResponse is the response of /network/list.
+type NetworkListResponse struct {
+	NetworkIdentifiers []*NetworkIdentifier `json:"network_identifiers"`
+}
+
+// NetworkRequest is the request of /network/options and /network/status.
+type NetworkRequest struct {
+	NetworkIdentifier *NetworkIdentifier `json:"network_identifier"`
+}
+
+// NetworkOptionsResponse is the response of /network/options.
+type NetworkOptionsResponse struct {
+	Version *Version `json:"version"`
+	Allow   *Allow   `json:"allow"`
+}
+
+// NetworkStatusResponse is the response of /network/status.
+type NetworkStatusResponse struct {
+	CurrentBlockIdentifier *BlockIdentifier `json:"current_block_identifier"`
+	// CurrentBlockTimestamp is in milliseconds since the Unix epoch.
+	CurrentBlockTimestamp  int64            `json:"current_block_timestamp"`
+	GenesisBlockIdentifier *BlockIdentifier `json:"genesis_block_identifier"`
+	OldestBlockIdentifier  *BlockIdentifier `json:"oldest_block_identifier,omitempty"`
+	Peers                  []*Peer          `json:"peers"`
+}
+
+// BlockRequest is the request of /block.
+type BlockRequest struct {
+	NetworkIdentifier *NetworkIdentifier      `json:"network_identifier"`
+	BlockIdentifier   *PartialBlockIdentifier `json:"block_identifier"`
+}
+
+// BlockResponse is the response of /block.
+type BlockResponse struct {
+	Block *Block `json:"block"`
+}
+
+// BlockTransactionRequest is the request of /block/transaction.
+type BlockTransactionRequest struct {
+	NetworkIdentifier     *NetworkIdentifier     `json:"network_identifier"`
+	BlockIdentifier       *BlockIdentifier       `json:"block_identifier"`
+	TransactionIdentifier *TransactionIdentifier `json:"transaction_identifier"`
+}
+
+// BlockTransactionResponse is the response of /block/transaction.
+type BlockTransactionResponse struct {
+	Transaction *Transaction `json:"transaction"`
+}
+
+// AccountBalanceRequest is the request of /account/balance.
+type AccountBalanceRequest struct {
+	NetworkIdentifier *NetworkIdentifier      `json:"network_identifier"`
+	AccountIdentifier *AccountIdentifier      `json:"account_identifier"`
+	BlockIdentifier   *PartialBlockIdentifier `json:"block_identifier,omitempty"`
+	Currencies        []*Currency             `json:"currencies,omitempty"`
+}
+
+// AccountBalanceResponse is the response of /account/balance.
+type AccountBalanceResponse struct {
+	BlockIdentifier *BlockIdentifier       `json:"block_identifier"`
+	Balances        []*Amount              `json:"balances"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// MempoolResponse is the response of /mempool.
+type MempoolResponse struct {
+	TransactionIdentifiers []*TransactionIdentifier `json:"transaction_identifiers"`
+}
+
+// ConstructionDeriveRequest is the request of /construction/derive.
+type ConstructionDeriveRequest struct {
+	NetworkIdentifier *NetworkIdentifier `json:"network_identifier"`
+	PublicKey         *PublicKey         `json:"public_key"`
+}
+
+// ConstructionDeriveResponse is the response of /construction/derive.
+type ConstructionDeriveResponse struct {
+	AccountIdentifier *AccountIdentifier `json:"account_identifier"`
+}
+
+// ConstructionPreprocessRequest is the request of /construction/preprocess.
+type ConstructionPreprocessRequest struct {
+	NetworkIdentifier *NetworkIdentifier `json:"network_identifier"`
+	Operations        []*Operation       `json:"operations"`
+}
+
+// ConstructionPreprocessResponse is the response of /construction/preprocess.
+type ConstructionPreprocessResponse struct {
+	Options            *Options             `json:"options"`
+	RequiredPublicKeys []*AccountIdentifier `json:"required_public_keys"`
+}
+
+// Options are the options returned by /construction/preprocess and passed to
+// /construction/metadata, describing the transfer whose gas is estimated.
+type Options struct {
+	// From is the Bech32-encoded address of the sender.
+	From string `json:"from"`
+	// To is the Bech32-encoded address of the recipient.
+	To string `json:"to"`
+	// Amount is the amount in base units.
+	Amount string `json:"amount"`
+	// Denomination is the denomination of the amount, empty for the native denomination.
+	Denomination string `json:"denomination,omitempty"`
+}
+
+// ConstructionMetadataRequest is the request of /construction/metadata.
+type ConstructionMetadataRequest struct {
+	NetworkIdentifier *NetworkIdentifier `json:"network_identifier"`
+	Options           *Options           `json:"options"`
+	PublicKeys        []*PublicKey       `json:"public_keys,omitempty"`
+}
+
+// ConstructionMetadataResponse is the response of /construction/metadata.
+type ConstructionMetadataResponse struct {
+	Metadata     *Metadata `json:"metadata"`
+	SuggestedFee []*Amount `json:"suggested_fee"`
+}
+
+// Metadata is the metadata returned by /construction/metadata and passed to
+// /construction/payloads.
+type Metadata struct {
+	Nonce uint64 `json:"nonce"`
+	// FeeGas is the gas limit of the transaction.
+	FeeGas uint64 `json:"fee_gas"`
+	// FeeAmount is the fee in base units of the native denomination.
+	FeeAmount string `json:"fee_amount"`
+}
+
+// ConstructionPayloadsRequest is the request of /construction/payloads.
+type ConstructionPayloadsRequest struct {
+	NetworkIdentifier *NetworkIdentifier `json:"network_identifier"`
+	Operations        []*Operation       `json:"operations"`
+	Metadata          *Metadata          `json:"metadata"`
+	PublicKeys        []*PublicKey       `json:"public_keys"`
+}
+
+// ConstructionPayloadsResponse is the response of /construction/payloads.
+type ConstructionPayloadsResponse struct {
+	UnsignedTransaction string            `json:"unsigned_transaction"`
+	Payloads            []*SigningPayload `json:"payloads"`
+}
+
+// ConstructionCombineRequest is the request of /construction/combine.
+type ConstructionCombineRequest struct {
+	NetworkIdentifier   *NetworkIdentifier `json:"network_identifier"`
+	UnsignedTransaction string             `json:"unsigned_transaction"`
+	Signatures          []*Signature       `json:"signatures"`
+}
+
+// ConstructionCombineResponse is the response of /construction/combine.
+type ConstructionCombineResponse struct {
+	SignedTransaction string `json:"signed_transaction"`
+}
+
+// ConstructionParseRequest is the request of /construction/parse.
+type ConstructionParseRequest struct {
+	NetworkIdentifier *NetworkIdentifier `json:"network_identifier"`
+	Signed            bool               `json:"signed"`
+	Transaction       string             `json:"transaction"`
+}
+
+// ConstructionParseResponse is the response of /construction/parse.
+type ConstructionParseResponse struct {
+	Operations               []*Operation         `json:"operations"`
+	AccountIdentifierSigners []*AccountIdentifier `json:"account_identifier_signers,omitempty"`
+}
+
+// ConstructionHashRequest is the request of /construction/hash.
+type ConstructionHashRequest struct {
+	NetworkIdentifier *NetworkIdentifier `json:"network_identifier"`
+	SignedTransaction string             `json:"signed_transaction"`
+}
+
+// TransactionIdentifierResponse is the response of /construction/hash and /construction/submit.
+type TransactionIdentifierResponse struct {
+	TransactionIdentifier *TransactionIdentifier `json:"transaction_identifier"`
+}
+
+// ConstructionSubmitRequest is the request of /construction/submit.
+type ConstructionSubmitRequest struct {
+	NetworkIdentifier *NetworkIdentifier `json:"network_identifier"`
+	SignedTransaction string             `json:"signed_transaction"`
+}
@@ -0,0 +1,142 @@
+// Package subcall provides helpers for composing subcalls, calls that a module makes into other
+// modules on behalf of its caller.
+//
+// Subcalls are currently supported by the contracts module, where a contract can emit call
+// messages which the runtime dispatches after the contract returns. The types in this package
+// mirror the message and reply types of the contract SDK so that contracts which forward messages
+// supplied by their callers can be driven from Go.
+package subcall
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// NotifyReply specifies when the caller wants to be notified of a subcall reply.
+type NotifyReply uint8
+
+const (
+	// NotifyReplyNever never notifies the caller.
+	NotifyReplyNever NotifyReply = 0
+	// NotifyReplyOnError notifies the caller only when the subcall fails.
+	NotifyReplyOnError NotifyReply = 1
+	// NotifyReplyOnSuccess notifies the caller only when the subcall succeeds.
+	NotifyReplyOnSuccess NotifyReply = 2
+	// NotifyReplyAlways always notifies the caller.
+	NotifyReplyAlways NotifyReply = 3
+)
+
+// Message is a message emitted by a module caller requesting the runtime to perform an action.
+type Message struct {
+	Call *Call `json:"call,omitempty"`
+}
+
+// Call is a message requesting the runtime to call an arbitrary method handler in a child context
+// with the emitter as the caller.
+type Call struct {
+	// ID is an arbitrary identifier that is returned in the reply.
+	ID uint64 `json:"id,omitempty"`
+	// Reply specifies when the caller wants to be notified of the reply.
+	Reply NotifyReply `json:"reply"`
+	// Method is the name of the method to call.
+	Method string `json:"method"`
+	// Body is the CBOR-encoded method body.
+	Body cbor.RawMessage `json:"body"`
+	// MaxGas is an optional gas limit for the subcall.
+	MaxGas *uint64 `json:"max_gas,omitempty"`
+	// Data is optional arbitrary data that is returned in the reply.
+	Data cbor.RawMessage `json:"data,omitempty"`
+}
+
+// SetID sets the identifier that is returned in the reply.
+func (c *Call) SetID(id uint64) *Call {
+	c.ID = id
+	return c
+}
+
+// SetReply sets when the caller wants to be notified of the reply.
+func (c *Call) SetReply(reply NotifyReply) *Call {
+	c.Reply = reply
+	return c
+}
+
+// SetMaxGas sets the gas limit for the subcall.
+func (c *Call) SetMaxGas(gas uint64) *Call {
+	c.MaxGas = &gas
+	return c
+}
+
+// SetData sets arbitrary data that is returned in the reply.
+func (c *Call) SetData(data interface{}) *Call {
+	c.Data = cbor.Marshal(data)
+	return c
+}
+
+// Message wraps the call into a message.
+func (c *Call) Message() *Message {
+	return &Message{Call: c}
+}
+
+// NewCall creates a new subcall of the given method.
+//
+// When the method is known to the module registry, the body must be of the registered body type.
+// By default the caller is never notified of the reply.
+func NewCall(method string, body interface{}) (*Call, error) {
+	if m, ok := registry.LookupMethod(method); ok {
+		if m.Kind != registry.MethodKindCall {
+			return nil, fmt.Errorf("subcall: method '%s' is not callable", method)
+		}
+		if m.Body != nil && body != nil && baseType(body) != baseType(m.Body) {
+			return nil, fmt.Errorf("subcall: invalid body type for method '%s' (expected: %s, got: %s)",
+				method, baseType(m.Body), baseType(body))
+		}
+	}
+
+	return &Call{
+		Reply:  NotifyReplyNever,
+		Method: method,
+		Body:   cbor.Marshal(body),
+	}, nil
+}
+
+func baseType(v interface{}) reflect.Type {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// Reply is a reply to a delivered message.
+type Reply struct {
+	Call *CallReply `json:"call,omitempty"`
+}
+
+// CallReply is a reply to a call message.
+type CallReply struct {
+	// ID is the identifier of the call message.
+	ID uint64 `json:"id,omitempty"`
+	// Result is the subcall result.
+	Result types.CallResult `json:"result"`
+	// Data is the data attached to the call message.
+	Data cbor.RawMessage `json:"data,omitempty"`
+}
+
+// DecodeResult decodes the successful subcall result into the given value.
+func (r *CallReply) DecodeResult(rsp interface{}) error {
+	if !r.Result.IsSuccess() {
+		return r.Result.Failed
+	}
+	if rsp == nil {
+		return nil
+	}
+	if err := cbor.Unmarshal(r.Result.Ok, rsp); err != nil {
+		return fmt.Errorf("subcall: failed to unmarshal result: %w", err)
+	}
+	return nil
+}
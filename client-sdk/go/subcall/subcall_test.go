@@ -0,0 +1,66 @@
+package subcall
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func TestNewCall(t *testing.T) {
+	require := require.New(t)
+
+	xfer := &accounts.Transfer{
+		To:     sdkTesting.Bob.Address,
+		Amount: types.NewBaseUnits(*quantity.NewFromUint64(1000), types.NativeDenomination),
+	}
+	call, err := NewCall("accounts.Transfer", xfer)
+	require.NoError(err, "NewCall")
+	call.SetID(42).SetReply(NotifyReplyAlways).SetMaxGas(10_000).SetData("data")
+
+	var raw map[string]map[string]interface{}
+	err = cbor.Unmarshal(cbor.Marshal(call.Message()), &raw)
+	require.NoError(err, "message should be encoded as a map")
+	require.Contains(raw, "call", "message should be a call message")
+	require.EqualValues(42, raw["call"]["id"])
+	require.EqualValues(NotifyReplyAlways, raw["call"]["reply"])
+	require.EqualValues("accounts.Transfer", raw["call"]["method"])
+	require.EqualValues(10_000, raw["call"]["max_gas"])
+
+	var msg Message
+	err = cbor.Unmarshal(cbor.Marshal(call.Message()), &msg)
+	require.NoError(err, "Unmarshal")
+	var decoded accounts.Transfer
+	err = cbor.Unmarshal(msg.Call.Body, &decoded)
+	require.NoError(err, "body should decode as a transfer")
+	require.Equal(*xfer, decoded)
+
+	_, err = NewCall("accounts.Transfer", &accounts.NonceQuery{})
+	require.Error(err, "mismatched body type should be rejected")
+
+	_, err = NewCall("accounts.Nonce", &accounts.NonceQuery{})
+	require.Error(err, "queries should be rejected")
+
+	_, err = NewCall("contracts.Call", map[string]interface{}{"id": 1})
+	require.NoError(err, "unregistered body types should be accepted")
+}
+
+func TestCallReply(t *testing.T) {
+	require := require.New(t)
+
+	reply := CallReply{ID: 1, Result: types.CallResult{Ok: cbor.Marshal(uint64(7))}}
+	var result uint64
+	require.NoError(reply.DecodeResult(&result), "DecodeResult")
+	require.EqualValues(7, result)
+
+	reply = CallReply{Result: types.CallResult{Failed: &types.FailedCallResult{Module: "accounts", Code: 5}}}
+	err := reply.DecodeResult(&result)
+	require.Error(err, "failed subcalls should return an error")
+	require.Contains(err.Error(), "accounts")
+}
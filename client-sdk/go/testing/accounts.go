@@ -0,0 +1,77 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// FundedAccountsConfig is the configuration of a set of fresh funded test accounts.
+type FundedAccountsConfig struct {
+	// Funder is the key of the account that funds the new accounts.
+	Funder TestKey
+	// Count is the number of accounts to create.
+	Count int
+	// Amounts are the amounts transferred to each new account. Each amount is transferred in a
+	// separate transaction, so multiple denominations can be funded at once.
+	Amounts []types.BaseUnits
+
+	// FeeAmount is the fee paid by the funder for each funding transaction.
+	FeeAmount types.BaseUnits
+	// FeeGas is the maximum gas amount of each funding transaction.
+	FeeGas uint64
+
+	// NewKey is an optional function used to generate the new keys. It defaults to
+	// NewRandomEd25519TestKey.
+	NewKey func() (TestKey, error)
+}
+
+// NewFundedAccounts creates fresh accounts and funds them from the funder's account.
+//
+// The returned keys are ready to be used for signing transactions once the function returns, as
+// all funding transactions have been executed by then.
+func NewFundedAccounts(ctx context.Context, rc client.RuntimeClient, cfg *FundedAccountsConfig) ([]TestKey, error) {
+	if cfg.Count <= 0 {
+		return nil, fmt.Errorf("testing: invalid number of accounts: %d", cfg.Count)
+	}
+	if len(cfg.Amounts) == 0 {
+		return nil, fmt.Errorf("testing: no funding amounts specified")
+	}
+	newKey := cfg.NewKey
+	if newKey == nil {
+		newKey = NewRandomEd25519TestKey
+	}
+
+	batch := accounts.NewBatchTransfer(rc).
+		SetFeeAmount(cfg.FeeAmount).
+		SetFeeGas(cfg.FeeGas)
+	keys := make([]TestKey, 0, cfg.Count)
+	for i := 0; i < cfg.Count; i++ {
+		key, err := newKey()
+		if err != nil {
+			return nil, fmt.Errorf("testing: failed to generate key %d: %w", i, err)
+		}
+		for _, amount := range cfg.Amounts {
+			batch.Add(key.Address, amount)
+		}
+		keys = append(keys, key)
+	}
+
+	results, err := batch.Submit(ctx, cfg.Funder.Signer, cfg.Funder.SigSpec)
+	if err != nil {
+		return nil, fmt.Errorf("testing: failed to fund accounts: %w", err)
+	}
+	for _, result := range results {
+		if !result.IsSuccess() {
+			return nil, fmt.Errorf("testing: failed to fund account %s with %s: %w",
+				result.Transfer.To,
+				result.Transfer.Amount,
+				result.Error,
+			)
+		}
+	}
+	return keys, nil
+}
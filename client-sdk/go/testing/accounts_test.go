@@ -0,0 +1,85 @@
+package testing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/fakeruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func TestNewFundedAccounts(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	foo := types.Denomination("FOO")
+	rt := fakeruntime.New(fakeruntime.Config{})
+	rt.SetBalance(sdkTesting.Alice.Address, types.NewBaseUnits(*quantity.NewFromUint64(1000), types.NativeDenomination))
+	rt.SetBalance(sdkTesting.Alice.Address, types.NewBaseUnits(*quantity.NewFromUint64(1000), foo))
+
+	keys, err := sdkTesting.NewFundedAccounts(ctx, rt, &sdkTesting.FundedAccountsConfig{
+		Funder: sdkTesting.Alice,
+		Count:  3,
+		Amounts: []types.BaseUnits{
+			types.NewBaseUnits(*quantity.NewFromUint64(100), types.NativeDenomination),
+			types.NewBaseUnits(*quantity.NewFromUint64(10), foo),
+		},
+		NewKey: sdkTesting.NewRandomSecp256k1TestKey,
+	})
+	require.NoError(err, "NewFundedAccounts")
+	require.Len(keys, 3)
+
+	ac := accounts.NewV1(rt)
+	seen := make(map[types.Address]bool)
+	for _, key := range keys {
+		require.False(seen[key.Address], "keys should be unique")
+		seen[key.Address] = true
+		require.Len(key.EthAddress, 20)
+
+		balances, err := ac.Balances(ctx, client.RoundLatest, key.Address)
+		require.NoError(err, "Balances")
+		require.Equal(*quantity.NewFromUint64(100), balances.Balances[types.NativeDenomination])
+		require.Equal(*quantity.NewFromUint64(10), balances.Balances[foo])
+
+		// The new accounts should be usable right away.
+		tb := ac.Transfer(sdkTesting.Bob.Address, types.NewBaseUnits(*quantity.NewFromUint64(1), foo)).
+			AppendAuthSignature(key.SigSpec, 0)
+		require.NoError(tb.AppendSign(ctx, key.Signer), "AppendSign")
+		require.NoError(tb.SubmitTx(ctx, nil), "Transfer from a funded account")
+	}
+
+	// Funding more than the funder owns should fail.
+	_, err = sdkTesting.NewFundedAccounts(ctx, rt, &sdkTesting.FundedAccountsConfig{
+		Funder:  sdkTesting.Alice,
+		Count:   1,
+		Amounts: []types.BaseUnits{types.NewBaseUnits(*quantity.NewFromUint64(10000), types.NativeDenomination)},
+	})
+	require.Error(err, "NewFundedAccounts exceeding the funder's balance")
+
+	_, err = sdkTesting.NewFundedAccounts(ctx, rt, &sdkTesting.FundedAccountsConfig{Funder: sdkTesting.Alice})
+	require.Error(err, "NewFundedAccounts without accounts")
+}
+
+func TestNewRandomTestKeys(t *testing.T) {
+	require := require.New(t)
+
+	for _, newKey := range []func() (sdkTesting.TestKey, error){
+		sdkTesting.NewRandomEd25519TestKey,
+		sdkTesting.NewRandomSecp256k1TestKey,
+		sdkTesting.NewRandomSr25519TestKey,
+	} {
+		a, err := newKey()
+		require.NoError(err, "newKey")
+		b, err := newKey()
+		require.NoError(err, "newKey")
+		require.NotEqual(a.Address, b.Address, "random keys should differ")
+		require.Equal(types.NewAddress(a.SigSpec), a.Address)
+	}
+}
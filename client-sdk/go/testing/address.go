@@ -0,0 +1,98 @@
+package testing
+
+import (
+	"fmt"
+
+	"golang.org/x/crypto/sha3"
+
+	coreSignature "github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// addressV0Version is the version byte of V0 addresses.
+const addressV0Version = 0
+
+// CheckAddress checks the invariants that hold for all addresses: the address is a V0 address and
+// round-trips through both its Bech32 and its binary encoding.
+func CheckAddress(addr types.Address) error {
+	raw, err := addr.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("address: failed to marshal: %w", err)
+	}
+	if raw[0] != addressV0Version {
+		return fmt.Errorf("address: unexpected version %d", raw[0])
+	}
+
+	var fromRaw types.Address
+	if err = fromRaw.UnmarshalBinary(raw); err != nil {
+		return fmt.Errorf("address: failed to unmarshal binary encoding: %w", err)
+	}
+	if !fromRaw.Equal(addr) {
+		return fmt.Errorf("address: binary encoding does not round-trip")
+	}
+
+	var fromBech32 types.Address
+	if err = fromBech32.UnmarshalText([]byte(addr.String())); err != nil {
+		return fmt.Errorf("address: failed to unmarshal Bech32 encoding: %w", err)
+	}
+	if !fromBech32.Equal(addr) {
+		return fmt.Errorf("address: Bech32 encoding does not round-trip")
+	}
+	return nil
+}
+
+// CheckSigspecAddress checks that the given address is the one derived from the given signature
+// address specification, e.g. to validate the derivation code of a wallet against the SDK.
+//
+// Besides matching the SDK derivation, Ed25519 addresses must match the consensus layer staking
+// addresses of the same keys and Secp256k1 addresses must be derivable from the Ethereum
+// addresses of the same keys.
+func CheckSigspecAddress(spec types.SignatureAddressSpec, addr types.Address) error {
+	if err := CheckAddress(addr); err != nil {
+		return err
+	}
+	if expected := types.NewAddress(spec); !addr.Equal(expected) {
+		return fmt.Errorf("address: %s does not match derived address %s", addr, expected)
+	}
+
+	switch {
+	case spec.Ed25519 != nil:
+		pk, _ := spec.Ed25519.MarshalBinary()
+		var corePk coreSignature.PublicKey
+		if err := corePk.UnmarshalBinary(pk); err != nil {
+			return fmt.Errorf("address: malformed Ed25519 public key: %w", err)
+		}
+		if consensusAddr := types.Address(staking.NewAddress(corePk)); !addr.Equal(consensusAddr) {
+			return fmt.Errorf("address: %s does not match consensus address %s", addr, consensusAddr)
+		}
+	case spec.Secp256k1Eth != nil:
+		untaggedPk, err := spec.Secp256k1Eth.MarshalBinaryUncompressedUntagged()
+		if err != nil {
+			return fmt.Errorf("address: malformed Secp256k1 public key: %w", err)
+		}
+		h := sha3.NewLegacyKeccak256()
+		h.Write(untaggedPk)
+		ethAddr := h.Sum(nil)[32-20:]
+		if expected := types.NewAddressRaw(types.AddressV0Secp256k1EthContext, ethAddr); !addr.Equal(expected) {
+			return fmt.Errorf("address: %s does not match address %s of Ethereum address %x", addr, expected, ethAddr)
+		}
+	}
+	return nil
+}
+
+// CheckMultisigAddress checks that the given address is the one derived from the given (valid)
+// multisig configuration.
+func CheckMultisigAddress(config *types.MultisigConfig, addr types.Address) error {
+	if err := config.ValidateBasic(); err != nil {
+		return fmt.Errorf("address: invalid multisig config: %w", err)
+	}
+	if err := CheckAddress(addr); err != nil {
+		return err
+	}
+	if expected := types.NewAddressFromMultisig(config); !addr.Equal(expected) {
+		return fmt.Errorf("address: %s does not match derived address %s", addr, expected)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package testing
+
+import (
+	"bytes"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/address"
+	memorySigner "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/memory"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// testKeyFromSeed derives a test key of the given scheme (0: Ed25519, 1: Secp256k1, 2: Sr25519)
+// from the given seed.
+func testKeyFromSeed(t *testing.T, scheme uint8, seed [32]byte) TestKey {
+	switch scheme % 3 {
+	case 0:
+		signer, err := memorySigner.NewSigner(bytes.NewReader(seed[:]))
+		require.NoError(t, err, "memorySigner.NewSigner")
+		return ed25519TestKeyFromSigner(signer)
+	case 1:
+		return secp256k1TestKeyFromSecret(seed[:])
+	default:
+		key, err := sr25519TestKeyFromSecret(seed[:])
+		require.NoError(t, err, "sr25519TestKeyFromSecret")
+		return key
+	}
+}
+
+func TestCheckAddressTestKeys(t *testing.T) {
+	require := require.New(t)
+
+	for _, k := range TestKeys {
+		require.NoError(CheckSigspecAddress(k.SigSpec, k.Address), k.Address.String())
+	}
+	for _, k := range []MultisigTestKey{AliceBobMultisig, MixedMultisig} {
+		require.NoError(CheckMultisigAddress(k.Config, k.Address), k.Address.String())
+	}
+
+	require.Error(CheckSigspecAddress(Alice.SigSpec, Bob.Address), "mismatched address should be rejected")
+	require.Error(CheckSigspecAddress(Dave.SigSpec, Frank.Address), "mismatched address should be rejected")
+	require.Error(CheckMultisigAddress(MixedMultisig.Config, AliceBobMultisig.Address), "mismatched address should be rejected")
+	require.Error(CheckMultisigAddress(&types.MultisigConfig{Threshold: 1}, AliceBobMultisig.Address), "invalid config should be rejected")
+
+	var v1 types.Address
+	v1[0] = 1
+	require.Error(CheckAddress(v1), "non-V0 address should be rejected")
+}
+
+func TestAddressDerivationProperties(t *testing.T) {
+	require := require.New(t)
+
+	// Derivation is deterministic and derived addresses satisfy all invariants.
+	require.NoError(quick.Check(func(scheme uint8, seed [32]byte) bool {
+		key := testKeyFromSeed(t, scheme, seed)
+		again := testKeyFromSeed(t, scheme, seed)
+		return key.Address.Equal(again.Address) && CheckSigspecAddress(key.SigSpec, key.Address) == nil
+	}, nil), "derived addresses should be deterministic and valid")
+
+	// Different keys map to different addresses.
+	require.NoError(quick.Check(func(schemeA, schemeB uint8, seedA, seedB [32]byte) bool {
+		if schemeA%3 == schemeB%3 && seedA == seedB {
+			return true
+		}
+		a := testKeyFromSeed(t, schemeA, seedA)
+		b := testKeyFromSeed(t, schemeB, seedB)
+		return !a.Address.Equal(b.Address)
+	}, nil), "different keys should derive different addresses")
+
+	// Addresses derived from the same data in different contexts never collide.
+	contexts := []address.Context{
+		types.AddressV0Ed25519Context,
+		types.AddressV0Secp256k1EthContext,
+		types.AddressV0Sr25519Context,
+		types.AddressV0MultisigContext,
+		types.AddressV0ModuleContext,
+	}
+	require.NoError(quick.Check(func(data []byte) bool {
+		seen := make(map[types.Address]bool)
+		for _, ctx := range contexts {
+			addr := types.NewAddressRaw(ctx, data)
+			if seen[addr] || CheckAddress(addr) != nil {
+				return false
+			}
+			seen[addr] = true
+		}
+		return true
+	}, nil), "address contexts should be domain separated")
+}
+
+func TestMultisigAddressDerivationProperties(t *testing.T) {
+	require := require.New(t)
+
+	newConfig := func(schemes []uint8, seeds [][32]byte, threshold uint8) *types.MultisigConfig {
+		cfg := &types.MultisigConfig{Threshold: uint64(threshold%3) + 1}
+		for i, seed := range seeds {
+			key := testKeyFromSeed(t, schemes[i%len(schemes)], seed)
+			cfg.Signers = append(cfg.Signers, types.MultisigSigner{
+				PublicKey: types.PublicKey{PublicKey: key.Signer.Public()},
+				Weight:    1,
+			})
+		}
+		return cfg
+	}
+	cfg := &quick.Config{MaxCount: 20}
+
+	// Valid configurations derive valid addresses, which change with any part of the config.
+	require.NoError(quick.Check(func(schemes []uint8, seedA, seedB, seedC [32]byte, threshold uint8) bool {
+		if len(schemes) == 0 || seedA == seedB || seedB == seedC || seedA == seedC {
+			return true
+		}
+		config := newConfig(schemes, [][32]byte{seedA, seedB, seedC}, threshold)
+		addr := types.NewAddressFromMultisig(config)
+		if CheckMultisigAddress(config, addr) != nil {
+			return false
+		}
+
+		changed := *config
+		changed.Threshold = config.Threshold%3 + 1
+		if types.NewAddressFromMultisig(&changed).Equal(addr) {
+			return false
+		}
+
+		changed = *config
+		changed.Signers = append([]types.MultisigSigner{}, config.Signers...)
+		changed.Signers[0].Weight++
+		if types.NewAddressFromMultisig(&changed).Equal(addr) {
+			return false
+		}
+
+		changed.Signers = []types.MultisigSigner{config.Signers[1], config.Signers[0], config.Signers[2]}
+		return !types.NewAddressFromMultisig(&changed).Equal(addr)
+	}, cfg), "multisig addresses should be valid and depend on the whole config")
+}
@@ -0,0 +1,171 @@
+// Package events implements helpers for waiting on expected runtime and consensus events in
+// tests.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+)
+
+// DefaultTimeout is the default amount of time to wait for an expected event.
+const DefaultTimeout = 1 * time.Minute
+
+// maxDumpedEvents is the maximum number of received events included in a NotFoundError message.
+const maxDumpedEvents = 32
+
+var (
+	// ErrTimeout is the error returned when the expected event is not received in time.
+	ErrTimeout = errors.New("events: timeout waiting for event")
+	// ErrChannelClosed is the error returned when the event channel is closed before the expected
+	// event is received.
+	ErrChannelClosed = errors.New("events: event channel closed")
+)
+
+// NotFoundError is the error returned when waiting for an expected event fails. It carries all
+// the events received while waiting to ease debugging.
+type NotFoundError struct {
+	// Reason is the reason why waiting stopped, e.g. ErrTimeout.
+	Reason error
+	// Events are all the events received while waiting, in the order they were received.
+	Events []interface{}
+}
+
+// Error implements error.
+func (e *NotFoundError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "expected event not found: %s (received %d events)", e.Reason, len(e.Events))
+
+	events := e.Events
+	if len(events) > maxDumpedEvents {
+		fmt.Fprintf(&b, "\n  ... %d earlier events omitted", len(events)-maxDumpedEvents)
+		events = events[len(events)-maxDumpedEvents:]
+	}
+	for _, ev := range events {
+		fmt.Fprintf(&b, "\n  %s", formatEvent(ev))
+	}
+	return b.String()
+}
+
+// Unwrap returns the reason why waiting stopped.
+func (e *NotFoundError) Unwrap() error {
+	return e.Reason
+}
+
+func formatEvent(ev interface{}) string {
+	if data, err := json.Marshal(ev); err == nil {
+		return string(data)
+	}
+	return fmt.Sprintf("%+v", ev)
+}
+
+// Config is the configuration of an event wait.
+type Config struct {
+	// Timeout is the maximum amount of time to wait for the expected event. It defaults to
+	// DefaultTimeout.
+	Timeout time.Duration
+	// Logger is an optional logger that all received events are logged to.
+	Logger *logging.Logger
+}
+
+func (cfg *Config) timeout() time.Duration {
+	if cfg == nil || cfg.Timeout == 0 {
+		return DefaultTimeout
+	}
+	return cfg.Timeout
+}
+
+func (cfg *Config) logger() *logging.Logger {
+	if cfg == nil {
+		return nil
+	}
+	return cfg.Logger
+}
+
+// RuntimeEvent is a decoded runtime event together with the round it was emitted in.
+type RuntimeEvent struct {
+	Round uint64              `json:"round"`
+	Event client.DecodedEvent `json:"event"`
+}
+
+// WaitForRuntimeEvent waits for a runtime event accepted by the matcher to be received on the
+// given channel, as returned by client.RuntimeClient.WatchEvents.
+func WaitForRuntimeEvent(
+	ctx context.Context,
+	ch <-chan *client.BlockEvents,
+	cfg *Config,
+	match RuntimeMatcher,
+) (*RuntimeEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, cfg.timeout())
+	defer cancel()
+
+	logger := cfg.logger()
+	var received []interface{}
+	for {
+		select {
+		case blockEvs, ok := <-ch:
+			if !ok {
+				return nil, &NotFoundError{Reason: ErrChannelClosed, Events: received}
+			}
+			for _, ev := range blockEvs.Events {
+				rev := &RuntimeEvent{Round: blockEvs.Round, Event: ev}
+				if logger != nil {
+					logger.Debug("received runtime event", "round", rev.Round, "event", ev)
+				}
+				received = append(received, rev)
+				if match(ev) {
+					return rev, nil
+				}
+			}
+		case <-ctx.Done():
+			return nil, &NotFoundError{Reason: waitError(ctx), Events: received}
+		}
+	}
+}
+
+// WaitForConsensusEvent waits for a consensus staking event accepted by the matcher to be
+// received on the given channel, as returned by consensus.Client.WatchStakingEvents.
+func WaitForConsensusEvent(
+	ctx context.Context,
+	ch <-chan *staking.Event,
+	cfg *Config,
+	match ConsensusMatcher,
+) (*staking.Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, cfg.timeout())
+	defer cancel()
+
+	logger := cfg.logger()
+	var received []interface{}
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return nil, &NotFoundError{Reason: ErrChannelClosed, Events: received}
+			}
+			if logger != nil {
+				logger.Debug("received consensus event", "event", ev)
+			}
+			received = append(received, ev)
+			if match(ev) {
+				return ev, nil
+			}
+		case <-ctx.Done():
+			return nil, &NotFoundError{Reason: waitError(ctx), Events: received}
+		}
+	}
+}
+
+func waitError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,110 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/evm"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/fakeruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func nativeUnits(amount uint64) types.BaseUnits {
+	return types.NewBaseUnits(*quantity.NewFromUint64(amount), types.NativeDenomination)
+}
+
+func TestWaitForRuntimeEvent(t *testing.T) {
+	require := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rt := fakeruntime.New(fakeruntime.Config{})
+	rt.SetBalance(sdkTesting.Alice.Address, nativeUnits(100))
+	ac := accounts.NewV1(rt)
+
+	ch, err := rt.WatchEvents(ctx, []client.EventDecoder{ac}, false)
+	require.NoError(err, "WatchEvents")
+
+	tb := ac.Transfer(sdkTesting.Bob.Address, nativeUnits(10)).
+		AppendAuthSignature(sdkTesting.Alice.SigSpec, 0)
+	require.NoError(tb.AppendSign(ctx, sdkTesting.Alice.Signer), "AppendSign")
+	meta, err := tb.SubmitTxMeta(ctx, nil)
+	require.NoError(err, "Transfer")
+
+	ev, err := WaitForRuntimeEvent(ctx, ch, nil, AccountsTransfer(sdkTesting.Alice.Address, sdkTesting.Bob.Address, nativeUnits(10)))
+	require.NoError(err, "WaitForRuntimeEvent")
+	require.Equal(meta.Round, ev.Round)
+
+	// A non-matching event should be reported on timeout.
+	tb = ac.Transfer(sdkTesting.Bob.Address, nativeUnits(5)).
+		AppendAuthSignature(sdkTesting.Alice.SigSpec, 1)
+	require.NoError(tb.AppendSign(ctx, sdkTesting.Alice.Signer), "AppendSign")
+	require.NoError(tb.SubmitTx(ctx, nil), "Transfer")
+
+	_, err = WaitForRuntimeEvent(ctx, ch, &Config{Timeout: 100 * time.Millisecond}, AccountsMint(sdkTesting.Bob.Address, nativeUnits(5)))
+	require.True(errors.Is(err, ErrTimeout), "WaitForRuntimeEvent should time out")
+	var nfErr *NotFoundError
+	require.True(errors.As(err, &nfErr))
+	require.Len(nfErr.Events, 1)
+	require.Contains(err.Error(), sdkTesting.Bob.Address.String())
+
+	// Closing the channel should abort the wait.
+	cancel()
+	_, err = WaitForRuntimeEvent(context.Background(), ch, nil, AccountsBurn(sdkTesting.Bob.Address, nativeUnits(5)))
+	require.True(errors.Is(err, ErrChannelClosed), "WaitForRuntimeEvent should fail on closed channel")
+}
+
+func TestWaitForConsensusEvent(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	from := staking.Address(sdkTesting.Alice.Address)
+	to := staking.Address(sdkTesting.Bob.Address)
+	ch := make(chan *staking.Event, 3)
+	ch <- &staking.Event{Height: 1, Burn: &staking.BurnEvent{Owner: from, Amount: *quantity.NewFromUint64(5)}}
+	ch <- &staking.Event{Height: 2, Transfer: &staking.TransferEvent{From: from, To: to, Amount: *quantity.NewFromUint64(1)}}
+	ch <- &staking.Event{Height: 3, Transfer: &staking.TransferEvent{From: from, To: to, Amount: *quantity.NewFromUint64(10)}}
+
+	ev, err := WaitForConsensusEvent(ctx, ch, nil, ConsensusTransfer(from, to, *quantity.NewFromUint64(10)))
+	require.NoError(err, "WaitForConsensusEvent")
+	require.EqualValues(3, ev.Height)
+
+	_, err = WaitForConsensusEvent(ctx, ch, &Config{Timeout: 10 * time.Millisecond}, ConsensusBurn(from, *quantity.NewFromUint64(5)))
+	require.True(errors.Is(err, ErrTimeout), "WaitForConsensusEvent should time out")
+
+	matcher := AllConsensus(
+		ConsensusBurn(from, *quantity.NewFromUint64(5)),
+		func(ev *staking.Event) bool { return ev.Height > 1 },
+	)
+	require.False(matcher(&staking.Event{Height: 1, Burn: &staking.BurnEvent{Owner: from, Amount: *quantity.NewFromUint64(5)}}))
+	require.True(matcher(&staking.Event{Height: 2, Burn: &staking.BurnEvent{Owner: from, Amount: *quantity.NewFromUint64(5)}}))
+}
+
+func TestEVMLog(t *testing.T) {
+	require := require.New(t)
+
+	address := []byte("01234567890123456789")
+	topic := []byte("0123456789012345678901234567890a")
+	ev := &evm.Event{Log: &evm.LogEvent{
+		Address: address,
+		Topics:  [][]byte{topic},
+		Data:    []byte("data"),
+	}}
+
+	require.True(EVMLog(address, [][]byte{topic}, []byte("data"))(ev))
+	require.True(EVMLog(address, [][]byte{topic}, nil)(ev), "nil data should match any data")
+	require.False(EVMLog(address, [][]byte{topic}, []byte("other"))(ev))
+	require.False(EVMLog(address, nil, nil)(ev), "topics should match")
+	require.False(EVMLog([]byte("98765432109876543210"), [][]byte{topic}, nil)(ev), "address should match")
+	require.False(EVMLog(address, [][]byte{topic}, nil)(&accounts.Event{}), "other events should not match")
+}
@@ -0,0 +1,139 @@
+package events
+
+import (
+	"bytes"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/evm"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// RuntimeMatcher reports whether a decoded runtime event is the expected one.
+type RuntimeMatcher func(client.DecodedEvent) bool
+
+// ConsensusMatcher reports whether a consensus staking event is the expected one.
+type ConsensusMatcher func(*staking.Event) bool
+
+// AllRuntime returns a matcher that accepts runtime events accepted by all given matchers.
+func AllRuntime(matchers ...RuntimeMatcher) RuntimeMatcher {
+	return func(ev client.DecodedEvent) bool {
+		for _, m := range matchers {
+			if !m(ev) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AllConsensus returns a matcher that accepts consensus events accepted by all given matchers.
+func AllConsensus(matchers ...ConsensusMatcher) ConsensusMatcher {
+	return func(ev *staking.Event) bool {
+		for _, m := range matchers {
+			if !m(ev) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func equalBaseUnits(a, b *types.BaseUnits) bool {
+	return a.Denomination == b.Denomination && a.Amount.Cmp(&b.Amount) == 0
+}
+
+func accountsEvent(ev client.DecodedEvent) *accounts.Event {
+	aev, _ := ev.(*accounts.Event)
+	return aev
+}
+
+// AccountsTransfer returns a matcher that accepts an accounts transfer event of the given amount
+// between the given accounts.
+//
+// The events must be decoded using the accounts module event decoder.
+func AccountsTransfer(from, to types.Address, amount types.BaseUnits) RuntimeMatcher {
+	return func(ev client.DecodedEvent) bool {
+		aev := accountsEvent(ev)
+		if aev == nil || aev.Transfer == nil {
+			return false
+		}
+		return aev.Transfer.From == from && aev.Transfer.To == to && equalBaseUnits(&aev.Transfer.Amount, &amount)
+	}
+}
+
+// AccountsMint returns a matcher that accepts an accounts mint event of the given amount into the
+// given account.
+//
+// The events must be decoded using the accounts module event decoder.
+func AccountsMint(owner types.Address, amount types.BaseUnits) RuntimeMatcher {
+	return func(ev client.DecodedEvent) bool {
+		aev := accountsEvent(ev)
+		if aev == nil || aev.Mint == nil {
+			return false
+		}
+		return aev.Mint.Owner == owner && equalBaseUnits(&aev.Mint.Amount, &amount)
+	}
+}
+
+// AccountsBurn returns a matcher that accepts an accounts burn event of the given amount from the
+// given account.
+//
+// The events must be decoded using the accounts module event decoder.
+func AccountsBurn(owner types.Address, amount types.BaseUnits) RuntimeMatcher {
+	return func(ev client.DecodedEvent) bool {
+		aev := accountsEvent(ev)
+		if aev == nil || aev.Burn == nil {
+			return false
+		}
+		return aev.Burn.Owner == owner && equalBaseUnits(&aev.Burn.Amount, &amount)
+	}
+}
+
+// EVMLog returns a matcher that accepts an EVM log event emitted by the contract at the given
+// address with the given topics and data. A nil data matches any data.
+//
+// The events must be decoded using the EVM module event decoder.
+func EVMLog(address []byte, topics [][]byte, data []byte) RuntimeMatcher {
+	return func(ev client.DecodedEvent) bool {
+		eev, _ := ev.(*evm.Event)
+		if eev == nil || eev.Log == nil {
+			return false
+		}
+		log := eev.Log
+		if !bytes.Equal(log.Address, address) || len(log.Topics) != len(topics) {
+			return false
+		}
+		for i := range topics {
+			if !bytes.Equal(log.Topics[i], topics[i]) {
+				return false
+			}
+		}
+		return data == nil || bytes.Equal(log.Data, data)
+	}
+}
+
+// ConsensusTransfer returns a matcher that accepts a staking transfer event of the given amount
+// between the given consensus accounts.
+func ConsensusTransfer(from, to staking.Address, amount quantity.Quantity) ConsensusMatcher {
+	return func(ev *staking.Event) bool {
+		if ev.Transfer == nil {
+			return false
+		}
+		return ev.Transfer.From == from && ev.Transfer.To == to && ev.Transfer.Amount.Cmp(&amount) == 0
+	}
+}
+
+// ConsensusBurn returns a matcher that accepts a staking burn event of the given amount from the
+// given consensus account.
+func ConsensusBurn(owner staking.Address, amount quantity.Quantity) ConsensusMatcher {
+	return func(ev *staking.Event) bool {
+		if ev.Burn == nil {
+			return false
+		}
+		return ev.Burn.Owner == owner && ev.Burn.Amount.Cmp(&amount) == 0
+	}
+}
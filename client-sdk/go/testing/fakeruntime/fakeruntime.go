@@ -0,0 +1,524 @@
+// Package fakeruntime implements an in-memory runtime for unit tests.
+//
+// The fake runtime implements client.RuntimeClient and emulates the transaction and query semantics
+// of the core, accounts, consensus accounts and EVM modules closely enough for testing client-side
+// logic without running a network. It is not a runtime implementation:
+//
+//   - Each submitted transaction is executed immediately in its own block.
+//   - Gas is not metered and fees are only deducted from the first signer.
+//   - Consensus messages emitted in a round are processed at the start of the next round, which
+//     can be produced explicitly via NextBlock.
+//   - The consensus layer is reduced to a ledger of account balances and does not check
+//     allowances.
+//   - EVM bytecode is not executed, calls are delegated to a configurable handler instead.
+//
+// Methods that require access to the consensus layer (committees, epochs, the runtime descriptor)
+// return ErrNotSupported.
+package fakeruntime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	cmnErrors "github.com/oasisprotocol/oasis-core/go/common/errors"
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+	coreClient "github.com/oasisprotocol/oasis-core/go/runtime/client/api"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/core"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// consensusChainContext is the consensus layer chain context the runtime chain context is derived
+// from.
+const consensusChainContext = "oasis-sdk/fakeruntime: consensus"
+
+// ErrNotSupported is the error returned by methods that the fake runtime does not implement.
+var ErrNotSupported = errors.New("fakeruntime: not supported")
+
+var _ client.RuntimeClient = (*Runtime)(nil)
+
+// EVMCall is an EVM call delegated to the EVMCallHandler.
+type EVMCall struct {
+	// Caller is the Ethereum address of the caller.
+	Caller []byte
+	// Address is the address of the called contract.
+	Address []byte
+	// Value is the 256-bit big-endian amount of native tokens sent with the call.
+	Value []byte
+	// Data is the call data.
+	Data []byte
+	// Code is the code stored at the called address, if any.
+	Code []byte
+	// Simulated is true for calls made via the evm.SimulateCall query.
+	Simulated bool
+}
+
+// EVMCallHandler handles an EVM call, returning the call output. Returned errors are reported as
+// EVM errors.
+type EVMCallHandler func(call *EVMCall) ([]byte, error)
+
+// Config is the fake runtime configuration.
+type Config struct {
+	// ID is the runtime identifier.
+	ID common.Namespace
+	// ConsensusDenomination is the denomination representing consensus layer tokens. If empty,
+	// the native denomination is used.
+	ConsensusDenomination types.Denomination
+	// CoreParameters are the core module parameters returned by queries.
+	CoreParameters core.Parameters
+	// EVMCallHandler is the handler for EVM calls. If nil, all EVM calls fail.
+	EVMCallHandler EVMCallHandler
+}
+
+// roundData is everything the fake runtime keeps about a single round.
+type roundData struct {
+	block          *block.Block
+	state          *state
+	txs            []*client.TransactionWithResults
+	events         []*types.Event
+	messageResults []*client.MessageResult
+}
+
+// Runtime is an in-memory fake runtime.
+type Runtime struct {
+	sync.Mutex
+
+	cfg  Config
+	info *types.RuntimeInfo
+
+	rounds  []*roundData
+	pending []*pendingMessage
+
+	blockNotifier *pubsub.Broker
+}
+
+// New creates a new fake runtime with an empty state and a genesis block at round zero.
+func New(cfg Config) *Runtime {
+	if cfg.ConsensusDenomination == "" {
+		cfg.ConsensusDenomination = types.NativeDenomination
+	}
+
+	return &Runtime{
+		cfg: cfg,
+		info: &types.RuntimeInfo{
+			ID:           cfg.ID,
+			ChainContext: signature.DeriveChainContext(cfg.ID, consensusChainContext),
+		},
+		rounds: []*roundData{{
+			block: block.NewGenesisBlock(cfg.ID, uint64(time.Now().Unix())),
+			state: newState(),
+		}},
+		blockNotifier: pubsub.NewBroker(false),
+	}
+}
+
+// SetBalance sets the runtime balance of the given account in the latest round.
+func (r *Runtime) SetBalance(addr types.Address, amount types.BaseUnits) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.latest().state.setBalance(addr, amount.Denomination, *amount.Amount.Clone())
+}
+
+// SetConsensusBalance sets the consensus layer balance of the given account.
+func (r *Runtime) SetConsensusBalance(addr types.Address, amount quantity.Quantity) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.latest().state.consensusBalances[addr] = *amount.Clone()
+}
+
+// ConsensusBalance returns the consensus layer balance of the given account.
+func (r *Runtime) ConsensusBalance(addr types.Address) quantity.Quantity {
+	r.Lock()
+	defer r.Unlock()
+
+	amount := r.latest().state.consensusBalances[addr]
+	return *amount.Clone()
+}
+
+// SetEVMCode sets the code stored at the given EVM address in the latest round.
+func (r *Runtime) SetEVMCode(address []byte, code []byte) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.latest().state.evmCode[toEVMAddress(address)] = append([]byte{}, code...)
+}
+
+// SetEVMStorage sets the value of the given EVM storage slot in the latest round.
+func (r *Runtime) SetEVMStorage(address []byte, index []byte, value []byte) {
+	r.Lock()
+	defer r.Unlock()
+
+	key := evmStorageKey{address: toEVMAddress(address)}
+	copy(key.index[:], index)
+	r.latest().state.evmStorage[key] = append([]byte{}, value...)
+}
+
+// NextBlock produces a block without transactions, processing any pending consensus messages. It
+// returns the round of the new block.
+func (r *Runtime) NextBlock() uint64 {
+	r.Lock()
+	defer r.Unlock()
+
+	rd := r.beginRound()
+	return r.finalizeRound(rd)
+}
+
+func (r *Runtime) latest() *roundData {
+	return r.rounds[len(r.rounds)-1]
+}
+
+func (r *Runtime) round(round uint64) (*roundData, error) {
+	if round == client.RoundLatest {
+		return r.latest(), nil
+	}
+	if round >= uint64(len(r.rounds)) {
+		return nil, roothash.ErrNotFound
+	}
+	return r.rounds[round], nil
+}
+
+// beginRound starts a new round on top of the latest state and processes the consensus messages
+// emitted in the previous round.
+func (r *Runtime) beginRound() *roundData {
+	prev := r.latest()
+	rd := &roundData{
+		block: block.NewEmptyBlock(prev.block, uint64(time.Now().Unix()), block.Normal),
+		state: prev.state.clone(),
+	}
+
+	pending := r.pending
+	r.pending = nil
+	for _, msg := range pending {
+		result := msg.process(rd)
+		emitter := r.rounds[msg.round]
+		emitter.messageResults = append(emitter.messageResults, result)
+	}
+	return rd
+}
+
+func (r *Runtime) finalizeRound(rd *roundData) uint64 {
+	r.rounds = append(r.rounds, rd)
+	r.blockNotifier.Broadcast(&roothash.AnnotatedBlock{
+		Height: int64(rd.block.Header.Round),
+		Block:  rd.block,
+	})
+	return rd.block.Header.Round
+}
+
+// Implements client.RuntimeClient.
+func (r *Runtime) GetInfo(ctx context.Context) (*types.RuntimeInfo, error) {
+	return r.info, nil
+}
+
+// Implements client.RuntimeClient.
+func (r *Runtime) SubmitTxRaw(ctx context.Context, tx *types.UnverifiedTransaction) (*types.CallResult, error) {
+	meta, err := r.SubmitTxRawMeta(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if meta.CheckTxError != nil {
+		return nil, checkTxError(meta.CheckTxError)
+	}
+	return &meta.Result, nil
+}
+
+// Implements client.RuntimeClient.
+func (r *Runtime) SubmitTxRawMeta(ctx context.Context, tx *types.UnverifiedTransaction) (*client.SubmitTxRawMeta, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	verified, failed := r.checkTx(r.latest().state, tx)
+	if failed != nil {
+		return &client.SubmitTxRawMeta{
+			TransactionMeta: client.TransactionMeta{
+				CheckTxError: &client.CheckTxError{
+					Module:  failed.Module,
+					Code:    failed.Code,
+					Message: failed.Message,
+				},
+			},
+		}, nil
+	}
+
+	rd := r.beginRound()
+	result, events := r.executeTx(rd, verified)
+	rd.txs = append(rd.txs, &client.TransactionWithResults{
+		Tx:     *tx,
+		Result: *result,
+		Events: events,
+	})
+	rd.events = append(rd.events, events...)
+	round := r.finalizeRound(rd)
+
+	return &client.SubmitTxRawMeta{
+		Result: *result,
+		TransactionMeta: client.TransactionMeta{
+			Round: round,
+		},
+	}, nil
+}
+
+// Implements client.RuntimeClient.
+func (r *Runtime) SubmitTx(ctx context.Context, tx *types.UnverifiedTransaction) (cbor.RawMessage, error) {
+	result, err := r.SubmitTxRaw(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if !result.IsSuccess() {
+		return nil, result.Failed
+	}
+	return result.Ok, nil
+}
+
+// Implements client.RuntimeClient.
+func (r *Runtime) SubmitTxMeta(ctx context.Context, tx *types.UnverifiedTransaction) (*client.SubmitTxMeta, error) {
+	meta, err := r.SubmitTxRawMeta(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if meta.CheckTxError != nil {
+		return &client.SubmitTxMeta{TransactionMeta: meta.TransactionMeta}, nil
+	}
+	if !meta.Result.IsSuccess() {
+		return &client.SubmitTxMeta{TransactionMeta: meta.TransactionMeta}, meta.Result.Failed
+	}
+	return &client.SubmitTxMeta{
+		Result:          meta.Result.Ok,
+		TransactionMeta: meta.TransactionMeta,
+	}, nil
+}
+
+// Implements client.RuntimeClient.
+func (r *Runtime) SubmitTxNoWait(ctx context.Context, tx *types.UnverifiedTransaction) error {
+	_, err := r.SubmitTxRaw(ctx, tx)
+	return err
+}
+
+// Implements client.RuntimeClient.
+func (r *Runtime) CheckTx(ctx context.Context, tx *types.UnverifiedTransaction) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if _, failed := r.checkTx(r.latest().state, tx); failed != nil {
+		return checkTxError(&client.CheckTxError{
+			Module:  failed.Module,
+			Code:    failed.Code,
+			Message: failed.Message,
+		})
+	}
+	return nil
+}
+
+func checkTxError(e *client.CheckTxError) error {
+	return cmnErrors.WithContext(coreClient.ErrCheckTxFailed,
+		fmt.Sprintf("module: %s code: %d message: %s", e.Module, e.Code, e.Message),
+	)
+}
+
+// Implements client.RuntimeClient.
+func (r *Runtime) GetGenesisBlock(ctx context.Context) (*block.Block, error) {
+	return r.GetBlock(ctx, 0)
+}
+
+// Implements client.RuntimeClient.
+func (r *Runtime) GetBlock(ctx context.Context, round uint64) (*block.Block, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	rd, err := r.round(round)
+	if err != nil {
+		return nil, err
+	}
+	return rd.block, nil
+}
+
+// Implements client.RuntimeClient.
+func (r *Runtime) GetTransactions(ctx context.Context, round uint64) ([]*types.UnverifiedTransaction, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	rd, err := r.round(round)
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]*types.UnverifiedTransaction, len(rd.txs))
+	for i, tx := range rd.txs {
+		ut := tx.Tx
+		txs[i] = &ut
+	}
+	return txs, nil
+}
+
+// Implements client.RuntimeClient.
+func (r *Runtime) GetTransactionsWithResults(ctx context.Context, round uint64) ([]*client.TransactionWithResults, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	rd, err := r.round(round)
+	if err != nil {
+		return nil, err
+	}
+	return append([]*client.TransactionWithResults{}, rd.txs...), nil
+}
+
+// Implements client.RuntimeClient.
+func (r *Runtime) GetEventsRaw(ctx context.Context, round uint64) ([]*types.Event, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	rd, err := r.round(round)
+	if err != nil {
+		return nil, err
+	}
+	return append([]*types.Event{}, rd.events...), nil
+}
+
+// Implements client.RuntimeClient.
+func (r *Runtime) GetEvents(ctx context.Context, round uint64, decoders []client.EventDecoder, includeUndecoded bool) ([]client.DecodedEvent, error) {
+	rawEvs, err := r.GetEventsRaw(ctx, round)
+	if err != nil {
+		return nil, err
+	}
+
+	evs := make([]client.DecodedEvent, 0)
+OUTER:
+	for _, ev := range rawEvs {
+		for _, decoder := range decoders {
+			decoded, err := decoder.DecodeEvent(ev)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode event: %w", err)
+			}
+			if decoded != nil {
+				evs = append(evs, decoded)
+				continue OUTER
+			}
+		}
+		if includeUndecoded {
+			evs = append(evs, ev)
+		}
+	}
+	return evs, nil
+}
+
+// Implements client.RuntimeClient.
+func (r *Runtime) GetEventsRange(ctx context.Context, startRound, endRound uint64, filter *client.EventFilter) ([]*client.RoundEvents, error) {
+	if err := client.ValidateEventsRange(startRound, endRound); err != nil {
+		return nil, err
+	}
+
+	var result []*client.RoundEvents
+	for round := startRound; ; round++ {
+		evs, err := r.GetEventsRaw(ctx, round)
+		if err != nil {
+			return nil, err
+		}
+		if evs = filter.FilterEvents(evs); len(evs) > 0 {
+			result = append(result, &client.RoundEvents{Round: round, Events: evs})
+		}
+		if round == endRound {
+			return result, nil
+		}
+	}
+}
+
+// Implements client.RuntimeClient.
+func (r *Runtime) GetMessageResults(ctx context.Context, round uint64) ([]*client.MessageResult, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	rd, err := r.round(round)
+	if err != nil {
+		return nil, err
+	}
+	return append([]*client.MessageResult{}, rd.messageResults...), nil
+}
+
+// Implements client.RuntimeClient.
+func (r *Runtime) GetStatus(ctx context.Context) (*client.RuntimeStatus, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	return &client.RuntimeStatus{
+		LatestRound:   r.latest().block.Header.Round,
+		StorageSynced: true,
+	}, nil
+}
+
+// Implements client.RuntimeClient.
+func (r *Runtime) WatchBlocks(ctx context.Context) (<-chan *roothash.AnnotatedBlock, pubsub.ClosableSubscription, error) {
+	sub := r.blockNotifier.Subscribe()
+	ch := make(chan *roothash.AnnotatedBlock)
+	sub.Unwrap(ch)
+	return ch, sub, nil
+}
+
+// Implements client.RuntimeClient.
+func (r *Runtime) WatchEvents(ctx context.Context, decoders []client.EventDecoder, includeUndecoded bool) (<-chan *client.BlockEvents, error) {
+	ch := make(chan *client.BlockEvents)
+
+	blkCh, blkSub, err := r.WatchBlocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer blkSub.Close()
+		defer close(ch)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case blk, ok := <-blkCh:
+				if !ok {
+					return
+				}
+
+				events, err := r.GetEvents(ctx, blk.Block.Header.Round, decoders, includeUndecoded)
+				if err != nil {
+					return
+				}
+				select {
+				case ch <- &client.BlockEvents{Round: blk.Block.Header.Round, Events: events}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// Implements client.RuntimeClient.
+func (r *Runtime) Query(ctx context.Context, round uint64, method string, args, rsp interface{}) error {
+	r.Lock()
+	defer r.Unlock()
+
+	rd, err := r.round(round)
+	if err != nil {
+		return err
+	}
+	result, err := r.query(rd.state, method, cbor.Marshal(args))
+	if err != nil {
+		return err
+	}
+	if rsp != nil {
+		if err = cbor.Unmarshal(cbor.Marshal(result), rsp); err != nil {
+			return fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+	}
+	return nil
+}
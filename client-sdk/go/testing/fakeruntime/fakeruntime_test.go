@@ -0,0 +1,298 @@
+package fakeruntime
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/consensusaccounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/evm"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func nativeUnits(amount uint64) types.BaseUnits {
+	return types.NewBaseUnits(*quantity.NewFromUint64(amount), types.NativeDenomination)
+}
+
+func submit(ctx context.Context, t *testing.T, tb *client.TransactionBuilder, key sdkTesting.TestKey, nonce uint64, rsp interface{}) (*client.TransactionMeta, error) {
+	tb.AppendAuthSignature(key.SigSpec, nonce)
+	require.NoError(t, tb.AppendSign(ctx, key.Signer), "AppendSign")
+	return tb.SubmitTxMeta(ctx, rsp)
+}
+
+func TestAccounts(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rt := New(Config{})
+	rt.SetBalance(sdkTesting.Alice.Address, nativeUnits(100))
+	ac := accounts.NewV1(rt)
+
+	tb := ac.Transfer(sdkTesting.Bob.Address, nativeUnits(30)).SetFeeAmount(nativeUnits(10))
+	meta, err := submit(ctx, t, tb, sdkTesting.Alice, 0, nil)
+	require.NoError(err, "Transfer")
+	require.EqualValues(1, meta.Round)
+
+	balances, err := ac.Balances(ctx, client.RoundLatest, sdkTesting.Alice.Address)
+	require.NoError(err, "Balances")
+	require.Equal(*quantity.NewFromUint64(60), balances.Balances[types.NativeDenomination])
+	balances, err = ac.Balances(ctx, client.RoundLatest, sdkTesting.Bob.Address)
+	require.NoError(err, "Balances")
+	require.Equal(*quantity.NewFromUint64(30), balances.Balances[types.NativeDenomination])
+
+	// Queries at earlier rounds should see the earlier state.
+	balances, err = ac.Balances(ctx, 0, sdkTesting.Alice.Address)
+	require.NoError(err, "Balances at genesis")
+	require.Equal(*quantity.NewFromUint64(100), balances.Balances[types.NativeDenomination])
+
+	nonce, err := ac.Nonce(ctx, client.RoundLatest, sdkTesting.Alice.Address)
+	require.NoError(err, "Nonce")
+	require.EqualValues(1, nonce)
+
+	evs, err := ac.GetEvents(ctx, meta.Round)
+	require.NoError(err, "GetEvents")
+	require.Len(evs, 1)
+	require.NotNil(evs[0].Transfer)
+	require.Equal(sdkTesting.Alice.Address, evs[0].Transfer.From)
+
+	// Reusing a nonce should fail the transaction check.
+	tb = ac.Transfer(sdkTesting.Bob.Address, nativeUnits(1))
+	meta, err = submit(ctx, t, tb, sdkTesting.Alice, 0, nil)
+	require.NoError(err, "Transfer with stale nonce")
+	require.NotNil(meta.CheckTxError)
+	require.EqualValues(4, meta.CheckTxError.Code)
+
+	// Transferring more than the balance should fail the call, but still use up the nonce.
+	tb = ac.Transfer(sdkTesting.Bob.Address, nativeUnits(1000))
+	_, err = submit(ctx, t, tb, sdkTesting.Alice, 1, nil)
+	var failed *types.FailedCallResult
+	require.ErrorAs(err, &failed, "Transfer exceeding balance")
+	require.Equal(accounts.ModuleName, failed.Module)
+	require.EqualValues(2, failed.Code)
+
+	nonce, err = ac.Nonce(ctx, client.RoundLatest, sdkTesting.Alice.Address)
+	require.NoError(err, "Nonce")
+	require.EqualValues(2, nonce)
+
+	meta, err = submit(ctx, t, ac.TransferWithMemo(sdkTesting.Bob.Address, nativeUnits(5), []byte("invoice")), sdkTesting.Alice, 2, nil)
+	require.NoError(err, "TransferWithMemo")
+	evs, err = ac.GetEvents(ctx, meta.Round)
+	require.NoError(err, "GetEvents")
+	require.Len(evs, 1)
+	require.NotNil(evs[0].Transfer)
+	require.Equal([]byte("invoice"), evs[0].Transfer.Memo, "transfer events should include the memo")
+}
+
+func TestConsensusAccounts(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rt := New(Config{})
+	rt.SetConsensusBalance(sdkTesting.Alice.Address, *quantity.NewFromUint64(100))
+	ac := accounts.NewV1(rt)
+	ca := consensusaccounts.NewV1(rt)
+
+	meta, err := submit(ctx, t, ca.DepositWithReceipt(nativeUnits(40)), sdkTesting.Alice, 0, nil)
+	require.NoError(err, "Deposit")
+
+	// The deposit is only processed in the next round.
+	query := &consensusaccounts.ReceiptQuery{Address: sdkTesting.Alice.Address, ID: 0}
+	_, err = ca.Receipt(ctx, client.RoundLatest, query)
+	require.True(consensusaccounts.IsReceiptNotFound(err), "receipt should not exist before the deposit is processed")
+
+	round := rt.NextBlock()
+	receipt, err := ca.Receipt(ctx, round, query)
+	require.NoError(err, "Receipt")
+	require.True(receipt.IsSuccess())
+
+	results, err := rt.GetMessageResults(ctx, meta.Round)
+	require.NoError(err, "GetMessageResults")
+	require.Len(results, 1)
+	require.True(results[0].IsSuccess())
+
+	balances, err := ac.Balances(ctx, client.RoundLatest, sdkTesting.Alice.Address)
+	require.NoError(err, "Balances")
+	require.Equal(*quantity.NewFromUint64(40), balances.Balances[types.NativeDenomination])
+	require.Equal(*quantity.NewFromUint64(60), rt.ConsensusBalance(sdkTesting.Alice.Address))
+
+	evs, err := ac.GetEvents(ctx, round)
+	require.NoError(err, "GetEvents")
+	require.Len(evs, 1)
+	require.NotNil(evs[0].Mint)
+	caEvs, err := ca.GetEvents(ctx, round)
+	require.NoError(err, "GetEvents")
+	require.Len(caEvs, 1)
+	require.NotNil(caEvs[0].Deposit)
+	require.True(caEvs[0].Deposit.IsSuccess())
+	require.Equal(sdkTesting.Alice.Address, caEvs[0].Deposit.Address)
+	require.Equal(nativeUnits(40), caEvs[0].Deposit.Amount)
+
+	_, err = submit(ctx, t, ca.WithdrawWithMemo(nativeUnits(15), []byte("payout")), sdkTesting.Alice, 1, nil)
+	require.NoError(err, "Withdraw")
+	round = rt.NextBlock()
+	require.Equal(*quantity.NewFromUint64(75), rt.ConsensusBalance(sdkTesting.Alice.Address))
+	caEvs, err = ca.GetEvents(ctx, round)
+	require.NoError(err, "GetEvents")
+	require.Len(caEvs, 1)
+	require.NotNil(caEvs[0].Withdraw)
+	require.Equal([]byte("payout"), caEvs[0].Withdraw.Memo, "withdraw events should include the memo")
+
+	// Depositing more than the consensus balance should result in a failed receipt and event.
+	_, err = submit(ctx, t, ca.DepositWithMemo(nativeUnits(1000), []byte("top-up")), sdkTesting.Alice, 2, nil)
+	require.NoError(err, "Deposit exceeding balance")
+	round = rt.NextBlock()
+	caEvs, err = ca.GetEvents(ctx, round)
+	require.NoError(err, "GetEvents")
+	require.Len(caEvs, 1)
+	require.NotNil(caEvs[0].Deposit)
+	require.False(caEvs[0].Deposit.IsSuccess())
+	require.Equal([]byte("top-up"), caEvs[0].Deposit.Memo, "deposit events should include the memo")
+	evs, err = ac.GetEvents(ctx, round)
+	require.NoError(err, "GetEvents")
+	require.Empty(evs, "failed deposits should not mint")
+
+	_, err = submit(ctx, t, ca.DepositWithReceipt(nativeUnits(1000)), sdkTesting.Alice, 3, nil)
+	require.NoError(err, "Deposit exceeding balance")
+	round = rt.NextBlock()
+	receipt, err = ca.Receipt(ctx, round, &consensusaccounts.ReceiptQuery{Address: sdkTesting.Alice.Address, ID: 3})
+	require.NoError(err, "Receipt")
+	require.False(receipt.IsSuccess())
+
+	// Taking a receipt removes it.
+	var taken consensusaccounts.Receipt
+	_, err = submit(ctx, t, ca.TakeReceipt(3), sdkTesting.Alice, 4, &taken)
+	require.NoError(err, "TakeReceipt")
+	require.Equal(*receipt, taken)
+	_, err = ca.Receipt(ctx, client.RoundLatest, &consensusaccounts.ReceiptQuery{Address: sdkTesting.Alice.Address, ID: 3})
+	require.True(consensusaccounts.IsReceiptNotFound(err), "taken receipts should be removed")
+	_, err = submit(ctx, t, ca.TakeReceipt(3), sdkTesting.Alice, 5, nil)
+	var failed *types.FailedCallResult
+	require.ErrorAs(err, &failed, "taken receipts cannot be taken again")
+	require.Equal(consensusaccounts.ModuleName, failed.Module)
+	require.EqualValues(4, failed.Code)
+
+	// Only Ed25519 signers can interact with the consensus layer.
+	_, err = submit(ctx, t, ca.Withdraw(nativeUnits(1)), sdkTesting.Dave, 0, nil)
+	require.ErrorAs(err, &failed, "Withdraw with a Secp256k1 signer")
+	require.EqualValues(4, failed.Code)
+}
+
+func TestEVM(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rt := New(Config{
+		EVMCallHandler: func(call *EVMCall) ([]byte, error) {
+			if len(call.Data) == 0 {
+				return nil, fmt.Errorf("reverted")
+			}
+			return append(append([]byte{}, call.Code...), call.Data...), nil
+		},
+	})
+	ev := evm.NewV1(rt)
+
+	var address []byte
+	_, err := submit(ctx, t, ev.Create(nil, []byte{0x60, 0x00}), sdkTesting.Dave, 0, &address)
+	require.NoError(err, "Create")
+	require.Equal(CreateAddress(sdkTesting.Dave.EthAddress, 0), address)
+
+	code, err := ev.Code(ctx, address)
+	require.NoError(err, "Code")
+	require.Equal([]byte{0x60, 0x00}, code)
+
+	var output []byte
+	_, err = submit(ctx, t, ev.Call(address, nil, []byte{0x01}), sdkTesting.Dave, 1, &output)
+	require.NoError(err, "Call")
+	require.Equal([]byte{0x60, 0x00, 0x01}, output)
+
+	_, err = ev.SimulateCall(ctx, nil, 0, sdkTesting.Dave.EthAddress, address, nil, nil)
+	require.Error(err, "SimulateCall of a reverting call")
+	module, errCode := errors.Code(err)
+	require.Equal(evm.ModuleName, module)
+	require.EqualValues(2, errCode)
+
+	// Only Secp256k1 signers have an Ethereum address.
+	_, err = submit(ctx, t, ev.Create(nil, nil), sdkTesting.Alice, 0, nil)
+	var failed *types.FailedCallResult
+	require.ErrorAs(err, &failed, "Create with an Ed25519 signer")
+	require.EqualValues(3, failed.Code)
+}
+
+func TestCreateAddress(t *testing.T) {
+	require := require.New(t)
+
+	caller, _ := hex.DecodeString("6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0")
+	require.Equal("cd234a471b72ba2f1ccf0a70fcaba648a5eecd8d", hex.EncodeToString(CreateAddress(caller, 0)))
+	require.Equal("343c43a37d37dff08ae8c4a11544c718abb4fcf8", hex.EncodeToString(CreateAddress(caller, 1)))
+}
+
+func TestWatchBlocks(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rt := New(Config{})
+	ch, sub, err := rt.WatchBlocks(ctx)
+	require.NoError(err, "WatchBlocks")
+	defer sub.Close()
+
+	round := rt.NextBlock()
+	blk := <-ch
+	require.Equal(round, blk.Block.Header.Round)
+
+	status, err := rt.GetStatus(ctx)
+	require.NoError(err, "GetStatus")
+	require.Equal(round, status.LatestRound)
+
+	_, err = rt.GetBlock(ctx, round+1)
+	require.Error(err, "GetBlock for a future round")
+}
+
+func TestGetEventsRange(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rt := New(Config{})
+	rt.SetBalance(sdkTesting.Alice.Address, nativeUnits(100))
+	ac := accounts.NewV1(rt)
+
+	rt.NextBlock()
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		_, err := submit(ctx, t, ac.Transfer(sdkTesting.Bob.Address, nativeUnits(10)), sdkTesting.Alice, nonce, nil)
+		require.NoError(err, "Transfer")
+	}
+
+	evs, err := rt.GetEventsRange(ctx, 0, 3, nil)
+	require.NoError(err, "GetEventsRange")
+	require.Len(evs, 2, "rounds without events should be omitted")
+	require.EqualValues(2, evs[0].Round)
+	require.EqualValues(3, evs[1].Round)
+
+	evs, err = rt.GetEventsRange(ctx, 0, 3, &client.EventFilter{
+		Module: accounts.ModuleName,
+		Codes:  []uint32{accounts.TransferEventCode},
+	})
+	require.NoError(err, "GetEventsRange with filter")
+	require.Len(evs, 2)
+	for _, rev := range evs {
+		require.Len(rev.Events, 1)
+		require.EqualValues(accounts.TransferEventCode, rev.Events[0].Code)
+	}
+
+	evs, err = rt.GetEventsRange(ctx, 0, 3, &client.EventFilter{Module: evm.ModuleName})
+	require.NoError(err, "GetEventsRange with filter")
+	require.Empty(evs)
+
+	_, err = rt.GetEventsRange(ctx, 3, 2, nil)
+	require.Error(err, "GetEventsRange with start after end")
+	_, err = rt.GetEventsRange(ctx, 0, client.MaxEventsRange, nil)
+	require.Error(err, "GetEventsRange exceeding the maximum range")
+}
@@ -0,0 +1,679 @@
+package fakeruntime
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	cmnErrors "github.com/oasisprotocol/oasis-core/go/common/errors"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/consensusaccounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/core"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/evm"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// Module error codes, matching the ones in runtime-sdk.
+const (
+	errCoreInvalidTransaction     = 2
+	errCoreInvalidMethod          = 3
+	errCoreInvalidNonce           = 4
+	errCoreInsufficientFeeBalance = 5
+	errCoreInvalidCallFormat      = 18
+	errCoreGasPriceTooLow         = 20
+
+	errAccountsInsufficientBalance = 2
+
+	errConsensusInvalidDenomination = 2
+	errConsensusIncompatibleSigner  = 4
+
+	errConsensusAccountsInsufficientWithdrawBalance = 3
+	errConsensusAccountsReceiptNotFound             = 4
+
+	errEVMInvalidArgument     = 1
+	errEVMError               = 2
+	errEVMInvalidSignerType   = 3
+	errEVMInsufficientBalance = 6
+)
+
+// consensusModuleName is the name of the runtime's consensus module.
+const consensusModuleName = "consensus"
+
+type callHandler func(r *Runtime, ctx *txContext, body cbor.RawMessage) (interface{}, *types.FailedCallResult)
+
+type queryHandler func(r *Runtime, st *state, args cbor.RawMessage) (interface{}, error)
+
+var (
+	callHandlers = map[string]callHandler{
+		"accounts.Transfer":     (*Runtime).txAccountsTransfer,
+		"consensus.Deposit":     (*Runtime).txConsensusDeposit,
+		"consensus.Withdraw":    (*Runtime).txConsensusWithdraw,
+		"consensus.TakeReceipt": (*Runtime).txConsensusTakeReceipt,
+		"evm.Create":            (*Runtime).txEVMCreate,
+		"evm.Call":              (*Runtime).txEVMCall,
+	}
+
+	queryHandlers = map[string]queryHandler{
+		"core.Parameters":        (*Runtime).queryCoreParameters,
+		"core.MinGasPrice":       (*Runtime).queryCoreMinGasPrice,
+		"accounts.Parameters":    (*Runtime).queryAccountsParameters,
+		"accounts.Nonce":         (*Runtime).queryAccountsNonce,
+		"accounts.Balances":      (*Runtime).queryAccountsBalances,
+		"accounts.Addresses":     (*Runtime).queryAccountsAddresses,
+		"accounts.TotalSupplies": (*Runtime).queryAccountsTotalSupplies,
+		"consensus.Parameters":   (*Runtime).queryConsensusParameters,
+		"consensus.Denomination": (*Runtime).queryConsensusDenomination,
+		"consensus.Balance":      (*Runtime).queryConsensusBalance,
+		"consensus.Account":      (*Runtime).queryConsensusAccount,
+		"consensus.Receipt":      (*Runtime).queryConsensusReceipt,
+		"evm.Storage":            (*Runtime).queryEVMStorage,
+		"evm.Code":               (*Runtime).queryEVMCode,
+		"evm.Balance":            (*Runtime).queryEVMBalance,
+		"evm.SimulateCall":       (*Runtime).queryEVMSimulateCall,
+	}
+)
+
+func failed(module string, code uint32, format string, args ...interface{}) *types.FailedCallResult {
+	return &types.FailedCallResult{
+		Module:  module,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// txContext is the context of a transaction being executed.
+type txContext struct {
+	st *state
+	tx *types.Transaction
+
+	events   []*types.Event
+	messages []*pendingMessage
+}
+
+func (ctx *txContext) emitEvent(module string, code uint32, ev interface{}) {
+	ctx.events = append(ctx.events, &types.Event{
+		Module: module,
+		Code:   code,
+		Value:  cbor.Marshal(ev),
+	})
+}
+
+// signer returns the address and nonce of the first transaction signer.
+func (ctx *txContext) signer() (types.Address, uint64) {
+	si := ctx.tx.AuthInfo.SignerInfo[0]
+	addr, _ := si.AddressSpec.Address()
+	return addr, si.Nonce
+}
+
+// checkTx verifies the transaction and performs the checks done by the core module before
+// a transaction is accepted.
+func (r *Runtime) checkTx(st *state, ut *types.UnverifiedTransaction) (*types.Transaction, *types.FailedCallResult) {
+	tx, err := ut.Verify(r.info.ChainContext)
+	if err != nil {
+		return nil, failed(core.ModuleName, errCoreInvalidTransaction, "%s", err)
+	}
+	if tx.Call.Format != types.CallFormatPlain {
+		return nil, failed(core.ModuleName, errCoreInvalidCallFormat, "unsupported call format")
+	}
+	if _, ok := callHandlers[tx.Call.Method]; !ok {
+		return nil, failed(core.ModuleName, errCoreInvalidMethod, "invalid method: %s", tx.Call.Method)
+	}
+
+	for _, si := range tx.AuthInfo.SignerInfo {
+		addr, err := si.AddressSpec.Address()
+		if err != nil {
+			return nil, failed(core.ModuleName, errCoreInvalidTransaction, "%s", err)
+		}
+		if si.Nonce != st.nonces[addr] {
+			return nil, failed(core.ModuleName, errCoreInvalidNonce, "invalid nonce")
+		}
+	}
+
+	fee := tx.AuthInfo.Fee
+	if mgp, ok := r.cfg.CoreParameters.MinGasPrice[fee.Amount.Denomination]; ok {
+		required := quantity.NewFromUint64(fee.Gas)
+		if err = required.Mul(&mgp); err != nil {
+			return nil, failed(core.ModuleName, errCoreGasPriceTooLow, "%s", err)
+		}
+		if fee.Amount.Amount.Cmp(required) < 0 {
+			return nil, failed(core.ModuleName, errCoreGasPriceTooLow, "gas price too low")
+		}
+	}
+	payer, _ := tx.AuthInfo.SignerInfo[0].AddressSpec.Address()
+	balance := st.balance(payer, fee.Amount.Denomination)
+	if balance.Cmp(&fee.Amount.Amount) < 0 {
+		return nil, failed(core.ModuleName, errCoreInsufficientFeeBalance, "insufficient balance to pay fees")
+	}
+
+	return tx, nil
+}
+
+// executeTx executes a checked transaction in the given round.
+//
+// Nonces are incremented and fees are charged even if the call fails. Call handlers check all
+// preconditions before modifying state so that failed calls do not need to be reverted.
+func (r *Runtime) executeTx(rd *roundData, tx *types.Transaction) (*types.CallResult, []*types.Event) {
+	ctx := &txContext{st: rd.state, tx: tx}
+
+	for _, si := range tx.AuthInfo.SignerInfo {
+		addr, _ := si.AddressSpec.Address()
+		ctx.st.nonces[addr]++
+	}
+	payer, _ := ctx.signer()
+	ctx.st.transfer(payer, accounts.FeeAccumulatorAddress, &tx.AuthInfo.Fee.Amount)
+
+	result, fail := callHandlers[tx.Call.Method](r, ctx, tx.Call.Body)
+	if fail != nil {
+		return &types.CallResult{Failed: fail}, nil
+	}
+
+	for _, msg := range ctx.messages {
+		msg.round = rd.block.Header.Round
+		msg.index = uint32(len(r.pending))
+		r.pending = append(r.pending, msg)
+	}
+	return &types.CallResult{Ok: cbor.Marshal(result)}, ctx.events
+}
+
+func (r *Runtime) query(st *state, method string, args cbor.RawMessage) (interface{}, error) {
+	handler, ok := queryHandlers[method]
+	if !ok {
+		return nil, cmnErrors.FromCode(core.ModuleName, errCoreInvalidMethod, fmt.Sprintf("invalid method: %s", method))
+	}
+	return handler(r, st, args)
+}
+
+func decodeArgs(module string, args cbor.RawMessage, dst interface{}) error {
+	if err := cbor.Unmarshal(args, dst); err != nil {
+		return cmnErrors.FromCode(module, 1, fmt.Sprintf("malformed arguments: %s", err))
+	}
+	return nil
+}
+
+func (r *Runtime) txAccountsTransfer(ctx *txContext, body cbor.RawMessage) (interface{}, *types.FailedCallResult) {
+	var xfer accounts.Transfer
+	if err := cbor.Unmarshal(body, &xfer); err != nil {
+		return nil, failed(accounts.ModuleName, 1, "malformed body: %s", err)
+	}
+
+	from, _ := ctx.signer()
+	if !ctx.st.transfer(from, xfer.To, &xfer.Amount) {
+		return nil, failed(accounts.ModuleName, errAccountsInsufficientBalance, "insufficient balance")
+	}
+	ctx.emitEvent(accounts.ModuleName, accounts.TransferEventCode, &accounts.TransferEvent{
+		From:   from,
+		To:     xfer.To,
+		Amount: xfer.Amount,
+		Memo:   xfer.Memo,
+	})
+	return nil, nil
+}
+
+// consensusSigner returns the address of the transaction signer, making sure that it is
+// compatible with the consensus layer.
+func consensusSigner(ctx *txContext) (types.Address, uint64, *types.FailedCallResult) {
+	sis := ctx.tx.AuthInfo.SignerInfo
+	if len(sis) != 1 || sis[0].AddressSpec.Signature == nil || sis[0].AddressSpec.Signature.Ed25519 == nil {
+		return types.Address{}, 0, failed(consensusModuleName, errConsensusIncompatibleSigner, "consensus incompatible signer")
+	}
+	addr, nonce := ctx.signer()
+	return addr, nonce, nil
+}
+
+func (r *Runtime) txConsensusDeposit(ctx *txContext, body cbor.RawMessage) (interface{}, *types.FailedCallResult) {
+	var deposit consensusaccounts.Deposit
+	if err := cbor.Unmarshal(body, &deposit); err != nil {
+		return nil, failed(consensusaccounts.ModuleName, 1, "malformed body: %s", err)
+	}
+	addr, nonce, fail := consensusSigner(ctx)
+	if fail != nil {
+		return nil, fail
+	}
+	if deposit.Amount.Denomination != r.cfg.ConsensusDenomination {
+		return nil, failed(consensusModuleName, errConsensusInvalidDenomination, "invalid denomination")
+	}
+
+	ctx.messages = append(ctx.messages, &pendingMessage{
+		kind:    messageDeposit,
+		address: addr,
+		amount:  deposit.Amount,
+		memo:    deposit.Memo,
+		receipt: receiptID(deposit.Receipt, nonce),
+	})
+	return nil, nil
+}
+
+func (r *Runtime) txConsensusWithdraw(ctx *txContext, body cbor.RawMessage) (interface{}, *types.FailedCallResult) {
+	var withdraw consensusaccounts.Withdraw
+	if err := cbor.Unmarshal(body, &withdraw); err != nil {
+		return nil, failed(consensusaccounts.ModuleName, 1, "malformed body: %s", err)
+	}
+	addr, nonce, fail := consensusSigner(ctx)
+	if fail != nil {
+		return nil, fail
+	}
+	if withdraw.Amount.Denomination != r.cfg.ConsensusDenomination {
+		return nil, failed(consensusModuleName, errConsensusInvalidDenomination, "invalid denomination")
+	}
+	balance := ctx.st.balance(addr, withdraw.Amount.Denomination)
+	if balance.Cmp(&withdraw.Amount.Amount) < 0 {
+		return nil, failed(consensusaccounts.ModuleName, errConsensusAccountsInsufficientWithdrawBalance, "insufficient balance")
+	}
+
+	ctx.messages = append(ctx.messages, &pendingMessage{
+		kind:    messageWithdraw,
+		address: addr,
+		amount:  withdraw.Amount,
+		memo:    withdraw.Memo,
+		receipt: receiptID(withdraw.Receipt, nonce),
+	})
+	return nil, nil
+}
+
+func (r *Runtime) txConsensusTakeReceipt(ctx *txContext, body cbor.RawMessage) (interface{}, *types.FailedCallResult) {
+	var take consensusaccounts.TakeReceipt
+	if err := cbor.Unmarshal(body, &take); err != nil {
+		return nil, failed(consensusaccounts.ModuleName, 1, "malformed body: %s", err)
+	}
+	addr, _ := ctx.signer()
+	key := receiptKey{address: addr, id: take.ID}
+	receipt, ok := ctx.st.receipts[key]
+	if !ok {
+		return nil, failed(consensusaccounts.ModuleName, errConsensusAccountsReceiptNotFound, "receipt not found")
+	}
+	delete(ctx.st.receipts, key)
+	return &receipt, nil
+}
+
+func receiptID(requested bool, nonce uint64) *uint64 {
+	if !requested {
+		return nil
+	}
+	return &nonce
+}
+
+// evmCaller returns the Ethereum address of the transaction signer.
+func evmCaller(ctx *txContext) ([]byte, *types.FailedCallResult) {
+	spec := ctx.tx.AuthInfo.SignerInfo[0].AddressSpec.Signature
+	if spec == nil || spec.Secp256k1Eth == nil {
+		return nil, failed(evm.ModuleName, errEVMInvalidSignerType, "invalid signer type")
+	}
+	untaggedPk, _ := spec.Secp256k1Eth.MarshalBinaryUncompressedUntagged()
+	return keccak256(untaggedPk)[32-20:], nil
+}
+
+// evmTransfer moves the value of an EVM call between the accounts the Ethereum addresses map to.
+func evmTransfer(st *state, from, to []byte, value []byte) *types.FailedCallResult {
+	amount, fail := evmValue(value)
+	if fail != nil {
+		return fail
+	}
+	if amount.Amount.IsZero() {
+		return nil
+	}
+	if !st.transfer(evmAccount(from), evmAccount(to), amount) {
+		return failed(evm.ModuleName, errEVMInsufficientBalance, "insufficient balance")
+	}
+	return nil
+}
+
+func evmValue(value []byte) (*types.BaseUnits, *types.FailedCallResult) {
+	if len(value) > 32 {
+		return nil, failed(evm.ModuleName, errEVMInvalidArgument, "invalid argument")
+	}
+	var amount quantity.Quantity
+	if err := amount.FromBigInt(new(big.Int).SetBytes(value)); err != nil {
+		return nil, failed(evm.ModuleName, errEVMInvalidArgument, "invalid argument")
+	}
+	bu := types.NewBaseUnits(amount, types.NativeDenomination)
+	return &bu, nil
+}
+
+func (r *Runtime) txEVMCreate(ctx *txContext, body cbor.RawMessage) (interface{}, *types.FailedCallResult) {
+	var create evm.Create
+	if err := cbor.Unmarshal(body, &create); err != nil {
+		return nil, failed(evm.ModuleName, errEVMInvalidArgument, "malformed body: %s", err)
+	}
+	caller, fail := evmCaller(ctx)
+	if fail != nil {
+		return nil, fail
+	}
+	_, nonce := ctx.signer()
+	address := CreateAddress(caller, nonce)
+	if fail = evmTransfer(ctx.st, caller, address, create.Value); fail != nil {
+		return nil, fail
+	}
+
+	// The init code is not executed, so it is stored as the contract code.
+	ctx.st.evmCode[toEVMAddress(address)] = create.InitCode
+	return address, nil
+}
+
+func (r *Runtime) txEVMCall(ctx *txContext, body cbor.RawMessage) (interface{}, *types.FailedCallResult) {
+	var call evm.Call
+	if err := cbor.Unmarshal(body, &call); err != nil {
+		return nil, failed(evm.ModuleName, errEVMInvalidArgument, "malformed body: %s", err)
+	}
+	caller, fail := evmCaller(ctx)
+	if fail != nil {
+		return nil, fail
+	}
+	amount, fail := evmValue(call.Value)
+	if fail != nil {
+		return nil, fail
+	}
+	balance := ctx.st.balance(evmAccount(caller), amount.Denomination)
+	if balance.Cmp(&amount.Amount) < 0 {
+		return nil, failed(evm.ModuleName, errEVMInsufficientBalance, "insufficient balance")
+	}
+
+	output, err := r.evmCall(ctx.st, &EVMCall{
+		Caller:  caller,
+		Address: call.Address,
+		Value:   call.Value,
+		Data:    call.Data,
+	})
+	if err != nil {
+		return nil, failed(evm.ModuleName, errEVMError, "%s", err)
+	}
+	if fail = evmTransfer(ctx.st, caller, call.Address, call.Value); fail != nil {
+		return nil, fail
+	}
+	return output, nil
+}
+
+func (r *Runtime) evmCall(st *state, call *EVMCall) ([]byte, error) {
+	if r.cfg.EVMCallHandler == nil {
+		return nil, fmt.Errorf("no call handler configured")
+	}
+	call.Code = st.evmCode[toEVMAddress(call.Address)]
+	return r.cfg.EVMCallHandler(call)
+}
+
+func (r *Runtime) queryCoreParameters(st *state, args cbor.RawMessage) (interface{}, error) {
+	return &r.cfg.CoreParameters, nil
+}
+
+func (r *Runtime) queryCoreMinGasPrice(st *state, args cbor.RawMessage) (interface{}, error) {
+	mgp := r.cfg.CoreParameters.MinGasPrice
+	if mgp == nil {
+		mgp = make(map[types.Denomination]types.Quantity)
+	}
+	return mgp, nil
+}
+
+func (r *Runtime) queryAccountsParameters(st *state, args cbor.RawMessage) (interface{}, error) {
+	return &accounts.Parameters{}, nil
+}
+
+func (r *Runtime) queryAccountsNonce(st *state, args cbor.RawMessage) (interface{}, error) {
+	var query accounts.NonceQuery
+	if err := decodeArgs(accounts.ModuleName, args, &query); err != nil {
+		return nil, err
+	}
+	return st.nonces[query.Address], nil
+}
+
+func (r *Runtime) queryAccountsBalances(st *state, args cbor.RawMessage) (interface{}, error) {
+	var query accounts.BalancesQuery
+	if err := decodeArgs(accounts.ModuleName, args, &query); err != nil {
+		return nil, err
+	}
+	balances := accounts.AccountBalances{Balances: make(map[types.Denomination]types.Quantity)}
+	for denom, amount := range st.balances[query.Address] {
+		balances.Balances[denom] = amount
+	}
+	return &balances, nil
+}
+
+func (r *Runtime) queryAccountsAddresses(st *state, args cbor.RawMessage) (interface{}, error) {
+	var query accounts.AddressesQuery
+	if err := decodeArgs(accounts.ModuleName, args, &query); err != nil {
+		return nil, err
+	}
+	addresses := make(accounts.Addresses, 0)
+	for addr, balances := range st.balances {
+		if _, ok := balances[query.Denomination]; ok {
+			addresses = append(addresses, addr)
+		}
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return bytes.Compare(addresses[i][:], addresses[j][:]) < 0
+	})
+	return addresses, nil
+}
+
+func (r *Runtime) queryAccountsTotalSupplies(st *state, args cbor.RawMessage) (interface{}, error) {
+	return st.totalSupplies(), nil
+}
+
+func (r *Runtime) queryConsensusParameters(st *state, args cbor.RawMessage) (interface{}, error) {
+	return &consensusaccounts.Parameters{}, nil
+}
+
+func (r *Runtime) queryConsensusDenomination(st *state, args cbor.RawMessage) (interface{}, error) {
+	return r.cfg.ConsensusDenomination, nil
+}
+
+func (r *Runtime) queryConsensusBalance(st *state, args cbor.RawMessage) (interface{}, error) {
+	var query consensusaccounts.BalanceQuery
+	if err := decodeArgs(consensusaccounts.ModuleName, args, &query); err != nil {
+		return nil, err
+	}
+	return &consensusaccounts.AccountBalance{Balance: st.consensusBalances[query.Address]}, nil
+}
+
+func (r *Runtime) queryConsensusAccount(st *state, args cbor.RawMessage) (interface{}, error) {
+	var query consensusaccounts.AccountQuery
+	if err := decodeArgs(consensusaccounts.ModuleName, args, &query); err != nil {
+		return nil, err
+	}
+	return &staking.Account{
+		General: staking.GeneralAccount{Balance: st.consensusBalances[query.Address]},
+	}, nil
+}
+
+func (r *Runtime) queryConsensusReceipt(st *state, args cbor.RawMessage) (interface{}, error) {
+	var query consensusaccounts.ReceiptQuery
+	if err := decodeArgs(consensusaccounts.ModuleName, args, &query); err != nil {
+		return nil, err
+	}
+	receipt, ok := st.receipts[receiptKey{address: query.Address, id: query.ID}]
+	if !ok {
+		return nil, cmnErrors.FromCode(consensusaccounts.ModuleName, errConsensusAccountsReceiptNotFound, "receipt not found")
+	}
+	return &receipt, nil
+}
+
+func (r *Runtime) queryEVMStorage(st *state, args cbor.RawMessage) (interface{}, error) {
+	var query evm.StorageQuery
+	if err := decodeArgs(evm.ModuleName, args, &query); err != nil {
+		return nil, err
+	}
+	key := evmStorageKey{address: toEVMAddress(query.Address)}
+	copy(key.index[:], query.Index)
+	if value, ok := st.evmStorage[key]; ok {
+		return value, nil
+	}
+	return make([]byte, 32), nil
+}
+
+func (r *Runtime) queryEVMCode(st *state, args cbor.RawMessage) (interface{}, error) {
+	var query evm.CodeQuery
+	if err := decodeArgs(evm.ModuleName, args, &query); err != nil {
+		return nil, err
+	}
+	code := st.evmCode[toEVMAddress(query.Address)]
+	if code == nil {
+		code = []byte{}
+	}
+	return code, nil
+}
+
+func (r *Runtime) queryEVMBalance(st *state, args cbor.RawMessage) (interface{}, error) {
+	var query evm.BalanceQuery
+	if err := decodeArgs(evm.ModuleName, args, &query); err != nil {
+		return nil, err
+	}
+	balance := st.balance(evmAccount(query.Address), types.NativeDenomination)
+	return &balance, nil
+}
+
+func (r *Runtime) queryEVMSimulateCall(st *state, args cbor.RawMessage) (interface{}, error) {
+	var query evm.SimulateCallQuery
+	if err := decodeArgs(evm.ModuleName, args, &query); err != nil {
+		return nil, err
+	}
+	output, err := r.evmCall(st, &EVMCall{
+		Caller:    query.Caller,
+		Address:   query.Address,
+		Value:     query.Value,
+		Data:      query.Data,
+		Simulated: true,
+	})
+	if err != nil {
+		return nil, cmnErrors.FromCode(evm.ModuleName, errEVMError, err.Error())
+	}
+	return output, nil
+}
+
+type messageKind uint8
+
+const (
+	messageDeposit messageKind = iota
+	messageWithdraw
+)
+
+// pendingMessage is a consensus message emitted by a transaction that has not yet been processed
+// by the consensus layer.
+type pendingMessage struct {
+	kind    messageKind
+	round   uint64
+	index   uint32
+	address types.Address
+	amount  types.BaseUnits
+	memo    []byte
+	receipt *uint64
+}
+
+// process executes the message against the consensus layer ledger and handles its result in the
+// given round, returning the message result.
+func (m *pendingMessage) process(rd *roundData) *client.MessageResult {
+	st := rd.state
+	var err error
+	switch m.kind {
+	case messageDeposit:
+		balance := st.consensusBalances[m.address]
+		if err = balance.Sub(&m.amount.Amount); err != nil {
+			err = staking.ErrInsufficientBalance
+			break
+		}
+		st.consensusBalances[m.address] = balance
+	case messageWithdraw:
+		if !st.burn(m.address, &m.amount) {
+			err = staking.ErrInsufficientBalance
+			break
+		}
+		balance := st.consensusBalances[m.address]
+		_ = balance.Add(&m.amount.Amount)
+		st.consensusBalances[m.address] = balance
+	}
+
+	result := &client.MessageResult{Index: m.index}
+	if err != nil {
+		result.Module, result.Code = cmnErrors.Code(err)
+	}
+	if m.receipt != nil {
+		st.receipts[receiptKey{address: m.address, id: *m.receipt}] = consensusaccounts.Receipt{
+			Amount: m.amount,
+			Module: result.Module,
+			Code:   result.Code,
+		}
+	}
+
+	// Like the runtime, emit the consensus accounts event regardless of the outcome, followed by
+	// the accounts event in case of success.
+	switch m.kind {
+	case messageDeposit:
+		rd.emitEvent(consensusaccounts.ModuleName, consensusaccounts.DepositEventCode, &consensusaccounts.DepositEvent{
+			Address: m.address,
+			Amount:  m.amount,
+			Memo:    m.memo,
+			Module:  result.Module,
+			Code:    result.Code,
+		})
+		if err == nil {
+			st.mint(m.address, &m.amount)
+			rd.emitEvent(accounts.ModuleName, accounts.MintEventCode, &accounts.MintEvent{
+				Owner:  m.address,
+				Amount: m.amount,
+			})
+		}
+	case messageWithdraw:
+		rd.emitEvent(consensusaccounts.ModuleName, consensusaccounts.WithdrawEventCode, &consensusaccounts.WithdrawEvent{
+			Address: m.address,
+			Amount:  m.amount,
+			Memo:    m.memo,
+			Module:  result.Module,
+			Code:    result.Code,
+		})
+		if err == nil {
+			rd.emitEvent(accounts.ModuleName, accounts.BurnEventCode, &accounts.BurnEvent{
+				Owner:  m.address,
+				Amount: m.amount,
+			})
+		}
+	}
+	return result
+}
+
+func (rd *roundData) emitEvent(module string, code uint32, ev interface{}) {
+	rd.events = append(rd.events, &types.Event{
+		Module: module,
+		Code:   code,
+		Value:  cbor.Marshal(ev),
+	})
+}
+
+// CreateAddress derives the address of a contract created by the given caller with the given
+// nonce, as for the legacy CREATE scheme.
+func CreateAddress(caller []byte, nonce uint64) []byte {
+	var encNonce []byte
+	switch {
+	case nonce == 0:
+		encNonce = []byte{0x80}
+	case nonce < 0x80:
+		encNonce = []byte{byte(nonce)}
+	default:
+		raw := new(big.Int).SetUint64(nonce).Bytes()
+		encNonce = append([]byte{0x80 + byte(len(raw))}, raw...)
+	}
+
+	payload := append([]byte{0x80 + byte(len(caller))}, caller...)
+	payload = append(payload, encNonce...)
+	return keccak256(append([]byte{0xc0 + byte(len(payload))}, payload...))[32-20:]
+}
+
+// evmAccount returns the runtime account the given Ethereum address maps to.
+func evmAccount(address []byte) types.Address {
+	return types.NewAddressRaw(types.AddressV0Secp256k1EthContext, address)
+}
+
+func toEVMAddress(address []byte) (a [20]byte) {
+	copy(a[:], address)
+	return
+}
+
+func keccak256(data []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+	return h.Sum(nil)
+}
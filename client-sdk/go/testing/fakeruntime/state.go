@@ -0,0 +1,129 @@
+package fakeruntime
+
+import (
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/consensusaccounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+type receiptKey struct {
+	address types.Address
+	id      uint64
+}
+
+type evmStorageKey struct {
+	address [20]byte
+	index   [32]byte
+}
+
+// state is the runtime state at the end of a round, together with the consensus layer ledger.
+type state struct {
+	nonces   map[types.Address]uint64
+	balances map[types.Address]map[types.Denomination]quantity.Quantity
+
+	consensusBalances map[types.Address]quantity.Quantity
+	receipts          map[receiptKey]consensusaccounts.Receipt
+
+	evmCode    map[[20]byte][]byte
+	evmStorage map[evmStorageKey][]byte
+}
+
+func newState() *state {
+	return &state{
+		nonces:            make(map[types.Address]uint64),
+		balances:          make(map[types.Address]map[types.Denomination]quantity.Quantity),
+		consensusBalances: make(map[types.Address]quantity.Quantity),
+		receipts:          make(map[receiptKey]consensusaccounts.Receipt),
+		evmCode:           make(map[[20]byte][]byte),
+		evmStorage:        make(map[evmStorageKey][]byte),
+	}
+}
+
+// clone returns a deep copy of the state.
+//
+// Stored byte slices are never modified in place, so they are shared between copies.
+func (s *state) clone() *state {
+	c := newState()
+	for addr, nonce := range s.nonces {
+		c.nonces[addr] = nonce
+	}
+	for addr, balances := range s.balances {
+		cb := make(map[types.Denomination]quantity.Quantity, len(balances))
+		for denom, amount := range balances {
+			cb[denom] = *amount.Clone()
+		}
+		c.balances[addr] = cb
+	}
+	for addr, amount := range s.consensusBalances {
+		c.consensusBalances[addr] = *amount.Clone()
+	}
+	for key, receipt := range s.receipts {
+		c.receipts[key] = receipt
+	}
+	for addr, code := range s.evmCode {
+		c.evmCode[addr] = code
+	}
+	for key, value := range s.evmStorage {
+		c.evmStorage[key] = value
+	}
+	return c
+}
+
+func (s *state) balance(addr types.Address, denom types.Denomination) quantity.Quantity {
+	amount := s.balances[addr][denom]
+	return *amount.Clone()
+}
+
+func (s *state) setBalance(addr types.Address, denom types.Denomination, amount quantity.Quantity) {
+	balances := s.balances[addr]
+	if balances == nil {
+		balances = make(map[types.Denomination]quantity.Quantity)
+		s.balances[addr] = balances
+	}
+	if amount.IsZero() {
+		delete(balances, denom)
+		return
+	}
+	balances[denom] = amount
+}
+
+// mint credits the given amount to the account.
+func (s *state) mint(addr types.Address, amount *types.BaseUnits) {
+	balance := s.balance(addr, amount.Denomination)
+	_ = balance.Add(&amount.Amount)
+	s.setBalance(addr, amount.Denomination, balance)
+}
+
+// burn debits the given amount from the account, returning false in case the account does not
+// have enough balance.
+func (s *state) burn(addr types.Address, amount *types.BaseUnits) bool {
+	balance := s.balance(addr, amount.Denomination)
+	if balance.Sub(&amount.Amount) != nil {
+		return false
+	}
+	s.setBalance(addr, amount.Denomination, balance)
+	return true
+}
+
+// transfer moves the given amount between accounts, returning false in case the source account
+// does not have enough balance.
+func (s *state) transfer(from, to types.Address, amount *types.BaseUnits) bool {
+	if !s.burn(from, amount) {
+		return false
+	}
+	s.mint(to, amount)
+	return true
+}
+
+func (s *state) totalSupplies() map[types.Denomination]types.Quantity {
+	supplies := make(map[types.Denomination]types.Quantity)
+	for _, balances := range s.balances {
+		for denom, amount := range balances {
+			total := supplies[denom]
+			_ = total.Add(&amount)
+			supplies[denom] = total
+		}
+	}
+	return supplies
+}
@@ -1,13 +1,20 @@
 package testing
 
 import (
+	"crypto/rand"
 	"crypto/sha512"
+	"fmt"
 
+	"golang.org/x/crypto/sha3"
+
+	voiSr25519 "github.com/oasisprotocol/curve25519-voi/primitives/sr25519"
+	coreSignature "github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 	memorySigner "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/memory"
 
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/secp256k1"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/sr25519"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
 )
 
@@ -16,36 +23,182 @@ type TestKey struct {
 	Signer  signature.Signer
 	Address types.Address
 	SigSpec types.SignatureAddressSpec
+
+	// ConsensusSigner is the Oasis Core signer for Ed25519 keys, which can also be used to sign
+	// consensus layer transactions. It is nil for other schemes.
+	ConsensusSigner coreSignature.Signer
+	// EthAddress is the Ethereum address for Secp256k1 keys. It is nil for other schemes.
+	EthAddress []byte
+	// EthPrivateKey is the Ethereum private key for Secp256k1 keys, which can be imported into
+	// Ethereum tooling. It is nil for other schemes.
+	EthPrivateKey []byte
+}
+
+// MultisigTestKey is a multisig account used for testing.
+type MultisigTestKey struct {
+	Config  *types.MultisigConfig
+	Signers []signature.Signer
+	Address types.Address
 }
 
 func newEd25519TestKey(seed string) TestKey {
-	signer := ed25519.WrapSigner(memorySigner.NewTestSigner(seed))
+	return ed25519TestKeyFromSigner(memorySigner.NewTestSigner(seed))
+}
+
+func ed25519TestKeyFromSigner(coreSigner coreSignature.Signer) TestKey {
+	signer := ed25519.WrapSigner(coreSigner)
 	sigspec := types.NewSignatureAddressSpecEd25519(signer.Public().(ed25519.PublicKey))
 	return TestKey{
-		Signer:  signer,
-		Address: types.NewAddress(sigspec),
-		SigSpec: sigspec,
+		Signer:          signer,
+		Address:         types.NewAddress(sigspec),
+		SigSpec:         sigspec,
+		ConsensusSigner: coreSigner,
 	}
 }
 
 func newSecp256k1TestKey(seed string) TestKey {
 	pk := sha512.Sum512_256([]byte(seed))
-	signer := secp256k1.NewSigner(pk[:])
+	return secp256k1TestKeyFromSecret(pk[:])
+}
+
+func secp256k1TestKeyFromSecret(pk []byte) TestKey {
+	signer := secp256k1.NewSigner(pk)
 	sigspec := types.NewSignatureAddressSpecSecp256k1Eth(signer.Public().(secp256k1.PublicKey))
+
+	h := sha3.NewLegacyKeccak256()
+	untaggedPk, _ := sigspec.Secp256k1Eth.MarshalBinaryUncompressedUntagged()
+	h.Write(untaggedPk)
+
+	return TestKey{
+		Signer:        signer,
+		Address:       types.NewAddress(sigspec),
+		SigSpec:       sigspec,
+		EthAddress:    h.Sum(nil)[32-20:],
+		EthPrivateKey: append([]byte{}, pk...),
+	}
+}
+
+func newSr25519TestKey(seed string) TestKey {
+	sk := sha512.Sum512_256([]byte(seed))
+	key, err := sr25519TestKeyFromSecret(sk[:])
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+func sr25519TestKeyFromSecret(sk []byte) (TestKey, error) {
+	msk, err := voiSr25519.NewMiniSecretKeyFromBytes(sk)
+	if err != nil {
+		return TestKey{}, err
+	}
+	signer := sr25519.NewSignerFromKeyPair(msk.ExpandEd25519().KeyPair())
+	sigspec := types.NewSignatureAddressSpecSr25519(signer.Public().(sr25519.PublicKey))
 	return TestKey{
 		Signer:  signer,
 		Address: types.NewAddress(sigspec),
 		SigSpec: sigspec,
+	}, nil
+}
+
+func randomSecret() ([]byte, error) {
+	var secret [32]byte
+	if _, err := rand.Read(secret[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return secret[:], nil
+}
+
+// NewRandomEd25519TestKey generates a fresh random Ed25519 test key.
+func NewRandomEd25519TestKey() (TestKey, error) {
+	coreSigner, err := memorySigner.NewSigner(rand.Reader)
+	if err != nil {
+		return TestKey{}, err
+	}
+	return ed25519TestKeyFromSigner(coreSigner), nil
+}
+
+// NewRandomSecp256k1TestKey generates a fresh random Secp256k1 test key.
+func NewRandomSecp256k1TestKey() (TestKey, error) {
+	secret, err := randomSecret()
+	if err != nil {
+		return TestKey{}, err
+	}
+	return secp256k1TestKeyFromSecret(secret), nil
+}
+
+// NewRandomSr25519TestKey generates a fresh random Sr25519 test key.
+func NewRandomSr25519TestKey() (TestKey, error) {
+	secret, err := randomSecret()
+	if err != nil {
+		return TestKey{}, err
+	}
+	return sr25519TestKeyFromSecret(secret)
+}
+
+func newMultisigTestKey(threshold uint64, keys ...TestKey) MultisigTestKey {
+	cfg := &types.MultisigConfig{Threshold: threshold}
+	signers := make([]signature.Signer, 0, len(keys))
+	for _, k := range keys {
+		cfg.Signers = append(cfg.Signers, types.MultisigSigner{
+			PublicKey: types.PublicKey{PublicKey: k.Signer.Public()},
+			Weight:    1,
+		})
+		signers = append(signers, k.Signer)
+	}
+	return MultisigTestKey{
+		Config:  cfg,
+		Signers: signers,
+		Address: types.NewAddressFromMultisig(cfg),
 	}
 }
 
 var (
-	// Alice is the test key A.
+	// Alice is the test key A (Ed25519).
+	//
+	// Address: oasis1qrec770vrek0a9a5lcrv0zvt22504k68svq7kzve
 	Alice = newEd25519TestKey("oasis-runtime-sdk/test-keys: alice")
-	// Bob is the test key A.
+	// Bob is the test key B (Ed25519).
+	//
+	// Address: oasis1qrydpazemvuwtnp3efm7vmfvg3tde044qg6cxwzx
 	Bob = newEd25519TestKey("oasis-runtime-sdk/test-keys: bob")
-	// Charlie is the test key C.
+	// Charlie is the test key C (Ed25519).
+	//
+	// Address: oasis1qr5kfjm8lx6mctjmwcx9225q5k3nxacqwqnjahkw
 	Charlie = newEd25519TestKey("oasis-runtime-sdk/test-keys: charlie")
-	// Dave is the test key D.
+	// Dave is the test key D (Secp256k1).
+	//
+	// Address: oasis1qrk58a6j2qn065m6p06jgjyt032f7qucy5wqeqpt
+	// Ethereum address: 0xdce075e1c39b1ae0b75d554558b6451a226ffe00
 	Dave = newSecp256k1TestKey("oasis-runtime-sdk/test-keys: dave")
+	// Erin is the test key E (Sr25519).
+	//
+	// Address: oasis1qzh2m8wysgty5hnjvwdwj87xyvlsy80zg5j3jpfn
+	Erin = newSr25519TestKey("oasis-runtime-sdk/test-keys: erin")
+	// Frank is the test key F (Secp256k1).
+	//
+	// Address: oasis1qzkrm2t8ydccwwm69zk0xz7c7ua68d5ppqkjx36l
+	// Ethereum address: 0x66f3fa9805018fb2bcbdf9b953126fc1f05a8f9c
+	Frank = newSecp256k1TestKey("oasis-runtime-sdk/test-keys: frank")
+	// Grace is the test key G (Ed25519).
+	//
+	// Address: oasis1qzcgnulwxev9q5adduxqmscvwta353yl0c64revy
+	Grace = newEd25519TestKey("oasis-runtime-sdk/test-keys: grace")
+	// Heidi is the test key H (Sr25519).
+	//
+	// Address: oasis1qqynj6qv4fgg952ndkth3cqc5azr7tjvacjc7c6d
+	Heidi = newSr25519TestKey("oasis-runtime-sdk/test-keys: heidi")
+
+	// AliceBobMultisig is a 2-of-2 multisig account of Alice and Bob.
+	//
+	// Address: oasis1qpcprk8jxpsjxw9fadxvzrv9ln7td69yus8rmtux
+	AliceBobMultisig = newMultisigTestKey(2, Alice, Bob)
+	// MixedMultisig is a 2-of-3 multisig account of Alice (Ed25519), Dave (Secp256k1) and Erin
+	// (Sr25519).
+	//
+	// Address: oasis1qrumxle3zhpv5vk6vkwp3g3smqatau8qdyr8t57e
+	MixedMultisig = newMultisigTestKey(2, Alice, Dave, Erin)
+
+	// TestKeys are all single-signer test keys.
+	TestKeys = []TestKey{Alice, Bob, Charlie, Dave, Erin, Frank, Grace, Heidi}
 )
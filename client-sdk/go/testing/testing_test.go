@@ -1,8 +1,11 @@
 package testing
 
 import (
+	"encoding/hex"
 	"fmt"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestPrintTestKeys(t *testing.T) {
@@ -11,3 +14,31 @@ func TestPrintTestKeys(t *testing.T) {
 	fmt.Printf("C: %v\n", Charlie.Signer.Public().String())
 	fmt.Printf("D: %v\n", Dave.Signer.Public().String())
 }
+
+func TestTestKeyAddresses(t *testing.T) {
+	require := require.New(t)
+
+	for _, tc := range []struct {
+		key     TestKey
+		address string
+	}{
+		{Alice, "oasis1qrec770vrek0a9a5lcrv0zvt22504k68svq7kzve"},
+		{Bob, "oasis1qrydpazemvuwtnp3efm7vmfvg3tde044qg6cxwzx"},
+		{Charlie, "oasis1qr5kfjm8lx6mctjmwcx9225q5k3nxacqwqnjahkw"},
+		{Dave, "oasis1qrk58a6j2qn065m6p06jgjyt032f7qucy5wqeqpt"},
+		{Erin, "oasis1qzh2m8wysgty5hnjvwdwj87xyvlsy80zg5j3jpfn"},
+		{Frank, "oasis1qzkrm2t8ydccwwm69zk0xz7c7ua68d5ppqkjx36l"},
+		{Grace, "oasis1qzcgnulwxev9q5adduxqmscvwta353yl0c64revy"},
+		{Heidi, "oasis1qqynj6qv4fgg952ndkth3cqc5azr7tjvacjc7c6d"},
+	} {
+		require.Equal(tc.address, tc.key.Address.String())
+	}
+	require.Equal("oasis1qpcprk8jxpsjxw9fadxvzrv9ln7td69yus8rmtux", AliceBobMultisig.Address.String())
+	require.Equal("oasis1qrumxle3zhpv5vk6vkwp3g3smqatau8qdyr8t57e", MixedMultisig.Address.String())
+
+	require.Equal("dce075e1c39b1ae0b75d554558b6451a226ffe00", hex.EncodeToString(Dave.EthAddress))
+	require.Equal("66f3fa9805018fb2bcbdf9b953126fc1f05a8f9c", hex.EncodeToString(Frank.EthAddress))
+	require.NotNil(Alice.ConsensusSigner, "Ed25519 keys should have a consensus signer")
+	require.Nil(Erin.ConsensusSigner, "Sr25519 keys should not have a consensus signer")
+	require.NoError(MixedMultisig.Config.ValidateBasic(), "multisig config should be valid")
+}
@@ -0,0 +1,40 @@
+//go:build ignore
+// +build ignore
+
+// Command gen_vectors regenerates the golden test vector files.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/vectors"
+)
+
+func main() {
+	dir := flag.String("dir", filepath.Join("..", "..", "..", "..", vectors.Dir), "directory to write the golden files to")
+	flag.Parse()
+
+	if err := generate(*dir); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate test vectors: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func generate(dir string) error {
+	files, err := vectors.Files()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for name, data := range files {
+		if err = os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil { //nolint: gosec
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+// Package vectors generates golden test vectors used to check that all SDKs derive addresses,
+// encode transactions and sign them the same way.
+//
+// The vectors are generated from a fixed set of inputs and stored in versioned golden files,
+// which are consumed by the test suites of the Go, Rust and TypeScript SDKs. After intentionally
+// changing any of the vectors, the golden files can be regenerated with go generate.
+package vectors
+
+//go:generate go run gen_vectors.go
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/secp256k1"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// Version is the version of the golden files. It must be bumped whenever the format of the
+// vectors changes in a way that breaks their consumers.
+const Version = 1
+
+// Dir is the directory containing the golden files, relative to the repository root.
+var Dir = fmt.Sprintf("tests/vectors/v%d", Version)
+
+// Golden file names.
+const (
+	AddressesFile    = "addresses.json"
+	TransactionsFile = "transactions.json"
+)
+
+// AddressVector is a test vector for address derivation.
+//
+// Exactly one of SigSpec, Multisig and Module is set.
+type AddressVector struct {
+	// SigSpec is the signature address specification the address is derived from.
+	SigSpec *types.SignatureAddressSpec `json:"sigspec,omitempty"`
+	// Multisig is the hex-encoded CBOR serialization of the multisig configuration the address is
+	// derived from.
+	Multisig string `json:"multisig,omitempty"`
+	// Module is the name of the module the address is derived from.
+	Module string `json:"module,omitempty"`
+	// Kind is the kind of the module address.
+	Kind string `json:"kind,omitempty"`
+
+	// Address is the Bech32-encoded address.
+	Address string `json:"address"`
+	// AddressRaw is the hex-encoded raw address.
+	AddressRaw string `json:"address_raw"`
+}
+
+// TransactionVector is a test vector for transaction encoding and signing.
+type TransactionVector struct {
+	// Description is a human readable description of the vector.
+	Description string `json:"description"`
+	// Method is the method of the transaction call.
+	Method string `json:"method"`
+	// Nonce is the nonce of the (only) signer of the transaction.
+	Nonce uint64 `json:"nonce"`
+	// SignatureContext is the domain separation context used for signing the transaction.
+	SignatureContext string `json:"signature_context"`
+
+	// Tx is the hex-encoded CBOR serialization of the transaction.
+	Tx string `json:"tx"`
+	// SignedTx is the hex-encoded CBOR serialization of the signed (unverified) transaction.
+	SignedTx string `json:"signed_tx"`
+}
+
+// chainContext is the chain context used for signing transaction vectors.
+var chainContext = func() signature.Context {
+	var runtimeID common.Namespace
+	_ = runtimeID.UnmarshalHex("8000000000000000000000000000000000000000000000000000000000000000")
+	return signature.DeriveChainContext(runtimeID, "643fb06848be7e970af3b5b2d772eb8cfb30499c8162bc18ac03df2f5e22520e")
+}()
+
+// Addresses returns the address derivation test vectors.
+func Addresses() []AddressVector {
+	sigspecs := []types.SignatureAddressSpec{
+		types.NewSignatureAddressSpecEd25519(ed25519.NewPublicKey("utrdHlX///////////////////////////////////8=")),
+		types.NewSignatureAddressSpecSecp256k1Eth(secp256k1.NewPublicKey("Arra3R5V////////////////////////////////////")),
+	}
+	for _, k := range []testing.TestKey{
+		testing.Alice,
+		testing.Bob,
+		testing.Charlie,
+		testing.Dave,
+		testing.Erin,
+		testing.Frank,
+		testing.Grace,
+		testing.Heidi,
+	} {
+		sigspecs = append(sigspecs, k.SigSpec)
+	}
+
+	var vectors []AddressVector
+	for i := range sigspecs {
+		vectors = append(vectors, newAddressVector(AddressVector{SigSpec: &sigspecs[i]}, types.NewAddress(sigspecs[i])))
+	}
+	for _, k := range []testing.MultisigTestKey{
+		testing.AliceBobMultisig,
+		testing.MixedMultisig,
+	} {
+		vectors = append(vectors, newAddressVector(AddressVector{Multisig: hex.EncodeToString(cbor.Marshal(k.Config))}, k.Address))
+	}
+	for _, m := range []struct {
+		module string
+		kind   string
+	}{
+		{"accounts", "common-pool"},
+		{"accounts", "fee-accumulator"},
+		{"consensus_accounts", "pending-withdrawal"},
+		{"rewards", "reward-pool"},
+	} {
+		vectors = append(vectors, newAddressVector(AddressVector{Module: m.module, Kind: m.kind}, types.NewAddressForModule(m.module, []byte(m.kind))))
+	}
+	return vectors
+}
+
+func newAddressVector(v AddressVector, addr types.Address) AddressVector {
+	raw, _ := addr.MarshalBinary()
+	v.Address = addr.String()
+	v.AddressRaw = hex.EncodeToString(raw)
+	return v
+}
+
+// Transactions returns the transaction encoding and signing test vectors.
+func Transactions() ([]TransactionVector, error) {
+	newTransfer := func() *types.Transaction {
+		return types.NewTransaction(&types.Fee{
+			Amount: types.NewBaseUnits(*quantity.NewFromUint64(100), types.NativeDenomination),
+			Gas:    1000,
+		}, "accounts.Transfer", &accounts.Transfer{
+			To:     testing.Charlie.Address,
+			Amount: types.NewBaseUnits(*quantity.NewFromUint64(1_000_000), types.NativeDenomination),
+		})
+	}
+
+	var vectors []TransactionVector
+	for _, tc := range []struct {
+		description string
+		nonce       uint64
+		key         *testing.TestKey
+		multisig    *testing.MultisigTestKey
+	}{
+		{"transfer signed with Ed25519", 0, &testing.Alice, nil},
+		{"transfer signed with Secp256k1", 42, &testing.Dave, nil},
+		{"transfer signed with a multisig", 1 << 40, nil, &testing.AliceBobMultisig},
+	} {
+		tx := newTransfer()
+		var signers []signature.Signer
+		switch {
+		case tc.key != nil:
+			tx.AppendAuthSignature(tc.key.SigSpec, tc.nonce)
+			signers = []signature.Signer{tc.key.Signer}
+		default:
+			tx.AppendAuthMultisig(tc.multisig.Config, tc.nonce)
+			signers = tc.multisig.Signers
+		}
+
+		ts := tx.PrepareForSigning()
+		for _, signer := range signers {
+			if err := ts.AppendSign(chainContext, signer); err != nil {
+				return nil, fmt.Errorf("vectors: %s: %w", tc.description, err)
+			}
+		}
+
+		vectors = append(vectors, TransactionVector{
+			Description:      tc.description,
+			Method:           tx.Call.Method,
+			Nonce:            tc.nonce,
+			SignatureContext: string(chainContext.New(types.SignatureContextBase)),
+			Tx:               hex.EncodeToString(cbor.Marshal(tx)),
+			SignedTx:         hex.EncodeToString(cbor.Marshal(ts.UnverifiedTransaction())),
+		})
+	}
+	return vectors, nil
+}
+
+// Files returns the contents of all golden files, keyed by file name.
+func Files() (map[string][]byte, error) {
+	txs, err := Transactions()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte)
+	for name, vectors := range map[string]interface{}{
+		AddressesFile:    Addresses(),
+		TransactionsFile: txs,
+	} {
+		data, err := json.MarshalIndent(vectors, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("vectors: failed to marshal %s: %w", name, err)
+		}
+		files[name] = append(data, '\n')
+	}
+	return files, nil
+}
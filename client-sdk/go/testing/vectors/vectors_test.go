@@ -0,0 +1,44 @@
+package vectors
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func TestGoldenFiles(t *testing.T) {
+	require := require.New(t)
+
+	files, err := Files()
+	require.NoError(err, "Files")
+	for name, data := range files {
+		golden, err := os.ReadFile(filepath.Join("..", "..", "..", "..", Dir, name))
+		require.NoError(err, "golden file %s should exist", name)
+		require.Equal(string(golden), string(data), "golden file %s is out of date (run go generate)", name)
+	}
+}
+
+func TestTransactionVectors(t *testing.T) {
+	require := require.New(t)
+
+	vectors, err := Transactions()
+	require.NoError(err, "Transactions")
+	for _, v := range vectors {
+		raw, err := hex.DecodeString(v.SignedTx)
+		require.NoError(err, v.Description)
+		var ut types.UnverifiedTransaction
+		require.NoError(cbor.Unmarshal(raw, &ut), v.Description)
+
+		tx, err := ut.Verify(chainContext)
+		require.NoError(err, "%s: signatures should verify", v.Description)
+		require.Equal(v.Method, tx.Call.Method, v.Description)
+		require.Equal(v.Tx, hex.EncodeToString(ut.Body), v.Description)
+	}
+}
@@ -0,0 +1,330 @@
+// Package txsender implements a sender of runtime transactions for high-throughput clients, like
+// bots, which sign and submit transactions of many accounts in parallel.
+//
+// Transactions are submitted in the order they are received for each account, with nonces handed
+// out by a nonce manager, as the runtime only accepts a transaction once the previous transactions
+// of its signer have been executed. Transactions of different accounts are pipelined, with a
+// configurable number of transactions submitted at once.
+//
+// Failures are retried with exponential backoff:
+//
+//   - Transactions that fail the transaction checks have not been executed, so they are signed
+//     again with a fresh nonce and resubmitted, in case the failure was caused by a nonce gap or a
+//     temporary lack of funds.
+//   - Transactions that are not confirmed within the submission timeout, e.g. because they were
+//     dropped by the node, and transactions whose submission failed may or may not have been
+//     executed, so the same signed transaction is broadcast again, which can only be executed
+//     once. Should the node report that its nonce has been used in the meantime, the outcome of
+//     the transaction is unknown and reported with ErrOutcomeUnknown, as resubmitting it with
+//     another nonce could execute it twice.
+package txsender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const (
+	// DefaultWorkers is the default number of transactions submitted at once.
+	DefaultWorkers = 16
+	// DefaultQueueSize is the default number of requests queued for each account.
+	DefaultQueueSize = 64
+	// DefaultMaxAttempts is the default number of attempts to submit a transaction.
+	DefaultMaxAttempts = 5
+	// DefaultRetryBackoff is the default delay before the first retry of a failed submission.
+	DefaultRetryBackoff = time.Second
+	// DefaultSubmitTimeout is the default time to wait for a submitted transaction to be executed
+	// before it is considered dropped and broadcast again.
+	DefaultSubmitTimeout = time.Minute
+)
+
+var (
+	// ErrOutcomeUnknown is the error of requests whose transaction may or may not have been
+	// executed.
+	ErrOutcomeUnknown = errors.New("txsender: transaction outcome unknown")
+	// ErrQueueFull is the error of requests that were rejected because the queue of their account
+	// was full.
+	ErrQueueFull = errors.New("txsender: queue full")
+)
+
+// Request is a request to send a transaction.
+type Request struct {
+	// Tx is the unsigned transaction, including its fee. Its signer information is set by the
+	// sender.
+	Tx *types.Transaction
+	// SigSpec is the signature address specification of the signer.
+	SigSpec types.SignatureAddressSpec
+	// Signer is the signer of the transaction.
+	Signer signature.Signer
+
+	// Done, if not nil, is called with the result of the request. It is called by the goroutine
+	// submitting the transactions of the signer, or by the goroutine running the sender in case
+	// the request is rejected, so it should not block.
+	Done func(*Result)
+}
+
+// Result is the result of a request.
+type Result struct {
+	// Request is the request.
+	Request *Request
+	// Nonce is the nonce of the last submitted transaction.
+	Nonce uint64
+	// Attempts is the number of attempts to submit the transaction.
+	Attempts int
+
+	// Meta is the metadata and call result of the executed transaction. It is nil in case the
+	// transaction was not executed. Note that the call of an executed transaction may have failed.
+	Meta *client.SubmitTxRawMeta
+	// Error is the error of the last attempt in case the transaction was not executed.
+	Error error
+}
+
+// Config is the sender configuration.
+type Config struct {
+	// Workers is the number of transactions submitted at once. If zero, DefaultWorkers is used.
+	Workers int
+	// QueueSize is the number of requests queued for each account. Requests exceeding it fail
+	// with ErrQueueFull, so that a slow account does not hold up the requests of other accounts.
+	// If zero, DefaultQueueSize is used.
+	QueueSize int
+	// MaxAttempts is the number of attempts to submit a transaction. If zero, DefaultMaxAttempts
+	// is used.
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry of a failed submission, which doubles with
+	// each retry. If zero, DefaultRetryBackoff is used.
+	RetryBackoff time.Duration
+	// SubmitTimeout is the time to wait for a submitted transaction to be executed. If zero,
+	// DefaultSubmitTimeout is used.
+	SubmitTimeout time.Duration
+
+	// Nonces is the nonce manager handing out the nonces of the transactions, which may be shared
+	// with other senders of the same accounts. If nil, a new nonce manager is used.
+	Nonces *accounts.NonceManager
+
+	// OnError, if not nil, is called for each failure that is retried, e.g. for logging.
+	OnError func(req *Request, err error)
+}
+
+// Sender sends runtime transactions.
+type Sender struct {
+	rc  client.RuntimeClient
+	cfg Config
+
+	sem chan struct{}
+}
+
+// Run sends the transactions of the requests received from the given channel, until the channel
+// is closed and all received requests are done, or the context is canceled. Requests that are not
+// done when the context is canceled fail with the error of the context.
+func (s *Sender) Run(ctx context.Context, reqs <-chan *Request) error {
+	info, err := s.rc.GetInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("txsender: failed to retrieve runtime info: %w", err)
+	}
+
+	lanes := make(map[types.Address]chan *Request)
+	var wg sync.WaitGroup
+	defer func() {
+		for _, lane := range lanes {
+			close(lane)
+		}
+		wg.Wait()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case req, ok := <-reqs:
+			if !ok {
+				return nil
+			}
+
+			address := types.NewAddress(req.SigSpec)
+			lane, ok := lanes[address]
+			if !ok {
+				lane = make(chan *Request, s.cfg.QueueSize)
+				lanes[address] = lane
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for req := range lane {
+						res := s.send(ctx, info.ChainContext, address, req)
+						if req.Done != nil {
+							req.Done(res)
+						}
+					}
+				}()
+			}
+
+			select {
+			case lane <- req:
+			default:
+				if req.Done != nil {
+					req.Done(&Result{Request: req, Error: ErrQueueFull})
+				}
+			}
+		}
+	}
+}
+
+// send signs and submits the transaction of the given request until it is executed or all
+// attempts have failed.
+func (s *Sender) send(ctx context.Context, chainContext signature.Context, address types.Address, req *Request) *Result {
+	res := &Result{Request: req}
+
+	var (
+		lease *accounts.NonceLease
+		utx   *types.UnverifiedTransaction
+		// broadcast is true iff the signed transaction may have been received by the node.
+		broadcast bool
+	)
+	backoff := s.cfg.RetryBackoff
+	for {
+		if ctx.Err() != nil {
+			res.Error = ctx.Err()
+			break
+		}
+		res.Attempts++
+
+		var err error
+		if utx == nil {
+			if lease, err = s.cfg.Nonces.Next(ctx, address); err != nil {
+				res.Error = err
+				if !s.retry(ctx, req, res, &backoff) {
+					return res
+				}
+				continue
+			}
+
+			tx := *req.Tx
+			tx.AuthInfo.SignerInfo = nil
+			tx.AppendAuthSignature(req.SigSpec, lease.Nonce)
+			ts := tx.PrepareForSigning()
+			if err = ts.AppendSign(chainContext, req.Signer); err != nil {
+				lease.Release()
+				res.Error = fmt.Errorf("txsender: failed to sign transaction: %w", err)
+				return res
+			}
+			utx = ts.UnverifiedTransaction()
+			res.Nonce = lease.Nonce
+			broadcast = false
+		}
+
+		meta, err := s.submit(ctx, utx)
+		switch {
+		case err == nil && meta.CheckTxError == nil:
+			lease.Commit()
+			res.Meta = meta
+			res.Error = nil
+			return res
+		case err == nil:
+			// The transaction was not executed, so it is signed again with a fresh nonce, unless an
+			// earlier broadcast may have been executed.
+			lease.Release()
+			lease, utx = nil, nil
+			res.Error = fmt.Errorf("txsender: transaction check failed: module %s code %d: %s",
+				meta.CheckTxError.Module,
+				meta.CheckTxError.Code,
+				meta.CheckTxError.Message,
+			)
+			if broadcast && !s.nonceUnused(ctx, address, res.Nonce) {
+				res.Error = fmt.Errorf("%w: %v", ErrOutcomeUnknown, res.Error)
+				return res
+			}
+			broadcast = false
+		case ctx.Err() != nil:
+			res.Error = ctx.Err()
+		default:
+			// The transaction may have been dropped, so it is broadcast again as is.
+			broadcast = true
+			res.Error = fmt.Errorf("txsender: failed to submit transaction: %w", err)
+		}
+
+		if !s.retry(ctx, req, res, &backoff) {
+			break
+		}
+	}
+
+	if lease != nil {
+		lease.Release()
+	}
+	if broadcast {
+		res.Error = fmt.Errorf("%w: %v", ErrOutcomeUnknown, res.Error)
+	}
+	return res
+}
+
+// retry waits before the next attempt to submit the transaction of the request, returning false
+// if there are no attempts left.
+func (s *Sender) retry(ctx context.Context, req *Request, res *Result, backoff *time.Duration) bool {
+	if res.Attempts >= s.cfg.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+	if s.cfg.OnError != nil {
+		s.cfg.OnError(req, res.Error)
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(*backoff):
+	}
+	*backoff *= 2
+	return true
+}
+
+// submit submits the transaction, waiting for a worker to become available.
+func (s *Sender) submit(ctx context.Context, utx *types.UnverifiedTransaction) (*client.SubmitTxRawMeta, error) {
+	select {
+	case s.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-s.sem }()
+
+	submitCtx, cancel := context.WithTimeout(ctx, s.cfg.SubmitTimeout)
+	defer cancel()
+	return s.rc.SubmitTxRawMeta(submitCtx, utx)
+}
+
+// nonceUnused returns true iff the given nonce of the account is known not to have been used.
+func (s *Sender) nonceUnused(ctx context.Context, address types.Address, nonce uint64) bool {
+	next, err := accounts.NewV1(s.rc).Nonce(ctx, client.RoundLatest, address)
+	return err == nil && next <= nonce
+}
+
+// New creates a new sender of transactions to the runtime of the given client.
+func New(rc client.RuntimeClient, cfg Config) *Sender {
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultWorkers
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultQueueSize
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	if cfg.RetryBackoff == 0 {
+		cfg.RetryBackoff = DefaultRetryBackoff
+	}
+	if cfg.SubmitTimeout == 0 {
+		cfg.SubmitTimeout = DefaultSubmitTimeout
+	}
+	if cfg.Nonces == nil {
+		cfg.Nonces = accounts.NewNonceManager(rc)
+	}
+	return &Sender{
+		rc:  rc,
+		cfg: cfg,
+		sem: make(chan struct{}, cfg.Workers),
+	}
+}
@@ -0,0 +1,209 @@
+package txsender
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/fakeruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+var errTestSubmit = errors.New("test submission error")
+
+// flakyClient is a runtime client failing the submission of the transactions with given nonces
+// once, either before or after submitting them to the runtime.
+type flakyClient struct {
+	client.RuntimeClient
+
+	l           sync.Mutex
+	failBefore  map[uint64]bool
+	failAfter   map[uint64]bool
+	submissions int
+}
+
+func (c *flakyClient) SubmitTxRawMeta(ctx context.Context, utx *types.UnverifiedTransaction) (*client.SubmitTxRawMeta, error) {
+	var tx types.Transaction
+	if err := cbor.Unmarshal(utx.Body, &tx); err != nil {
+		return nil, err
+	}
+	nonce := tx.AuthInfo.SignerInfo[0].Nonce
+
+	c.l.Lock()
+	c.submissions++
+	failBefore, failAfter := c.failBefore[nonce], c.failAfter[nonce]
+	delete(c.failBefore, nonce)
+	delete(c.failAfter, nonce)
+	c.l.Unlock()
+
+	if failBefore {
+		return nil, errTestSubmit
+	}
+	meta, err := c.RuntimeClient.SubmitTxRawMeta(ctx, utx)
+	if failAfter {
+		return nil, errTestSubmit
+	}
+	return meta, err
+}
+
+// blockingClient is a runtime client blocking the submission of transactions until it is
+// released, signaling submissions on the submitting channel if it has room.
+type blockingClient struct {
+	client.RuntimeClient
+
+	submitting chan struct{}
+	release    chan struct{}
+}
+
+func (c *blockingClient) SubmitTxRawMeta(ctx context.Context, utx *types.UnverifiedTransaction) (*client.SubmitTxRawMeta, error) {
+	select {
+	case c.submitting <- struct{}{}:
+	default:
+	}
+	<-c.release
+	return c.RuntimeClient.SubmitTxRawMeta(ctx, utx)
+}
+
+func nativeUnits(amount uint64) types.BaseUnits {
+	return types.NewBaseUnits(*quantity.NewFromUint64(amount), types.NativeDenomination)
+}
+
+func transferRequest(key sdkTesting.TestKey, amount uint64, done func(*Result)) *Request {
+	return &Request{
+		Tx:      types.NewTransaction(nil, "accounts.Transfer", &accounts.Transfer{To: sdkTesting.Heidi.Address, Amount: nativeUnits(amount)}),
+		SigSpec: key.SigSpec,
+		Signer:  key.Signer,
+		Done:    done,
+	}
+}
+
+func TestSender(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rt := fakeruntime.New(fakeruntime.Config{})
+	senders := []sdkTesting.TestKey{sdkTesting.Alice, sdkTesting.Bob, sdkTesting.Charlie}
+	for _, key := range senders {
+		rt.SetBalance(key.Address, nativeUnits(1000))
+	}
+	rc := &flakyClient{RuntimeClient: rt}
+	s := New(rc, Config{Workers: 2, RetryBackoff: time.Millisecond})
+
+	var (
+		l       sync.Mutex
+		results = make(map[types.Address][]*Result)
+	)
+	done := func(res *Result) {
+		l.Lock()
+		defer l.Unlock()
+		address := types.NewAddress(res.Request.SigSpec)
+		results[address] = append(results[address], res)
+	}
+
+	// Transactions of each account are executed in order.
+	reqs := make(chan *Request)
+	go func() {
+		defer close(reqs)
+		for i := uint64(0); i < 5; i++ {
+			for _, key := range senders {
+				reqs <- transferRequest(key, i+1, done)
+			}
+		}
+	}()
+	require.NoError(s.Run(ctx, reqs), "Run")
+	for _, key := range senders {
+		require.Len(results[key.Address], 5)
+		for i, res := range results[key.Address] {
+			require.NoError(res.Error, "transfer should succeed")
+			require.NotNil(res.Meta)
+			require.True(res.Meta.Result.IsSuccess())
+			require.EqualValues(i, res.Nonce)
+			require.Equal(1, res.Attempts)
+		}
+	}
+
+	runOne := func(req *Request) *Result {
+		var res *Result
+		req.Done = func(r *Result) { res = r }
+		reqs := make(chan *Request, 1)
+		reqs <- req
+		close(reqs)
+		require.NoError(s.Run(ctx, reqs), "Run")
+		return res
+	}
+
+	// Failed submissions are broadcast again.
+	rc.failBefore = map[uint64]bool{5: true}
+	res := runOne(transferRequest(sdkTesting.Alice, 1, nil))
+	require.NoError(res.Error, "transfer should succeed after a failed submission")
+	require.EqualValues(5, res.Nonce)
+	require.Equal(2, res.Attempts)
+
+	// Transactions executed despite a failed submission have an unknown outcome.
+	rc.failAfter = map[uint64]bool{6: true}
+	res = runOne(transferRequest(sdkTesting.Alice, 1, nil))
+	require.ErrorIs(res.Error, ErrOutcomeUnknown)
+	require.Nil(res.Meta)
+	require.Equal(2, res.Attempts)
+
+	// The nonce used by the transaction with an unknown outcome is not handed out again.
+	res = runOne(transferRequest(sdkTesting.Alice, 1, nil))
+	require.NoError(res.Error, "transfer should succeed")
+	require.EqualValues(7, res.Nonce)
+
+	// Transactions failing the checks are retried with a fresh nonce, until they run out of
+	// attempts.
+	submissions := rc.submissions
+	req := transferRequest(sdkTesting.Alice, 1, nil)
+	req.Tx.AuthInfo.Fee.Amount = nativeUnits(1_000_000)
+	res = runOne(req)
+	require.Error(res.Error, "transfer exceeding the fee balance should fail")
+	require.NotErrorIs(res.Error, ErrOutcomeUnknown)
+	require.Nil(res.Meta)
+	require.Equal(DefaultMaxAttempts, res.Attempts)
+	require.Equal(submissions+DefaultMaxAttempts, rc.submissions)
+
+	// Executed transactions with failed calls are done.
+	res = runOne(transferRequest(sdkTesting.Alice, 1_000_000, nil))
+	require.NoError(res.Error)
+	require.NotNil(res.Meta)
+	require.False(res.Meta.Result.IsSuccess())
+	require.EqualValues(8, res.Nonce)
+
+	// Requests exceeding the queue of their account are rejected.
+	bc := &blockingClient{
+		RuntimeClient: rt,
+		submitting:    make(chan struct{}, 1),
+		release:       make(chan struct{}),
+	}
+	s = New(bc, Config{QueueSize: 1})
+	reqs = make(chan *Request)
+	rejected := make(chan *Result, 1)
+	runErr := make(chan error)
+	go func() { runErr <- s.Run(ctx, reqs) }()
+	reqs <- transferRequest(sdkTesting.Bob, 1, done)
+	<-bc.submitting
+	reqs <- transferRequest(sdkTesting.Bob, 1, done)
+	reqs <- transferRequest(sdkTesting.Bob, 1, func(res *Result) { rejected <- res })
+	res = <-rejected
+	require.ErrorIs(res.Error, ErrQueueFull)
+	require.Zero(res.Attempts)
+	close(bc.release)
+	close(reqs)
+	require.NoError(<-runErr, "Run")
+
+	// Requests fail once the context is canceled.
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(s.Run(cancelCtx, make(chan *Request)), context.Canceled)
+}
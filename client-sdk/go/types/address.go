@@ -5,6 +5,7 @@ import (
 
 	"golang.org/x/crypto/sha3"
 
+	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/address"
 	"github.com/oasisprotocol/oasis-core/go/common/encoding/bech32"
@@ -163,6 +164,14 @@ func NewAddressForModule(module string, kind []byte) Address {
 	return (Address)(address.NewAddress(AddressV0ModuleContext, data))
 }
 
+// NewAddressForRuntime creates the consensus layer staking address of the given runtime.
+//
+// This is the address holding the runtime's consensus layer balance, e.g. tokens deposited into
+// the runtime via the consensus accounts module.
+func NewAddressForRuntime(id common.Namespace) Address {
+	return (Address)(staking.NewRuntimeAddress(id))
+}
+
 // NewAddressFromBech32 creates a new address from the given bech-32 encoded string.
 //
 // Panics in case of errors -- use UnmarshalText if you want to handle errors.
@@ -7,6 +7,9 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/oasisprotocol/oasis-core/go/common"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/secp256k1"
 )
@@ -61,6 +64,15 @@ func TestAddressModule(t *testing.T) {
 	require.EqualValues("oasis1qq398yyk4wt2zxhtt8c66raynelgt6ngh5yq87xg", addr.String())
 }
 
+func TestAddressRuntime(t *testing.T) {
+	require := require.New(t)
+
+	var runtimeID common.Namespace
+	require.NoError(runtimeID.UnmarshalHex("8000000000000000000000000000000000000000000000000000000000000000"))
+	addr := NewAddressForRuntime(runtimeID)
+	require.EqualValues(staking.NewRuntimeAddress(runtimeID).String(), addr.String())
+}
+
 func TestAddressRaw(t *testing.T) {
 	require := require.New(t)
 
@@ -36,10 +36,16 @@ func (pk *PublicKey) marshal() (*serializedPublicKey, error) {
 	switch inner := pk.PublicKey.(type) {
 	case ed25519.PublicKey:
 		spk.Ed25519 = &inner
+	case *ed25519.PublicKey:
+		spk.Ed25519 = inner
 	case secp256k1.PublicKey:
 		spk.Secp256k1 = &inner
+	case *secp256k1.PublicKey:
+		spk.Secp256k1 = inner
 	case sr25519.PublicKey:
 		spk.Sr25519 = &inner
+	case *sr25519.PublicKey:
+		spk.Sr25519 = inner
 	default:
 		return nil, fmt.Errorf("unsupported public key type")
 	}
@@ -47,7 +53,14 @@ func (pk *PublicKey) marshal() (*serializedPublicKey, error) {
 }
 
 func (pk *PublicKey) unmarshal(spk *serializedPublicKey) error {
-	if spk.Ed25519 != nil && spk.Secp256k1 != nil && spk.Sr25519 != nil {
+	// Exactly one of the key types may be set, like for the enum of the runtime.
+	var n int
+	for _, set := range []bool{spk.Ed25519 != nil, spk.Secp256k1 != nil, spk.Sr25519 != nil} {
+		if set {
+			n++
+		}
+	}
+	if n > 1 {
 		return fmt.Errorf("malformed public key")
 	}
 
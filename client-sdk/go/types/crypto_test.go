@@ -1,13 +1,50 @@
 package types
 
 import (
+	"encoding/json"
 	"math"
 	"testing"
 
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/secp256k1"
 	"github.com/stretchr/testify/require"
 )
 
+func TestPublicKeySerialization(t *testing.T) {
+	require := require.New(t)
+
+	edPk := ed25519.NewPublicKey("CgAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=")
+	pk := PublicKey{PublicKey: edPk}
+
+	// Decoded keys are stored as pointers and encode the same as the original keys.
+	raw := cbor.Marshal(&pk)
+	var decoded PublicKey
+	require.NoError(cbor.Unmarshal(raw, &decoded), "UnmarshalCBOR")
+	inner, ok := decoded.PublicKey.(*ed25519.PublicKey)
+	require.True(ok, "decoded key should be a pointer")
+	require.Equal(edPk, *inner)
+	require.Equal(raw, cbor.Marshal(&decoded), "decoded public keys should encode the same")
+
+	rawJSON, err := json.Marshal(&pk)
+	require.NoError(err, "MarshalJSON")
+	decoded = PublicKey{}
+	require.NoError(json.Unmarshal(rawJSON, &decoded), "UnmarshalJSON")
+	_, ok = decoded.PublicKey.(*ed25519.PublicKey)
+	require.True(ok, "decoded key should be a pointer")
+	reencoded, err := json.Marshal(&decoded)
+	require.NoError(err, "MarshalJSON")
+	require.Equal(rawJSON, reencoded, "decoded public keys should encode the same")
+
+	// Exactly one key type must be set.
+	var secpPk secp256k1.PublicKey
+	require.Error(decoded.unmarshal(&serializedPublicKey{Ed25519: &edPk, Secp256k1: &secpPk}), "two key types")
+	require.Error(cbor.Unmarshal(cbor.Marshal(map[string][]byte{}), &decoded), "no key type")
+
+	_, err = (&PublicKey{}).MarshalCBOR()
+	require.Error(err, "unsupported key type")
+}
+
 func TestMultisigConfigValidateBasic(t *testing.T) {
 	require := require.New(t)
 
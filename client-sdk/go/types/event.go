@@ -4,8 +4,37 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"sync"
 )
 
+// maxInternedModuleNames is the maximum number of interned event module names, which bounds the
+// memory used for interning in case of events with arbitrary module names.
+const maxInternedModuleNames = 1024
+
+var (
+	moduleNamesLock sync.RWMutex
+	moduleNames     = make(map[string]string)
+)
+
+// internModuleName returns the module name given as bytes as a string, reusing the same string
+// for all events of a module instead of allocating one per event.
+func internModuleName(b []byte) string {
+	moduleNamesLock.RLock()
+	name, ok := moduleNames[string(b)]
+	moduleNamesLock.RUnlock()
+	if ok {
+		return name
+	}
+
+	name = string(b)
+	moduleNamesLock.Lock()
+	defer moduleNamesLock.Unlock()
+	if len(moduleNames) < maxInternedModuleNames {
+		moduleNames[name] = name
+	}
+	return name
+}
+
 // Event is an event emitted by a runtime in the form of a runtime transaction tag.
 //
 // Key and value semantics are runtime-dependent.
@@ -21,7 +50,7 @@ func (ev *Event) UnmarshalRaw(key, value []byte) error {
 		return fmt.Errorf("malformed event key")
 	}
 
-	ev.Module = string(key[:len(key)-4])
+	ev.Module = internModuleName(key[:len(key)-4])
 	ev.Code = binary.BigEndian.Uint32(key[len(key)-4:])
 	ev.Value = value
 	return nil
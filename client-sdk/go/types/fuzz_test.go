@@ -0,0 +1,124 @@
+//go:build go1.18
+// +build go1.18
+
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	memorySigner "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/memory"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
+)
+
+func fuzzChainContext() signature.Context {
+	var runtimeID common.Namespace
+	_ = runtimeID.UnmarshalHex("8000000000000000000000000000000000000000000000000000000000000000")
+	return signature.DeriveChainContext(runtimeID, "0000000000000000000000000000000000000000000000000000000000000001")
+}
+
+func fuzzSignedTransaction(f *testing.F) []byte {
+	signer := ed25519.WrapSigner(memorySigner.NewTestSigner("oasis-runtime-sdk/test-keys: fuzz"))
+	tx := NewTransaction(nil, "hello.World", map[string]uint64{"foo": 42})
+	tx.AppendAuthSignature(NewSignatureAddressSpecEd25519(signer.Public().(ed25519.PublicKey)), 1)
+	tx.AppendAuthMultisig(&MultisigConfig{
+		Signers: []MultisigSigner{
+			{PublicKey: PublicKey{PublicKey: signer.Public()}, Weight: 1},
+		},
+		Threshold: 1,
+	}, 2)
+
+	ts := tx.PrepareForSigning()
+	if err := ts.AppendSign(fuzzChainContext(), signer); err != nil {
+		f.Fatalf("failed to sign seed transaction: %s", err)
+	}
+	return cbor.Marshal(ts.UnverifiedTransaction())
+}
+
+func FuzzUnverifiedTransaction(f *testing.F) {
+	f.Add(fuzzSignedTransaction(f))
+	f.Add(cbor.Marshal(&UnverifiedTransaction{Body: []byte{0xa0}}))
+	f.Add(cbor.Marshal(&UnverifiedTransaction{AuthProofs: []AuthProof{{Module: "evm.ethereum.v0"}}}))
+
+	chainCtx := fuzzChainContext()
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var ut UnverifiedTransaction
+		if err := cbor.Unmarshal(data, &ut); err != nil {
+			return
+		}
+		tx, err := ut.Verify(chainCtx)
+		if err != nil {
+			return
+		}
+		if err = tx.ValidateBasic(); err != nil {
+			t.Fatalf("verified transaction fails basic validation: %s", err)
+		}
+	})
+}
+
+func FuzzTransaction(f *testing.F) {
+	f.Add(cbor.Marshal(NewTransaction(nil, "hello.World", nil)))
+	f.Add(cbor.Marshal(&Call{Format: CallFormatEncryptedX25519DeoxysII, Body: cbor.Marshal([]byte("body"))}))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var tx Transaction
+		if err := cbor.Unmarshal(data, &tx); err == nil {
+			_ = tx.ValidateBasic()
+			for _, si := range tx.AuthInfo.SignerInfo {
+				_, _ = si.AddressSpec.Address()
+			}
+		}
+
+		var call Call
+		_ = cbor.Unmarshal(data, &call)
+	})
+}
+
+func FuzzAddress(f *testing.F) {
+	f.Add([]byte("oasis1qrec770vrek0a9a5lcrv0zvt22504k68svq7kzve"))
+	f.Add([]byte("oasis1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq"))
+	f.Add(make([]byte, 21))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var a Address
+		if err := a.UnmarshalText(data); err == nil {
+			text, err := a.MarshalText()
+			if err != nil {
+				t.Fatalf("failed to marshal decoded address: %s", err)
+			}
+			var b Address
+			if err = b.UnmarshalText(text); err != nil || !a.Equal(b) {
+				t.Fatalf("address text round trip failed: %s", err)
+			}
+		}
+
+		if err := a.UnmarshalBinary(data); err == nil {
+			raw, err := a.MarshalBinary()
+			if err != nil {
+				t.Fatalf("failed to marshal decoded address: %s", err)
+			}
+			if !bytes.Equal(raw, data) {
+				t.Fatalf("address binary round trip mismatch")
+			}
+		}
+	})
+}
+
+func FuzzEventUnmarshalRaw(f *testing.F) {
+	f.Add([]byte(NewEventKey("accounts", 1)), []byte{0xa0})
+	f.Add([]byte{0x00}, []byte(nil))
+
+	f.Fuzz(func(t *testing.T, key, value []byte) {
+		var ev Event
+		if err := ev.UnmarshalRaw(key, value); err != nil {
+			return
+		}
+		if !ev.Key().IsEqual(key) {
+			t.Fatalf("event key round trip mismatch")
+		}
+	})
+}
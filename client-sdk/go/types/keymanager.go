@@ -4,6 +4,9 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 )
 
+// SignedPublicKeyContext is the context used by the key manager when signing public keys.
+var SignedPublicKeyContext = signature.NewContext("EkKmPubK")
+
 // SignedPublicKey is the public key signed by the key manager.
 type SignedPublicKey struct {
 	// PublicKey is the requested public key.
@@ -13,3 +16,11 @@ type SignedPublicKey struct {
 	// Signature is the Sign(sk, (key || checksum)) from the key manager.
 	Signature signature.RawSignature `json:"signature"`
 }
+
+// Verify checks whether the public key has been signed by the given key manager signer.
+func (spk *SignedPublicKey) Verify(signer signature.PublicKey) bool {
+	body := make([]byte, 0, len(spk.PublicKey)+len(spk.Checksum))
+	body = append(body, spk.PublicKey[:]...)
+	body = append(body, spk.Checksum...)
+	return signer.Verify(SignedPublicKeyContext, body, spk.Signature[:])
+}
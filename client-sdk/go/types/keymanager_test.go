@@ -0,0 +1,35 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	memorySigner "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/memory"
+)
+
+func TestSignedPublicKeyVerify(t *testing.T) {
+	require := require.New(t)
+
+	signer := memorySigner.NewTestSigner("oasis-runtime-sdk/test-keys: key manager")
+	other := memorySigner.NewTestSigner("oasis-runtime-sdk/test-keys: other")
+
+	spk := SignedPublicKey{
+		PublicKey: [32]byte{1, 2, 3},
+		Checksum:  []byte("checksum"),
+	}
+	body := append(append([]byte{}, spk.PublicKey[:]...), spk.Checksum...)
+	sig, err := signer.ContextSign(SignedPublicKeyContext, body)
+	require.NoError(err, "ContextSign")
+	copy(spk.Signature[:], sig)
+
+	require.True(spk.Verify(signer.Public()), "signature should verify")
+	require.False(spk.Verify(other.Public()), "signature should not verify with a different signer")
+
+	spk.Checksum = []byte("other checksum")
+	require.False(spk.Verify(signer.Public()), "signature should not verify with a different checksum")
+
+	var empty signature.PublicKey
+	require.False(spk.Verify(empty), "signature should not verify with an empty key")
+}
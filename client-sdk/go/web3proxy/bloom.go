@@ -0,0 +1,125 @@
+package web3proxy
+
+import (
+	"context"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/evm"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// maxCachedBlooms is the maximum number of log blooms of rounds kept by the proxy, which take
+// bloomSize bytes each.
+const maxCachedBlooms = 16384
+
+// bloom is an Ethereum log bloom filter of the addresses and topics of logs.
+type bloom [bloomSize]byte
+
+// add adds the given address or topic to the bloom filter.
+func (b *bloom) add(data []byte) {
+	h := keccak256(data)
+	for i := 0; i < 6; i += 2 {
+		bit := (uint(h[i])<<8 | uint(h[i+1])) & (bloomSize*8 - 1)
+		b[bloomSize-1-bit/8] |= 1 << (bit % 8)
+	}
+}
+
+// contains returns false iff the given address or topic has not been added to the bloom filter.
+func (b *bloom) contains(data []byte) bool {
+	var other bloom
+	other.add(data)
+	for i := range b {
+		if b[i]&other[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// mayMatch returns false iff the bloom filter shows that no log matches the filter.
+func (b *bloom) mayMatch(f *logFilter) bool {
+	if len(f.Address) > 0 {
+		var found bool
+		for i := 0; i < len(f.Address) && !found; i++ {
+			found = b.contains(f.Address[i][:])
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, set := range f.Topics {
+		if len(set) == 0 {
+			continue
+		}
+		var found bool
+		for i := 0; i < len(set) && !found; i++ {
+			found = b.contains(set[i])
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// logsBloom returns the bloom filter of the given logs.
+func logsBloom(logs []*rpcLog) hexBytes {
+	var b bloom
+	for _, log := range logs {
+		b.add(log.Address[:])
+		for _, topic := range log.Topics {
+			b.add(topic)
+		}
+	}
+	return b[:]
+}
+
+// roundBloom returns the log bloom filter of the given round, computing it from the events of the
+// round if it is not cached. This is much cheaper than fetching the contents of the block, so
+// eth_getLogs only fetches the blocks that may contain matching logs.
+func (p *Proxy) roundBloom(ctx context.Context, round uint64) (*bloom, error) {
+	p.mu.Lock()
+	b, ok := p.blooms[round]
+	p.mu.Unlock()
+	if ok {
+		return b, nil
+	}
+
+	evs, err := p.rc.GetEventsRaw(ctx, round)
+	if err != nil {
+		return nil, err
+	}
+	b = eventsBloom(evs)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.blooms) >= maxCachedBlooms {
+		// Blooms of finalized rounds never change, so evict an arbitrary one.
+		for r := range p.blooms {
+			delete(p.blooms, r)
+			break
+		}
+	}
+	p.blooms[round] = b
+	return b, nil
+}
+
+// eventsBloom returns the bloom filter of the EVM logs among the given events.
+func eventsBloom(evs []*types.Event) *bloom {
+	var b bloom
+	for _, ev := range evs {
+		if ev.Module != evm.ModuleName || ev.Code != evm.LogEventCode {
+			continue
+		}
+		var logEv evm.LogEvent
+		if err := cbor.Unmarshal(ev.Value, &logEv); err != nil || len(logEv.Address) != len(address{}) {
+			continue
+		}
+		b.add(logEv.Address)
+		for _, topic := range logEv.Topics {
+			b.add(topic)
+		}
+	}
+	return &b
+}
@@ -0,0 +1,138 @@
+package web3proxy
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// hexUint64 is an unsigned integer encoded as a hex quantity, e.g. "0x1f".
+type hexUint64 uint64
+
+// MarshalText implements encoding.TextMarshaler.
+func (q hexUint64) MarshalText() ([]byte, error) {
+	return []byte("0x" + strconv.FormatUint(uint64(q), 16)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (q *hexUint64) UnmarshalText(text []byte) error {
+	s, err := trimHexPrefix(string(text))
+	if err != nil {
+		return err
+	}
+	v, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return fmt.Errorf("malformed quantity: %w", err)
+	}
+	*q = hexUint64(v)
+	return nil
+}
+
+// hexBig is an arbitrary-precision unsigned integer encoded as a hex quantity.
+type hexBig big.Int
+
+func newHexBig(v *big.Int) *hexBig {
+	return (*hexBig)(v)
+}
+
+// Int returns the value as a big integer.
+func (q *hexBig) Int() *big.Int {
+	if q == nil {
+		return new(big.Int)
+	}
+	return (*big.Int)(q)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (q *hexBig) MarshalText() ([]byte, error) {
+	return []byte("0x" + (*big.Int)(q).Text(16)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (q *hexBig) UnmarshalText(text []byte) error {
+	s, err := trimHexPrefix(string(text))
+	if err != nil {
+		return err
+	}
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok || v.Sign() < 0 || v.BitLen() > 256 {
+		return fmt.Errorf("malformed quantity")
+	}
+	*q = hexBig(*v)
+	return nil
+}
+
+// hexBytes is a byte string encoded as hex data, e.g. "0x00ff".
+type hexBytes []byte
+
+// MarshalText implements encoding.TextMarshaler.
+func (b hexBytes) MarshalText() ([]byte, error) {
+	return []byte("0x" + hex.EncodeToString(b)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *hexBytes) UnmarshalText(text []byte) error {
+	s, err := trimHexPrefix(string(text))
+	if err != nil {
+		return err
+	}
+	if len(s)%2 == 1 {
+		s = "0" + s
+	}
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("malformed data: %w", err)
+	}
+	*b = data
+	return nil
+}
+
+// address is an Ethereum address.
+type address [20]byte
+
+// MarshalText implements encoding.TextMarshaler.
+func (a address) MarshalText() ([]byte, error) {
+	return hexBytes(a[:]).MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (a *address) UnmarshalText(text []byte) error {
+	var b hexBytes
+	if err := b.UnmarshalText(text); err != nil {
+		return err
+	}
+	if len(b) != len(a) {
+		return fmt.Errorf("malformed address")
+	}
+	copy(a[:], b)
+	return nil
+}
+
+func trimHexPrefix(s string) (string, error) {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return "", fmt.Errorf("missing 0x prefix")
+	}
+	return s[2:], nil
+}
+
+// uint256 encodes the given value as a 32-byte big-endian integer, as used by the evm module.
+func uint256(v *big.Int) []byte {
+	b := make([]byte, 32)
+	if v != nil {
+		v.FillBytes(b)
+	}
+	return b
+}
+
+// padUint256 left-pads the given big-endian integer to 32 bytes, returning it as is if it is
+// already 32 bytes long, which storage values returned by the evm module are.
+func padUint256(v []byte) []byte {
+	if len(v) >= 32 {
+		return v
+	}
+	b := make([]byte, 32)
+	copy(b[32-len(v):], v)
+	return b
+}
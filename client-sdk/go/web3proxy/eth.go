@@ -0,0 +1,1004 @@
+package web3proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/core"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/evm"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const (
+	// ethereumScheme is the module-controlled decoding scheme of raw Ethereum transactions.
+	ethereumScheme = "evm.ethereum.v0"
+
+	methodEVMCreate       = "evm.Create"
+	methodEVMCall         = "evm.Call"
+	methodEVMStorage      = "evm.Storage"
+	methodEVMCode         = "evm.Code"
+	methodEVMBalance      = "evm.Balance"
+	methodEVMSimulateCall = "evm.SimulateCall"
+
+	// maxLogsRange is the maximum number of rounds eth_getLogs scans.
+	maxLogsRange = 1024
+
+	bloomSize = 256
+)
+
+// emptyUnclesHash is the hash of an empty list of uncles.
+var emptyUnclesHash = keccak256(rlpEncodeList())
+
+// Block parameter tags.
+const (
+	// blockTagLatest is the default, so that omitted block parameters refer to the latest block.
+	blockTagLatest = iota
+	blockTagEarliest
+	blockTagNumber
+)
+
+// blockTag is a block parameter, either a block number or a tag.
+type blockTag struct {
+	tag    int
+	number uint64
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *blockTag) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("malformed block number: %w", err)
+	}
+	switch s {
+	case "latest", "pending", "safe", "finalized":
+		b.tag = blockTagLatest
+	case "earliest":
+		b.tag = blockTagEarliest
+	default:
+		var n hexUint64
+		if err := n.UnmarshalText([]byte(s)); err != nil {
+			return err
+		}
+		b.tag, b.number = blockTagNumber, uint64(n)
+	}
+	return nil
+}
+
+// txArgs are the arguments of eth_sendTransaction, eth_call and eth_estimateGas.
+type txArgs struct {
+	From                 *address   `json:"from"`
+	To                   *address   `json:"to"`
+	Gas                  *hexUint64 `json:"gas"`
+	GasPrice             *hexBig    `json:"gasPrice"`
+	MaxFeePerGas         *hexBig    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexBig    `json:"maxPriorityFeePerGas"`
+	Value                *hexBig    `json:"value"`
+	Nonce                *hexUint64 `json:"nonce"`
+	Data                 *hexBytes  `json:"data"`
+	Input                *hexBytes  `json:"input"`
+}
+
+func (a *txArgs) data() []byte {
+	switch {
+	case a.Input != nil:
+		return *a.Input
+	case a.Data != nil:
+		return *a.Data
+	default:
+		return nil
+	}
+}
+
+// topicSet is a set of alternative topics at one position of a log filter.
+type topicSet []hexBytes
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (ts *topicSet) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, nullID) {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '[' {
+		return json.Unmarshal(data, (*[]hexBytes)(ts))
+	}
+	var topic hexBytes
+	if err := json.Unmarshal(data, &topic); err != nil {
+		return err
+	}
+	*ts = topicSet{topic}
+	return nil
+}
+
+// addressSet is a set of alternative addresses of a log filter.
+type addressSet []address
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (as *addressSet) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '[' {
+		return json.Unmarshal(data, (*[]address)(as))
+	}
+	var addr address
+	if err := json.Unmarshal(data, &addr); err != nil {
+		return err
+	}
+	*as = addressSet{addr}
+	return nil
+}
+
+// logFilter are the arguments of eth_getLogs.
+type logFilter struct {
+	FromBlock *blockTag  `json:"fromBlock"`
+	ToBlock   *blockTag  `json:"toBlock"`
+	BlockHash *hexBytes  `json:"blockHash"`
+	Address   addressSet `json:"address"`
+	Topics    []topicSet `json:"topics"`
+}
+
+func (f *logFilter) matches(log *rpcLog) bool {
+	if len(f.Address) > 0 {
+		var found bool
+		for _, addr := range f.Address {
+			found = found || addr == log.Address
+		}
+		if !found {
+			return false
+		}
+	}
+	for i, set := range f.Topics {
+		if len(set) == 0 {
+			continue
+		}
+		if i >= len(log.Topics) {
+			return false
+		}
+		var found bool
+		for _, topic := range set {
+			found = found || bytes.Equal(topic, log.Topics[i])
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+type rpcBlock struct {
+	Number           hexUint64     `json:"number"`
+	Hash             hexBytes      `json:"hash"`
+	ParentHash       hexBytes      `json:"parentHash"`
+	Nonce            hexBytes      `json:"nonce"`
+	MixHash          hexBytes      `json:"mixHash"`
+	Sha3Uncles       hexBytes      `json:"sha3Uncles"`
+	LogsBloom        hexBytes      `json:"logsBloom"`
+	TransactionsRoot hexBytes      `json:"transactionsRoot"`
+	StateRoot        hexBytes      `json:"stateRoot"`
+	ReceiptsRoot     hexBytes      `json:"receiptsRoot"`
+	Miner            address       `json:"miner"`
+	Difficulty       hexUint64     `json:"difficulty"`
+	TotalDifficulty  hexUint64     `json:"totalDifficulty"`
+	ExtraData        hexBytes      `json:"extraData"`
+	Size             hexUint64     `json:"size"`
+	GasLimit         hexUint64     `json:"gasLimit"`
+	GasUsed          hexUint64     `json:"gasUsed"`
+	Timestamp        hexUint64     `json:"timestamp"`
+	Transactions     []interface{} `json:"transactions"`
+	Uncles           []hexBytes    `json:"uncles"`
+}
+
+type rpcTransaction struct {
+	Hash             hexBytes  `json:"hash"`
+	Nonce            hexUint64 `json:"nonce"`
+	BlockHash        hexBytes  `json:"blockHash"`
+	BlockNumber      hexUint64 `json:"blockNumber"`
+	TransactionIndex hexUint64 `json:"transactionIndex"`
+	From             address   `json:"from"`
+	To               *address  `json:"to"`
+	Value            *hexBig   `json:"value"`
+	GasPrice         *hexBig   `json:"gasPrice"`
+	Gas              hexUint64 `json:"gas"`
+	Input            hexBytes  `json:"input"`
+	Type             hexUint64 `json:"type"`
+	ChainID          *hexBig   `json:"chainId,omitempty"`
+	V                *hexBig   `json:"v"`
+	R                *hexBig   `json:"r"`
+	S                *hexBig   `json:"s"`
+}
+
+type rpcReceipt struct {
+	TransactionHash   hexBytes  `json:"transactionHash"`
+	TransactionIndex  hexUint64 `json:"transactionIndex"`
+	BlockHash         hexBytes  `json:"blockHash"`
+	BlockNumber       hexUint64 `json:"blockNumber"`
+	From              address   `json:"from"`
+	To                *address  `json:"to"`
+	CumulativeGasUsed hexUint64 `json:"cumulativeGasUsed"`
+	GasUsed           hexUint64 `json:"gasUsed"`
+	EffectiveGasPrice *hexBig   `json:"effectiveGasPrice"`
+	ContractAddress   *address  `json:"contractAddress"`
+	Logs              []*rpcLog `json:"logs"`
+	LogsBloom         hexBytes  `json:"logsBloom"`
+	Status            hexUint64 `json:"status"`
+	Type              hexUint64 `json:"type"`
+}
+
+type rpcLog struct {
+	Address          address    `json:"address"`
+	Topics           []hexBytes `json:"topics"`
+	Data             hexBytes   `json:"data"`
+	BlockNumber      hexUint64  `json:"blockNumber"`
+	BlockHash        hexBytes   `json:"blockHash"`
+	TransactionHash  hexBytes   `json:"transactionHash"`
+	TransactionIndex hexUint64  `json:"transactionIndex"`
+	LogIndex         hexUint64  `json:"logIndex"`
+	Removed          bool       `json:"removed"`
+}
+
+// txInfo are the Ethereum fields of a runtime transaction.
+type txInfo struct {
+	hash     [32]byte
+	typ      uint8
+	chainID  *big.Int
+	from     address
+	to       *address
+	create   bool
+	nonce    uint64
+	gas      uint64
+	gasPrice *big.Int
+	value    *big.Int
+	input    []byte
+	v, r, s  *big.Int
+}
+
+// decodeTx extracts the Ethereum fields of a runtime transaction. Raw Ethereum transactions are
+// identified by their Ethereum hash, other transactions by their runtime hash.
+func decodeTx(utx *types.UnverifiedTransaction) *txInfo {
+	if len(utx.AuthProofs) == 1 && utx.AuthProofs[0].Module == ethereumScheme {
+		if etx, err := decodeEthTx(utx.Body); err == nil {
+			return &txInfo{
+				hash:     etx.Hash,
+				typ:      etx.Type,
+				chainID:  etx.ChainID,
+				from:     etx.From,
+				to:       etx.To,
+				create:   etx.To == nil,
+				nonce:    etx.Nonce,
+				gas:      etx.Gas,
+				gasPrice: etx.GasPrice,
+				value:    etx.Value,
+				input:    etx.Data,
+				v:        etx.V,
+				r:        etx.R,
+				s:        etx.S,
+			}
+		}
+	}
+
+	info := &txInfo{
+		hash:     hash.NewFromBytes(cbor.Marshal(utx)),
+		gasPrice: new(big.Int),
+		value:    new(big.Int),
+		v:        new(big.Int),
+		r:        new(big.Int),
+		s:        new(big.Int),
+	}
+	// Transactions that cannot be decoded are still served, just without any details.
+	var tx types.Transaction
+	if err := cbor.Unmarshal(utx.Body, &tx); err != nil {
+		return info
+	}
+	if si := tx.AuthInfo.SignerInfo; len(si) > 0 {
+		info.nonce = si[0].Nonce
+		if spec := si[0].AddressSpec.Signature; spec != nil && spec.Secp256k1Eth != nil {
+			if untaggedPk, err := spec.Secp256k1Eth.MarshalBinaryUncompressedUntagged(); err == nil {
+				info.from = ethAddress(untaggedPk)
+			}
+		}
+	}
+	info.gas = tx.AuthInfo.Fee.Gas
+	if info.gas > 0 {
+		info.gasPrice.Quo(tx.AuthInfo.Fee.Amount.Amount.ToBigInt(), new(big.Int).SetUint64(info.gas))
+	}
+	switch tx.Call.Method {
+	case methodEVMCreate:
+		var body evm.Create
+		if err := cbor.Unmarshal(tx.Call.Body, &body); err == nil {
+			info.create = true
+			info.value.SetBytes(body.Value)
+			info.input = body.InitCode
+		}
+	case methodEVMCall:
+		var body evm.Call
+		if err := cbor.Unmarshal(tx.Call.Body, &body); err == nil && len(body.Address) == len(address{}) {
+			info.to = new(address)
+			copy(info.to[:], body.Address)
+			info.value.SetBytes(body.Value)
+			info.input = body.Data
+		}
+	}
+	return info
+}
+
+// roundData are the contents of a block.
+type roundData struct {
+	blk   *block.Block
+	txs   []*client.TransactionWithResults
+	infos []*txInfo
+}
+
+func (rd *roundData) newRPCTransaction(index int) *rpcTransaction {
+	info := rd.infos[index]
+	rpcTx := &rpcTransaction{
+		Hash:             info.hash[:],
+		Nonce:            hexUint64(info.nonce),
+		BlockHash:        rd.blockHash(),
+		BlockNumber:      hexUint64(rd.blk.Header.Round),
+		TransactionIndex: hexUint64(index),
+		From:             info.from,
+		To:               info.to,
+		Value:            newHexBig(info.value),
+		GasPrice:         newHexBig(info.gasPrice),
+		Gas:              hexUint64(info.gas),
+		Input:            info.input,
+		Type:             hexUint64(info.typ),
+		V:                newHexBig(info.v),
+		R:                newHexBig(info.r),
+		S:                newHexBig(info.s),
+	}
+	if info.chainID != nil {
+		rpcTx.ChainID = newHexBig(info.chainID)
+	}
+	if rpcTx.Input == nil {
+		rpcTx.Input = hexBytes{}
+	}
+	return rpcTx
+}
+
+func (rd *roundData) newRPCReceipt(index int) *rpcReceipt {
+	info := rd.infos[index]
+	txr := rd.txs[index]
+	var cumulativeGas uint64
+	for _, other := range rd.infos[:index+1] {
+		cumulativeGas += other.gas
+	}
+	rcpt := &rpcReceipt{
+		TransactionHash:   info.hash[:],
+		TransactionIndex:  hexUint64(index),
+		BlockHash:         rd.blockHash(),
+		BlockNumber:       hexUint64(rd.blk.Header.Round),
+		From:              info.from,
+		To:                info.to,
+		CumulativeGasUsed: hexUint64(cumulativeGas),
+		GasUsed:           hexUint64(info.gas),
+		EffectiveGasPrice: newHexBig(info.gasPrice),
+		Logs:              rd.logs()[index],
+		Type:              hexUint64(info.typ),
+	}
+	rcpt.LogsBloom = logsBloom(rcpt.Logs)
+	if txr.Result.IsSuccess() {
+		rcpt.Status = 1
+		var created []byte
+		if info.create && cbor.Unmarshal(txr.Result.Ok, &created) == nil && len(created) == len(address{}) {
+			rcpt.ContractAddress = new(address)
+			copy(rcpt.ContractAddress[:], created)
+		}
+	}
+	return rcpt
+}
+
+// logs returns the EVM logs emitted by each transaction of the block.
+func (rd *roundData) logs() [][]*rpcLog {
+	logs := make([][]*rpcLog, len(rd.txs))
+	var logIndex uint64
+	for i, txr := range rd.txs {
+		logs[i] = []*rpcLog{}
+		for _, ev := range txr.Events {
+			if ev.Module != evm.ModuleName || ev.Code != evm.LogEventCode {
+				continue
+			}
+			var logEv evm.LogEvent
+			if err := cbor.Unmarshal(ev.Value, &logEv); err != nil || len(logEv.Address) != len(address{}) {
+				continue
+			}
+			log := &rpcLog{
+				Topics:           []hexBytes{},
+				Data:             logEv.Data,
+				BlockNumber:      hexUint64(rd.blk.Header.Round),
+				BlockHash:        rd.blockHash(),
+				TransactionHash:  rd.infos[i].hash[:],
+				TransactionIndex: hexUint64(i),
+				LogIndex:         hexUint64(logIndex),
+			}
+			copy(log.Address[:], logEv.Address)
+			for _, topic := range logEv.Topics {
+				log.Topics = append(log.Topics, topic)
+			}
+			if log.Data == nil {
+				log.Data = hexBytes{}
+			}
+			logs[i] = append(logs[i], log)
+			logIndex++
+		}
+	}
+	return logs
+}
+
+func (rd *roundData) blockHash() hexBytes {
+	h := rd.blk.Header.EncodedHash()
+	return h[:]
+}
+
+// call invokes the given method.
+func (p *Proxy) call(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "web3_clientVersion":
+		return ClientVersion, nil
+	case "web3_sha3":
+		var data hexBytes
+		if err := decodeParams(params, 1, &data); err != nil {
+			return nil, err
+		}
+		h := keccak256(data)
+		return hexBytes(h[:]), nil
+	case "net_version":
+		return strconv.FormatUint(p.cfg.ChainID, 10), nil
+	case "net_listening":
+		return true, nil
+	case "eth_chainId":
+		return hexUint64(p.cfg.ChainID), nil
+	case "eth_syncing":
+		return false, nil
+	case "eth_accounts":
+		return append([]address{}, p.accountAddrs...), nil
+	case "eth_blockNumber":
+		blk, err := p.rc.GetBlock(ctx, client.RoundLatest)
+		if err != nil {
+			return nil, err
+		}
+		return hexUint64(blk.Header.Round), nil
+	case "eth_gasPrice", "eth_maxPriorityFeePerGas":
+		// The runtime has no base fee, so the whole gas price is the priority fee.
+		price, err := p.gasPrice(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return newHexBig(price), nil
+	case "eth_getBalance", "eth_getCode", "eth_getTransactionCount":
+		var (
+			addr address
+			tag  blockTag
+		)
+		if err := decodeParams(params, 1, &addr, &tag); err != nil {
+			return nil, err
+		}
+		round, err := p.round(ctx, &tag)
+		if err != nil {
+			return nil, err
+		}
+		switch method {
+		case "eth_getBalance":
+			return p.getBalance(ctx, round, addr)
+		case "eth_getCode":
+			var code []byte
+			if err = p.rc.Query(ctx, round, methodEVMCode, &evm.CodeQuery{Address: addr[:]}, &code); err != nil {
+				return nil, err
+			}
+			return hexBytes(code), nil
+		default:
+			nonce, err := accounts.NewV1(p.rc).Nonce(ctx, round, types.NewAddressRaw(types.AddressV0Secp256k1EthContext, addr[:]))
+			if err != nil {
+				return nil, err
+			}
+			return hexUint64(nonce), nil
+		}
+	case "eth_getStorageAt":
+		var (
+			addr address
+			slot hexBig
+			tag  blockTag
+		)
+		if err := decodeParams(params, 2, &addr, &slot, &tag); err != nil {
+			return nil, err
+		}
+		round, err := p.round(ctx, &tag)
+		if err != nil {
+			return nil, err
+		}
+		var value []byte
+		if err = p.rc.Query(ctx, round, methodEVMStorage, &evm.StorageQuery{Address: addr[:], Index: uint256(slot.Int())}, &value); err != nil {
+			return nil, err
+		}
+		return hexBytes(padUint256(value)), nil
+	case "eth_call":
+		var (
+			args txArgs
+			tag  blockTag
+		)
+		if err := decodeParams(params, 1, &args, &tag); err != nil {
+			return nil, err
+		}
+		round, err := p.round(ctx, &tag)
+		if err != nil {
+			return nil, err
+		}
+		return p.simulateCall(ctx, round, &args)
+	case "eth_estimateGas":
+		var args txArgs
+		if err := decodeParams(params, 1, &args, new(blockTag)); err != nil {
+			return nil, err
+		}
+		return hexUint64(p.cfg.GasLimit), nil
+	case "eth_sendTransaction":
+		var args txArgs
+		if err := decodeParams(params, 1, &args); err != nil {
+			return nil, err
+		}
+		return p.sendTransaction(ctx, &args)
+	case "eth_sendRawTransaction":
+		var raw hexBytes
+		if err := decodeParams(params, 1, &raw); err != nil {
+			return nil, err
+		}
+		return p.sendRawTransaction(ctx, raw)
+	case "eth_getTransactionByHash", "eth_getTransactionReceipt":
+		var txHash hexBytes
+		if err := decodeParams(params, 1, &txHash); err != nil {
+			return nil, err
+		}
+		rd, index, err := p.findTransaction(ctx, txHash)
+		if err != nil || rd == nil {
+			return nil, err
+		}
+		if method == "eth_getTransactionByHash" {
+			return rd.newRPCTransaction(index), nil
+		}
+		return rd.newRPCReceipt(index), nil
+	case "eth_getBlockByNumber", "eth_getBlockByHash":
+		var (
+			tag       blockTag
+			blockHash hexBytes
+			full      bool
+		)
+		round := client.RoundLatest
+		if method == "eth_getBlockByNumber" {
+			if err := decodeParams(params, 1, &tag, &full); err != nil {
+				return nil, err
+			}
+			var err error
+			if round, err = p.round(ctx, &tag); err != nil {
+				return nil, err
+			}
+		} else {
+			if err := decodeParams(params, 1, &blockHash, &full); err != nil {
+				return nil, err
+			}
+			var (
+				ok  bool
+				err error
+			)
+			if round, ok, err = p.findBlock(ctx, blockHash); err != nil || !ok {
+				return nil, err
+			}
+		}
+		return p.getBlock(ctx, round, full)
+	case "eth_getLogs":
+		var filter logFilter
+		if err := decodeParams(params, 1, &filter); err != nil {
+			return nil, err
+		}
+		return p.getLogs(ctx, &filter)
+	default:
+		return nil, newError(ErrCodeMethodNotFound, "method %s not found", method)
+	}
+}
+
+// round returns the round of the given block parameter, which is client.RoundLatest for the
+// latest block.
+func (p *Proxy) round(ctx context.Context, tag *blockTag) (uint64, error) {
+	switch tag.tag {
+	case blockTagLatest:
+		return client.RoundLatest, nil
+	case blockTagEarliest:
+		blk, err := p.rc.GetGenesisBlock(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return blk.Header.Round, nil
+	default:
+		return tag.number, nil
+	}
+}
+
+// gasPrice returns the gas price of transactions that do not specify one.
+func (p *Proxy) gasPrice(ctx context.Context) (*big.Int, error) {
+	mgp, err := core.NewV1(p.rc).MinGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if price, ok := mgp[types.NativeDenomination]; ok && !price.IsZero() {
+		return price.ToBigInt(), nil
+	}
+	return new(big.Int).SetUint64(p.cfg.GasPrice), nil
+}
+
+func (p *Proxy) getBalance(ctx context.Context, round uint64, addr address) (*hexBig, error) {
+	var balance types.Quantity
+	if err := p.rc.Query(ctx, round, methodEVMBalance, &evm.BalanceQuery{Address: addr[:]}, &balance); err != nil {
+		return nil, err
+	}
+	return newHexBig(balance.ToBigInt()), nil
+}
+
+func (p *Proxy) simulateCall(ctx context.Context, round uint64, args *txArgs) (hexBytes, error) {
+	if args.To == nil {
+		return nil, newError(ErrCodeInvalidParams, "calls without a recipient are not supported")
+	}
+	gasPrice, err := p.gasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if args.GasPrice != nil {
+		gasPrice = args.GasPrice.Int()
+	}
+	gasLimit := p.cfg.GasLimit
+	if args.Gas != nil {
+		gasLimit = uint64(*args.Gas)
+	}
+	var caller address
+	if args.From != nil {
+		caller = *args.From
+	}
+
+	var output []byte
+	if err = p.rc.Query(ctx, round, methodEVMSimulateCall, &evm.SimulateCallQuery{
+		GasPrice: uint256(gasPrice),
+		GasLimit: gasLimit,
+		Caller:   caller[:],
+		Address:  args.To[:],
+		Value:    uint256(args.Value.Int()),
+		Data:     args.data(),
+	}, &output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// sendTransaction signs the transaction with a development account and submits it.
+func (p *Proxy) sendTransaction(ctx context.Context, args *txArgs) (hexBytes, error) {
+	if args.From == nil {
+		return nil, newError(ErrCodeInvalidParams, "missing sender")
+	}
+	acct, ok := p.accounts[*args.From]
+	if !ok {
+		return nil, newError(ErrCodeInvalidParams, "unknown account %x", args.From[:])
+	}
+
+	var nonce uint64
+	if args.Nonce != nil {
+		nonce = uint64(*args.Nonce)
+	} else {
+		var err error
+		if nonce, err = accounts.NewV1(p.rc).Nonce(ctx, client.RoundLatest, types.NewAddress(acct.spec)); err != nil {
+			return nil, err
+		}
+	}
+	gas := p.cfg.GasLimit
+	if args.Gas != nil {
+		gas = uint64(*args.Gas)
+	}
+	var gasPrice *big.Int
+	switch {
+	case args.GasPrice != nil:
+		gasPrice = args.GasPrice.Int()
+	case args.MaxPriorityFeePerGas != nil:
+		gasPrice = args.MaxPriorityFeePerGas.Int()
+		if args.MaxFeePerGas != nil && args.MaxFeePerGas.Int().Cmp(gasPrice) < 0 {
+			gasPrice = args.MaxFeePerGas.Int()
+		}
+	default:
+		var err error
+		if gasPrice, err = p.gasPrice(ctx); err != nil {
+			return nil, err
+		}
+	}
+	var feeAmount quantity.Quantity
+	if err := feeAmount.FromBigInt(new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gas))); err != nil {
+		return nil, newError(ErrCodeInvalidParams, "malformed fee: %s", err)
+	}
+
+	var tb *client.TransactionBuilder
+	value := uint256(args.Value.Int())
+	if args.To == nil {
+		tb = evm.NewV1(p.rc).Create(value, args.data())
+	} else {
+		tb = evm.NewV1(p.rc).Call(args.To[:], value, args.data())
+	}
+	tx := tb.SetFeeGas(gas).
+		SetFeeAmount(types.NewBaseUnits(feeAmount, types.NativeDenomination)).
+		AppendAuthSignature(acct.spec, nonce).
+		GetTransaction()
+
+	info, err := p.rc.GetInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ts := tx.PrepareForSigning()
+	if err = ts.AppendSign(info.ChainContext, acct.signer); err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	utx := ts.UnverifiedTransaction()
+	return p.submit(ctx, utx, hash.NewFromBytes(cbor.Marshal(utx)))
+}
+
+// sendRawTransaction submits a signed Ethereum transaction.
+func (p *Proxy) sendRawTransaction(ctx context.Context, raw []byte) (hexBytes, error) {
+	etx, err := decodeEthTx(raw)
+	if err != nil {
+		return nil, newError(ErrCodeInvalidParams, "malformed transaction: %s", err)
+	}
+	if etx.ChainID != nil && (!etx.ChainID.IsUint64() || etx.ChainID.Uint64() != p.cfg.ChainID) {
+		return nil, newError(ErrCodeInvalidParams, "transaction chain ID %s does not match %d", etx.ChainID, p.cfg.ChainID)
+	}
+	return p.submit(ctx, &types.UnverifiedTransaction{
+		Body:       raw,
+		AuthProofs: []types.AuthProof{{Module: ethereumScheme}},
+	}, etx.Hash)
+}
+
+// submit submits the transaction, waits for it to be executed and remembers its location.
+func (p *Proxy) submit(ctx context.Context, utx *types.UnverifiedTransaction, txHash [32]byte) (hexBytes, error) {
+	meta, err := p.rc.SubmitTxRawMeta(ctx, utx)
+	if err != nil {
+		return nil, err
+	}
+	if cte := meta.CheckTxError; cte != nil {
+		return nil, newError(ErrCodeServer, "transaction check failed: module: %s code: %d message: %s", cte.Module, cte.Code, cte.Message)
+	}
+	p.remember(txHash, txLocation{round: meta.Round, index: int(meta.BatchOrder)})
+	return txHash[:], nil
+}
+
+// fetchRound fetches the contents of the given block and remembers the locations of its
+// transactions.
+func (p *Proxy) fetchRound(ctx context.Context, round uint64) (*roundData, error) {
+	blk, err := p.rc.GetBlock(ctx, round)
+	if err != nil {
+		return nil, err
+	}
+	txs, err := p.rc.GetTransactionsWithResults(ctx, blk.Header.Round)
+	if err != nil {
+		return nil, err
+	}
+
+	rd := &roundData{blk: blk, txs: txs}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rememberBlockLocked(blk.Header.EncodedHash(), blk.Header.Round)
+	for i, txr := range txs {
+		info := decodeTx(&txr.Tx)
+		rd.infos = append(rd.infos, info)
+		p.rememberLocked(info.hash, txLocation{round: blk.Header.Round, index: i})
+	}
+	return rd, nil
+}
+
+// scanRounds calls the given function for the most recent rounds, starting at the latest round and
+// going back at most maxScanRounds rounds, until it returns true or fails.
+func (p *Proxy) scanRounds(ctx context.Context, fn func(round uint64) (bool, error)) error {
+	genesis, err := p.rc.GetGenesisBlock(ctx)
+	if err != nil {
+		return err
+	}
+	latest, err := p.rc.GetBlock(ctx, client.RoundLatest)
+	if err != nil {
+		return err
+	}
+	round := latest.Header.Round
+	for i := 0; i < maxScanRounds; i++ {
+		done, err := fn(round)
+		if err != nil || done {
+			return err
+		}
+		if round <= genesis.Header.Round {
+			break
+		}
+		round--
+	}
+	return nil
+}
+
+// findTransaction returns the block of the transaction with the given hash and its index, or nil
+// if the transaction is not known.
+func (p *Proxy) findTransaction(ctx context.Context, txHash []byte) (*roundData, int, error) {
+	var key [32]byte
+	if len(txHash) != len(key) {
+		return nil, 0, newError(ErrCodeInvalidParams, "malformed transaction hash")
+	}
+	copy(key[:], txHash)
+	loc, ok := p.lookup(key)
+	if !ok {
+		// The transaction is not cached, look for it in the most recent rounds.
+		var (
+			found *roundData
+			index int
+		)
+		err := p.scanRounds(ctx, func(round uint64) (bool, error) {
+			rd, err := p.fetchRound(ctx, round)
+			if err != nil {
+				return false, err
+			}
+			for i, info := range rd.infos {
+				if info.hash == key {
+					found, index = rd, i
+					return true, nil
+				}
+			}
+			return false, nil
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		return found, index, nil
+	}
+	rd, err := p.fetchRound(ctx, loc.round)
+	if err != nil {
+		return nil, 0, err
+	}
+	if loc.index >= len(rd.infos) || rd.infos[loc.index].hash != key {
+		return nil, 0, nil
+	}
+	return rd, loc.index, nil
+}
+
+// findBlock returns the round of the block with the given hash, looking for it in the most recent
+// rounds if it is not cached.
+func (p *Proxy) findBlock(ctx context.Context, blockHash []byte) (uint64, bool, error) {
+	var h hash.Hash
+	if err := h.UnmarshalBinary(blockHash); err != nil {
+		return 0, false, nil
+	}
+	p.mu.Lock()
+	round, ok := p.blocks[h]
+	p.mu.Unlock()
+	if ok {
+		return round, true, nil
+	}
+
+	err := p.scanRounds(ctx, func(r uint64) (bool, error) {
+		blk, err := p.rc.GetBlock(ctx, r)
+		if err != nil {
+			return false, err
+		}
+		if blk.Header.EncodedHash() != h {
+			return false, nil
+		}
+		p.mu.Lock()
+		p.rememberBlockLocked(h, r)
+		p.mu.Unlock()
+		round, ok = r, true
+		return true, nil
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	return round, ok, nil
+}
+
+func (p *Proxy) getBlock(ctx context.Context, round uint64, full bool) (*rpcBlock, error) {
+	rd, err := p.fetchRound(ctx, round)
+	if err != nil {
+		return nil, err
+	}
+	params, err := core.NewV1(p.rc).Parameters(ctx, rd.blk.Header.Round)
+	if err != nil {
+		return nil, err
+	}
+
+	hdr := &rd.blk.Header
+	rpcBlk := &rpcBlock{
+		Number:           hexUint64(hdr.Round),
+		Hash:             rd.blockHash(),
+		ParentHash:       hdr.PreviousHash[:],
+		Nonce:            make(hexBytes, 8),
+		MixHash:          make(hexBytes, 32),
+		Sha3Uncles:       emptyUnclesHash[:],
+		TransactionsRoot: hdr.IORoot[:],
+		StateRoot:        hdr.StateRoot[:],
+		ReceiptsRoot:     hdr.IORoot[:],
+		ExtraData:        hexBytes{},
+		Timestamp:        hexUint64(hdr.Timestamp),
+		Transactions:     []interface{}{},
+		Uncles:           []hexBytes{},
+	}
+	var logs []*rpcLog
+	for _, txLogs := range rd.logs() {
+		logs = append(logs, txLogs...)
+	}
+	rpcBlk.LogsBloom = logsBloom(logs)
+	for i, info := range rd.infos {
+		rpcBlk.GasUsed += hexUint64(info.gas)
+		if full {
+			rpcBlk.Transactions = append(rpcBlk.Transactions, rd.newRPCTransaction(i))
+		} else {
+			rpcBlk.Transactions = append(rpcBlk.Transactions, hexBytes(info.hash[:]))
+		}
+	}
+	rpcBlk.GasLimit = hexUint64(params.MaxBatchGas)
+	if rpcBlk.GasLimit < rpcBlk.GasUsed {
+		rpcBlk.GasLimit = rpcBlk.GasUsed
+	}
+	return rpcBlk, nil
+}
+
+func (p *Proxy) getLogs(ctx context.Context, filter *logFilter) ([]*rpcLog, error) {
+	var from, to uint64
+	if filter.BlockHash != nil {
+		round, ok, err := p.findBlock(ctx, *filter.BlockHash)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, newError(ErrCodeInvalidParams, "unknown block")
+		}
+		from, to = round, round
+	} else {
+		latest, err := p.rc.GetBlock(ctx, client.RoundLatest)
+		if err != nil {
+			return nil, err
+		}
+		resolve := func(tag *blockTag) (uint64, error) {
+			if tag == nil {
+				return latest.Header.Round, nil
+			}
+			round, err := p.round(ctx, tag)
+			if round == client.RoundLatest {
+				round = latest.Header.Round
+			}
+			return round, err
+		}
+		if from, err = resolve(filter.FromBlock); err != nil {
+			return nil, err
+		}
+		if to, err = resolve(filter.ToBlock); err != nil {
+			return nil, err
+		}
+	}
+	if to >= from && to-from >= maxLogsRange {
+		return nil, newError(ErrCodeInvalidParams, "block range exceeds %d blocks", maxLogsRange)
+	}
+
+	logs := []*rpcLog{}
+	for round := from; round <= to; round++ {
+		if filter.BlockHash == nil {
+			// Skip rounds whose log bloom shows that they have no matching logs.
+			b, err := p.roundBloom(ctx, round)
+			if err != nil {
+				return nil, err
+			}
+			if !b.mayMatch(filter) {
+				continue
+			}
+		}
+		rd, err := p.fetchRound(ctx, round)
+		if err != nil {
+			return nil, err
+		}
+		for _, txLogs := range rd.logs() {
+			for _, log := range txLogs {
+				if filter.matches(log) {
+					logs = append(logs, log)
+				}
+			}
+		}
+	}
+	return logs, nil
+}
@@ -0,0 +1,364 @@
+// Package web3proxy implements a developer-mode proxy serving the Ethereum JSON-RPC API on top of
+// the EVM module of a runtime, so that Ethereum tooling like Hardhat and Foundry can deploy and
+// test contracts against a local runtime.
+//
+// Requests are translated as follows:
+//
+//   - Blocks are runtime blocks, numbered by round and identified by the runtime block hash.
+//   - Queries of balances, code, storage and calls are served by the EVM module queries, at the
+//     requested round.
+//   - eth_sendTransaction signs an evm.Create or evm.Call transaction with one of the configured
+//     development accounts and returns the runtime transaction hash.
+//   - eth_sendRawTransaction submits the signed Ethereum transaction as is, which the runtime
+//     decodes itself, and returns the Ethereum transaction hash.
+//   - Transaction, receipt and block hash lookups are served from a bounded in-memory index of the
+//     transactions and blocks submitted through or seen by the proxy. Hashes that are not indexed
+//     are looked up in the most recent rounds.
+//
+// The proxy is meant for development against local networks. It does not implement filters or
+// subscriptions and gas estimation returns the configured gas limit.
+package web3proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/secp256k1"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const (
+	// DefaultGasLimit is the default gas limit of transactions and simulated calls.
+	DefaultGasLimit = 3_000_000
+
+	// ClientVersion is the client version reported by web3_clientVersion.
+	ClientVersion = "oasis-sdk/web3proxy"
+)
+
+// JSON-RPC error codes.
+const (
+	ErrCodeParse          = -32700
+	ErrCodeInvalidRequest = -32600
+	ErrCodeMethodNotFound = -32601
+	ErrCodeInvalidParams  = -32602
+	ErrCodeInternal       = -32603
+	// ErrCodeServer is the code of errors returned by the node, e.g. failed calls.
+	ErrCodeServer = -32000
+)
+
+const (
+	jsonrpcVersion = "2.0"
+
+	// maxRequestSize is the maximum size of a request body.
+	maxRequestSize = 4 << 20
+
+	// maxCachedTxs is the maximum number of transaction locations kept by the proxy.
+	maxCachedTxs = 65536
+	// maxCachedBlocks is the maximum number of block hashes kept by the proxy.
+	maxCachedBlocks = 16384
+	// maxScanRounds is the maximum number of rounds scanned, starting at the latest one, when
+	// looking up a transaction or block hash that is not cached.
+	maxScanRounds = 1000
+)
+
+var nullID = json.RawMessage("null")
+
+// Error is a JSON-RPC error.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("web3proxy: %s (code %d)", e.Message, e.Code)
+}
+
+func newError(code int, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+type jsonrpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type jsonrpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Config is the configuration of the proxy.
+type Config struct {
+	// ChainID is the EIP-155 chain ID reported to clients. Raw transactions bound to another chain
+	// are rejected.
+	ChainID uint64
+	// Accounts are the secp256k1 signers of the development accounts, which are reported by
+	// eth_accounts and used to sign transactions sent with eth_sendTransaction.
+	Accounts []signature.Signer
+	// GasLimit is the gas limit of transactions and simulated calls that do not specify one, which
+	// is also returned by gas estimation. If zero, DefaultGasLimit is used.
+	GasLimit uint64
+	// GasPrice is the gas price in base units of the native denomination used in case the runtime
+	// does not require a minimum gas price.
+	GasPrice uint64
+}
+
+// account is a development account.
+type account struct {
+	signer signature.Signer
+	spec   types.SignatureAddressSpec
+}
+
+// txLocation is the location of a transaction in a block.
+type txLocation struct {
+	round uint64
+	index int
+}
+
+// Proxy is an Ethereum JSON-RPC server backed by a runtime. It implements http.Handler.
+type Proxy struct {
+	rc  client.RuntimeClient
+	cfg Config
+
+	accounts     map[address]*account
+	accountAddrs []address
+
+	mu     sync.Mutex
+	txs    map[[32]byte]txLocation
+	blocks map[hash.Hash]uint64
+	blooms map[uint64]*bloom
+}
+
+// ServeHTTP implements http.Handler.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var rsp interface{}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		rsp = &jsonrpcResponse{
+			JSONRPC: jsonrpcVersion,
+			ID:      nullID,
+			Error:   newError(ErrCodeInvalidRequest, "failed to read request: %s", err),
+		}
+	} else {
+		rsp = p.handleMessage(r.Context(), data)
+	}
+	if rsp == nil {
+		// Only notifications, which are not answered.
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(rsp)
+}
+
+// handleMessage handles a request or a batch of requests, returning the response to be sent or
+// nil if there is none.
+func (p *Proxy) handleMessage(ctx context.Context, data []byte) interface{} {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '[' {
+		var req jsonrpcRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			return &jsonrpcResponse{
+				JSONRPC: jsonrpcVersion,
+				ID:      nullID,
+				Error:   newError(ErrCodeParse, "malformed request: %s", err),
+			}
+		}
+		if rsp := p.handleRequest(ctx, &req); rsp != nil {
+			return rsp
+		}
+		return nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return &jsonrpcResponse{
+			JSONRPC: jsonrpcVersion,
+			ID:      nullID,
+			Error:   newError(ErrCodeParse, "malformed batch: %s", err),
+		}
+	}
+	if len(batch) == 0 {
+		return &jsonrpcResponse{
+			JSONRPC: jsonrpcVersion,
+			ID:      nullID,
+			Error:   newError(ErrCodeInvalidRequest, "empty batch"),
+		}
+	}
+	var rsps []*jsonrpcResponse
+	for _, raw := range batch {
+		var (
+			req jsonrpcRequest
+			rsp *jsonrpcResponse
+		)
+		if err := json.Unmarshal(raw, &req); err != nil {
+			rsp = &jsonrpcResponse{
+				JSONRPC: jsonrpcVersion,
+				ID:      nullID,
+				Error:   newError(ErrCodeInvalidRequest, "malformed request: %s", err),
+			}
+		} else {
+			rsp = p.handleRequest(ctx, &req)
+		}
+		if rsp != nil {
+			rsps = append(rsps, rsp)
+		}
+	}
+	if len(rsps) == 0 {
+		return nil
+	}
+	return rsps
+}
+
+// handleRequest handles a single request, returning nil for notifications.
+func (p *Proxy) handleRequest(ctx context.Context, req *jsonrpcRequest) *jsonrpcResponse {
+	rsp := &jsonrpcResponse{JSONRPC: jsonrpcVersion, ID: req.ID}
+	if req.JSONRPC != jsonrpcVersion || req.Method == "" {
+		if rsp.ID == nil {
+			rsp.ID = nullID
+		}
+		rsp.Error = newError(ErrCodeInvalidRequest, "invalid request")
+		return rsp
+	}
+
+	result, err := p.call(ctx, req.Method, req.Params)
+	if req.ID == nil {
+		return nil
+	}
+	if err == nil {
+		if rsp.Result, err = json.Marshal(result); err != nil {
+			err = newError(ErrCodeInternal, "failed to encode result: %s", err)
+		}
+	}
+	var rpcErr *Error
+	switch {
+	case err == nil:
+	case errors.As(err, &rpcErr):
+		rsp.Error = rpcErr
+	default:
+		rsp.Error = newError(ErrCodeServer, "%s", err)
+	}
+	if rsp.Error != nil {
+		rsp.Result = nil
+	}
+	return rsp
+}
+
+// decodeParams decodes positional parameters into the given values, of which the first required
+// ones must be present. Omitted and null parameters leave the values unchanged.
+func decodeParams(raw json.RawMessage, required int, values ...interface{}) error {
+	var params []json.RawMessage
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return newError(ErrCodeInvalidParams, "malformed parameters: %s", err)
+		}
+	}
+	if len(params) < required || len(params) > len(values) {
+		return newError(ErrCodeInvalidParams, "expected %d to %d parameters, got %d", required, len(values), len(params))
+	}
+	for i, param := range params {
+		if bytes.Equal(param, nullID) {
+			if i < required {
+				return newError(ErrCodeInvalidParams, "parameter %d is required", i)
+			}
+			continue
+		}
+		if err := json.Unmarshal(param, values[i]); err != nil {
+			return newError(ErrCodeInvalidParams, "malformed parameter %d: %s", i, err)
+		}
+	}
+	return nil
+}
+
+// remember records the location of a transaction with the given hash.
+func (p *Proxy) remember(txHash [32]byte, loc txLocation) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rememberLocked(txHash, loc)
+}
+
+// rememberLocked records the location of a transaction with the given hash, evicting an arbitrary
+// one if the cache is full. The caller must hold p.mu.
+func (p *Proxy) rememberLocked(txHash [32]byte, loc txLocation) {
+	if _, ok := p.txs[txHash]; !ok && len(p.txs) >= maxCachedTxs {
+		for h := range p.txs {
+			delete(p.txs, h)
+			break
+		}
+	}
+	p.txs[txHash] = loc
+}
+
+// rememberBlockLocked records the round of the block with the given hash, evicting an arbitrary
+// one if the cache is full. The caller must hold p.mu.
+func (p *Proxy) rememberBlockLocked(blockHash hash.Hash, round uint64) {
+	if _, ok := p.blocks[blockHash]; !ok && len(p.blocks) >= maxCachedBlocks {
+		for h := range p.blocks {
+			delete(p.blocks, h)
+			break
+		}
+	}
+	p.blocks[blockHash] = round
+}
+
+// lookup returns the location of a transaction with the given hash.
+func (p *Proxy) lookup(txHash [32]byte) (txLocation, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	loc, ok := p.txs[txHash]
+	return loc, ok
+}
+
+// New creates a new proxy serving the runtime of the given client.
+func New(rc client.RuntimeClient, cfg Config) (*Proxy, error) {
+	if cfg.GasLimit == 0 {
+		cfg.GasLimit = DefaultGasLimit
+	}
+	p := &Proxy{
+		rc:       rc,
+		cfg:      cfg,
+		accounts: make(map[address]*account),
+		txs:      make(map[[32]byte]txLocation),
+		blocks:   make(map[hash.Hash]uint64),
+		blooms:   make(map[uint64]*bloom),
+	}
+	for _, signer := range cfg.Accounts {
+		pk, ok := signer.Public().(secp256k1.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("web3proxy: account %s is not a secp256k1 account", signer)
+		}
+		untaggedPk, err := pk.MarshalBinaryUncompressedUntagged()
+		if err != nil {
+			return nil, fmt.Errorf("web3proxy: malformed account public key: %w", err)
+		}
+		addr := ethAddress(untaggedPk)
+		if _, ok = p.accounts[addr]; !ok {
+			p.accountAddrs = append(p.accountAddrs, addr)
+		}
+		p.accounts[addr] = &account{
+			signer: signer,
+			spec:   types.NewSignatureAddressSpecSecp256k1Eth(pk),
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,134 @@
+package web3proxy
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// rlpItem is a decoded RLP item.
+type rlpItem struct {
+	// raw is the full encoding of the item.
+	raw []byte
+	// content is the content of a string item, or the encoded items of a list item.
+	content []byte
+	// list is true for list items.
+	list bool
+}
+
+// uint64 returns the value of a string item as an unsigned integer.
+func (it *rlpItem) uint64() (uint64, error) {
+	if it.list || len(it.content) > 8 {
+		return 0, fmt.Errorf("rlp: not an unsigned integer")
+	}
+	var v uint64
+	for _, b := range it.content {
+		v = v<<8 | uint64(b)
+	}
+	return v, nil
+}
+
+// bigInt returns the value of a string item as an arbitrary-precision unsigned integer.
+func (it *rlpItem) bigInt() (*big.Int, error) {
+	if it.list || len(it.content) > 32 {
+		return nil, fmt.Errorf("rlp: not an unsigned integer")
+	}
+	return new(big.Int).SetBytes(it.content), nil
+}
+
+// rlpSplit decodes the first item of the given data and returns it with the remaining data.
+func rlpSplit(data []byte) (*rlpItem, []byte, error) {
+	if len(data) == 0 {
+		return nil, nil, fmt.Errorf("rlp: unexpected end of data")
+	}
+	var (
+		prefix    = data[0]
+		headerLen int
+		size      uint64
+		list      bool
+	)
+	switch {
+	case prefix < 0x80:
+		return &rlpItem{raw: data[:1], content: data[:1]}, data[1:], nil
+	case prefix < 0xb8:
+		headerLen, size = 1, uint64(prefix-0x80)
+	case prefix < 0xc0:
+		headerLen = 1 + int(prefix-0xb7)
+	case prefix < 0xf8:
+		headerLen, size, list = 1, uint64(prefix-0xc0), true
+	default:
+		headerLen, list = 1+int(prefix-0xf7), true
+	}
+	if headerLen > 1 {
+		if len(data) < headerLen || headerLen > 9 {
+			return nil, nil, fmt.Errorf("rlp: malformed length")
+		}
+		for _, b := range data[1:headerLen] {
+			size = size<<8 | uint64(b)
+		}
+	}
+	if size > uint64(len(data)-headerLen) {
+		return nil, nil, fmt.Errorf("rlp: unexpected end of data")
+	}
+	end := headerLen + int(size)
+	return &rlpItem{raw: data[:end], content: data[headerLen:end], list: list}, data[end:], nil
+}
+
+// rlpDecodeList decodes the given data, which must consist of a single list, into its items.
+func rlpDecodeList(data []byte) ([]*rlpItem, error) {
+	list, rest, err := rlpSplit(data)
+	switch {
+	case err != nil:
+		return nil, err
+	case !list.list:
+		return nil, fmt.Errorf("rlp: not a list")
+	case len(rest) > 0:
+		return nil, fmt.Errorf("rlp: trailing data")
+	}
+
+	var items []*rlpItem
+	for content := list.content; len(content) > 0; {
+		var item *rlpItem
+		if item, content, err = rlpSplit(content); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// rlpEncodeList encodes a list of the given encoded items.
+func rlpEncodeList(items ...[]byte) []byte {
+	var content []byte
+	for _, item := range items {
+		content = append(content, item...)
+	}
+	return append(rlpHeader(0xc0, len(content)), content...)
+}
+
+// rlpEncodeUint64 encodes the given unsigned integer.
+func rlpEncodeUint64(v uint64) []byte {
+	var b []byte
+	for ; v > 0; v >>= 8 {
+		b = append([]byte{byte(v)}, b...)
+	}
+	return rlpEncodeBytes(b)
+}
+
+// rlpEncodeBytes encodes the given byte string.
+func rlpEncodeBytes(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return b
+	}
+	return append(rlpHeader(0x80, len(b)), b...)
+}
+
+func rlpHeader(offset byte, size int) []byte {
+	if size < 56 {
+		return []byte{offset + byte(size)}
+	}
+	var sizeBytes []byte
+	for s := size; s > 0; s >>= 8 {
+		sizeBytes = append([]byte{byte(s)}, sizeBytes...)
+	}
+	return append([]byte{offset + 55 + byte(len(sizeBytes))}, sizeBytes...)
+}
@@ -0,0 +1,185 @@
+package web3proxy
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+	"golang.org/x/crypto/sha3"
+)
+
+// Ethereum transaction types.
+const (
+	ethTxTypeLegacy     = 0
+	ethTxTypeAccessList = 1
+	ethTxTypeDynamicFee = 2
+)
+
+// ethTx is a decoded signed Ethereum transaction.
+type ethTx struct {
+	Type uint8
+	// ChainID is the chain ID the transaction is bound to, nil for legacy transactions without
+	// replay protection.
+	ChainID  *big.Int
+	Nonce    uint64
+	GasPrice *big.Int
+	Gas      uint64
+	// To is the called address, nil for contract creations.
+	To      *address
+	Value   *big.Int
+	Data    []byte
+	V, R, S *big.Int
+
+	// From is the sender, recovered from the signature.
+	From address
+	// Hash is the Ethereum transaction hash.
+	Hash [32]byte
+}
+
+// decodeEthTx decodes a signed legacy, EIP-2930 or EIP-1559 transaction and recovers its sender.
+func decodeEthTx(raw []byte) (*ethTx, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("empty transaction")
+	}
+	tx := &ethTx{Hash: keccak256(raw)}
+
+	var (
+		items []*rlpItem
+		err   error
+	)
+	if raw[0] >= 0xc0 {
+		items, err = rlpDecodeList(raw)
+	} else {
+		tx.Type = raw[0]
+		items, err = rlpDecodeList(raw[1:])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Fields of the transaction up to and including the data, followed by the signature.
+	var (
+		fields []*rlpItem
+		maxFee *big.Int
+	)
+	switch tx.Type {
+	case ethTxTypeLegacy:
+		if len(items) != 9 {
+			return nil, fmt.Errorf("malformed legacy transaction")
+		}
+		fields = items[:6]
+	case ethTxTypeAccessList:
+		if len(items) != 11 {
+			return nil, fmt.Errorf("malformed access list transaction")
+		}
+		fields = items[1:7]
+	case ethTxTypeDynamicFee:
+		if len(items) != 12 {
+			return nil, fmt.Errorf("malformed dynamic fee transaction")
+		}
+		if maxFee, err = items[3].bigInt(); err != nil {
+			return nil, err
+		}
+		// The priority fee is used as the gas price, as the runtime has no base fee.
+		fields = append([]*rlpItem{items[1], items[2]}, items[4:8]...)
+	default:
+		return nil, fmt.Errorf("unsupported transaction type %d", tx.Type)
+	}
+	sig := items[len(items)-3:]
+
+	if tx.Nonce, err = fields[0].uint64(); err != nil {
+		return nil, err
+	}
+	if tx.GasPrice, err = fields[1].bigInt(); err != nil {
+		return nil, err
+	}
+	if maxFee != nil && maxFee.Cmp(tx.GasPrice) < 0 {
+		tx.GasPrice = maxFee
+	}
+	if tx.Gas, err = fields[2].uint64(); err != nil {
+		return nil, err
+	}
+	switch to := fields[3]; {
+	case to.list:
+		return nil, fmt.Errorf("malformed recipient")
+	case len(to.content) == len(address{}):
+		tx.To = new(address)
+		copy(tx.To[:], to.content)
+	case len(to.content) != 0:
+		return nil, fmt.Errorf("malformed recipient")
+	}
+	if tx.Value, err = fields[4].bigInt(); err != nil {
+		return nil, err
+	}
+	if fields[5].list {
+		return nil, fmt.Errorf("malformed data")
+	}
+	tx.Data = fields[5].content
+	if tx.V, err = sig[0].bigInt(); err != nil {
+		return nil, err
+	}
+	if tx.R, err = sig[1].bigInt(); err != nil {
+		return nil, err
+	}
+	if tx.S, err = sig[2].bigInt(); err != nil {
+		return nil, err
+	}
+
+	// Compute the signed payload and the recovery identifier.
+	var (
+		payload  [][]byte
+		recovery *big.Int
+	)
+	for _, item := range items[:len(items)-3] {
+		payload = append(payload, item.raw)
+	}
+	switch tx.Type {
+	case ethTxTypeLegacy:
+		recovery = new(big.Int).Sub(tx.V, big.NewInt(27))
+		if tx.V.Cmp(big.NewInt(35)) >= 0 {
+			// EIP-155: v = chainID * 2 + 35 + recovery.
+			tx.ChainID = new(big.Int).Rsh(new(big.Int).Sub(tx.V, big.NewInt(35)), 1)
+			recovery = new(big.Int).Sub(tx.V, new(big.Int).Add(new(big.Int).Lsh(tx.ChainID, 1), big.NewInt(35)))
+			payload = append(payload, rlpEncodeBytes(tx.ChainID.Bytes()), rlpEncodeBytes(nil), rlpEncodeBytes(nil))
+		}
+	default:
+		if tx.ChainID, err = items[0].bigInt(); err != nil {
+			return nil, err
+		}
+		recovery = tx.V
+	}
+	if !recovery.IsUint64() || recovery.Uint64() > 1 || tx.R.BitLen() > 256 || tx.S.BitLen() > 256 {
+		return nil, fmt.Errorf("malformed signature")
+	}
+	signed := rlpEncodeList(payload...)
+	if tx.Type != ethTxTypeLegacy {
+		signed = append([]byte{tx.Type}, signed...)
+	}
+	msgHash := keccak256(signed)
+
+	compact := make([]byte, 65)
+	compact[0] = 27 + byte(recovery.Uint64())
+	tx.R.FillBytes(compact[1:33])
+	tx.S.FillBytes(compact[33:])
+	pk, _, err := btcec.RecoverCompact(btcec.S256(), compact, msgHash[:])
+	if err != nil {
+		return nil, fmt.Errorf("malformed signature: %w", err)
+	}
+	tx.From = ethAddress(pk.SerializeUncompressed()[1:])
+
+	return tx, nil
+}
+
+// ethAddress derives the Ethereum address of an uncompressed untagged secp256k1 public key.
+func ethAddress(untaggedPk []byte) (a address) {
+	h := keccak256(untaggedPk)
+	copy(a[:], h[32-20:])
+	return
+}
+
+func keccak256(data []byte) (h [32]byte) {
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(data)
+	copy(h[:], hasher.Sum(nil))
+	return
+}
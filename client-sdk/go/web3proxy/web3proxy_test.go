@@ -0,0 +1,439 @@
+package web3proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/core"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/evm"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/fakeruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const testChainID = 0x5afe
+
+// signEthTx signs an Ethereum transaction of the given type with the given fields, which are
+// followed by the signature.
+func signEthTx(t *testing.T, key *btcec.PrivateKey, typ uint8, chainID uint64, fields ...[]byte) []byte {
+	payload := append([][]byte{}, fields...)
+	if typ == ethTxTypeLegacy {
+		payload = append(payload, rlpEncodeUint64(chainID), rlpEncodeBytes(nil), rlpEncodeBytes(nil))
+	}
+	signed := rlpEncodeList(payload...)
+	if typ != ethTxTypeLegacy {
+		signed = append([]byte{typ}, signed...)
+	}
+	msgHash := keccak256(signed)
+	sig, err := btcec.SignCompact(btcec.S256(), key, msgHash[:], false)
+	require.NoError(t, err, "SignCompact")
+
+	v := uint64(sig[0] - 27)
+	if typ == ethTxTypeLegacy {
+		v += chainID*2 + 35
+	}
+	raw := rlpEncodeList(append(fields, rlpEncodeUint64(v), rlpEncodeBytes(sig[1:33]), rlpEncodeBytes(sig[33:]))...)
+	if typ != ethTxTypeLegacy {
+		raw = append([]byte{typ}, raw...)
+	}
+	return raw
+}
+
+func TestDecodeEthTx(t *testing.T) {
+	require := require.New(t)
+
+	key, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(err, "NewPrivateKey")
+	from := ethAddress(key.PubKey().SerializeUncompressed()[1:])
+	to := bytes.Repeat([]byte{0x42}, 20)
+	data := bytes.Repeat([]byte{0xaa}, 100)
+
+	legacy := signEthTx(t, key, ethTxTypeLegacy, testChainID,
+		rlpEncodeUint64(7), rlpEncodeUint64(100), rlpEncodeUint64(21000), rlpEncodeBytes(to), rlpEncodeUint64(1000), rlpEncodeBytes(data),
+	)
+	tx, err := decodeEthTx(legacy)
+	require.NoError(err, "decodeEthTx legacy")
+	require.EqualValues(ethTxTypeLegacy, tx.Type)
+	require.EqualValues(testChainID, tx.ChainID.Uint64())
+	require.Equal(from, tx.From)
+	require.EqualValues(7, tx.Nonce)
+	require.EqualValues(100, tx.GasPrice.Uint64())
+	require.EqualValues(21000, tx.Gas)
+	require.Equal(to, tx.To[:])
+	require.EqualValues(1000, tx.Value.Uint64())
+	require.Equal(data, tx.Data)
+	require.Equal(keccak256(legacy), tx.Hash)
+
+	dynamic := signEthTx(t, key, ethTxTypeDynamicFee, testChainID,
+		rlpEncodeUint64(testChainID), rlpEncodeUint64(0), rlpEncodeUint64(5), rlpEncodeUint64(3), rlpEncodeUint64(50000), rlpEncodeBytes(nil), rlpEncodeBytes(nil), rlpEncodeBytes(data), rlpEncodeList(),
+	)
+	tx, err = decodeEthTx(dynamic)
+	require.NoError(err, "decodeEthTx dynamic fee")
+	require.EqualValues(ethTxTypeDynamicFee, tx.Type)
+	require.Equal(from, tx.From)
+	require.EqualValues(3, tx.GasPrice.Uint64(), "gas price should be capped by the max fee")
+	require.Nil(tx.To, "contract creation should have no recipient")
+
+	_, err = decodeEthTx(legacy[:len(legacy)-1])
+	require.Error(err, "truncated transaction")
+	_, err = decodeEthTx(append([]byte{0x03}, dynamic[1:]...))
+	require.Error(err, "unsupported transaction type")
+}
+
+type testClient struct {
+	t   *testing.T
+	url string
+	id  int
+}
+
+// call invokes the given method, decoding its result into rsp and returning the JSON-RPC error.
+func (c *testClient) call(rsp interface{}, method string, params ...interface{}) *Error {
+	c.id++
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": c.id, "method": method, "params": params})
+	require.NoError(c.t, err, "Marshal")
+	httpRsp, err := http.Post(c.url, "application/json", bytes.NewReader(body))
+	require.NoError(c.t, err, "Post")
+	defer httpRsp.Body.Close()
+
+	var result struct {
+		Result json.RawMessage `json:"result"`
+		Error  *Error          `json:"error"`
+	}
+	require.NoError(c.t, json.NewDecoder(httpRsp.Body).Decode(&result), "Decode")
+	if result.Error == nil && rsp != nil {
+		require.NoError(c.t, json.Unmarshal(result.Result, rsp), "Unmarshal result of %s", method)
+	}
+	return result.Error
+}
+
+func TestProxy(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rt := fakeruntime.New(fakeruntime.Config{
+		CoreParameters: core.Parameters{
+			MaxBatchGas: 10_000_000,
+			MinGasPrice: map[types.Denomination]types.Quantity{types.NativeDenomination: *quantity.NewFromUint64(1)},
+		},
+		EVMCallHandler: func(call *fakeruntime.EVMCall) ([]byte, error) {
+			return append(append([]byte{}, call.Code...), call.Data...), nil
+		},
+	})
+	dave := sdkTesting.Dave
+	rt.SetBalance(dave.Address, types.NewBaseUnits(*quantity.NewFromUint64(100_000_000), types.NativeDenomination))
+
+	proxy, err := New(rt, Config{ChainID: testChainID, Accounts: []signature.Signer{dave.Signer}})
+	require.NoError(err, "New")
+	_, err = New(rt, Config{Accounts: []signature.Signer{sdkTesting.Alice.Signer}})
+	require.Error(err, "New with an ed25519 account")
+	srv := httptest.NewServer(proxy)
+	defer srv.Close()
+	c := &testClient{t: t, url: srv.URL}
+
+	var chainID hexUint64
+	require.Nil(c.call(&chainID, "eth_chainId"))
+	require.EqualValues(testChainID, chainID)
+	var accts []address
+	require.Nil(c.call(&accts, "eth_accounts"))
+	require.Len(accts, 1)
+	require.Equal(dave.EthAddress, accts[0][:])
+	var gasPrice hexBig
+	require.Nil(c.call(&gasPrice, "eth_gasPrice"))
+	require.EqualValues(1, gasPrice.Int().Uint64())
+
+	// Deploy a contract.
+	initCode := hexBytes{0x60, 0x80}
+	var deployHash hexBytes
+	require.Nil(c.call(&deployHash, "eth_sendTransaction", map[string]interface{}{"from": accts[0], "data": initCode}))
+	var rcpt rpcReceipt
+	require.Nil(c.call(&rcpt, "eth_getTransactionReceipt", deployHash))
+	require.EqualValues(1, rcpt.Status, "deployment should succeed")
+	require.NotNil(rcpt.ContractAddress)
+	contract := *rcpt.ContractAddress
+	require.Equal(fakeruntime.CreateAddress(dave.EthAddress, 0), contract[:])
+	require.Equal(accts[0], rcpt.From)
+	require.Nil(rcpt.To)
+	var code hexBytes
+	require.Nil(c.call(&code, "eth_getCode", contract, "latest"))
+	require.Equal(initCode, code)
+
+	// Call the contract.
+	var callHash hexBytes
+	require.Nil(c.call(&callHash, "eth_sendTransaction", map[string]interface{}{
+		"from":  accts[0],
+		"to":    contract,
+		"data":  hexBytes{0x01},
+		"value": "0x10",
+		"gas":   "0x10000",
+	}))
+	var tx rpcTransaction
+	require.Nil(c.call(&tx, "eth_getTransactionByHash", callHash))
+	require.Equal(contract, *tx.To)
+	require.Equal(hexBytes{0x01}, tx.Input)
+	require.EqualValues(0x10, tx.Value.Int().Uint64())
+	require.EqualValues(0x10000, tx.Gas)
+	require.EqualValues(1, tx.Nonce)
+	require.EqualValues(1, tx.GasPrice.Int().Uint64())
+	var balance hexBig
+	require.Nil(c.call(&balance, "eth_getBalance", contract))
+	require.EqualValues(0x10, balance.Int().Uint64())
+	var nonce hexUint64
+	require.Nil(c.call(&nonce, "eth_getTransactionCount", accts[0], "latest"))
+	require.EqualValues(2, nonce)
+
+	// Simulate a call.
+	var output hexBytes
+	require.Nil(c.call(&output, "eth_call", map[string]interface{}{"to": contract, "data": hexBytes{0x02}}, "latest"))
+	require.Equal(hexBytes{0x60, 0x80, 0x02}, output)
+
+	// Blocks.
+	var blk rpcBlock
+	require.Nil(c.call(&blk, "eth_getBlockByNumber", "latest", false))
+	require.EqualValues(tx.BlockNumber, blk.Number)
+	require.Equal(tx.BlockHash, blk.Hash)
+	require.Len(blk.Transactions, 1)
+	require.EqualValues(10_000_000, blk.GasLimit)
+	var byHash map[string]interface{}
+	require.Nil(c.call(&byHash, "eth_getBlockByHash", blk.Hash, true))
+	require.Len(byHash["transactions"], 1)
+	require.Nil(c.call(&byHash, "eth_getBlockByHash", make(hexBytes, 32), true))
+	require.Nil(byHash, "unknown block")
+	var blockNumber hexUint64
+	require.Nil(c.call(&blockNumber, "eth_blockNumber"))
+	require.Equal(blk.Number, blockNumber)
+	var logs []*rpcLog
+	require.Nil(c.call(&logs, "eth_getLogs", map[string]interface{}{"fromBlock": "0x0", "address": contract}))
+	require.Empty(logs)
+
+	// Hashes that are no longer cached are looked up in the most recent rounds.
+	clearCaches := func() {
+		proxy.mu.Lock()
+		defer proxy.mu.Unlock()
+		proxy.txs = make(map[[32]byte]txLocation)
+		proxy.blocks = make(map[hash.Hash]uint64)
+	}
+	clearCaches()
+	var evicted rpcTransaction
+	require.Nil(c.call(&evicted, "eth_getTransactionByHash", deployHash))
+	require.Equal(rcpt.BlockHash, evicted.BlockHash)
+	clearCaches()
+	byHash = nil
+	require.Nil(c.call(&byHash, "eth_getBlockByHash", blk.Hash, false))
+	require.NotNil(byHash, "evicted block")
+	clearCaches()
+	require.Nil(c.call(&logs, "eth_getLogs", map[string]interface{}{"blockHash": blk.Hash}))
+	require.Empty(logs)
+
+	// Raw transactions are submitted as is, and rejected by the fake runtime.
+	key, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(err, "NewPrivateKey")
+	raw := signEthTx(t, key, ethTxTypeLegacy, testChainID,
+		rlpEncodeUint64(0), rlpEncodeUint64(1), rlpEncodeUint64(21000), rlpEncodeBytes(contract[:]), rlpEncodeUint64(0), rlpEncodeBytes(nil),
+	)
+	rpcErr := c.call(nil, "eth_sendRawTransaction", hexBytes(raw))
+	require.NotNil(rpcErr)
+	require.Equal(ErrCodeServer, rpcErr.Code)
+	require.Contains(rpcErr.Message, ethereumScheme)
+	raw = signEthTx(t, key, ethTxTypeLegacy, 1,
+		rlpEncodeUint64(0), rlpEncodeUint64(1), rlpEncodeUint64(21000), rlpEncodeBytes(contract[:]), rlpEncodeUint64(0), rlpEncodeBytes(nil),
+	)
+	rpcErr = c.call(nil, "eth_sendRawTransaction", hexBytes(raw))
+	require.NotNil(rpcErr)
+	require.Equal(ErrCodeInvalidParams, rpcErr.Code, "transaction of another chain")
+
+	// Errors.
+	rpcErr = c.call(nil, "eth_sendTransaction", map[string]interface{}{"from": address{}, "to": contract})
+	require.NotNil(rpcErr)
+	require.Equal(ErrCodeInvalidParams, rpcErr.Code, "unknown account")
+	rpcErr = c.call(nil, "eth_newFilter")
+	require.NotNil(rpcErr)
+	require.Equal(ErrCodeMethodNotFound, rpcErr.Code)
+	rpcErr = c.call(nil, "eth_getBalance")
+	require.NotNil(rpcErr)
+	require.Equal(ErrCodeInvalidParams, rpcErr.Code)
+	var unknown *rpcTransaction
+	require.Nil(c.call(&unknown, "eth_getTransactionReceipt", make(hexBytes, 32)))
+	require.Nil(unknown)
+
+	// Batches.
+	rsp := proxy.handleMessage(ctx, []byte(`[{"jsonrpc":"2.0","id":1,"method":"net_version"},{"jsonrpc":"2.0","method":"net_version"}]`))
+	rsps, ok := rsp.([]*jsonrpcResponse)
+	require.True(ok, "batch response")
+	require.Len(rsps, 1)
+	require.JSONEq(`"23294"`, string(rsps[0].Result))
+}
+
+func TestCacheLimits(t *testing.T) {
+	require := require.New(t)
+
+	proxy, err := New(&logsClient{}, Config{})
+	require.NoError(err, "New")
+
+	var h [32]byte
+	for i := 0; i < maxCachedTxs+10; i++ {
+		binary.BigEndian.PutUint64(h[:], uint64(i))
+		proxy.remember(h, txLocation{round: uint64(i)})
+	}
+	require.Len(proxy.txs, maxCachedTxs)
+	proxy.remember(h, txLocation{round: 1})
+	require.Len(proxy.txs, maxCachedTxs, "updating a cached location should not evict")
+
+	for i := 0; i < maxCachedBlocks+10; i++ {
+		binary.BigEndian.PutUint64(h[:], uint64(i))
+		proxy.rememberBlockLocked(hash.Hash(h), uint64(i))
+	}
+	require.Len(proxy.blocks, maxCachedBlocks)
+}
+
+func TestLogs(t *testing.T) {
+	require := require.New(t)
+
+	emitter := address{0x01}
+	topic := bytes.Repeat([]byte{0x02}, 32)
+	logEvent := func(addr address, topics ...[]byte) *types.Event {
+		return &types.Event{
+			Module: evm.ModuleName,
+			Code:   evm.LogEventCode,
+			Value:  cbor.Marshal(&evm.LogEvent{Address: addr[:], Topics: topics, Data: []byte{0x03}}),
+		}
+	}
+	rd := &roundData{
+		blk: &block.Block{Header: block.Header{Round: 5}},
+		txs: []*client.TransactionWithResults{
+			{Events: []*types.Event{logEvent(emitter, topic), {Module: "accounts", Code: 1}}},
+			{
+				Result: types.CallResult{Failed: &types.FailedCallResult{Module: evm.ModuleName, Code: 2}},
+				Events: []*types.Event{logEvent(address{0x04}), logEvent(emitter)},
+			},
+		},
+	}
+	for _, txr := range rd.txs {
+		rd.infos = append(rd.infos, decodeTx(&txr.Tx))
+	}
+
+	logs := rd.logs()
+	require.Len(logs, 2)
+	require.Len(logs[0], 1)
+	require.Len(logs[1], 2)
+	require.EqualValues(2, logs[1][1].LogIndex, "log indices should be unique within the block")
+	require.EqualValues(1, logs[1][1].TransactionIndex)
+	require.EqualValues(5, logs[1][1].BlockNumber)
+	require.Equal(hexBytes{0x03}, logs[0][0].Data)
+
+	filter := &logFilter{Address: addressSet{emitter}}
+	require.True(filter.matches(logs[0][0]))
+	require.False(filter.matches(logs[1][0]))
+	require.True(filter.matches(logs[1][1]))
+	filter.Topics = []topicSet{{topic}}
+	require.True(filter.matches(logs[0][0]))
+	require.False(filter.matches(logs[1][1]), "log without topics")
+	filter = &logFilter{}
+	require.NoError(json.Unmarshal([]byte(`{"address":["0x0100000000000000000000000000000000000000"],"topics":[null,"0x02"]}`), filter))
+	require.Nil(filter.Topics[0])
+	require.False(filter.matches(logs[0][0]))
+
+	rcpt := rd.newRPCReceipt(1)
+	require.Len(rcpt.Logs, 2)
+	require.EqualValues(0, rcpt.Status, "failed transaction")
+}
+
+// logsClient is a runtime client serving blocks with a single transaction emitting the given
+// events in each round, which counts the fetched transactions.
+type logsClient struct {
+	client.RuntimeClient
+
+	events  map[uint64][]*types.Event
+	latest  uint64
+	fetched int
+}
+
+func (c *logsClient) GetBlock(ctx context.Context, round uint64) (*block.Block, error) {
+	if round == client.RoundLatest {
+		round = c.latest
+	}
+	return &block.Block{Header: block.Header{Round: round}}, nil
+}
+
+func (c *logsClient) GetGenesisBlock(ctx context.Context) (*block.Block, error) {
+	return c.GetBlock(ctx, 0)
+}
+
+func (c *logsClient) GetEventsRaw(ctx context.Context, round uint64) ([]*types.Event, error) {
+	return c.events[round], nil
+}
+
+func (c *logsClient) GetTransactionsWithResults(ctx context.Context, round uint64) ([]*client.TransactionWithResults, error) {
+	c.fetched++
+	return []*client.TransactionWithResults{{Events: c.events[round]}}, nil
+}
+
+func TestLogsBloom(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	emitter, other := address{0x01}, address{0x02}
+	topic := bytes.Repeat([]byte{0x03}, 32)
+	logEvent := func(addr address, topics ...[]byte) *types.Event {
+		return &types.Event{
+			Module: evm.ModuleName,
+			Code:   evm.LogEventCode,
+			Value:  cbor.Marshal(&evm.LogEvent{Address: addr[:], Topics: topics}),
+		}
+	}
+	rc := &logsClient{
+		events: map[uint64][]*types.Event{
+			3: {logEvent(emitter, topic)},
+			7: {logEvent(other)},
+		},
+		latest: 10,
+	}
+	proxy, err := New(rc, Config{})
+	require.NoError(err, "New")
+
+	b := eventsBloom(rc.events[3])
+	require.True(b.contains(emitter[:]))
+	require.True(b.contains(topic))
+	require.False(b.contains(other[:]))
+	require.Equal(hexBytes(b[:]), logsBloom([]*rpcLog{{Address: emitter, Topics: []hexBytes{topic}}}))
+	require.Equal(make(hexBytes, bloomSize), logsBloom(nil))
+
+	// Only rounds that may contain matching logs are fetched.
+	logs, err := proxy.getLogs(ctx, &logFilter{FromBlock: &blockTag{tag: blockTagEarliest}, Address: addressSet{emitter}})
+	require.NoError(err, "getLogs")
+	require.Len(logs, 1)
+	require.EqualValues(3, logs[0].BlockNumber)
+	require.Equal(1, rc.fetched)
+
+	logs, err = proxy.getLogs(ctx, &logFilter{FromBlock: &blockTag{tag: blockTagEarliest}, Topics: []topicSet{nil, {topic}}})
+	require.NoError(err, "getLogs")
+	require.Empty(logs)
+	require.Equal(2, rc.fetched, "blooms do not record the positions of topics")
+
+	logs, err = proxy.getLogs(ctx, &logFilter{FromBlock: &blockTag{tag: blockTagEarliest}, Topics: []topicSet{{bytes.Repeat([]byte{0x04}, 32)}}})
+	require.NoError(err, "getLogs")
+	require.Empty(logs)
+	require.Equal(2, rc.fetched, "rounds without the topic should be skipped")
+
+	logs, err = proxy.getLogs(ctx, &logFilter{FromBlock: &blockTag{tag: blockTagEarliest}})
+	require.NoError(err, "getLogs")
+	require.Len(logs, 2)
+	require.Equal(13, rc.fetched, "all rounds should be fetched without a filter")
+}
@@ -0,0 +1,208 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/indexer"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/processor"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+)
+
+const (
+	// DefaultInitialBackoff is the default delay before the first retry of a failed delivery.
+	DefaultInitialBackoff = time.Second
+	// DefaultMaxBackoff is the default maximum delay between retries of a failed delivery.
+	DefaultMaxBackoff = 5 * time.Minute
+
+	// maxErrorBodySize is the maximum size of an error response body included in errors.
+	maxErrorBodySize = 1024
+)
+
+// Config is the dispatcher configuration.
+type Config struct {
+	// Rules are the rules events are matched against. An event matching multiple rules is
+	// notified once for each of them.
+	Rules []*Rule
+
+	// Store is the store of the last processed round. If nil, an in-memory store is used.
+	Store processor.Store
+	// StartRound is the first round to process in case the store is empty. If zero, processing
+	// starts with the latest round.
+	StartRound uint64
+
+	// Client is the HTTP client used for deliveries. If nil, http.DefaultClient is used.
+	Client *http.Client
+	// InitialBackoff is the delay before the first retry of a failed delivery or after another
+	// failure. If zero, DefaultInitialBackoff is used.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum delay between retries of failed deliveries and after other
+	// failures. If zero, DefaultMaxBackoff is used.
+	MaxBackoff time.Duration
+
+	// OnDeliveryError, if not nil, is called for each failed delivery attempt, e.g. for logging.
+	OnDeliveryError func(n *Notification, err error)
+	// OnError, if not nil, is called for each other failure that is retried, e.g. for logging.
+	OnError func(err error)
+}
+
+// Dispatcher delivers webhook notifications for the events of a runtime.
+type Dispatcher struct {
+	cfg Config
+	p   *processor.Processor
+}
+
+// Run processes all rounds following the last processed round, and then the rounds of new blocks
+// as they are finalized, until the context is canceled or a chain inconsistency is detected.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	return d.p.Run(ctx)
+}
+
+// handle delivers the notifications for the events of the given round. It only returns once all
+// notifications have been delivered or the context is canceled.
+func (d *Dispatcher) handle(ctx context.Context, tx processor.Tx, data *indexer.RoundData) error {
+	for _, n := range d.Notifications(data) {
+		if err := d.deliver(ctx, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Notifications returns the notifications for the events of the given round, in the order of the
+// events.
+func (d *Dispatcher) Notifications(data *indexer.RoundData) []*Notification {
+	var ns []*Notification
+	for _, ev := range data.Events {
+		decoded, err := registry.DecodeEvent(ev.Raw())
+		if err != nil || decoded == nil {
+			continue
+		}
+
+		for _, rule := range d.cfg.Rules {
+			if !rule.Matches(ev.Module, decoded) {
+				continue
+			}
+			n := &Notification{
+				ID:         notificationID(data.Block.Round, ev.Index, rule.Name),
+				Rule:       rule.Name,
+				Round:      data.Block.Round,
+				Timestamp:  data.Block.Timestamp,
+				EventIndex: ev.Index,
+				Module:     ev.Module,
+				Code:       ev.Code,
+				Event:      decoded,
+			}
+			if ev.TxIndex != nil {
+				n.TxHash = &data.Transactions[*ev.TxIndex].Hash
+			}
+			ns = append(ns, n)
+		}
+	}
+	return ns
+}
+
+// deliver delivers the notification, retrying until it succeeds or the context is canceled.
+func (d *Dispatcher) deliver(ctx context.Context, n *Notification) error {
+	rule := d.rule(n.Rule)
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("webhook: failed to encode notification: %w", err)
+	}
+
+	backoff := d.cfg.InitialBackoff
+	for {
+		err = d.post(ctx, rule, body)
+		if err == nil {
+			return nil
+		}
+		if d.cfg.OnDeliveryError != nil {
+			d.cfg.OnDeliveryError(n, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > d.cfg.MaxBackoff {
+			backoff = d.cfg.MaxBackoff
+		}
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, rule *Rule, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rule.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook: failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(rule.Secret, time.Now(), body))
+
+	rsp, err := d.cfg.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: request failed: %w", err)
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(rsp.Body, maxErrorBodySize))
+		return fmt.Errorf("webhook: request failed with status %s: %s", rsp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+func (d *Dispatcher) rule(name string) *Rule {
+	for _, rule := range d.cfg.Rules {
+		if rule.Name == name {
+			return rule
+		}
+	}
+	return nil
+}
+
+// New creates a new dispatcher of notifications for the events of the runtime of the given
+// client.
+func New(rc client.RuntimeClient, cfg Config) (*Dispatcher, error) {
+	names := make(map[string]struct{})
+	for _, rule := range cfg.Rules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+		if _, exists := names[rule.Name]; exists {
+			return nil, fmt.Errorf("webhook: duplicate rule '%s'", rule.Name)
+		}
+		names[rule.Name] = struct{}{}
+	}
+
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	if cfg.InitialBackoff == 0 {
+		cfg.InitialBackoff = DefaultInitialBackoff
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+
+	d := &Dispatcher{cfg: cfg}
+	p, err := processor.New(rc, processor.Config{
+		Handler:        d.handle,
+		Store:          cfg.Store,
+		StartRound:     cfg.StartRound,
+		InitialBackoff: cfg.InitialBackoff,
+		MaxBackoff:     cfg.MaxBackoff,
+		OnError:        cfg.OnError,
+	})
+	if err != nil {
+		return nil, err
+	}
+	d.p = p
+	return d, nil
+}
@@ -0,0 +1,192 @@
+// Package webhook implements a dispatcher of webhook notifications for runtime events.
+//
+// The dispatcher follows the blocks of a runtime, matches their decoded events against a set of
+// rules and delivers a signed notification for each match to the endpoint of the rule, e.g. for
+// payment processors to be notified of deposits to their addresses.
+//
+// The dispatcher runs on a round processor (see the processor package). Notifications are
+// delivered at least once: failed deliveries are retried with exponential backoff, and a round is
+// only recorded as processed in the store once all of its notifications have been delivered.
+// Receivers should use the notification identifier, which is the same for all deliveries of a
+// notification, to skip duplicates.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/registry"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// SignatureHeader is the HTTP header carrying the signature of a notification.
+//
+// The header has the form "t=<timestamp>,v1=<signature>", where the timestamp is the time of the
+// delivery in seconds since the Unix epoch and the signature is the hex-encoded HMAC-SHA256, keyed
+// by the secret of the rule, of the timestamp, a dot and the request body.
+const SignatureHeader = "X-Oasis-Signature"
+
+// ErrInvalidSignature is the error returned when a notification signature is invalid.
+var ErrInvalidSignature = errors.New("webhook: invalid signature")
+
+// Rule selects the events notified to a webhook endpoint. An event matches a rule in case it
+// satisfies all of the rule's conditions.
+type Rule struct {
+	// Name is the unique name of the rule.
+	Name string `json:"name"`
+	// URL is the URL of the webhook endpoint notifications are POSTed to.
+	URL string `json:"url"`
+	// Secret is the secret notifications are signed with.
+	Secret string `json:"secret"`
+
+	// Modules are the modules whose events match. If empty, events of all modules match.
+	Modules []string `json:"modules,omitempty"`
+	// Addresses are the addresses of which at least one must be involved in a matching event. If
+	// empty, events match regardless of the involved addresses.
+	Addresses []types.Address `json:"addresses,omitempty"`
+	// MinAmount is the minimum amount in the given denomination an event must contain to match.
+	// If nil, events match regardless of the amounts they contain.
+	MinAmount *types.BaseUnits `json:"min_amount,omitempty"`
+}
+
+// Validate checks whether the rule is valid.
+func (r *Rule) Validate() error {
+	switch {
+	case r.Name == "":
+		return fmt.Errorf("webhook: rule without a name")
+	case r.URL == "":
+		return fmt.Errorf("webhook: rule '%s' without a URL", r.Name)
+	case r.Secret == "":
+		return fmt.Errorf("webhook: rule '%s' without a secret", r.Name)
+	default:
+		return nil
+	}
+}
+
+// Matches checks whether the given decoded event of the given module matches the rule.
+func (r *Rule) Matches(module string, ev client.DecodedEvent) bool {
+	if len(r.Modules) > 0 && !containsString(r.Modules, module) {
+		return false
+	}
+	if len(r.Addresses) > 0 && !r.involves(ev) {
+		return false
+	}
+	if r.MinAmount != nil && !r.exceedsMinAmount(ev) {
+		return false
+	}
+	return true
+}
+
+func (r *Rule) involves(ev client.DecodedEvent) bool {
+	for _, addr := range registry.Addresses(ev) {
+		for _, ruleAddr := range r.Addresses {
+			if addr.Equal(ruleAddr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *Rule) exceedsMinAmount(ev client.DecodedEvent) bool {
+	for _, amount := range registry.Amounts(ev) {
+		if amount.Denomination == r.MinAmount.Denomination && amount.Amount.Cmp(&r.MinAmount.Amount) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification is the JSON-encoded body of a webhook notification.
+type Notification struct {
+	// ID is the identifier of the notification, which is the same for all its deliveries.
+	ID string `json:"id"`
+	// Rule is the name of the matched rule.
+	Rule string `json:"rule"`
+
+	// Round is the round in which the event was emitted.
+	Round uint64 `json:"round"`
+	// Timestamp is the timestamp of the block in seconds since the Unix epoch.
+	Timestamp uint64 `json:"timestamp"`
+	// EventIndex is the index of the event within the round.
+	EventIndex uint32 `json:"event_index"`
+	// TxHash is the hash of the transaction that emitted the event, if any.
+	TxHash *hash.Hash `json:"tx_hash,omitempty"`
+
+	// Module is the module that emitted the event.
+	Module string `json:"module"`
+	// Code is the event code.
+	Code uint32 `json:"code"`
+	// Event is the decoded event.
+	Event client.DecodedEvent `json:"event"`
+}
+
+// notificationID returns the identifier of the notification of the given event and rule.
+func notificationID(round uint64, eventIndex uint32, rule string) string {
+	return fmt.Sprintf("%d-%d-%s", round, eventIndex, rule)
+}
+
+// Sign returns the value of the signature header for the given body delivered at the given time.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac(secret, t, body))
+}
+
+// Verify verifies the value of the signature header of a notification with the given body. The
+// signature is rejected in case it was made more than the given tolerance before or after now,
+// to limit replays. A zero tolerance disables the check.
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var t, sig string
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return ErrInvalidSignature
+		}
+		switch kv[0] {
+		case "t":
+			t = kv[1]
+		case "v1":
+			sig = kv[1]
+		}
+	}
+	timestamp, err := strconv.ParseInt(t, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	rawSig, err := hex.DecodeString(sig)
+	if err != nil || !hmac.Equal(rawSig, mac(secret, t, body)) {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 {
+		if age := now.Sub(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+			return fmt.Errorf("%w: timestamp outside of tolerance", ErrInvalidSignature)
+		}
+	}
+	return nil
+}
+
+func mac(secret, timestamp string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	_, _ = h.Write([]byte(timestamp))
+	_, _ = h.Write([]byte("."))
+	_, _ = h.Write(body)
+	return h.Sum(nil)
+}
@@ -0,0 +1,170 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/processor"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/fakeruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const testSecret = "secret"
+
+func nativeUnits(amount uint64) types.BaseUnits {
+	return types.NewBaseUnits(*quantity.NewFromUint64(amount), types.NativeDenomination)
+}
+
+func TestRuleMatches(t *testing.T) {
+	require := require.New(t)
+
+	ev := &accounts.Event{Transfer: &accounts.TransferEvent{
+		From:   sdkTesting.Alice.Address,
+		To:     sdkTesting.Bob.Address,
+		Amount: nativeUnits(100),
+	}}
+	minAmount := nativeUnits(100)
+	tooMuch := nativeUnits(101)
+	otherDenomination := types.NewBaseUnits(*quantity.NewFromUint64(1), "OTHER")
+	for _, tc := range []struct {
+		rule    Rule
+		matches bool
+	}{
+		{Rule{}, true},
+		{Rule{Modules: []string{accounts.ModuleName}}, true},
+		{Rule{Modules: []string{"consensus_accounts"}}, false},
+		{Rule{Addresses: []types.Address{sdkTesting.Charlie.Address, sdkTesting.Bob.Address}}, true},
+		{Rule{Addresses: []types.Address{sdkTesting.Charlie.Address}}, false},
+		{Rule{MinAmount: &minAmount}, true},
+		{Rule{MinAmount: &tooMuch}, false},
+		{Rule{MinAmount: &otherDenomination}, false},
+		{Rule{Modules: []string{accounts.ModuleName}, Addresses: []types.Address{sdkTesting.Bob.Address}, MinAmount: &tooMuch}, false},
+	} {
+		require.Equal(tc.matches, tc.rule.Matches(accounts.ModuleName, ev), "rule %+v", tc.rule)
+	}
+}
+
+func TestSignature(t *testing.T) {
+	require := require.New(t)
+
+	now := time.Now()
+	body := []byte(`{"id":"1-0-deposits"}`)
+	header := Sign(testSecret, now, body)
+	require.NoError(Verify(testSecret, header, body, time.Minute, now))
+	require.NoError(Verify(testSecret, header, body, 0, now.Add(time.Hour)), "zero tolerance")
+
+	require.ErrorIs(Verify("other", header, body, time.Minute, now), ErrInvalidSignature)
+	require.ErrorIs(Verify(testSecret, header, []byte("{}"), time.Minute, now), ErrInvalidSignature)
+	require.ErrorIs(Verify(testSecret, header, body, time.Minute, now.Add(time.Hour)), ErrInvalidSignature)
+	require.ErrorIs(Verify(testSecret, "bogus", body, time.Minute, now), ErrInvalidSignature)
+}
+
+func TestDispatcher(t *testing.T) {
+	require := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rt := fakeruntime.New(fakeruntime.Config{})
+	rt.SetBalance(sdkTesting.Alice.Address, nativeUnits(1000))
+	transfer := func(to types.Address, amount, nonce uint64) uint64 {
+		tb := accounts.NewV1(rt).Transfer(to, nativeUnits(amount))
+		tb.AppendAuthSignature(sdkTesting.Alice.SigSpec, nonce)
+		require.NoError(tb.AppendSign(ctx, sdkTesting.Alice.Signer), "AppendSign")
+		meta, err := tb.SubmitTxMeta(ctx, nil)
+		require.NoError(err, "SubmitTxMeta")
+		return meta.Round
+	}
+
+	var (
+		mu            sync.Mutex
+		notifications []*Notification
+		attempts      int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		body, _ := io.ReadAll(r.Body)
+		if err := Verify(testSecret, r.Header.Get(SignatureHeader), body, time.Minute, time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		// Fail the first delivery to exercise retries.
+		if attempts++; attempts == 1 {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		var n Notification
+		if err := json.Unmarshal(body, &n); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		notifications = append(notifications, &n)
+	}))
+	defer srv.Close()
+
+	deposits := transfer(sdkTesting.Bob.Address, 100, 0)
+	transfer(sdkTesting.Bob.Address, 10, 1)
+	transfer(sdkTesting.Charlie.Address, 100, 2)
+
+	minAmount := nativeUnits(50)
+	store := processor.NewFileStore(filepath.Join(t.TempDir(), "position"))
+	var deliveryErrors int
+	d, err := New(rt, Config{
+		Rules: []*Rule{{
+			Name:      "deposits",
+			URL:       srv.URL,
+			Secret:    testSecret,
+			Addresses: []types.Address{sdkTesting.Bob.Address},
+			MinAmount: &minAmount,
+		}},
+		Store:           store,
+		StartRound:      deposits,
+		InitialBackoff:  10 * time.Millisecond,
+		OnDeliveryError: func(n *Notification, err error) { deliveryErrors++ },
+	})
+	require.NoError(err, "New")
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- d.Run(ctx) }()
+
+	// Rounds finalized while running should be processed as well.
+	last := transfer(sdkTesting.Bob.Address, 60, 3)
+	require.Eventually(func() bool {
+		pos, loadErr := store.Load(ctx)
+		return loadErr == nil && pos != nil && pos.Round >= last
+	}, 5*time.Second, 10*time.Millisecond, "all rounds should be processed")
+	cancel()
+	require.ErrorIs(<-errCh, context.Canceled)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(1, deliveryErrors, "failed delivery should be retried")
+	require.Len(notifications, 2)
+	n := notifications[0]
+	require.Equal(notificationID(deposits, 0, "deposits"), n.ID)
+	require.Equal(deposits, n.Round)
+	require.Equal(accounts.ModuleName, n.Module)
+	require.NotNil(n.TxHash)
+	require.Equal(last, notifications[1].Round)
+
+	// Rules must be valid and have distinct names.
+	_, err = New(rt, Config{Rules: []*Rule{{Name: "r", URL: srv.URL}}})
+	require.Error(err, "rule without a secret")
+	rule := &Rule{Name: "r", URL: srv.URL, Secret: testSecret}
+	_, err = New(rt, Config{Rules: []*Rule{rule, rule}})
+	require.Error(err, "duplicate rules")
+}
@@ -0,0 +1,58 @@
+import * as oasis from '@oasisprotocol/client';
+import * as fs from 'fs';
+import * as path from 'path';
+
+import * as oasisRT from './../src';
+
+function readVectors(name: string) {
+    const file = path.join(__dirname, '..', '..', '..', '..', 'tests', 'vectors', 'v1', name);
+    return JSON.parse(fs.readFileSync(file, 'utf8'));
+}
+
+describe('vectors', () => {
+    describe('addresses', () => {
+        const vectors = readVectors('addresses.json');
+
+        it('Should derive signature addresses correctly', async () => {
+            for (const v of vectors) {
+                // Sr25519 addresses are not supported yet.
+                if (!v.sigspec || v.sigspec.sr25519) continue;
+                const spec: oasisRT.types.SignatureAddressSpec = v.sigspec.ed25519
+                    ? {ed25519: new Uint8Array(Buffer.from(v.sigspec.ed25519, 'base64'))}
+                    : {secp256k1eth: new Uint8Array(Buffer.from(v.sigspec.secp256k1eth, 'base64'))};
+                const address = await oasisRT.address.fromSigspec(spec);
+                expect(oasisRT.address.toBech32(address)).toEqual(v.address);
+                expect(oasis.misc.toHex(address)).toEqual(v.address_raw);
+            }
+        });
+
+        it('Should derive multisig addresses correctly', async () => {
+            for (const v of vectors) {
+                if (!v.multisig) continue;
+                const config = oasis.misc.fromCBOR(
+                    oasis.misc.fromHex(v.multisig),
+                ) as oasisRT.types.MultisigConfig;
+                const address = await oasisRT.address.fromMultisigConfig(config);
+                expect(oasisRT.address.toBech32(address)).toEqual(v.address);
+            }
+        });
+    });
+
+    describe('transactions', () => {
+        const vectors = readVectors('transactions.json');
+
+        it('Should encode transactions canonically', () => {
+            for (const v of vectors) {
+                const signed = oasis.misc.fromHex(v.signed_tx);
+                const utx = oasis.misc.fromCBOR(signed) as oasisRT.types.UnverifiedTransaction;
+                expect(oasis.misc.toHex(oasis.misc.toCBOR(utx))).toEqual(v.signed_tx);
+                expect(oasis.misc.toHex(utx[0])).toEqual(v.tx);
+
+                const tx = oasis.misc.fromCBOR(utx[0]) as oasisRT.types.Transaction;
+                expect(oasis.misc.toHex(oasis.misc.toCBOR(tx))).toEqual(v.tx);
+                expect(tx.call.method).toEqual(v.method);
+                expect(BigInt(tx.ai.si[0].nonce)).toEqual(BigInt(v.nonce));
+            }
+        });
+    });
+});
@@ -60,6 +60,7 @@ pub enum Event {
         from: Address,
         to: Address,
         amount: token::BaseUnits,
+        memo: Vec<u8>,
     },
 
     #[sdk_event(code = 2)]
@@ -140,6 +141,16 @@ pub trait API {
         amount: &token::BaseUnits,
     ) -> Result<(), Error>;
 
+    /// Transfer an amount from one account to the other. The memo is included in the transfer
+    /// event.
+    fn transfer_with_memo<C: Context>(
+        ctx: &mut C,
+        from: Address,
+        to: Address,
+        amount: &token::BaseUnits,
+        memo: Vec<u8>,
+    ) -> Result<(), Error>;
+
     /// Mint new tokens, increasing the total supply.
     fn mint<C: Context>(ctx: &mut C, to: Address, amount: &token::BaseUnits) -> Result<(), Error>;
 
@@ -393,6 +404,16 @@ impl API for Module {
         from: Address,
         to: Address,
         amount: &token::BaseUnits,
+    ) -> Result<(), Error> {
+        Self::transfer_with_memo(ctx, from, to, amount, Vec::new())
+    }
+
+    fn transfer_with_memo<C: Context>(
+        ctx: &mut C,
+        from: Address,
+        to: Address,
+        amount: &token::BaseUnits,
+        memo: Vec<u8>,
     ) -> Result<(), Error> {
         if ctx.is_check_only() {
             return Ok(());
@@ -408,6 +429,7 @@ impl API for Module {
             from,
             to,
             amount: amount.clone(),
+            memo,
         });
 
         Ok(())
@@ -613,7 +635,13 @@ impl Module {
 
         Core::use_tx_gas(ctx, params.gas_costs.tx_transfer)?;
 
-        Self::transfer(ctx, ctx.tx_caller_address(), body.to, &body.amount)?;
+        Self::transfer_with_memo(
+            ctx,
+            ctx.tx_caller_address(),
+            body.to,
+            &body.amount,
+            body.memo,
+        )?;
 
         Ok(())
     }
@@ -639,6 +667,17 @@ impl Module {
     ) -> Result<types::AccountBalances, Error> {
         Self::get_balances(ctx.runtime_state(), args.address)
     }
+
+    fn query_parameters<C: Context>(ctx: &mut C, _args: ()) -> Result<Parameters, Error> {
+        Ok(Self::params(ctx.runtime_state()))
+    }
+
+    fn query_total_supplies<C: Context>(
+        ctx: &mut C,
+        _args: (),
+    ) -> Result<BTreeMap<token::Denomination, u128>, Error> {
+        Self::get_total_supplies(ctx.runtime_state())
+    }
 }
 
 impl module::Module for Module {
@@ -704,6 +743,10 @@ impl module::MethodHandler for Module {
             "accounts.Nonce" => module::dispatch_query(ctx, args, Self::query_nonce),
             "accounts.Balances" => module::dispatch_query(ctx, args, Self::query_balances),
             "accounts.Addresses" => module::dispatch_query(ctx, args, Self::query_addresses),
+            "accounts.Parameters" => module::dispatch_query(ctx, args, Self::query_parameters),
+            "accounts.TotalSupplies" => {
+                module::dispatch_query(ctx, args, Self::query_total_supplies)
+            }
             _ => module::DispatchResult::Unhandled(args),
         }
     }
@@ -8,6 +8,7 @@ use anyhow::anyhow;
 
 use crate::{
     context::{BatchContext, Context},
+    event::Event as _,
     module::{AuthHandler, BlockHandler, InvariantHandler, MethodHandler},
     modules::core,
     testing::{keys, mock},
@@ -18,7 +19,7 @@ use crate::{
 };
 
 use super::{
-    types::*, Error, Genesis, Module as Accounts, Parameters, ADDRESS_COMMON_POOL,
+    types::*, Error, Event, Genesis, Module as Accounts, Parameters, ADDRESS_COMMON_POOL,
     ADDRESS_FEE_ACCUMULATOR, API as _,
 };
 
@@ -220,6 +221,7 @@ fn test_api_tx_transfer_disabled() {
             body: cbor::to_value(Transfer {
                 to: keys::bob::address(),
                 amount: BaseUnits::new(1_000, Denomination::NATIVE),
+                memo: Vec::new(),
             }),
         },
         auth_info: transaction::AuthInfo {
@@ -272,6 +274,7 @@ fn test_prefetch() {
             body: cbor::to_value(Transfer {
                 to: keys::bob::address(),
                 amount: BaseUnits::new(1_000, Denomination::NATIVE),
+                memo: Vec::new(),
             }),
         },
         auth_info: auth_info.clone(),
@@ -398,6 +401,7 @@ fn test_authenticate_tx() {
             body: cbor::to_value(Transfer {
                 to: keys::bob::address(),
                 amount: BaseUnits::new(1_000, Denomination::NATIVE),
+                memo: Vec::new(),
             }),
         },
         auth_info: transaction::AuthInfo {
@@ -459,6 +463,7 @@ fn test_tx_transfer() {
             body: cbor::to_value(Transfer {
                 to: keys::bob::address(),
                 amount: BaseUnits::new(1_000, Denomination::NATIVE),
+                memo: b"invoice".to_vec(),
             }),
         },
         auth_info: transaction::AuthInfo {
@@ -506,6 +511,21 @@ fn test_tx_transfer() {
             1,
             "there should only be one denomination"
         );
+
+        // Ensure the transfer event includes the memo.
+        let (tags, _) = tx_ctx.commit();
+        let expected = Event::Transfer {
+            from: keys::alice::address(),
+            to: keys::bob::address(),
+            amount: BaseUnits::new(1_000, Denomination::NATIVE),
+            memo: b"invoice".to_vec(),
+        }
+        .into_tag();
+        assert!(
+            tags.iter()
+                .any(|tag| tag.key == expected.key && tag.value == expected.value),
+            "transfer event should be emitted"
+        );
     });
 }
 
@@ -531,6 +551,7 @@ fn test_fee_disbursement() {
             body: cbor::to_value(Transfer {
                 to: keys::bob::address(),
                 amount: Default::default(),
+                memo: Vec::new(),
             }),
         },
         auth_info: transaction::AuthInfo {
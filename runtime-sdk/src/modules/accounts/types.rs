@@ -10,6 +10,11 @@ use crate::types::{address::Address, token};
 pub struct Transfer {
     pub to: Address,
     pub amount: token::BaseUnits,
+
+    /// Optional opaque memo attached to the transfer.
+    #[cbor(optional)]
+    #[cbor(default)]
+    pub memo: Vec<u8>,
 }
 
 /// Account metadata.
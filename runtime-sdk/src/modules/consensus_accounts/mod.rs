@@ -14,7 +14,7 @@ use crate::{
     module::{CallResult, Module as _},
     modules,
     modules::core::{Error as CoreError, Module as Core, API as _},
-    storage::Prefix,
+    storage::{self, Prefix},
     types::{
         address::Address,
         message::{MessageEvent, MessageEventHookInvocation, MessageResult},
@@ -44,6 +44,10 @@ pub enum Error {
     #[sdk_error(code = 3)]
     InsufficientWithdrawBalance,
 
+    #[error("receipt not found")]
+    #[sdk_error(code = 4)]
+    ReceiptNotFound,
+
     #[error("consensus: {0}")]
     #[sdk_error(transparent)]
     Consensus(#[from] modules::consensus::Error),
@@ -58,6 +62,9 @@ pub enum Error {
 pub struct GasCosts {
     pub tx_deposit: u64,
     pub tx_withdraw: u64,
+    #[cbor(optional)]
+    #[cbor(default)]
+    pub tx_take_receipt: u64,
 }
 
 /// Parameters for the consensus module.
@@ -70,10 +77,41 @@ impl module::Parameters for Parameters {
     type Error = ();
 }
 
-/// Events emitted by the consensus module (none so far).
+/// Events emitted by the consensus accounts module.
 #[derive(Debug, cbor::Encode, oasis_runtime_sdk_macros::Event)]
 #[cbor(untagged)]
-pub enum Event {}
+pub enum Event {
+    /// A deposit from the consensus layer has been processed.
+    #[sdk_event(code = 1)]
+    Deposit {
+        address: Address,
+        amount: token::BaseUnits,
+        memo: Vec<u8>,
+        /// Module that emitted the consensus layer error, if any.
+        module: String,
+        /// Consensus layer error code, zero in case of success.
+        code: u32,
+    },
+
+    /// A withdrawal into the consensus layer has been processed.
+    #[sdk_event(code = 2)]
+    Withdraw {
+        address: Address,
+        amount: token::BaseUnits,
+        memo: Vec<u8>,
+        /// Module that emitted the consensus layer error, if any.
+        module: String,
+        /// Consensus layer error code, zero in case of success.
+        code: u32,
+    },
+}
+
+/// State schema constants.
+pub mod state {
+    /// Map of (address, receipt identifier) to deposit and withdrawal receipts. Receipts are
+    /// removed once they are taken.
+    pub const RECEIPTS: &[u8] = &[0x01];
+}
 
 /// Genesis state for the consensus module.
 #[derive(Clone, Debug, Default, cbor::Encode, cbor::Decode)]
@@ -117,6 +155,31 @@ impl<Accounts: modules::accounts::API, Consensus: modules::consensus::API> API
         ctx: &mut C,
         from: Address,
         amount: token::BaseUnits,
+    ) -> Result<(), Error> {
+        Self::deposit_with_receipt(ctx, from, amount, Vec::new(), None)
+    }
+
+    fn withdraw<C: TxContext>(
+        ctx: &mut C,
+        to: Address,
+        amount: token::BaseUnits,
+    ) -> Result<(), Error> {
+        Self::withdraw_with_receipt(ctx, to, amount, Vec::new(), None)
+    }
+}
+
+impl<Accounts: modules::accounts::API, Consensus: modules::consensus::API>
+    Module<Accounts, Consensus>
+{
+    /// Deposit an amount into the runtime account, optionally storing a receipt under the given
+    /// identifier once the consensus layer processes the withdrawal. The memo is included in the
+    /// deposit event.
+    fn deposit_with_receipt<C: TxContext>(
+        ctx: &mut C,
+        from: Address,
+        amount: token::BaseUnits,
+        memo: Vec<u8>,
+        receipt: Option<u64>,
     ) -> Result<(), Error> {
         if ctx.is_check_only() {
             // In case this is not check only this weight will be emitted from Cosnensus::withdraw
@@ -140,6 +203,8 @@ impl<Accounts: modules::accounts::API, Consensus: modules::consensus::API> API
                 types::ConsensusWithdrawContext {
                     address: from,
                     amount: amount.clone(),
+                    memo,
+                    receipt,
                 },
             ),
         )?;
@@ -147,10 +212,15 @@ impl<Accounts: modules::accounts::API, Consensus: modules::consensus::API> API
         Ok(())
     }
 
-    fn withdraw<C: TxContext>(
+    /// Withdraw an amount out from the runtime account, optionally storing a receipt under the
+    /// given identifier once the consensus layer processes the transfer. The memo is included in
+    /// the withdrawal event.
+    fn withdraw_with_receipt<C: TxContext>(
         ctx: &mut C,
         to: Address,
         amount: token::BaseUnits,
+        memo: Vec<u8>,
+        receipt: Option<u64>,
     ) -> Result<(), Error> {
         if ctx.is_check_only() {
             // In case this is not check only this weight will be emitted from Cosnensus::transfer
@@ -180,17 +250,15 @@ impl<Accounts: modules::accounts::API, Consensus: modules::consensus::API> API
                 types::ConsensusTransferContext {
                     address: to,
                     amount: amount.clone(),
+                    memo,
+                    receipt,
                 },
             ),
         )?;
 
         Ok(())
     }
-}
 
-impl<Accounts: modules::accounts::API, Consensus: modules::consensus::API>
-    Module<Accounts, Consensus>
-{
     /// Deposit in the runtime.
     fn tx_deposit<C: TxContext>(ctx: &mut C, body: types::Deposit) -> Result<(), Error> {
         let params = Self::params(ctx.runtime_state());
@@ -200,7 +268,12 @@ impl<Accounts: modules::accounts::API, Consensus: modules::consensus::API>
         Consensus::ensure_compatible_tx_signer(ctx)?;
 
         let address = signer.address_spec.address();
-        Self::deposit(ctx, address, body.amount)
+        let receipt = if body.receipt {
+            Some(signer.nonce)
+        } else {
+            None
+        };
+        Self::deposit_with_receipt(ctx, address, body.amount, body.memo, receipt)
     }
 
     /// Withdraw from the runtime.
@@ -213,7 +286,35 @@ impl<Accounts: modules::accounts::API, Consensus: modules::consensus::API>
         Consensus::ensure_compatible_tx_signer(ctx)?;
 
         let address = signer.address_spec.address();
-        Self::withdraw(ctx, address, body.amount)
+        let receipt = if body.receipt {
+            Some(signer.nonce)
+        } else {
+            None
+        };
+        Self::withdraw_with_receipt(ctx, address, body.amount, body.memo, receipt)
+    }
+
+    /// Take a receipt of the caller, removing it.
+    fn tx_take_receipt<C: TxContext>(
+        ctx: &mut C,
+        body: types::TakeReceipt,
+    ) -> Result<types::Receipt, Error> {
+        let params = Self::params(ctx.runtime_state());
+        Core::use_tx_gas(ctx, params.gas_costs.tx_take_receipt)?;
+
+        let address = ctx.tx_caller_address();
+        Self::take_receipt(ctx, address, body.id)
+    }
+
+    fn query_parameters<C: Context>(ctx: &mut C, _args: ()) -> Result<Parameters, Error> {
+        Ok(Self::params(ctx.runtime_state()))
+    }
+
+    fn query_denomination<C: Context>(
+        ctx: &mut C,
+        _args: (),
+    ) -> Result<token::Denomination, Error> {
+        Ok(Consensus::consensus_denomination(ctx)?)
     }
 
     fn query_balance<C: Context>(
@@ -237,11 +338,72 @@ impl<Accounts: modules::accounts::API, Consensus: modules::consensus::API>
         Consensus::account(ctx, args.address).map_err(|_| Error::InvalidArgument)
     }
 
+    fn query_receipt<C: Context>(
+        ctx: &mut C,
+        args: types::ReceiptQuery,
+    ) -> Result<types::Receipt, Error> {
+        let store = storage::PrefixStore::new(ctx.runtime_state(), &MODULE_NAME);
+        let receipts = storage::TypedStore::new(storage::PrefixStore::new(store, &state::RECEIPTS));
+        receipts
+            .get(Self::receipt_key(args.address, args.id))
+            .ok_or(Error::ReceiptNotFound)
+    }
+
+    fn take_receipt<C: Context>(
+        ctx: &mut C,
+        address: Address,
+        id: u64,
+    ) -> Result<types::Receipt, Error> {
+        let mut store = storage::PrefixStore::new(ctx.runtime_state(), &MODULE_NAME);
+        let mut receipts =
+            storage::TypedStore::new(storage::PrefixStore::new(&mut store, &state::RECEIPTS));
+        let key = Self::receipt_key(address, id);
+        let receipt = receipts.get(&key).ok_or(Error::ReceiptNotFound)?;
+        receipts.remove(&key);
+        Ok(receipt)
+    }
+
+    fn receipt_key(address: Address, id: u64) -> Vec<u8> {
+        [address.as_ref(), &id.to_be_bytes()].concat()
+    }
+
+    fn store_receipt<C: Context>(
+        ctx: &mut C,
+        address: Address,
+        id: u64,
+        amount: &token::BaseUnits,
+        me: &MessageEvent,
+    ) {
+        let mut store = storage::PrefixStore::new(ctx.runtime_state(), &MODULE_NAME);
+        let mut receipts =
+            storage::TypedStore::new(storage::PrefixStore::new(&mut store, &state::RECEIPTS));
+        receipts.insert(
+            Self::receipt_key(address, id),
+            types::Receipt {
+                amount: amount.clone(),
+                module: me.module.clone(),
+                code: me.code,
+            },
+        );
+    }
+
     fn message_result_transfer<C: Context>(
         ctx: &mut C,
         me: MessageEvent,
         context: types::ConsensusTransferContext,
     ) {
+        if let Some(id) = context.receipt {
+            Self::store_receipt(ctx, context.address, id, &context.amount, &me);
+        }
+
+        ctx.emit_event(Event::Withdraw {
+            address: context.address,
+            amount: context.amount.clone(),
+            memo: context.memo,
+            module: me.module.clone(),
+            code: me.code,
+        });
+
         if !me.is_success() {
             // Transfer out failed.
             return;
@@ -256,6 +418,18 @@ impl<Accounts: modules::accounts::API, Consensus: modules::consensus::API>
         me: MessageEvent,
         context: types::ConsensusWithdrawContext,
     ) {
+        if let Some(id) = context.receipt {
+            Self::store_receipt(ctx, context.address, id, &context.amount, &me);
+        }
+
+        ctx.emit_event(Event::Deposit {
+            address: context.address,
+            amount: context.amount.clone(),
+            memo: context.memo,
+            module: me.module.clone(),
+            code: me.code,
+        });
+
         if !me.is_success() {
             // Transfer out failed.
             return;
@@ -316,6 +490,7 @@ impl<Accounts: modules::accounts::API, Consensus: modules::consensus::API> modul
         match method {
             "consensus.Deposit" => module::dispatch_call(ctx, body, Self::tx_deposit),
             "consensus.Withdraw" => module::dispatch_call(ctx, body, Self::tx_withdraw),
+            "consensus.TakeReceipt" => module::dispatch_call(ctx, body, Self::tx_take_receipt),
             _ => module::DispatchResult::Unhandled(body),
         }
     }
@@ -326,8 +501,11 @@ impl<Accounts: modules::accounts::API, Consensus: modules::consensus::API> modul
         args: cbor::Value,
     ) -> module::DispatchResult<cbor::Value, Result<cbor::Value, error::RuntimeError>> {
         match method {
+            "consensus.Parameters" => module::dispatch_query(ctx, args, Self::query_parameters),
+            "consensus.Denomination" => module::dispatch_query(ctx, args, Self::query_denomination),
             "consensus.Balance" => module::dispatch_query(ctx, args, Self::query_balance),
             "consensus.Account" => module::dispatch_query(ctx, args, Self::query_consensus_account),
+            "consensus.Receipt" => module::dispatch_query(ctx, args, Self::query_receipt),
             _ => module::DispatchResult::Unhandled(args),
         }
     }
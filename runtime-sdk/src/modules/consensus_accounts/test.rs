@@ -12,6 +12,7 @@ use oasis_core_runtime::{
 
 use crate::{
     context::BatchContext,
+    event::Event as _,
     module::{MethodHandler, MigrationHandler},
     modules::{
         accounts::{Genesis as AccountsGenesis, Module as Accounts, API},
@@ -60,6 +61,8 @@ fn test_api_deposit_invalid_denomination() {
             method: "consensus.Deposit".to_owned(),
             body: cbor::to_value(Deposit {
                 amount: BaseUnits::new(1_000, Denomination::NATIVE),
+                memo: Vec::new(),
+                receipt: false,
             }),
         },
         auth_info: transaction::AuthInfo {
@@ -102,6 +105,8 @@ fn test_api_deposit() {
             method: "consensus.Deposit".to_owned(),
             body: cbor::to_value(Deposit {
                 amount: BaseUnits::new(1_000, Denomination::from_str("TEST").unwrap()),
+                memo: Vec::new(),
+                receipt: false,
             }),
         },
         auth_info: transaction::AuthInfo {
@@ -168,6 +173,8 @@ fn test_api_withdraw_invalid_denomination() {
             method: "consensus.Withdraw".to_owned(),
             body: cbor::to_value(Withdraw {
                 amount: BaseUnits::new(1_000, Denomination::NATIVE),
+                memo: Vec::new(),
+                receipt: false,
             }),
         },
         auth_info: transaction::AuthInfo {
@@ -210,6 +217,8 @@ fn test_api_withdraw_insufficient_balance() {
             method: "consensus.Withdraw".to_owned(),
             body: cbor::to_value(Withdraw {
                 amount: BaseUnits::new(1_000, Denomination::from_str("TEST").unwrap()),
+                memo: Vec::new(),
+                receipt: false,
             }),
         },
         auth_info: transaction::AuthInfo {
@@ -274,6 +283,8 @@ fn test_api_withdraw() {
             method: "consensus.Withdraw".to_owned(),
             body: cbor::to_value(Withdraw {
                 amount: BaseUnits::new(1_000_000, denom.clone()),
+                memo: Vec::new(),
+                receipt: false,
             }),
         },
         auth_info: transaction::AuthInfo {
@@ -360,12 +371,30 @@ fn test_consensus_transfer_handler() {
     let h_ctx = types::ConsensusTransferContext {
         address: keys::alice::address(),
         amount: BaseUnits::new(999_999, denom.clone()),
+        memo: b"payout".to_vec(),
+        receipt: None,
     };
     Module::<Accounts, Consensus>::message_result_transfer(&mut ctx, me, h_ctx);
 
     // Ensure runtime balance is updated.
     let bals = Accounts::get_balances(ctx.runtime_state(), keys::alice::address()).unwrap();
-    assert_eq!(bals.balances[&denom], 1, "alice balance transferred out")
+    assert_eq!(bals.balances[&denom], 1, "alice balance transferred out");
+
+    // Ensure the withdrawal event includes the memo.
+    let (tags, _) = ctx.commit();
+    let expected = Event::Withdraw {
+        address: keys::alice::address(),
+        amount: BaseUnits::new(999_999, denom),
+        memo: b"payout".to_vec(),
+        module: Default::default(),
+        code: 0,
+    }
+    .into_tag();
+    assert!(
+        tags.iter()
+            .any(|tag| tag.key == expected.key && tag.value == expected.value),
+        "withdraw event should be emitted"
+    );
 }
 
 #[test]
@@ -406,6 +435,8 @@ fn test_consensus_withdraw_handler() {
     let h_ctx = types::ConsensusWithdrawContext {
         address: keys::alice::address(),
         amount: BaseUnits::new(1, denom.clone()),
+        memo: b"top-up".to_vec(),
+        receipt: None,
     };
     Module::<Accounts, Consensus>::message_result_withdraw(&mut ctx, me, h_ctx);
 
@@ -414,7 +445,149 @@ fn test_consensus_withdraw_handler() {
     assert_eq!(
         bals.balances[&denom], 1_000_001,
         "alice balance deposited in"
+    );
+
+    // Ensure the deposit event includes the memo.
+    let (tags, _) = ctx.commit();
+    let expected = Event::Deposit {
+        address: keys::alice::address(),
+        amount: BaseUnits::new(1, denom),
+        memo: b"top-up".to_vec(),
+        module: Default::default(),
+        code: 0,
+    }
+    .into_tag();
+    assert!(
+        tags.iter()
+            .any(|tag| tag.key == expected.key && tag.value == expected.value),
+        "deposit event should be emitted"
+    );
+}
+
+#[test]
+fn test_consensus_withdraw_handler_receipt() {
+    let denom: Denomination = Denomination::from_str("TEST").unwrap();
+    let mut mock = mock::Mock::default();
+    let mut ctx = mock.create_ctx();
+    let mut meta = Metadata {
+        ..Default::default()
+    };
+
+    Accounts::init_or_migrate(&mut ctx, &mut meta, Default::default());
+    Module::<Accounts, Consensus>::init_or_migrate(&mut ctx, &mut meta, Default::default());
+
+    // Simulate successful event.
+    let me = Default::default();
+    let h_ctx = types::ConsensusWithdrawContext {
+        address: keys::alice::address(),
+        amount: BaseUnits::new(1, denom.clone()),
+        receipt: Some(42),
+        ..Default::default()
+    };
+    Module::<Accounts, Consensus>::message_result_withdraw(&mut ctx, me, h_ctx);
+
+    // Ensure the receipt has been stored.
+    let receipt = Module::<Accounts, Consensus>::query_receipt(
+        &mut ctx,
+        types::ReceiptQuery {
+            address: keys::alice::address(),
+            id: 42,
+        },
     )
+    .expect("receipt should be stored");
+    assert_eq!(
+        receipt,
+        types::Receipt {
+            amount: BaseUnits::new(1, denom),
+            ..Default::default()
+        }
+    );
+
+    // Unknown receipts should not be found.
+    assert!(Module::<Accounts, Consensus>::query_receipt(
+        &mut ctx,
+        types::ReceiptQuery {
+            address: keys::bob::address(),
+            id: 42,
+        },
+    )
+    .is_err());
+}
+
+#[test]
+fn test_tx_take_receipt() {
+    let denom: Denomination = Denomination::from_str("TEST").unwrap();
+    let mut mock = mock::Mock::default();
+    let mut ctx = mock.create_ctx();
+    let mut meta = Metadata {
+        ..Default::default()
+    };
+
+    Accounts::init_or_migrate(&mut ctx, &mut meta, Default::default());
+    Module::<Accounts, Consensus>::init_or_migrate(&mut ctx, &mut meta, Default::default());
+
+    // Simulate a successful deposit requesting a receipt.
+    let me = Default::default();
+    let h_ctx = types::ConsensusWithdrawContext {
+        address: keys::alice::address(),
+        amount: BaseUnits::new(1, denom.clone()),
+        receipt: Some(42),
+        ..Default::default()
+    };
+    Module::<Accounts, Consensus>::message_result_withdraw(&mut ctx, me, h_ctx);
+
+    let take_receipt_tx = |sigspec| transaction::Transaction {
+        version: 1,
+        call: transaction::Call {
+            format: transaction::CallFormat::Plain,
+            method: "consensus.TakeReceipt".to_owned(),
+            body: cbor::to_value(types::TakeReceipt { id: 42 }),
+        },
+        auth_info: transaction::AuthInfo {
+            signer_info: vec![transaction::SignerInfo::new_sigspec(sigspec, 0)],
+            fee: transaction::Fee {
+                amount: Default::default(),
+                gas: 1000,
+                consensus_messages: 0,
+            },
+        },
+    };
+
+    // Receipts of other accounts cannot be taken.
+    let tx = take_receipt_tx(keys::bob::sigspec());
+    ctx.with_tx(0, tx, |mut tx_ctx, call| {
+        let result = Module::<Accounts, Consensus>::tx_take_receipt(
+            &mut tx_ctx,
+            cbor::from_value(call.body).unwrap(),
+        );
+        assert!(matches!(result, Err(Error::ReceiptNotFound)));
+    });
+
+    let tx = take_receipt_tx(keys::alice::sigspec());
+    ctx.with_tx(0, tx, |mut tx_ctx, call| {
+        let body: types::TakeReceipt = cbor::from_value(call.body).unwrap();
+        let receipt = Module::<Accounts, Consensus>::tx_take_receipt(&mut tx_ctx, body.clone())
+            .expect("receipt should be taken");
+        assert_eq!(
+            receipt,
+            types::Receipt {
+                amount: BaseUnits::new(1, denom),
+                ..Default::default()
+            }
+        );
+
+        // Taken receipts are removed.
+        let result = Module::<Accounts, Consensus>::tx_take_receipt(&mut tx_ctx, body);
+        assert!(matches!(result, Err(Error::ReceiptNotFound)));
+        assert!(Module::<Accounts, Consensus>::query_receipt(
+            &mut tx_ctx,
+            types::ReceiptQuery {
+                address: keys::alice::address(),
+                id: 42,
+            },
+        )
+        .is_err());
+    });
 }
 
 #[test]
@@ -442,6 +615,8 @@ fn test_prefetch() {
             method: "consensus.Withdraw".to_owned(),
             body: cbor::to_value(Withdraw {
                 amount: BaseUnits::new(1_000, Denomination::NATIVE),
+                memo: Vec::new(),
+                receipt: false,
             }),
         },
         auth_info: auth_info.clone(),
@@ -470,6 +645,8 @@ fn test_prefetch() {
             method: "consensus.Deposit".to_owned(),
             body: cbor::to_value(Deposit {
                 amount: BaseUnits::new(1_000, Denomination::NATIVE),
+                memo: Vec::new(),
+                receipt: false,
             }),
         },
         auth_info: auth_info.clone(),
@@ -5,12 +5,42 @@ use crate::types::{address::Address, token};
 #[derive(Clone, Debug, cbor::Encode, cbor::Decode)]
 pub struct Deposit {
     pub amount: token::BaseUnits,
+
+    /// Optional opaque memo attached to the deposit.
+    #[cbor(optional)]
+    #[cbor(default)]
+    pub memo: Vec<u8>,
+
+    /// Whether a receipt should be stored once the deposit is processed. The receipt is
+    /// identified by the signer address and the transaction nonce.
+    #[cbor(optional)]
+    #[cbor(default)]
+    pub receipt: bool,
 }
 
 /// Withdraw from runtime call.
 #[derive(Clone, Debug, cbor::Encode, cbor::Decode)]
 pub struct Withdraw {
     pub amount: token::BaseUnits,
+
+    /// Optional opaque memo attached to the withdrawal.
+    #[cbor(optional)]
+    #[cbor(default)]
+    pub memo: Vec<u8>,
+
+    /// Whether a receipt should be stored once the withdrawal is processed. The receipt is
+    /// identified by the signer address and the transaction nonce.
+    #[cbor(optional)]
+    #[cbor(default)]
+    pub receipt: bool,
+}
+
+/// Take receipt call.
+#[derive(Clone, Debug, cbor::Encode, cbor::Decode)]
+pub struct TakeReceipt {
+    /// Identifier of the receipt of the caller, i.e. the nonce of the transaction that requested
+    /// the receipt.
+    pub id: u64,
 }
 
 /// Balance query.
@@ -30,11 +60,41 @@ pub struct AccountBalance {
     pub balance: u128,
 }
 
+/// Receipt query.
+#[derive(Clone, Debug, cbor::Encode, cbor::Decode)]
+pub struct ReceiptQuery {
+    pub address: Address,
+    pub id: u64,
+}
+
+/// Receipt of a processed deposit or withdrawal.
+#[derive(Clone, Debug, Default, PartialEq, cbor::Encode, cbor::Decode)]
+pub struct Receipt {
+    pub amount: token::BaseUnits,
+
+    /// Module that emitted the consensus layer error, if any.
+    #[cbor(optional)]
+    #[cbor(default)]
+    pub module: String,
+    /// Consensus layer error code, zero in case of success.
+    #[cbor(optional)]
+    #[cbor(default)]
+    pub code: u32,
+}
+
 /// Context for consensus transfer message handler.
 #[derive(Clone, Debug, cbor::Encode, cbor::Decode, Default)]
 pub struct ConsensusTransferContext {
     pub address: Address,
     pub amount: token::BaseUnits,
+
+    #[cbor(optional)]
+    #[cbor(default)]
+    pub memo: Vec<u8>,
+
+    #[cbor(optional)]
+    #[cbor(default)]
+    pub receipt: Option<u64>,
 }
 
 /// Context for consensus withdraw message handler.
@@ -42,4 +102,12 @@ pub struct ConsensusTransferContext {
 pub struct ConsensusWithdrawContext {
     pub address: Address,
     pub amount: token::BaseUnits,
+
+    #[cbor(optional)]
+    #[cbor(default)]
+    pub memo: Vec<u8>,
+
+    #[cbor(optional)]
+    #[cbor(default)]
+    pub receipt: Option<u64>,
 }
@@ -379,6 +379,11 @@ impl Module {
 
         Ok(params.min_gas_price)
     }
+
+    /// Query the core module parameters.
+    fn query_parameters<C: Context>(ctx: &mut C, _args: ()) -> Result<Parameters, Error> {
+        Ok(Self::params(ctx.runtime_state()))
+    }
 }
 
 impl module::Module for Module {
@@ -515,6 +520,7 @@ impl module::MethodHandler for Module {
                 module::dispatch_query(ctx, args, Self::query_calldata_public_key)
             }
             "core.MinGasPrice" => module::dispatch_query(ctx, args, Self::query_min_gas_price),
+            "core.Parameters" => module::dispatch_query(ctx, args, Self::query_parameters),
             _ => module::DispatchResult::Unhandled(args),
         }
     }
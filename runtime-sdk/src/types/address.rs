@@ -302,6 +302,42 @@ mod test {
         );
     }
 
+    #[test]
+    fn test_address_vectors() {
+        let vectors: serde_json::Value =
+            serde_json::from_str(include_str!("../../../tests/vectors/v1/addresses.json")).unwrap();
+        for v in vectors.as_array().unwrap() {
+            let addr = if let Some(spec) = v.get("sigspec") {
+                let (kind, pk) = spec.as_object().unwrap().iter().next().unwrap();
+                let pk = base64::decode(pk.as_str().unwrap()).unwrap();
+                let spec = match kind.as_str() {
+                    "ed25519" => {
+                        SignatureAddressSpec::Ed25519(ed25519::PublicKey::from_bytes(&pk).unwrap())
+                    }
+                    "secp256k1eth" => SignatureAddressSpec::Secp256k1Eth(
+                        secp256k1::PublicKey::from_bytes(&pk).unwrap(),
+                    ),
+                    "sr25519" => {
+                        SignatureAddressSpec::Sr25519(sr25519::PublicKey::from_bytes(&pk).unwrap())
+                    }
+                    kind => panic!("unsupported signature address specification: {}", kind),
+                };
+                Address::from_sigspec(&spec)
+            } else if let Some(config) = v.get("multisig") {
+                let config = hex::decode(config.as_str().unwrap()).unwrap();
+                Address::from_multisig(cbor::from_slice(&config).unwrap())
+            } else {
+                Address::from_module(v["module"].as_str().unwrap(), v["kind"].as_str().unwrap())
+            };
+
+            assert_eq!(addr.to_bech32(), v["address"].as_str().unwrap());
+            assert_eq!(
+                hex::encode(addr.into_bytes()),
+                v["address_raw"].as_str().unwrap()
+            );
+        }
+    }
+
     #[test]
     fn test_address_try_from_bytes() {
         let bytes_fixture = vec![42u8; ADDRESS_SIZE + 1];
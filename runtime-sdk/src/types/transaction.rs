@@ -286,6 +286,65 @@ mod test {
 
     use super::*;
 
+    #[test]
+    fn test_transaction_vectors() {
+        let vectors: serde_json::Value =
+            serde_json::from_str(include_str!("../../../tests/vectors/v1/transactions.json"))
+                .unwrap();
+        for v in vectors.as_array().unwrap() {
+            let description = v["description"].as_str().unwrap();
+            let raw = hex::decode(v["signed_tx"].as_str().unwrap()).unwrap();
+
+            let utx: UnverifiedTransaction = cbor::from_slice(&raw).unwrap();
+            assert_eq!(
+                cbor::to_vec(utx.clone()),
+                raw,
+                "{}: signed transaction encoding should be canonical",
+                description
+            );
+            assert_eq!(
+                hex::encode(&utx.0),
+                v["tx"].as_str().unwrap(),
+                "{}: signed transaction should contain the transaction",
+                description
+            );
+
+            let tx: Transaction = cbor::from_slice(&utx.0).unwrap();
+            assert_eq!(
+                cbor::to_vec(tx.clone()),
+                utx.0,
+                "{}: transaction encoding should be canonical",
+                description
+            );
+            tx.validate_basic().unwrap();
+            assert_eq!(
+                tx.call.method,
+                v["method"].as_str().unwrap(),
+                "{}",
+                description
+            );
+            assert_eq!(
+                tx.auth_info.signer_info[0].nonce,
+                v["nonce"].as_u64().unwrap(),
+                "{}",
+                description
+            );
+
+            // Verify the signatures using the signature context of the vector, as the global
+            // chain context cannot be changed by tests.
+            let ctx = v["signature_context"].as_str().unwrap().as_bytes();
+            let mut public_keys = vec![];
+            let mut signatures = vec![];
+            for (si, auth_proof) in tx.auth_info.signer_info.iter().zip(utx.1.iter()) {
+                let (mut batch_pks, mut batch_sigs) = si.address_spec.batch(auth_proof).unwrap();
+                public_keys.append(&mut batch_pks);
+                signatures.append(&mut batch_sigs);
+            }
+            PublicKey::verify_batch_multisig(ctx, &utx.0, &public_keys, &signatures)
+                .unwrap_or_else(|err| panic!("{}: signatures should verify: {}", description, err));
+        }
+    }
+
     #[test]
     fn test_fee_gas_price() {
         let fee = Fee {
@@ -37,7 +37,10 @@ func (cfg *Config) RunBenchmark(ctx context.Context, benchmark Benchmark) error
 
 	logger.Info("starting benchmark")
 
-	rtc := client.New(cfg.Conn, cfg.RuntimeID)
+	rtc := &instrumentedClient{
+		RuntimeClient: client.New(cfg.Conn, cfg.RuntimeID),
+		benchmark:     benchmark.Name(),
+	}
 
 	states := make([]*State, 0, cfg.Concurrency)
 	defer func() {
@@ -119,7 +122,9 @@ func (cfg *Config) RunBenchmark(ctx context.Context, benchmark Benchmark) error
 				default:
 				}
 
+				iterStart := time.Now()
 				iters, err := benchmark.Scenario(ctx, state)
+				scenarioDuration.WithLabelValues(benchmark.Name()).Observe(time.Since(iterStart).Seconds())
 				if err != nil {
 					// The cancelation can also interrupt a scenario in
 					// progress.
@@ -131,6 +136,7 @@ func (cfg *Config) RunBenchmark(ctx context.Context, benchmark Benchmark) error
 					logger.Error("iteration failed",
 						"err", err,
 					)
+					errorCount.WithLabelValues(benchmark.Name(), "scenario").Inc()
 					errCh <- err
 					return
 				}
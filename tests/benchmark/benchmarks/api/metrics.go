@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const metricsNamespace = "oasis_sdk_benchmark"
+
+var (
+	scenarioDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "scenario_duration_seconds",
+			Help:      "Duration of benchmark scenario iterations.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 16),
+		},
+		[]string{"benchmark"},
+	)
+	txSubmitDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "tx_submit_duration_seconds",
+			Help:      "Latency of runtime transaction submissions.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 16),
+		},
+		[]string{"benchmark", "method"},
+	)
+	errorCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "errors_total",
+			Help:      "Number of errors by kind (scenario or tx_submit).",
+		},
+		[]string{"benchmark", "kind"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(scenarioDuration, txSubmitDuration, errorCount)
+}
+
+// instrumentedClient is a runtime client recording the latency and errors of transaction
+// submissions.
+type instrumentedClient struct {
+	client.RuntimeClient
+
+	benchmark string
+}
+
+func (ic *instrumentedClient) observe(method string, start time.Time, err error) {
+	txSubmitDuration.WithLabelValues(ic.benchmark, method).Observe(time.Since(start).Seconds())
+	if err != nil && err != context.Canceled {
+		errorCount.WithLabelValues(ic.benchmark, "tx_submit").Inc()
+	}
+}
+
+func (ic *instrumentedClient) SubmitTxRaw(ctx context.Context, tx *types.UnverifiedTransaction) (result *types.CallResult, err error) {
+	defer func(start time.Time) { ic.observe("SubmitTxRaw", start, err) }(time.Now())
+	return ic.RuntimeClient.SubmitTxRaw(ctx, tx)
+}
+
+func (ic *instrumentedClient) SubmitTxRawMeta(ctx context.Context, tx *types.UnverifiedTransaction) (meta *client.SubmitTxRawMeta, err error) {
+	defer func(start time.Time) { ic.observe("SubmitTxRawMeta", start, err) }(time.Now())
+	return ic.RuntimeClient.SubmitTxRawMeta(ctx, tx)
+}
+
+func (ic *instrumentedClient) SubmitTx(ctx context.Context, tx *types.UnverifiedTransaction) (result cbor.RawMessage, err error) {
+	defer func(start time.Time) { ic.observe("SubmitTx", start, err) }(time.Now())
+	return ic.RuntimeClient.SubmitTx(ctx, tx)
+}
+
+func (ic *instrumentedClient) SubmitTxMeta(ctx context.Context, tx *types.UnverifiedTransaction) (meta *client.SubmitTxMeta, err error) {
+	defer func(start time.Time) { ic.observe("SubmitTxMeta", start, err) }(time.Now())
+	return ic.RuntimeClient.SubmitTxMeta(ctx, tx)
+}
+
+func (ic *instrumentedClient) SubmitTxNoWait(ctx context.Context, tx *types.UnverifiedTransaction) (err error) {
+	defer func(start time.Time) { ic.observe("SubmitTxNoWait", start, err) }(time.Now())
+	return ic.RuntimeClient.SubmitTxNoWait(ctx, tx)
+}
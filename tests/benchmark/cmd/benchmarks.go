@@ -3,12 +3,15 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/prometheus/client_golang/prometheus/push"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -30,6 +33,7 @@ const (
 	cfgLogLevel = "log.level"
 	cfgLogFile  = "log.file"
 
+	cfgPrometheusListenAddr        = "prometheus.listen.addr"
 	cfgPrometheusPushAddr          = "prometheus.push.addr"
 	cfgPrometheusPushJobName       = "prometheus.push.job_name"
 	cfgPrometheusPushInstanceLabel = "prometheus.push.instance_label"
@@ -42,6 +46,7 @@ var (
 	flagBenchmarksDuration    time.Duration
 	flagBenchmarksRate        uint
 
+	flagPrometheusListenAddr        string
 	flagPrometheusPushAddr          string
 	flagPrometheusPushJobName       string
 	flagPrometheusPushInstanceLabel string
@@ -86,6 +91,13 @@ func benchmarkMain(cmd *cobra.Command, args []string) {
 	}
 	cfg.Conn = conn
 
+	if err = serveMetrics(cmd, logger); err != nil {
+		logger.Error("failed to serve metrics",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+
 	sigCh := make(chan os.Signal)
 	signal.Notify(sigCh, os.Interrupt)
 	ctx, cancelFn := context.WithCancel(context.Background())
@@ -116,6 +128,35 @@ func benchmarkMain(cmd *cobra.Command, args []string) {
 	}
 }
 
+// serveMetrics serves metrics in the OpenMetrics format while the benchmarks run, so that long
+// running benchmarks can be monitored live.
+func serveMetrics(cmd *cobra.Command, logger *logging.Logger) error {
+	addr, _ := cmd.Flags().GetString(cfgPrometheusListenAddr)
+	if addr == "" {
+		return nil
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("metrics: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+	}))
+	go func() {
+		if err := http.Serve(ln, mux); err != nil {
+			logger.Error("metrics server failed",
+				"err", err,
+			)
+		}
+	}()
+	logger.Info("serving metrics",
+		"addr", ln.Addr().String(),
+	)
+	return nil
+}
+
 func pushMetrics(cmd *cobra.Command) error {
 	addr, _ := cmd.Flags().GetString(cfgPrometheusPushAddr)
 	if addr == "" {
@@ -194,6 +235,7 @@ func benchmarkInit(cmd *cobra.Command) {
 	cmd.Flags().UintVar(&flagBenchmarksConcurrency, cfgBenchmarksConcurrency, 1, "Benchmark concurrency")
 	cmd.Flags().DurationVar(&flagBenchmarksDuration, cfgBenchmarksDuration, 30*time.Second, "Benchmark duration")
 	cmd.Flags().UintVar(&flagBenchmarksRate, cfgBenchmarksRate, 1, "Benchmark maximum per second rate per concurrent connection")
+	cmd.Flags().StringVar(&flagPrometheusListenAddr, cfgPrometheusListenAddr, "", "Prometheus metrics listen address (serves /metrics while running)")
 	cmd.Flags().StringVar(&flagPrometheusPushAddr, cfgPrometheusPushAddr, "", "Prometheus push gateway address")
 	cmd.Flags().StringVar(&flagPrometheusPushJobName, cfgPrometheusPushJobName, "", "Prometheus push `job` name")
 	cmd.Flags().StringVar(&flagPrometheusPushInstanceLabel, cfgPrometheusPushInstanceLabel, "", "Prometheus push `instance` label")
@@ -206,6 +248,7 @@ func benchmarkInit(cmd *cobra.Command) {
 		cfgBenchmarksRate,
 		cfgLogLevel,
 		cfgLogFile,
+		cfgPrometheusListenAddr,
 		cfgPrometheusPushAddr,
 		cfgPrometheusPushJobName,
 		cfgPrometheusPushInstanceLabel,
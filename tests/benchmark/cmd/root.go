@@ -33,4 +33,5 @@ func Execute() {
 func init() {
 	benchmarkInit(rootCmd)
 	fixtureInit(rootCmd)
+	throughputInit(rootCmd)
 }
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	cmdGrpc "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/grpc"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+
+	"github.com/oasisprotocol/oasis-sdk/tests/benchmark/throughput"
+)
+
+const (
+	cfgThroughputMix            = "throughput.mix"
+	cfgThroughputConcurrency    = "throughput.concurrency"
+	cfgThroughputDuration       = "throughput.duration"
+	cfgThroughputRate           = "throughput.rate"
+	cfgThroughputFunder         = "throughput.funder"
+	cfgThroughputFundAmount     = "throughput.fund_amount"
+	cfgThroughputFeeAmount      = "throughput.fee_amount"
+	cfgThroughputFeeGas         = "throughput.fee_gas"
+	cfgThroughputTransferAmount = "throughput.transfer_amount"
+	cfgThroughputEVMAddress     = "throughput.evm.address"
+	cfgThroughputEVMData        = "throughput.evm.data"
+	cfgThroughputOutput         = "throughput.output"
+)
+
+var (
+	throughputFlags = flag.NewFlagSet("", flag.ContinueOnError)
+
+	throughputCmd = &cobra.Command{
+		Use:   "throughput",
+		Short: "flood a runtime with a transaction mix and report latencies and failure rates",
+		Run:   doThroughput,
+	}
+
+	throughputFunders = map[string]sdkTesting.TestKey{
+		"alice":   sdkTesting.Alice,
+		"bob":     sdkTesting.Bob,
+		"charlie": sdkTesting.Charlie,
+		"dave":    sdkTesting.Dave,
+		"erin":    sdkTesting.Erin,
+		"frank":   sdkTesting.Frank,
+		"grace":   sdkTesting.Grace,
+		"heidi":   sdkTesting.Heidi,
+	}
+)
+
+func nativeUnits(amount uint64) types.BaseUnits {
+	return types.NewBaseUnits(*quantity.NewFromUint64(amount), types.NativeDenomination)
+}
+
+func throughputConfig(cmd *cobra.Command) (*throughput.Config, error) {
+	flags := cmd.Flags()
+
+	mixStr, _ := flags.GetString(cfgThroughputMix)
+	mix, err := throughput.ParseMix(mixStr)
+	if err != nil {
+		return nil, err
+	}
+
+	funderName, _ := flags.GetString(cfgThroughputFunder)
+	funder, ok := throughputFunders[strings.ToLower(funderName)]
+	if !ok {
+		return nil, fmt.Errorf("unknown funder test key: '%s'", funderName)
+	}
+
+	cfg := &throughput.Config{
+		Mix:    mix,
+		Funder: funder,
+	}
+	concurrency, _ := flags.GetUint(cfgThroughputConcurrency)
+	cfg.Concurrency = int(concurrency)
+	cfg.Duration, _ = flags.GetDuration(cfgThroughputDuration)
+	cfg.Rate, _ = flags.GetUint(cfgThroughputRate)
+	cfg.FeeGas, _ = flags.GetUint64(cfgThroughputFeeGas)
+
+	fundAmount, _ := flags.GetUint64(cfgThroughputFundAmount)
+	cfg.FundAmount = nativeUnits(fundAmount)
+	feeAmount, _ := flags.GetUint64(cfgThroughputFeeAmount)
+	cfg.FeeAmount = nativeUnits(feeAmount)
+	transferAmount, _ := flags.GetUint64(cfgThroughputTransferAmount)
+	cfg.TransferAmount = nativeUnits(transferAmount)
+
+	evmAddress, _ := flags.GetString(cfgThroughputEVMAddress)
+	if cfg.EVMAddress, err = hex.DecodeString(strings.TrimPrefix(evmAddress, "0x")); err != nil {
+		return nil, fmt.Errorf("malformed EVM contract address: %w", err)
+	}
+	evmData, _ := flags.GetString(cfgThroughputEVMData)
+	if cfg.EVMData, err = hex.DecodeString(strings.TrimPrefix(evmData, "0x")); err != nil {
+		return nil, fmt.Errorf("malformed EVM call data: %w", err)
+	}
+	// Only the zero value is supported for now.
+	cfg.EVMValue = make([]byte, 32)
+
+	return cfg, nil
+}
+
+func doThroughput(cmd *cobra.Command, args []string) {
+	logger := logging.GetLogger("throughput")
+
+	cfg, err := throughputConfig(cmd)
+	if err != nil {
+		logger.Error("invalid configuration", "err", err)
+		os.Exit(1)
+	}
+	cfg.Logger = logger
+
+	output, _ := cmd.Flags().GetString(cfgThroughputOutput)
+	if output != "text" && output != "json" {
+		logger.Error("invalid output format", "output", output)
+		os.Exit(1)
+	}
+
+	var runtimeID common.Namespace
+	rtID, _ := cmd.Flags().GetString(cfgRuntimeID)
+	if err = runtimeID.UnmarshalHex(rtID); err != nil {
+		logger.Error("invalid runtime ID",
+			"runtime_id", rtID,
+			"err", err,
+		)
+		os.Exit(1)
+	}
+	conn, err := cmdGrpc.NewClient(cmd)
+	if err != nil {
+		logger.Error("failed to establish connection with node",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+	defer conn.Close()
+	cfg.Client = client.New(conn, runtimeID)
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	go func() {
+		<-sigCh
+		logger.Error("user requested interrupt")
+		cancelFn()
+	}()
+
+	rpt, err := throughput.Run(ctx, cfg)
+	if err != nil {
+		logger.Error("throughput benchmark failed",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+
+	switch output {
+	case "json":
+		data, _ := json.MarshalIndent(rpt, "", "  ")
+		fmt.Printf("%s\n", data)
+	default:
+		_ = rpt.WriteText(os.Stdout)
+	}
+}
+
+func throughputInit(cmd *cobra.Command) {
+	throughputFlags.String(cfgRuntimeID, "8000000000000000000000000000000000000000000000000000000000000000", "runtime ID")
+	throughputFlags.String(cfgThroughputMix, "transfer=1", "transaction mix (e.g. transfer=3,evm_call=1)")
+	throughputFlags.Uint(cfgThroughputConcurrency, 10, "number of concurrent submitters")
+	throughputFlags.Duration(cfgThroughputDuration, 30*time.Second, "duration of the run")
+	throughputFlags.Uint(cfgThroughputRate, 0, "maximum transactions per second per submitter (0 is unlimited)")
+	throughputFlags.String(cfgThroughputFunder, "alice", "test key funding the submitter accounts")
+	throughputFlags.Uint64(cfgThroughputFundAmount, 1_000_000, "native amount each submitter account is funded with")
+	throughputFlags.Uint64(cfgThroughputFeeAmount, 0, "native fee amount paid per transaction")
+	throughputFlags.Uint64(cfgThroughputFeeGas, 100_000, "gas limit per transaction")
+	throughputFlags.Uint64(cfgThroughputTransferAmount, 1, "native amount transferred by each transfer")
+	throughputFlags.String(cfgThroughputEVMAddress, "", "EVM contract address called by evm_call transactions (HEX)")
+	throughputFlags.String(cfgThroughputEVMData, "", "EVM call data of evm_call transactions (HEX)")
+	throughputFlags.String(cfgThroughputOutput, "text", "report output format (text, json)")
+
+	throughputCmd.Flags().AddFlagSet(throughputFlags)
+	throughputCmd.Flags().AddFlagSet(cmdGrpc.ClientFlags)
+
+	cmd.AddCommand(throughputCmd)
+}
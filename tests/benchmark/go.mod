@@ -21,5 +21,6 @@ require (
 	github.com/spf13/cobra v1.2.1
 	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.9.0
+	github.com/stretchr/testify v1.7.0
 	google.golang.org/grpc v1.41.0
 )
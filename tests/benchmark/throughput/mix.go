@@ -0,0 +1,110 @@
+package throughput
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// TxKind is a kind of transaction generated by the throughput benchmark.
+type TxKind string
+
+const (
+	// TxKindTransfer is an accounts.Transfer transaction.
+	TxKindTransfer TxKind = "transfer"
+	// TxKindEVMCall is an evm.Call transaction.
+	TxKindEVMCall TxKind = "evm_call"
+)
+
+func (k TxKind) validate() error {
+	switch k {
+	case TxKindTransfer, TxKindEVMCall:
+		return nil
+	default:
+		return fmt.Errorf("unknown transaction kind: '%s'", k)
+	}
+}
+
+// Mix is a weighted mix of transaction kinds.
+type Mix struct {
+	kinds   []TxKind
+	weights []uint64
+	total   uint64
+}
+
+// ParseMix parses a transaction mix in the form "kind=weight,kind=weight", e.g.
+// "transfer=3,evm_call=1". The weight may be omitted, in which case it defaults to 1.
+func ParseMix(s string) (*Mix, error) {
+	var m Mix
+	seen := make(map[TxKind]bool)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		kind, weight := TxKind(part), uint64(1)
+		if idx := strings.IndexByte(part, '='); idx >= 0 {
+			kind = TxKind(part[:idx])
+			var err error
+			if weight, err = strconv.ParseUint(part[idx+1:], 10, 64); err != nil {
+				return nil, fmt.Errorf("malformed weight for transaction kind '%s': %w", kind, err)
+			}
+		}
+		if err := kind.validate(); err != nil {
+			return nil, err
+		}
+		if seen[kind] {
+			return nil, fmt.Errorf("duplicate transaction kind: '%s'", kind)
+		}
+		seen[kind] = true
+		if weight == 0 {
+			continue
+		}
+
+		m.kinds = append(m.kinds, kind)
+		m.weights = append(m.weights, weight)
+		m.total += weight
+	}
+	if m.total == 0 {
+		return nil, fmt.Errorf("empty transaction mix")
+	}
+	return &m, nil
+}
+
+// Kinds returns the transaction kinds included in the mix.
+func (m *Mix) Kinds() []TxKind {
+	return m.kinds
+}
+
+// Has returns true iff the mix includes the given transaction kind.
+func (m *Mix) Has(kind TxKind) bool {
+	for _, k := range m.kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Pick randomly picks a transaction kind according to the mix weights.
+func (m *Mix) Pick(rng *rand.Rand) TxKind {
+	n := uint64(rng.Int63n(int64(m.total)))
+	for i, w := range m.weights {
+		if n < w {
+			return m.kinds[i]
+		}
+		n -= w
+	}
+	return m.kinds[len(m.kinds)-1]
+}
+
+// String returns a string representation of the mix.
+func (m *Mix) String() string {
+	parts := make([]string, 0, len(m.kinds))
+	for i, k := range m.kinds {
+		parts = append(parts, fmt.Sprintf("%s=%d", k, m.weights[i]))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,199 @@
+package throughput
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Outcome is the outcome of a submitted transaction.
+type Outcome uint8
+
+const (
+	// OutcomeSuccess is a transaction that was executed successfully.
+	OutcomeSuccess Outcome = iota
+	// OutcomeCallFailed is a transaction that was executed, but whose call failed.
+	OutcomeCallFailed
+	// OutcomeCheckFailed is a transaction that failed the transaction checks.
+	OutcomeCheckFailed
+	// OutcomeSubmitFailed is a transaction whose submission failed, e.g. due to a timeout.
+	OutcomeSubmitFailed
+)
+
+type kindStats struct {
+	latencies []time.Duration
+	outcomes  [OutcomeSubmitFailed + 1]uint64
+}
+
+// Stats collects per transaction kind latencies and outcomes.
+type Stats struct {
+	sync.Mutex
+
+	kinds map[TxKind]*kindStats
+}
+
+// NewStats creates a new empty statistics collector.
+func NewStats() *Stats {
+	return &Stats{kinds: make(map[TxKind]*kindStats)}
+}
+
+// Record records the outcome of a transaction of the given kind. The latency is ignored for
+// transactions that failed to submit.
+func (s *Stats) Record(kind TxKind, outcome Outcome, latency time.Duration) {
+	s.Lock()
+	defer s.Unlock()
+
+	ks := s.kinds[kind]
+	if ks == nil {
+		ks = &kindStats{}
+		s.kinds[kind] = ks
+	}
+	ks.outcomes[outcome]++
+	if outcome != OutcomeSubmitFailed {
+		ks.latencies = append(ks.latencies, latency)
+	}
+}
+
+// LatencyReport are latency statistics.
+type LatencyReport struct {
+	Min  time.Duration `json:"min"`
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P90  time.Duration `json:"p90"`
+	P99  time.Duration `json:"p99"`
+	Max  time.Duration `json:"max"`
+}
+
+// KindReport are the results for a single transaction kind.
+type KindReport struct {
+	Kind TxKind `json:"kind"`
+
+	Submitted    uint64 `json:"submitted"`
+	Succeeded    uint64 `json:"succeeded"`
+	CallFailed   uint64 `json:"call_failed"`
+	CheckFailed  uint64 `json:"check_failed"`
+	SubmitFailed uint64 `json:"submit_failed"`
+
+	// FailureRate is the fraction of submitted transactions that did not succeed.
+	FailureRate float64 `json:"failure_rate"`
+	// Throughput is the number of successful transactions per second.
+	Throughput float64 `json:"throughput"`
+
+	Latency LatencyReport `json:"latency"`
+}
+
+// Report are the results of a throughput benchmark run.
+type Report struct {
+	Duration time.Duration `json:"duration"`
+	Mix      string        `json:"mix"`
+
+	Total *KindReport   `json:"total"`
+	Kinds []*KindReport `json:"kinds"`
+}
+
+// Report summarizes the collected statistics over a run of the given duration.
+func (s *Stats) Report(duration time.Duration) *Report {
+	s.Lock()
+	defer s.Unlock()
+
+	kinds := make([]TxKind, 0, len(s.kinds))
+	for kind := range s.kinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i] < kinds[j] })
+
+	var (
+		rpt   Report
+		total kindStats
+	)
+	rpt.Duration = duration
+	for _, kind := range kinds {
+		ks := s.kinds[kind]
+		rpt.Kinds = append(rpt.Kinds, ks.report(kind, duration))
+
+		total.latencies = append(total.latencies, ks.latencies...)
+		for i, n := range ks.outcomes {
+			total.outcomes[i] += n
+		}
+	}
+	rpt.Total = total.report("total", duration)
+	return &rpt
+}
+
+func (ks *kindStats) report(kind TxKind, duration time.Duration) *KindReport {
+	kr := &KindReport{
+		Kind:         kind,
+		Succeeded:    ks.outcomes[OutcomeSuccess],
+		CallFailed:   ks.outcomes[OutcomeCallFailed],
+		CheckFailed:  ks.outcomes[OutcomeCheckFailed],
+		SubmitFailed: ks.outcomes[OutcomeSubmitFailed],
+	}
+	for _, n := range ks.outcomes {
+		kr.Submitted += n
+	}
+	if kr.Submitted > 0 {
+		kr.FailureRate = float64(kr.Submitted-kr.Succeeded) / float64(kr.Submitted)
+	}
+	if duration > 0 {
+		kr.Throughput = float64(kr.Succeeded) / duration.Seconds()
+	}
+
+	latencies := append([]time.Duration{}, ks.latencies...)
+	if len(latencies) == 0 {
+		return kr
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	var sum time.Duration
+	for _, l := range latencies {
+		sum += l
+	}
+	kr.Latency = LatencyReport{
+		Min:  latencies[0],
+		Mean: sum / time.Duration(len(latencies)),
+		P50:  percentile(latencies, 50),
+		P90:  percentile(latencies, 90),
+		P99:  percentile(latencies, 99),
+		Max:  latencies[len(latencies)-1],
+	}
+	return kr
+}
+
+// percentile returns the p-th percentile of the given sorted latencies using the nearest-rank
+// method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// WriteText writes a human-readable form of the report.
+func (r *Report) WriteText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "duration: %s, mix: %s\n", r.Duration, r.Mix); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "%-10s %10s %10s %8s %10s %10s %10s %10s %10s\n",
+		"kind", "submitted", "succeeded", "fail%", "tx/s", "p50", "p90", "p99", "max",
+	); err != nil {
+		return err
+	}
+	for _, kr := range append(append([]*KindReport{}, r.Kinds...), r.Total) {
+		if _, err := fmt.Fprintf(w, "%-10s %10d %10d %8.2f %10.2f %10s %10s %10s %10s\n",
+			kr.Kind,
+			kr.Submitted,
+			kr.Succeeded,
+			kr.FailureRate*100,
+			kr.Throughput,
+			kr.Latency.P50.Round(time.Millisecond),
+			kr.Latency.P90.Round(time.Millisecond),
+			kr.Latency.P99.Round(time.Millisecond),
+			kr.Latency.Max.Round(time.Millisecond),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+// Package throughput implements a transaction throughput benchmark that floods a runtime with a
+// configurable mix of transactions and measures latencies and failure rates.
+//
+// Unlike the benchmarks in the benchmarks package, it only uses standard runtime modules and can
+// thus be run against any runtime that includes them.
+package throughput
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/evm"
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+// Config is a throughput benchmark run configuration.
+type Config struct {
+	Logger *logging.Logger
+	Client client.RuntimeClient
+
+	// Mix is the mix of transactions to submit.
+	Mix *Mix
+	// Concurrency is the number of concurrent submitters. Each submitter uses its own account.
+	Concurrency int
+	// Duration is the duration of the run.
+	Duration time.Duration
+	// Rate is the maximum number of transactions per second per submitter. Zero means unlimited.
+	Rate uint
+
+	// Funder is the key of the account that funds the submitter accounts.
+	Funder sdkTesting.TestKey
+	// FundAmount is the amount each submitter account is funded with.
+	FundAmount types.BaseUnits
+
+	// FeeAmount is the fee amount paid for each transaction.
+	FeeAmount types.BaseUnits
+	// FeeGas is the maximum gas amount of each transaction.
+	FeeGas uint64
+
+	// TransferAmount is the amount transferred by each transfer transaction. Transfers are sent
+	// back to the funder account.
+	TransferAmount types.BaseUnits
+
+	// EVMAddress is the address of the contract called by EVM call transactions.
+	EVMAddress []byte
+	// EVMData is the call data of EVM call transactions.
+	EVMData []byte
+	// EVMValue is the value sent with EVM call transactions.
+	EVMValue []byte
+}
+
+func (cfg *Config) validate() error {
+	if cfg.Mix == nil {
+		return fmt.Errorf("throughput: no transaction mix configured")
+	}
+	if cfg.Concurrency <= 0 {
+		return fmt.Errorf("throughput: invalid concurrency: %d", cfg.Concurrency)
+	}
+	if cfg.Duration <= 0 {
+		return fmt.Errorf("throughput: invalid duration: %s", cfg.Duration)
+	}
+	if cfg.Mix.Has(TxKindEVMCall) && len(cfg.EVMAddress) != 20 {
+		return fmt.Errorf("throughput: EVM calls require a 20-byte contract address")
+	}
+	return nil
+}
+
+type submitter struct {
+	cfg   *Config
+	stats *Stats
+	key   sdkTesting.TestKey
+	rng   *rand.Rand
+	nonce uint64
+
+	logger *logging.Logger
+}
+
+func (s *submitter) buildTx(kind TxKind) *client.TransactionBuilder {
+	var tb *client.TransactionBuilder
+	switch kind {
+	case TxKindTransfer:
+		tb = accounts.NewV1(s.cfg.Client).Transfer(s.cfg.Funder.Address, s.cfg.TransferAmount)
+	case TxKindEVMCall:
+		tb = evm.NewV1(s.cfg.Client).Call(s.cfg.EVMAddress, s.cfg.EVMValue, s.cfg.EVMData)
+	}
+	return tb.
+		SetFeeAmount(s.cfg.FeeAmount).
+		SetFeeGas(s.cfg.FeeGas).
+		AppendAuthSignature(s.key.SigSpec, s.nonce)
+}
+
+func (s *submitter) submit(ctx context.Context) error {
+	kind := s.cfg.Mix.Pick(s.rng)
+	tb := s.buildTx(kind)
+	if err := tb.AppendSign(ctx, s.key.Signer); err != nil {
+		return fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	start := time.Now()
+	meta, err := tb.SubmitTxMeta(ctx, nil)
+	latency := time.Since(start)
+
+	switch {
+	case meta == nil:
+		if ctx.Err() != nil {
+			// Interrupted by the end of the run, do not count.
+			return nil
+		}
+		s.logger.Debug("failed to submit transaction", "kind", kind, "err", err)
+		s.stats.Record(kind, OutcomeSubmitFailed, latency)
+		// We don't know whether the nonce was consumed.
+		return s.syncNonce(ctx)
+	case meta.CheckTxError != nil:
+		s.logger.Debug("transaction check failed", "kind", kind, "err", meta.CheckTxError)
+		s.stats.Record(kind, OutcomeCheckFailed, latency)
+		return s.syncNonce(ctx)
+	case err != nil:
+		s.logger.Debug("transaction call failed", "kind", kind, "err", err)
+		s.stats.Record(kind, OutcomeCallFailed, latency)
+	default:
+		s.stats.Record(kind, OutcomeSuccess, latency)
+	}
+	s.nonce++
+	return nil
+}
+
+func (s *submitter) syncNonce(ctx context.Context) error {
+	nonce, err := accounts.NewV1(s.cfg.Client).Nonce(ctx, client.RoundLatest, s.key.Address)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("failed to query nonce: %w", err)
+	}
+	s.nonce = nonce
+	return nil
+}
+
+func (s *submitter) run(ctx context.Context) error {
+	var interval time.Duration
+	if s.cfg.Rate != 0 {
+		interval = time.Second / time.Duration(s.cfg.Rate)
+	}
+
+	began := time.Now()
+	for count := int64(1); ; count++ {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := s.submit(ctx); err != nil {
+			return err
+		}
+
+		if interval != 0 {
+			// Rate limit.
+			select {
+			case <-time.After(time.Until(began.Add(time.Duration(count) * interval))):
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// Run funds the submitter accounts and then submits transactions for the configured duration.
+func Run(ctx context.Context, cfg *Config) (*Report, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	logger := cfg.Logger.With("mix", cfg.Mix.String())
+
+	// EVM calls require an Ethereum address, so use Secp256k1 accounts in that case.
+	newKey := sdkTesting.NewRandomEd25519TestKey
+	if cfg.Mix.Has(TxKindEVMCall) {
+		newKey = sdkTesting.NewRandomSecp256k1TestKey
+	}
+
+	logger.Info("funding accounts", "num_accounts", cfg.Concurrency, "amount", cfg.FundAmount)
+	keys, err := sdkTesting.NewFundedAccounts(ctx, cfg.Client, &sdkTesting.FundedAccountsConfig{
+		Funder:    cfg.Funder,
+		Count:     cfg.Concurrency,
+		Amounts:   []types.BaseUnits{cfg.FundAmount},
+		FeeAmount: cfg.FeeAmount,
+		FeeGas:    cfg.FeeGas,
+		NewKey:    newKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("throughput: %w", err)
+	}
+
+	stats := NewStats()
+	runCtx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	logger.Info("submitting transactions", "concurrency", cfg.Concurrency, "duration", cfg.Duration)
+	errCh := make(chan error, len(keys))
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i, key := range keys {
+		s := &submitter{
+			cfg:    cfg,
+			stats:  stats,
+			key:    key,
+			rng:    rand.New(rand.NewSource(start.UnixNano() + int64(i))), //nolint: gosec
+			logger: logger.With("submitter", i),
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.run(runCtx); err != nil {
+				s.logger.Error("submitter failed", "err", err)
+				errCh <- err
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	select {
+	case err = <-errCh:
+		return nil, fmt.Errorf("throughput: %w", err)
+	default:
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rpt := stats.Report(elapsed)
+	rpt.Mix = cfg.Mix.String()
+	logger.Info("run finished",
+		"submitted", rpt.Total.Submitted,
+		"succeeded", rpt.Total.Succeeded,
+		"failure_rate", rpt.Total.FailureRate,
+		"throughput", rpt.Total.Throughput,
+	)
+	return rpt, nil
+}
@@ -0,0 +1,108 @@
+package throughput
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	sdkTesting "github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/fakeruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+func nativeUnits(amount uint64) types.BaseUnits {
+	return types.NewBaseUnits(*quantity.NewFromUint64(amount), types.NativeDenomination)
+}
+
+func TestParseMix(t *testing.T) {
+	require := require.New(t)
+
+	mix, err := ParseMix("transfer=3, evm_call")
+	require.NoError(err, "ParseMix")
+	require.Equal([]TxKind{TxKindTransfer, TxKindEVMCall}, mix.Kinds())
+	require.Equal("transfer=3,evm_call=1", mix.String())
+
+	rng := rand.New(rand.NewSource(0)) //nolint: gosec
+	counts := make(map[TxKind]int)
+	for i := 0; i < 4000; i++ {
+		counts[mix.Pick(rng)]++
+	}
+	require.InDelta(3000, counts[TxKindTransfer], 200)
+	require.InDelta(1000, counts[TxKindEVMCall], 200)
+
+	mix, err = ParseMix("transfer=1,evm_call=0")
+	require.NoError(err, "ParseMix with zero weight")
+	require.False(mix.Has(TxKindEVMCall))
+
+	for _, s := range []string{"", "transfer=0", "unknown=1", "transfer=x", "transfer,transfer=2"} {
+		_, err = ParseMix(s)
+		require.Error(err, "ParseMix(%q) should fail", s)
+	}
+}
+
+func TestStatsReport(t *testing.T) {
+	require := require.New(t)
+
+	stats := NewStats()
+	for i := 1; i <= 100; i++ {
+		stats.Record(TxKindTransfer, OutcomeSuccess, time.Duration(i)*time.Millisecond)
+	}
+	stats.Record(TxKindEVMCall, OutcomeCallFailed, 5*time.Millisecond)
+	stats.Record(TxKindEVMCall, OutcomeSubmitFailed, time.Hour)
+
+	rpt := stats.Report(10 * time.Second)
+	require.Len(rpt.Kinds, 2)
+
+	evmCall, transfer := rpt.Kinds[0], rpt.Kinds[1]
+	require.EqualValues(100, transfer.Succeeded)
+	require.Equal(0.0, transfer.FailureRate)
+	require.Equal(10.0, transfer.Throughput)
+	require.Equal(time.Millisecond, transfer.Latency.Min)
+	require.Equal(50*time.Millisecond, transfer.Latency.P50)
+	require.Equal(90*time.Millisecond, transfer.Latency.P90)
+	require.Equal(99*time.Millisecond, transfer.Latency.P99)
+	require.Equal(100*time.Millisecond, transfer.Latency.Max)
+
+	require.EqualValues(2, evmCall.Submitted)
+	require.Equal(1.0, evmCall.FailureRate)
+	require.Equal(5*time.Millisecond, evmCall.Latency.Max, "submit failures should not count towards latency")
+
+	require.EqualValues(102, rpt.Total.Submitted)
+	require.EqualValues(100, rpt.Total.Succeeded)
+}
+
+func TestRun(t *testing.T) {
+	require := require.New(t)
+
+	rt := fakeruntime.New(fakeruntime.Config{
+		EVMCallHandler: func(call *fakeruntime.EVMCall) ([]byte, error) {
+			return nil, nil
+		},
+	})
+	rt.SetBalance(sdkTesting.Alice.Address, nativeUnits(1_000_000))
+	mix, err := ParseMix("transfer=1,evm_call=1")
+	require.NoError(err, "ParseMix")
+
+	rpt, err := Run(context.Background(), &Config{
+		Logger:         logging.GetLogger("throughput/test"),
+		Client:         rt,
+		Mix:            mix,
+		Concurrency:    4,
+		Duration:       200 * time.Millisecond,
+		Funder:         sdkTesting.Alice,
+		FundAmount:     nativeUnits(1000),
+		TransferAmount: nativeUnits(1),
+		EVMAddress:     make([]byte, 20),
+	})
+	require.NoError(err, "Run")
+	require.Equal("transfer=1,evm_call=1", rpt.Mix)
+	require.NotZero(rpt.Total.Submitted)
+	require.Equal(rpt.Total.Submitted, rpt.Total.Succeeded, "all transactions should succeed")
+	require.Len(rpt.Kinds, 2)
+}
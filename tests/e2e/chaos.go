@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+
+	"github.com/oasisprotocol/oasis-sdk/tests/e2e/harness"
+)
+
+const (
+	// chaosDowntime is how long nodes stay down in chaos tests.
+	chaosDowntime = 10 * time.Second
+	// chaosRecoveryTimeout is how long the client may take to recover from an injected fault.
+	chaosRecoveryTimeout = 2 * time.Minute
+)
+
+// kvInsertEventually inserts the given key-value pair, resubmitting the transaction until it
+// succeeds.
+func kvInsertEventually(ctx context.Context, log *logging.Logger, rtc client.RuntimeClient, key, value []byte) error {
+	return harness.Eventually(ctx, chaosRecoveryTimeout, time.Second, func(context.Context) error {
+		err := kvInsert(rtc, testing.Alice.Signer, key, value)
+		if err != nil {
+			log.Info("insert failed, resubmitting", "key", string(key), "err", err)
+		}
+		return err
+	})
+}
+
+// kvInsertMany inserts numbered keys with the given prefix while faults are being injected and
+// checks that they can all be read back afterwards.
+func kvInsertMany(ctx context.Context, log *logging.Logger, rtc client.RuntimeClient, prefix string, n int) error {
+	for i := 0; i < n; i++ {
+		key := []byte(fmt.Sprintf("%s-%d", prefix, i))
+		if err := kvInsertEventually(ctx, log, rtc, key, key); err != nil {
+			return fmt.Errorf("failed to insert key %s: %w", key, err)
+		}
+	}
+	for i := 0; i < n; i++ {
+		key := []byte(fmt.Sprintf("%s-%d", prefix, i))
+		value, err := kvGet(rtc, key)
+		if err != nil {
+			return fmt.Errorf("failed to get key %s: %w", key, err)
+		}
+		if !bytes.Equal(value, key) {
+			return fmt.Errorf("unexpected value for key %s: %s", key, value)
+		}
+	}
+	return nil
+}
+
+// ChaosComputeRestartTest restarts a compute worker while transactions are being submitted.
+func ChaosComputeRestartTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+	ctx := context.Background()
+	chaos, err := sc.Chaos()
+	if err != nil {
+		return err
+	}
+
+	computeNodes := chaos.Nodes(harness.NodeGroupComputeWorkers)
+	return chaos.During(ctx, func(ctx context.Context) error {
+		return chaos.RestartNodes(ctx, chaosDowntime, computeNodes[0])
+	}, func(ctx context.Context) error {
+		return kvInsertMany(ctx, log, rtc, "chaos-compute", 10)
+	})
+}
+
+// ChaosClientRestartTest restarts the client node and checks that the existing connection
+// recovers.
+func ChaosClientRestartTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+	ctx := context.Background()
+	chaos, err := sc.Chaos()
+	if err != nil {
+		return err
+	}
+
+	if err = chaos.RestartNodes(ctx, chaosDowntime, chaos.Nodes(harness.NodeGroupClients)...); err != nil {
+		return err
+	}
+
+	log.Info("waiting for the client connection to recover")
+	ac := accounts.NewV1(rtc)
+	if err = harness.Eventually(ctx, chaosRecoveryTimeout, time.Second, func(ctx context.Context) error {
+		_, err := ac.Nonce(ctx, client.RoundLatest, testing.Alice.Address)
+		return err
+	}); err != nil {
+		return fmt.Errorf("client connection did not recover: %w", err)
+	}
+	return kvInsertMany(ctx, log, rtc, "chaos-client", 3)
+}
+
+// ChaosPartitionTest partitions a storage and a compute worker off the network while
+// transactions are being submitted.
+func ChaosPartitionTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+	ctx := context.Background()
+	chaos, err := sc.Chaos()
+	if err != nil {
+		return err
+	}
+
+	nodes := append(
+		chaos.Nodes(harness.NodeGroupStorageWorkers)[:1],
+		chaos.Nodes(harness.NodeGroupComputeWorkers)[1],
+	)
+	return chaos.During(ctx, func(ctx context.Context) error {
+		return chaos.Partition(ctx, chaosDowntime, nodes...)
+	}, func(ctx context.Context) error {
+		return kvInsertMany(ctx, log, rtc, "chaos-partition", 10)
+	})
+}
+
+// ChaosKeymanagerTest kills the key manager and checks that the runtime recovers once it is back.
+func ChaosKeymanagerTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+	ctx := context.Background()
+	chaos, err := sc.Chaos()
+	if err != nil {
+		return err
+	}
+	if len(chaos.Nodes(harness.NodeGroupKeymanagers)) == 0 {
+		log.Info("no key manager configured, skipping")
+		return nil
+	}
+
+	if err = chaos.KillKeymanager(ctx, chaosDowntime); err != nil {
+		return err
+	}
+
+	log.Info("waiting for key manager requests to succeed again")
+	if err = harness.Eventually(ctx, chaosRecoveryTimeout, time.Second, func(context.Context) error {
+		return kvGetCreateKey(rtc, testing.Alice.Signer, []byte("chaos-keymanager"))
+	}); err != nil {
+		return fmt.Errorf("key manager requests did not recover: %w", err)
+	}
+	return kvInsertMany(ctx, log, rtc, "chaos-keymanager", 3)
+}
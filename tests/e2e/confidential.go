@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	mrae "github.com/oasisprotocol/oasis-core/go/common/crypto/mrae/api"
+	memorySigner "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/memory"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/core"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+
+	"github.com/oasisprotocol/oasis-sdk/tests/e2e/harness"
+)
+
+// errCoreInvalidCallFormat is the core module error returned for calls that cannot be opened.
+const errCoreInvalidCallFormat = 18
+
+// staleKeyClient is a runtime client that returns a random call data public key the first time it
+// is queried, simulating a key that was rotated after it had been fetched.
+type staleKeyClient struct {
+	client.RuntimeClient
+
+	served bool
+}
+
+// Implements client.RuntimeClient.
+func (c *staleKeyClient) Query(ctx context.Context, round uint64, method string, args, rsp interface{}) error {
+	if method != "core.CallDataPublicKey" || c.served {
+		return c.RuntimeClient.Query(ctx, round, method, args, rsp)
+	}
+	c.served = true
+
+	pk, _, err := mrae.GenerateKeyPair(rand.Reader)
+	if err != nil {
+		return err
+	}
+	stale := core.CallDataPublicKeyQueryResponse{
+		PublicKey: types.SignedPublicKey{PublicKey: *pk},
+	}
+	return cbor.Unmarshal(cbor.Marshal(stale), rsp)
+}
+
+// requireKeymanager makes sure that the test network runs a key manager.
+func requireKeymanager(sc *harness.RuntimeScenario) error {
+	if len(sc.Net.Keymanagers()) == 0 {
+		return fmt.Errorf("confidential tests require a key manager (see keymanager.binary)")
+	}
+	return nil
+}
+
+// kvInsertConfidential inserts the given key-value pair using an encrypted call. The transaction
+// can be tampered with before it is signed.
+func kvInsertConfidential(
+	ctx context.Context,
+	rtc client.RuntimeClient,
+	pkCache *client.CallDataPublicKeyCache,
+	signer signature.Signer,
+	key, value []byte,
+	tamper func(*types.Transaction) error,
+) (*client.TransactionMeta, error) {
+	nonce, err := accounts.NewV1(rtc).Nonce(ctx, client.RoundLatest, types.NewAddress(sigspecForSigner(signer)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query nonce: %w", err)
+	}
+
+	tb := client.NewTransactionBuilder(rtc, "keyvalue.Insert", kvKeyValue{
+		Key:   key,
+		Value: value,
+	})
+	tb.SetFeeGas(10 * defaultGasAmount)
+	if pkCache != nil {
+		tb.SetCallDataPublicKeyCache(pkCache)
+	}
+	if err = tb.SetCallFormat(ctx, types.CallFormatEncryptedX25519DeoxysII); err != nil {
+		return nil, fmt.Errorf("failed to set call format: %w", err)
+	}
+	if tamper != nil {
+		if err = tamper(tb.GetTransaction()); err != nil {
+			return nil, err
+		}
+	}
+	tb.AppendAuthSignature(sigspecForSigner(signer), nonce)
+	if err = tb.AppendSign(ctx, signer); err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	meta, err := tb.SubmitTxMeta(ctx, nil)
+	if err != nil {
+		return meta, err
+	}
+	if meta.CheckTxError != nil {
+		return meta, fmt.Errorf("transaction check failed: %s", meta.CheckTxError.Message)
+	}
+	return meta, nil
+}
+
+// expectInvalidCallFormat checks that the given error is an invalid call format failure.
+func expectInvalidCallFormat(err error) error {
+	var failed *types.FailedCallResult
+	switch {
+	case err == nil:
+		return fmt.Errorf("expected the call to fail")
+	case !errors.As(err, &failed):
+		return fmt.Errorf("unexpected error: %w", err)
+	case failed.Module != core.ModuleName || failed.Code != errCoreInvalidCallFormat:
+		return fmt.Errorf("expected invalid call format failure, got: %s", failed)
+	}
+	return nil
+}
+
+// ConfidentialRoundTripTest submits an encrypted call and checks that neither the call nor its
+// result are stored in plain text, while the client can decrypt the result.
+func ConfidentialRoundTripTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+	ctx := context.Background()
+	if err := requireKeymanager(sc); err != nil {
+		return err
+	}
+
+	testKey := []byte("confidential_key")
+	testValue := []byte("confidential_value")
+
+	log.Info("fetching call data public key")
+	rsp, err := core.NewV1(rtc).CallDataPublicKey(ctx, client.RoundLatest)
+	if err != nil {
+		return fmt.Errorf("failed to fetch call data public key: %w", err)
+	}
+	if rsp.PublicKey.PublicKey == [32]byte{} {
+		return fmt.Errorf("runtime returned an empty call data public key")
+	}
+
+	log.Info("submitting encrypted call")
+	meta, err := kvInsertConfidential(ctx, rtc, nil, testing.Alice.Signer, testKey, testValue, nil)
+	if err != nil {
+		return fmt.Errorf("failed to submit encrypted call: %w", err)
+	}
+
+	log.Info("checking stored transaction", "round", meta.Round, "batch_order", meta.BatchOrder)
+	txs, err := rtc.GetTransactionsWithResults(ctx, meta.Round)
+	if err != nil {
+		return fmt.Errorf("failed to get transactions: %w", err)
+	}
+	if int(meta.BatchOrder) >= len(txs) {
+		return fmt.Errorf("transaction missing from round %d", meta.Round)
+	}
+	txr := txs[meta.BatchOrder]
+	var tx types.Transaction
+	if err = cbor.Unmarshal(txr.Tx.Body, &tx); err != nil {
+		return fmt.Errorf("malformed stored transaction: %w", err)
+	}
+	switch {
+	case tx.Call.Format != types.CallFormatEncryptedX25519DeoxysII:
+		return fmt.Errorf("unexpected call format of stored transaction: %d", tx.Call.Format)
+	case tx.Call.Method != "":
+		return fmt.Errorf("stored transaction reveals method: %s", tx.Call.Method)
+	case bytes.Contains(tx.Call.Body, testValue):
+		return fmt.Errorf("stored transaction reveals call body")
+	case !txr.Result.IsUnknown():
+		return fmt.Errorf("stored transaction result is not encrypted")
+	}
+
+	log.Info("checking inserted value")
+	value, err := kvGet(rtc, testKey)
+	if err != nil {
+		return fmt.Errorf("failed to get inserted value: %w", err)
+	}
+	if !bytes.Equal(value, testValue) {
+		return fmt.Errorf("unexpected inserted value: %s", value)
+	}
+	return kvRemove(rtc, testing.Alice.Signer, testKey)
+}
+
+// ConfidentialFailureTest checks that encrypted calls fail as expected when encrypted to a stale
+// key, when the key is not signed by a trusted key manager, or when the envelope is tampered with,
+// and that the client recovers from a stale key.
+func ConfidentialFailureTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+	ctx := context.Background()
+	if err := requireKeymanager(sc); err != nil {
+		return err
+	}
+
+	signer := testing.Alice.Signer
+	testKey := []byte("confidential_failure_key")
+	testValue := []byte("confidential_failure_value")
+
+	log.Info("submitting encrypted call with a stale key")
+	pkCache := client.NewCallDataPublicKeyCache(&staleKeyClient{RuntimeClient: rtc}, 0)
+	_, err := kvInsertConfidential(ctx, rtc, pkCache, signer, testKey, testValue, nil)
+	if err = expectInvalidCallFormat(err); err != nil {
+		return fmt.Errorf("stale key: %w", err)
+	}
+
+	log.Info("resubmitting encrypted call after key refresh")
+	if _, err = kvInsertConfidential(ctx, rtc, pkCache, signer, testKey, testValue, nil); err != nil {
+		return fmt.Errorf("failed to resubmit encrypted call with refreshed key: %w", err)
+	}
+
+	log.Info("submitting encrypted call with an untrusted key")
+	untrusted, err := memorySigner.NewSigner(rand.Reader)
+	if err != nil {
+		return err
+	}
+	untrustedCache := client.NewCallDataPublicKeyCache(rtc, 0, untrusted.Public())
+	if _, err = kvInsertConfidential(ctx, rtc, untrustedCache, signer, testKey, testValue, nil); err == nil {
+		return fmt.Errorf("untrusted key: expected encrypting the call to fail")
+	}
+
+	log.Info("submitting encrypted call with a tampered envelope")
+	_, err = kvInsertConfidential(ctx, rtc, nil, signer, testKey, testValue, func(tx *types.Transaction) error {
+		var envelope types.CallEnvelopeX25519DeoxysII
+		if err := cbor.Unmarshal(tx.Call.Body, &envelope); err != nil {
+			return fmt.Errorf("malformed call envelope: %w", err)
+		}
+		envelope.Data[0] ^= 0xff
+		tx.Call.Body = cbor.Marshal(envelope)
+		return nil
+	})
+	if err = expectInvalidCallFormat(err); err != nil {
+		return fmt.Errorf("tampered envelope: %w", err)
+	}
+
+	return kvRemove(rtc, signer, testKey)
+}
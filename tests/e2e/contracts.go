@@ -20,6 +20,8 @@ import (
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/contracts/oas20"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+
+	"github.com/oasisprotocol/oasis-sdk/tests/e2e/harness"
 )
 
 //go:embed contracts/hello.wasm
@@ -33,7 +35,7 @@ type HelloInitiate struct {
 }
 
 // ContractsTest does a simple upload/instantiate/call contract test.
-func ContractsTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error { // nolint: gocyclo
+func ContractsTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error { // nolint: gocyclo
 	ctx := context.Background()
 
 	counter := uint64(24)
@@ -93,7 +95,7 @@ func ContractsTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.ClientCo
 	}
 
 	var result map[string]map[string]string
-	if err = cbor.Unmarshal(rawResult, &result); err != nil {
+	if err = rawResult.Decode(&result); err != nil {
 		return fmt.Errorf("failed to decode contract result: %w", err)
 	}
 
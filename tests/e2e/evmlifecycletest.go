@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/evm"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/events"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+
+	"github.com/oasisprotocol/oasis-sdk/tests/e2e/harness"
+)
+
+// evmStoredTopic is the Keccak-256 hash of the "Stored(uint256)" event signature.
+const evmStoredTopic = "c6d8c0af6d21f291e7c359603aa97e0ed500f04db6e983b9fce75a91c6b8da6b"
+
+// errEVMError is the EVM module error returned for failed (e.g. reverted) executions.
+const errEVMError = 2
+
+// evmU256 returns the given value as a big-endian 256-bit word.
+func evmU256(v uint64) []byte {
+	var w [32]byte
+	for i := 0; i < 8; i++ {
+		w[31-i] = byte(v >> (8 * i))
+	}
+	return w[:]
+}
+
+// expectEVMRevert checks that the given error is caused by a reverted EVM execution.
+func expectEVMRevert(err error) error {
+	if err == nil {
+		return fmt.Errorf("expected the execution to revert")
+	}
+	var failed *types.FailedCallResult
+	if errors.As(err, &failed) && (failed.Module != evm.ModuleName || failed.Code != errEVMError) {
+		return fmt.Errorf("expected EVM error, got: %s", failed)
+	}
+	if !strings.Contains(err.Error(), "Revert") {
+		return fmt.Errorf("expected the execution to revert, got: %w", err)
+	}
+	return nil
+}
+
+// SimpleEVMLifecycleTest exercises the whole EVM module surface: it deploys a contract, queries its
+// code, storage and balance, simulates and submits both successful and reverting calls, and checks
+// the logs emitted by the contract.
+func SimpleEVMLifecycleTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signer := testing.Dave.Signer
+	e := evm.NewV1(rtc)
+	gasPrice := uint64(1)
+	gasLimit := uint64(64000)
+
+	// Create a contract that reverts when called without call data. Otherwise it stores the
+	// first word of the call data in slot 0 of its storage, emits it in a Stored(uint256) log
+	// and returns it.
+	var storeSrc string
+	storeSrc += "36"                  // CALLDATASIZE.
+	storeSrc += "60"                  // PUSH1.
+	storeSrc += "08"                  // Offset of JUMPDEST below.
+	storeSrc += "57"                  // JUMPI -- jump if there is call data.
+	storeSrc += "60"                  // PUSH1.
+	storeSrc += "00"                  // Constant 0.
+	storeSrc += "80"                  // DUP1.
+	storeSrc += "fd"                  // REVERT.
+	storeSrc += "5b"                  // JUMPDEST.
+	storeSrc += "60"                  // PUSH1.
+	storeSrc += "00"                  // Constant 0.
+	storeSrc += "35"                  // CALLDATALOAD -- load the first word of call data.
+	storeSrc += "80"                  // DUP1.
+	storeSrc += "60"                  // PUSH1.
+	storeSrc += "00"                  // Constant 0.
+	storeSrc += "55"                  // SSTORE -- store the word in slot 0.
+	storeSrc += "60"                  // PUSH1.
+	storeSrc += "00"                  // Constant 0.
+	storeSrc += "52"                  // MSTORE -- store the word in memory at 0.
+	storeSrc += "7f" + evmStoredTopic // PUSH32 topic.
+	storeSrc += "60"                  // PUSH1.
+	storeSrc += "20"                  // Constant 32.
+	storeSrc += "60"                  // PUSH1.
+	storeSrc += "00"                  // Constant 0.
+	storeSrc += "a1"                  // LOG1 -- log the word from memory.
+	storeSrc += "60"                  // PUSH1.
+	storeSrc += "20"                  // Constant 32.
+	storeSrc += "60"                  // PUSH1.
+	storeSrc += "00"                  // Constant 0.
+	storeSrc += "f3"                  // RETURN -- return the word from memory.
+
+	storeBytecode, err := hex.DecodeString(storeSrc)
+	if err != nil {
+		return err
+	}
+	topic, err := hex.DecodeString(evmStoredTopic)
+	if err != nil {
+		return err
+	}
+
+	log.Info("creating contract")
+	contractAddr, err := evmCreate(ctx, rtc, e, signer, evmU256(1), evmPack(storeBytecode), gasPrice, gasLimit)
+	if err != nil {
+		return fmt.Errorf("evmCreate failed: %w", err)
+	}
+	log.Info("evmCreate finished", "contract_addr", hex.EncodeToString(contractAddr))
+
+	storedCode, err := e.Code(ctx, contractAddr)
+	if err != nil {
+		return fmt.Errorf("Code failed: %w", err) //nolint: stylecheck
+	}
+	if !bytes.Equal(storedCode, storeBytecode) {
+		return fmt.Errorf("stored code doesn't match original code")
+	}
+
+	checkContract := func(expectedValue []byte, expectedBalance uint64) error {
+		storedVal, err := e.Storage(ctx, contractAddr, evmU256(0))
+		if err != nil {
+			return fmt.Errorf("Storage failed: %w", err) //nolint: stylecheck
+		}
+		if !bytes.Equal(storedVal, expectedValue) {
+			return fmt.Errorf("stored value is wrong (expected %X, got %X)", expectedValue, storedVal)
+		}
+		bal, err := e.Balance(ctx, contractAddr)
+		if err != nil {
+			return fmt.Errorf("Balance failed: %w", err) //nolint: stylecheck
+		}
+		if bal.Cmp(quantity.NewFromUint64(expectedBalance)) != 0 {
+			return fmt.Errorf("contract's EVM account balance is wrong (expected %d, got %s)", expectedBalance, bal)
+		}
+		return nil
+	}
+	if err = checkContract(evmU256(0), 1); err != nil {
+		return err
+	}
+
+	log.Info("simulating reverting call")
+	_, err = e.SimulateCall(ctx, evmU256(gasPrice), gasLimit, testing.Dave.EthAddress, contractAddr, evmU256(0), nil)
+	if err = expectEVMRevert(err); err != nil {
+		return fmt.Errorf("SimulateCall: %w", err)
+	}
+
+	value := evmU256(0x42)
+	log.Info("simulating call")
+	simCallResult, err := e.SimulateCall(ctx, evmU256(gasPrice), gasLimit, testing.Dave.EthAddress, contractAddr, evmU256(0), value)
+	if err != nil {
+		return fmt.Errorf("SimulateCall failed: %w", err)
+	}
+	if !bytes.Equal(simCallResult, value) {
+		return fmt.Errorf("SimulateCall returned wrong result: %X", simCallResult)
+	}
+	// Simulated calls must not change any state.
+	if err = checkContract(evmU256(0), 1); err != nil {
+		return err
+	}
+
+	ch, err := rtc.WatchEvents(ctx, []client.EventDecoder{e}, false)
+	if err != nil {
+		return err
+	}
+
+	log.Info("calling contract")
+	callResult, err := evmCall(ctx, rtc, e, signer, contractAddr, evmU256(1), value, gasPrice, gasLimit)
+	if err != nil {
+		return fmt.Errorf("evmCall failed: %w", err)
+	}
+	if !bytes.Equal(callResult, simCallResult) {
+		return fmt.Errorf("SimulateCall and evmCall returned different results")
+	}
+
+	ev, err := events.WaitForRuntimeEvent(ctx, ch, sc.EventConfig(log), events.EVMLog(contractAddr, [][]byte{topic}, value))
+	if err != nil {
+		return fmt.Errorf("Stored log not emitted: %w", err) //nolint: stylecheck
+	}
+	log.Info("Stored log emitted", "round", ev.Round)
+
+	if err = checkContract(value, 2); err != nil {
+		return err
+	}
+
+	log.Info("submitting reverting call")
+	_, err = evmCall(ctx, rtc, e, signer, contractAddr, evmU256(1), nil, gasPrice, gasLimit)
+	if err = expectEVMRevert(err); err != nil {
+		return fmt.Errorf("evmCall: %w", err)
+	}
+	// Reverted calls must not change any state, including the value transfer.
+	return checkContract(value, 2)
+}
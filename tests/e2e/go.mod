@@ -24,5 +24,6 @@ require (
 	github.com/oasisprotocol/curve25519-voi v0.0.0-20210716083614-f38f8e8b0b84
 	github.com/oasisprotocol/oasis-core/go v0.2103.1
 	github.com/oasisprotocol/oasis-sdk/client-sdk/go v0.0.0-00010101000000-000000000000
+	github.com/prometheus/client_golang v1.11.0
 	google.golang.org/grpc v1.41.0
 )
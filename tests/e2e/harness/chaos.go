@@ -0,0 +1,114 @@
+package harness
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/oasisprotocol/oasis-core/go/oasis-test-runner/oasis"
+)
+
+// Chaos injects faults into the test network of a chaos scenario.
+type Chaos struct {
+	sc *RuntimeScenario
+}
+
+// Chaos returns the fault injector of the scenario.
+//
+// It fails in case the scenario is not a chaos scenario, i.e. when called from a test that was
+// not registered with Options.Chaos, as injected faults would break the tests sharing the network.
+func (sc *RuntimeScenario) Chaos() (*Chaos, error) {
+	if !sc.chaos {
+		return nil, fmt.Errorf("harness: fault injection is only supported in chaos tests")
+	}
+	return &Chaos{sc: sc}, nil
+}
+
+// Nodes returns the nodes of the given group.
+func (c *Chaos) Nodes(group NodeGroup) []*oasis.Node {
+	return c.sc.nodes(group)
+}
+
+// StopNodes stops the given nodes.
+func (c *Chaos) StopNodes(nodes ...*oasis.Node) error {
+	return c.sc.stopNodes(nodes)
+}
+
+// StartNodes starts the given (previously stopped) nodes and waits for them to become ready.
+func (c *Chaos) StartNodes(ctx context.Context, nodes ...*oasis.Node) error {
+	return c.sc.startNodes(ctx, nodes)
+}
+
+// RestartNodes stops the given nodes and starts them again after the given downtime.
+func (c *Chaos) RestartNodes(ctx context.Context, downtime time.Duration, nodes ...*oasis.Node) error {
+	if err := c.StopNodes(nodes...); err != nil {
+		return err
+	}
+	select {
+	case <-time.After(downtime):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return c.StartNodes(ctx, nodes...)
+}
+
+// Partition cuts the given nodes off the rest of the network for the given duration.
+//
+// The test runner has no control over the network between nodes, so the partition is simulated by
+// stopping the nodes. For the rest of the network, this cannot be told apart from losing
+// connectivity to them, but the partitioned nodes do not make progress on their own meanwhile.
+func (c *Chaos) Partition(ctx context.Context, duration time.Duration, nodes ...*oasis.Node) error {
+	c.sc.Logger.Info("chaos: partitioning network", "nodes", len(nodes), "duration", duration)
+	return c.RestartNodes(ctx, duration, nodes...)
+}
+
+// KillKeymanager kills all key manager nodes and starts them again after the given downtime.
+func (c *Chaos) KillKeymanager(ctx context.Context, downtime time.Duration) error {
+	nodes := c.Nodes(NodeGroupKeymanagers)
+	if len(nodes) == 0 {
+		return fmt.Errorf("harness: test network has no key manager")
+	}
+	return c.RestartNodes(ctx, downtime, nodes...)
+}
+
+// During runs the given fault concurrently with the given function and waits for both to finish.
+// It returns the first encountered error.
+func (c *Chaos) During(ctx context.Context, fault, fn func(context.Context) error) error {
+	var (
+		wg       sync.WaitGroup
+		faultErr error
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		faultErr = fault(ctx)
+	}()
+	err := fn(ctx)
+	wg.Wait()
+
+	if faultErr != nil {
+		return fmt.Errorf("harness: fault injection failed: %w", faultErr)
+	}
+	return err
+}
+
+// Eventually calls the given function every interval until it succeeds or until the timeout
+// expires, in which case the last error is returned. It is meant to assert that the client
+// recovers from injected faults.
+func Eventually(ctx context.Context, timeout, interval time.Duration, fn func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return fmt.Errorf("no success within %s: %w", timeout, err)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package harness
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	sdkConsensus "github.com/oasisprotocol/oasis-sdk/client-sdk/go/client/consensus"
+)
+
+// initialEpochTransitions performs the epoch transitions needed for the runtime committees to be
+// elected in a test network with mock epochs.
+func (sc *RuntimeScenario) initialEpochTransitions(ctx context.Context) error {
+	waitReady := func(groups ...NodeGroup) error {
+		for _, group := range groups {
+			sc.Logger.Info("waiting for nodes to initialize", "group", group)
+			for _, n := range sc.nodes(group) {
+				if err := n.WaitReady(ctx); err != nil {
+					return fmt.Errorf("harness: failed to wait for node %s: %w", n.Name, err)
+				}
+			}
+		}
+		return nil
+	}
+
+	epoch := beacon.EpochTime(1)
+	if len(sc.Net.Keymanagers()) > 0 {
+		// Key managers must be registered before the other nodes, which only happens once the
+		// epoch advances.
+		if err := waitReady(NodeGroupValidators, NodeGroupKeymanagers); err != nil {
+			return err
+		}
+		if err := sc.setEpoch(ctx, epoch); err != nil {
+			return err
+		}
+		epoch++
+	}
+
+	if err := waitReady(NodeGroupStorageWorkers, NodeGroupComputeWorkers); err != nil {
+		return err
+	}
+	return sc.setEpoch(ctx, epoch)
+}
+
+// Epoch returns the current epoch of the test network.
+func (sc *RuntimeScenario) Epoch(ctx context.Context) (beacon.EpochTime, error) {
+	epoch, err := sc.Net.Controller().Beacon.GetEpoch(ctx, consensus.HeightLatest)
+	if err != nil {
+		return beacon.EpochInvalid, fmt.Errorf("harness: failed to query epoch: %w", err)
+	}
+	return epoch, nil
+}
+
+// SetEpoch advances the test network to the given epoch.
+//
+// It fails in case the scenario does not use mock epochs, i.e. when called from a test that was
+// not registered with Options.MockEpoch, or in case the given epoch is not after the current one.
+// As epochs are shared by the whole test network, tests controlling epochs should not be
+// registered as parallel.
+func (sc *RuntimeScenario) SetEpoch(ctx context.Context, epoch beacon.EpochTime) error {
+	if !sc.mockEpoch {
+		return fmt.Errorf("harness: epoch control is only supported in mock epoch tests")
+	}
+	current, err := sc.Epoch(ctx)
+	if err != nil {
+		return err
+	}
+	if epoch <= current {
+		return fmt.Errorf("harness: epoch %d is not after the current epoch %d", epoch, current)
+	}
+	return sc.setEpoch(ctx, epoch)
+}
+
+// AdvanceEpochs advances the epoch of the test network by the given number of epochs and returns
+// the new epoch (see SetEpoch).
+func (sc *RuntimeScenario) AdvanceEpochs(ctx context.Context, n uint64) (beacon.EpochTime, error) {
+	current, err := sc.Epoch(ctx)
+	if err != nil {
+		return beacon.EpochInvalid, err
+	}
+	epoch := current + beacon.EpochTime(n)
+	if err = sc.SetEpoch(ctx, epoch); err != nil {
+		return beacon.EpochInvalid, err
+	}
+	return epoch, nil
+}
+
+func (sc *RuntimeScenario) setEpoch(ctx context.Context, epoch beacon.EpochTime) error {
+	sc.Logger.Info("triggering epoch transition", "epoch", epoch)
+	if err := sc.Net.Controller().SetEpoch(ctx, epoch); err != nil {
+		return fmt.Errorf("harness: failed to set epoch %d: %w", epoch, err)
+	}
+	return nil
+}
+
+// WaitRuntimeEpoch waits for the runtime to finalize a round in the given (or a later) epoch, i.e.
+// for the runtime to process the transition to the given epoch.
+func (sc *RuntimeScenario) WaitRuntimeEpoch(ctx context.Context, conn *grpc.ClientConn, rtc client.RuntimeClient, epoch beacon.EpochTime) error {
+	cons := sdkConsensus.New(conn)
+	return Eventually(ctx, sc.epochTransitionTimeout(), time.Second, func(ctx context.Context) error {
+		info, err := rtc.GetInfo(ctx)
+		if err != nil {
+			return err
+		}
+		blk, err := rtc.GetBlock(ctx, client.RoundLatest)
+		if err != nil {
+			return err
+		}
+		roundEpoch, err := cons.RoundEpoch(ctx, info.ID, blk.Header.Round)
+		if err != nil {
+			return err
+		}
+		if roundEpoch < epoch {
+			return fmt.Errorf("harness: latest runtime round %d is in epoch %d", blk.Header.Round, roundEpoch)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,187 @@
+// Package harness implements an end-to-end test harness for runtimes built with the Oasis Runtime
+// SDK, based on oasis-test-runner.
+//
+// Tests are registered with Register and grouped into one scenario per runtime binary, so that all
+// tests of a runtime share the same test network. Tests of a scenario run in registration order
+// and can be selected using the test.regex and test.skip_regex scenario parameters. Adjacent tests
+// registered as parallel run concurrently, up to the limit given by the test.parallelism scenario
+// parameter.
+//
+// Tests may require a clean test network state, in which case the state of all nodes is
+// snapshotted after the network is set up and restored before running them. Restoring the snapshot
+// only restarts the nodes, which is much faster than setting up a new network. The test.reset
+// scenario parameter restores the snapshot before every test.
+//
+// Timeouts, like how long to wait for expected events (see RuntimeScenario.EventConfig), are
+// scenario parameters, whose defaults can be scaled for all scenarios on slow machines using the
+// environment variable named by TimeoutScaleEnvVar. Known-flaky setup steps are retried with
+// backoff according to the retry.attempts and retry.backoff scenario parameters.
+//
+// In case the report.dir scenario parameter is set, each scenario run writes a JSON and a JUnit
+// XML report (see Report) with the results and timing of all tests, so that CI systems can track
+// failures without parsing logs.
+//
+// In case the metrics.listen_addr scenario parameter is set, scenario and test durations,
+// transaction submission latencies and error counts are served as Prometheus metrics in the
+// OpenMetrics format while scenarios run, so that long-running soak tests can be monitored live.
+//
+// Tests registered as chaos tests run in a separate scenario per runtime and may inject faults,
+// like restarting nodes, into the test network of that scenario.
+//
+// Tests may require additional runtimes, which are started in the same test network as the
+// runtime the test runs against. Such tests run in a separate scenario per set of runtimes and can
+// address each runtime by its index, e.g. to test flows between runtimes. The same runtime binary
+// may be started more than once, with a different runtime identifier each time.
+//
+// Tests registered as mock epoch tests run in a separate scenario per runtime, in which epochs do
+// not advance on their own but only when advanced by a test, so that logic depending on epochs,
+// like rewards, debonding or expiry, can be tested deterministically. Block timestamps cannot be
+// controlled, so such logic should be based on epochs rather than on time.
+package harness
+
+import (
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"github.com/oasisprotocol/oasis-core/go/oasis-test-runner/cmd"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+)
+
+// HookFunction is a test setup or teardown function.
+type HookFunction func(*RuntimeScenario, *grpc.ClientConn, client.RuntimeClient) error
+
+// Options are the options of a registered test.
+type Options struct {
+	// Runtime is the name of the runtime binary the test runs against.
+	Runtime string
+
+	// ExtraRuntimes are the names of additional runtime binaries started in the same test network
+	// (see RuntimeScenario.RuntimeClient). Tests with extra runtimes run in a separate scenario per
+	// set of runtimes, so that other tests do not pay for starting them.
+	ExtraRuntimes []string
+
+	// Setup is an optional function invoked before the test. The test is not run in case setup
+	// fails.
+	Setup HookFunction
+
+	// Teardown is an optional function invoked after the test, even if the test failed.
+	Teardown HookFunction
+
+	// Parallel specifies that the test may run concurrently with adjacent parallel tests of the
+	// same scenario. Each parallel test gets its own client connection, but the test network is
+	// shared, so parallel tests must not use the same accounts (or otherwise depend on state that
+	// other tests modify).
+	Parallel bool
+
+	// Reset specifies that the test requires a clean test network state. The network state is
+	// snapshotted after the network is set up and restored before the test (or before the group
+	// of adjacent parallel tests it belongs to), so that balances and nonces modified by previous
+	// tests do not leak into it.
+	Reset bool
+
+	// Chaos specifies that the test injects faults into the test network (see Chaos). Chaos tests
+	// run in a separate scenario with a test network of their own, so that the injected faults do
+	// not affect other tests.
+	Chaos bool
+
+	// MockEpoch specifies that the test controls the epochs of the test network (see SetEpoch).
+	// Mock epoch tests run in a separate scenario with a test network of their own, in which
+	// epochs only advance when a test advances them.
+	MockEpoch bool
+}
+
+type registeredTest struct {
+	name string
+	fn   RunTestFunction
+	opts Options
+}
+
+var testRegistry struct {
+	sync.Mutex
+
+	scenarios []*RuntimeScenario
+	names     map[string]bool
+}
+
+// Register registers a test with the given name to be run against the runtime given in the
+// options.
+//
+// Test names must be unique. All tests must be registered before calling RegisterScenarios.
+func Register(name string, fn RunTestFunction, opts *Options) error {
+	if name == "" || fn == nil {
+		return fmt.Errorf("harness: malformed test registration")
+	}
+	if opts == nil || opts.Runtime == "" {
+		return fmt.Errorf("harness: test %s: runtime not specified", name)
+	}
+
+	testRegistry.Lock()
+	defer testRegistry.Unlock()
+
+	if testRegistry.names[name] {
+		return fmt.Errorf("harness: test %s already registered", name)
+	}
+	if testRegistry.names == nil {
+		testRegistry.names = make(map[string]bool)
+	}
+	testRegistry.names[name] = true
+
+	sc := scenarioLocked(opts.Runtime, opts.ExtraRuntimes, opts.Chaos, opts.MockEpoch)
+	sc.tests = append(sc.tests, &registeredTest{
+		name: name,
+		fn:   fn,
+		opts: *opts,
+	})
+	return nil
+}
+
+// Scenario returns the (non-chaos, non-mock epoch) single runtime scenario of the given runtime, e.g. to register additional
+// scenario parameters. The scenario is created in case it does not exist yet.
+func Scenario(runtimeName string) *RuntimeScenario {
+	testRegistry.Lock()
+	defer testRegistry.Unlock()
+
+	return scenarioLocked(runtimeName, nil, false, false)
+}
+
+func scenarioLocked(runtimeName string, extraRuntimes []string, chaos, mockEpoch bool) *RuntimeScenario {
+	for _, sc := range testRegistry.scenarios {
+		if sc.RuntimeName == runtimeName && equalRuntimes(sc.extraRuntimes, extraRuntimes) && sc.chaos == chaos && sc.mockEpoch == mockEpoch {
+			return sc
+		}
+	}
+	sc := newRuntimeScenario(runtimeName, extraRuntimes, chaos, mockEpoch)
+	testRegistry.scenarios = append(testRegistry.scenarios, sc)
+	return sc
+}
+
+// RegisterScenarios registers the scenarios of all registered tests with the test runner.
+func RegisterScenarios() error {
+	testRegistry.Lock()
+	defer testRegistry.Unlock()
+
+	// Register non-scenario-specific parameters.
+	cmd.RegisterScenarioParams(runtimeParamsDummy.Name(), runtimeParamsDummy.Parameters())
+
+	for _, sc := range testRegistry.scenarios {
+		if err := cmd.Register(sc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func equalRuntimes(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
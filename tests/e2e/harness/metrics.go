@@ -0,0 +1,177 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
+)
+
+const cfgMetricsListenAddr = "metrics.listen_addr"
+
+const metricsNamespace = "oasis_sdk_e2e"
+
+// Results of scenarios and tests in metric labels.
+const (
+	resultPassed = "passed"
+	resultFailed = "failed"
+)
+
+var (
+	scenarioDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "scenario_duration_seconds",
+			Help:      "Duration of scenario runs, including setting up the test network.",
+			Buckets:   prometheus.ExponentialBuckets(10, 2, 10),
+		},
+		[]string{"scenario", "result"},
+	)
+	testDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "test_duration_seconds",
+			Help:      "Duration of tests, including their setup and teardown hooks.",
+			Buckets:   prometheus.ExponentialBuckets(0.5, 2, 12),
+		},
+		[]string{"scenario", "test", "result"},
+	)
+	txSubmitDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "tx_submit_duration_seconds",
+			Help:      "Latency of runtime transaction submissions.",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12),
+		},
+		[]string{"scenario", "method"},
+	)
+	errorCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "errors_total",
+			Help:      "Number of errors by kind (scenario, test or tx_submit).",
+		},
+		[]string{"scenario", "kind"},
+	)
+
+	metricsLock    sync.Mutex
+	metricsServing bool
+)
+
+func init() {
+	prometheus.MustRegister(scenarioDuration, testDuration, txSubmitDuration, errorCount)
+}
+
+func metricsResult(err error) string {
+	if err != nil {
+		return resultFailed
+	}
+	return resultPassed
+}
+
+// serveMetrics starts serving metrics in the OpenMetrics format on the address given by the
+// metrics.listen_addr scenario parameter, if set. The server is shared by all scenarios run by the
+// process and is started by the first scenario run with the parameter set.
+func (sc *RuntimeScenario) serveMetrics() error {
+	addr, _ := sc.Flags.GetString(cfgMetricsListenAddr)
+	if addr == "" {
+		return nil
+	}
+
+	metricsLock.Lock()
+	defer metricsLock.Unlock()
+	if metricsServing {
+		return nil
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+	}))
+	srv := &http.Server{Handler: mux}
+	go func() {
+		if srvErr := srv.Serve(ln); !errors.Is(srvErr, http.ErrServerClosed) {
+			sc.Logger.Error("metrics server failed", "err", srvErr)
+		}
+	}()
+	metricsServing = true
+
+	sc.Logger.Info("serving metrics", "addr", ln.Addr().String())
+	return nil
+}
+
+// recordScenario records the result of the scenario run, started at the given time.
+func (sc *RuntimeScenario) recordScenario(start time.Time, err error) {
+	scenarioDuration.WithLabelValues(sc.Name(), metricsResult(err)).Observe(time.Since(start).Seconds())
+	if err != nil {
+		errorCount.WithLabelValues(sc.Name(), "scenario").Inc()
+	}
+}
+
+// recordTestMetrics records the result of the given test, started at the given time.
+func (sc *RuntimeScenario) recordTestMetrics(name string, start time.Time, err error) {
+	testDuration.WithLabelValues(sc.Name(), name, metricsResult(err)).Observe(time.Since(start).Seconds())
+	if err != nil {
+		errorCount.WithLabelValues(sc.Name(), "test").Inc()
+	}
+}
+
+// instrumentedClient is a runtime client recording the latency and errors of transaction
+// submissions.
+type instrumentedClient struct {
+	client.RuntimeClient
+
+	scenario string
+}
+
+func (ic *instrumentedClient) observe(method string, start time.Time, err error) {
+	txSubmitDuration.WithLabelValues(ic.scenario, method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		errorCount.WithLabelValues(ic.scenario, "tx_submit").Inc()
+	}
+}
+
+func (ic *instrumentedClient) SubmitTxRaw(ctx context.Context, tx *types.UnverifiedTransaction) (result *types.CallResult, err error) {
+	defer func(start time.Time) { ic.observe("SubmitTxRaw", start, err) }(time.Now())
+	return ic.RuntimeClient.SubmitTxRaw(ctx, tx)
+}
+
+func (ic *instrumentedClient) SubmitTxRawMeta(ctx context.Context, tx *types.UnverifiedTransaction) (meta *client.SubmitTxRawMeta, err error) {
+	defer func(start time.Time) { ic.observe("SubmitTxRawMeta", start, err) }(time.Now())
+	return ic.RuntimeClient.SubmitTxRawMeta(ctx, tx)
+}
+
+func (ic *instrumentedClient) SubmitTx(ctx context.Context, tx *types.UnverifiedTransaction) (result cbor.RawMessage, err error) {
+	defer func(start time.Time) { ic.observe("SubmitTx", start, err) }(time.Now())
+	return ic.RuntimeClient.SubmitTx(ctx, tx)
+}
+
+func (ic *instrumentedClient) SubmitTxMeta(ctx context.Context, tx *types.UnverifiedTransaction) (meta *client.SubmitTxMeta, err error) {
+	defer func(start time.Time) { ic.observe("SubmitTxMeta", start, err) }(time.Now())
+	return ic.RuntimeClient.SubmitTxMeta(ctx, tx)
+}
+
+func (ic *instrumentedClient) SubmitTxNoWait(ctx context.Context, tx *types.UnverifiedTransaction) (err error) {
+	defer func(start time.Time) { ic.observe("SubmitTxNoWait", start, err) }(time.Now())
+	return ic.RuntimeClient.SubmitTxNoWait(ctx, tx)
+}
+
+// instrumentClient wraps the given runtime client so that its transaction submissions are
+// recorded in the metrics of the scenario.
+func (sc *RuntimeScenario) instrumentClient(rtc client.RuntimeClient) client.RuntimeClient {
+	return &instrumentedClient{RuntimeClient: rtc, scenario: sc.Name()}
+}
@@ -0,0 +1,113 @@
+package harness
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/oasisprotocol/oasis-core/go/oasis-test-runner/oasis"
+)
+
+// NodeGroup is a group of nodes in the test network.
+type NodeGroup uint8
+
+// Node groups.
+const (
+	NodeGroupValidators NodeGroup = iota
+	NodeGroupStorageWorkers
+	NodeGroupComputeWorkers
+	NodeGroupKeymanagers
+	NodeGroupClients
+)
+
+// nodeGroups are all node groups, in the order in which they are started.
+var nodeGroups = []NodeGroup{
+	NodeGroupValidators,
+	NodeGroupKeymanagers,
+	NodeGroupStorageWorkers,
+	NodeGroupComputeWorkers,
+	NodeGroupClients,
+}
+
+// String returns a string representation of the node group.
+func (g NodeGroup) String() string {
+	switch g {
+	case NodeGroupValidators:
+		return "validators"
+	case NodeGroupStorageWorkers:
+		return "storage workers"
+	case NodeGroupComputeWorkers:
+		return "compute workers"
+	case NodeGroupKeymanagers:
+		return "key managers"
+	case NodeGroupClients:
+		return "clients"
+	default:
+		return "[unknown node group]"
+	}
+}
+
+// nodes returns the nodes of the given group.
+func (sc *RuntimeScenario) nodes(group NodeGroup) []*oasis.Node {
+	var nodes []*oasis.Node
+	switch group {
+	case NodeGroupValidators:
+		for _, n := range sc.Net.Validators() {
+			nodes = append(nodes, n.Node)
+		}
+	case NodeGroupStorageWorkers:
+		for _, n := range sc.Net.StorageWorkers() {
+			nodes = append(nodes, n.Node)
+		}
+	case NodeGroupComputeWorkers:
+		for _, n := range sc.Net.ComputeWorkers() {
+			nodes = append(nodes, n.Node)
+		}
+	case NodeGroupKeymanagers:
+		for _, n := range sc.Net.Keymanagers() {
+			nodes = append(nodes, n.Node)
+		}
+	case NodeGroupClients:
+		for _, n := range sc.Net.Clients() {
+			nodes = append(nodes, n.Node)
+		}
+	}
+	return nodes
+}
+
+// allNodes returns the nodes of all groups, in the order in which they are started.
+func (sc *RuntimeScenario) allNodes() []*oasis.Node {
+	var nodes []*oasis.Node
+	for _, group := range nodeGroups {
+		nodes = append(nodes, sc.nodes(group)...)
+	}
+	return nodes
+}
+
+// stopNodes stops the given nodes.
+func (sc *RuntimeScenario) stopNodes(nodes []*oasis.Node) error {
+	for _, n := range nodes {
+		sc.Logger.Info("stopping node", "node", n.Name)
+		if err := n.Stop(); err != nil {
+			return fmt.Errorf("harness: failed to stop node %s: %w", n.Name, err)
+		}
+	}
+	return nil
+}
+
+// startNodes starts the given (previously stopped) nodes and waits for them to become ready.
+func (sc *RuntimeScenario) startNodes(ctx context.Context, nodes []*oasis.Node) error {
+	for _, n := range nodes {
+		sc.Logger.Info("starting node", "node", n.Name)
+		if err := n.Start(); err != nil {
+			return fmt.Errorf("harness: failed to start node %s: %w", n.Name, err)
+		}
+	}
+	for _, n := range nodes {
+		// The node's socket may not exist yet right after the node is started.
+		if err := Eventually(ctx, sc.nodeReadyTimeout(), time.Second, n.WaitReady); err != nil {
+			return fmt.Errorf("harness: node %s did not become ready: %w", n.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package harness
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	cmnGrpc "github.com/oasisprotocol/oasis-core/go/common/grpc"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+)
+
+// groupTests splits the tests into groups that are run one after another. Adjacent parallel tests
+// are put into the same group, all other tests are put into groups of their own.
+func groupTests(tests []*registeredTest) [][]*registeredTest {
+	var groups [][]*registeredTest
+	for i, test := range tests {
+		if test.opts.Parallel && i > 0 && tests[i-1].opts.Parallel {
+			groups[len(groups)-1] = append(groups[len(groups)-1], test)
+			continue
+		}
+		groups = append(groups, []*registeredTest{test})
+	}
+	return groups
+}
+
+func groupNames(group []*registeredTest) string {
+	names := make([]string, 0, len(group))
+	for _, test := range group {
+		names = append(names, test.name)
+	}
+	return strings.Join(names, ",")
+}
+
+// runParallel runs a group of parallel tests concurrently, each using its own connection to the
+// client node at the given socket path.
+func (sc *RuntimeScenario) runParallel(group []*registeredTest, socketPath string) error {
+	parallelism, _ := sc.Flags.GetInt(cfgTestParallelism)
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	sc.Logger.Info("running parallel tests",
+		"tests", groupNames(group),
+		"parallelism", parallelism,
+	)
+
+	var (
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, parallelism)
+		errs = make([]error, len(group))
+	)
+	for i, test := range group {
+		wg.Add(1)
+		go func(i int, test *registeredTest) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			conn, err := cmnGrpc.Dial("unix:"+socketPath, grpc.WithInsecure())
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: failed to connect to client node: %w", test.name, err)
+				return
+			}
+			defer conn.Close()
+
+			errs[i] = sc.runTest(test, conn, sc.instrumentClient(client.New(conn, RuntimeID)))
+		}(i, test)
+	}
+	wg.Wait()
+
+	var failed []string
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("parallel tests failed: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
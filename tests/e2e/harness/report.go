@@ -0,0 +1,307 @@
+package harness
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oasisprotocol/oasis-core/go/oasis-test-runner/env"
+	"github.com/oasisprotocol/oasis-core/go/oasis-test-runner/oasis"
+)
+
+const cfgReportDir = "report.dir"
+
+// maxLogTailSize is the maximum size of the client node log tail included in failure diagnostics.
+const maxLogTailSize = 16 * 1024
+
+// TestStatus is the status of a test in a report.
+type TestStatus string
+
+const (
+	// TestPassed is the status of tests that passed.
+	TestPassed TestStatus = "passed"
+	// TestFailed is the status of tests that failed.
+	TestFailed TestStatus = "failed"
+	// TestSkipped is the status of tests that were not selected or not run, e.g. because an
+	// earlier test of the scenario failed.
+	TestSkipped TestStatus = "skipped"
+)
+
+// TestReport is the result of a single test.
+type TestReport struct {
+	// Name is the name of the test.
+	Name string `json:"name"`
+	// Status is the status of the test.
+	Status TestStatus `json:"status"`
+	// Duration is how long the test took in seconds, including its setup and teardown hooks.
+	Duration float64 `json:"duration"`
+	// Error is the error of a failed test or the reason a test was skipped.
+	Error string `json:"error,omitempty"`
+}
+
+// Report is the structured result of a scenario run, written to the directory given by the
+// report.dir scenario parameter in both JSON and JUnit XML format.
+type Report struct {
+	// Scenario is the name of the scenario.
+	Scenario string `json:"scenario"`
+	// Dir is the directory of the scenario run, containing the data and logs of all nodes.
+	Dir string `json:"dir"`
+	// StartTime is when the scenario run started.
+	StartTime time.Time `json:"start_time"`
+	// Duration is how long the scenario run took in seconds, including setting up the network.
+	Duration float64 `json:"duration"`
+	// Passed is true iff the scenario run succeeded.
+	Passed bool `json:"passed"`
+	// Error is the error the scenario run failed with.
+	Error string `json:"error,omitempty"`
+	// Tests are the results of all tests of the scenario, in registration order.
+	Tests []*TestReport `json:"tests"`
+	// Logs are the paths of the logs of all nodes in the test network.
+	Logs []string `json:"logs,omitempty"`
+	// ClientLogTail is the tail of the client node log of a failed scenario run.
+	ClientLogTail string `json:"client_log_tail,omitempty"`
+
+	sync.Mutex `json:"-"`
+}
+
+func newReport(sc *RuntimeScenario, childEnv *env.Env) *Report {
+	r := &Report{
+		Scenario:  sc.Name(),
+		Dir:       childEnv.Dir(),
+		StartTime: time.Now(),
+	}
+	for _, test := range sc.tests {
+		r.Tests = append(r.Tests, &TestReport{
+			Name:   test.name,
+			Status: TestSkipped,
+			Error:  "not run",
+		})
+	}
+	return r
+}
+
+func (r *Report) test(name string) *TestReport {
+	for _, t := range r.Tests {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// recordTest records the result of the given test, started at the given time.
+func (r *Report) recordTest(name string, start time.Time, err error) {
+	if r == nil {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+
+	t := r.test(name)
+	t.Duration = time.Since(start).Seconds()
+	t.Status, t.Error = TestPassed, ""
+	if err != nil {
+		t.Status, t.Error = TestFailed, err.Error()
+	}
+}
+
+// recordSkipped records that the given test was not selected.
+func (r *Report) recordSkipped(name string) {
+	if r == nil {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+
+	r.test(name).Error = "not selected"
+}
+
+// finish records the result of the scenario run and its diagnostics.
+func (r *Report) finish(nodes []*oasis.Node, clientLog string, err error) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.Duration = time.Since(r.StartTime).Seconds()
+	r.Passed = err == nil
+	if err != nil {
+		r.Error = err.Error()
+	}
+	for _, n := range nodes {
+		r.Logs = append(r.Logs, n.LogPath())
+	}
+	if err != nil && clientLog != "" {
+		r.ClientLogTail = tailFile(clientLog, maxLogTailSize)
+	}
+}
+
+// tailFile returns the last complete lines of the given file, up to the given size.
+func tailFile(path string, size int64) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return ""
+	}
+	offset := fi.Size() - size
+	if offset < 0 {
+		offset = 0
+	}
+	data, err := io.ReadAll(io.NewSectionReader(f, offset, fi.Size()-offset))
+	if err != nil {
+		return ""
+	}
+	tail := string(data)
+	if offset > 0 {
+		// Drop the partial first line.
+		if i := strings.IndexByte(tail, '\n'); i >= 0 {
+			tail = tail[i+1:]
+		}
+	}
+	return tail
+}
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       float64         `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitTestCase `xml:"testcase"`
+	SystemOut  string          `xml:"system-out,omitempty"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junit converts the report into a JUnit XML report with a single test suite. Scenario failures
+// outside of tests, e.g. while setting up the network, are reported as a failed "scenario" test.
+func (r *Report) junit() *junitTestSuites {
+	suite := junitTestSuite{
+		Name:      r.Scenario,
+		Time:      r.Duration,
+		Timestamp: r.StartTime.UTC().Format("2006-01-02T15:04:05"),
+		Properties: []junitProperty{
+			{Name: "dir", Value: r.Dir},
+		},
+		SystemOut: strings.Join(r.Logs, "\n"),
+	}
+
+	testFailed := false
+	for _, t := range r.Tests {
+		tc := junitTestCase{
+			ClassName: r.Scenario,
+			Name:      t.Name,
+			Time:      t.Duration,
+		}
+		switch t.Status {
+		case TestFailed:
+			testFailed = true
+			suite.Failures++
+			tc.Failure = &junitMessage{Message: t.Error, Text: r.ClientLogTail}
+		case TestSkipped:
+			suite.Skipped++
+			tc.Skipped = &junitMessage{Message: t.Error}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	if !r.Passed && !testFailed {
+		suite.Failures++
+		suite.Cases = append(suite.Cases, junitTestCase{
+			ClassName: r.Scenario,
+			Name:      "scenario",
+			Time:      r.Duration,
+			Failure:   &junitMessage{Message: r.Error, Text: r.ClientLogTail},
+		})
+	}
+	suite.Tests = len(suite.Cases)
+
+	return &junitTestSuites{Suites: []junitTestSuite{suite}}
+}
+
+// write writes the report to the given directory, as JSON and JUnit XML files named after the
+// scenario and the start time of the run, so that repeated runs do not overwrite each other.
+func (r *Report) write(dir string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("harness: failed to create report directory: %w", err)
+	}
+	base := filepath.Join(dir, fmt.Sprintf("%s-%d",
+		strings.NewReplacer("/", "_", "+", "_").Replace(r.Scenario),
+		r.StartTime.UnixNano(),
+	))
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("harness: failed to encode JSON report: %w", err)
+	}
+	if err = os.WriteFile(base+".json", data, 0o644); err != nil { //nolint: gosec
+		return fmt.Errorf("harness: failed to write JSON report: %w", err)
+	}
+
+	data, err = xml.MarshalIndent(r.junit(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("harness: failed to encode JUnit report: %w", err)
+	}
+	data = append([]byte(xml.Header), data...)
+	if err = os.WriteFile(base+".xml", data, 0o644); err != nil { //nolint: gosec
+		return fmt.Errorf("harness: failed to write JUnit report: %w", err)
+	}
+	return nil
+}
+
+// writeReport finishes the report of the current run with the given error and writes it, if a
+// report directory is configured.
+func (sc *RuntimeScenario) writeReport(err error) error {
+	dir, _ := sc.Flags.GetString(cfgReportDir)
+	if dir == "" || sc.report == nil {
+		return nil
+	}
+
+	var (
+		nodes     []*oasis.Node
+		clientLog string
+	)
+	if sc.Net != nil {
+		nodes = sc.allNodes()
+		if clients := sc.Net.Clients(); len(clients) > 0 {
+			clientLog = clients[0].LogPath()
+		}
+	}
+	sc.report.finish(nodes, clientLog, err)
+	return sc.report.write(dir)
+}
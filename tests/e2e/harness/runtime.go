@@ -1,11 +1,10 @@
-package main
+package harness
 
 import (
 	"context"
 	"fmt"
 	"path/filepath"
-	"reflect"
-	"runtime"
+	"regexp"
 	"time"
 
 	"google.golang.org/grpc"
@@ -27,7 +26,6 @@ import (
 
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
-	"github.com/oasisprotocol/oasis-sdk/tests/e2e/txgen"
 )
 
 const (
@@ -36,12 +34,17 @@ const (
 	cfgIasMock                 = "ias.mock"
 
 	cfgKeymanagerBinary = "keymanager.binary"
+
+	cfgTestRegex       = "test.regex"
+	cfgTestSkipRegex   = "test.skip_regex"
+	cfgTestParallelism = "test.parallelism"
+	cfgTestReset       = "test.reset"
 )
 
 var (
-	// RuntimeParamsDummy is a dummy instance of RuntimeScenario used to
+	// runtimeParamsDummy is a dummy instance of RuntimeScenario used to
 	// register global e2e/runtime flags.
-	RuntimeParamsDummy = NewRuntimeScenario("", []RunTestFunction{})
+	runtimeParamsDummy = newRuntimeScenario("", nil, false, false)
 
 	// DefaultRuntimeLogWatcherHandlerFactories is a list of default log watcher
 	// handler factories for the basic scenario.
@@ -51,11 +54,20 @@ var (
 		oasis.LogAssertNoExecutionDiscrepancyDetected(),
 	}
 
-	runtimeID common.Namespace
-	_         = runtimeID.UnmarshalHex("8000000000000000000000000000000000000000000000000000000000000000")
+	// ChaosRuntimeLogWatcherHandlerFactories is a list of log watcher handler
+	// factories for chaos scenarios. Injected faults are expected to cause
+	// timeouts and round failures, so only discrepancies are checked for.
+	ChaosRuntimeLogWatcherHandlerFactories = []log.WatcherHandlerFactory{
+		oasis.LogAssertNoExecutionDiscrepancyDetected(),
+	}
 
-	keymanagerID common.Namespace
-	_            = keymanagerID.UnmarshalHex("c000000000000000ffffffffffffffffffffffffffffffffffffffffffffffff")
+	// RuntimeID is the identifier of the compute runtime in the test network.
+	RuntimeID common.Namespace
+	_         = RuntimeID.UnmarshalHex("8000000000000000000000000000000000000000000000000000000000000000")
+
+	// KeymanagerID is the identifier of the key manager runtime in the test network.
+	KeymanagerID common.Namespace
+	_            = KeymanagerID.UnmarshalHex("c000000000000000ffffffffffffffffffffffffffffffffffffffffffffffff")
 )
 
 // RunTestFunction is a test function.
@@ -68,31 +80,63 @@ type RuntimeScenario struct {
 	// RuntimeName is the name of the runtime binary.
 	RuntimeName string
 
-	// RunTest is a list of test functions to run once the network is up.
-	RunTest []RunTestFunction
+	// extraRuntimes are the names of the additional runtime binaries in the test network.
+	extraRuntimes []string
+
+	// chaos is true for the scenario running the chaos tests of the runtime.
+	chaos bool
+
+	// mockEpoch is true for the scenario running the mock epoch tests of the runtime.
+	mockEpoch bool
+
+	// tests are the tests registered for this runtime, in registration order.
+	tests []*registeredTest
+
+	// report is the report of the current run.
+	report *Report
 }
 
-// NewRuntimeScenario creates a new runtime test scenario using the given
-// runtime and test functions.
-func NewRuntimeScenario(runtimeName string, tests []RunTestFunction) *RuntimeScenario {
+func newRuntimeScenario(runtimeName string, extraRuntimes []string, chaos, mockEpoch bool) *RuntimeScenario {
+	name := runtimeName
+	for _, rt := range extraRuntimes {
+		name += "+" + rt
+	}
+	if chaos {
+		name += "/chaos"
+	}
+	if mockEpoch {
+		name += "/mockepoch"
+	}
 	sc := &RuntimeScenario{
-		E2E:         *e2e.NewE2E(runtimeName),
-		RuntimeName: runtimeName,
-		RunTest:     tests,
+		E2E:           *e2e.NewE2E(name),
+		RuntimeName:   runtimeName,
+		extraRuntimes: append([]string{}, extraRuntimes...),
+		chaos:         chaos,
+		mockEpoch:     mockEpoch,
 	}
 	sc.Flags.String(cfgRuntimeBinaryDirDefault, "../../target/debug", "path to the runtime binaries directory")
 	sc.Flags.String(cfgRuntimeLoader, "../../../oasis-core/target/default/debug/oasis-core-runtime-loader", "path to the runtime loader")
 	sc.Flags.String(cfgKeymanagerBinary, "", "path to the keymanager binary")
 	sc.Flags.Bool(cfgIasMock, true, "if mock IAS service should be used")
+	sc.Flags.String(cfgTestRegex, "", "only run tests with names matching this regular expression")
+	sc.Flags.String(cfgTestSkipRegex, "", "skip tests with names matching this regular expression")
+	sc.Flags.Int(cfgTestParallelism, 4, "maximum number of parallel tests to run concurrently (1 disables parallel execution)")
+	sc.Flags.Bool(cfgTestReset, false, "restore the initial network state before each test")
+	sc.Flags.String(cfgReportDir, "", "directory to write JSON and JUnit XML reports of scenario runs to (none if empty)")
+	sc.Flags.String(cfgMetricsListenAddr, "", "address to serve Prometheus metrics on while scenarios run (disabled if empty)")
+	registerTimeoutFlags(sc.Flags)
 
 	return sc
 }
 
 func (sc *RuntimeScenario) Clone() scenario.Scenario {
 	return &RuntimeScenario{
-		E2E:         sc.E2E.Clone(),
-		RuntimeName: sc.RuntimeName,
-		RunTest:     append(make([]RunTestFunction, 0, len(sc.RunTest)), sc.RunTest...),
+		E2E:           sc.E2E.Clone(),
+		RuntimeName:   sc.RuntimeName,
+		extraRuntimes: sc.extraRuntimes,
+		chaos:         sc.chaos,
+		mockEpoch:     sc.mockEpoch,
+		tests:         append(make([]*registeredTest, 0, len(sc.tests)), sc.tests...),
 	}
 }
 
@@ -113,6 +157,11 @@ func (sc *RuntimeScenario) Fixture() (*oasis.NetworkFixture, error) {
 	keymanagerPath, _ := sc.Flags.GetString(cfgKeymanagerBinary)
 	usingKeymanager := len(keymanagerPath) > 0
 
+	logWatcherHandlerFactories := DefaultRuntimeLogWatcherHandlerFactories
+	if sc.chaos {
+		logWatcherHandlerFactories = ChaosRuntimeLogWatcherHandlerFactories
+	}
+
 	ff := &oasis.NetworkFixture{
 		TEE: oasis.TEEFixture{
 			Hardware: node.TEEHardwareInvalid,
@@ -121,7 +170,7 @@ func (sc *RuntimeScenario) Fixture() (*oasis.NetworkFixture, error) {
 		Network: oasis.NetworkCfg{
 			NodeBinary:                        f.Network.NodeBinary,
 			RuntimeSGXLoaderBinary:            runtimeLoader,
-			DefaultLogWatcherHandlerFactories: DefaultRuntimeLogWatcherHandlerFactories,
+			DefaultLogWatcherHandlerFactories: logWatcherHandlerFactories,
 			Consensus:                         f.Network.Consensus,
 			IAS: oasis.IASCfg{
 				Mock: iasMock,
@@ -136,7 +185,7 @@ func (sc *RuntimeScenario) Fixture() (*oasis.NetworkFixture, error) {
 						General: api.GeneralAccount{
 							Balance: *quantity.NewFromUint64(100),
 							Allowances: map[api.Address]quantity.Quantity{
-								api.NewRuntimeAddress(runtimeID): *quantity.NewFromUint64(100),
+								api.NewRuntimeAddress(RuntimeID): *quantity.NewFromUint64(100),
 							},
 						},
 					},
@@ -144,7 +193,7 @@ func (sc *RuntimeScenario) Fixture() (*oasis.NetworkFixture, error) {
 						General: api.GeneralAccount{
 							Balance: *quantity.NewFromUint64(100),
 							Allowances: map[api.Address]quantity.Quantity{
-								api.NewRuntimeAddress(runtimeID): *quantity.NewFromUint64(100),
+								api.NewRuntimeAddress(RuntimeID): *quantity.NewFromUint64(100),
 							},
 						},
 					},
@@ -158,7 +207,7 @@ func (sc *RuntimeScenario) Fixture() (*oasis.NetworkFixture, error) {
 		Runtimes: []oasis.RuntimeFixture{
 			// Key manager runtime.
 			{
-				ID:         keymanagerID,
+				ID:         KeymanagerID,
 				Kind:       registry.KindKeyManager,
 				Entity:     0,
 				Keymanager: -1,
@@ -172,7 +221,7 @@ func (sc *RuntimeScenario) Fixture() (*oasis.NetworkFixture, error) {
 			},
 			// Compute runtime.
 			{
-				ID:         runtimeID,
+				ID:         RuntimeID,
 				Kind:       registry.KindCompute,
 				Entity:     0,
 				Keymanager: -1,
@@ -248,6 +297,33 @@ func (sc *RuntimeScenario) Fixture() (*oasis.NetworkFixture, error) {
 		},
 	}
 
+	if sc.mockEpoch {
+		ff.Network.SetMockEpoch()
+	}
+
+	// Additional runtimes share the parameters and nodes of the main compute runtime.
+	for i, name := range sc.extraRuntimes {
+		id := RuntimeIDAt(i + 1)
+		rt := ff.Runtimes[1]
+		rt.ID = id
+		rt.Binaries = sc.resolveRuntimeBinaries([]string{name})
+		ff.Runtimes = append(ff.Runtimes, rt)
+
+		idx := len(ff.Runtimes) - 1
+		for j := range ff.ComputeWorkers {
+			ff.ComputeWorkers[j].Runtimes = append(ff.ComputeWorkers[j].Runtimes, idx)
+		}
+		for j := range ff.Clients {
+			ff.Clients[j].Runtimes = append(ff.Clients[j].Runtimes, idx)
+			ff.Clients[j].RuntimeConfig[idx] = map[string]interface{}{
+				"allow_expensive_queries": true,
+			}
+		}
+		for _, acct := range ff.Network.StakingGenesis.Ledger {
+			acct.General.Allowances[api.NewRuntimeAddress(id)] = *quantity.NewFromUint64(100)
+		}
+	}
+
 	if usingKeymanager {
 		for i := range ff.Runtimes {
 			if ff.Runtimes[i].Kind == registry.KindKeyManager {
@@ -327,7 +403,27 @@ func (sc *RuntimeScenario) waitNodesSynced() error {
 	return nil
 }
 
-func (sc *RuntimeScenario) Run(childEnv *env.Env) error {
+func (sc *RuntimeScenario) Run(childEnv *env.Env) (err error) {
+	if err = sc.serveMetrics(); err != nil {
+		return fmt.Errorf("failed to serve metrics: %w", err)
+	}
+
+	start := time.Now()
+	sc.report = newReport(sc, childEnv)
+	defer func() {
+		sc.recordScenario(start, err)
+		if reportErr := sc.writeReport(err); reportErr != nil {
+			sc.Logger.Error("failed to write report", "err", reportErr)
+			if err == nil {
+				err = reportErr
+			}
+		}
+	}()
+
+	return sc.run(childEnv)
+}
+
+func (sc *RuntimeScenario) run(childEnv *env.Env) error {
 	ctx := context.Background()
 
 	// Start the test network.
@@ -335,8 +431,18 @@ func (sc *RuntimeScenario) Run(childEnv *env.Env) error {
 		return err
 	}
 
+	// With mock epochs, the committees are only elected once the epoch is advanced.
+	if sc.mockEpoch {
+		if err := sc.initialEpochTransitions(ctx); err != nil {
+			return err
+		}
+	}
+
 	// Wait for all nodes to sync.
-	if err := sc.waitNodesSynced(); err != nil {
+	retry := sc.RetryPolicy()
+	if err := retry.Do(ctx, "node sync", func(context.Context) error {
+		return sc.waitNodesSynced()
+	}); err != nil {
 		return err
 	}
 
@@ -346,40 +452,138 @@ func (sc *RuntimeScenario) Run(childEnv *env.Env) error {
 		return fmt.Errorf("client initialization failed")
 	}
 
-	conn, err := cmnGrpc.Dial("unix:"+clients[0].SocketPath(), grpc.WithInsecure())
+	socketPath := clients[0].SocketPath()
+	conn, err := cmnGrpc.Dial("unix:"+socketPath, grpc.WithInsecure())
 	if err != nil {
 		return err
 	}
-	rtc := client.New(conn, runtimeID)
+	defer conn.Close()
+	rtc := sc.instrumentClient(client.New(conn, RuntimeID))
 
-	// Do an initial invariants check.
-	if err = txgen.CheckInvariants(ctx, rtc); err != nil {
+	// Do an initial invariants check, retrying as the runtime may not be available right away.
+	if err = retry.Do(ctx, "initial invariants check", func(ctx context.Context) error {
+		return sc.checkInvariants(ctx, conn)
+	}); err != nil {
 		sc.Logger.Error("initial invariants check failed", "err", err)
 		return err
 	}
 
-	// Run the given tests for this runtime.
-	for _, test := range sc.RunTest {
-		testName := runtime.FuncForPC(reflect.ValueOf(test).Pointer()).Name()
+	// Select the tests to run for this runtime.
+	tests, err := sc.selectedTests()
+	if err != nil {
+		return err
+	}
 
-		sc.Logger.Info("running test", "test", testName)
-		if testErr := test(sc, sc.Logger, conn, rtc); testErr != nil {
-			sc.Logger.Error("test failed",
-				"test", testName,
-				"err", testErr,
-			)
-			return testErr
+	// Snapshot the initial network state in case any test requires a clean state.
+	var snap *snapshot
+	resetAll, _ := sc.Flags.GetBool(cfgTestReset)
+	if resetAll || needsReset(tests) {
+		if snap, err = sc.takeSnapshot(ctx, childEnv, conn, rtc); err != nil {
+			return err
+		}
+	}
+
+	// Run the selected tests, restoring the snapshot for tests that require a clean state.
+	clean := true
+	for _, group := range groupTests(tests) {
+		if !clean && snap != nil && (resetAll || needsReset(group)) {
+			if err = sc.restoreSnapshot(ctx, snap, conn, rtc); err != nil {
+				return err
+			}
+		}
+		clean = false
+
+		if len(group) == 1 && !group[0].opts.Parallel {
+			err = sc.runTest(group[0], conn, rtc)
+		} else {
+			err = sc.runParallel(group, socketPath)
+		}
+		if err != nil {
+			return err
 		}
-		sc.Logger.Info("test passed", "test", testName)
 
-		// Do an invariants check after each test.
-		if err = txgen.CheckInvariants(ctx, rtc); err != nil {
-			sc.Logger.Error("invariants check failed after test",
-				"test", testName,
-				"err", err)
+		// Do an invariants check after each test or group of parallel tests.
+		if err = sc.checkInvariants(ctx, conn); err != nil {
+			sc.Logger.Error("invariants check failed",
+				"tests", groupNames(group),
+				"err", err,
+			)
 			return err
 		}
 	}
 
 	return sc.Net.CheckLogWatchers()
 }
+
+// selectedTests returns the registered tests selected by the test regex flags.
+func (sc *RuntimeScenario) selectedTests() ([]*registeredTest, error) {
+	compile := func(name string) (*regexp.Regexp, error) {
+		expr, _ := sc.Flags.GetString(name)
+		if expr == "" {
+			return nil, nil
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("malformed %s: %w", name, err)
+		}
+		return re, nil
+	}
+	include, err := compile(cfgTestRegex)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compile(cfgTestSkipRegex)
+	if err != nil {
+		return nil, err
+	}
+
+	var tests []*registeredTest
+	for _, test := range sc.tests {
+		if (include != nil && !include.MatchString(test.name)) || (exclude != nil && exclude.MatchString(test.name)) {
+			sc.Logger.Info("skipping test", "test", test.name)
+			sc.report.recordSkipped(test.name)
+			continue
+		}
+		tests = append(tests, test)
+	}
+	return tests, nil
+}
+
+// runTest runs a single registered test together with its setup and teardown hooks.
+//
+// The test is given its own logger, so that log lines of concurrently running tests can be told
+// apart.
+func (sc *RuntimeScenario) runTest(test *registeredTest, conn *grpc.ClientConn, rtc client.RuntimeClient) (err error) {
+	logger := sc.Logger.With("test", test.name)
+
+	start := time.Now()
+	defer func() {
+		sc.report.recordTest(test.name, start, err)
+		sc.recordTestMetrics(test.name, start, err)
+	}()
+
+	if test.opts.Setup != nil {
+		if err = test.opts.Setup(sc, conn, rtc); err != nil {
+			logger.Error("test setup failed", "err", err)
+			return fmt.Errorf("%s: setup failed: %w", test.name, err)
+		}
+	}
+	if test.opts.Teardown != nil {
+		defer func() {
+			if tdErr := test.opts.Teardown(sc, conn, rtc); tdErr != nil {
+				logger.Error("test teardown failed", "err", tdErr)
+				if err == nil {
+					err = fmt.Errorf("%s: teardown failed: %w", test.name, tdErr)
+				}
+			}
+		}()
+	}
+
+	logger.Info("running test")
+	if err = test.fn(sc, logger, conn, rtc); err != nil {
+		logger.Error("test failed", "err", err)
+		return fmt.Errorf("%s: %w", test.name, err)
+	}
+	logger.Info("test passed")
+	return nil
+}
@@ -0,0 +1,46 @@
+package harness
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/tests/e2e/txgen"
+)
+
+// RuntimeIDAt returns the identifier of the i-th compute runtime in the test network (see
+// RuntimeScenario.Runtimes). The runtime the scenario runs against has index zero and RuntimeID.
+func RuntimeIDAt(i int) common.Namespace {
+	id := RuntimeID
+	id[len(id)-1] = byte(i)
+	return id
+}
+
+// Runtimes returns the binary names of all compute runtimes in the test network, starting with the
+// runtime the scenario runs against, followed by the extra runtimes in the order of the options.
+func (sc *RuntimeScenario) Runtimes() []string {
+	return append([]string{sc.RuntimeName}, sc.extraRuntimes...)
+}
+
+// RuntimeClient returns a client for the i-th compute runtime in the test network, using the given
+// connection to the client node.
+func (sc *RuntimeScenario) RuntimeClient(conn *grpc.ClientConn, i int) (client.RuntimeClient, error) {
+	if i < 0 || i > len(sc.extraRuntimes) {
+		return nil, fmt.Errorf("harness: runtime %d not in test network", i)
+	}
+	return sc.instrumentClient(client.New(conn, RuntimeIDAt(i))), nil
+}
+
+// checkInvariants checks the invariants of all compute runtimes in the test network.
+func (sc *RuntimeScenario) checkInvariants(ctx context.Context, conn *grpc.ClientConn) error {
+	for i, name := range sc.Runtimes() {
+		if err := txgen.CheckInvariants(ctx, client.New(conn, RuntimeIDAt(i))); err != nil {
+			return fmt.Errorf("runtime %d (%s): %w", i, name, err)
+		}
+	}
+	return nil
+}
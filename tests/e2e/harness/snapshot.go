@@ -0,0 +1,187 @@
+package harness
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/oasisprotocol/oasis-core/go/oasis-test-runner/env"
+	"github.com/oasisprotocol/oasis-core/go/oasis-test-runner/oasis"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+)
+
+// snapshot is a copy of the state of all nodes in the test network.
+type snapshot struct {
+	dir string
+}
+
+// needsReset returns true if any of the given tests requires a clean test network state.
+func needsReset(tests []*registeredTest) bool {
+	for _, test := range tests {
+		if test.opts.Reset {
+			return true
+		}
+	}
+	return false
+}
+
+// takeSnapshot takes a snapshot of the state of all nodes in the test network.
+//
+// As the state can only be copied consistently while the nodes are not running, the network is
+// stopped while the snapshot is taken.
+func (sc *RuntimeScenario) takeSnapshot(ctx context.Context, childEnv *env.Env, conn *grpc.ClientConn, rtc client.RuntimeClient) (*snapshot, error) {
+	dir, err := childEnv.NewSubDir("snapshot")
+	if err != nil {
+		return nil, fmt.Errorf("harness: failed to create snapshot directory: %w", err)
+	}
+	snap := &snapshot{dir: dir.String()}
+
+	sc.Logger.Info("taking network state snapshot", "dir", snap.dir)
+	err = sc.whileStopped(ctx, conn, rtc, func() error {
+		for _, n := range sc.allNodes() {
+			if err := copyNodeState(n.DataDir(), filepath.Join(snap.dir, n.Name)); err != nil {
+				return fmt.Errorf("harness: failed to snapshot node %s: %w", n.Name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// restoreSnapshot restores the state of all nodes in the test network from the given snapshot.
+func (sc *RuntimeScenario) restoreSnapshot(ctx context.Context, snap *snapshot, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+	sc.Logger.Info("restoring network state snapshot", "dir", snap.dir)
+	return sc.whileStopped(ctx, conn, rtc, func() error {
+		for _, n := range sc.allNodes() {
+			if err := clearNodeState(n.DataDir()); err != nil {
+				return fmt.Errorf("harness: failed to clear state of node %s: %w", n.Name, err)
+			}
+			if err := copyNodeState(filepath.Join(snap.dir, n.Name), n.DataDir()); err != nil {
+				return fmt.Errorf("harness: failed to restore state of node %s: %w", n.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// whileStopped stops all nodes in the test network, calls the given function and starts the
+// nodes again. It only returns once the runtime can be queried through the given client.
+func (sc *RuntimeScenario) whileStopped(ctx context.Context, conn *grpc.ClientConn, rtc client.RuntimeClient, fn func() error) error {
+	nodes := sc.allNodes()
+	stopOrder := make([]*oasis.Node, 0, len(nodes))
+	for i := len(nodes) - 1; i >= 0; i-- {
+		stopOrder = append(stopOrder, nodes[i])
+	}
+	if err := sc.stopNodes(stopOrder); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	if err := sc.startNodes(ctx, nodes); err != nil {
+		return err
+	}
+	if err := sc.waitNodesSynced(); err != nil {
+		return err
+	}
+
+	// Do not wait for the connection to the restarted client node to back off.
+	conn.ResetConnectBackoff()
+	if err := Eventually(ctx, sc.nodeReadyTimeout(), time.Second, func(ctx context.Context) error {
+		_, err := rtc.GetInfo(ctx)
+		return err
+	}); err != nil {
+		return fmt.Errorf("harness: runtime did not become available: %w", err)
+	}
+	return nil
+}
+
+// isNodeStateFile returns true if the given data directory entry is part of the node's state.
+//
+// Logs are kept, so that they cover the whole scenario and log watchers keep working, and
+// sockets are recreated by the node anyway.
+func isNodeStateFile(d fs.DirEntry) bool {
+	if d.IsDir() {
+		return true
+	}
+	return d.Type().IsRegular() && !strings.HasSuffix(d.Name(), ".log")
+}
+
+// clearNodeState removes the node's state from the given (stopped) node's data directory.
+func clearNodeState(dataDir string) error {
+	entries, err := os.ReadDir(dataDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !isNodeStateFile(entry) {
+			continue
+		}
+		if err = os.RemoveAll(filepath.Join(dataDir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyNodeState copies the node's state from the src to the dst data directory.
+func copyNodeState(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case path == src:
+			return os.MkdirAll(target, 0o700)
+		case !isNodeStateFile(d):
+			return nil
+		case d.IsDir():
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return os.Mkdir(target, info.Mode().Perm())
+		default:
+			return copyFile(path, target)
+		}
+	})
+}
+
+func copyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
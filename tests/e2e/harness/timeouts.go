@@ -0,0 +1,145 @@
+package harness
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/oasis-test-runner/env"
+
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/events"
+)
+
+const (
+	cfgTimeoutEvent           = "timeout.event"
+	cfgTimeoutNodeReady       = "timeout.node_ready"
+	cfgTimeoutEpochTransition = "timeout.epoch_transition"
+
+	cfgRetryAttempts = "retry.attempts"
+	cfgRetryBackoff  = "retry.backoff"
+)
+
+// TimeoutScaleEnvVar is the name of the environment variable that scales the default timeouts of
+// all scenarios, e.g. 2.5 on slow CI machines. Timeouts given as scenario parameters are not
+// scaled.
+const TimeoutScaleEnvVar = "OASIS_SDK_E2E_TIMEOUT_SCALE"
+
+const (
+	// defaultNodeReadyTimeout is how long a (re)started node may take to become ready.
+	defaultNodeReadyTimeout = 2 * time.Minute
+	// defaultEpochTransitionTimeout is how long the runtime may take to process an epoch
+	// transition.
+	defaultEpochTransitionTimeout = 2 * time.Minute
+
+	// defaultRetryAttempts is the number of attempts made for known-flaky steps.
+	defaultRetryAttempts = 3
+	// defaultRetryBackoff is the delay before the first retry, which doubles with each retry.
+	defaultRetryBackoff = 2 * time.Second
+	// maxRetryBackoff is the maximum delay between retries.
+	maxRetryBackoff = 30 * time.Second
+)
+
+// scaleTimeout scales the given default timeout by the factor given in TimeoutScaleEnvVar. Malformed
+// factors are ignored.
+func scaleTimeout(timeout time.Duration) time.Duration {
+	raw := os.Getenv(TimeoutScaleEnvVar)
+	if raw == "" {
+		return timeout
+	}
+	scale, err := strconv.ParseFloat(raw, 64)
+	if err != nil || scale <= 0 {
+		return timeout
+	}
+	return time.Duration(float64(timeout) * scale)
+}
+
+// registerTimeoutFlags registers the timeout and retry scenario parameters.
+func registerTimeoutFlags(fs *env.ParameterFlagSet) {
+	fs.Duration(cfgTimeoutEvent, scaleTimeout(events.DefaultTimeout), "how long to wait for an expected event")
+	fs.Duration(cfgTimeoutNodeReady, scaleTimeout(defaultNodeReadyTimeout), "how long a (re)started node may take to become ready")
+	fs.Duration(cfgTimeoutEpochTransition, scaleTimeout(defaultEpochTransitionTimeout), "how long the runtime may take to process an epoch transition")
+	fs.Int(cfgRetryAttempts, defaultRetryAttempts, "number of attempts made for known-flaky steps (1 disables retries)")
+	fs.Duration(cfgRetryBackoff, defaultRetryBackoff, "delay before retrying a known-flaky step, doubled with each retry")
+}
+
+// EventTimeout returns how long tests should wait for an expected event.
+func (sc *RuntimeScenario) EventTimeout() time.Duration {
+	timeout, _ := sc.Flags.GetDuration(cfgTimeoutEvent)
+	return timeout
+}
+
+// EventConfig returns the configuration for waiting on expected events, using the event timeout of
+// the scenario and the given (optional) logger.
+func (sc *RuntimeScenario) EventConfig(logger *logging.Logger) *events.Config {
+	return &events.Config{
+		Timeout: sc.EventTimeout(),
+		Logger:  logger,
+	}
+}
+
+func (sc *RuntimeScenario) nodeReadyTimeout() time.Duration {
+	timeout, _ := sc.Flags.GetDuration(cfgTimeoutNodeReady)
+	return timeout
+}
+
+func (sc *RuntimeScenario) epochTransitionTimeout() time.Duration {
+	timeout, _ := sc.Flags.GetDuration(cfgTimeoutEpochTransition)
+	return timeout
+}
+
+// RetryPolicy is a policy for retrying known-flaky steps with exponential backoff.
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts. Values below one are treated as one.
+	Attempts int
+	// Backoff is the delay before the first retry, which doubles with each retry up to a maximum
+	// of 30 seconds.
+	Backoff time.Duration
+	// Logger is an optional logger that failed attempts are logged to.
+	Logger *logging.Logger
+}
+
+// RetryPolicy returns the retry policy of the scenario.
+func (sc *RuntimeScenario) RetryPolicy() RetryPolicy {
+	attempts, _ := sc.Flags.GetInt(cfgRetryAttempts)
+	backoff, _ := sc.Flags.GetDuration(cfgRetryBackoff)
+	return RetryPolicy{
+		Attempts: attempts,
+		Backoff:  backoff,
+		Logger:   sc.Logger,
+	}
+}
+
+// Do calls the given function until it succeeds or all attempts fail, in which case the error of
+// the last attempt is returned.
+func (p RetryPolicy) Do(ctx context.Context, step string, fn func(context.Context) error) error {
+	backoff := p.Backoff
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if attempt >= p.Attempts {
+			return fmt.Errorf("%s failed after %d attempt(s): %w", step, attempt, err)
+		}
+		if p.Logger != nil {
+			p.Logger.Warn("step failed, retrying",
+				"step", step,
+				"attempt", attempt,
+				"backoff", backoff,
+				"err", err,
+			)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return fmt.Errorf("%s failed: %w", step, err)
+		}
+		if backoff *= 2; backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
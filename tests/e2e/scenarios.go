@@ -3,8 +3,7 @@ package main
 import (
 	"time"
 
-	"github.com/oasisprotocol/oasis-core/go/oasis-test-runner/cmd"
-	"github.com/oasisprotocol/oasis-core/go/oasis-test-runner/scenario"
+	"github.com/oasisprotocol/oasis-sdk/tests/e2e/harness"
 )
 
 // Transaction generator e2e test option names.
@@ -14,52 +13,100 @@ const (
 	CfgTxGenDuration     = "txgen.duration"
 )
 
-var (
+// Runtime binaries the tests run against.
+const (
 	// SimpleKVRuntime is the basic network + client test case with runtime support.
-	SimpleKVRuntime *RuntimeScenario = NewRuntimeScenario("test-runtime-simple-keyvalue", []RunTestFunction{
-		SimpleKVTest,
-		KVEventTest,
-		KVBalanceTest,
-		KVTransferTest,
-		KVDaveTest,
-		KVMultisigTest,
-		KVRewardsTest,
-		KVTxGenTest,
-		ContractsTest,
-		ConfidentialTest,
-		TransactionsQueryTest,
-	})
+	SimpleKVRuntime = "test-runtime-simple-keyvalue"
 
 	// SimpleConsensusRuntime is the simple-consensus runtime test.
-	SimpleConsensusRuntime *RuntimeScenario = NewRuntimeScenario("test-runtime-simple-consensus", []RunTestFunction{SimpleConsensusTest})
+	SimpleConsensusRuntime = "test-runtime-simple-consensus"
 
 	// SimpleEVMRuntime is the simple-evm runtime test.
-	SimpleEVMRuntime *RuntimeScenario = NewRuntimeScenario("test-runtime-simple-evm", []RunTestFunction{
-		SimpleEVMDepositWithdrawTest,
-		SimpleEVMTest,
-		SimpleSolEVMTest,
-		SimpleERC20EVMTest,
-	})
+	SimpleEVMRuntime = "test-runtime-simple-evm"
 )
 
-// RegisterScenarios registers all oasis-sdk end-to-end runtime tests.
-func RegisterScenarios() error {
-	// Register non-scenario-specific parameters.
-	cmd.RegisterScenarioParams(RuntimeParamsDummy.Name(), RuntimeParamsDummy.Parameters())
+type testRegistration struct {
+	name string
+	fn   harness.RunTestFunction
+}
+
+// resetTests are the tests that check balances against the initial network state and thus
+// require it to be restored before they run.
+var resetTests = map[string]bool{
+	"KVBalanceTest": true,
+}
 
-	SimpleKVRuntime.Flags.Int(CfgTxGenNumAccounts, 10, "number of accounts to use in txgen test")
-	SimpleKVRuntime.Flags.Uint64(CfgTxGenCoinsPerAcct, 200, "number of coins to allocate to each account in txgen test")
-	SimpleKVRuntime.Flags.Duration(CfgTxGenDuration, 60*time.Second, "duration of txgen test")
+// mockEpochTests are the tests that control the epochs of the test network and thus run in a
+// separate mock epoch scenario.
+var mockEpochTests = map[string]bool{
+	"KVRewardsDisbursementTest": true,
+}
+
+// extraRuntimes are the additional runtimes started for tests that interact with more than one
+// runtime. Such tests run in a separate scenario per set of runtimes.
+var extraRuntimes = map[string][]string{
+	"ConsensusMultiRuntimeTest": {SimpleConsensusRuntime},
+}
 
-	for _, s := range []scenario.Scenario{
-		SimpleKVRuntime,
-		SimpleConsensusRuntime,
-		SimpleEVMRuntime,
+// RegisterScenarios registers all oasis-sdk end-to-end runtime tests.
+func RegisterScenarios() error {
+	for _, rt := range []struct {
+		runtime string
+		chaos   bool
+		tests   []testRegistration
+	}{
+		{SimpleKVRuntime, false, []testRegistration{
+			{"SimpleKVTest", SimpleKVTest},
+			{"KVEventTest", KVEventTest},
+			{"KVBalanceTest", KVBalanceTest},
+			{"KVTransferTest", KVTransferTest},
+			{"KVDaveTest", KVDaveTest},
+			{"KVMultisigTest", KVMultisigTest},
+			{"KVRewardsTest", KVRewardsTest},
+			{"KVRewardsDisbursementTest", KVRewardsDisbursementTest},
+			{"KVTxGenTest", KVTxGenTest},
+			{"ContractsTest", ContractsTest},
+			{"ConfidentialTest", ConfidentialTest},
+			{"ConfidentialRoundTripTest", ConfidentialRoundTripTest},
+			{"ConfidentialFailureTest", ConfidentialFailureTest},
+			{"TransactionsQueryTest", TransactionsQueryTest},
+			{"KVRuntimeInfoTest", KVRuntimeInfoTest},
+		}},
+		{SimpleKVRuntime, true, []testRegistration{
+			{"ChaosComputeRestartTest", ChaosComputeRestartTest},
+			{"ChaosPartitionTest", ChaosPartitionTest},
+			{"ChaosClientRestartTest", ChaosClientRestartTest},
+			{"ChaosKeymanagerTest", ChaosKeymanagerTest},
+		}},
+		{SimpleConsensusRuntime, false, []testRegistration{
+			{"SimpleConsensusTest", SimpleConsensusTest},
+			{"ConsensusMultiRuntimeTest", ConsensusMultiRuntimeTest},
+		}},
+		{SimpleEVMRuntime, false, []testRegistration{
+			{"SimpleEVMDepositWithdrawTest", SimpleEVMDepositWithdrawTest},
+			{"SimpleEVMTest", SimpleEVMTest},
+			{"SimpleSolEVMTest", SimpleSolEVMTest},
+			{"SimpleERC20EVMTest", SimpleERC20EVMTest},
+			{"SimpleEVMLifecycleTest", SimpleEVMLifecycleTest},
+		}},
 	} {
-		if err := cmd.Register(s); err != nil {
-			return err
+		for _, test := range rt.tests {
+			if err := harness.Register(test.name, test.fn, &harness.Options{
+				Runtime:       rt.runtime,
+				ExtraRuntimes: extraRuntimes[test.name],
+				Reset:         resetTests[test.name],
+				Chaos:         rt.chaos,
+				MockEpoch:     mockEpochTests[test.name],
+			}); err != nil {
+				return err
+			}
 		}
 	}
 
-	return nil
+	kv := harness.Scenario(SimpleKVRuntime)
+	kv.Flags.Int(CfgTxGenNumAccounts, 10, "number of accounts to use in txgen test")
+	kv.Flags.Uint64(CfgTxGenCoinsPerAcct, 200, "number of coins to allocate to each account in txgen test")
+	kv.Flags.Duration(CfgTxGenDuration, 60*time.Second, "duration of txgen test")
+
+	return harness.RegisterScenarios()
 }
@@ -3,53 +3,37 @@ package main
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"google.golang.org/grpc"
 
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
 	"github.com/oasisprotocol/oasis-core/go/common/quantity"
-	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
 	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
 
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client/consensus"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client/crossruntime"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/faucet"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
 	consensusAccounts "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/consensusaccounts"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing/events"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
-)
 
-const (
-	timeout = 1 * time.Minute
+	"github.com/oasisprotocol/oasis-sdk/tests/e2e/harness"
 )
 
-func ensureStakingEvent(log *logging.Logger, ch <-chan *staking.Event, check func(*staking.Event) bool) error {
-	log.Info("waiting for expected staking event...")
-	for {
-		select {
-		case ev := <-ch:
-			log.Debug("received event", "event", ev)
-			if check(ev) {
-				return nil
-			}
-
-		case <-time.After(timeout):
-			return fmt.Errorf("timeout waiting for event")
-		}
-	}
-}
-
-func SimpleConsensusTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+func SimpleConsensusTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
 	ctx := context.Background()
 
-	cons := consensus.NewConsensusClient(conn)
-	stakingClient := cons.Staking()
-	ch, sub, err := stakingClient.WatchEvents(ctx)
+	cons := consensus.New(conn)
+	ch, sub, err := cons.WatchStakingEvents(ctx)
 	defer sub.Close()
 	if err != nil {
 		return err
 	}
 
+	waitCfg := sc.EventConfig(log)
 	consDenomination := types.Denomination("TEST")
 
 	consAccounts := consensusAccounts.NewV1(rtc)
@@ -65,18 +49,11 @@ func SimpleConsensusTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.Cl
 		return err
 	}
 
-	if err = ensureStakingEvent(log, ch, func(e *staking.Event) bool {
-		if e.Transfer == nil {
-			return false
-		}
-		if e.Transfer.From != staking.Address(testing.Alice.Address) {
-			return false
-		}
-		if e.Transfer.To != staking.NewRuntimeAddress(runtimeID) {
-			return false
-		}
-		return e.Transfer.Amount.Cmp(&amount.Amount) == 0
-	}); err != nil {
+	if _, err = events.WaitForConsensusEvent(ctx, ch, waitCfg, events.ConsensusTransfer(
+		staking.Address(testing.Alice.Address),
+		staking.NewRuntimeAddress(harness.RuntimeID),
+		amount.Amount,
+	)); err != nil {
 		return fmt.Errorf("ensuring alice deposit consensus event: %w", err)
 	}
 
@@ -89,18 +66,11 @@ func SimpleConsensusTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.Cl
 	if err = tb.SubmitTx(ctx, nil); err != nil {
 		return err
 	}
-	if err = ensureStakingEvent(log, ch, func(e *staking.Event) bool {
-		if e.Transfer == nil {
-			return false
-		}
-		if e.Transfer.From != staking.Address(testing.Bob.Address) {
-			return false
-		}
-		if e.Transfer.To != staking.NewRuntimeAddress(runtimeID) {
-			return false
-		}
-		return e.Transfer.Amount.Cmp(&amount.Amount) == 0
-	}); err != nil {
+	if _, err = events.WaitForConsensusEvent(ctx, ch, waitCfg, events.ConsensusTransfer(
+		staking.Address(testing.Bob.Address),
+		staking.NewRuntimeAddress(harness.RuntimeID),
+		amount.Amount,
+	)); err != nil {
 		return fmt.Errorf("ensuring bob deposit consensus event: %w", err)
 	}
 
@@ -110,21 +80,28 @@ func SimpleConsensusTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.Cl
 		SetFeeConsensusMessages(1).
 		AppendAuthSignature(testing.Alice.SigSpec, 1)
 	_ = tb.AppendSign(ctx, testing.Alice.Signer)
-	if err = tb.SubmitTx(ctx, nil); err != nil {
+	meta, err := tb.SubmitTxMeta(ctx, nil)
+	if err != nil {
 		return err
 	}
-	if err = ensureStakingEvent(log, ch, func(e *staking.Event) bool {
-		if e.Transfer == nil {
-			return false
-		}
-		if e.Transfer.To != staking.Address(testing.Alice.Address) {
-			return false
-		}
-		if e.Transfer.From != staking.NewRuntimeAddress(runtimeID) {
-			return false
-		}
-		return e.Transfer.Amount.Cmp(&amount.Amount) == 0
-	}); err != nil {
+
+	log.Info("checking alice withdraw message results")
+	results, err := rtc.GetMessageResults(ctx, meta.Round)
+	if err != nil {
+		return fmt.Errorf("failed to get message results: %w", err)
+	}
+	if len(results) != 1 {
+		return fmt.Errorf("unexpected number of message results (expected: %d, got: %d)", 1, len(results))
+	}
+	if err = results[0].Error(); err != nil {
+		return fmt.Errorf("alice withdraw message failed: %w", err)
+	}
+
+	if _, err = events.WaitForConsensusEvent(ctx, ch, waitCfg, events.ConsensusTransfer(
+		staking.NewRuntimeAddress(harness.RuntimeID),
+		staking.Address(testing.Alice.Address),
+		amount.Amount,
+	)); err != nil {
 		return fmt.Errorf("ensuring alice withdraw consensus event: %w", err)
 	}
 
@@ -175,6 +152,15 @@ func SimpleConsensusTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.Cl
 		return fmt.Errorf("unexpected alice consensus account balance, got: %s", acc.General.Balance)
 	}
 
+	log.Info("query alice consensus balance directly")
+	consBalance, err := cons.Balance(ctx, consensus.HeightLatest, testing.Alice.Address)
+	if err != nil {
+		return err
+	}
+	if consBalance.Cmp(&acc.General.Balance) != 0 {
+		return fmt.Errorf("consensus balance mismatch (runtime query: %s, consensus: %s)", acc.General.Balance, consBalance)
+	}
+
 	log.Info("dave depositing (secp256k1)")
 	amount.Amount = *quantity.NewFromUint64(50)
 	tb = consAccounts.Deposit(amount).
@@ -187,6 +173,32 @@ func SimpleConsensusTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.Cl
 		return fmt.Errorf("dave depositing should fail")
 	}
 
+	log.Info("alice transferring via the consensus layer")
+	xfer := crossruntime.Transfer{
+		Source:      rtc,
+		Destination: rtc,
+		Consensus:   cons,
+		Signer:      testing.Alice.ConsensusSigner,
+		Amount:      *quantity.NewFromUint64(10),
+		OnProgress: func(ev *crossruntime.ProgressEvent) {
+			log.Info("transfer progress", "stage", ev.Stage, "completed", ev.Completed, "round", ev.Round)
+		},
+	}
+	xferResult, err := xfer.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("cross-runtime transfer failed: %w", err)
+	}
+	if !xferResult.Withdrawn || !xferResult.Deposited {
+		return fmt.Errorf("cross-runtime transfer incomplete: %+v", xferResult)
+	}
+	resp, err = consAccounts.Balance(ctx, client.RoundLatest, balanceQuery)
+	if err != nil {
+		return err
+	}
+	if resp.Balance.Cmp(quantity.NewFromUint64(25)) != 0 {
+		return fmt.Errorf("unexpected alice balance after transfer, got: %s", resp.Balance)
+	}
+
 	log.Info("query consensus addresses")
 	addrs, err := ac.Addresses(ctx, client.RoundLatest, consDenomination)
 	if err != nil {
@@ -198,3 +210,95 @@ func SimpleConsensusTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.Cl
 
 	return nil
 }
+
+// ConsensusMultiRuntimeTest tests transfers between two runtimes via the consensus layer.
+func ConsensusMultiRuntimeTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+	ctx := context.Background()
+
+	cons := consensus.New(conn)
+	ch, sub, err := cons.WatchStakingEvents(ctx)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	otherRtc, err := sc.RuntimeClient(conn, 1)
+	if err != nil {
+		return err
+	}
+	waitCfg := sc.EventConfig(log)
+	consDenomination := types.Denomination("TEST")
+
+	log.Info("alice depositing into first runtime")
+	amount := types.NewBaseUnits(*quantity.NewFromUint64(30), consDenomination)
+	tb := consensusAccounts.NewV1(rtc).Deposit(amount).
+		SetFeeConsensusMessages(1).
+		AppendAuthSignature(testing.Alice.SigSpec, 0)
+	_ = tb.AppendSign(ctx, testing.Alice.Signer)
+	if err = tb.SubmitTx(ctx, nil); err != nil {
+		return err
+	}
+	if _, err = events.WaitForConsensusEvent(ctx, ch, waitCfg, events.ConsensusTransfer(
+		staking.Address(testing.Alice.Address),
+		staking.NewRuntimeAddress(harness.RuntimeIDAt(0)),
+		amount.Amount,
+	)); err != nil {
+		return fmt.Errorf("ensuring alice deposit consensus event: %w", err)
+	}
+	if err = faucet.WaitForBalance(ctx, rtc, testing.Alice.Address, amount); err != nil {
+		return fmt.Errorf("waiting for alice deposit: %w", err)
+	}
+
+	log.Info("alice transferring from first to second runtime")
+	xfer := crossruntime.Transfer{
+		Source:      rtc,
+		Destination: otherRtc,
+		Consensus:   cons,
+		Signer:      testing.Alice.ConsensusSigner,
+		Amount:      *quantity.NewFromUint64(10),
+		OnProgress: func(ev *crossruntime.ProgressEvent) {
+			log.Info("transfer progress", "stage", ev.Stage, "completed", ev.Completed, "round", ev.Round)
+		},
+	}
+	xferResult, err := xfer.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("cross-runtime transfer failed: %w", err)
+	}
+	if !xferResult.Withdrawn || !xferResult.Deposited {
+		return fmt.Errorf("cross-runtime transfer incomplete: %+v", xferResult)
+	}
+
+	log.Info("checking alice balances")
+	for _, expected := range []struct {
+		rtc     client.RuntimeClient
+		balance uint64
+	}{
+		{rtc, 20},
+		{otherRtc, 10},
+	} {
+		if err = faucet.WaitForBalance(ctx, expected.rtc, testing.Alice.Address,
+			types.NewBaseUnits(*quantity.NewFromUint64(expected.balance), consDenomination)); err != nil {
+			return err
+		}
+		resp, err := consensusAccounts.NewV1(expected.rtc).Balance(ctx, client.RoundLatest, &consensusAccounts.BalanceQuery{
+			Address: testing.Alice.Address,
+		})
+		if err != nil {
+			return err
+		}
+		if resp.Balance.Cmp(quantity.NewFromUint64(expected.balance)) != 0 {
+			return fmt.Errorf("unexpected alice balance, expected: %d got: %s", expected.balance, resp.Balance)
+		}
+	}
+
+	log.Info("checking alice consensus balance")
+	consBalance, err := cons.Balance(ctx, consensus.HeightLatest, testing.Alice.Address)
+	if err != nil {
+		return err
+	}
+	if consBalance.Cmp(quantity.NewFromUint64(70)) != 0 {
+		return fmt.Errorf("unexpected alice consensus balance, got: %s", consBalance)
+	}
+
+	return nil
+}
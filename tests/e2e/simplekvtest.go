@@ -18,6 +18,7 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/common/quantity"
 
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/client/consensus"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/ed25519"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
@@ -26,12 +27,10 @@ import (
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
 
+	"github.com/oasisprotocol/oasis-sdk/tests/e2e/harness"
 	"github.com/oasisprotocol/oasis-sdk/tests/e2e/txgen"
 )
 
-// EventWaitTimeout specifies how long to wait for an event.
-const EventWaitTimeout = 20 * time.Second
-
 // defaultGasAmount is the default amount of gas to specify.
 const defaultGasAmount = 400
 
@@ -207,7 +206,7 @@ func kvInsertSpecialGreeting(rtc client.RuntimeClient, signer signature.Signer,
 }
 
 // SimpleKVTest does a simple key insert/fetch/remove test.
-func SimpleKVTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+func SimpleKVTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
 	signer := testing.Alice.Signer
 
 	testKey := []byte("test_key")
@@ -256,8 +255,77 @@ func SimpleKVTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.ClientCon
 	return nil
 }
 
+// KVRuntimeInfoTest checks the runtime descriptor, roothash state, committee, epoch and status
+// queries.
+func KVRuntimeInfoTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+	ctx := context.Background()
+	cons := consensus.New(conn)
+
+	log.Info("query runtime descriptor")
+	info, err := rtc.GetInfo(ctx)
+	if err != nil {
+		return err
+	}
+	rt, err := cons.RuntimeDescriptor(ctx, consensus.HeightLatest, info.ID)
+	if err != nil {
+		return fmt.Errorf("failed to query runtime descriptor: %w", err)
+	}
+	if !rt.ID.Equal(&info.ID) {
+		return fmt.Errorf("unexpected runtime descriptor ID (expected: %s, got: %s)", info.ID, rt.ID)
+	}
+
+	log.Info("query runtime state")
+	state, err := cons.RuntimeState(ctx, consensus.HeightLatest, info.ID)
+	if err != nil {
+		return fmt.Errorf("failed to query runtime state: %w", err)
+	}
+	if state.Suspended {
+		return fmt.Errorf("runtime should not be suspended")
+	}
+
+	log.Info("query executor committee")
+	committee, err := cons.ExecutorCommittee(ctx, consensus.HeightLatest, info.ID)
+	if err != nil {
+		return fmt.Errorf("failed to query executor committee: %w", err)
+	}
+	if len(committee.Workers()) == 0 {
+		return fmt.Errorf("executor committee should have workers")
+	}
+
+	log.Info("query epoch")
+	epoch, err := cons.Epoch(ctx, consensus.HeightLatest)
+	if err != nil {
+		return fmt.Errorf("failed to query epoch: %w", err)
+	}
+	round, err := cons.EpochStartRound(ctx, info.ID, epoch)
+	if err != nil {
+		return fmt.Errorf("failed to query epoch start round: %w", err)
+	}
+	roundEpoch, err := cons.RoundEpoch(ctx, info.ID, round)
+	if err != nil {
+		return fmt.Errorf("failed to query round epoch: %w", err)
+	}
+	if roundEpoch > epoch {
+		return fmt.Errorf("epoch start round %d finalized in a later epoch (%d > %d)", round, roundEpoch, epoch)
+	}
+
+	log.Info("query runtime status")
+	status, err := rtc.GetStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to query runtime status: %w", err)
+	}
+	if status.LastRetainedRound < status.GenesisRound || status.LastRetainedRound > status.LatestRound {
+		return fmt.Errorf("last retained round %d outside of [%d, %d]", status.LastRetainedRound, status.GenesisRound, status.LatestRound)
+	}
+	if status.LatestRound < round {
+		return fmt.Errorf("latest round %d before epoch start round %d", status.LatestRound, round)
+	}
+
+	return nil
+}
+
 // ConfidentialTest tests functions that require a key manager.
-func ConfidentialTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+func ConfidentialTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
 	ctx := context.Background()
 	signer := testing.Alice.Signer
 
@@ -296,7 +364,7 @@ func ConfidentialTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.Clien
 }
 
 // TransactionsQueryTest tests SubmitTx*Meta and GetTransactionsWithResults functions.
-func TransactionsQueryTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+func TransactionsQueryTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
 	ctx := context.Background()
 	signer := testing.Alice.Signer
 
@@ -348,7 +416,7 @@ func TransactionsQueryTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.
 }
 
 // KVEventTest tests key insert/remove events.
-func KVEventTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+func KVEventTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
 	signer := testing.Alice.Signer
 
 	testKey := []byte("event_test_key")
@@ -374,7 +442,7 @@ WaitInsertLoop:
 		select {
 		case <-ctx.Done():
 			return fmt.Errorf("context terminated")
-		case <-time.After(EventWaitTimeout):
+		case <-time.After(sc.EventTimeout()):
 			return fmt.Errorf("timed out")
 		case blk, ok := <-blkCh:
 			if !ok {
@@ -427,7 +495,7 @@ WaitRemoveLoop:
 		select {
 		case <-ctx.Done():
 			return fmt.Errorf("context terminated")
-		case <-time.After(EventWaitTimeout):
+		case <-time.After(sc.EventTimeout()):
 			return fmt.Errorf("timed out")
 		case blk, ok := <-blkCh:
 			if !ok {
@@ -472,7 +540,7 @@ WaitRemoveLoop:
 }
 
 // KVBalanceTest checks test accounts' default balances.
-func KVBalanceTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+func KVBalanceTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
 	ctx := context.Background()
 	ac := accounts.NewV1(rtc)
 
@@ -532,7 +600,7 @@ func KVBalanceTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.ClientCo
 }
 
 // KVTransferTest does a transfer test and verifies balances.
-func KVTransferTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+func KVTransferTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
 	ctx := context.Background()
 	ac := accounts.NewV1(rtc)
 
@@ -612,11 +680,32 @@ func KVTransferTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.ClientC
 		return fmt.Errorf("unexpected number of addresses (expected at least: %d, got: %d)", 5, len(addrs))
 	}
 
+	log.Info("query parameters")
+	params, err := ac.Parameters(ctx, client.RoundLatest)
+	if err != nil {
+		return err
+	}
+	if params.TransfersDisabled {
+		return fmt.Errorf("transfers should be enabled")
+	}
+	if params.GasCosts.TxTransfer != 100 {
+		return fmt.Errorf("unexpected transfer gas cost (expected: %d, got: %d)", 100, params.GasCosts.TxTransfer)
+	}
+
+	log.Info("query total supplies")
+	supplies, err := ac.TotalSupplies(ctx, client.RoundLatest)
+	if err != nil {
+		return err
+	}
+	if _, ok := supplies[types.NativeDenomination]; !ok {
+		return fmt.Errorf("total supplies are missing native denomination")
+	}
+
 	return nil
 }
 
 // KVDaveTest does a tx signing test using the secp256k1 signer.
-func KVDaveTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+func KVDaveTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
 	ctx := context.Background()
 	ac := accounts.NewV1(rtc)
 
@@ -663,7 +752,7 @@ func KVDaveTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn,
 	return nil
 }
 
-func KVMultisigTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+func KVMultisigTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
 	signerA := testing.Alice.Signer
 	signerB := testing.Bob.Signer
 	config := types.MultisigConfig{
@@ -725,7 +814,7 @@ func KVMultisigTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.ClientC
 	return nil
 }
 
-func KVRewardsTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+func KVRewardsTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
 	ctx := context.Background()
 	rw := rewards.NewV1(rtc)
 
@@ -748,8 +837,75 @@ func KVRewardsTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.ClientCo
 	return nil
 }
 
+// KVRewardsDisbursementTest checks that rewards for an epoch are disbursed from the reward pool
+// once the epoch ends. It controls the epochs of the test network, so it must run in a mock epoch
+// scenario.
+func KVRewardsDisbursementTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+	ctx := context.Background()
+	rw := rewards.NewV1(rtc)
+
+	params, err := rw.Parameters(ctx, client.RoundLatest)
+	if err != nil {
+		return err
+	}
+
+	// Start with a fresh epoch, so that no rewards are pending from previous tests.
+	epoch, err := sc.AdvanceEpochs(ctx, 1)
+	if err != nil {
+		return err
+	}
+	if err = sc.WaitRuntimeEpoch(ctx, conn, rtc, epoch); err != nil {
+		return err
+	}
+
+	poolBalance := func() (*quantity.Quantity, error) {
+		pb, err := rw.RewardPoolBalances(ctx, client.RoundLatest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query reward pool balance: %w", err)
+		}
+		q := pb.Balances[types.NativeDenomination]
+		return &q, nil
+	}
+
+	// Rewards are only accumulated in rounds in which the compute committee did some work.
+	log.Info("accumulating rewards", "epoch", epoch)
+	if err = kvInsert(rtc, testing.Alice.Signer, []byte("rewards_key"), []byte("rewards_value")); err != nil {
+		return err
+	}
+	before, err := poolBalance()
+	if err != nil {
+		return err
+	}
+
+	// Rewards for the epoch are disbursed in the first round after the epoch ends.
+	next, err := sc.AdvanceEpochs(ctx, 1)
+	if err != nil {
+		return err
+	}
+	log.Info("disbursing rewards", "epoch", next)
+	if err = kvRemove(rtc, testing.Alice.Signer, []byte("rewards_key")); err != nil {
+		return err
+	}
+	after, err := poolBalance()
+	if err != nil {
+		return err
+	}
+
+	disbursed := before.Clone()
+	if err = disbursed.Sub(after); err != nil {
+		return fmt.Errorf("reward pool balance increased (before: %s after: %s)", before, after)
+	}
+	reward := params.Schedule.ForEpoch(epoch)
+	if disbursed.Cmp(&reward.Amount) < 0 {
+		return fmt.Errorf("rewards for epoch %d not disbursed (expected at least: %s got: %s)", epoch, reward.Amount, disbursed)
+	}
+	log.Info("rewards disbursed", "epoch", epoch, "amount", disbursed)
+
+	return nil
+}
+
 // KVTxGenTest generates random transactions.
-func KVTxGenTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
+func KVTxGenTest(sc *harness.RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn, rtc client.RuntimeClient) error {
 	ctx := context.Background()
 	ac := accounts.NewV1(rtc)
 
@@ -798,18 +954,23 @@ func KVTxGenTest(sc *RuntimeScenario, log *logging.Logger, conn *grpc.ClientConn
 
 	// Generate accounts.
 	log.Info("generating accounts", "num_accounts", numAccounts, "coins_per_account", coinsPerAccount, "rng_seed", seed)
-	var accts []signature.Signer
 	numT := make(map[string]uint64)
-	for i := 0; i < numAccounts; i++ {
-		// Create account.
-		at := txgen.AccountType(uint8(rng.Intn(int(txgen.AccountTypeMax) + 1)))
-		numT[at.String()]++
-		sig, grr := txgen.CreateAndFundAccount(ctx, rtc, testing.Alice.Signer, i, at, coinsPerAccount)
-		if grr != nil {
-			return grr
-		}
-
-		accts = append(accts, sig)
+	keys, err := testing.NewFundedAccounts(ctx, rtc, &testing.FundedAccountsConfig{
+		Funder:  testing.Alice,
+		Count:   numAccounts,
+		Amounts: []types.BaseUnits{types.NewBaseUnits(*quantity.NewFromUint64(coinsPerAccount), types.NativeDenomination)},
+		NewKey: func() (testing.TestKey, error) {
+			at := txgen.AccountType(uint8(rng.Intn(int(txgen.AccountTypeMax) + 1)))
+			numT[at.String()]++
+			return at.NewTestKey()
+		},
+	})
+	if err != nil {
+		return err
+	}
+	accts := make([]signature.Signer, 0, len(keys))
+	for _, key := range keys {
+		accts = append(accts, key.Signer)
 	}
 	log.Info("accounts generated", "num_accts_per_type", numT)
 
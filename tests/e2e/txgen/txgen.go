@@ -9,13 +9,8 @@ import (
 
 	"google.golang.org/grpc"
 
-	"github.com/btcsuite/btcd/btcec"
-
-	voiSr "github.com/oasisprotocol/curve25519-voi/primitives/sr25519"
-
 	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/cbor"
-	coreMemSig "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/memory"
 	cmnGrpc "github.com/oasisprotocol/oasis-core/go/common/grpc"
 	"github.com/oasisprotocol/oasis-core/go/common/quantity"
 
@@ -25,6 +20,8 @@ import (
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/secp256k1"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/crypto/signature/sr25519"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/core"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/testing"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
 )
 
@@ -79,12 +76,12 @@ func GetChainContext(ctx context.Context, rtc client.RuntimeClient) (signature.C
 // EstimateGas estimates the amount of gas the transaction will use.
 // Returns modified transaction that has just the right amount of gas.
 func EstimateGas(ctx context.Context, rtc client.RuntimeClient, tx types.Transaction, extraGas uint64) types.Transaction {
-	var gas uint64
 	oldGas := tx.AuthInfo.Fee.Gas
 	// Set the starting gas to something high, so we don't run out.
 	tx.AuthInfo.Fee.Gas = highGasAmount
 	// Estimate gas usage.
-	if err := rtc.Query(ctx, client.RoundLatest, "core.EstimateGas", tx, &gas); err != nil {
+	gas, err := core.NewV1(rtc).EstimateGas(ctx, client.RoundLatest, &tx)
+	if err != nil {
 		tx.AuthInfo.Fee.Gas = oldGas + extraGas
 		return tx
 	}
@@ -95,7 +92,7 @@ func EstimateGas(ctx context.Context, rtc client.RuntimeClient, tx types.Transac
 
 // CheckInvariants issues a check of invariants in all modules in the runtime.
 func CheckInvariants(ctx context.Context, rtc client.RuntimeClient) error {
-	return rtc.Query(ctx, client.RoundLatest, "core.CheckInvariants", nil, nil)
+	return core.NewV1(rtc).CheckInvariants(ctx, client.RoundLatest)
 }
 
 // SignAndSubmitTx signs and submits the given transaction.
@@ -132,44 +129,18 @@ func SignAndSubmitTx(ctx context.Context, rtc client.RuntimeClient, signer signa
 	return result, nil
 }
 
-// CreateAndFundAccount creates a new account and funds it using the
-// given funding account.
-func CreateAndFundAccount(ctx context.Context, rtc client.RuntimeClient, funder signature.Signer, id int, acctType AccountType, fundAmount uint64) (signature.Signer, error) {
-	// Create new account.
-	var sig signature.Signer
-	switch acctType {
+// NewTestKey generates a fresh random test key of the given account type.
+func (at AccountType) NewTestKey() (testing.TestKey, error) {
+	switch at {
 	case AccountEd25519:
-		cs := coreMemSig.NewTestSigner(fmt.Sprintf("test account %d", id))
-		sig = ed25519.WrapSigner(cs)
+		return testing.NewRandomEd25519TestKey()
 	case AccountSecp256k1:
-		pk, err := btcec.NewPrivateKey(btcec.S256())
-		if err != nil {
-			return nil, err
-		}
-		sig = secp256k1.NewSigner(pk.Serialize())
+		return testing.NewRandomSecp256k1TestKey()
 	case AccountSr25519:
-		kp, err := voiSr.GenerateKeyPair(nil)
-		if err != nil {
-			return nil, err
-		}
-		sig = sr25519.NewSignerFromKeyPair(kp)
+		return testing.NewRandomSr25519TestKey()
 	default:
-		return nil, fmt.Errorf("invalid account type")
+		return testing.TestKey{}, fmt.Errorf("invalid account type")
 	}
-
-	// Give it some coins.
-	tx := types.NewTransaction(nil, "accounts.Transfer", struct {
-		To     types.Address   `json:"to"`
-		Amount types.BaseUnits `json:"amount"`
-	}{
-		To:     types.NewAddress(sigspecForSigner(sig)),
-		Amount: types.NewBaseUnits(*quantity.NewFromUint64(fundAmount), types.NativeDenomination),
-	})
-	if _, err := SignAndSubmitTx(ctx, rtc, funder, *tx, 0); err != nil {
-		return nil, err
-	}
-
-	return sig, nil
 }
 
 // RandomizeFee generates random fee parameters for the transaction.
@@ -77,6 +77,13 @@ cd "${TEST_BASE_DIR}"
 . "${TESTS_DIR}/consts.sh"
 . "${TESTS_DIR}/paths.sh"
 
+# Write machine-readable reports in case a report directory is given.
+REPORT_ARGS=()
+if [[ -n "${TEST_REPORT_DIR:-}" ]]; then
+	mkdir -p "${TEST_REPORT_DIR}"
+	REPORT_ARGS+=(--e2e.report.dir="$(cd "${TEST_REPORT_DIR}" && pwd -P)")
+fi
+
 printf "${CYAN}### Running end-to-end tests...${OFF}\n"
 ./e2e --log.level=INFO \
 	--log.format json \
@@ -85,6 +92,7 @@ printf "${CYAN}### Running end-to-end tests...${OFF}\n"
 	--e2e.runtime.binary_dir.default="${TEST_BASE_DIR}" \
 	--e2e.runtime.loader="${TEST_RUNTIME_LOADER}" \
 	--e2e.keymanager.binary="${TEST_KM_BINARY}" \
+	"${REPORT_ARGS[@]}" \
 	"$@"
 
 cd "${TESTS_DIR}"